@@ -0,0 +1,109 @@
+// Package apitoken provides the tokens that authenticate requests to the JSON API.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+	"gorm.io/gorm"
+)
+
+// tokenPrefix marks Oar API tokens, so that they are easy to recognize, e.g. by secret scanners
+const tokenPrefix = "oar_"
+
+// ErrInvalidToken is returned for tokens that were not issued by this server or were revoked
+var ErrInvalidToken = errors.New("invalid API token")
+
+// Service issues, authenticates and revokes API tokens. Only the SHA-256 hash of a token is stored,
+// so the token itself is shown once, when it is created.
+type Service struct {
+	tokens repository.APITokenRepository
+}
+
+func NewService(tokens repository.APITokenRepository) *Service {
+	return &Service{tokens: tokens}
+}
+
+// Create issues a token allowed to perform actions, on the project when projectID is set or on all
+// projects otherwise, and returns it with the token value
+func (s *Service) Create(name string, projectID *uuid.UUID, actions []domain.APITokenAction) (*domain.APIToken, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", fmt.Errorf("API token name is required")
+	}
+	if len(actions) == 0 {
+		return nil, "", fmt.Errorf("API token needs at least one action")
+	}
+
+	value := tokenPrefix + rand.Text()
+	token := &domain.APIToken{
+		ID:        uuid.New(),
+		Name:      name,
+		ProjectID: projectID,
+		Actions:   actions,
+	}
+	if err := s.tokens.Create(token, hash(value)); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "apitoken",
+			"operation", "create_api_token",
+			"name", name,
+			"error", err)
+		return nil, "", fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	slog.Info("API token created", "token_id", token.ID, "name", name, "project_id", projectID)
+	return token, value, nil
+}
+
+// List returns all tokens, oldest first
+func (s *Service) List() ([]*domain.APIToken, error) {
+	return s.tokens.List()
+}
+
+// Revoke deletes a token, so that it can no longer be used
+func (s *Service) Revoke(id uuid.UUID) error {
+	err := s.tokens.Delete(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("API token %s not found", id)
+	}
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "apitoken",
+			"operation", "revoke_api_token",
+			"token_id", id,
+			"error", err)
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	slog.Info("API token revoked", "token_id", id)
+	return nil
+}
+
+// Authenticate returns the token with the given value, or ErrInvalidToken when there is none
+func (s *Service) Authenticate(value string) (*domain.APIToken, error) {
+	if !strings.HasPrefix(value, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+	token, err := s.tokens.FindByHash(hash(value))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find API token: %w", err)
+	}
+	return token, nil
+}
+
+// hash returns the hex-encoded SHA-256 hash of a token. Tokens are random, so a plain hash is
+// enough to keep a leaked database from revealing usable tokens.
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
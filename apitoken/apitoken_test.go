@@ -0,0 +1,86 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+)
+
+func setupService(t *testing.T) (*Service, uuid.UUID) {
+	t.Helper()
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+
+	project := db.ProjectModel{
+		BaseModel:    db.BaseModel{ID: uuid.New()},
+		Name:         "scoped",
+		GitURL:       "https://example.com/scoped.git",
+		GitBranch:    "main",
+		WorkingDir:   "/tmp/scoped",
+		ComposeFiles: "compose.yaml",
+		Status:       "running",
+	}
+	require.NoError(t, database.Create(&project).Error)
+
+	return NewService(repository.NewAPITokenRepository(database)), project.ID
+}
+
+func TestCreateAndAuthenticate(t *testing.T) {
+	service, projectID := setupService(t)
+
+	token, value, err := service.Create("ci", &projectID, []domain.APITokenAction{domain.APITokenActionRead})
+	require.NoError(t, err)
+	assert.Contains(t, value, tokenPrefix)
+
+	authenticated, err := service.Authenticate(value)
+	require.NoError(t, err)
+	assert.Equal(t, token.ID, authenticated.ID)
+	require.NotNil(t, authenticated.ProjectID)
+	assert.Equal(t, projectID, *authenticated.ProjectID)
+	assert.Equal(t, []domain.APITokenAction{domain.APITokenActionRead}, authenticated.Actions)
+
+	otherProject := uuid.New()
+	assert.True(t, authenticated.Allows(domain.APITokenActionRead, &projectID))
+	assert.False(t, authenticated.Allows(domain.APITokenActionDeploy, &projectID), "Action not granted")
+	assert.False(t, authenticated.Allows(domain.APITokenActionRead, &otherProject), "Other project")
+	assert.False(t, authenticated.Allows(domain.APITokenActionRead, nil), "Endpoint of no single project")
+
+	_, err = service.Authenticate(value + "x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = service.Authenticate("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevoke(t *testing.T) {
+	service, _ := setupService(t)
+
+	token, value, err := service.Create("all projects", nil, domain.APITokenActions)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Revoke(token.ID))
+	_, err = service.Authenticate(value)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	assert.Error(t, service.Revoke(token.ID), "Revoking twice fails")
+
+	tokens, err := service.List()
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+}
+
+func TestCreateValidates(t *testing.T) {
+	service, _ := setupService(t)
+
+	_, _, err := service.Create(" ", nil, domain.APITokenActions)
+	assert.Error(t, err)
+	_, _, err = service.Create("no actions", nil, nil)
+	assert.Error(t, err)
+}
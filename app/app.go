@@ -3,13 +3,21 @@ package app
 
 import (
 	"os"
+	"sync/atomic"
 
+	"github.com/oar-cd/oar/apitoken"
 	"github.com/oar-cd/oar/config"
 	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/encryption"
 	"github.com/oar-cd/oar/git"
+	"github.com/oar-cd/oar/maintenance"
+	"github.com/oar-cd/oar/metrics"
 	"github.com/oar-cd/oar/project"
 	"github.com/oar-cd/oar/repository"
+	"github.com/oar-cd/oar/share"
+	"github.com/oar-cd/oar/update"
+	"github.com/oar-cd/oar/watcher"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +29,16 @@ var (
 	projectService project.ProjectManager
 	gitService     *git.GitService
 	appConfig      *config.Config
+	maintenanceSvc *maintenance.Scheduler
+	retention      *maintenance.RetentionLimits
+	shareService   *share.Service
+	apiTokens      *apitoken.Service
+	metricsReg     *metrics.Registry
+	updateChecker  *update.Checker
+	watcherChecks  repository.WatcherCheckRepository
+
+	// watcherService is set by the server while HTTP handlers may already be reading it
+	watcherService atomic.Pointer[watcher.WatcherService]
 )
 
 // InitializeWithConfig initializes the app with a pre-configured Config
@@ -42,7 +60,7 @@ func InitializeWithConfig(cfg *config.Config) error {
 	}
 
 	// Initialize database using config
-	database, err = db.InitDB(appConfig.DataDir)
+	database, err = db.InitDB(appConfig.DataDir, appConfig.DatabaseOptions())
 	if err != nil {
 		return err
 	}
@@ -52,6 +70,12 @@ func InitializeWithConfig(cfg *config.Config) error {
 		return err
 	}
 
+	// Every docker command and Docker API client talks to the configured daemon
+	docker.Configure(docker.HostSettings{
+		Host:          appConfig.DockerHost,
+		CommandPrefix: appConfig.DockerCommandPrefix,
+	})
+
 	gitService = git.NewGitService(appConfig)
 
 	// Initialize encryption service
@@ -62,13 +86,47 @@ func InitializeWithConfig(cfg *config.Config) error {
 
 	// Initialize repositories
 	projectRepo := repository.NewProjectRepository(database, encryptionSvc)
-	deploymentRepo := repository.NewDeploymentRepository(database)
+	deploymentRepo := repository.NewDeploymentRepository(
+		database,
+		repository.WithOutputCompression(appConfig.ComposeCompressOutput),
+	)
 
 	// Initialize services with dependency injection
-	projectService = project.NewProjectService(projectRepo, deploymentRepo, gitService, appConfig)
+	metricsReg = metrics.NewRegistry()
+	service := project.NewProjectService(projectRepo, deploymentRepo, gitService, appConfig)
+	service.SetMetrics(metricsReg)
+	projectService = service
+	shareLinkRepo := repository.NewShareLinkRepository(database)
+	shareService = share.NewService(shareLinkRepo, appConfig.EncryptionKey)
+	apiTokens = apitoken.NewService(repository.NewAPITokenRepository(database))
+	watcherChecks = repository.NewWatcherCheckRepository(database)
+
+	// Retention tasks of the history tables
+	retention = maintenance.NewRetentionLimits(
+		appConfig.DeploymentRetentionMaxAge,
+		appConfig.DeploymentRetentionMaxCount,
+	)
+	maintenanceSvc = maintenance.NewScheduler(
+		appConfig.MaintenanceInterval,
+		maintenance.DeploymentRetention(deploymentRepo, retention),
+		maintenance.ShareLinkRetention(shareLinkRepo, share.EndedRetention),
+	)
+
+	// The update check is opt-in, it queries the releases API on GitHub
+	updateChecker = nil
+	if appConfig.UpdateCheckEnabled {
+		updateChecker = update.NewChecker(Version, update.DefaultReleasesURL, appConfig.UpdateCheckInterval)
+	}
 	return nil
 }
 
+// ReconcileInterruptedDeployments cleans up deployments left unfinished by a previous run.
+// Only the long-running server calls this: CLI invocations may run alongside a deployment
+// started by another process, which must not be treated as interrupted.
+func ReconcileInterruptedDeployments() error {
+	return projectService.ReconcileInterruptedDeployments()
+}
+
 func GetProjectService() project.ProjectManager {
 	return projectService
 }
@@ -77,7 +135,60 @@ func GetGitService() *git.GitService {
 	return gitService
 }
 
+func GetMaintenanceScheduler() *maintenance.Scheduler {
+	return maintenanceSvc
+}
+
+// GetDeploymentRetention returns the limits of the deployment retention, which a reload can change
+func GetDeploymentRetention() *maintenance.RetentionLimits {
+	return retention
+}
+
+func GetShareService() *share.Service {
+	return shareService
+}
+
+// GetAPITokenService returns the tokens that authenticate requests to the JSON API
+func GetAPITokenService() *apitoken.Service {
+	return apiTokens
+}
+
+// GetWatcherCheckRepository returns where the watcher keeps the last check of each project
+func GetWatcherCheckRepository() repository.WatcherCheckRepository {
+	return watcherChecks
+}
+
+// GetMetrics returns the registry of the per-project metrics served at /metrics
+func GetMetrics() *metrics.Registry {
+	return metricsReg
+}
+
+// GetUpdateChecker returns the check for newer Oar releases, or nil when it is disabled
+func GetUpdateChecker() *update.Checker {
+	return updateChecker
+}
+
+func GetConfig() *config.Config {
+	return appConfig
+}
+
+// SetWatcherService registers the running watcher so its status can be served over HTTP
+func SetWatcherService(service *watcher.WatcherService) {
+	watcherService.Store(service)
+}
+
+// GetWatcherService returns the running watcher, or nil when the watcher is disabled
+// or the current process is not the server
+func GetWatcherService() *watcher.WatcherService {
+	return watcherService.Load()
+}
+
 // SetProjectServiceForTesting allows overriding the project service for testing purposes
 func SetProjectServiceForTesting(service project.ProjectManager) {
 	projectService = service
 }
+
+// SetAPITokenServiceForTesting allows overriding the API token service for testing purposes
+func SetAPITokenServiceForTesting(service *apitoken.Service) {
+	apiTokens = service
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Commit and BuildDate are set at build time via -ldflags, like Version
+var (
+	Commit    = ""
+	BuildDate = ""
+)
+
+// BuildInfo describes the running Oar build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// GetBuildInfo returns the version and build details of the running binary. A commit or build date not
+// set via -ldflags is taken from the VCS information Go embeds when building from a git checkout.
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
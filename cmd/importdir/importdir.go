@@ -0,0 +1,111 @@
+// Package importdir provides the import-dir command for onboarding a directory of compose stacks.
+package importdir
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/project"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdImportDir creates the import-dir command
+func NewCmdImportDir() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "import-dir <path>",
+		Short: "Create projects for the compose stacks in a directory",
+		Long: `Create a project for each subdirectory of <path> that is a git repository with a compose file.
+
+The project name is derived from the directory name, as Docker Compose does. The git URL
+comes from the origin remote, and the branch from the checked out branch. The default compose
+file and its override file are used. Stacks that are already running are adopted without
+restarting them, as with 'oar project add --adopt'.
+
+Oar clones each repository into its own workspace; the directories themselves are not changed.
+Directories that are not git repositories, have no compose file or whose name is already taken
+are skipped. Use --dry-run to see what would be imported.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runImportDir(cmd, args[0], dryRun)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without creating projects")
+
+	return cmd
+}
+
+func runImportDir(cmd *cobra.Command, root string, dryRun bool) error {
+	results, err := app.GetProjectService().ImportDirectory(root, dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if err := printImportResult(cmd, result, dryRun); err != nil {
+			return err
+		}
+	}
+
+	counts := project.CountImportResults(results)
+	imported := "Imported"
+	if dryRun {
+		imported = "Would import"
+	}
+	if err := output.FprintPlain(cmd, "\n%s: %d, skipped: %d, failed: %d", imported,
+		counts[project.DirectoryImported], counts[project.DirectorySkipped], counts[project.DirectoryFailed],
+	); err != nil {
+		return err
+	}
+
+	if failed := counts[project.DirectoryFailed]; failed > 0 {
+		return fmt.Errorf("%d of %d directories failed to import", failed, len(results))
+	}
+	return nil
+}
+
+// printImportResult prints the outcome for one directory
+func printImportResult(cmd *cobra.Command, result project.DirectoryImportResult, dryRun bool) error {
+	switch result.Status {
+	case project.DirectorySkipped:
+		return output.FprintWarning(cmd, "Skipped %s: %s", result.Dir, result.Reason)
+	case project.DirectoryFailed:
+		return output.FprintError(cmd, "Failed %s: %s", result.Dir, result.Reason)
+	}
+
+	branch := result.GitBranch
+	if branch == "" {
+		branch = "(default)"
+	}
+	details := fmt.Sprintf("%s, branch %s, %s", result.GitURL, branch, strings.Join(result.ComposeFiles, ", "))
+
+	if dryRun {
+		return output.FprintPlain(cmd, "Would import %s as '%s' (%s)", result.Dir, result.Name, details)
+	}
+
+	verb := "Imported"
+	if result.Adopted {
+		verb = "Adopted running stack"
+	}
+	if err := output.FprintSuccess(cmd, "%s %s as '%s' (%s)", verb, result.Dir, result.Name, details); err != nil {
+		return err
+	}
+	if err := output.FprintPlain(cmd, "  Project ID: %s", result.Project.ID); err != nil {
+		return err
+	}
+	for _, warning := range result.Warnings {
+		if err := output.FprintWarning(cmd, "  Warning: %s", warning); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package maintenance provides commands for database housekeeping.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdMaintenance() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Run database housekeeping",
+	}
+
+	cmd.AddCommand(NewCmdMaintenanceRun())
+	return cmd
+}
+
+func NewCmdMaintenanceRun() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Prune history beyond the configured retention and ended share links",
+		Long: `Run the maintenance tasks once, as the server does every maintenance.interval.
+
+Deployments older than retention.deployments.max_age, and those beyond the
+retention.deployments.max_count most recent of their project, are removed. The
+latest deployment of each project, its latest completed deployment and
+deployments in progress are always kept.
+
+Share links that expired or were revoked more than a week ago are removed too.
+
+  oar maintenance run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runMaintenance(cmd)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	return cmd
+}
+
+func runMaintenance(cmd *cobra.Command) error {
+	failed := 0
+	for _, result := range app.GetMaintenanceScheduler().RunAll(context.Background()) {
+		if result.Err != nil {
+			failed++
+			if err := output.FprintError(cmd, "%s: %s", result.Task, result.Err); err != nil {
+				return fmt.Errorf("failed to print output: %w", err)
+			}
+			continue
+		}
+		if err := output.FprintPlain(cmd, "%s: removed %d", result.Task, result.Removed); err != nil {
+			return fmt.Errorf("failed to print output: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d maintenance task(s) failed", failed)
+	}
+	return output.FprintSuccess(cmd, "Maintenance finished")
+}
@@ -0,0 +1,112 @@
+// Package migratedata provides the migrate-data command for relocating the Oar data directory.
+package migratedata
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/datadir"
+	"github.com/oar-cd/oar/logging"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdMigrateData creates the migrate-data command
+func NewCmdMigrateData() *cobra.Command {
+	var target, configPath string
+
+	cmd := &cobra.Command{
+		Use:   "migrate-data --to <new-data-dir>",
+		Short: "Move the data directory to a new location",
+		Long: `Move the database and project working directories to a new data directory.
+
+The working directories stored in the database are rewritten and data_dir is
+updated in the config file. Stop the Oar server first; the command refuses to
+run while the server is reachable or a deployment is in progress. Running
+projects must be redeployed afterwards so that their bind mounts use the new paths.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runMigrateData(cmd, configPath, target)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "to", "", "New data directory (must not exist or be empty)")
+	cmd.Flags().StringVarP(&configPath, "config", "c", config.ConfigPath, "Path to configuration file")
+	if err := cmd.MarkFlagRequired("to"); err != nil {
+		slog.Error("Failed to mark to flag as required", "error", err)
+		panic(fmt.Sprintf("CLI setup error: %v", err)) // This is a setup error, should panic
+	}
+
+	return cmd
+}
+
+func runMigrateData(cmd *cobra.Command, configPath, target string) error {
+	cfg, err := config.NewConfig(configPath, config.WithCLIDefaults())
+	if err != nil {
+		return fmt.Errorf("failed to initialize configuration: %w", err)
+	}
+
+	output.InitColors()
+	logLevel := cfg.LogLevel
+	if logging.LogLevel.IsSet() {
+		logLevel = logging.LogLevel.String()
+	}
+	// CLI commands log to stderr; only the server writes the log file
+	if err := logging.Setup(logging.Options{Level: logLevel, Format: cfg.LogFormat}); err != nil {
+		return fmt.Errorf("failed to initialize logging: %w", err)
+	}
+
+	if err := output.FprintPlain(cmd, "Moving data directory %s to %s", cfg.DataDir, target); err != nil {
+		return err
+	}
+
+	result, err := datadir.Migrate(datadir.Options{
+		Config:     cfg,
+		ConfigPath: configPath,
+		Target:     target,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Copied {
+		if err := output.FprintPlain(cmd, "Copied data across filesystems"); err != nil {
+			return err
+		}
+	}
+	if err := output.FprintSuccess(cmd, "Data directory moved to %s (%d project(s) updated)",
+		result.Target, result.ProjectsUpdated); err != nil {
+		return err
+	}
+
+	if result.ConfigUpdated {
+		if err := output.FprintPlain(cmd, "Updated data_dir in %s", configPath); err != nil {
+			return err
+		}
+	} else if err := output.FprintWarning(cmd, "Set data_dir to %s in your configuration", result.Target); err != nil {
+		return err
+	}
+	if os.Getenv("OAR_DATA_DIR") != "" {
+		if err := output.FprintWarning(cmd, "OAR_DATA_DIR is set and overrides the config file, update it to %s",
+			result.Target); err != nil {
+			return err
+		}
+	}
+
+	if len(result.RunningProjects) > 0 {
+		if err := output.FprintWarning(cmd, "Redeploy these projects to use the new paths: %s",
+			strings.Join(result.RunningProjects, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package notify provides commands for deployment notifications.
+package notify
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdNotify() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Manage deployment notifications",
+	}
+
+	cmd.AddCommand(NewCmdNotifyTest())
+	return cmd
+}
+
+func NewCmdNotifyTest() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Send a test notification",
+		Long: `Send a test notification to check the notification settings.
+
+Without --project, the notification goes to the webhook and email configured in
+config.yaml. With --project, it goes where the project's deployment notifications
+go, with the project's template.
+
+  oar notify test
+  oar notify test --project <project-id>`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runNotifyTest(cmd)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().String("project", "", "ID of the project whose notification settings to test")
+
+	return cmd
+}
+
+func runNotifyTest(cmd *cobra.Command) error {
+	projectID := uuid.Nil
+	if value, _ := cmd.Flags().GetString("project"); value != "" {
+		var err error
+		if projectID, err = uuid.Parse(value); err != nil {
+			return fmt.Errorf("invalid project ID '%s': must be a valid UUID", value)
+		}
+	}
+
+	if err := app.GetProjectService().SendTestNotification(projectID); err != nil {
+		return err
+	}
+	return output.FprintSuccess(cmd, "Test notification sent")
+}
@@ -4,8 +4,12 @@ package output
 import (
 	"fmt"
 	"io"
+	"maps"
+	"slices"
 	"strings"
 
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 
 	"github.com/fatih/color"
@@ -97,8 +101,21 @@ func PrintProjectDetails(project *domain.Project, short bool) (string, error) {
 	data := [][]string{
 		{"Name", project.Name},
 		{"Status", formatProjectStatus(project.Status.String())},
-		{"Git URL", project.GitURL},
-		{"Git Branch", project.GitBranch},
+	}
+	if composeName := project.ComposeProjectName(); composeName != project.Name {
+		data = slices.Insert(data, 1, []string{"Compose Project", composeName})
+	}
+	if project.UsesGit() {
+		branch := project.GitBranch
+		if project.GitBranchDetected {
+			branch += " (default branch)"
+		}
+		data = append(data, []string{"Git URL", project.GitURL}, []string{"Git Branch", branch})
+		if project.WatchURL != "" {
+			data = append(data, []string{"Watch URL", project.WatchURL})
+		}
+	} else {
+		data = append(data, []string{"Source", "Uploaded archive"})
 	}
 
 	if !short {
@@ -132,9 +149,16 @@ func PrintProjectDetails(project *domain.Project, short bool) (string, error) {
 
 		// Compose configuration
 		data = append(data,
-			[]string{"Compose Files", formatStringList(project.ComposeFiles)},
+			[]string{"Compose Files", formatComposeFiles(project)},
 		)
 
+		// Env files from the repository
+		if len(project.EnvFiles) > 0 {
+			data = append(data,
+				[]string{"Env Files", formatStringList(project.EnvFiles)},
+			)
+		}
+
 		// Environment variables
 		if len(project.Variables) > 0 {
 			data = append(data,
@@ -146,6 +170,140 @@ func PrintProjectDetails(project *domain.Project, short bool) (string, error) {
 			)
 		}
 
+		// Build arguments, and only the names of build secrets
+		if len(project.BuildArgs) > 0 {
+			args := make([]string, 0, len(project.BuildArgs))
+			for _, key := range slices.Sorted(maps.Keys(project.BuildArgs)) {
+				args = append(args, key+"="+project.BuildArgs[key])
+			}
+			data = append(data, []string{"Build Args", formatStringList(args)})
+		}
+		if len(project.BuildSecrets) > 0 {
+			data = append(data,
+				[]string{"Build Secrets", formatStringList(slices.Sorted(maps.Keys(project.BuildSecrets)))},
+			)
+		}
+
+		if len(project.ContainerLabels) > 0 {
+			labels := make([]string, 0, len(project.ContainerLabels))
+			for _, key := range slices.Sorted(maps.Keys(project.ContainerLabels)) {
+				labels = append(labels, key+"="+project.ContainerLabels[key])
+			}
+			data = append(data, []string{"Container Labels", formatStringList(labels)})
+		}
+
+		if len(project.DeployHooks) > 0 {
+			hooks := make([]string, len(project.DeployHooks))
+			for i, hook := range project.DeployHooks {
+				hooks[i] = hook.String()
+			}
+			data = append(data, []string{"Deploy Hooks", formatStringList(hooks)})
+		}
+
+		// Compose projects of earlier names that may still have containers
+		if len(project.PreviousNames) > 0 {
+			data = append(data,
+				[]string{"Previous Names", formatStringList(project.PreviousNames)},
+			)
+		}
+
+		// Prerequisite projects
+		if len(project.DependsOn) > 0 {
+			ids := make([]string, len(project.DependsOn))
+			for i, id := range project.DependsOn {
+				ids[i] = id.String()
+			}
+			data = append(data,
+				[]string{"Depends On", formatStringList(ids)},
+			)
+		}
+
+		if len(project.ExternalNetworks) > 0 {
+			data = append(data,
+				[]string{"External Networks", formatStringList(project.ExternalNetworks)},
+			)
+		}
+		if project.RegistryMirror != "" {
+			data = append(data, []string{"Registry Mirror", project.RegistryMirror})
+		}
+		if project.OfflineImages {
+			data = append(data, []string{"Images", "offline"})
+		}
+		if project.PinImageDigests {
+			pinned := "(none yet)"
+			if len(project.ImageDigests) > 0 {
+				pinned = formatStringList(project.ImageDigests)
+			}
+			data = append(data, []string{"Pinned Images", pinned})
+		}
+
+		if project.WebhookSecret != "" {
+			data = append(data, []string{"Webhook Secret", "(set)"})
+		}
+
+		// Notification routing, the webhook URL is a secret
+		if project.NotifyWebhookURL != "" {
+			data = append(data, []string{"Notify Webhook", "(set)"})
+		}
+		if project.NotifyChannel != "" {
+			data = append(data, []string{"Notify Channel", project.NotifyChannel})
+		}
+		if project.NotifyTemplate != "" {
+			data = append(data, []string{"Notify Template", project.NotifyTemplate})
+		}
+		if email := project.NotifyEmail; email != nil {
+			recipients := formatStringList(email.To)
+			if email.Host != "" {
+				recipients += fmt.Sprintf("\n(via %s)", email.Host)
+			}
+			data = append(data, []string{"Notify Email", recipients})
+		}
+
+		// What automatic deployments refresh
+		autoDeploy := "disabled"
+		if project.AutoDeployEnabled {
+			autoDeploy = "git pull"
+			if project.AutoDeploySkipGitPull {
+				autoDeploy = "current commit"
+			}
+			if project.AutoDeployPullImages {
+				autoDeploy += ", image pull"
+			}
+		}
+		data = append(data,
+			[]string{"Auto Deploy", autoDeploy},
+		)
+		if project.WatcherDisabled {
+			data = append(data, []string{"Watcher", "disabled"})
+		}
+
+		// What up shows and builds, listed only when it differs from the quiet default
+		var upSettings []string
+		if project.VerboseBuild {
+			upSettings = append(upSettings, "build output")
+		}
+		if project.VerbosePull {
+			upSettings = append(upSettings, "pull progress")
+		}
+		if project.BuildOnDeploy {
+			upSettings = append(upSettings, "rebuild images")
+		}
+		if len(upSettings) > 0 {
+			data = append(data, []string{"Deploy Output", strings.Join(upSettings, ", ")})
+		}
+
+		// Volume mount initialization
+		volumeInit := "enabled"
+		if project.VolumeInitPhase != "" {
+			volumeInit += " (" + project.VolumeInitPhase.String() + ")"
+		}
+		if project.SkipVolumeInit {
+			volumeInit = "skipped"
+		}
+		data = append(data,
+			[]string{"Volume Init", volumeInit},
+		)
+
 		// Timestamps
 		data = append(data,
 			[][]string{
@@ -231,12 +389,32 @@ func formatCommitDetails(commit string) string {
 		return "(no commits)"
 	}
 	if len(commit) > 8 {
-		return fmt.Sprintf("%s (%s)", commit[:8], commit)
+		return fmt.Sprintf("%s (%s)", domain.ShortCommit(commit), commit)
 	}
 	return commit
 }
 
+// formatPreviousCommit formats the commit a deployment replaced
+func formatPreviousCommit(commit string) string {
+	if commit == "" {
+		return "(first deployment)"
+	}
+	return formatCommitDetails(commit)
+}
+
 // formatStringList formats a list of strings with proper line breaks and numbering
+// formatComposeFiles lists the compose files of a project, marking the disabled ones
+func formatComposeFiles(project *domain.Project) string {
+	files := make([]string, len(project.ComposeFiles))
+	for i, file := range project.ComposeFiles {
+		files[i] = file
+		if slices.Contains(project.DisabledComposeFiles, file) {
+			files[i] += " (disabled)"
+		}
+	}
+	return formatStringList(files)
+}
+
 func formatStringList(items []string) string {
 	if len(items) == 0 {
 		return "(none)"
@@ -284,6 +462,10 @@ func PrintProjectList(projects []*domain.Project) (string, error) {
 		// Format branch for display
 		branch := project.GitBranch
 
+		if !project.UsesGit() {
+			gitURL, branch = "(archive)", "-"
+		}
+
 		data = append(data, []string{
 			project.ID.String(),
 			project.Name,
@@ -304,6 +486,57 @@ func PrintProjectList(projects []*domain.Project) (string, error) {
 	return table, nil
 }
 
+// PrintStatusTable prints the live status of each project, with its uptime and how many of its
+// containers are running. Projects whose status could not be read show why in place of a status.
+func PrintStatusTable(
+	projects []*domain.Project,
+	statuses map[uuid.UUID]*docker.ComposeStatus,
+	errs map[uuid.UUID]error,
+) (string, error) {
+	if len(projects) == 0 {
+		return PrintMessage(Plain, "No projects found."), nil
+	}
+
+	var data [][]string
+	for _, project := range projects {
+		status, ok := statuses[project.ID]
+		if !ok {
+			state := "unknown"
+			if docker.IsTimeout(errs[project.ID]) {
+				state = "unavailable"
+			}
+			data = append(data, []string{project.Name, formatProjectStatus(state), "-", "-"})
+			continue
+		}
+
+		running := 0
+		for _, container := range status.Containers {
+			if container.State == "running" {
+				running++
+			}
+		}
+		uptime := status.Uptime
+		if uptime == "" {
+			uptime = "-"
+		}
+		data = append(data, []string{
+			project.Name,
+			formatProjectStatus(status.Status.String()),
+			uptime,
+			fmt.Sprintf("%d/%d", running, len(status.Containers)),
+		})
+	}
+
+	table, err := PrintTable([]string{"Name", "Status", "Uptime", "Containers"}, data)
+	if err != nil {
+		return "", fmt.Errorf("printing status table: %w", err)
+	}
+	return table, nil
+}
+
+// ClearScreen moves the cursor to the top left corner of the terminal and clears the screen
+const ClearScreen = "\x1b[H\x1b[2J"
+
 func PrintDeploymentList(deployments []*domain.Deployment, projectName string) (string, error) {
 	if len(deployments) == 0 {
 		return PrintMessage(Plain, "No deployments found for project '%s'.", projectName), nil
@@ -315,14 +548,18 @@ func PrintDeploymentList(deployments []*domain.Deployment, projectName string) (
 		"Commit",
 		"Created At",
 		"Updated At",
+		"Notes",
 	}
 	var data [][]string
 	for _, deployment := range deployments {
 		// Format status with color coding
 		statusStr := formatDeploymentStatus(deployment.Status.String())
 
-		// Format commit hash (8 chars like git)
+		// Format commit hash (8 chars like git), after the commit it replaced
 		commit := formatCommitHash(deployment.CommitHash)
+		if deployment.PreviousCommit != "" && deployment.PreviousCommit != deployment.CommitHash {
+			commit = formatCommitHash(deployment.PreviousCommit) + " → " + commit
+		}
 
 		// Format timestamps
 		createdAt := deployment.CreatedAt.Format("2006-01-02 15:04:05")
@@ -334,6 +571,7 @@ func PrintDeploymentList(deployments []*domain.Deployment, projectName string) (
 			commit,
 			createdAt,
 			updatedAt,
+			formatDeploymentNotes(deployment),
 		})
 	}
 
@@ -345,6 +583,58 @@ func PrintDeploymentList(deployments []*domain.Deployment, projectName string) (
 	return table, nil
 }
 
+// PrintDeploymentDetails formats a single deployment. The command line is printed below the table
+// so that it can be copied as is.
+func PrintDeploymentDetails(deployment *domain.Deployment, projectName string) (string, error) {
+	data := [][]string{
+		{"Deployment ID", deployment.ID.String()},
+		{"Project", projectName},
+		{"Status", formatDeploymentStatus(deployment.Status.String())},
+		{"Commit", formatCommitDetails(deployment.CommitHash)},
+		{"Previous Commit", formatPreviousCommit(deployment.PreviousCommit)},
+		{"Created At", deployment.CreatedAt.Format("2006-01-02 15:04:05")},
+		{"Updated At", deployment.UpdatedAt.Format("2006-01-02 15:04:05")},
+		{"Docker Version", formatVersion(deployment.DockerVersion)},
+		{"Compose Version", formatVersion(deployment.ComposeVersion)},
+		{"Labels", formatLabels(deployment.Labels)},
+		{"Notes", formatNotes(deployment.Notes)},
+	}
+
+	table, err := PrintTable([]string{}, data)
+	if err != nil {
+		return "", fmt.Errorf("printing deployment details table: %w", err)
+	}
+
+	commandLine := deployment.CommandLine
+	if commandLine == "" {
+		commandLine = "(not recorded)"
+	}
+	details := fmt.Sprintf("%s\nCommand line:\n%s\n", table, commandLine)
+	if deployment.Summary != "" {
+		details += "\nChanges:\n" + deployment.Summary + "\n"
+	}
+	if len(deployment.ImageDigests) > 0 {
+		details += "\nPinned images:\n" + strings.Join(deployment.ImageDigests, "\n") + "\n"
+	}
+	if len(deployment.FailedServices) > 0 {
+		details += "\nFailed services:\n" + formatServiceFailures(deployment.FailedServices)
+	}
+	return details, nil
+}
+
+// formatServiceFailures formats the services a failed deployment could not bring up, each followed
+// by the last lines it logged
+func formatServiceFailures(failures []domain.ServiceFailure) string {
+	var b strings.Builder
+	for _, failure := range failures {
+		b.WriteString(failure.String() + "\n")
+		for _, line := range failure.LastLogs {
+			b.WriteString("    " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
 // formatProjectStatus applies color coding to project status
 func formatProjectStatus(status string) string {
 	// If colors are not initialized, return plain status
@@ -357,8 +647,10 @@ func formatProjectStatus(status string) string {
 		return maybeColorize(Success, "%s", status)
 	case "stopped":
 		return maybeColorize(Warning, "%s", status)
-	case "error":
+	case "error", "failed":
 		return maybeColorize(Error, "%s", status)
+	case "degraded", "unavailable":
+		return maybeColorize(Warning, "%s", status)
 	default:
 		return maybeColorize(Plain, "%s", status)
 	}
@@ -383,6 +675,43 @@ func formatDeploymentStatus(status string) string {
 	}
 }
 
+// formatDeploymentNotes formats the labels and the first line of the notes of a deployment for a table
+func formatDeploymentNotes(deployment *domain.Deployment) string {
+	notes, _, _ := strings.Cut(deployment.Notes, "\n")
+	if len(deployment.Labels) > 0 {
+		notes = strings.TrimSpace("[" + strings.Join(deployment.Labels, ", ") + "] " + notes)
+	}
+	if notes == "" {
+		return "-"
+	}
+	return truncateString(notes, 40)
+}
+
+// formatLabels formats a list of labels on one line
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return "(none)"
+	}
+	return strings.Join(labels, ", ")
+}
+
+// formatVersion formats a tool version recorded with a deployment. Deployments from before versions
+// were recorded, or whose versions could not be queried, have none.
+func formatVersion(version string) string {
+	if version == "" {
+		return "(not recorded)"
+	}
+	return version
+}
+
+// formatNotes formats free-form notes, which may span several lines
+func formatNotes(notes string) string {
+	if notes == "" {
+		return "(none)"
+	}
+	return notes
+}
+
 // truncateString truncates a string to maxLength with "..." if needed
 func truncateString(s string, maxLength int) string {
 	if len(s) <= maxLength {
@@ -396,10 +725,7 @@ func formatCommitHash(commit string) string {
 	if commit == "" {
 		return "-"
 	}
-	if len(commit) > 8 {
-		return commit[:8]
-	}
-	return commit
+	return domain.ShortCommit(commit)
 }
 
 // CLI output helpers
@@ -2,12 +2,13 @@ package project
 
 import (
 	"fmt"
-	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 	"github.com/spf13/cobra"
 )
@@ -15,14 +16,18 @@ import (
 func NewCmdProjectAdd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add",
-		Short: "Add a Git repository as a managed project",
+		Short: "Add a Git repository or an archive as a managed project",
 		Long: `Add a new Docker Compose project from a Git repository.
 Oar will clone the repository and manage it with Docker Compose.
+Projects can also be added from a tar archive, for hosts that cannot reach a repository.
 
 Basic usage:
   # Use default branch
   oar project add --git-url https://github.com/user/repo.git --compose-file compose.yml
 
+  # Use the configured default compose files, or the file Docker Compose would pick
+  oar project add --git-url https://github.com/user/repo.git
+
   # Use specific branch
   oar project add --git-url https://github.com/user/repo.git \
                   --branch feature/new-feature --compose-file compose.yml
@@ -38,6 +43,14 @@ Authentication examples:
                   --git-auth ssh --git-username git --git-ssh-key-file ~/.ssh/id_rsa \
                   --branch main --compose-file compose.yml
 
+  # Run database migrations in a new container of the migrate service before each deployment
+  oar project add --git-url https://github.com/user/repo.git --compose-file compose.yml \
+                  --deploy-hook 'pre-deploy:migrate:./manage.py migrate'
+
+  # Deploy from a read-only mirror, detecting new commits in the canonical repository
+  oar project add --git-url https://mirror.example.com/user/repo.git \
+                  --watch-url https://github.com/user/repo.git --compose-file compose.yml
+
 Environment variables:
   # Individual variables
   oar project add --git-url https://github.com/user/repo.git \
@@ -46,7 +59,20 @@ Environment variables:
 
   # From file
   oar project add --git-url https://github.com/user/repo.git \
-                  --compose-file compose.yml --env-file .env.production`,
+                  --compose-file compose.yml --env-file .env.production
+
+  # From env files in the repository (later files override earlier ones)
+  oar project add --git-url https://github.com/user/repo.git \
+                  --compose-file compose.yml --repo-env-file env/common.env --repo-env-file env/prod.env
+
+Deploying from an archive:
+  # Extract a tar or tar.gz archive instead of cloning; the project is not watched for changes
+  oar project add --archive release.tar.gz --name myapp --compose-file compose.yml
+
+Adopting a running stack:
+  # Manage a stack started with docker compose -p myapp, without restarting it
+  oar project add --git-url https://github.com/user/repo.git \
+                  --compose-file compose.yml --adopt myapp`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := runProjectAdd(cmd)
 			if err != nil {
@@ -59,10 +85,14 @@ Environment variables:
 
 	// Basic flags
 	cmd.Flags().StringP("git-url", "u", "", "Git repository URL")
+	cmd.Flags().
+		String("archive", "", "Tar archive, optionally gzip-compressed, to extract instead of cloning a repository")
 	cmd.Flags().StringP("name", "n", "", "Custom project name (auto-detected if not specified)")
 	cmd.Flags().StringP("branch", "b", "", "Git branch to use (uses repository default if not specified)")
 	cmd.Flags().
-		StringArrayP("compose-file", "f", nil, `Docker Compose file path, relative to repository root. Can be used multiple times: --compose-file compose.yml --compose-file docker-compose.override.yml`)
+		String("watch-url", "", "Repository to detect new commits in, when --git-url is a read-only mirror of it")
+	cmd.Flags().
+		StringArrayP("compose-file", "f", nil, `Docker Compose file path, relative to repository root. Can be used multiple times: --compose-file compose.yml --compose-file docker-compose.override.yml. Defaults to compose.default_files from the configuration, or else the file Docker Compose would pick`)
 
 	// Git authentication flags
 	cmd.Flags().String("git-auth", "", "Git authentication method: http, ssh")
@@ -74,14 +104,64 @@ Environment variables:
 	cmd.Flags().
 		StringArray("env", nil, `Environment variable in KEY=value format. Can be used multiple times: --env KEY1=val1 --env KEY2=val2`)
 	cmd.Flags().String("env-file", "", "Path to environment file (.env format)")
+	cmd.Flags().
+		StringArray("build-arg", nil, "Build argument in KEY=value format, passed to docker compose build (repeatable)")
+	cmd.Flags().
+		StringArray("build-secret", nil, "Build secret in NAME=value format, stored encrypted (repeatable)")
+	cmd.Flags().
+		StringArray("container-label", nil, "Label in key=value format set on every container (repeatable)")
+	cmd.Flags().
+		StringArray("deploy-hook", nil, "Hook as phase:service[:command], phase pre-deploy or post-deploy (repeatable)")
+	cmd.Flags().
+		StringArray("repo-env-file", nil, `Env file path, relative to repository root, applied after the repository .env and before --env variables. Can be used multiple times`)
 
-	if err := cmd.MarkFlagRequired("git-url"); err != nil {
-		slog.Error("Failed to mark git-url flag as required", "error", err)
-		panic(fmt.Sprintf("CLI setup error: %v", err)) // This is a setup error, should panic
-	}
-	if err := cmd.MarkFlagRequired("compose-file"); err != nil {
-		slog.Error("Failed to mark compose-file flag as required", "error", err)
-		panic(fmt.Sprintf("CLI setup error: %v", err)) // This is a setup error, should panic
+	// Deployment flags
+	cmd.Flags().
+		Bool("skip-volume-init", false, "Do not fix volume mount ownership before starting services (e.g. for NFS or read-only mounts)")
+	cmd.Flags().
+		String("volume-init-phase", "after-create", "When to fix volume mount ownership: after-create or before-create (before replacing containers)")
+	cmd.Flags().
+		StringArray("depends-on", nil, "ID of a project that must be running before this project is deployed (repeatable)")
+	cmd.Flags().
+		StringArray("external-network", nil, "Docker network to create before deployment if missing, for sharing with other projects (repeatable)")
+	cmd.Flags().
+		String("registry-mirror", "", "Registry to pull Docker Hub images from, as host[:port][/path], instead of the configured default")
+	cmd.Flags().
+		Bool("offline-images", false, "Never pull images; deployments fail if an image is not present locally")
+	cmd.Flags().
+		Bool("pin-image-digests", false, "Deploy images pinned to the digests their tags had on first deployment")
+	cmd.Flags().Bool("verbose-build", false, "Show the output of image builds on deployments")
+	cmd.Flags().Bool("verbose-pull", false, "Show the progress of image pulls on deployments")
+	cmd.Flags().Bool("build", false, "Rebuild images on every deployment instead of only missing ones")
+	cmd.Flags().
+		Bool("auto-deploy-pull-images", false, "Pull images before bringing up services on automatic deployments")
+	cmd.Flags().
+		Bool("auto-deploy-skip-git-pull", false, "Redeploy the current commit on automatic deployments instead of pulling new commits")
+	cmd.Flags().
+		String("webhook-secret", "", "Secret push webhooks must be signed with (X-Hub-Signature-256), stored encrypted")
+	cmd.Flags().
+		String("adopt", "", "Compose project name of a stack already running on the host to manage without restarting it")
+
+	// Notification flags
+	cmd.Flags().
+		String("notify-url", "", "Webhook for deployment notifications (uses the configured default if not specified)")
+	cmd.Flags().String("notify-channel", "", "Channel to post deployment notifications to")
+	cmd.Flags().
+		String("notify-template", "", "Go template for deployment notifications, e.g. '{{.ProjectName}} {{.Status}}'")
+	cmd.Flags().
+		StringArray("notify-email", nil, "Email address to send deployment notifications to (repeatable)")
+	cmd.Flags().
+		String("notify-smtp-host", "", "SMTP server for this project's emails (uses the configured server if not specified)")
+	cmd.Flags().Int("notify-smtp-port", 0, "SMTP server port (defaults to 587 for starttls, 465 for tls)")
+	cmd.Flags().String("notify-smtp-username", "", "SMTP username")
+	cmd.Flags().String("notify-smtp-password", "", "SMTP password, stored encrypted")
+	cmd.Flags().String("notify-smtp-from", "", "Sender address of this project's emails")
+	cmd.Flags().String("notify-smtp-tls", "", "SMTP connection security: starttls (default), tls, none")
+
+	cmd.MarkFlagsOneRequired("git-url", "archive")
+	cmd.MarkFlagsMutuallyExclusive("git-url", "archive")
+	for _, gitFlag := range []string{"branch", "watch-url", "git-auth", "adopt", "auto-deploy-skip-git-pull"} {
+		cmd.MarkFlagsMutuallyExclusive("archive", gitFlag)
 	}
 	return cmd
 }
@@ -91,6 +171,8 @@ func runProjectAdd(cmd *cobra.Command) error {
 	// Get basic flag values
 	gitURL, _ := cmd.Flags().GetString("git-url")
 	name, _ := cmd.Flags().GetString("name")
+	adopt, _ := cmd.Flags().GetString("adopt")
+	archive, _ := cmd.Flags().GetString("archive")
 	branch, _ := cmd.Flags().GetString("branch")
 	composeFiles, _ := cmd.Flags().GetStringArray("compose-file")
 
@@ -106,15 +188,85 @@ func runProjectAdd(cmd *cobra.Command) error {
 		return fmt.Errorf("invalid environment variables: %w", err)
 	}
 
+	buildArgs, err := parseKeyValueFlag(cmd, "build-arg")
+	if err != nil {
+		return err
+	}
+	buildSecrets, err := parseKeyValueFlag(cmd, "build-secret")
+	if err != nil {
+		return err
+	}
+	containerLabels, err := parseKeyValueFlag(cmd, "container-label")
+	if err != nil {
+		return err
+	}
+	hookFlags, _ := cmd.Flags().GetStringArray("deploy-hook")
+	var deployHooks []domain.DeployHook
+	for _, flag := range hookFlags {
+		hook, err := domain.ParseDeployHook(flag)
+		if err != nil {
+			return err
+		}
+		deployHooks = append(deployHooks, hook)
+	}
+
+	// An adopted stack keeps its compose project name unless a name is given
+	if adopt != "" && name == "" {
+		name = adopt
+	}
+
+	// An archive project is named after its file unless a name is given
+	if archive != "" && name == "" {
+		name = archiveName(archive)
+	}
+
 	// Create project struct from CLI input
 	project := domain.NewProject(name, gitURL, composeFiles, variables)
 	project.GitBranch = branch
+	project.WatchURL, _ = cmd.Flags().GetString("watch-url")
 	project.GitAuth = gitAuth
+	project.BuildArgs = buildArgs
+	project.BuildSecrets = buildSecrets
+	project.ContainerLabels = containerLabels
+	project.DeployHooks = deployHooks
+	project.EnvFiles, _ = cmd.Flags().GetStringArray("repo-env-file")
+	project.SkipVolumeInit, _ = cmd.Flags().GetBool("skip-volume-init")
+	volumeInitPhase, _ := cmd.Flags().GetString("volume-init-phase")
+	project.VolumeInitPhase = domain.VolumeInitPhase(volumeInitPhase)
+	project.ExternalNetworks, _ = cmd.Flags().GetStringArray("external-network")
+	project.RegistryMirror, _ = cmd.Flags().GetString("registry-mirror")
+	project.OfflineImages, _ = cmd.Flags().GetBool("offline-images")
+	project.PinImageDigests, _ = cmd.Flags().GetBool("pin-image-digests")
+	project.VerboseBuild, _ = cmd.Flags().GetBool("verbose-build")
+	project.VerbosePull, _ = cmd.Flags().GetBool("verbose-pull")
+	project.BuildOnDeploy, _ = cmd.Flags().GetBool("build")
+	project.AutoDeployPullImages, _ = cmd.Flags().GetBool("auto-deploy-pull-images")
+	project.AutoDeploySkipGitPull, _ = cmd.Flags().GetBool("auto-deploy-skip-git-pull")
+	project.WebhookSecret, _ = cmd.Flags().GetString("webhook-secret")
+	project.NotifyWebhookURL, _ = cmd.Flags().GetString("notify-url")
+	project.NotifyChannel, _ = cmd.Flags().GetString("notify-channel")
+	project.NotifyTemplate, _ = cmd.Flags().GetString("notify-template")
+	project.NotifyEmail = buildNotifyEmailFromFlags(cmd)
+	dependsOn, _ := cmd.Flags().GetStringArray("depends-on")
+	if project.DependsOn, err = parseProjectIDs(dependsOn); err != nil {
+		return err
+	}
 
 	// Call service
-	createdProject, err := app.GetProjectService().Create(&project)
+	var createdProject *domain.Project
+	var warnings []string
+	source := gitURL
+	switch {
+	case archive != "":
+		source = archive
+		createdProject, err = createFromArchive(&project, archive)
+	case adopt != "":
+		createdProject, warnings, err = app.GetProjectService().ImportExisting(&project, adopt)
+	default:
+		createdProject, err = createWithProgress(cmd, &project)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create project from %s: %w", gitURL, err)
+		return fmt.Errorf("failed to create project from %s: %w", source, err)
 	}
 
 	// Print success output
@@ -127,9 +279,77 @@ func runProjectAdd(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to print output: %w", err)
 	}
 
+	for _, warning := range warnings {
+		if err := output.FprintWarning(cmd, "Warning: %s", warning); err != nil {
+			return fmt.Errorf("failed to print output: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// createWithProgress creates the project, printing each step and the progress of the clone as it happens
+func createWithProgress(cmd *cobra.Command, project *domain.Project) (*domain.Project, error) {
+	outputChan := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range outputChan {
+			kind := output.Plain
+			switch msg.Type {
+			case "success":
+				kind = output.Success
+			case "stderr":
+				kind = output.Warning
+			}
+			if err := output.FprintCmd(cmd, kind, "%s", msg.Content); err != nil {
+				return
+			}
+		}
+	}()
+
+	createdProject, err := app.GetProjectService().CreateStreaming(project, outputChan)
+	close(outputChan)
+	<-done
+
+	return createdProject, err
+}
+
+// createFromArchive creates the project from the archive file at path
+func createFromArchive(project *domain.Project, path string) (*domain.Project, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return app.GetProjectService().CreateFromArchive(project, file)
+}
+
+// archiveName returns the file name of an archive without its extensions, e.g. myapp for myapp.tar.gz
+func archiveName(path string) string {
+	name := filepath.Base(path)
+	for _, ext := range []string{".tgz", ".gz", ".tar"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// buildNotifyEmailFromFlags constructs the project's email notification settings from command flags
+func buildNotifyEmailFromFlags(cmd *cobra.Command) *domain.EmailNotifyConfig {
+	email := &domain.EmailNotifyConfig{}
+	email.To, _ = cmd.Flags().GetStringArray("notify-email")
+	email.Host, _ = cmd.Flags().GetString("notify-smtp-host")
+	email.Port, _ = cmd.Flags().GetInt("notify-smtp-port")
+	email.Username, _ = cmd.Flags().GetString("notify-smtp-username")
+	email.Password, _ = cmd.Flags().GetString("notify-smtp-password")
+	email.From, _ = cmd.Flags().GetString("notify-smtp-from")
+	email.TLS, _ = cmd.Flags().GetString("notify-smtp-tls")
+	if len(email.To) == 0 && email.Host == "" {
+		return nil
+	}
+	return email
+}
+
 // buildGitAuthFromFlags constructs GitAuthConfig from command flags
 func buildGitAuthFromFlags(cmd *cobra.Command) (*domain.GitAuthConfig, error) {
 	authMethod, _ := cmd.Flags().GetString("git-auth")
@@ -214,6 +434,23 @@ func buildVariablesFromFlags(cmd *cobra.Command) ([]string, error) {
 	return variables, nil
 }
 
+// parseKeyValueFlag reads a repeatable KEY=value flag into a map
+func parseKeyValueFlag(cmd *cobra.Command, name string) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray(name)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --%s %q, expected KEY=value", name, value)
+		}
+		parsed[key] = val
+	}
+	return parsed, nil
+}
+
 // readEnvFile reads environment variables from a .env file
 func readEnvFile(filename string) ([]string, error) {
 	content, err := os.ReadFile(filename)
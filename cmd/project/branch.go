@@ -0,0 +1,105 @@
+package project
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectBranch() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "branch <project-id>",
+		Short: "Show the branch of a project or detect its default branch again",
+		Long: `Show the Git branch a project is deployed from. With --redetect, the default branch of
+the repository is detected again, e.g. after it was renamed from master to main, and the
+project switches to it after confirmation. The new branch is used from the next deployment.
+
+Examples:
+  oar project branch <project-id>
+  oar project branch <project-id> --redetect
+  oar project branch <project-id> --redetect --confirm`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectBranch(cmd, args)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().Bool("redetect", false, "Detect the default branch of the repository again")
+	cmd.Flags().BoolP("confirm", "y", false, "Skip confirmation prompt and switch to the detected branch")
+	return cmd
+}
+
+func runProjectBranch(cmd *cobra.Command, args []string) error {
+	projectID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+	}
+	redetect, _ := cmd.Flags().GetBool("redetect")
+	skipConfirmation, _ := cmd.Flags().GetBool("confirm")
+
+	projectService := app.GetProjectService()
+	project, err := projectService.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to find project %s: %w", projectID, err)
+	}
+
+	if !redetect {
+		if project.GitBranchDetected {
+			return output.FprintPlain(cmd, "%s (default branch)", project.GitBranch)
+		}
+		return output.FprintPlain(cmd, "%s", project.GitBranch)
+	}
+
+	// Set when the switch to the detected branch is declined at the prompt
+	declined := false
+	var confirm func(current, detected string) bool
+	if !skipConfirmation {
+		confirm = func(current, detected string) bool {
+			declined = !promptBranchSwitch(cmd, current, detected)
+			return !declined
+		}
+	}
+	detected, err := projectService.RedetectDefaultBranch(projectID, confirm)
+	if err != nil {
+		return fmt.Errorf("failed to detect default branch of project %s: %w", project.Name, err)
+	}
+
+	switch {
+	case detected == project.GitBranch:
+		return output.FprintPlain(cmd, "Project '%s' already uses the default branch %s", project.Name, detected)
+	case declined:
+		return output.FprintPlain(cmd, "Branch of project '%s' left at %s", project.Name, project.GitBranch)
+	}
+	if err := output.FprintSuccess(cmd, "Project '%s' switched from branch %s to the default branch %s",
+		project.Name, project.GitBranch, detected); err != nil {
+		return err
+	}
+	return output.FprintPlain(cmd, "Redeploy the project to apply the change")
+}
+
+func promptBranchSwitch(cmd *cobra.Command, current, detected string) bool {
+	if err := output.FprintWarning(cmd, "The default branch is now %s, switch from %s? [y/N]: ",
+		detected, current); err != nil {
+		return false
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
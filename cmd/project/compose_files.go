@@ -0,0 +1,90 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectComposeFiles() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "compose-files <project-id>",
+		Short: "List or switch the compose files of a project",
+		Long: `List the compose files of a project and whether Compose commands use them, or switch
+files on and off without changing the list. A disabled file, e.g. compose.debug.yaml, stays
+listed for switching back on. At least one file must stay enabled.
+
+Examples:
+  oar project compose-files <project-id>
+  oar project compose-files <project-id> --enable compose.debug.yaml
+  oar project compose-files <project-id> --disable compose.override.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectComposeFiles(cmd, args)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringArray("enable", nil, "Compose file to use in Compose commands (can be repeated)")
+	cmd.Flags().StringArray("disable", nil, "Compose file to leave out of Compose commands (can be repeated)")
+	return cmd
+}
+
+func runProjectComposeFiles(cmd *cobra.Command, args []string) error {
+	projectID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+	}
+	enable, _ := cmd.Flags().GetStringArray("enable")
+	disable, _ := cmd.Flags().GetStringArray("disable")
+
+	projectService := app.GetProjectService()
+	project, err := projectService.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to find project %s: %w", projectID, err)
+	}
+
+	changed := len(enable) > 0 || len(disable) > 0
+	if changed {
+		for _, file := range append(slices.Clone(enable), disable...) {
+			if !slices.Contains(project.ComposeFiles, file) {
+				return fmt.Errorf("compose file %s is not a compose file of project %s", file, project.Name)
+			}
+			if slices.Contains(enable, file) && slices.Contains(disable, file) {
+				return fmt.Errorf("compose file %s cannot be both enabled and disabled", file)
+			}
+		}
+		project.DisabledComposeFiles = slices.DeleteFunc(project.DisabledComposeFiles, func(file string) bool {
+			return slices.Contains(enable, file)
+		})
+		project.DisabledComposeFiles = append(project.DisabledComposeFiles, disable...)
+		if err := projectService.Update(project); err != nil {
+			return fmt.Errorf("failed to update project %s: %w", project.Name, err)
+		}
+		if err := output.FprintSuccess(cmd, "Compose files of project '%s' updated", project.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range project.ComposeFiles {
+		state := "enabled"
+		if slices.Contains(project.DisabledComposeFiles, file) {
+			state = "disabled"
+		}
+		if err := output.FprintPlain(cmd, "  %s (%s)", file, state); err != nil {
+			return err
+		}
+	}
+	if changed {
+		return output.FprintPlain(cmd, "Redeploy the project to apply the change")
+	}
+	return nil
+}
@@ -17,13 +17,26 @@ func NewCmdProjectConfig() *cobra.Command {
 		Use:   "config <project-id>",
 		Short: "Show the Docker Compose configuration for a project",
 		Long: `Display the generated Docker Compose configuration for a project.
-This shows the final configuration after resolving all variables and includes.`,
+This shows the final configuration after resolving all variables and includes.
+
+Compose files are merged in the order they are listed, and the compose override of the
+project is merged last, so it always wins. Use --sources to show the merge order and the
+files that declare each service, network and volume.
+
+Use --services, --volumes or --images to list only the declared services, named volumes
+or images, one per line. The project does not need to be running.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runProjectConfig(cmd, args)
 		},
 	}
 
+	cmd.Flags().Bool("services", false, "List the declared services")
+	cmd.Flags().Bool("volumes", false, "List the declared named volumes")
+	cmd.Flags().Bool("images", false, "List the images used by the services")
+	cmd.Flags().Bool("sources", false, "Annotate the configuration with the files it was merged from")
+	cmd.MarkFlagsMutuallyExclusive("services", "volumes", "images", "sources")
+
 	return cmd
 }
 
@@ -37,6 +50,32 @@ func runProjectConfig(cmd *cobra.Command, args []string) error {
 	// Get services
 	projectService := app.GetProjectService()
 
+	// List only the names selected by a flag, if any
+	var list func(uuid.UUID) ([]string, error)
+	services, _ := cmd.Flags().GetBool("services")
+	volumes, _ := cmd.Flags().GetBool("volumes")
+	images, _ := cmd.Flags().GetBool("images")
+	switch {
+	case services:
+		list = projectService.ListServices
+	case volumes:
+		list = projectService.ListDeclaredVolumes
+	case images:
+		list = projectService.ListImages
+	}
+	if list != nil {
+		names, err := list(projectID)
+		if err != nil {
+			return fmt.Errorf("failed to get project configuration: %w", err)
+		}
+		for _, name := range names {
+			if err := output.FprintPlain(cmd, "%s", name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Fetch project details for display
 	project, err := projectService.Get(projectID)
 	if err != nil {
@@ -48,8 +87,12 @@ func runProjectConfig(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get configuration
-	config, stderr, err := projectService.GetConfig(projectID)
+	// Get configuration, annotated with its sources if requested
+	getConfig := projectService.GetConfig
+	if sources, _ := cmd.Flags().GetBool("sources"); sources {
+		getConfig = projectService.GetMergedConfig
+	}
+	config, stderr, err := getConfig(projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project configuration: %w", err)
 	}
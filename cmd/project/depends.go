@@ -0,0 +1,80 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectDepends() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "depends <project-id> [<prerequisite-id>...]",
+		Short: "Set the projects a project depends on",
+		Long: `Set the projects that must be running before a project is deployed, for example a shared
+proxy or network. This replaces the current list; without prerequisite IDs, the list is cleared.
+
+Deploying several projects at once deploys each after its prerequisites, and
+--ensure-dependencies also deploys prerequisites that are not running.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectDepends(cmd, args)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+	return cmd
+}
+
+func runProjectDepends(cmd *cobra.Command, args []string) error {
+	projectIDs, err := parseProjectIDs(args)
+	if err != nil {
+		return err
+	}
+
+	projectService := app.GetProjectService()
+	project, err := projectService.Get(projectIDs[0])
+	if err != nil {
+		return fmt.Errorf("failed to find project %s: %w", projectIDs[0], err)
+	}
+
+	project.DependsOn = projectIDs[1:]
+	if err := projectService.Update(project); err != nil {
+		return fmt.Errorf("failed to update project %s: %w", project.Name, err)
+	}
+
+	if len(project.DependsOn) == 0 {
+		return output.FprintSuccess(cmd, "Project '%s' has no prerequisites", project.Name)
+	}
+	if err := output.FprintSuccess(cmd, "Project '%s' depends on:", project.Name); err != nil {
+		return err
+	}
+	for _, id := range project.DependsOn {
+		prerequisite, err := projectService.Get(id)
+		if err != nil {
+			return fmt.Errorf("failed to find project %s: %w", id, err)
+		}
+		if err := output.FprintPlain(cmd, "  %s (%s)", prerequisite.Name, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProjectIDs parses project IDs given on the command line
+func parseProjectIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid project ID '%s': must be a valid UUID", value)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
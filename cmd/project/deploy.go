@@ -2,20 +2,38 @@ package project
 
 import (
 	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	oarproject "github.com/oar-cd/oar/project"
 	"github.com/spf13/cobra"
 )
 
 func NewCmdProjectDeploy() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "deploy <project-id>",
-		Short: "Deploy or update a project",
+		Use:   "deploy <project-id>...",
+		Short: "Deploy or update projects",
 		Long: `Pull the latest changes from Git and deploy the project using Docker Compose.
-This will update running containers with the latest configuration.`,
-		Args: cobra.ExactArgs(1),
+This will update running containers with the latest configuration.
+
+Use --service to deploy only some services. Compose also starts the services they depend on,
+unless --no-deps is set, e.g. to redeploy an app without restarting its database.
+Use --notes and --label to annotate the deployment, e.g. with the incident it fixes.
+Use --verbose-build and --verbose-pull to show the image build and pull output that is kept quiet
+by default, e.g. to debug a build, and --build to rebuild images that already exist.
+
+A renamed project is deployed under its new name, and the containers of its old name are left
+running. The deployment warns about them; --remove-previous-stacks removes them instead.
+
+Several projects are deployed one after another, each after the projects it depends on. A project
+is skipped while one of its prerequisites is not running. Use --ensure-dependencies to also deploy
+prerequisites that are not running.`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := runProjectDeploy(cmd, args)
 			if err != nil {
@@ -27,33 +45,123 @@ This will update running containers with the latest configuration.`,
 	}
 
 	cmd.Flags().Bool("pull", true, "Pull latest Git changes before deployment")
+	cmd.Flags().StringArray("service", nil, "Deploy only this service and its dependencies (repeatable)")
+	cmd.Flags().Bool("no-deps", false, "With --service, leave the services it depends on untouched")
+	cmd.Flags().String("notes", "", "Notes to store with the deployment")
+	cmd.Flags().StringArray("label", nil, "Label to store with the deployment (repeatable)")
+	cmd.Flags().Bool("ensure-dependencies", false, "Deploy prerequisite projects that are not running first")
+	cmd.Flags().Bool("remove-previous-stacks", false,
+		"Remove the containers left under the previous names of renamed projects")
+	cmd.Flags().Bool("refresh-digests", false,
+		"Resolve the digests of pinned images again instead of reusing those of earlier deployments")
+	cmd.Flags().Bool("verbose-build", false, "Show the output of image builds")
+	cmd.Flags().Bool("verbose-pull", false, "Show the progress of image pulls")
+	cmd.Flags().Bool("build", false, "Rebuild images instead of building only missing ones")
 	return cmd
 }
 
 // runProjectDeploy handles the main logic for project deployment
 func runProjectDeploy(cmd *cobra.Command, args []string) error {
-	projectID, err := uuid.Parse(args[0])
+	projectIDs, err := parseProjectIDs(args)
 	if err != nil {
-		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+		return err
 	}
 
 	// Get flags
 	pull, _ := cmd.Flags().GetBool("pull")
+	services, _ := cmd.Flags().GetStringArray("service")
+	noDeps, _ := cmd.Flags().GetBool("no-deps")
+	notes, _ := cmd.Flags().GetString("notes")
+	labels, _ := cmd.Flags().GetStringArray("label")
+	ensureDependencies, _ := cmd.Flags().GetBool("ensure-dependencies")
+	removePreviousStacks, _ := cmd.Flags().GetBool("remove-previous-stacks")
+	refreshDigests, _ := cmd.Flags().GetBool("refresh-digests")
+	var upOptions docker.UpOptions
+	upOptions.VerboseBuild, _ = cmd.Flags().GetBool("verbose-build")
+	upOptions.VerbosePull, _ = cmd.Flags().GetBool("verbose-pull")
+	upOptions.Build, _ = cmd.Flags().GetBool("build")
+
+	if len(projectIDs) > 1 && len(services) > 0 {
+		return fmt.Errorf("--service can only be used when deploying a single project")
+	}
+	if noDeps && len(services) == 0 {
+		err := output.FprintWarning(cmd, "--no-deps has no effect without --service, deploying all services")
+		if err != nil {
+			return err
+		}
+	}
 
 	// Get services
 	projectService := app.GetProjectService()
 
-	// Fetch project details for display
-	project, err := projectService.Get(projectID)
+	// Deploy projects in dependency order with direct stdout/stderr piping
+	results, err := projectService.DeployManyPiping(projectIDs, oarproject.BulkDeployOptions{
+		Pull:                 pull,
+		Services:             services,
+		NoDeps:               noDeps,
+		Annotation:           domain.DeploymentAnnotation{Notes: notes, Labels: labels},
+		EnsureDependencies:   ensureDependencies,
+		RemovePreviousStacks: removePreviousStacks,
+		RefreshImageDigests:  refreshDigests,
+		UpOptions:            upOptions,
+		OnStart: func(project *domain.Project) {
+			if err := printDeploymentStart(cmd, project, pull, services, noDeps); err != nil {
+				slog.Debug("Failed to print deployment header", "error", err)
+			}
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to find project %s: %w", projectID, err)
+		return err
 	}
 
-	// Display deployment info
+	var failed int
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			failed++
+			if err := output.FprintWarning(cmd, "\nSkipped project '%s': %v", result.Project.Name, result.Err); err != nil {
+				return err
+			}
+		case result.Err != nil:
+			failed++
+			// A single project fails with its own error, as it did before bulk deployments
+			if len(results) == 1 {
+				return result.Err
+			}
+			if err := output.FprintError(cmd, "\nProject '%s' failed: %v", result.Project.Name, result.Err); err != nil {
+				return err
+			}
+		default:
+			if err := printDeploymentSuccess(cmd, result.Project.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects were not deployed", failed, len(results))
+	}
+	return nil
+}
+
+// printDeploymentStart prints what is about to be deployed
+func printDeploymentStart(
+	cmd *cobra.Command,
+	project *domain.Project,
+	pull bool,
+	services []string,
+	noDeps bool,
+) error {
 	if err := output.FprintPlain(cmd, "Starting deployment for project '%s'\n", project.Name); err != nil {
 		return err
 	}
-	if err := output.FprintPlain(cmd, "Git URL: %s", project.GitURL); err != nil {
+	if !project.UsesGit() {
+		// Archive projects are deployed as they are, there is nothing to pull
+		pull = false
+		if err := output.FprintPlain(cmd, "Source: uploaded archive\n"); err != nil {
+			return err
+		}
+	} else if err := output.FprintPlain(cmd, "Git URL: %s", project.GitURL); err != nil {
 		return err
 	}
 
@@ -61,27 +169,36 @@ func runProjectDeploy(cmd *cobra.Command, args []string) error {
 		if err := output.FprintPlain(cmd, "Git pull: enabled\n"); err != nil {
 			return err
 		}
-	} else {
+	} else if project.UsesGit() {
 		if err := output.FprintPlain(cmd, "Git pull: disabled\n"); err != nil {
 			return err
 		}
 	}
 
+	if len(services) > 0 {
+		if err := output.FprintPlain(cmd, "Services: %s\n", strings.Join(services, ", ")); err != nil {
+			return err
+		}
+		if noDeps {
+			if err := output.FprintPlain(cmd, "Dependencies: left untouched\n"); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Handle git pull messaging
 	if pull {
 		if err := output.FprintPlain(cmd, "Pulling latest changes from Git...\n"); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	// Deploy project with direct stdout/stderr piping
-	err = projectService.DeployPiping(projectID, pull)
-	if err != nil {
-		return err
-	}
-
+// printDeploymentSuccess prints the status and commit of a deployed project
+func printDeploymentSuccess(cmd *cobra.Command, projectID uuid.UUID) error {
 	// Get updated project for final status
-	updatedProject, err := projectService.Get(projectID)
+	updatedProject, err := app.GetProjectService().Get(projectID)
 	if err != nil {
 		return fmt.Errorf("failed to get updated project status: %w", err)
 	}
@@ -94,10 +211,7 @@ func runProjectDeploy(cmd *cobra.Command, args []string) error {
 	}
 
 	if updatedProject.LocalCommit != nil {
-		shortCommit := *updatedProject.LocalCommit
-		if len(shortCommit) > 8 {
-			shortCommit = shortCommit[:8]
-		}
+		shortCommit := domain.ShortCommit(*updatedProject.LocalCommit)
 		if err := output.FprintPlain(cmd, "Latest commit: %s", shortCommit); err != nil {
 			return err
 		}
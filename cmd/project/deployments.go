@@ -6,11 +6,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/domain"
 	"github.com/spf13/cobra"
 )
 
 func NewCmdProjectDeployments() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "deployments <project-id>",
 		Short: "List deployments for a project",
 		Long: `Display all deployments for a specific project.
@@ -59,4 +60,111 @@ Shows deployment history in a table format including:
 			return nil
 		},
 	}
+
+	cmd.AddCommand(newCmdProjectDeploymentsShow())
+	cmd.AddCommand(newCmdProjectDeploymentsAnnotate())
+	return cmd
+}
+
+func newCmdProjectDeploymentsShow() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <deployment-id>",
+		Short: "Show a single deployment",
+		Long: `Display the details of a deployment, including the Docker Compose command
+line that started its services. Variable values are masked in the command line.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deploymentID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid deployment ID '%s': must be a valid UUID", args[0])
+			}
+
+			deployment, err := app.GetProjectService().GetDeployment(deploymentID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve deployment %s: %w", deploymentID, err)
+			}
+
+			project, err := app.GetProjectService().Get(deployment.ProjectID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve project %s: %w", deployment.ProjectID, err)
+			}
+
+			out, err := output.PrintDeploymentDetails(deployment, project.Name)
+			if err != nil {
+				return fmt.Errorf("failed to format deployment: %w", err)
+			}
+
+			if err := output.FprintPlain(cmd, "%s", out); err != nil {
+				return fmt.Errorf("failed to print deployment: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newCmdProjectDeploymentsAnnotate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "annotate <project-id> <deployment-id>",
+		Short: "Set the notes and labels of a deployment",
+		Long: `Annotate a deployment of a project, e.g. with the incident a hotfix was deployed for.
+
+--notes replaces the notes and --label replaces the labels. Flags that are not given
+leave the current value unchanged. Pass --notes "" to clear the notes and --label ""
+to clear the labels.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+			}
+			deploymentID, err := uuid.Parse(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid deployment ID '%s': must be a valid UUID", args[1])
+			}
+
+			if !cmd.Flags().Changed("notes") && !cmd.Flags().Changed("label") {
+				return fmt.Errorf("nothing to change: pass --notes and/or --label")
+			}
+
+			projectService := app.GetProjectService()
+			deployment, err := projectService.GetDeployment(deploymentID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve deployment %s: %w", deploymentID, err)
+			}
+
+			annotation := domain.DeploymentAnnotation{Notes: deployment.Notes, Labels: deployment.Labels}
+			if cmd.Flags().Changed("notes") {
+				annotation.Notes, _ = cmd.Flags().GetString("notes")
+			}
+			if cmd.Flags().Changed("label") {
+				annotation.Labels, _ = cmd.Flags().GetStringArray("label")
+			}
+
+			deployment, err = projectService.AnnotateDeployment(projectID, deploymentID, annotation)
+			if err != nil {
+				return fmt.Errorf("failed to annotate deployment %s: %w", deploymentID, err)
+			}
+
+			project, err := projectService.Get(projectID)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve project %s: %w", projectID, err)
+			}
+
+			out, err := output.PrintDeploymentDetails(deployment, project.Name)
+			if err != nil {
+				return fmt.Errorf("failed to format deployment: %w", err)
+			}
+
+			if err := output.FprintPlain(cmd, "%s", out); err != nil {
+				return fmt.Errorf("failed to print deployment: %w", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("notes", "", "Notes to store with the deployment")
+	cmd.Flags().StringArray("label", nil, "Label to store with the deployment (repeatable)")
+	return cmd
 }
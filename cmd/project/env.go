@@ -0,0 +1,61 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectEnv() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env <project-id>",
+		Short: "Show the effective environment of a project",
+		Long: `Display the variables Docker Compose uses to interpolate the project's compose files.
+
+Variables are merged from the repository .env file, the project env files and
+inline variables, in that order. The source column shows where each final value
+comes from. Values of secret-looking variables are masked unless --show-secrets
+is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProjectEnv(cmd, args)
+		},
+	}
+
+	cmd.Flags().Bool("show-secrets", false, "Show values of secret-looking variables")
+	return cmd
+}
+
+// runProjectEnv handles the main logic for displaying the effective project environment
+func runProjectEnv(cmd *cobra.Command, args []string) error {
+	projectID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+	}
+
+	showSecrets, _ := cmd.Flags().GetBool("show-secrets")
+
+	vars, err := app.GetProjectService().GetEffectiveEnv(projectID, showSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to get project environment: %w", err)
+	}
+
+	if len(vars) == 0 {
+		return output.FprintPlain(cmd, "No variables found.")
+	}
+
+	data := make([][]string, 0, len(vars))
+	for _, v := range vars {
+		data = append(data, []string{v.Key, v.Value, v.Source})
+	}
+
+	out, err := output.PrintTable([]string{"Variable", "Value", "Source"}, data)
+	if err != nil {
+		return err
+	}
+
+	return output.FprintPlain(cmd, "%s", out)
+}
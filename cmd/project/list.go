@@ -1,13 +1,17 @@
 package project
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	oarproject "github.com/oar-cd/oar/project"
 	"github.com/spf13/cobra"
 )
 
 func NewCmdProjectList() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all managed projects",
 		Long: `Display all Docker Compose projects currently managed by Oar.
@@ -15,12 +19,28 @@ func NewCmdProjectList() *cobra.Command {
 Shows project information in a table format including:
 - Project name and current status (with color coding)
 - Git repository URL and latest commit hash
-- Creation and update timestamps`,
+- Creation and update timestamps
+
+Projects are listed in the order of the dashboard: projects arranged by hand in the web UI first,
+then the others by name. Use --sort name or --sort updated for the default orderings.
+
+Use --label to list only the projects whose containers carry a label, e.g. --label team=payments.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			order, _ := cmd.Flags().GetString("sort")
+			labels, _ := cmd.Flags().GetStringArray("label")
+
 			projects, err := app.GetProjectService().List()
 			if err != nil {
 				return err
 			}
+			if err := oarproject.SortProjects(projects, order); err != nil {
+				return err
+			}
+			if len(labels) > 0 {
+				if projects, err = oarproject.FilterByLabels(projects, labels); err != nil {
+					return err
+				}
+			}
 
 			if len(projects) == 0 {
 				if err := output.FprintPlain(cmd, "No projects found."); err != nil {
@@ -41,4 +61,11 @@ Shows project information in a table format including:
 			return nil
 		},
 	}
+
+	cmd.Flags().String("sort", oarproject.ProjectOrderDashboard,
+		fmt.Sprintf("Order of the projects (%s)", strings.Join(oarproject.ProjectOrders, ", ")))
+	cmd.Flags().
+		StringArray("label", nil, "Only list projects with this container label, as key or key=value (repeatable)")
+
+	return cmd
 }
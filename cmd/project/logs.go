@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/docker"
 	"github.com/spf13/cobra"
 )
 
@@ -14,13 +15,29 @@ func NewCmdProjectLogs() *cobra.Command {
 		Use:   "logs <project-id>",
 		Short: "View logs from a project's containers",
 		Long: `Stream logs from all containers in a Docker Compose project.
-This shows real-time logs from all services in the project.`,
+This shows real-time logs from all services in the project.
+
+  # Prefix each line with its timestamp and drop colors written by the services
+  oar project logs <project-id> --timestamps --strip-color
+
+  # Start from the last 100 lines of each service written in the past hour
+  oar project logs <project-id> --since 1h --tail 100
+
+  # Only show lines that mention a timeout, in any case
+  oar project logs <project-id> --grep 'time(d )?out' --ignore-case`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runProjectLogs(cmd, args)
 		},
 	}
 
+	cmd.Flags().BoolP("timestamps", "t", false, "Show timestamps")
+	cmd.Flags().Bool("strip-color", false, "Remove color and other terminal escape sequences from service output")
+	cmd.Flags().String("since", "", "Show logs written since a duration ago (e.g. 1h) or a timestamp")
+	cmd.Flags().Int("tail", 0, "Number of lines to show from the end of the logs of each service, 0 for all")
+	cmd.Flags().String("grep", "", "Only show lines matching this regular expression")
+	cmd.Flags().BoolP("ignore-case", "i", false, "Match --grep regardless of case")
+
 	return cmd
 }
 
@@ -31,6 +48,17 @@ func runProjectLogs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
 	}
 
+	var opts docker.LogsOptions
+	opts.Timestamps, _ = cmd.Flags().GetBool("timestamps")
+	opts.StripColor, _ = cmd.Flags().GetBool("strip-color")
+	opts.Since, _ = cmd.Flags().GetString("since")
+	opts.Tail, _ = cmd.Flags().GetInt("tail")
+	opts.Grep, _ = cmd.Flags().GetString("grep")
+	opts.IgnoreCase, _ = cmd.Flags().GetBool("ignore-case")
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
 	// Get services
 	projectService := app.GetProjectService()
 
@@ -49,7 +77,7 @@ func runProjectLogs(cmd *cobra.Command, args []string) error {
 	}
 
 	// Stream project logs with direct stdout/stderr piping
-	err = projectService.GetLogsPiping(projectID)
+	err = projectService.GetLogsPiping(projectID, opts)
 	if err != nil {
 		return err
 	}
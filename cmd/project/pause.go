@@ -0,0 +1,73 @@
+package project
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectPause() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <project-id>",
+		Short: "Freeze the containers of a project",
+		Long: `Freeze the running containers of a project with docker compose pause, e.g. to
+inspect it in a given state. Unlike stop, the containers are kept as they are,
+with their memory and network connections. The watcher neither reports a paused
+project as crashed nor deploys it. Resume it with 'oar project unpause'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectPause(cmd, args, true)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+}
+
+func NewCmdProjectUnpause() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unpause <project-id>",
+		Short: "Resume the containers of a paused project",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectPause(cmd, args, false)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+}
+
+// runProjectPause pauses or resumes the containers of a project
+func runProjectPause(cmd *cobra.Command, args []string, pause bool) error {
+	projectID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+	}
+
+	projectService := app.GetProjectService()
+	project, err := projectService.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to find project %s: %w", projectID, err)
+	}
+
+	verb := "paused"
+	if pause {
+		err = projectService.Pause(projectID)
+	} else {
+		verb = "resumed"
+		err = projectService.Unpause(projectID)
+	}
+	if err != nil {
+		return err
+	}
+
+	return output.FprintSuccess(cmd, "Project '%s' %s\n", project.Name, verb)
+}
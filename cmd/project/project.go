@@ -14,9 +14,16 @@ func NewCmdProject() *cobra.Command {
 	cmd.AddCommand(NewCmdProjectRemove())
 	cmd.AddCommand(NewCmdProjectShow())
 	cmd.AddCommand(NewCmdProjectDeploy())
+	cmd.AddCommand(NewCmdProjectDepends())
+	cmd.AddCommand(NewCmdProjectComposeFiles())
+	cmd.AddCommand(NewCmdProjectBranch())
 	cmd.AddCommand(NewCmdProjectStop())
+	cmd.AddCommand(NewCmdProjectPause())
+	cmd.AddCommand(NewCmdProjectUnpause())
 	cmd.AddCommand(NewCmdProjectStatus())
 	cmd.AddCommand(NewCmdProjectConfig())
+	cmd.AddCommand(NewCmdProjectEnv())
+	cmd.AddCommand(NewCmdProjectSetVariable())
 	cmd.AddCommand(NewCmdProjectLogs())
 	cmd.AddCommand(NewCmdProjectDeployments())
 	return cmd
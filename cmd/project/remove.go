@@ -9,7 +9,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
+	oarproject "github.com/oar-cd/oar/project"
 	"github.com/spf13/cobra"
 )
 
@@ -24,7 +26,14 @@ This operation will permanently delete:
 - All deployment history and logs
 - Project configuration and metadata
 
-The project cannot be recovered after deletion.`,
+The project cannot be recovered after deletion. Named volumes are kept unless --remove-volumes
+is given, and images unless --remove-images is: 'local' removes the images built by Compose, 'all'
+every image of the services, including those other projects use too.
+
+Running services are stopped the same way as by 'docker compose down'. For stateful services such
+as databases, --stop-signal and --stop-timeout control the shutdown, e.g. '--stop-signal SIGINT
+--stop-timeout 2m' gives PostgreSQL a fast shutdown with time to write a checkpoint before its
+container is killed.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := runProjectRemove(cmd, args)
@@ -39,6 +48,13 @@ The project cannot be recovered after deletion.`,
 	// Add confirmation flags
 	cmd.Flags().BoolP("confirm", "y", false, "Skip confirmation prompt and proceed with deletion")
 	cmd.Flags().Bool("force", false, "Force removal even if project is running")
+	cmd.Flags().Bool("remove-networks", false,
+		"Also remove external networks that Oar created and no other project uses")
+	cmd.Flags().Bool("remove-volumes", false, "Also remove the named volumes of the project")
+	cmd.Flags().String("remove-images", "", "Also remove the images of the services: local (built ones) or all")
+	cmd.Flags().String("stop-signal", "", "Signal that stops the services (default: stop_signal from the compose file)")
+	cmd.Flags().Duration("stop-timeout", 0,
+		"Time services get to shut down before they are killed (default: stop_grace_period from the compose file)")
 
 	return cmd
 }
@@ -53,6 +69,24 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 	// Get confirmation flags
 	skipConfirmation, _ := cmd.Flags().GetBool("confirm")
 	forceRemoval, _ := cmd.Flags().GetBool("force")
+	removeNetworks, _ := cmd.Flags().GetBool("remove-networks")
+	removeVolumes, _ := cmd.Flags().GetBool("remove-volumes")
+	removeImages, _ := cmd.Flags().GetString("remove-images")
+	stopSignal, _ := cmd.Flags().GetString("stop-signal")
+	stopTimeout, _ := cmd.Flags().GetDuration("stop-timeout")
+
+	// Checked before asking for confirmation, so that a typo does not abort the removal halfway
+	if stopSignal != "" {
+		if stopSignal, err = docker.ParseSignal(stopSignal); err != nil {
+			return err
+		}
+	}
+	if stopTimeout < 0 {
+		return fmt.Errorf("--stop-timeout must not be negative")
+	}
+	if err := docker.ValidateRemoveImages(removeImages); err != nil {
+		return err
+	}
 
 	// Fetch project details before removal
 	project, err := app.GetProjectService().Get(projectID)
@@ -74,10 +108,11 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Check if project is running and warn
-	if project.Status == domain.ProjectStatusRunning {
+	// Check if project is running and warn, paused containers are still there as well
+	if project.Status == domain.ProjectStatusRunning || project.Status == domain.ProjectStatusPaused {
 		if !forceRemoval {
-			if err := output.FprintError(cmd, "ERROR: Project is currently RUNNING!\n"); err != nil {
+			if err := output.FprintError(cmd, "ERROR: Project is currently %s!\n",
+				strings.ToUpper(project.Status.String())); err != nil {
 				return err
 			}
 			if err := output.FprintPlain(cmd, "Stop the project first, or use --force to override.\n"); err != nil {
@@ -85,7 +120,8 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 			}
 			return fmt.Errorf("cannot remove running project without --force flag")
 		} else {
-			if err := output.FprintWarning(cmd, "WARNING: Project is RUNNING but will be force-removed\n"); err != nil {
+			if err := output.FprintWarning(cmd, "WARNING: Project is %s but will be force-removed\n",
+				strings.ToUpper(project.Status.String())); err != nil {
 				return err
 			}
 		}
@@ -101,10 +137,31 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 	if err := output.FprintPlain(cmd, "All deployment history and logs\n"); err != nil {
 		return err
 	}
+	if removeVolumes {
+		if err := output.FprintPlain(cmd, "Named volumes of the project and their data\n"); err != nil {
+			return err
+		}
+	}
+	switch removeImages {
+	case docker.RemoveImagesLocal:
+		if err := output.FprintPlain(cmd, "Images built for the project\n"); err != nil {
+			return err
+		}
+	case docker.RemoveImagesAll:
+		if err := output.FprintPlain(cmd, "All images of the project\n"); err != nil {
+			return err
+		}
+	}
 	if err := output.FprintPlain(cmd, "Project configuration and metadata\n\n"); err != nil {
 		return err
 	}
 
+	if removeImages == docker.RemoveImagesAll {
+		if err := warnSharedImages(cmd, projectID); err != nil {
+			return err
+		}
+	}
+
 	// Confirmation prompt (unless skipped)
 	if !skipConfirmation {
 		if !promptConfirmation(cmd, project.Name) {
@@ -120,7 +177,13 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := app.GetProjectService().Remove(projectID, false); err != nil {
+	removeOptions := oarproject.RemoveOptions{
+		RemoveVolumes: removeVolumes,
+		StopSignal:    stopSignal,
+		StopTimeout:   stopTimeout,
+		RemoveImages:  removeImages,
+	}
+	if err := app.GetProjectService().Remove(projectID, removeOptions); err != nil {
 		return fmt.Errorf("failed to remove project: %w", err)
 	}
 
@@ -128,6 +191,19 @@ func runProjectRemove(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Networks are removed after the project, so that it no longer counts as a user
+	if removeNetworks && len(project.ExternalNetworks) > 0 {
+		removed, err := app.GetProjectService().RemoveUnusedNetworks(project.ExternalNetworks)
+		for _, name := range removed {
+			if err := output.FprintPlain(cmd, "Removed network %s", name); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove unused networks: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -146,3 +222,18 @@ func promptConfirmation(cmd *cobra.Command, projectName string) bool {
 	input = strings.TrimSpace(input)
 	return input == projectName
 }
+
+// warnSharedImages warns about the images of the project that other projects use as well, which
+// --remove-images all removes too
+func warnSharedImages(cmd *cobra.Command, projectID uuid.UUID) error {
+	shared, err := app.GetProjectService().SharedImages(projectID)
+	if err != nil {
+		return output.FprintWarning(cmd, "WARNING: Could not check which images other projects use: %v\n", err)
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+	return output.FprintWarning(cmd, "WARNING: Other projects use these images too: %s. Images that containers "+
+		"use are kept, the others have to be pulled again by the projects that need them\n",
+		strings.Join(shared, ", "))
+}
@@ -0,0 +1,148 @@
+package project
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	oarproject "github.com/oar-cd/oar/project"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdProjectSetVariable() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-variable <KEY=value> [project-id...]",
+		Short: "Set a variable of several projects at once",
+		Long: `Set an inline variable of projects selected by ID or by container label, e.g. to rotate a
+token that several projects share. Projects that set the variable get the new value, the others
+get the variable added. Their other variables are left as they are.
+
+The affected projects are shown before anything is changed. All of them are saved together, or
+none if one fails. Use --deploy to redeploy the changed projects afterwards; otherwise the new
+value is used from their next deployment.
+
+Examples:
+  oar project set-variable REGISTRY_TOKEN=s3cr3t --label team=shop
+  oar project set-variable LOG_LEVEL=debug <project-id> <project-id> --deploy --confirm`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runProjectSetVariable(cmd, args)
+			if err != nil {
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringArray("label", nil, "Select projects with this container label, as key or key=value (repeatable)")
+	cmd.Flags().Bool("deploy", false, "Redeploy the changed projects")
+	cmd.Flags().BoolP("confirm", "y", false, "Skip confirmation prompt and apply the change")
+	return cmd
+}
+
+// runProjectSetVariable previews the change, asks for confirmation and sets the variable
+func runProjectSetVariable(cmd *cobra.Command, args []string) error {
+	key, value, ok := strings.Cut(args[0], "=")
+	if !ok {
+		return fmt.Errorf("invalid variable '%s': use KEY=value", args[0])
+	}
+	key = strings.TrimSpace(key)
+
+	labels, _ := cmd.Flags().GetStringArray("label")
+	deploy, _ := cmd.Flags().GetBool("deploy")
+	skipConfirmation, _ := cmd.Flags().GetBool("confirm")
+
+	projectIDs, err := parseProjectIDs(args[1:])
+	if err != nil {
+		return err
+	}
+	selector := oarproject.ProjectSelector{ProjectIDs: projectIDs, Labels: labels}
+
+	projectService := app.GetProjectService()
+	changes, err := projectService.PreviewVariableUpdate(selector, key, value)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return output.FprintPlain(cmd, "No projects match the labels.")
+	}
+
+	data := make([][]string, 0, len(changes))
+	var changed int
+	for _, change := range changes {
+		data = append(data, []string{change.Project.ID.String(), change.Project.Name, describeVariableChange(change)})
+		if !change.Unchanged {
+			changed++
+		}
+	}
+	table, err := output.PrintTable([]string{"ID", "Name", "Change"}, data)
+	if err != nil {
+		return err
+	}
+	if err := output.FprintPlain(cmd, "Setting %s on these projects:\n%s", key, table); err != nil {
+		return err
+	}
+	if changed == 0 {
+		return output.FprintPlain(cmd, "All projects already set %s to this value.", key)
+	}
+
+	if !skipConfirmation && !promptSetVariable(cmd, changed) {
+		return output.FprintPlain(cmd, "Variable update cancelled.")
+	}
+
+	changes, err = projectService.BulkUpdateVariable(selector, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set variable: %w", err)
+	}
+
+	var deployIDs []string
+	for _, change := range changes {
+		if !change.Unchanged {
+			deployIDs = append(deployIDs, change.Project.ID.String())
+		}
+	}
+	if err := output.FprintSuccess(cmd, "Variable %s set on %d projects", key, len(deployIDs)); err != nil {
+		return err
+	}
+	if !deploy || len(deployIDs) == 0 {
+		return nil
+	}
+
+	deployCmd := NewCmdProjectDeploy()
+	deployCmd.SetOut(cmd.OutOrStdout())
+	deployCmd.SetErr(cmd.ErrOrStderr())
+	if err := runProjectDeploy(deployCmd, deployIDs); err != nil {
+		return errors.Join(errors.New("the variable was set, but redeploying failed"), err)
+	}
+	return nil
+}
+
+func describeVariableChange(change oarproject.VariableChange) string {
+	switch {
+	case change.Unchanged:
+		return "unchanged"
+	case change.Added:
+		return "added"
+	default:
+		return "updated"
+	}
+}
+
+func promptSetVariable(cmd *cobra.Command, changed int) bool {
+	if err := output.FprintWarning(cmd, "Change %d projects? [y/N]: ", changed); err != nil {
+		return false
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	input = strings.ToLower(strings.TrimSpace(input))
+	return input == "y" || input == "yes"
+}
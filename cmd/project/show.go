@@ -3,6 +3,7 @@ package project
 import (
 	"fmt"
 
+	"github.com/docker/go-units"
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/cmd/output"
@@ -10,10 +11,16 @@ import (
 )
 
 func NewCmdProjectShow() *cobra.Command {
-	return &cobra.Command{
+	var showDiskUsage bool
+
+	cmd := &cobra.Command{
 		Use:   "show <project-id>",
 		Short: "Show detailed project information",
-		Long:  "Display comprehensive information about a project including configuration, deployment history, and current status.",
+		Long: `Display comprehensive information about a project including configuration, deployment history, and current status.
+
+With --disk-usage, the disk space taken by the project's working directory, volumes and images is
+measured and shown as well. Measuring walks the directory and volumes, which takes a while for large
+projects.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return cmd.Help()
@@ -38,7 +45,57 @@ func NewCmdProjectShow() *cobra.Command {
 				return fmt.Errorf("failed to print project details: %w", err)
 			}
 
+			if showDiskUsage {
+				return printDiskUsage(cmd, projectID)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&showDiskUsage, "disk-usage", false, "Measure and show the disk space the project takes")
+
+	return cmd
+}
+
+// printDiskUsage measures the disk space the project takes and prints a breakdown, warning when the
+// project takes more than the configured threshold
+func printDiskUsage(cmd *cobra.Command, projectID uuid.UUID) error {
+	usage, err := app.GetProjectService().GetDiskUsage(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to measure disk usage: %w", err)
+	}
+
+	data := [][]string{{"Working Directory", units.HumanSize(float64(usage.WorkingDir))}}
+	for _, volume := range usage.Volumes {
+		data = append(data, []string{"Volume " + volume.Name, formatSize(volume.Size)})
+	}
+	for _, image := range usage.Images {
+		size := units.HumanSize(float64(image.Size))
+		if image.Shared {
+			size += " (shared)"
+		}
+		data = append(data, []string{"Image " + image.Image, size})
+	}
+	data = append(data, []string{"Total", units.HumanSize(float64(usage.Total))})
+
+	table, err := output.PrintTable([]string{}, data)
+	if err != nil {
+		return fmt.Errorf("failed to format disk usage: %w", err)
+	}
+	if err := output.FprintPlain(cmd, "\nDisk usage:\n%s", table); err != nil {
+		return fmt.Errorf("failed to print disk usage: %w", err)
+	}
+	if usage.Exceeds {
+		return output.FprintWarning(cmd, "WARNING: The project uses more disk space than the configured %s\n",
+			units.HumanSize(float64(usage.Warning)))
+	}
+	return nil
+}
+
+// formatSize formats a size in bytes, which is negative when Docker could not measure it
+func formatSize(size int64) string {
+	if size < 0 {
+		return "unknown"
+	}
+	return units.HumanSize(float64(size))
 }
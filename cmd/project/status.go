@@ -49,7 +49,10 @@ func runProjectStatus(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if projectStatus.Status == docker.ComposeProjectStatusRunning && projectStatus.Uptime != "" {
+	running := projectStatus.Status == docker.ComposeProjectStatusRunning ||
+		projectStatus.Status == docker.ComposeProjectStatusDegraded ||
+		projectStatus.Status == docker.ComposeProjectStatusPaused
+	if running && projectStatus.Uptime != "" {
 		if err := output.FprintPlain(cmd, "Uptime: %s", projectStatus.Uptime); err != nil {
 			return err
 		}
@@ -62,14 +65,35 @@ func runProjectStatus(cmd *cobra.Command, args []string) error {
 		}
 		for _, container := range projectStatus.Containers {
 			prefix := "[OK]"
-			if container.State != "running" {
+			switch container.State {
+			case "running":
+			case "paused":
+				prefix = "[PAUSED]"
+			default:
 				prefix = "[ERROR]"
 			}
 			if err := output.FprintPlain(cmd, "  %s %s: %s", prefix, container.Service, container.Status); err != nil {
 				return err
 			}
+			if container.RestartCount > 0 {
+				if err := output.FprintPlain(cmd, "      %s", restartSummary(container)); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
 	return nil
 }
+
+// restartSummary describes how often a container restarted and why it last exited
+func restartSummary(container docker.ContainerInfo) string {
+	summary := fmt.Sprintf("restarted %d times", container.RestartCount)
+	if container.RestartCount == 1 {
+		summary = "restarted 1 time"
+	}
+	if container.LastExitReason != "" {
+		summary += " (last exit: " + container.LastExitReason + ")"
+	}
+	return summary
+}
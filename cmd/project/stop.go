@@ -14,7 +14,8 @@ func NewCmdProjectStop() *cobra.Command {
 		Use:   "stop <project-id>",
 		Short: "Stop a running project",
 		Long: `Stop a running Docker Compose project.
-This will gracefully shut down all containers associated with the project.`,
+This will gracefully shut down all containers associated with the project.
+Named volumes are kept unless --remove-volumes is given.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			err := runProjectStop(cmd, args)
@@ -26,6 +27,8 @@ This will gracefully shut down all containers associated with the project.`,
 		},
 	}
 
+	cmd.Flags().Bool("remove-volumes", false, "Also remove the named volumes of the project")
+
 	return cmd
 }
 
@@ -36,6 +39,8 @@ func runProjectStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
 	}
 
+	removeVolumes, _ := cmd.Flags().GetBool("remove-volumes")
+
 	// Get services
 	projectService := app.GetProjectService()
 
@@ -51,7 +56,7 @@ func runProjectStop(cmd *cobra.Command, args []string) error {
 	}
 
 	// Stop project with direct stdout/stderr piping
-	err = projectService.StopPiping(projectID)
+	err = projectService.StopPiping(projectID, removeVolumes)
 	if err != nil {
 		return err
 	}
@@ -6,10 +6,18 @@ import (
 	"os"
 
 	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/importdir"
+	cmdmaintenance "github.com/oar-cd/oar/cmd/maintenance"
+	"github.com/oar-cd/oar/cmd/migratedata"
+	cmdnotify "github.com/oar-cd/oar/cmd/notify"
 	"github.com/oar-cd/oar/cmd/output"
 	cmdproject "github.com/oar-cd/oar/cmd/project"
 	"github.com/oar-cd/oar/cmd/server"
+	"github.com/oar-cd/oar/cmd/signal"
+	cmdstatus "github.com/oar-cd/oar/cmd/status"
+	cmdtoken "github.com/oar-cd/oar/cmd/token"
 	"github.com/oar-cd/oar/cmd/version"
+	cmdwatcher "github.com/oar-cd/oar/cmd/watcher"
 	"github.com/oar-cd/oar/config"
 	"github.com/oar-cd/oar/logging"
 	"github.com/spf13/cobra"
@@ -34,7 +42,7 @@ func NewCmdRoot() *cobra.Command {
 		},
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// Skip initialization for commands that don't need full app context
-			skipInitCommands := []string{"version", "server"}
+			skipInitCommands := []string{"version", "server", "migrate-data"}
 
 			// For root-level commands (parent is "oar"), skip initialization
 			if cmd.Parent() != nil && cmd.Parent().Name() == "oar" {
@@ -61,7 +69,11 @@ func NewCmdRoot() *cobra.Command {
 			if logging.LogLevel.IsSet() {
 				logLevel = logging.LogLevel.String()
 			}
-			logging.InitLogging(logLevel)
+			// CLI commands log to stderr; only the server writes the log file
+			if err := logging.Setup(logging.Options{Level: logLevel, Format: cfg.LogFormat}); err != nil {
+				log.Fatalf("Failed to initialize logging: %s", err)
+				os.Exit(1)
+			}
 
 			// Initialize application with config
 			if err := app.InitializeWithConfig(cfg); err != nil {
@@ -75,6 +87,14 @@ func NewCmdRoot() *cobra.Command {
 
 	cmd.AddCommand(cmdproject.NewCmdProject())
 	cmd.AddCommand(server.NewCmdServer())
+	cmd.AddCommand(migratedata.NewCmdMigrateData())
+	cmd.AddCommand(importdir.NewCmdImportDir())
+	cmd.AddCommand(signal.NewCmdSignal())
+	cmd.AddCommand(cmdstatus.NewCmdStatus())
 	cmd.AddCommand(version.NewCmdVersion())
+	cmd.AddCommand(cmdwatcher.NewCmdWatcher())
+	cmd.AddCommand(cmdnotify.NewCmdNotify())
+	cmd.AddCommand(cmdmaintenance.NewCmdMaintenance())
+	cmd.AddCommand(cmdtoken.NewCmdToken())
 	return cmd
 }
@@ -16,12 +16,16 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/logging"
 	"github.com/oar-cd/oar/watcher"
 	"github.com/oar-cd/oar/web/routes"
 	"github.com/spf13/cobra"
 )
 
+// shutdownTimeout bounds how long the web server and the watcher get to finish in-flight work
+const shutdownTimeout = 30 * time.Second
+
 // NewCmdServer creates a command to run both web and watcher services
 func NewCmdServer() *cobra.Command {
 	cmd := &cobra.Command{
@@ -47,7 +51,9 @@ func runServer(configPath string) error {
 	}
 
 	// Initialize logging
-	logging.InitLogging(config.LogLevel)
+	if err := logging.Setup(config.LoggingOptions()); err != nil {
+		return fmt.Errorf("failed to initialize logging: %w", err)
+	}
 
 	slog.Info("Starting Oar Server (web + watcher)")
 
@@ -56,6 +62,15 @@ func runServer(configPath string) error {
 		return fmt.Errorf("failed to initialize application: %w", err)
 	}
 
+	// Report an unreachable Docker daemon now rather than at the first deployment. Docker may still
+	// be starting, so the server keeps running.
+	checkDockerConnection(config.ComposeQueryTimeout)
+
+	// Clean up deployments that were in flight when the server last stopped
+	if err := app.ReconcileInterruptedDeployments(); err != nil {
+		slog.Error("Failed to reconcile interrupted deployments", "error", err)
+	}
+
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -63,20 +78,70 @@ func runServer(configPath string) error {
 	// Handle shutdown signals
 	go handleShutdown(cancel)
 
+	// Register the watcher before serving requests, handlers read it from the app
+	var watcherService *watcher.WatcherService
+	if config.WatcherEnabled {
+		watcherService = watcher.NewWatcherService(
+			app.GetProjectService(),
+			app.GetGitService(),
+			config.WatcherPollInterval,
+		)
+		watcherService.SetWebhookDebounce(config.WebhookQuietPeriod, config.WebhookMaxDelay)
+		watcherService.SetCheckDependencies(config.WatcherCheckDependencies)
+		watcherService.SetMetrics(app.GetMetrics())
+		if err := watcherService.SetCheckRepository(app.GetWatcherCheckRepository()); err != nil {
+			return fmt.Errorf("failed to initialize watcher: %w", err)
+		}
+		app.SetWatcherService(watcherService)
+	} else {
+		slog.Info("Watcher service is disabled")
+	}
+
+	// Reload hot-reloadable settings on SIGHUP
+	go handleReload(ctx, configPath, config, watcherService)
+
 	// Start watcher service in background
+	watcherDone := make(chan struct{})
 	go func() {
-		if err := startWatcherService(ctx, config); err != nil {
+		defer close(watcherDone)
+		if err := startWatcherService(ctx, watcherService); err != nil {
 			slog.Error("Watcher service failed", "error", err)
 			cancel() // Trigger shutdown
 		}
 	}()
 
+	// Prune history in the background, an interrupted run is picked up by the next one
+	go func() {
+		if err := app.GetMaintenanceScheduler().Start(ctx); err != nil {
+			slog.Error("Maintenance scheduler failed", "error", err)
+		}
+	}()
+
+	// Look for newer releases in the background, a failed check is retried at the next interval
+	if updateChecker := app.GetUpdateChecker(); updateChecker != nil {
+		go func() {
+			if err := updateChecker.Start(ctx); err != nil {
+				slog.Error("Update check failed", "error", err)
+			}
+		}()
+	}
+
 	// Start web server (blocks until shutdown)
-	return startWebServer(ctx, config)
+	webErr := startWebServer(ctx, cancel, config)
+
+	// Let a check or deployment started by the watcher finish so it is recorded properly.
+	// Deployments still running after the timeout are reconciled on the next start.
+	select {
+	case <-watcherDone:
+	case <-time.After(shutdownTimeout):
+		slog.Warn("Watcher did not stop in time, exiting anyway", "timeout", shutdownTimeout)
+	}
+
+	return webErr
 }
 
-// startWebServer starts the HTTP server
-func startWebServer(ctx context.Context, config *config.Config) error {
+// startWebServer starts the HTTP server. A server failure cancels the context so the watcher stops too.
+func startWebServer(ctx context.Context, cancel context.CancelFunc, config *config.Config) error {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 
@@ -87,6 +152,8 @@ func startWebServer(ctx context.Context, config *config.Config) error {
 	routes.RegisterHomeRoutes(r)
 	routes.RegisterProjectRoutes(r)
 	routes.RegisterUtilityRoutes(r)
+	routes.RegisterAPIRoutes(r)
+	routes.RegisterShareRoutes(r)
 
 	// Create HTTP server
 	address := fmt.Sprintf("%s:%d", config.HTTPHost, config.HTTPPort)
@@ -96,19 +163,28 @@ func startWebServer(ctx context.Context, config *config.Config) error {
 	}
 
 	// Start server in goroutine
+	serveErr := make(chan error, 1)
 	go func() {
 		log.Printf("Web server starting on http://%s", address)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			slog.Error("Web server failed", "error", err)
+			serveErr <- err
+			cancel()
 		}
 	}()
 
 	// Wait for shutdown signal
 	<-ctx.Done()
 
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("web server failed: %w", err)
+	default:
+	}
+
 	// Graceful shutdown
 	slog.Info("Shutting down web server")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer shutdownCancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -119,28 +195,67 @@ func startWebServer(ctx context.Context, config *config.Config) error {
 	return nil
 }
 
-// startWatcherService starts the watcher service
-func startWatcherService(ctx context.Context, config *config.Config) error {
-
-	// Initialize and run watcher service if enabled
-	if config.WatcherEnabled {
-		watcherService := watcher.NewWatcherService(
-			app.GetProjectService(),
-			app.GetGitService(),
-			config.WatcherPollInterval,
-		)
+// startWatcherService runs the watcher until ctx is done. It returns immediately if the watcher is disabled.
+func startWatcherService(ctx context.Context, watcherService *watcher.WatcherService) error {
+	if watcherService == nil {
+		return nil
+	}
 
-		if err := watcherService.Start(ctx); err != nil {
-			return fmt.Errorf("watcher service failed: %w", err)
-		}
-	} else {
-		slog.Info("Watcher service is disabled")
+	if err := watcherService.Start(ctx); err != nil {
+		return fmt.Errorf("watcher service failed: %w", err)
 	}
 
 	slog.Info("Watcher service stopped")
 	return nil
 }
 
+// handleReload re-reads the configuration on SIGHUP and applies the settings that can change
+// without a restart. An invalid configuration is rejected and the running one stays in effect.
+func handleReload(ctx context.Context, configPath string, current *config.Config, watcherService *watcher.WatcherService) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigChan:
+			slog.Info("Reload signal received, reloading configuration", "config_path", configPath)
+
+			reloaded, restartRequired, err := current.Reload(configPath)
+			if err != nil {
+				slog.Error("Configuration reload failed, keeping the current configuration", "error", err)
+				continue
+			}
+			if len(restartRequired) > 0 {
+				slog.Warn("Configuration changes require a restart and were not applied",
+					"fields", restartRequired)
+			}
+
+			logging.SetLogLevel(reloaded.LogLevel)
+			if watcherService != nil {
+				watcherService.SetPollInterval(reloaded.WatcherPollInterval)
+				watcherService.SetWebhookDebounce(reloaded.WebhookQuietPeriod, reloaded.WebhookMaxDelay)
+				watcherService.SetCheckDependencies(reloaded.WatcherCheckDependencies)
+			}
+			if retention := app.GetDeploymentRetention(); retention != nil {
+				retention.Set(reloaded.DeploymentRetentionMaxAge, reloaded.DeploymentRetentionMaxCount)
+			}
+			current = reloaded
+
+			slog.Info("Configuration reloaded",
+				"log_level", reloaded.LogLevel,
+				"watcher_poll_interval", reloaded.WatcherPollInterval,
+				"watcher_check_dependencies", reloaded.WatcherCheckDependencies,
+				"webhook_quiet_period", reloaded.WebhookQuietPeriod,
+				"webhook_max_delay", reloaded.WebhookMaxDelay,
+				"retention_deployments_max_age", reloaded.DeploymentRetentionMaxAge,
+				"retention_deployments_max_count", reloaded.DeploymentRetentionMaxCount)
+		}
+	}
+}
+
 // handleShutdown handles OS signals for graceful shutdown
 func handleShutdown(cancel context.CancelFunc) {
 	sigChan := make(chan os.Signal, 1)
@@ -149,3 +264,19 @@ func handleShutdown(cancel context.CancelFunc) {
 	slog.Info("Shutdown signal received")
 	cancel()
 }
+
+// checkDockerConnection logs whether the docker CLI and the Docker API reach the daemon
+func checkDockerConnection(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	version, err := docker.CheckConnection(ctx)
+	if err != nil {
+		slog.Error("Docker is not reachable, deployments will fail until it is",
+			"error", err,
+			"docker_host", app.GetConfig().DockerHost,
+			"docker_command_prefix", app.GetConfig().DockerCommandPrefix)
+		return
+	}
+	slog.Info("Connected to Docker", "version", version)
+}
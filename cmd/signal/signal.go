@@ -0,0 +1,100 @@
+// Package signal provides the signal command for Oar.
+package signal
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/docker"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdSignal creates the signal command
+func NewCmdSignal() *cobra.Command {
+	var reload bool
+
+	cmd := &cobra.Command{
+		Use:   "signal <project-id> <service> [signal]",
+		Short: "Send a signal to a running service",
+		Long: `Send a signal to the running containers of a service, e.g. to make nginx reload its
+configuration without restarting:
+
+  oar signal <project-id> nginx SIGHUP
+  oar signal <project-id> nginx --reload
+
+The signal is required, use --reload to send SIGHUP.`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runSignal(cmd, args, reload)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&reload, "reload", false, "Send "+docker.ReloadSignal+" to reload the service configuration")
+
+	return cmd
+}
+
+// resolveSignal picks the signal from the arguments, requiring it to be explicit
+func resolveSignal(args []string, reload bool) (string, error) {
+	if len(args) < 3 {
+		if !reload {
+			return "", fmt.Errorf("a signal is required, e.g. SIGHUP, or use --reload")
+		}
+		return docker.ReloadSignal, nil
+	}
+
+	signal, err := docker.ParseSignal(args[2])
+	if err != nil {
+		return "", err
+	}
+	if reload && signal != docker.ReloadSignal {
+		return "", fmt.Errorf("--reload sends %s and cannot be combined with %s", docker.ReloadSignal, signal)
+	}
+	return signal, nil
+}
+
+func runSignal(cmd *cobra.Command, args []string, reload bool) error {
+	projectID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+	}
+	service := args[1]
+
+	signal, err := resolveSignal(args, reload)
+	if err != nil {
+		return err
+	}
+
+	projectService := app.GetProjectService()
+
+	outputChan := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range outputChan {
+			kind := output.Plain
+			switch msg.Type {
+			case "success":
+				kind = output.Success
+			case "error":
+				kind = output.Error
+			}
+			if err := output.FprintCmd(cmd, kind, "%s", msg.Content); err != nil {
+				return
+			}
+		}
+	}()
+
+	err = projectService.KillService(projectID, service, signal, outputChan)
+	close(outputChan)
+	<-done
+
+	return err
+}
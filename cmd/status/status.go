@@ -0,0 +1,137 @@
+// Package status implements the status command, an overview of the live status of all projects.
+package status
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	oarproject "github.com/oar-cd/oar/project"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdStatus creates the status command
+func NewCmdStatus() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the live status of all projects",
+		Long: `Display the live status of every project: whether it is running, its uptime,
+and how many of its containers are running. With --label, only the projects
+whose containers carry the label are shown, e.g. --label team=payments.
+
+With --watch, the status is refreshed every --interval until Ctrl+C, like top.
+On a terminal the table is redrawn in place; otherwise, for example when the
+output is piped or TERM is dumb, each refresh is appended below the last one.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runStatus(cmd)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolP("watch", "w", false, "Refresh the status until interrupted")
+	cmd.Flags().Duration("interval", 2*time.Second, "Time between refreshes with --watch")
+	cmd.Flags().
+		StringArray("label", nil, "Only show projects with this container label, as key or key=value (repeatable)")
+
+	return cmd
+}
+
+func runStatus(cmd *cobra.Command) error {
+	watch, _ := cmd.Flags().GetBool("watch")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	labels, _ := cmd.Flags().GetStringArray("label")
+	if interval < time.Second {
+		return fmt.Errorf("interval must be at least 1s, got %s", interval)
+	}
+
+	if !watch {
+		table, err := statusTable(labels)
+		if err != nil {
+			return err
+		}
+		return output.FprintPlain(cmd, "%s", table)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	out := cmd.OutOrStdout()
+	redraw := canRedraw(out)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		table, err := statusTable(labels)
+		if err != nil {
+			return err
+		}
+		if err := printRefresh(out, table, interval, redraw); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusTable queries the live status of all projects, or of those with the given container labels,
+// and formats it
+func statusTable(labels []string) (string, error) {
+	projectService := app.GetProjectService()
+	projects, err := projectService.List()
+	if err != nil {
+		return "", fmt.Errorf("failed to list projects: %w", err)
+	}
+	if len(labels) > 0 {
+		if projects, err = oarproject.FilterByLabels(projects, labels); err != nil {
+			return "", err
+		}
+	}
+
+	ids := make([]uuid.UUID, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+	}
+	statuses, errs := projectService.GetStatusMany(ids)
+	return output.PrintStatusTable(projects, statuses, errs)
+}
+
+// printRefresh prints one refresh of the watched status, in place of the previous one when redraw
+// is set
+func printRefresh(out io.Writer, table string, interval time.Duration, redraw bool) error {
+	header := fmt.Sprintf("Every %s: oar status    %s", interval, time.Now().Format("2006-01-02 15:04:05"))
+	if redraw {
+		_, err := fmt.Fprintf(out, "%s%s\nPress Ctrl+C to stop\n\n%s", output.ClearScreen, header, table)
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%s\n%s\n", header, table)
+	return err
+}
+
+// canRedraw reports whether out is a terminal that understands the escape sequences that clear
+// the screen
+func canRedraw(out io.Writer) bool {
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
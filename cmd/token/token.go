@@ -0,0 +1,154 @@
+// Package token provides commands for managing the tokens of the JSON API.
+package token
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/domain"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdToken() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens",
+		Long: `Manage the tokens that authenticate requests to the JSON API.
+
+Requests send a token as "Authorization: Bearer <token>". A token only allows the actions it was
+created with: read (deployments, logs, declared services and statuses), deploy (watcher checks
+and push webhooks) and annotate (deployment notes and labels). A token created with --project
+only works for that project. Set http.require_api_token to refuse requests without a token.`,
+	}
+
+	cmd.AddCommand(NewCmdTokenCreate())
+	cmd.AddCommand(NewCmdTokenList())
+	cmd.AddCommand(NewCmdTokenRevoke())
+	return cmd
+}
+
+func NewCmdTokenCreate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API token",
+		Long: `Create an API token and print it. Only a hash of the token is stored, so it cannot be
+shown again.
+
+  oar token create ci --project <project-id> --action read --action deploy`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runTokenCreate(cmd, args)
+			if err != nil {
+				// Silence usage for runtime errors (not argument validation errors)
+				cmd.SilenceUsage = true
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().String("project", "", "Only allow the token for this project (default: all projects)")
+	cmd.Flags().StringArray("action", []string{string(domain.APITokenActionRead)},
+		"Action the token allows: read, deploy or annotate (repeatable)")
+
+	return cmd
+}
+
+func runTokenCreate(cmd *cobra.Command, args []string) error {
+	projectFlag, _ := cmd.Flags().GetString("project")
+	actionFlags, _ := cmd.Flags().GetStringArray("action")
+
+	var projectID *uuid.UUID
+	if projectFlag != "" {
+		id, err := uuid.Parse(projectFlag)
+		if err != nil {
+			return fmt.Errorf("invalid project ID '%s': must be a valid UUID", projectFlag)
+		}
+		if _, err := app.GetProjectService().Get(id); err != nil {
+			return fmt.Errorf("project %s not found", id)
+		}
+		projectID = &id
+	}
+
+	var actions []domain.APITokenAction
+	for _, flag := range actionFlags {
+		action, err := domain.ParseAPITokenAction(flag)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, action)
+	}
+
+	token, value, err := app.GetAPITokenService().Create(args[0], projectID, actions)
+	if err != nil {
+		return err
+	}
+
+	if err := output.FprintSuccess(cmd, "API token %s created: %s", token.Name, token.ID); err != nil {
+		return fmt.Errorf("failed to print output: %w", err)
+	}
+	return output.FprintPlain(cmd, "%s", value)
+}
+
+func NewCmdTokenList() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokens, err := app.GetAPITokenService().List()
+			if err != nil {
+				return err
+			}
+			if len(tokens) == 0 {
+				return output.FprintPlain(cmd, "No API tokens found.")
+			}
+
+			rows := make([][]string, len(tokens))
+			for i, token := range tokens {
+				project := "all"
+				if token.ProjectID != nil {
+					project = token.ProjectID.String()
+				}
+				actions := make([]string, len(token.Actions))
+				for j, action := range token.Actions {
+					actions[j] = string(action)
+				}
+				rows[i] = []string{
+					token.ID.String(),
+					token.Name,
+					project,
+					strings.Join(actions, ", "),
+					token.CreatedAt.Format("2006-01-02 15:04"),
+				}
+			}
+
+			out, err := output.PrintTable([]string{"ID", "Name", "Project", "Actions", "Created"}, rows)
+			if err != nil {
+				return err
+			}
+			return output.FprintPlain(cmd, "%s", out)
+		},
+	}
+}
+
+func NewCmdTokenRevoke() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <token-id>",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid token ID '%s': must be a valid UUID", args[0])
+			}
+			if err := app.GetAPITokenService().Revoke(id); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			return output.FprintSuccess(cmd, "API token %s revoked", id)
+		},
+	}
+}
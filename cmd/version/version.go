@@ -2,27 +2,74 @@
 package version
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/update"
 	"github.com/spf13/cobra"
 )
 
+// versionOutput is the JSON output, with the result of the update check when one was requested
+type versionOutput struct {
+	app.BuildInfo
+	Update *update.Result `json:"update,omitempty"`
+}
+
 // NewCmdVersion creates the version command
 func NewCmdVersion() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Show version information",
-		Long:  `Display version information for Oar.`,
+		Long: `Display version information for Oar: the version, the git commit and date of the build,
+and the Go version it was built with. The version alone is on the first line.
+
+With --check, Oar also asks GitHub for the latest release and tells whether a newer one is
+available. Nothing is added when the check fails, such as on a host without internet access.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runVersion()
+			return runVersion(cmd)
 		},
 	}
 
+	cmd.Flags().Bool("json", false, "Print the build information as JSON")
+	cmd.Flags().Bool("check", false, "Check whether a newer release is available")
+
 	return cmd
 }
 
-func runVersion() error {
-	fmt.Println(app.Version)
-	return nil
+func runVersion(cmd *cobra.Command) error {
+	output := versionOutput{BuildInfo: app.GetBuildInfo()}
+
+	if check, _ := cmd.Flags().GetBool("check"); check {
+		checker := update.NewChecker(output.Version, update.DefaultReleasesURL, 0)
+		if result, err := checker.Check(cmd.Context()); err == nil {
+			output.Update = &result
+		}
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		encoded, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode build information: %w", err)
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(encoded))
+		return err
+	}
+
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "%s\nCommit:     %s\nBuilt:      %s\nGo version: %s\n",
+		output.Version, output.Commit, output.BuildDate, output.GoVersion); err != nil {
+		return err
+	}
+
+	if output.Update == nil {
+		return nil
+	}
+	var err error
+	if output.Update.UpdateAvailable {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Update:     %s is available, see %s\n",
+			output.Update.Latest, output.Update.URL)
+	} else {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "Update:     none, the latest release is %s\n", output.Update.Latest)
+	}
+	return err
 }
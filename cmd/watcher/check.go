@@ -0,0 +1,49 @@
+package watcher
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/watcher"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdWatcherCheck() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check <project-id>",
+		Short: "Check a project for new commits immediately",
+		Long: `Ask the watcher of a running 'oar server' to check a project right away
+instead of waiting for the next poll.
+
+If automatic deployment is enabled for the project and new commits are found,
+they are deployed before the command returns.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid project ID '%s': must be a valid UUID", args[0])
+			}
+
+			var check watcher.ProjectCheck
+			if err := doJSON(http.MethodPost, "/api/v1/watcher/check/"+projectID.String(), noTimeout, &check); err != nil {
+				return fmt.Errorf("failed to check project: %w", err)
+			}
+
+			switch check.Outcome {
+			case watcher.CheckOutcomeDeployed:
+				return output.FprintSuccess(cmd, "Project %s: new commit deployed", check.ProjectName)
+			case watcher.CheckOutcomeUpdatesAvailable:
+				return output.FprintWarning(cmd, "Project %s: updates available", check.ProjectName)
+			case watcher.CheckOutcomeDeferred:
+				return output.FprintWarning(cmd,
+					"Project %s: deployment deferred, another operation on the project is in progress", check.ProjectName)
+			case watcher.CheckOutcomeError:
+				return fmt.Errorf("check of project %s failed: %s", check.ProjectName, check.Error)
+			default:
+				return output.FprintPlain(cmd, "Project %s: no changes", check.ProjectName)
+			}
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/oar-cd/oar/app"
+)
+
+// The watcher runs inside the server process, so its state is only reachable over HTTP
+const requestTimeout = 10 * time.Second
+
+// apiTokenEnv holds the API token sent with requests, needed when the server requires one
+const apiTokenEnv = "OAR_API_TOKEN"
+
+// noTimeout leaves a request unbounded. A check waits for the git fetch and, with automatic
+// deployment enabled, for the deployment, which the server bounds by its own git and compose timeouts.
+const noTimeout = 0
+
+// serverURL builds the base URL of the local Oar server from the loaded configuration
+func serverURL() (string, error) {
+	cfg := app.GetConfig()
+	if cfg == nil {
+		return "", fmt.Errorf("application not initialized - config is nil")
+	}
+
+	host := cfg.HTTPHost
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.HTTPPort)), nil
+}
+
+// doJSON sends a request to the server API and decodes the JSON response into result. A zero
+// timeout waits for the response as long as it takes.
+func doJSON(method, path string, timeout time.Duration, result any) error {
+	baseURL, err := serverURL()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if token := os.Getenv(apiTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Oar server at %s (is 'oar server' running?): %w", baseURL, err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Debug("Failed to close response body", "error", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error != "" {
+			return fmt.Errorf("server returned %s: %s", resp.Status, apiErr.Error)
+		}
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode server response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/domain"
+	oarproject "github.com/oar-cd/oar/project"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdWatcherDisable() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disable [project-id...]",
+		Short: "Stop the watcher from checking and deploying projects",
+		Long: `Stop the deployment watcher from checking projects for new commits and deploying them,
+selected by ID or by container label, e.g. --label env=staging for all staging projects.
+
+The projects are skipped from the next poll on, and pushes to their webhooks are ignored. This
+holds on top of the automatic deployment setting of each project. Deployments and checks started
+by hand, such as 'oar watcher check', still run. Use 'oar watcher enable' to undo it.
+
+Examples:
+  oar watcher disable --label env=staging
+  oar watcher disable <project-id> <project-id>`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setWatcherDisabled(cmd, args, true)
+		},
+	}
+	cmd.Flags().StringArray("label", nil, "Select projects with this container label, as key or key=value (repeatable)")
+	return cmd
+}
+
+func NewCmdWatcherEnable() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enable [project-id...]",
+		Short: "Let the watcher check and deploy projects again",
+		Long: `Let the deployment watcher check projects disabled with 'oar watcher disable' again,
+selected by ID or by container label. Whether new commits are deployed depends on the automatic
+deployment setting of each project.
+
+Examples:
+  oar watcher enable --label env=staging`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setWatcherDisabled(cmd, args, false)
+		},
+	}
+	cmd.Flags().StringArray("label", nil, "Select projects with this container label, as key or key=value (repeatable)")
+	return cmd
+}
+
+// setWatcherDisabled disables or enables the watcher for the projects given by ID or by label. The
+// projects are changed in the database, which the watcher of a running server reads on each poll.
+func setWatcherDisabled(cmd *cobra.Command, args []string, disabled bool) error {
+	labels, _ := cmd.Flags().GetStringArray("label")
+	if len(args) == 0 && len(labels) == 0 {
+		return errors.New("select projects by ID or with --label")
+	}
+	if len(args) > 0 && len(labels) > 0 {
+		return errors.New("select projects by ID or with --label, not both")
+	}
+
+	projectService := app.GetProjectService()
+	var projects []*domain.Project
+	if len(labels) > 0 {
+		all, err := projectService.List()
+		if err != nil {
+			return err
+		}
+		if projects, err = oarproject.FilterByLabels(all, labels); err != nil {
+			return err
+		}
+		if len(projects) == 0 {
+			return output.FprintPlain(cmd, "No projects match the labels.")
+		}
+	}
+	for _, arg := range args {
+		projectID, err := uuid.Parse(arg)
+		if err != nil {
+			return fmt.Errorf("invalid project ID '%s': must be a valid UUID", arg)
+		}
+		project, err := projectService.Get(projectID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve project %s: %w", projectID, err)
+		}
+		projects = append(projects, project)
+	}
+
+	ids := make([]uuid.UUID, len(projects))
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		ids[i] = project.ID
+		names[i] = project.Name
+	}
+	if err := projectService.SetWatcherDisabled(ids, disabled); err != nil {
+		return fmt.Errorf("failed to change the watcher of the projects: %w", err)
+	}
+
+	state := "enabled"
+	if disabled {
+		state = "disabled"
+	}
+	return output.FprintSuccess(cmd, "Watcher %s for %s", state, strings.Join(names, ", "))
+}
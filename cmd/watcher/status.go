@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oar-cd/oar/cmd/output"
+	"github.com/oar-cd/oar/watcher"
+	"github.com/spf13/cobra"
+)
+
+func NewCmdWatcherStatus() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of the deployment watcher",
+		Long: `Display the state of the deployment watcher running inside 'oar server'.
+
+Shows when the watcher last polled and will poll next, whether it is paused,
+the result of the most recent check for each project, including the
+number of consecutive failed checks, and how many deployments are running
+or waiting for a slot across all projects.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var status watcher.WatcherStatus
+			if err := doJSON(http.MethodGet, "/api/v1/watcher", requestTimeout, &status); err != nil {
+				return fmt.Errorf("failed to get watcher status: %w", err)
+			}
+
+			state := "active"
+			if status.Paused {
+				state = "paused"
+			}
+
+			deploys := strconv.Itoa(status.Deploys.Running)
+			if status.Deploys.Limit > 0 {
+				deploys = fmt.Sprintf("%d of %d running, %d queued",
+					status.Deploys.Running, status.Deploys.Limit, status.Deploys.Queued)
+			}
+
+			summary, err := output.PrintTable(nil, [][]string{
+				{"State", state},
+				{"Poll Interval", status.PollInterval.String()},
+				{"Last Poll", formatTime(status.LastPoll)},
+				{"Next Poll", formatTime(status.NextPoll)},
+				{"Projects", strconv.Itoa(status.ProjectCount)},
+				{"Deployments", deploys},
+			})
+			if err != nil {
+				return err
+			}
+			if err := output.FprintPlain(cmd, "%s", summary); err != nil {
+				return err
+			}
+
+			if len(status.Projects) == 0 {
+				return nil
+			}
+
+			data := make([][]string, 0, len(status.Projects))
+			for _, check := range status.Projects {
+				data = append(data, []string{
+					check.ProjectName,
+					formatTime(check.CheckedAt),
+					string(check.Outcome),
+					strconv.Itoa(check.ConsecutiveFailures),
+					check.Error,
+				})
+			}
+
+			projects, err := output.PrintTable([]string{"Project", "Checked", "Result", "Failures", "Error"}, data)
+			if err != nil {
+				return err
+			}
+			return output.FprintPlain(cmd, "\n%s", projects)
+		},
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
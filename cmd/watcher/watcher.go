@@ -0,0 +1,18 @@
+// Package watcher provides commands for inspecting and controlling the deployment watcher of a running Oar
+// server.
+package watcher
+
+import "github.com/spf13/cobra"
+
+func NewCmdWatcher() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watcher",
+		Short: "Inspect and control the deployment watcher of a running server",
+	}
+
+	cmd.AddCommand(NewCmdWatcherStatus())
+	cmd.AddCommand(NewCmdWatcherCheck())
+	cmd.AddCommand(NewCmdWatcherDisable())
+	cmd.AddCommand(NewCmdWatcherEnable())
+	return cmd
+}
@@ -6,9 +6,16 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/docker/go-units"
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/logging"
+	"github.com/oar-cd/oar/notify"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,27 +42,123 @@ func (p *DefaultEnvProvider) Getenv(key string) string {
 
 // YamlConfig represents the YAML configuration file structure
 type YamlConfig struct {
-	DataDir       string        `yaml:"data_dir"`
-	DatabasePath  string        `yaml:"database_path,omitempty"`
-	LogLevel      string        `yaml:"log_level,omitempty"`
-	HTTP          HTTPConfig    `yaml:"http,omitempty"`
-	Git           GitConfig     `yaml:"git,omitempty"`
-	Watcher       WatcherConfig `yaml:"watcher,omitempty"`
-	EncryptionKey string        `yaml:"encryption_key"`
+	DataDir       string            `yaml:"data_dir"`
+	DatabasePath  string            `yaml:"database_path,omitempty"`
+	Database      DBConfig          `yaml:"database,omitempty"`
+	LogLevel      string            `yaml:"log_level,omitempty"`
+	LogFormat     string            `yaml:"log_format,omitempty"`
+	LogFile       string            `yaml:"log_file,omitempty"`
+	LogMaxSizeMB  *int              `yaml:"log_max_size_mb,omitempty"`
+	LogMaxBackups *int              `yaml:"log_max_backups,omitempty"`
+	HTTP          HTTPConfig        `yaml:"http,omitempty"`
+	Git           GitConfig         `yaml:"git,omitempty"`
+	Docker        DockerConfig      `yaml:"docker,omitempty"`
+	Compose       ComposeConfig     `yaml:"compose,omitempty"`
+	Watcher       WatcherConfig     `yaml:"watcher,omitempty"`
+	Webhook       WebhookConfig     `yaml:"webhook,omitempty"`
+	Notifications NotifyConfig      `yaml:"notifications,omitempty"`
+	Maintenance   MaintenanceConfig `yaml:"maintenance,omitempty"`
+	Retention     RetentionConfig   `yaml:"retention,omitempty"`
+	DiskUsage     DiskUsageConfig   `yaml:"disk_usage,omitempty"`
+	UpdateCheck   UpdateCheckConfig `yaml:"update_check,omitempty"`
+	EncryptionKey string            `yaml:"encryption_key"`
+}
+
+type DBConfig struct {
+	BusyTimeout     string `yaml:"busy_timeout,omitempty"`
+	MaxOpenConns    *int   `yaml:"max_open_conns,omitempty"`
+	MaxIdleConns    *int   `yaml:"max_idle_conns,omitempty"`
+	ConnMaxLifetime string `yaml:"conn_max_lifetime,omitempty"`
 }
 
 type HTTPConfig struct {
 	Host string `yaml:"host,omitempty"`
 	Port int    `yaml:"port,omitempty"`
+	// RequireAPIToken refuses JSON API requests that carry no API token
+	RequireAPIToken *bool `yaml:"require_api_token,omitempty"`
 }
 
 type GitConfig struct {
-	Timeout string `yaml:"timeout,omitempty"`
+	Timeout       string `yaml:"timeout,omitempty"`
+	ResetOnDeploy *bool  `yaml:"reset_on_deploy,omitempty"`
+	Retries       *int   `yaml:"retries,omitempty"`
+	RetryDelay    string `yaml:"retry_delay,omitempty"`
+}
+
+type DockerConfig struct {
+	Host string `yaml:"host,omitempty"`
+	// CommandPrefix is run in front of docker commands, one argument per item
+	CommandPrefix []string `yaml:"command_prefix,omitempty"`
+}
+
+type ComposeConfig struct {
+	QueryTimeout string `yaml:"query_timeout,omitempty"`
+	DownTimeout  string `yaml:"down_timeout,omitempty"`
+	Progress     string `yaml:"progress,omitempty"`
+	StoredOutput string `yaml:"stored_output,omitempty"`
+	MaxDeploys   *int   `yaml:"max_concurrent_deploys,omitempty"`
+	Parallel     *int   `yaml:"parallel,omitempty"`
+	// CompressStoredOutput stores deployment output gzip-compressed
+	CompressStoredOutput *bool `yaml:"compress_stored_output,omitempty"`
+	// DeployEnv is passed to compose as OAR_ENV
+	DeployEnv string `yaml:"deploy_env,omitempty"`
+	// RegistryMirror and Offline are the defaults for projects that do not set their own
+	RegistryMirror string `yaml:"registry_mirror,omitempty"`
+	Offline        *bool  `yaml:"offline,omitempty"`
+	// DefaultFiles are the compose files of new projects created without any
+	DefaultFiles []string `yaml:"default_files,omitempty"`
 }
 
 type WatcherConfig struct {
-	Enabled      *bool  `yaml:"enabled,omitempty"`
-	PollInterval string `yaml:"poll_interval,omitempty"`
+	Enabled           *bool  `yaml:"enabled,omitempty"`
+	PollInterval      string `yaml:"poll_interval,omitempty"`
+	CheckDependencies *bool  `yaml:"check_dependencies,omitempty"`
+}
+
+type WebhookConfig struct {
+	QuietPeriod string `yaml:"quiet_period,omitempty"`
+	MaxDelay    string `yaml:"max_delay,omitempty"`
+}
+
+type NotifyConfig struct {
+	WebhookURL string            `yaml:"webhook_url,omitempty"`
+	Channel    string            `yaml:"channel,omitempty"`
+	Template   string            `yaml:"template,omitempty"`
+	Email      NotifyEmailConfig `yaml:"email,omitempty"`
+}
+
+type MaintenanceConfig struct {
+	Interval string `yaml:"interval,omitempty"`
+}
+
+type DiskUsageConfig struct {
+	// Warning is a size such as 10GB, projects using more disk space are flagged
+	Warning string `yaml:"warning,omitempty"`
+}
+
+type UpdateCheckConfig struct {
+	Enabled  *bool  `yaml:"enabled,omitempty"`
+	Interval string `yaml:"interval,omitempty"`
+}
+
+type RetentionConfig struct {
+	Deployments RetentionPolicy `yaml:"deployments,omitempty"`
+}
+
+// RetentionPolicy limits how long the records of a history table are kept
+type RetentionPolicy struct {
+	MaxAge   string `yaml:"max_age,omitempty"`
+	MaxCount *int   `yaml:"max_count,omitempty"`
+}
+
+type NotifyEmailConfig struct {
+	Host     string   `yaml:"host,omitempty"`
+	Port     int      `yaml:"port,omitempty"`
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from,omitempty"`
+	To       []string `yaml:"to,omitempty"`
+	TLS      string   `yaml:"tls,omitempty"`
 }
 
 // Config holds configuration for all services
@@ -66,19 +169,79 @@ type Config struct {
 	TmpDir       string
 	WorkspaceDir string
 
+	// Database connections
+	DatabaseBusyTimeout     time.Duration // How long to wait for a locked database before failing
+	DatabaseMaxOpenConns    int           // 0 for no limit
+	DatabaseMaxIdleConns    int
+	DatabaseConnMaxLifetime time.Duration // 0 to keep connections open indefinitely
+
 	// Logging
-	LogLevel string
+	LogLevel      string
+	LogFormat     string // text or json
+	LogFile       string // Server log file, logs go to stderr when empty
+	LogMaxSizeMB  int    // Rotate the log file at this size, 0 disables rotation
+	LogMaxBackups int    // Rotated log files to keep
 
 	// HTTP server
-	HTTPHost string
-	HTTPPort int
+	HTTPHost            string
+	HTTPPort            int
+	HTTPRequireAPIToken bool // Refuse JSON API requests without an API token, instead of only checking tokens sent
 
 	// Git
-	GitTimeout time.Duration
+	GitTimeout       time.Duration
+	GitResetOnDeploy bool          // Discard local changes in a project's git directory instead of failing the pull
+	GitRetries       int           // Retries of a clone, pull or fetch that failed with a transient error
+	GitRetryDelay    time.Duration // Wait before the first retry, doubled for each further retry
+
+	// Docker daemon
+	DockerHost          string   // Daemon address for the docker CLI and the Docker API, empty for DOCKER_HOST
+	DockerCommandPrefix []string // Command run in front of docker, such as sudo
+
+	// Docker Compose
+	ComposeQueryTimeout   time.Duration // Bounds status and config calls, not deployments
+	ComposeDownTimeout    time.Duration // Bounds stopping a project before its containers are force removed
+	ComposeProgress       string        // Progress mode for streamed commands: plain, tty, quiet or json
+	ComposeStoredOutput   string        // Format of deployment output kept in history: ansi (as received) or plain
+	ComposeCompressOutput bool          // Store deployment output gzip-compressed
+	ComposeMaxDeploys     int           // Deployments that may run at once across all projects, 0 for no limit
+	ComposeParallel       int           // Images a deployment pulls or builds at once, 0 for the Compose default
+	ComposeDeployEnv      string        // Deploy environment passed to Compose as OAR_ENV, such as production
+	ComposeRegistryMirror string        // Registry to pull Docker Hub images from, empty to pull from Docker Hub
+	ComposeOffline        bool          // Never pull images, deploy only with images present locally
+	ComposeDefaultFiles   []string      // Compose files of new projects that list none, empty to discover them
 
 	// Watcher
-	WatcherEnabled      bool
-	WatcherPollInterval time.Duration
+	WatcherEnabled           bool
+	WatcherPollInterval      time.Duration
+	WatcherCheckDependencies bool // Skip automatic deployments while a prerequisite project is not running
+
+	// Webhooks
+	WebhookQuietPeriod time.Duration // Wait for pushes to settle before deploying
+	WebhookMaxDelay    time.Duration // Deploy at the latest this long after the first push, even if pushes continue
+
+	// Deployment notifications, the default for projects without their own route or template
+	NotifyWebhookURL   string // Notifications are not sent when neither this nor the project sets a URL
+	NotifyChannel      string
+	NotifyTemplate     string // Go template over notify.Event, notify.DefaultTemplate when empty
+	NotifySMTPHost     string // Email notifications are not sent when neither this nor the project sets a host
+	NotifySMTPPort     int    // 0 for the usual port of the TLS mode
+	NotifySMTPUsername string
+	NotifySMTPPassword string
+	NotifySMTPTLS      string // starttls, tls or none
+	NotifyEmailFrom    string
+	NotifyEmailTo      []string
+
+	// Maintenance and retention of history
+	MaintenanceInterval         time.Duration // How often the server prunes history, 0 for oar maintenance run only
+	DeploymentRetentionMaxAge   time.Duration // Deployments older than this are pruned, 0 for no age limit
+	DeploymentRetentionMaxCount int           // Deployments kept per project, 0 for no limit
+
+	// Disk usage
+	DiskUsageWarning int64 // Projects using more bytes than this are flagged, 0 to flag none
+
+	// Update check
+	UpdateCheckEnabled  bool          // Query the latest release and show when a newer one is available
+	UpdateCheckInterval time.Duration // How often the server checks for a newer release
 
 	// Encryption
 	EncryptionKey string
@@ -132,14 +295,54 @@ func NewConfigWithEnv(configPath string, env EnvProvider, options ...ConfigOptio
 		"config_path", configPath,
 		"data_dir", c.DataDir,
 		"database_path", c.DatabasePath,
+		"database_busy_timeout", c.DatabaseBusyTimeout,
+		"database_max_open_conns", c.DatabaseMaxOpenConns,
+		"database_max_idle_conns", c.DatabaseMaxIdleConns,
+		"database_conn_max_lifetime", c.DatabaseConnMaxLifetime,
 		"tmp_dir", c.TmpDir,
 		"workspace_dir", c.WorkspaceDir,
 		"log_level", c.LogLevel,
+		"log_format", c.LogFormat,
+		"log_file", c.LogFile,
+		"log_max_size_mb", c.LogMaxSizeMB,
+		"log_max_backups", c.LogMaxBackups,
 		"http_host", c.HTTPHost,
 		"http_port", c.HTTPPort,
+		"http_require_api_token", c.HTTPRequireAPIToken,
 		"git_timeout", c.GitTimeout,
+		"git_reset_on_deploy", c.GitResetOnDeploy,
+		"git_retries", c.GitRetries,
+		"git_retry_delay", c.GitRetryDelay,
+		"docker_host", c.DockerHost,
+		"docker_command_prefix", c.DockerCommandPrefix,
+		"compose_query_timeout", c.ComposeQueryTimeout,
+		"compose_down_timeout", c.ComposeDownTimeout,
+		"compose_progress", c.ComposeProgress,
+		"compose_stored_output", c.ComposeStoredOutput,
+		"compose_compress_stored_output", c.ComposeCompressOutput,
+		"compose_max_concurrent_deploys", c.ComposeMaxDeploys,
+		"compose_parallel", c.ComposeParallel,
+		"compose_deploy_env", c.ComposeDeployEnv,
+		"compose_registry_mirror", c.ComposeRegistryMirror,
+		"compose_offline", c.ComposeOffline,
+		"compose_default_files", c.ComposeDefaultFiles,
 		"watcher_enabled", c.WatcherEnabled,
 		"watcher_poll_interval", c.WatcherPollInterval,
+		"watcher_check_dependencies", c.WatcherCheckDependencies,
+		"webhook_quiet_period", c.WebhookQuietPeriod,
+		"webhook_max_delay", c.WebhookMaxDelay,
+		"has_notify_webhook_url", c.NotifyWebhookURL != "",
+		"notify_channel", c.NotifyChannel,
+		"notify_smtp_host", c.NotifySMTPHost,
+		"notify_smtp_port", c.NotifySMTPPort,
+		"notify_smtp_tls", c.NotifySMTPTLS,
+		"notify_email_to", c.NotifyEmailTo,
+		"maintenance_interval", c.MaintenanceInterval,
+		"retention_deployments_max_age", c.DeploymentRetentionMaxAge,
+		"retention_deployments_max_count", c.DeploymentRetentionMaxCount,
+		"disk_usage_warning", c.DiskUsageWarning,
+		"update_check_enabled", c.UpdateCheckEnabled,
+		"update_check_interval", c.UpdateCheckInterval,
 		"has_encryption_key", c.EncryptionKey != "")
 
 	return c, nil
@@ -159,11 +362,29 @@ func WithCLIDefaults() ConfigOption {
 func (c *Config) setDefaults() {
 	c.DataDir = DataDir
 	c.LogLevel = "info"
+	c.LogFormat = "text"
+	c.LogMaxSizeMB = 100
+	c.LogMaxBackups = 5
+	dbOptions := db.DefaultOptions()
+	c.DatabaseBusyTimeout = dbOptions.BusyTimeout
+	c.DatabaseMaxOpenConns = dbOptions.MaxOpenConns
+	c.DatabaseMaxIdleConns = dbOptions.MaxIdleConns
+	c.DatabaseConnMaxLifetime = dbOptions.ConnMaxLifetime
 	c.HTTPHost = "127.0.0.1"
 	c.HTTPPort = 4777
 	c.GitTimeout = 5 * time.Minute
+	c.GitRetries = 2
+	c.GitRetryDelay = 2 * time.Second
+	c.ComposeQueryTimeout = 10 * time.Second
+	c.ComposeDownTimeout = 2 * time.Minute
+	c.ComposeProgress = "plain"
+	c.ComposeStoredOutput = "ansi"
 	c.WatcherEnabled = true
 	c.WatcherPollInterval = 5 * time.Minute
+	c.WebhookQuietPeriod = 10 * time.Second
+	c.WebhookMaxDelay = 2 * time.Minute
+	c.MaintenanceInterval = 24 * time.Hour
+	c.UpdateCheckInterval = 24 * time.Hour
 	// Don't set default encryption key - it must be provided explicitly
 }
 
@@ -179,10 +400,54 @@ func (c *Config) loadFromEnv() {
 		c.DatabasePath = v
 		envVarsFound = append(envVarsFound, "OAR_DATABASE_PATH")
 	}
+	if v := c.env.Getenv("OAR_DATABASE_BUSY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.DatabaseBusyTimeout = d
+			envVarsFound = append(envVarsFound, "OAR_DATABASE_BUSY_TIMEOUT")
+		}
+	}
+	if v := c.env.Getenv("OAR_DATABASE_MAX_OPEN_CONNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.DatabaseMaxOpenConns = n
+			envVarsFound = append(envVarsFound, "OAR_DATABASE_MAX_OPEN_CONNS")
+		}
+	}
+	if v := c.env.Getenv("OAR_DATABASE_MAX_IDLE_CONNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.DatabaseMaxIdleConns = n
+			envVarsFound = append(envVarsFound, "OAR_DATABASE_MAX_IDLE_CONNS")
+		}
+	}
+	if v := c.env.Getenv("OAR_DATABASE_CONN_MAX_LIFETIME"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.DatabaseConnMaxLifetime = d
+			envVarsFound = append(envVarsFound, "OAR_DATABASE_CONN_MAX_LIFETIME")
+		}
+	}
 	if v := c.env.Getenv("OAR_LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 		envVarsFound = append(envVarsFound, "OAR_LOG_LEVEL")
 	}
+	if v := c.env.Getenv("OAR_LOG_FORMAT"); v != "" {
+		c.LogFormat = v
+		envVarsFound = append(envVarsFound, "OAR_LOG_FORMAT")
+	}
+	if v := c.env.Getenv("OAR_LOG_FILE"); v != "" {
+		c.LogFile = v
+		envVarsFound = append(envVarsFound, "OAR_LOG_FILE")
+	}
+	if v := c.env.Getenv("OAR_LOG_MAX_SIZE_MB"); v != "" {
+		if size, err := strconv.Atoi(v); err == nil {
+			c.LogMaxSizeMB = size
+			envVarsFound = append(envVarsFound, "OAR_LOG_MAX_SIZE_MB")
+		}
+	}
+	if v := c.env.Getenv("OAR_LOG_MAX_BACKUPS"); v != "" {
+		if backups, err := strconv.Atoi(v); err == nil {
+			c.LogMaxBackups = backups
+			envVarsFound = append(envVarsFound, "OAR_LOG_MAX_BACKUPS")
+		}
+	}
 	if v := c.env.Getenv("OAR_HTTP_HOST"); v != "" {
 		c.HTTPHost = v
 		envVarsFound = append(envVarsFound, "OAR_HTTP_HOST")
@@ -193,12 +458,100 @@ func (c *Config) loadFromEnv() {
 			envVarsFound = append(envVarsFound, "OAR_HTTP_PORT")
 		}
 	}
+	if v := c.env.Getenv("OAR_HTTP_REQUIRE_API_TOKEN"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.HTTPRequireAPIToken = b
+			envVarsFound = append(envVarsFound, "OAR_HTTP_REQUIRE_API_TOKEN")
+		}
+	}
 	if v := c.env.Getenv("OAR_GIT_TIMEOUT"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			c.GitTimeout = d
 			envVarsFound = append(envVarsFound, "OAR_GIT_TIMEOUT")
 		}
 	}
+	if v := c.env.Getenv("OAR_GIT_RESET_ON_DEPLOY"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.GitResetOnDeploy = b
+			envVarsFound = append(envVarsFound, "OAR_GIT_RESET_ON_DEPLOY")
+		}
+	}
+	if v := c.env.Getenv("OAR_GIT_RETRIES"); v != "" {
+		if retries, err := strconv.Atoi(v); err == nil {
+			c.GitRetries = retries
+			envVarsFound = append(envVarsFound, "OAR_GIT_RETRIES")
+		}
+	}
+	if v := c.env.Getenv("OAR_GIT_RETRY_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.GitRetryDelay = d
+			envVarsFound = append(envVarsFound, "OAR_GIT_RETRY_DELAY")
+		}
+	}
+	if v := c.env.Getenv("OAR_DOCKER_HOST"); v != "" {
+		c.DockerHost = v
+		envVarsFound = append(envVarsFound, "OAR_DOCKER_HOST")
+	}
+	if v := c.env.Getenv("OAR_DOCKER_COMMAND_PREFIX"); v != "" {
+		c.DockerCommandPrefix = strings.Fields(v)
+		envVarsFound = append(envVarsFound, "OAR_DOCKER_COMMAND_PREFIX")
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_QUERY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.ComposeQueryTimeout = d
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_QUERY_TIMEOUT")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_DOWN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.ComposeDownTimeout = d
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_DOWN_TIMEOUT")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_PROGRESS"); v != "" {
+		c.ComposeProgress = v
+		envVarsFound = append(envVarsFound, "OAR_COMPOSE_PROGRESS")
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_STORED_OUTPUT"); v != "" {
+		c.ComposeStoredOutput = v
+		envVarsFound = append(envVarsFound, "OAR_COMPOSE_STORED_OUTPUT")
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_COMPRESS_STORED_OUTPUT"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.ComposeCompressOutput = b
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_COMPRESS_STORED_OUTPUT")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_MAX_CONCURRENT_DEPLOYS"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil {
+			c.ComposeMaxDeploys = limit
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_MAX_CONCURRENT_DEPLOYS")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_PARALLEL"); v != "" {
+		if limit, err := strconv.Atoi(v); err == nil {
+			c.ComposeParallel = limit
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_PARALLEL")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_DEPLOY_ENV"); v != "" {
+		c.ComposeDeployEnv = v
+		envVarsFound = append(envVarsFound, "OAR_COMPOSE_DEPLOY_ENV")
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_REGISTRY_MIRROR"); v != "" {
+		c.ComposeRegistryMirror = v
+		envVarsFound = append(envVarsFound, "OAR_COMPOSE_REGISTRY_MIRROR")
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_OFFLINE"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.ComposeOffline = b
+			envVarsFound = append(envVarsFound, "OAR_COMPOSE_OFFLINE")
+		}
+	}
+	if v := c.env.Getenv("OAR_COMPOSE_DEFAULT_FILES"); v != "" {
+		c.ComposeDefaultFiles = splitList(v)
+		envVarsFound = append(envVarsFound, "OAR_COMPOSE_DEFAULT_FILES")
+	}
 	if v := c.env.Getenv("OAR_WATCHER_ENABLED"); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
 			c.WatcherEnabled = b
@@ -211,6 +564,98 @@ func (c *Config) loadFromEnv() {
 			envVarsFound = append(envVarsFound, "OAR_WATCHER_POLL_INTERVAL")
 		}
 	}
+	if v := c.env.Getenv("OAR_WATCHER_CHECK_DEPENDENCIES"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.WatcherCheckDependencies = b
+			envVarsFound = append(envVarsFound, "OAR_WATCHER_CHECK_DEPENDENCIES")
+		}
+	}
+	if v := c.env.Getenv("OAR_WEBHOOK_QUIET_PERIOD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.WebhookQuietPeriod = d
+			envVarsFound = append(envVarsFound, "OAR_WEBHOOK_QUIET_PERIOD")
+		}
+	}
+	if v := c.env.Getenv("OAR_WEBHOOK_MAX_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.WebhookMaxDelay = d
+			envVarsFound = append(envVarsFound, "OAR_WEBHOOK_MAX_DELAY")
+		}
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_WEBHOOK_URL"); v != "" {
+		c.NotifyWebhookURL = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_WEBHOOK_URL")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_CHANNEL"); v != "" {
+		c.NotifyChannel = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_CHANNEL")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_SMTP_HOST"); v != "" {
+		c.NotifySMTPHost = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_SMTP_HOST")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_SMTP_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			c.NotifySMTPPort = port
+			envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_SMTP_PORT")
+		}
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_SMTP_USERNAME"); v != "" {
+		c.NotifySMTPUsername = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_SMTP_USERNAME")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_SMTP_PASSWORD"); v != "" {
+		c.NotifySMTPPassword = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_SMTP_PASSWORD")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_SMTP_TLS"); v != "" {
+		c.NotifySMTPTLS = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_SMTP_TLS")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_EMAIL_FROM"); v != "" {
+		c.NotifyEmailFrom = v
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_EMAIL_FROM")
+	}
+	if v := c.env.Getenv("OAR_NOTIFICATIONS_EMAIL_TO"); v != "" {
+		c.NotifyEmailTo = splitList(v)
+		envVarsFound = append(envVarsFound, "OAR_NOTIFICATIONS_EMAIL_TO")
+	}
+	if v := c.env.Getenv("OAR_MAINTENANCE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.MaintenanceInterval = d
+			envVarsFound = append(envVarsFound, "OAR_MAINTENANCE_INTERVAL")
+		}
+	}
+	if v := c.env.Getenv("OAR_RETENTION_DEPLOYMENTS_MAX_AGE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.DeploymentRetentionMaxAge = d
+			envVarsFound = append(envVarsFound, "OAR_RETENTION_DEPLOYMENTS_MAX_AGE")
+		}
+	}
+	if v := c.env.Getenv("OAR_RETENTION_DEPLOYMENTS_MAX_COUNT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			c.DeploymentRetentionMaxCount = n
+			envVarsFound = append(envVarsFound, "OAR_RETENTION_DEPLOYMENTS_MAX_COUNT")
+		}
+	}
+	if v := c.env.Getenv("OAR_DISK_USAGE_WARNING"); v != "" {
+		if n, err := units.FromHumanSize(v); err == nil {
+			c.DiskUsageWarning = n
+			envVarsFound = append(envVarsFound, "OAR_DISK_USAGE_WARNING")
+		}
+	}
+	if v := c.env.Getenv("OAR_UPDATE_CHECK_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.UpdateCheckEnabled = b
+			envVarsFound = append(envVarsFound, "OAR_UPDATE_CHECK_ENABLED")
+		}
+	}
+	if v := c.env.Getenv("OAR_UPDATE_CHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			c.UpdateCheckInterval = d
+			envVarsFound = append(envVarsFound, "OAR_UPDATE_CHECK_INTERVAL")
+		}
+	}
 	if v := c.env.Getenv("OAR_ENCRYPTION_KEY"); v != "" {
 		c.EncryptionKey = v
 		envVarsFound = append(envVarsFound, "OAR_ENCRYPTION_KEY")
@@ -242,20 +687,105 @@ func (c *Config) loadFromYamlFile(configPath string) error {
 	if yamlConfig.DatabasePath != "" {
 		c.DatabasePath = yamlConfig.DatabasePath
 	}
+	if yamlConfig.Database.BusyTimeout != "" {
+		if d, err := time.ParseDuration(yamlConfig.Database.BusyTimeout); err == nil {
+			c.DatabaseBusyTimeout = d
+		}
+	}
+	if yamlConfig.Database.MaxOpenConns != nil {
+		c.DatabaseMaxOpenConns = *yamlConfig.Database.MaxOpenConns
+	}
+	if yamlConfig.Database.MaxIdleConns != nil {
+		c.DatabaseMaxIdleConns = *yamlConfig.Database.MaxIdleConns
+	}
+	if yamlConfig.Database.ConnMaxLifetime != "" {
+		if d, err := time.ParseDuration(yamlConfig.Database.ConnMaxLifetime); err == nil {
+			c.DatabaseConnMaxLifetime = d
+		}
+	}
 	if yamlConfig.LogLevel != "" {
 		c.LogLevel = yamlConfig.LogLevel
 	}
+	if yamlConfig.LogFormat != "" {
+		c.LogFormat = yamlConfig.LogFormat
+	}
+	if yamlConfig.LogFile != "" {
+		c.LogFile = yamlConfig.LogFile
+	}
+	if yamlConfig.LogMaxSizeMB != nil {
+		c.LogMaxSizeMB = *yamlConfig.LogMaxSizeMB
+	}
+	if yamlConfig.LogMaxBackups != nil {
+		c.LogMaxBackups = *yamlConfig.LogMaxBackups
+	}
 	if yamlConfig.HTTP.Host != "" {
 		c.HTTPHost = yamlConfig.HTTP.Host
 	}
 	if yamlConfig.HTTP.Port != 0 {
 		c.HTTPPort = yamlConfig.HTTP.Port
 	}
+	if yamlConfig.HTTP.RequireAPIToken != nil {
+		c.HTTPRequireAPIToken = *yamlConfig.HTTP.RequireAPIToken
+	}
 	if yamlConfig.Git.Timeout != "" {
 		if d, err := time.ParseDuration(yamlConfig.Git.Timeout); err == nil {
 			c.GitTimeout = d
 		}
 	}
+	if yamlConfig.Git.ResetOnDeploy != nil {
+		c.GitResetOnDeploy = *yamlConfig.Git.ResetOnDeploy
+	}
+	if yamlConfig.Git.Retries != nil {
+		c.GitRetries = *yamlConfig.Git.Retries
+	}
+	if yamlConfig.Git.RetryDelay != "" {
+		if d, err := time.ParseDuration(yamlConfig.Git.RetryDelay); err == nil {
+			c.GitRetryDelay = d
+		}
+	}
+	if yamlConfig.Docker.Host != "" {
+		c.DockerHost = yamlConfig.Docker.Host
+	}
+	if len(yamlConfig.Docker.CommandPrefix) > 0 {
+		c.DockerCommandPrefix = yamlConfig.Docker.CommandPrefix
+	}
+	if yamlConfig.Compose.QueryTimeout != "" {
+		if d, err := time.ParseDuration(yamlConfig.Compose.QueryTimeout); err == nil {
+			c.ComposeQueryTimeout = d
+		}
+	}
+	if yamlConfig.Compose.DownTimeout != "" {
+		if d, err := time.ParseDuration(yamlConfig.Compose.DownTimeout); err == nil {
+			c.ComposeDownTimeout = d
+		}
+	}
+	if yamlConfig.Compose.Progress != "" {
+		c.ComposeProgress = yamlConfig.Compose.Progress
+	}
+	if yamlConfig.Compose.StoredOutput != "" {
+		c.ComposeStoredOutput = yamlConfig.Compose.StoredOutput
+	}
+	if yamlConfig.Compose.CompressStoredOutput != nil {
+		c.ComposeCompressOutput = *yamlConfig.Compose.CompressStoredOutput
+	}
+	if yamlConfig.Compose.MaxDeploys != nil {
+		c.ComposeMaxDeploys = *yamlConfig.Compose.MaxDeploys
+	}
+	if yamlConfig.Compose.Parallel != nil {
+		c.ComposeParallel = *yamlConfig.Compose.Parallel
+	}
+	if yamlConfig.Compose.DeployEnv != "" {
+		c.ComposeDeployEnv = yamlConfig.Compose.DeployEnv
+	}
+	if yamlConfig.Compose.RegistryMirror != "" {
+		c.ComposeRegistryMirror = yamlConfig.Compose.RegistryMirror
+	}
+	if yamlConfig.Compose.Offline != nil {
+		c.ComposeOffline = *yamlConfig.Compose.Offline
+	}
+	if len(yamlConfig.Compose.DefaultFiles) > 0 {
+		c.ComposeDefaultFiles = yamlConfig.Compose.DefaultFiles
+	}
 	if yamlConfig.Watcher.Enabled != nil {
 		c.WatcherEnabled = *yamlConfig.Watcher.Enabled
 	}
@@ -264,6 +794,63 @@ func (c *Config) loadFromYamlFile(configPath string) error {
 			c.WatcherPollInterval = d
 		}
 	}
+	if yamlConfig.Watcher.CheckDependencies != nil {
+		c.WatcherCheckDependencies = *yamlConfig.Watcher.CheckDependencies
+	}
+	if yamlConfig.Webhook.QuietPeriod != "" {
+		if d, err := time.ParseDuration(yamlConfig.Webhook.QuietPeriod); err == nil {
+			c.WebhookQuietPeriod = d
+		}
+	}
+	if yamlConfig.Webhook.MaxDelay != "" {
+		if d, err := time.ParseDuration(yamlConfig.Webhook.MaxDelay); err == nil {
+			c.WebhookMaxDelay = d
+		}
+	}
+	if yamlConfig.Notifications.WebhookURL != "" {
+		c.NotifyWebhookURL = yamlConfig.Notifications.WebhookURL
+	}
+	if yamlConfig.Notifications.Channel != "" {
+		c.NotifyChannel = yamlConfig.Notifications.Channel
+	}
+	if yamlConfig.Notifications.Template != "" {
+		c.NotifyTemplate = yamlConfig.Notifications.Template
+	}
+	if email := yamlConfig.Notifications.Email; email.Host != "" {
+		c.NotifySMTPHost = email.Host
+		c.NotifySMTPPort = email.Port
+		c.NotifySMTPUsername = email.Username
+		c.NotifySMTPPassword = email.Password
+		c.NotifySMTPTLS = email.TLS
+		c.NotifyEmailFrom = email.From
+		c.NotifyEmailTo = email.To
+	}
+	if yamlConfig.Maintenance.Interval != "" {
+		if d, err := time.ParseDuration(yamlConfig.Maintenance.Interval); err == nil {
+			c.MaintenanceInterval = d
+		}
+	}
+	if yamlConfig.Retention.Deployments.MaxAge != "" {
+		if d, err := time.ParseDuration(yamlConfig.Retention.Deployments.MaxAge); err == nil {
+			c.DeploymentRetentionMaxAge = d
+		}
+	}
+	if yamlConfig.Retention.Deployments.MaxCount != nil {
+		c.DeploymentRetentionMaxCount = *yamlConfig.Retention.Deployments.MaxCount
+	}
+	if yamlConfig.DiskUsage.Warning != "" {
+		if n, err := units.FromHumanSize(yamlConfig.DiskUsage.Warning); err == nil {
+			c.DiskUsageWarning = n
+		}
+	}
+	if yamlConfig.UpdateCheck.Enabled != nil {
+		c.UpdateCheckEnabled = *yamlConfig.UpdateCheck.Enabled
+	}
+	if yamlConfig.UpdateCheck.Interval != "" {
+		if d, err := time.ParseDuration(yamlConfig.UpdateCheck.Interval); err == nil {
+			c.UpdateCheckInterval = d
+		}
+	}
 	if yamlConfig.EncryptionKey != "" {
 		c.EncryptionKey = yamlConfig.EncryptionKey
 	}
@@ -292,21 +879,136 @@ func (c *Config) validate() error {
 		return fmt.Errorf("invalid log level: %s (must be debug, info, warning, error, or silent)", c.LogLevel)
 	}
 
+	// Validate database connection settings
+	if c.DatabaseBusyTimeout < 0 {
+		return fmt.Errorf("database busy timeout must not be negative, got: %v", c.DatabaseBusyTimeout)
+	}
+	if c.DatabaseMaxOpenConns < 0 || c.DatabaseMaxIdleConns < 0 {
+		return fmt.Errorf("database connection limits must not be negative, got: max open %d, max idle %d",
+			c.DatabaseMaxOpenConns, c.DatabaseMaxIdleConns)
+	}
+	if c.DatabaseConnMaxLifetime < 0 {
+		return fmt.Errorf("database connection max lifetime must not be negative, got: %v", c.DatabaseConnMaxLifetime)
+	}
+
+	// Validate log format and rotation
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("invalid log format: %s (must be text or json)", c.LogFormat)
+	}
+	if c.LogMaxSizeMB < 0 {
+		return fmt.Errorf("log max size must not be negative, got: %d", c.LogMaxSizeMB)
+	}
+	if c.LogMaxBackups < 0 {
+		return fmt.Errorf("log max backups must not be negative, got: %d", c.LogMaxBackups)
+	}
+
 	// Validate HTTP port
 	if c.HTTPPort < 1 || c.HTTPPort > 65535 {
 		return fmt.Errorf("invalid HTTP port: %d (must be 1-65535)", c.HTTPPort)
 	}
 
+	// Validate the Docker daemon address and command prefix
+	if c.DockerHost != "" {
+		host, err := normalizeDockerHost(c.DockerHost)
+		if err != nil {
+			return err
+		}
+		c.DockerHost = host
+	}
+	if slices.Contains(c.DockerCommandPrefix, "") {
+		return fmt.Errorf("docker command prefix must not contain empty arguments, got: %q", c.DockerCommandPrefix)
+	}
+
 	// Validate timeout
 	if c.GitTimeout <= 0 {
 		return fmt.Errorf("git timeout must be positive, got: %v", c.GitTimeout)
 	}
+	if c.GitRetries < 0 {
+		return fmt.Errorf("git retries must not be negative, got: %d", c.GitRetries)
+	}
+	if c.GitRetryDelay < 0 {
+		return fmt.Errorf("git retry delay must not be negative, got: %v", c.GitRetryDelay)
+	}
+	if c.ComposeQueryTimeout <= 0 {
+		return fmt.Errorf("compose query timeout must be positive, got: %v", c.ComposeQueryTimeout)
+	}
+	if c.ComposeDownTimeout <= 0 {
+		return fmt.Errorf("compose down timeout must be positive, got: %v", c.ComposeDownTimeout)
+	}
+
+	// Validate compose progress mode
+	validProgressModes := map[string]bool{"plain": true, "tty": true, "quiet": true, "json": true}
+	if !validProgressModes[c.ComposeProgress] {
+		return fmt.Errorf("invalid compose progress mode: %s (must be plain, tty, quiet, or json)", c.ComposeProgress)
+	}
+
+	// Validate stored output format
+	if c.ComposeStoredOutput != "ansi" && c.ComposeStoredOutput != "plain" {
+		return fmt.Errorf("invalid compose stored output format: %s (must be ansi or plain)", c.ComposeStoredOutput)
+	}
+	if c.ComposeMaxDeploys < 0 {
+		return fmt.Errorf("compose max concurrent deploys must not be negative, got: %d", c.ComposeMaxDeploys)
+	}
+	if c.ComposeParallel < 0 {
+		return fmt.Errorf("compose parallel must not be negative, got: %d", c.ComposeParallel)
+	}
+	if c.ComposeRegistryMirror != "" {
+		if err := domain.ValidateRegistryMirror(c.ComposeRegistryMirror); err != nil {
+			return fmt.Errorf("invalid compose registry mirror: %w", err)
+		}
+	}
 
 	// Validate watcher poll interval
 	if c.WatcherPollInterval <= 0 {
 		return fmt.Errorf("watcher poll interval must be positive, got: %v", c.WatcherPollInterval)
 	}
 
+	// Validate webhook debouncing
+	if c.WebhookQuietPeriod < 0 {
+		return fmt.Errorf("webhook quiet period must not be negative, got: %v", c.WebhookQuietPeriod)
+	}
+	if c.WebhookMaxDelay < c.WebhookQuietPeriod {
+		return fmt.Errorf("webhook max delay (%v) must not be shorter than the quiet period (%v)",
+			c.WebhookMaxDelay, c.WebhookQuietPeriod)
+	}
+
+	// Validate the default notification route and template
+	if c.NotifyWebhookURL != "" {
+		if err := notify.ValidateWebhookURL(c.NotifyWebhookURL); err != nil {
+			return err
+		}
+	}
+	if c.NotifyTemplate != "" {
+		if err := notify.ValidateTemplate(c.NotifyTemplate); err != nil {
+			return err
+		}
+	}
+	if route := c.NotifyRoute(); route.Email != nil {
+		if err := route.Email.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate maintenance and retention
+	if c.MaintenanceInterval < 0 {
+		return fmt.Errorf("maintenance interval must not be negative, got: %v", c.MaintenanceInterval)
+	}
+	if c.DeploymentRetentionMaxAge < 0 {
+		return fmt.Errorf("deployment retention max age must not be negative, got: %v", c.DeploymentRetentionMaxAge)
+	}
+	if c.DeploymentRetentionMaxCount < 0 {
+		return fmt.Errorf("deployment retention max count must not be negative, got: %d",
+			c.DeploymentRetentionMaxCount)
+	}
+	if c.DiskUsageWarning < 0 {
+		return fmt.Errorf("disk usage warning must not be negative, got: %d", c.DiskUsageWarning)
+	}
+
+	// Validate update check interval
+	if c.UpdateCheckInterval <= 0 {
+		return fmt.Errorf("update check interval must be positive, got: %v", c.UpdateCheckInterval)
+	}
+
 	// Require encryption key to be provided via environment variable or config file
 	if c.EncryptionKey == "" {
 		return fmt.Errorf("encryption key is required - set in config file or OAR_ENCRYPTION_KEY environment variable")
@@ -315,7 +1017,72 @@ func (c *Config) validate() error {
 	return nil
 }
 
+// LoggingOptions returns the logging options of the server. Without a log file, logs go to stderr.
+func (c *Config) LoggingOptions() logging.Options {
+	return logging.Options{
+		Level:      c.LogLevel,
+		Format:     c.LogFormat,
+		File:       c.LogFile,
+		MaxSizeMB:  c.LogMaxSizeMB,
+		MaxBackups: c.LogMaxBackups,
+	}
+}
+
+// NotifyRoute returns the default route of deployment notifications
+func (c *Config) NotifyRoute() notify.Route {
+	route := notify.Route{WebhookURL: c.NotifyWebhookURL, Channel: c.NotifyChannel}
+	if c.NotifySMTPHost != "" {
+		route.Email = &notify.SMTPConfig{
+			Host:     c.NotifySMTPHost,
+			Port:     c.NotifySMTPPort,
+			Username: c.NotifySMTPUsername,
+			Password: c.NotifySMTPPassword,
+			From:     c.NotifyEmailFrom,
+			To:       c.NotifyEmailTo,
+			TLS:      c.NotifySMTPTLS,
+		}
+	}
+	return route
+}
+
+// normalizeDockerHost checks a Docker daemon address. A socket path is turned into a unix:// address.
+func normalizeDockerHost(host string) (string, error) {
+	if filepath.IsAbs(host) {
+		return "unix://" + host, nil
+	}
+	scheme, address, ok := strings.Cut(host, "://")
+	if !ok || (scheme != "unix" && scheme != "tcp") || address == "" {
+		return "", fmt.Errorf("invalid docker host: %s (must be a socket path, unix://<path> or tcp://<host>:<port>)",
+			host)
+	}
+	if scheme == "unix" && !filepath.IsAbs(address) {
+		return "", fmt.Errorf("invalid docker host: %s (the socket path must be absolute)", host)
+	}
+	return host, nil
+}
+
+// splitList splits a comma-separated environment variable value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // GetLogLevel returns the configured log level
 func (c *Config) GetLogLevel() string {
 	return c.LogLevel
 }
+
+// DatabaseOptions returns the database connection settings
+func (c *Config) DatabaseOptions() db.Options {
+	return db.Options{
+		BusyTimeout:     c.DatabaseBusyTimeout,
+		MaxOpenConns:    c.DatabaseMaxOpenConns,
+		MaxIdleConns:    c.DatabaseMaxIdleConns,
+		ConnMaxLifetime: c.DatabaseConnMaxLifetime,
+	}
+}
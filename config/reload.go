@@ -0,0 +1,74 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+)
+
+// Reload re-reads the configuration file and environment. The returned configuration takes its
+// hot-reloadable fields (log level, watcher poll interval and dependency checks, webhook debouncing,
+// deployment retention limits) from the reloaded configuration and every other field from c, since
+// those only take effect on restart. The names of changed fields that were not applied are returned
+// so they can be reported. When the reloaded configuration is invalid, an error is returned and c
+// stays in effect.
+func (c *Config) Reload(configPath string) (*Config, []string, error) {
+	reloaded, err := NewConfigWithEnv(configPath, c.env)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var restartRequired []string
+	check := func(name string, changed bool) {
+		if changed {
+			restartRequired = append(restartRequired, name)
+		}
+	}
+	check("data_dir", reloaded.DataDir != c.DataDir)
+	check("log_format", reloaded.LogFormat != c.LogFormat)
+	check("log_file", reloaded.LogFile != c.LogFile)
+	check("log_max_size_mb", reloaded.LogMaxSizeMB != c.LogMaxSizeMB)
+	check("log_max_backups", reloaded.LogMaxBackups != c.LogMaxBackups)
+	check("database_path", reloaded.DatabasePath != c.DatabasePath)
+	check("database", reloaded.DatabaseOptions() != c.DatabaseOptions())
+	check("http.host", reloaded.HTTPHost != c.HTTPHost)
+	check("http.port", reloaded.HTTPPort != c.HTTPPort)
+	check("http.require_api_token", reloaded.HTTPRequireAPIToken != c.HTTPRequireAPIToken)
+	check("git.timeout", reloaded.GitTimeout != c.GitTimeout)
+	check("git.reset_on_deploy", reloaded.GitResetOnDeploy != c.GitResetOnDeploy)
+	check("git.retries", reloaded.GitRetries != c.GitRetries)
+	check("git.retry_delay", reloaded.GitRetryDelay != c.GitRetryDelay)
+	check("docker.host", reloaded.DockerHost != c.DockerHost)
+	check("docker.command_prefix", !slices.Equal(reloaded.DockerCommandPrefix, c.DockerCommandPrefix))
+	check("compose.query_timeout", reloaded.ComposeQueryTimeout != c.ComposeQueryTimeout)
+	check("compose.down_timeout", reloaded.ComposeDownTimeout != c.ComposeDownTimeout)
+	check("compose.progress", reloaded.ComposeProgress != c.ComposeProgress)
+	check("compose.stored_output", reloaded.ComposeStoredOutput != c.ComposeStoredOutput)
+	check("compose.compress_stored_output", reloaded.ComposeCompressOutput != c.ComposeCompressOutput)
+	check("compose.max_concurrent_deploys", reloaded.ComposeMaxDeploys != c.ComposeMaxDeploys)
+	check("compose.parallel", reloaded.ComposeParallel != c.ComposeParallel)
+	check("compose.deploy_env", reloaded.ComposeDeployEnv != c.ComposeDeployEnv)
+	check("compose.registry_mirror", reloaded.ComposeRegistryMirror != c.ComposeRegistryMirror)
+	check("compose.offline", reloaded.ComposeOffline != c.ComposeOffline)
+	check("compose.default_files", !slices.Equal(reloaded.ComposeDefaultFiles, c.ComposeDefaultFiles))
+	check("watcher.enabled", reloaded.WatcherEnabled != c.WatcherEnabled)
+	check("notifications.webhook_url", reloaded.NotifyWebhookURL != c.NotifyWebhookURL)
+	check("notifications.channel", reloaded.NotifyChannel != c.NotifyChannel)
+	check("notifications.template", reloaded.NotifyTemplate != c.NotifyTemplate)
+	check("notifications.email", !reflect.DeepEqual(reloaded.NotifyRoute().Email, c.NotifyRoute().Email))
+	check("maintenance.interval", reloaded.MaintenanceInterval != c.MaintenanceInterval)
+	check("disk_usage.warning", reloaded.DiskUsageWarning != c.DiskUsageWarning)
+	check("update_check", reloaded.UpdateCheckEnabled != c.UpdateCheckEnabled ||
+		reloaded.UpdateCheckInterval != c.UpdateCheckInterval)
+	check("encryption_key", reloaded.EncryptionKey != c.EncryptionKey)
+
+	applied := *c
+	applied.LogLevel = reloaded.LogLevel
+	applied.WatcherPollInterval = reloaded.WatcherPollInterval
+	applied.WatcherCheckDependencies = reloaded.WatcherCheckDependencies
+	applied.WebhookQuietPeriod = reloaded.WebhookQuietPeriod
+	applied.WebhookMaxDelay = reloaded.WebhookMaxDelay
+	applied.DeploymentRetentionMaxAge = reloaded.DeploymentRetentionMaxAge
+	applied.DeploymentRetentionMaxCount = reloaded.DeploymentRetentionMaxCount
+
+	return &applied, restartRequired, nil
+}
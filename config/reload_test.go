@@ -0,0 +1,252 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+)
+
+type testEnv map[string]string
+
+func (e testEnv) Getenv(key string) string { return e[key] }
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	writeConfig(t, configPath, `data_dir: `+dir+`
+encryption_key: test-key
+log_level: info
+http:
+  port: 4777
+watcher:
+  poll_interval: 5m
+`)
+
+	current, err := config.NewConfigWithEnv(configPath, testEnv{})
+	require.NoError(t, err)
+
+	writeConfig(t, configPath, `data_dir: `+dir+`
+encryption_key: test-key
+log_level: debug
+http:
+  port: 8080
+watcher:
+  poll_interval: 30s
+webhook:
+  quiet_period: 5s
+retention:
+  deployments:
+    max_age: 720h
+    max_count: 50
+`)
+
+	reloaded, restartRequired, err := current.Reload(configPath)
+	require.NoError(t, err)
+
+	// Hot-reloadable fields are applied
+	assert.Equal(t, "debug", reloaded.LogLevel)
+	assert.Equal(t, 30*time.Second, reloaded.WatcherPollInterval)
+	assert.Equal(t, 5*time.Second, reloaded.WebhookQuietPeriod)
+	assert.Equal(t, 720*time.Hour, reloaded.DeploymentRetentionMaxAge)
+	assert.Equal(t, 50, reloaded.DeploymentRetentionMaxCount)
+
+	// Fields that need a restart keep their running value and are reported
+	assert.Equal(t, 4777, reloaded.HTTPPort)
+	assert.Equal(t, []string{"http.port"}, restartRequired)
+
+	// The running configuration is not modified
+	assert.Equal(t, "info", current.LogLevel)
+	assert.Equal(t, 5*time.Minute, current.WatcherPollInterval)
+	assert.Zero(t, current.DeploymentRetentionMaxCount)
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	writeConfig(t, configPath, `data_dir: `+dir+`
+encryption_key: test-key
+log_level: info
+watcher:
+  poll_interval: 5m
+`)
+
+	current, err := config.NewConfigWithEnv(configPath, testEnv{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "invalid value",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+log_level: verbose
+`,
+			wantErr: "invalid log level: verbose",
+		},
+		{
+			name: "invalid log format",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+log_format: xml
+`,
+			wantErr: "invalid log format: xml",
+		},
+		{
+			name: "invalid progress mode",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+compose:
+  progress: rawjson
+`,
+			wantErr: "invalid compose progress mode: rawjson",
+		},
+		{
+			name: "invalid stored output format",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+compose:
+  stored_output: html
+`,
+			wantErr: "invalid compose stored output format: html",
+		},
+		{
+			name: "invalid notification template",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+notifications:
+  webhook_url: https://chat.example.com/hooks/oar
+  template: "{{.Project}} deployed"
+`,
+			wantErr: "invalid notification template",
+		},
+		{
+			name: "negative database connection limit",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+database:
+  max_open_conns: -1
+`,
+			wantErr: "database connection limits must not be negative",
+		},
+		{
+			name: "negative deploy limit",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+compose:
+  max_concurrent_deploys: -1
+`,
+			wantErr: "compose max concurrent deploys must not be negative",
+		},
+		{
+			name: "zero down timeout",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+compose:
+  down_timeout: 0s
+`,
+			wantErr: "compose down timeout must be positive",
+		},
+		{
+			name: "registry mirror with scheme",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+compose:
+  registry_mirror: https://mirror.example.com
+`,
+			wantErr: "invalid compose registry mirror",
+		},
+		{
+			name: "docker host without scheme",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+docker:
+  host: docker.example.com:2375
+`,
+			wantErr: "invalid docker host",
+		},
+		{
+			name: "relative docker socket",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+docker:
+  host: unix://run/docker.sock
+`,
+			wantErr: "the socket path must be absolute",
+		},
+		{
+			name: "empty docker command prefix argument",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+docker:
+  command_prefix: [sudo, ""]
+`,
+			wantErr: "docker command prefix must not contain empty arguments",
+		},
+		{
+			name: "negative deployment retention",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+retention:
+  deployments:
+    max_count: -1
+`,
+			wantErr: "deployment retention max count must not be negative",
+		},
+		{
+			name: "email notifications without a sender",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+notifications:
+  email:
+    host: smtp.example.com
+    to: [ops@example.com]
+`,
+			wantErr: "invalid email notification settings",
+		},
+		{
+			name: "invalid combination",
+			content: `data_dir: ` + dir + `
+encryption_key: test-key
+webhook:
+  quiet_period: 5m
+  max_delay: 1m
+`,
+			wantErr: "webhook max delay",
+		},
+		{
+			name:    "malformed YAML",
+			content: "log_level: [debug\n",
+			wantErr: "failed to load config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, configPath, tt.content)
+
+			reloaded, restartRequired, err := current.Reload(configPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Nil(t, reloaded)
+			assert.Nil(t, restartRequired)
+
+			// The running configuration stays in effect
+			assert.Equal(t, "info", current.LogLevel)
+			assert.Equal(t, 5*time.Minute, current.WatcherPollInterval)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+// Package datadir relocates an existing Oar data directory.
+package datadir
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/encryption"
+	"github.com/oar-cd/oar/repository"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// freeSpaceMargin is the share of extra free space required at the destination on top of the data size
+const freeSpaceMargin = 0.1
+
+// Options describes a data directory migration
+type Options struct {
+	Config     *config.Config // Current configuration, its DataDir is the source
+	ConfigPath string         // Config file to update with the new data_dir, skipped if it does not exist
+	Target     string         // New data directory
+}
+
+// Result describes a completed migration
+type Result struct {
+	Source          string
+	Target          string
+	Copied          bool     // Data was copied because the target is on another filesystem
+	ProjectsUpdated int      // Projects whose working directory was rewritten
+	RunningProjects []string // Projects that must be redeployed to pick up the new bind mount paths
+	ConfigUpdated   bool     // data_dir was written to the config file
+}
+
+// Migrate moves the database and project working directories to a new data directory, rewrites
+// the working directories stored in the database and updates the config file. It refuses to run
+// while the server is reachable or a deployment is in progress.
+func Migrate(opts Options) (*Result, error) {
+	source := filepath.Clean(opts.Config.DataDir)
+	target, err := filepath.Abs(opts.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target directory: %w", err)
+	}
+
+	if err := validateTarget(source, target); err != nil {
+		return nil, err
+	}
+
+	if err := checkServerStopped(opts.Config); err != nil {
+		return nil, err
+	}
+
+	runningProjects, err := checkNotInUse(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkFreeSpace(source, target); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Moving data directory", "source", source, "target", target)
+	copied, err := moveDir(source, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move data directory: %w", err)
+	}
+
+	oldWorkspace := filepath.Join(source, config.ProjectsDir)
+	newWorkspace := filepath.Join(target, config.ProjectsDir)
+	updated, err := relocateWorkingDirs(target, opts.Config, oldWorkspace, newWorkspace)
+	if err != nil {
+		// Put the data back so the existing configuration keeps working
+		if _, moveErr := moveDir(target, source); moveErr != nil {
+			return nil, fmt.Errorf("failed to update database (%w), and failed to move data back to %s: %v",
+				err, source, moveErr)
+		}
+		return nil, fmt.Errorf("failed to update database, data left in %s: %w", source, err)
+	}
+
+	result := &Result{
+		Source:          source,
+		Target:          target,
+		Copied:          copied,
+		ProjectsUpdated: updated,
+		RunningProjects: runningProjects,
+	}
+
+	if opts.ConfigPath != "" {
+		if _, err := os.Stat(opts.ConfigPath); err == nil {
+			if err := updateConfigFile(opts.ConfigPath, target); err != nil {
+				return result, fmt.Errorf("data moved to %s, but failed to update %s: %w", target, opts.ConfigPath, err)
+			}
+			result.ConfigUpdated = true
+		}
+	}
+
+	return result, nil
+}
+
+// validateTarget makes sure the target is a new, empty location outside the current data directory
+func validateTarget(source, target string) error {
+	if target == source {
+		return fmt.Errorf("target %s is the current data directory", target)
+	}
+	if isWithin(source, target) {
+		return fmt.Errorf("target %s is inside the current data directory %s", target, source)
+	}
+	if isWithin(target, source) {
+		return fmt.Errorf("target %s contains the current data directory %s", target, source)
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return fmt.Errorf("current data directory %s is not accessible: %w", source, err)
+	}
+
+	entries, err := os.ReadDir(target)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read target directory: %w", err)
+	case len(entries) > 0:
+		return fmt.Errorf("target directory %s is not empty", target)
+	default:
+		return nil
+	}
+}
+
+// checkServerStopped refuses to migrate while the server could be using the data directory
+func checkServerStopped(cfg *config.Config) error {
+	host := cfg.HTTPHost
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(cfg.HTTPPort))
+
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return nil
+	}
+	if closeErr := conn.Close(); closeErr != nil {
+		slog.Debug("Failed to close connection", "error", closeErr)
+	}
+	return fmt.Errorf("the Oar server appears to be running on %s, stop it before migrating", address)
+}
+
+// checkNotInUse refuses to migrate while a deployment is in progress and returns the running projects
+func checkNotInUse(cfg *config.Config) ([]string, error) {
+	database, err := db.InitDB(cfg.DataDir, cfg.DatabaseOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer closeDB(database)
+
+	encryptionSvc, err := encryption.NewEncryptionService(cfg.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	started, err := repository.NewDeploymentRepository(database).ListByStatus(domain.DeploymentStatusStarted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	if len(started) > 0 {
+		return nil, fmt.Errorf("%d deployment(s) in progress, wait for them to finish", len(started))
+	}
+
+	projects, err := repository.NewProjectRepository(database, encryptionSvc).List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	var running []string
+	for _, project := range projects {
+		if project.Status == domain.ProjectStatusRunning {
+			running = append(running, project.Name)
+		}
+	}
+	return running, nil
+}
+
+// checkFreeSpace verifies the filesystem holding the target has room for the data directory
+func checkFreeSpace(source, target string) error {
+	size, err := dirSize(source)
+	if err != nil {
+		return fmt.Errorf("failed to compute data directory size: %w", err)
+	}
+
+	// Statfs needs an existing path, use the closest existing parent of the target
+	existing := target
+	for {
+		if _, err := os.Stat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(existing, &stat); err != nil {
+		return fmt.Errorf("failed to check free space at %s: %w", existing, err)
+	}
+
+	available := stat.Bavail * uint64(stat.Bsize)
+	required := uint64(float64(size) * (1 + freeSpaceMargin))
+	if required > available {
+		return fmt.Errorf("not enough free space at %s: %d bytes needed, %d available", existing, required, available)
+	}
+	return nil
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// moveDir renames source to target, falling back to copying when they are on different
+// filesystems. It reports whether the data was copied.
+func moveDir(source, target string) (bool, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return false, err
+	}
+	// An existing empty target directory is replaced
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+
+	err := os.Rename(source, target)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return false, err
+	}
+
+	slog.Info("Target is on another filesystem, copying data", "source", source, "target", target)
+	if err := copyDir(source, target); err != nil {
+		if removeErr := os.RemoveAll(target); removeErr != nil {
+			slog.Warn("Failed to clean up partial copy", "target", target, "error", removeErr)
+		}
+		return true, err
+	}
+	return true, os.RemoveAll(source)
+}
+
+// copyDir copies a directory tree, preserving permissions and symbolic links
+func copyDir(source, target string) error {
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(target, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dest, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, dest)
+		case d.Type().IsRegular():
+			return copyFile(path, dest, info.Mode().Perm())
+		default:
+			slog.Warn("Skipping special file", "path", path)
+			return nil
+		}
+	})
+}
+
+func copyFile(source, target string, perm fs.FileMode) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			slog.Debug("Failed to close file", "path", source, "error", err)
+		}
+	}()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// relocateWorkingDirs rewrites the project working directories in the database at dataDir
+func relocateWorkingDirs(dataDir string, cfg *config.Config, oldWorkspace, newWorkspace string) (int, error) {
+	database, err := db.InitDB(dataDir, cfg.DatabaseOptions())
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer closeDB(database)
+
+	encryptionSvc, err := encryption.NewEncryptionService(cfg.EncryptionKey)
+	if err != nil {
+		return 0, err
+	}
+
+	return repository.NewProjectRepository(database, encryptionSvc).RelocateWorkingDirs(oldWorkspace, newWorkspace)
+}
+
+// updateConfigFile sets data_dir in the YAML config file, keeping the rest of the file as is
+func updateConfigFile(path, dataDir string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config file is not a YAML mapping")
+	}
+
+	found := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "data_dir" {
+			root.Content[i+1].SetString(dataDir)
+			found = true
+			break
+		}
+	}
+	if !found {
+		key := &yaml.Node{}
+		key.SetString("data_dir")
+		value := &yaml.Node{}
+		value.SetString(dataDir)
+		root.Content = append([]*yaml.Node{key, value}, root.Content...)
+	}
+
+	updated, err := yaml.Marshal(&doc)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, updated, info.Mode().Perm())
+}
+
+func closeDB(database *gorm.DB) {
+	sqlDB, err := database.DB()
+	if err != nil {
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		slog.Debug("Failed to close database", "error", err)
+	}
+}
+
+// isWithin reports whether path is inside dir
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,195 @@
+package datadir_test
+
+import (
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fernet/fernet-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/datadir"
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/encryption"
+	"github.com/oar-cd/oar/repository"
+)
+
+type testInstall struct {
+	cfg        *config.Config
+	configPath string
+	projectIDs []uuid.UUID
+}
+
+// setupInstall creates a data directory with a database, two projects and a config file
+func setupInstall(t *testing.T) *testInstall {
+	t.Helper()
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+
+	var key fernet.Key
+	_, err := rand.Read(key[:])
+	require.NoError(t, err)
+
+	// Pick a port nothing listens on, so the server is considered stopped
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	cfg := &config.Config{
+		DataDir:       dataDir,
+		WorkspaceDir:  filepath.Join(dataDir, config.ProjectsDir),
+		HTTPHost:      "127.0.0.1",
+		HTTPPort:      port,
+		GitTimeout:    10 * time.Second,
+		EncryptionKey: key.Encode(),
+	}
+
+	database, err := db.InitDB(dataDir, db.DefaultOptions())
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	encryptionSvc, err := encryption.NewEncryptionService(cfg.EncryptionKey)
+	require.NoError(t, err)
+	projectRepo := repository.NewProjectRepository(database, encryptionSvc)
+
+	install := &testInstall{cfg: cfg, configPath: filepath.Join(root, "config.yaml")}
+	for i, status := range []domain.ProjectStatus{domain.ProjectStatusRunning, domain.ProjectStatusStopped} {
+		projectID := uuid.New()
+		workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String())
+		require.NoError(t, os.MkdirAll(filepath.Join(workingDir, "git"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(workingDir, "git", "compose.yaml"), []byte("services: {}\n"), 0o644))
+
+		_, err := projectRepo.Create(&domain.Project{
+			ID:           projectID,
+			Name:         []string{"running-project", "stopped-project"}[i],
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   workingDir,
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       status,
+		})
+		require.NoError(t, err)
+		install.projectIDs = append(install.projectIDs, projectID)
+	}
+
+	configContent := "# Oar configuration\ndata_dir: " + dataDir + "\nlog_level: info\nhttp:\n  port: 4777\n"
+	require.NoError(t, os.WriteFile(install.configPath, []byte(configContent), 0o600))
+
+	return install
+}
+
+func openProjects(t *testing.T, install *testInstall, dataDir string) repository.ProjectRepository {
+	t.Helper()
+	database, err := db.InitDB(dataDir, db.DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	encryptionSvc, err := encryption.NewEncryptionService(install.cfg.EncryptionKey)
+	require.NoError(t, err)
+	return repository.NewProjectRepository(database, encryptionSvc)
+}
+
+func TestMigrate(t *testing.T) {
+	install := setupInstall(t)
+	target := filepath.Join(t.TempDir(), "new-data")
+
+	result, err := datadir.Migrate(datadir.Options{
+		Config:     install.cfg,
+		ConfigPath: install.configPath,
+		Target:     target,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, target, result.Target)
+	assert.Equal(t, 2, result.ProjectsUpdated)
+	assert.Equal(t, []string{"running-project"}, result.RunningProjects)
+	assert.True(t, result.ConfigUpdated)
+
+	_, err = os.Stat(install.cfg.DataDir)
+	assert.True(t, os.IsNotExist(err), "Source data directory should be gone")
+
+	projects := openProjects(t, install, target)
+	for _, projectID := range install.projectIDs {
+		project, err := projects.FindByID(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(target, config.ProjectsDir, projectID.String()), project.WorkingDir)
+		assert.FileExists(t, filepath.Join(project.WorkingDir, "git", "compose.yaml"))
+	}
+
+	env := &testEnv{"OAR_ENCRYPTION_KEY": install.cfg.EncryptionKey}
+	cfg, err := config.NewConfigWithEnv(install.configPath, env)
+	require.NoError(t, err, "Updated config file should still load")
+	assert.Equal(t, target, cfg.DataDir)
+
+	content, err := os.ReadFile(install.configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "# Oar configuration", "Comments should be kept")
+	assert.Contains(t, string(content), "port: 4777", "Other settings should be kept")
+}
+
+func TestMigrateRefusals(t *testing.T) {
+	t.Run("non-empty target", func(t *testing.T) {
+		install := setupInstall(t)
+		target := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(target, "file"), []byte("x"), 0o644))
+
+		_, err := datadir.Migrate(datadir.Options{Config: install.cfg, Target: target})
+		assert.ErrorContains(t, err, "not empty")
+	})
+
+	t.Run("target inside data directory", func(t *testing.T) {
+		install := setupInstall(t)
+		_, err := datadir.Migrate(datadir.Options{
+			Config: install.cfg,
+			Target: filepath.Join(install.cfg.DataDir, "nested"),
+		})
+		assert.ErrorContains(t, err, "inside the current data directory")
+	})
+
+	t.Run("deployment in progress", func(t *testing.T) {
+		install := setupInstall(t)
+		database, err := db.InitDB(install.cfg.DataDir, db.DefaultOptions())
+		require.NoError(t, err)
+		deployment := domain.NewDeployment(install.projectIDs[0], "aaaaaaaaaaaa")
+		deployment.Status = domain.DeploymentStatusStarted
+		require.NoError(t, repository.NewDeploymentRepository(database).Create(&deployment))
+		sqlDB, err := database.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		_, err = datadir.Migrate(datadir.Options{Config: install.cfg, Target: filepath.Join(t.TempDir(), "new")})
+		assert.ErrorContains(t, err, "in progress")
+		assert.DirExists(t, install.cfg.DataDir, "Nothing should have been moved")
+	})
+
+	t.Run("server running", func(t *testing.T) {
+		install := setupInstall(t)
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = listener.Close() }()
+		install.cfg.HTTPPort = listener.Addr().(*net.TCPAddr).Port
+
+		_, err = datadir.Migrate(datadir.Options{Config: install.cfg, Target: filepath.Join(t.TempDir(), "new")})
+		assert.ErrorContains(t, err, "server appears to be running")
+	})
+}
+
+type testEnv map[string]string
+
+func (e *testEnv) Getenv(key string) string { return (*e)[key] }
@@ -10,7 +10,8 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-func InitDB(dataDir string) (*gorm.DB, error) {
+// InitDB opens the database in dataDir with the given connection settings
+func InitDB(dataDir string, options Options) (*gorm.DB, error) {
 	slog.Debug("Initializing database", "data_dir", dataDir)
 	dbPath := filepath.Join(dataDir, "oar.db")
 
@@ -21,6 +22,7 @@ func InitDB(dataDir string) (*gorm.DB, error) {
 	db, err := InitDatabase(DBConfig{
 		Path:     dbPath,
 		LogLevel: gormLogLevel,
+		Options:  options,
 	})
 	if err != nil {
 		return nil, err
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -17,6 +18,34 @@ type DBConfig struct {
 	Path string
 	// LogLevel specifies the GORM logging level
 	LogLevel logger.LogLevel
+	// Options tunes the connection pool and how long to wait for a locked database
+	Options Options
+}
+
+// Options holds the connection settings of the database. Zero values leave the driver and database/sql
+// defaults.
+type Options struct {
+	// BusyTimeout is how long a connection waits for another one to release a lock before failing with
+	// "database is locked". It is set on every connection, for file-based databases only.
+	BusyTimeout time.Duration
+	// MaxOpenConns limits the open connections, 0 for no limit
+	MaxOpenConns int
+	// MaxIdleConns limits the connections kept open while idle
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections after this long, 0 to keep them
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultOptions returns the connection settings used unless configured otherwise. SQLite serializes
+// writes, so a few connections are enough for the web interface and the watcher, and the busy timeout
+// lets a write wait for another instead of failing.
+func DefaultOptions() Options {
+	return Options{
+		BusyTimeout:     5 * time.Second,
+		MaxOpenConns:    8,
+		MaxIdleConns:    4,
+		ConnMaxLifetime: time.Hour,
+	}
 }
 
 // InitDatabase creates and configures a SQLite database with the given configuration
@@ -35,7 +64,14 @@ func InitDatabase(config DBConfig) (*gorm.DB, error) {
 			return nil, fmt.Errorf("failed to create data directory: %w", err)
 		}
 
-		dsn = config.Path
+		// Pragmas in the DSN apply to every connection in the pool, not just the one that runs a statement.
+		// WAL lets reads run alongside a write. Transactions take the write lock when they begin: a
+		// transaction that reads and then writes would otherwise fail with "database is locked" as soon as
+		// another one has written, without waiting for the busy timeout.
+		dsn = config.Path + "?_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_txlock=immediate"
+		if config.Options.BusyTimeout > 0 {
+			dsn += fmt.Sprintf("&_busy_timeout=%d", config.Options.BusyTimeout.Milliseconds())
+		}
 		slog.Debug("Initializing file-based database", "path", config.Path)
 	}
 
@@ -48,6 +84,23 @@ func InitDatabase(config DBConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access database connection pool: %w", err)
+	}
+	if config.Path == ":memory:" {
+		// Every connection opens its own empty in-memory database, so the pool must not grow
+		sqlDB.SetMaxOpenConns(1)
+	} else if config.Options.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.Options.MaxOpenConns)
+	}
+	if config.Options.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.Options.MaxIdleConns)
+	}
+	if config.Options.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(config.Options.ConnMaxLifetime)
+	}
+
 	// Configure SQLite pragmas
 	pragmas := "PRAGMA foreign_keys = ON;"
 
@@ -55,8 +108,6 @@ func InitDatabase(config DBConfig) (*gorm.DB, error) {
 	if config.Path != ":memory:" {
 		pragmas += `
 		PRAGMA legacy_alter_table = OFF;
-		PRAGMA journal_mode       = WAL;
-		PRAGMA synchronous        = NORMAL;
 		PRAGMA mmap_size          = 134217728;
 		PRAGMA journal_size_limit = 27103364;
 		PRAGMA cache_size         = 2000;`
@@ -70,7 +121,12 @@ func InitDatabase(config DBConfig) (*gorm.DB, error) {
 	if config.Path == ":memory:" {
 		slog.Debug("Database initialized successfully (in-memory)")
 	} else {
-		slog.Debug("Database initialized successfully", "path", config.Path)
+		slog.Debug("Database initialized successfully",
+			"path", config.Path,
+			"busy_timeout", config.Options.BusyTimeout,
+			"max_open_conns", config.Options.MaxOpenConns,
+			"max_idle_conns", config.Options.MaxIdleConns,
+			"conn_max_lifetime", config.Options.ConnMaxLifetime)
 	}
 
 	return db, nil
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestInitDatabaseAppliesOptions(t *testing.T) {
+	db, err := InitDatabase(DBConfig{
+		Path:     filepath.Join(t.TempDir(), "oar.db"),
+		LogLevel: logger.Silent,
+		Options: Options{
+			BusyTimeout:     2500 * time.Millisecond,
+			MaxOpenConns:    3,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: time.Minute,
+		},
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	assert.Equal(t, 3, sqlDB.Stats().MaxOpenConnections)
+
+	// Pragmas set when a connection opens apply to every connection in the pool
+	ctx := context.Background()
+	for range 2 {
+		conn, err := sqlDB.Conn(ctx)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		var busyTimeout, foreignKeys, synchronous int
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys))
+		require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&synchronous))
+		assert.Equal(t, 2500, busyTimeout)
+		assert.Equal(t, 1, foreignKeys)
+		assert.Equal(t, 1, synchronous, "synchronous should be NORMAL")
+	}
+}
+
+func TestInitDatabaseBusyTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		busyTimeout time.Duration
+		wantErr     string
+	}{
+		{name: "waits for the lock", busyTimeout: DefaultOptions().BusyTimeout},
+		{name: "gives up", busyTimeout: 50 * time.Millisecond, wantErr: "database is locked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := InitDatabase(DBConfig{
+				Path:     filepath.Join(t.TempDir(), "oar.db"),
+				LogLevel: logger.Silent,
+				Options:  Options{BusyTimeout: tt.busyTimeout},
+			})
+			require.NoError(t, err)
+			sqlDB, err := db.DB()
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = sqlDB.Close() })
+			require.NoError(t, db.Exec("CREATE TABLE items (name TEXT)").Error)
+
+			// Another connection holds the write lock for a while
+			ctx := context.Background()
+			holder, err := sqlDB.Conn(ctx)
+			require.NoError(t, err)
+			defer func() { _ = holder.Close() }()
+			_, err = holder.ExecContext(ctx, "BEGIN IMMEDIATE")
+			require.NoError(t, err)
+			committed := make(chan struct{})
+			go func() {
+				defer close(committed)
+				time.Sleep(300 * time.Millisecond)
+				_, _ = holder.ExecContext(ctx, "COMMIT")
+			}()
+			defer func() { <-committed }()
+
+			err = db.Exec("INSERT INTO items (name) VALUES ('written')").Error
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInitDatabaseConcurrentWriters(t *testing.T) {
+	db, err := InitDatabase(DBConfig{
+		Path:     filepath.Join(t.TempDir(), "oar.db"),
+		LogLevel: logger.Silent,
+		Options:  DefaultOptions(),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	var journalMode string
+	require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+
+	require.NoError(t, db.Exec("CREATE TABLE counters (name TEXT PRIMARY KEY, value INTEGER)").Error)
+	require.NoError(t, db.Exec("INSERT INTO counters VALUES ('status', 0), ('deploys', 0)").Error)
+
+	// Like the watcher updating project status while the web interface saves a project, each writer
+	// reads and then writes in a transaction
+	const writes = 50
+	errs := make(chan error, 2)
+	for _, name := range []string{"status", "deploys"} {
+		go func() {
+			for range writes {
+				err := db.Transaction(func(tx *gorm.DB) error {
+					var value int
+					if err := tx.Raw("SELECT value FROM counters WHERE name = ?", name).Scan(&value).Error; err != nil {
+						return err
+					}
+					// Give the other writer time to commit in between
+					time.Sleep(time.Millisecond)
+					return tx.Exec("UPDATE counters SET value = ? WHERE name = ?", value+1, name).Error
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	for range 2 {
+		require.NoError(t, <-errs)
+	}
+
+	var total int
+	require.NoError(t, db.Raw("SELECT SUM(value) FROM counters").Scan(&total).Error)
+	assert.Equal(t, 2*writes, total)
+}
@@ -41,6 +41,9 @@ func AllModels() []any {
 		&MigrationModel{},
 		&ProjectModel{},
 		&DeploymentModel{},
+		&ShareLinkModel{},
+		&WatcherCheckModel{},
+		&APITokenModel{},
 	}
 }
 
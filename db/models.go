@@ -20,19 +20,50 @@ type MigrationModel struct {
 
 type ProjectModel struct {
 	BaseModel
-	Name               string  `gorm:"not null;unique;check:name <> ''"`
-	GitURL             string  `gorm:"not null;check:git_url <> ''"`
-	GitBranch          string  `gorm:"not null;check:git_branch <> ''"`    // Git branch (never empty, always set to default branch if not specified)
-	GitAuthType        *string `gorm:"type:varchar(20)"`                   // "http", "ssh", "oauth", etc.
-	GitAuthCredentials *string `gorm:"type:text"`                          // Encrypted JSON blob containing all auth data
-	WorkingDir         string  `gorm:"not null;check:working_dir <> ''"`   // directory where the project is cloned
-	ComposeFiles       string  `gorm:"not null;check:compose_files <> ''"` // list of compose file paths separated by null character (\0)
-	ComposeOverride    *string `gorm:"type:text"`                          // Optional Docker Compose override content
-	Variables          string  `gorm:"not null"`                           // Variables separated by null character (\0)
-	Status             string  `gorm:"not null;check:status <> ''"`        // running, stopped, error
-	LocalCommit        *string
-	RemoteCommit       *string
-	AutoDeployEnabled  bool `gorm:"not null"` // Enable automatic deployments on git changes
+	Name                  string  `gorm:"not null;unique;check:name <> ''"`
+	ComposeName           string  `gorm:"not null;default:''"`  // Compose project name, the name when empty
+	PreviousNames         string  `gorm:"not null;default:''"`  // earlier compose project names separated by \0
+	Source                string  `gorm:"not null;default:git"` // git or archive
+	GitURL                string  `gorm:"not null;check:git_url <> ''"`
+	GitBranch             string  `gorm:"not null;check:git_branch <> ''"`    // Git branch (never empty, always set to default branch if not specified)
+	GitBranchDetected     bool    `gorm:"not null;default:false"`             // GitBranch is the detected default branch
+	WatchURL              string  `gorm:"not null;default:''"`                // repository watched for new commits, git_url when empty
+	GitAuthType           *string `gorm:"type:varchar(20)"`                   // "http", "ssh", "oauth", etc.
+	GitAuthCredentials    *string `gorm:"type:text"`                          // Encrypted JSON blob containing all auth data
+	WorkingDir            string  `gorm:"not null;check:working_dir <> ''"`   // directory where the project is cloned
+	ComposeFiles          string  `gorm:"not null;check:compose_files <> ''"` // list of compose file paths separated by null character (\0)
+	DisabledComposeFiles  string  `gorm:"not null;default:''"`                // compose file paths left out of Compose commands, separated by \0
+	ComposeOverride       *string `gorm:"type:text"`                          // Optional Docker Compose override content
+	EnvFiles              string  `gorm:"not null;default:''"`                // env file paths separated by null character (\0)
+	Variables             string  `gorm:"not null"`                           // Variables separated by null character (\0)
+	BuildArgs             string  `gorm:"not null;default:''"`                // KEY=value build arguments separated by \0
+	BuildSecrets          *string `gorm:"type:text"`                          // Encrypted JSON blob with build secrets
+	ContainerLabels       string  `gorm:"not null;default:''"`                // key=value container labels separated by \0
+	DeployHooks           string  `gorm:"type:text"`                          // JSON list of deploy hooks
+	Status                string  `gorm:"not null;check:status <> ''"`        // running, stopped, error, deploying, paused
+	LocalCommit           *string
+	RemoteCommit          *string
+	AutoDeployEnabled     bool    `gorm:"not null"`               // Enable automatic deployments on git changes
+	AutoDeployPullImages  bool    `gorm:"not null;default:false"` // Pull images on automatic deployments
+	AutoDeploySkipGitPull bool    `gorm:"not null;default:false"` // Redeploy the current commit on automatic deployments
+	WatcherDisabled       bool    `gorm:"not null;default:false"` // Left alone by the watcher's polls and webhooks
+	WebhookSecret         *string `gorm:"type:text"`              // Encrypted secret push webhooks are signed with
+	SkipVolumeInit        bool    `gorm:"not null;default:false"` // Skip preparing volume mount ownership before starting services
+	VolumeInitPhase       string  `gorm:"not null;default:''"`    // after-create or before-create, default when empty
+	DependsOn             string  `gorm:"not null;default:''"`    // prerequisite project IDs separated by null character (\0)
+	ExternalNetworks      string  `gorm:"not null;default:''"`    // external network names separated by null character (\0)
+	RegistryMirror        string  `gorm:"not null;default:''"`    // registry to pull Docker Hub images from, the global default when empty
+	OfflineImages         bool    `gorm:"not null;default:false"` // never pull images, deploy only with local images
+	PinImageDigests       bool    `gorm:"not null;default:false"` // deploy images pinned to digests
+	ImageDigests          string  `gorm:"not null;default:''"`    // pinned images (image:tag@digest) separated by \0
+	VerboseBuild          bool    `gorm:"not null;default:false"` // show image build output on deployments
+	VerbosePull           bool    `gorm:"not null;default:false"` // show image pull progress on deployments
+	BuildOnDeploy         bool    `gorm:"not null;default:false"` // rebuild images on every deployment
+	NotifyWebhookURL      string  `gorm:"not null;default:''"`    // deployment notifications webhook, the global default when empty
+	NotifyChannel         string  `gorm:"not null;default:''"`    // channel for deployment notifications
+	NotifyTemplate        string  `gorm:"not null;default:''"`    // Go template for deployment notifications
+	NotifyEmail           *string `gorm:"type:text"`              // Encrypted JSON blob with email notification settings, including SMTP credentials
+	SortOrder             int     `gorm:"not null;default:0"`     // position on the dashboard, 0 when never placed by hand
 
 	Deployments []DeploymentModel `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`
 }
@@ -43,11 +74,20 @@ func (ProjectModel) TableName() string {
 
 type DeploymentModel struct {
 	BaseModel
-	ProjectID  uuid.UUID `gorm:"not null;index"`
-	CommitHash string    `gorm:"not null;check:commit_hash <> ''"`
-	Status     string    `gorm:"not null;check:status <> ''"` // in_progress, success, failed
-	Stdout     string    `gorm:"type:text"`                   // Command stdout output
-	Stderr     string    `gorm:"type:text"`                   // Command stderr output
+	ProjectID      uuid.UUID `gorm:"not null;index"`
+	CommitHash     string    `gorm:"not null;check:commit_hash <> ''"`
+	PreviousCommit string    `gorm:"not null;default:''"`         // Commit the deployment replaced, empty for the first one
+	Status         string    `gorm:"not null;check:status <> ''"` // in_progress, success, failed
+	Stdout         string    `gorm:"type:text"`                   // Command stdout output
+	Stderr         string    `gorm:"type:text"`                   // Command stderr output
+	CommandLine    string    `gorm:"type:text"`                   // Compose command line, with variable values masked
+	Notes          string    `gorm:"type:text"`                   // User-provided deployment notes
+	Labels         string    `gorm:"not null;default:''"`         // Labels separated by null character (\0)
+	FailedServices string    `gorm:"type:text"`                   // JSON list of services that did not start
+	DockerVersion  string    `gorm:"not null;default:''"`         // Docker daemon version, empty if unknown
+	ComposeVersion string    `gorm:"not null;default:''"`         // Docker Compose version, empty if unknown
+	ImageDigests   string    `gorm:"not null;default:''"`         // Pinned images (image:tag@digest) separated by \0
+	Summary        string    `gorm:"type:text"`                   // What the deployment changed, see domain.Deployment
 
 	Project ProjectModel `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`
 }
@@ -56,6 +96,50 @@ func (DeploymentModel) TableName() string {
 	return "deployments"
 }
 
+type ShareLinkModel struct {
+	BaseModel
+	ProjectID uuid.UUID  `gorm:"not null;index"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time // set when the link was revoked before it expired
+
+	Project ProjectModel `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`
+}
+
+func (ShareLinkModel) TableName() string {
+	return "share_links"
+}
+
+// APITokenModel is a token for the JSON API. Only the SHA-256 hash of the token is stored.
+type APITokenModel struct {
+	BaseModel
+	Name      string     `gorm:"not null;check:name <> ''"`
+	TokenHash string     `gorm:"not null;uniqueIndex"`
+	ProjectID *uuid.UUID `gorm:"type:char(36);index"` // the project the token is scoped to, all projects when null
+	Actions   string     `gorm:"not null"`            // allowed actions separated by \0
+
+	Project *ProjectModel `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`
+}
+
+func (APITokenModel) TableName() string {
+	return "api_tokens"
+}
+
+// WatcherCheckModel is the result of the most recent watcher check of a project
+type WatcherCheckModel struct {
+	ProjectID           uuid.UUID `gorm:"type:char(36);primaryKey"`
+	CheckedAt           time.Time `gorm:"not null"`
+	Outcome             string    `gorm:"not null"`
+	Error               string    `gorm:"not null;default:''"` // empty when the check succeeded
+	ConsecutiveFailures int       `gorm:"not null;default:0"`
+	Manual              bool      `gorm:"not null;default:false"`
+
+	Project ProjectModel `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE"`
+}
+
+func (WatcherCheckModel) TableName() string {
+	return "watcher_checks"
+}
+
 func (MigrationModel) TableName() string {
 	return "migrations"
 }
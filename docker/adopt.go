@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Labels Compose puts on the containers it creates
+const (
+	composeProjectLabel    = "com.docker.compose.project"
+	composeServiceLabel    = "com.docker.compose.service"
+	composeConfigHashLabel = "com.docker.compose.config-hash"
+	composeOneoffLabel     = "com.docker.compose.oneoff"
+)
+
+// ConfigHashes returns the configuration hash of each service in the rendered configuration,
+// keyed by service name. Compose stores the same hash on the containers it creates, so comparing
+// the two shows which services would be recreated by the next deployment.
+func (p *ComposeProject) ConfigHashes() (map[string]string, error) {
+	lines, err := p.configList("--hash", "*")
+	if err != nil {
+		return nil, err
+	}
+	return parseServiceHashes(lines), nil
+}
+
+// RunningConfigHashes returns the configuration hash recorded on the containers of the Compose
+// project with the given name, keyed by service name. Stopped containers are included, one-off
+// containers created by docker compose run are not. The map is empty if the project has no containers.
+func RunningConfigHashes(projectName string, timeout time.Duration) (map[string]string, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	format := fmt.Sprintf(`{{.Label "%s"}} {{.Label "%s"}}`, composeServiceLabel, composeConfigHashLabel)
+	cmd := dockerCommand(ctx, "ps", "--all",
+		"--filter", "label="+composeProjectLabel+"="+projectName,
+		"--filter", "label="+composeOneoffLabel+"=False",
+		"--format", format)
+	killProcessGroupOnCancel(cmd)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+
+	if err := cmd.Run(); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "docker",
+			"operation", "running_config_hashes",
+			"project_name", projectName,
+			"error", err,
+			"stderr", stderrBuf.String())
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("docker ps timed out after %s", timeout)
+		}
+		if stderr := strings.TrimSpace(stderrBuf.String()); stderr != "" {
+			return nil, fmt.Errorf("failed to list containers: %w: %s", err, stderr)
+		}
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	var lines []string
+	for line := range strings.Lines(stdoutBuf.String()) {
+		lines = append(lines, line)
+	}
+	return parseServiceHashes(lines), nil
+}
+
+// parseServiceHashes parses "<service> <hash>" lines. Several containers of a scaled service
+// share a hash, so the last one seen wins.
+func parseServiceHashes(lines []string) map[string]string {
+	hashes := make(map[string]string)
+	for _, line := range lines {
+		service, hash, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if service == "" {
+			continue
+		}
+		hashes[service] = strings.TrimSpace(hash)
+	}
+	return hashes
+}
+
+// CompareConfigHashes describes how the running containers differ from the declared configuration,
+// one sorted message per service. It returns nil when they match.
+func CompareConfigHashes(declared, running map[string]string) []string {
+	var differences []string
+	for service, hash := range declared {
+		runningHash, ok := running[service]
+		switch {
+		case !ok:
+			differences = append(differences,
+				fmt.Sprintf("service %s is declared in the repository but has no containers", service))
+		case runningHash != hash:
+			differences = append(differences,
+				fmt.Sprintf("service %s is running with a configuration that differs from the repository", service))
+		}
+	}
+	for service := range running {
+		if _, ok := declared[service]; !ok {
+			differences = append(differences,
+				fmt.Sprintf("service %s is running but is not declared in the repository", service))
+		}
+	}
+	slices.Sort(differences)
+	return differences
+}
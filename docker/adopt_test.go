@@ -0,0 +1,63 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// installHashDocker puts a docker stub on PATH that answers config --hash and ps with service
+// hashes, and records the ps arguments
+func installHashDocker(t *testing.T) string {
+	t.Helper()
+
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := `#!/bin/sh
+case "$1" in
+ps) echo "$*" >> ` + argsFile + `; printf 'web aaa\nweb aaa\nworker bbb\nold ccc\n' ;;
+compose) printf 'web aaa\nworker xxx\ncron ddd\n' ;;
+*) exit 1 ;;
+esac
+`
+	installStubDocker(t, script)
+	return argsFile
+}
+
+func TestConfigHashes(t *testing.T) {
+	argsFile := installHashDocker(t)
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	declared, err := project.ConfigHashes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"web": "aaa", "worker": "xxx", "cron": "ddd"}, declared)
+
+	// Scaled services have one line per container
+	running, err := docker.RunningConfigHashes("app", 0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"web": "aaa", "worker": "bbb", "old": "ccc"}, running)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "label=com.docker.compose.project=app")
+	assert.Contains(t, string(args), "label=com.docker.compose.oneoff=False")
+
+	assert.Equal(t, []string{
+		"service cron is declared in the repository but has no containers",
+		"service old is running but is not declared in the repository",
+		"service worker is running with a configuration that differs from the repository",
+	}, docker.CompareConfigHashes(declared, running))
+}
+
+func TestCompareConfigHashes_Match(t *testing.T) {
+	hashes := map[string]string{"web": "aaa"}
+	assert.Nil(t, docker.CompareConfigHashes(hashes, hashes))
+}
@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ansiSequence matches terminal escape sequences: CSI sequences such as colors and cursor
+// movement, OSC sequences such as window titles and hyperlinks, and two-byte escapes. Only text
+// that starts with an ESC byte matches, so bracketed text like "[31m" in a log line is kept.
+var ansiSequence = regexp.MustCompile(
+	"\x1b\\[[0-?]*[ -/]*[@-~]" + // CSI
+		"|\x1b\\][^\x07\x1b]*(?:\x07|\x1b\\\\)" + // OSC, terminated by BEL or ST
+		"|\x1b[@-Z\\\\^_]", // two-byte escapes
+)
+
+// maxPendingEscape bounds how much of an unterminated escape sequence ansiStripWriter holds back
+const maxPendingEscape = 256
+
+// StripANSI removes terminal escape sequences from s
+func StripANSI(s string) string {
+	if !strings.ContainsRune(s, '\x1b') {
+		return s
+	}
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+// ansiStripWriter removes terminal escape sequences from everything written to it. A sequence
+// split across writes is held back until it is complete, so Flush must be called at the end.
+type ansiStripWriter struct {
+	w       io.Writer
+	pending []byte
+}
+
+func newANSIStripWriter(w io.Writer) *ansiStripWriter {
+	return &ansiStripWriter{w: w}
+}
+
+func (a *ansiStripWriter) Write(p []byte) (int, error) {
+	data := append(a.pending, p...)
+	a.pending = nil
+
+	// Hold back a trailing escape that may continue in the next write
+	if i := bytes.LastIndexByte(data, '\x1b'); i >= 0 && len(data)-i <= maxPendingEscape {
+		tail := data[i:]
+		if loc := ansiSequence.FindIndex(tail); loc == nil || loc[0] != 0 {
+			a.pending = bytes.Clone(tail)
+			data = data[:i]
+		}
+	}
+
+	if _, err := a.w.Write(ansiSequence.ReplaceAll(data, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes out a held back escape that never completed, with the ESC byte dropped
+func (a *ansiStripWriter) Flush() error {
+	if len(a.pending) == 0 {
+		return nil
+	}
+	pending := a.pending
+	a.pending = nil
+	_, err := a.w.Write(pending[1:])
+	return err
+}
@@ -0,0 +1,122 @@
+package docker_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "colors",
+			input: "\x1b[32mINFO\x1b[0m server started on \x1b[1;34m:8080\x1b[m",
+			want:  "INFO server started on :8080",
+		},
+		{
+			name:  "256 and true color",
+			input: "\x1b[38;5;208mwarn\x1b[0m \x1b[38;2;255;0;0merror\x1b[0m",
+			want:  "warn error",
+		},
+		{
+			name:  "cursor movement and erase line",
+			input: "progress\x1b[2K\x1b[1G100%",
+			want:  "progress100%",
+		},
+		{
+			name:  "hyperlink",
+			input: "see \x1b]8;;https://example.com\x1b\\docs\x1b]8;;\x1b\\ for details",
+			want:  "see docs for details",
+		},
+		{
+			name:  "window title terminated by BEL",
+			input: "\x1b]0;worker\x07ready",
+			want:  "ready",
+		},
+		{
+			name:  "bracketed text without escape is kept",
+			input: "GET /items?filter[31m]=1 [200] took 3ms",
+			want:  "GET /items?filter[31m]=1 [200] took 3ms",
+		},
+		{
+			name:  "unicode and tabs are kept",
+			input: "naïve\tcafé ✓ 日本",
+			want:  "naïve\tcafé ✓ 日本",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, docker.StripANSI(tt.input))
+		})
+	}
+}
+
+func TestStripANSI_ComposeLogLine(t *testing.T) {
+	line := "time=\"2025-01-01T00:00:00Z\" level=warning msg=\"\x1b[33mvariable \\\"A[1]\\\" is not set\x1b[0m\""
+	assert.Equal(t, `variable "A[1]" is not set`, docker.ParseComposeLogLine(docker.StripANSI(line)))
+}
+
+func TestLogsOptions(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := "#!/bin/sh\necho \"$*\" >> " + argsFile + "\nprintf 'web-1  | \\033[31mfailed\\033[0m [retry]\\n'\n"
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	stdout, _, err := project.Logs(docker.LogsOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | \x1b[31mfailed\x1b[0m [retry]\n", stdout)
+
+	stdout, _, err = project.Logs(docker.LogsOptions{Timestamps: true, StripColor: true})
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | failed [retry]\n", stdout)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], " logs"), "unexpected arguments: %s", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " logs --timestamps"), "unexpected arguments: %s", lines[1])
+}
+
+func TestLogsPiping_StripColorAcrossWrites(t *testing.T) {
+	// The escape sequences are split across separate writes
+	script := "#!/bin/sh\nprintf 'web-1  | \\033[3'\nsleep 0.1\nprintf '1mred\\033'\nsleep 0.1\nprintf '[0m done\\n'\n"
+	installStubDocker(t, script)
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+	err = project.LogsPiping(docker.LogsOptions{StripColor: true})
+	os.Stdout = stdout
+	require.NoError(t, writer.Close())
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | red done\n", string(output))
+}
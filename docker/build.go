@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// BuildStreaming builds the images of the selected services, or of all services when none are
+// selected, with the project's build arguments. docker compose up cannot take build arguments, so a
+// deployment builds first and up uses the images built here. Services without a build section are
+// skipped by Compose.
+func (p *ComposeProject) BuildStreaming(outputChan chan<- StreamMessage) error {
+	cmd := p.streaming().commandBuild()
+	return p.executeCommandStreaming(cmd, outputChan)
+}
+
+func (p *ComposeProject) commandBuild() *exec.Cmd {
+	return p.prepareCommand("build", append(p.buildArgs(), p.Services...))
+}
+
+// buildArgs returns the --build-arg arguments of the project's build arguments, sorted by name
+func (p *ComposeProject) buildArgs() []string {
+	var args []string
+	for _, key := range slices.Sorted(maps.Keys(p.BuildArgs)) {
+		args = append(args, "--build-arg", key+"="+p.BuildArgs[key])
+	}
+	return args
+}
+
+// buildSecretsEnv returns the build secrets in KEY=value format, sorted by name
+func (p *ComposeProject) buildSecretsEnv() []string {
+	var env []string
+	for _, key := range slices.Sorted(maps.Keys(p.BuildSecrets)) {
+		env = append(env, key+"="+p.BuildSecrets[key])
+	}
+	return env
+}
+
+// RedactBuildSecrets masks the values of the project's build secrets wherever they appear in text,
+// such as in the output of a build that prints them or in the rendered configuration
+func (p *ComposeProject) RedactBuildSecrets(text string) string {
+	if len(p.BuildSecrets) == 0 {
+		return text
+	}
+	return newRedactor(slices.Collect(maps.Values(p.BuildSecrets))).Replace(text)
+}
+
+// maskBuildArgs returns a copy of argv with the values of build arguments masked, for logging.
+// Build arguments may hold credentials, such as a token to fetch private packages.
+func maskBuildArgs(argv []string) []string {
+	masked := slices.Clone(argv)
+	for i := 1; i < len(masked); i++ {
+		if masked[i-1] != "--build-arg" {
+			continue
+		}
+		if key, _, ok := strings.Cut(masked[i], "="); ok {
+			masked[i] = key + "=" + maskedValue
+		}
+	}
+	return masked
+}
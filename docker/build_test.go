@@ -0,0 +1,92 @@
+package docker_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestBuildStreaming(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	// Stub docker: records its arguments and prints the build secret it sees, as a careless build would
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> %s\necho \"token is $NPM_TOKEN\"\n", argsFile)
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+		Services:     []string{"web"},
+		BuildArgs:    map[string]string{"VERSION": "1.2.3", "API_KEY": "s3cr3t-arg"},
+		BuildSecrets: map[string]string{"NPM_TOKEN": "npm_0123456789"},
+	}
+
+	output := make(chan docker.StreamMessage, 100)
+	require.NoError(t, project.BuildStreaming(output))
+	close(output)
+	var printed []string
+	for msg := range output {
+		printed = append(printed, project.RedactBuildSecrets(msg.Content))
+	}
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	wantTail := "build --build-arg API_KEY=s3cr3t-arg --build-arg VERSION=1.2.3 web"
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(string(args)), wantTail), "unexpected arguments: %s", args)
+
+	assert.Contains(t, printed, "token is ********", "The build secret should reach the build, masked in its output")
+	assert.NotContains(t, logs.String(), "s3cr3t-arg", "Build argument values should be masked in logs")
+	assert.NotContains(t, logs.String(), "npm_0123456789")
+	assert.Contains(t, logs.String(), "API_KEY=********")
+
+	commandLine := project.UpCommandLine()
+	assert.Contains(t, commandLine, "NPM_TOKEN='********'")
+	assert.NotContains(t, commandLine, "npm_0123456789")
+}
+
+func TestRedactBuildSecrets(t *testing.T) {
+	project := &docker.ComposeProject{BuildSecrets: map[string]string{"TOKEN": "abc", "NPM_TOKEN": "npm_0123456789"}}
+
+	// Values too short to redact safely are left alone, like those of variables
+	assert.Equal(t, "npm ******** abc", project.RedactBuildSecrets("npm npm_0123456789 abc"))
+	assert.Equal(t, "unchanged", (&docker.ComposeProject{}).RedactBuildSecrets("unchanged"))
+}
+
+func TestBuildStreamingParallel(t *testing.T) {
+	// Stub docker: records its arguments
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" > %s\n", argsFile)
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+	build := func() string {
+		output := make(chan docker.StreamMessage, 100)
+		require.NoError(t, project.BuildStreaming(output))
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		return string(args)
+	}
+
+	assert.NotContains(t, build(), "--parallel")
+
+	project.Parallel = 1
+	assert.Contains(t, build(), "--project-directory "+project.WorkingDir+" --parallel 1 --file ")
+}
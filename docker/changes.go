@@ -0,0 +1,204 @@
+package docker
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigChange is the kind of difference between two rendered compose configurations
+type ConfigChange string
+
+const (
+	// ConfigChangeNone means no service configuration changed, e.g. only documentation did
+	ConfigChangeNone ConfigChange = "none"
+	// ConfigChangeEnvironment means only the environment of some services changed
+	ConfigChangeEnvironment ConfigChange = "environment"
+	// ConfigChangeImage means the image of some services changed, and possibly their environment
+	ConfigChangeImage ConfigChange = "image"
+	// ConfigChangeFull means the change needs all services to be brought up, or could not be classified
+	ConfigChangeFull ConfigChange = "full"
+)
+
+// ConfigChangePlan is the smallest deployment that applies a configuration change
+type ConfigChangePlan struct {
+	Change ConfigChange
+	// Services are the services to recreate, sorted by name. Empty means all services.
+	Services []string
+	// Pull means the images of the services must be pulled before they are recreated
+	Pull bool
+	// Reason describes the change, or why all services are needed
+	Reason string
+}
+
+// serviceKeysRecreatedAlone are the service settings whose change only affects the service itself
+var serviceKeysRecreatedAlone = map[string]ConfigChange{
+	"environment": ConfigChangeEnvironment,
+	"image":       ConfigChangeImage,
+}
+
+// ClassifyConfigChange compares the rendered configuration of the deployed commit with the one of the
+// new commit. Changes to the environment or image of services are applied by recreating just those
+// services; anything else, and anything that cannot be compared, needs a full deployment.
+func ClassifyConfigChange(oldConfig, newConfig string) ConfigChangePlan {
+	full := func(format string, args ...any) ConfigChangePlan {
+		return ConfigChangePlan{Change: ConfigChangeFull, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	var oldParsed, newParsed map[string]any
+	var links serviceLinks
+	if err := yaml.Unmarshal([]byte(oldConfig), &oldParsed); err != nil {
+		return full("cannot parse the previous configuration: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(newConfig), &newParsed); err != nil {
+		return full("cannot parse the new configuration: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(newConfig), &links); err != nil {
+		return full("cannot parse the service dependencies: %v", err)
+	}
+
+	// Networks, volumes, secrets and configs are shared between services
+	for _, key := range changedKeys(oldParsed, newParsed) {
+		if key != "services" {
+			return full("%s changed", key)
+		}
+	}
+
+	oldServices, oldOK := oldParsed["services"].(map[string]any)
+	newServices, newOK := newParsed["services"].(map[string]any)
+	if !oldOK || !newOK {
+		return full("cannot compare the services")
+	}
+	if !slices.Equal(slices.Sorted(maps.Keys(oldServices)), slices.Sorted(maps.Keys(newServices))) {
+		return full("services were added or removed")
+	}
+
+	plan := ConfigChangePlan{Change: ConfigChangeNone}
+	for _, name := range slices.Sorted(maps.Keys(newServices)) {
+		oldService, oldOK := oldServices[name].(map[string]any)
+		newService, newOK := newServices[name].(map[string]any)
+		if !oldOK || !newOK {
+			return full("cannot compare service %s", name)
+		}
+
+		keys := changedKeys(oldService, newService)
+		if len(keys) == 0 {
+			continue
+		}
+		for _, key := range keys {
+			change, ok := serviceKeysRecreatedAlone[key]
+			if !ok {
+				return full("%s of service %s changed", key, name)
+			}
+			if change == ConfigChangeImage {
+				plan.Pull = true
+			}
+		}
+		plan.Services = append(plan.Services, name)
+	}
+
+	if len(plan.Services) == 0 {
+		plan.Reason = "no service configuration changed"
+		return plan
+	}
+
+	// Services that depend on a recreated one may have to be restarted with it, which Compose only
+	// does when it brings up all services
+	if dependent, dependency := links.findDependent(plan.Services); dependent != "" {
+		return full("service %s depends on changed service %s", dependent, dependency)
+	}
+
+	if plan.Pull {
+		plan.Change = ConfigChangeImage
+		plan.Reason = fmt.Sprintf("image changed for %s", strings.Join(plan.Services, ", "))
+	} else {
+		plan.Change = ConfigChangeEnvironment
+		plan.Reason = fmt.Sprintf("environment changed for %s", strings.Join(plan.Services, ", "))
+	}
+	return plan
+}
+
+// ChangedConfigKeys lists what differs between two rendered compose configurations, sorted: the
+// top-level keys that changed, such as "networks", and for services the settings that changed, as
+// "services.web.environment", or the service itself, as "services.web", when it was added or removed
+func ChangedConfigKeys(oldConfig, newConfig string) ([]string, error) {
+	var oldParsed, newParsed map[string]any
+	if err := yaml.Unmarshal([]byte(oldConfig), &oldParsed); err != nil {
+		return nil, fmt.Errorf("cannot parse the previous configuration: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(newConfig), &newParsed); err != nil {
+		return nil, fmt.Errorf("cannot parse the new configuration: %w", err)
+	}
+
+	var keys []string
+	for _, key := range changedKeys(oldParsed, newParsed) {
+		oldServices, oldOK := oldParsed["services"].(map[string]any)
+		newServices, newOK := newParsed["services"].(map[string]any)
+		if key != "services" || !oldOK || !newOK {
+			keys = append(keys, key)
+			continue
+		}
+		for _, name := range changedKeys(oldServices, newServices) {
+			oldService, oldOK := oldServices[name].(map[string]any)
+			newService, newOK := newServices[name].(map[string]any)
+			if !oldOK || !newOK {
+				keys = append(keys, "services."+name)
+				continue
+			}
+			for _, serviceKey := range changedKeys(oldService, newService) {
+				keys = append(keys, "services."+name+"."+serviceKey)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// changedKeys returns the keys whose values differ between the two maps, sorted
+func changedKeys(old, new map[string]any) []string {
+	var keys []string
+	for key, value := range new {
+		if oldValue, ok := old[key]; !ok || !reflect.DeepEqual(oldValue, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := new[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// serviceLinks is the part of a rendered compose config that ties services to each other
+type serviceLinks struct {
+	Services map[string]struct {
+		DependsOn   dependsOn `yaml:"depends_on"`
+		NetworkMode string    `yaml:"network_mode"`
+	} `yaml:"services"`
+}
+
+// findDependent returns a service that is not changed but depends on one of the changed services,
+// through depends_on or by sharing its network stack, together with that changed service
+func (l serviceLinks) findDependent(changed []string) (string, string) {
+	for _, name := range slices.Sorted(maps.Keys(l.Services)) {
+		if slices.Contains(changed, name) {
+			continue
+		}
+		service := l.Services[name]
+		dependencies := service.DependsOn
+		if dependency, ok := strings.CutPrefix(service.NetworkMode, "service:"); ok {
+			dependencies = append(dependencies, dependency)
+		}
+		for _, dependency := range dependencies {
+			if slices.Contains(changed, dependency) {
+				return name, dependency
+			}
+		}
+	}
+	return "", ""
+}
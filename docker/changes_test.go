@@ -0,0 +1,297 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestClassifyConfigChange(t *testing.T) {
+	const base = `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:1
+networks:
+  default:
+    name: app_default
+`
+
+	tests := []struct {
+		name         string
+		newConfig    string
+		wantChange   docker.ConfigChange
+		wantServices []string
+		wantPull     bool
+		wantReason   string
+	}{
+		{
+			name:       "no change",
+			newConfig:  base,
+			wantChange: docker.ConfigChangeNone,
+			wantReason: "no service configuration changed",
+		},
+		{
+			name: "environment of a leaf service",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: staging
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:1
+networks:
+  default:
+    name: app_default
+`,
+			wantChange:   docker.ConfigChangeEnvironment,
+			wantServices: []string{"web"},
+			wantReason:   "environment changed for web",
+		},
+		{
+			name: "image and environment of two services",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.28
+    environment:
+      MODE: staging
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:1
+    environment:
+      QUEUE: default
+networks:
+  default:
+    name: app_default
+`,
+			wantChange:   docker.ConfigChangeImage,
+			wantServices: []string{"web", "worker"},
+			wantPull:     true,
+			wantReason:   "image changed for web, worker",
+		},
+		{
+			name: "service with dependents",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:17
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:1
+networks:
+  default:
+    name: app_default
+`,
+			wantChange: docker.ConfigChangeFull,
+			wantReason: "service web depends on changed service db",
+		},
+		{
+			name: "other service setting",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:1
+    ports:
+      - "8080:80"
+networks:
+  default:
+    name: app_default
+`,
+			wantChange: docker.ConfigChangeFull,
+			wantReason: "ports of service worker changed",
+		},
+		{
+			name: "network",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+  worker:
+    image: app/worker:2
+networks:
+  default:
+    name: app_default
+  backend:
+    name: app_backend
+`,
+			wantChange: docker.ConfigChangeFull,
+			wantReason: "networks changed",
+		},
+		{
+			name: "volume added",
+			newConfig: base + `volumes:
+  data:
+    name: app_data
+`,
+			wantChange: docker.ConfigChangeFull,
+			wantReason: "volumes changed",
+		},
+		{
+			name: "service removed",
+			newConfig: `name: app
+services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+    depends_on:
+      db:
+        condition: service_started
+        required: true
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_DB: app
+networks:
+  default:
+    name: app_default
+`,
+			wantChange: docker.ConfigChangeFull,
+			wantReason: "services were added or removed",
+		},
+		{
+			name:       "unparseable",
+			newConfig:  "services: [",
+			wantChange: docker.ConfigChangeFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := docker.ClassifyConfigChange(base, tt.newConfig)
+			assert.Equal(t, tt.wantChange, plan.Change)
+			assert.Equal(t, tt.wantServices, plan.Services)
+			assert.Equal(t, tt.wantPull, plan.Pull)
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, plan.Reason)
+			} else {
+				assert.NotEmpty(t, plan.Reason)
+			}
+		})
+	}
+}
+
+func TestClassifyConfigChangeNetworkMode(t *testing.T) {
+	oldConfig := `services:
+  vpn:
+    image: vpn:1
+  client:
+    image: client:1
+    network_mode: service:vpn
+`
+	newConfig := `services:
+  vpn:
+    image: vpn:2
+  client:
+    image: client:1
+    network_mode: service:vpn
+`
+	plan := docker.ClassifyConfigChange(oldConfig, newConfig)
+	assert.Equal(t, docker.ConfigChangeFull, plan.Change)
+	assert.Equal(t, "service client depends on changed service vpn", plan.Reason)
+}
+
+func TestChangedConfigKeys(t *testing.T) {
+	oldConfig := `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+  cache:
+    image: redis:7
+volumes:
+  data: {}
+`
+	newConfig := `services:
+  web:
+    image: nginx:1.28
+    environment:
+      MODE: production
+    ports:
+      - "80:80"
+  worker:
+    image: app/worker:1
+networks:
+  backend: {}
+volumes:
+  data: {}
+`
+	keys, err := docker.ChangedConfigKeys(oldConfig, newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"networks",
+		"services.cache",
+		"services.web.image",
+		"services.web.ports",
+		"services.worker",
+	}, keys)
+
+	keys, err = docker.ChangedConfigKeys(oldConfig, oldConfig)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = docker.ChangedConfigKeys("services: [", newConfig)
+	assert.ErrorContains(t, err, "cannot parse the previous configuration")
+}
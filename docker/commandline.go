@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"strings"
+)
+
+// UpCommandLine returns the shell command line that starts the project's services, for example
+// to reproduce a deployment by hand. Inline variables and build secrets are passed in the environment,
+// as Oar does, with their values masked, followed by the deploy variables. Values from env files stay
+// in the generated env file.
+func (p *ComposeProject) UpCommandLine() string {
+	parts := []string{"NO_COLOR=1"}
+	for _, variable := range p.Variables {
+		key, _, ok := strings.Cut(variable, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		// Quote only the value, a quoted KEY=value would not be an assignment
+		parts = append(parts, key+"="+shellQuote(maskedValue))
+	}
+
+	for _, variable := range p.buildSecretsEnv() {
+		key, _, _ := strings.Cut(variable, "=")
+		parts = append(parts, key+"="+shellQuote(maskedValue))
+	}
+
+	// Deploy variables are not secret and identify what was deployed
+	for _, variable := range p.DeployVariables {
+		key, value, _ := strings.Cut(variable, "=")
+		parts = append(parts, key+"="+shellQuote(value))
+	}
+
+	parts = append(parts, dockerCommandLine()...)
+	for _, arg := range p.composeArgs("up", p.upArgs(true)) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes s for a POSIX shell when it contains anything other than safe characters
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, c := range s {
+		if !isShellSafe(c) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellSafe(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.ContainsRune("@%+=:,./_-", c)
+	}
+}
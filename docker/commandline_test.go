@@ -0,0 +1,117 @@
+package docker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestUpCommandLine(t *testing.T) {
+	override := "services: {}"
+	project := &docker.ComposeProject{
+		Name:            "my-app",
+		WorkingDir:      "/data/projects/my app/git",
+		ComposeFiles:    []string{"compose.yaml"},
+		ComposeOverride: &override,
+		Variables:       []string{"DB_PASSWORD=hunter2", "LOG_LEVEL=debug", "invalid"},
+		EnvFile:         "/data/projects/my app/git/.oar.env",
+		Services:        []string{"web"},
+	}
+
+	commandLine := project.UpCommandLine()
+
+	assert.Equal(t,
+		"NO_COLOR=1 DB_PASSWORD='********' LOG_LEVEL='********' docker compose --progress plain "+
+			"--project-name my-app "+
+			"--project-directory '/data/projects/my app/git' "+
+			"--file '/data/projects/my app/git/compose.yaml' "+
+			"--file '/data/projects/my app/git/.oar-compose-override.yaml' "+
+			"--env-file '/data/projects/my app/git/.oar.env' "+
+			"up --detach --quiet-pull --quiet-build --remove-orphans web",
+		commandLine)
+	assert.NotContains(t, commandLine, "hunter2")
+	assert.NotContains(t, commandLine, "debug")
+}
+
+func TestUpCommandLineOffline(t *testing.T) {
+	project := &docker.ComposeProject{
+		Name:         "my-app",
+		WorkingDir:   "/data/projects/my-app/git",
+		ComposeFiles: []string{"compose.yaml"},
+		EnvFile:      "/data/projects/my-app/git/.oar.env",
+		ImagePolicy:  docker.ImagePolicy{Offline: true},
+	}
+
+	assert.Contains(t, project.UpCommandLine(), "up --detach --quiet-pull --quiet-build --remove-orphans --pull never")
+}
+
+func TestUpCommandLineNoDeps(t *testing.T) {
+	project := &docker.ComposeProject{
+		Name:         "my-app",
+		WorkingDir:   "/data/projects/my-app/git",
+		ComposeFiles: []string{"compose.yaml"},
+		NoDeps:       true,
+	}
+
+	// Without services, the whole project is deployed and --no-deps would be meaningless
+	assert.NotContains(t, project.UpCommandLine(), "--no-deps")
+
+	project.Services = []string{"web"}
+	assert.Contains(t, project.UpCommandLine(), "up --detach --quiet-pull --quiet-build --remove-orphans --no-deps web")
+}
+
+func TestUpCommandLineUpOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options docker.UpOptions
+		want    string
+	}{
+		{name: "quiet by default", want: "up --detach --quiet-pull --quiet-build --remove-orphans"},
+		{
+			name:    "build output",
+			options: docker.UpOptions{VerboseBuild: true},
+			want:    "up --detach --quiet-pull --remove-orphans",
+		},
+		{
+			name:    "pull progress",
+			options: docker.UpOptions{VerbosePull: true},
+			want:    "up --detach --quiet-build --remove-orphans",
+		},
+		{
+			name:    "rebuild",
+			options: docker.UpOptions{Build: true},
+			want:    "up --detach --quiet-pull --quiet-build --remove-orphans --build",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := &docker.ComposeProject{
+				Name:         "my-app",
+				WorkingDir:   "/data/projects/my-app/git",
+				ComposeFiles: []string{"compose.yaml"},
+				UpOptions:    tt.options,
+			}
+			assert.True(t, strings.HasSuffix(project.UpCommandLine(), tt.want), project.UpCommandLine())
+		})
+	}
+}
+
+func TestUpCommandLineParallel(t *testing.T) {
+	project := &docker.ComposeProject{
+		Name:         "my-app",
+		WorkingDir:   "/data/projects/my-app/git",
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	// Unset, Compose uses its own default
+	assert.NotContains(t, project.UpCommandLine(), "--parallel")
+
+	// --parallel is a flag of docker compose itself, so it comes before the command
+	project.Parallel = 2
+	assert.Contains(t, project.UpCommandLine(),
+		"--project-directory /data/projects/my-app/git --parallel 2 --file /data/projects/my-app/git/compose.yaml up ")
+}
@@ -4,15 +4,20 @@ package docker
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/oar-cd/oar/config"
 	"github.com/oar-cd/oar/domain"
@@ -20,8 +25,10 @@ import (
 
 // StreamMessage represents a message in the streaming output
 type StreamMessage struct {
-	Type    string `json:"type"`    // "stdout", "stderr", "info", "success", "error"
+	Type    string `json:"type"`    // "stdout", "stderr", "info", "success", "error", "progress"
 	Content string `json:"content"` // the actual message content
+	// Progress is set on "progress" messages, Content then holds the same information as text
+	Progress *Progress `json:"progress,omitempty"`
 }
 
 type ComposeProjectStatus int
@@ -31,8 +38,18 @@ const (
 	ComposeProjectStatusRunning
 	ComposeProjectStatusStopped
 	ComposeProjectStatusFailed
+	// ComposeProjectStatusDegraded means all containers are running but some keep restarting
+	ComposeProjectStatusDegraded
+	// ComposeProjectStatusDeploying means a deployment of the project is in progress, so its
+	// containers may be in a transient state
+	ComposeProjectStatusDeploying
+	// ComposeProjectStatusPaused means the containers are up but frozen, some or all of them paused
+	ComposeProjectStatusPaused
 )
 
+// DegradedRestartCount is the restart count from which a running container is considered crash-looping
+const DegradedRestartCount = 3
+
 func (s ComposeProjectStatus) String() string {
 	switch s {
 	case ComposeProjectStatusRunning:
@@ -41,6 +58,12 @@ func (s ComposeProjectStatus) String() string {
 		return "stopped"
 	case ComposeProjectStatusFailed:
 		return "failed"
+	case ComposeProjectStatusDegraded:
+		return "degraded"
+	case ComposeProjectStatusDeploying:
+		return "deploying"
+	case ComposeProjectStatusPaused:
+		return "paused"
 	case ComposeProjectStatusUnknown:
 		return "unknown"
 	default:
@@ -56,6 +79,12 @@ func ParseComposeProjectStatus(s string) (ComposeProjectStatus, error) {
 		return ComposeProjectStatusStopped, nil
 	case "failed":
 		return ComposeProjectStatusFailed, nil
+	case "degraded":
+		return ComposeProjectStatusDegraded, nil
+	case "deploying":
+		return ComposeProjectStatusDeploying, nil
+	case "paused":
+		return ComposeProjectStatusPaused, nil
 	case "unknown":
 		return ComposeProjectStatusUnknown, nil
 	default:
@@ -63,6 +92,25 @@ func ParseComposeProjectStatus(s string) (ComposeProjectStatus, error) {
 	}
 }
 
+// ProjectStatus maps the Docker Compose status to the status stored for the project
+func (s ComposeProjectStatus) ProjectStatus() domain.ProjectStatus {
+	switch s {
+	case ComposeProjectStatusRunning, ComposeProjectStatusDegraded:
+		return domain.ProjectStatusRunning
+	case ComposeProjectStatusStopped:
+		return domain.ProjectStatusStopped
+	case ComposeProjectStatusFailed:
+		// Containers are in mixed states, which we consider an error
+		return domain.ProjectStatusError
+	case ComposeProjectStatusDeploying:
+		return domain.ProjectStatusDeploying
+	case ComposeProjectStatusPaused:
+		return domain.ProjectStatusPaused
+	default:
+		return domain.ProjectStatusUnknown
+	}
+}
+
 type ContainerInfo struct {
 	Service    string `json:"Service"`
 	Name       string `json:"Name"`
@@ -70,6 +118,10 @@ type ContainerInfo struct {
 	Status     string `json:"Status"`
 	RunningFor string `json:"RunningFor"`
 	ExitCode   int    `json:"ExitCode"`
+
+	// From docker inspect, zero values when the container could not be inspected
+	RestartCount   int    `json:"RestartCount"`
+	LastExitReason string `json:"LastExitReason,omitempty"`
 }
 
 type ComposeStatus struct {
@@ -89,11 +141,76 @@ type ComposeProject struct {
 	ComposeOverride *string
 	// Variables contains variables in KEY=value format
 	Variables []string
+	// DeployVariables are the variables Oar sets itself, in KEY=value format. They override Variables.
+	DeployVariables []string
+	// EnvFile is the generated env file with the resolved project environment
+	EnvFile string
+	// Services limits up to the named services (and the services they depend on). Empty means all services.
+	Services []string
+	// NoDeps leaves the services that Services depend on untouched, with up --no-deps. It has no
+	// effect without Services.
+	NoDeps bool
 	// Config holds configuration for docker commands and timeouts
 	Config *config.Config
+	// ImagePolicy controls whether images are pulled, and from where
+	ImagePolicy ImagePolicy
+	// ImagePins are the images pinned to digests by PinImages, keyed by service
+	ImagePins map[string]string
+	// ContainerLabels are set on every service by LabelContainers
+	ContainerLabels map[string]string
+	// LabelledServices are the services LabelContainers wrote the container labels for
+	LabelledServices []string
+	// BuildArgs are passed to docker compose build as --build-arg, see BuildStreaming
+	BuildArgs map[string]string
+	// BuildSecrets are set in the environment of every Compose command, where compose files hand them
+	// to BuildKit as secrets with an environment source. Their values are masked in output.
+	BuildSecrets map[string]string
+
+	// Up changes what up shows of image pulls and builds, and whether it rebuilds images
+	UpOptions UpOptions
+	// Parallel limits how many images up, pull and build handle at once, 0 for the Compose default
+	Parallel int
+
+	// progress is the --progress mode, plain when empty
+	progress string
 }
 
-func NewComposeProject(p *domain.Project, cfg *config.Config) (*ComposeProject, error) {
+// UpOptions are the settings of docker compose up beyond the services it brings up. The zero value
+// keeps up quiet about pulling and building and builds only missing images.
+type UpOptions struct {
+	VerboseBuild bool // Show the output of image builds, up passes --quiet-build otherwise
+	VerbosePull  bool // Show the progress of image pulls, up passes --quiet-pull otherwise
+	Build        bool // Build images before creating the containers, with --build
+}
+
+// ComposeProjectOption customizes a Compose project during creation
+type ComposeProjectOption func(*composeProjectOptions)
+
+type composeProjectOptions struct {
+	commit       string
+	deploymentID string
+}
+
+// WithDeployment sets the deploy variables for the given deployment of the given commit. Without it,
+// OAR_COMMIT is the project's deployed commit and OAR_DEPLOY_ID is empty.
+func WithDeployment(deploymentID, commit string) ComposeProjectOption {
+	return func(o *composeProjectOptions) {
+		o.deploymentID = deploymentID
+		o.commit = commit
+	}
+}
+
+func NewComposeProject(p *domain.Project, cfg *config.Config, opts ...ComposeProjectOption) (*ComposeProject, error) {
+	options := composeProjectOptions{commit: p.LocalCommitStr()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	environment, parallel := "", 0
+	if cfg != nil {
+		environment, parallel = cfg.ComposeDeployEnv, cfg.ComposeParallel
+	}
+	deployVariables := DeployVariables(options.commit, p.Name, options.deploymentID, environment)
+
 	gitDir, err := p.GitDir()
 	if err != nil {
 		slog.Error("Service operation failed",
@@ -105,9 +222,8 @@ func NewComposeProject(p *domain.Project, cfg *config.Config) (*ComposeProject,
 	}
 
 	// Write override file if present
-	// Use .oar-compose-override.yaml to avoid colliding with any compose.override.yaml in the repo
 	if p.ComposeOverride != nil && *p.ComposeOverride != "" {
-		overridePath := filepath.Join(gitDir, ".oar-compose-override.yaml")
+		overridePath := filepath.Join(gitDir, OverrideFile)
 		if err := os.WriteFile(overridePath, []byte(*p.ComposeOverride), 0644); err != nil {
 			err = fmt.Errorf("failed to write override file: %w", err)
 			slog.Error("Service operation failed",
@@ -123,16 +239,48 @@ func NewComposeProject(p *domain.Project, cfg *config.Config) (*ComposeProject,
 			"override_path", overridePath)
 	}
 
+	// Write the resolved environment so compose sees repo .env, env files and variables in a fixed order
+	envFile, err := writeGeneratedEnvFile(gitDir, p.EnabledComposeFiles(), p.EnvFiles, p.Variables, deployVariables)
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "docker_compose",
+			"operation", "create_compose_project",
+			"project_name", p.Name,
+			"error", err)
+		return nil, err
+	}
+
 	return &ComposeProject{
-		Name:            p.Name,
+		Name:            p.ComposeProjectName(),
 		WorkingDir:      gitDir,
-		ComposeFiles:    p.ComposeFiles,
+		ComposeFiles:    p.EnabledComposeFiles(),
 		ComposeOverride: p.ComposeOverride,
 		Variables:       p.Variables,
+		DeployVariables: deployVariables,
+		EnvFile:         envFile,
 		Config:          cfg,
+		ImagePolicy:     newImagePolicy(p, cfg),
+		BuildArgs:       p.BuildArgs,
+		BuildSecrets:    p.BuildSecrets,
+		ContainerLabels: p.ContainerLabels,
+		UpOptions:       UpOptions{VerboseBuild: p.VerboseBuild, VerbosePull: p.VerbosePull, Build: p.BuildOnDeploy},
+		Parallel:        parallel,
 	}, nil
 }
 
+// newImagePolicy combines the image settings of the project with the configured defaults. The
+// project's registry mirror replaces the default one; offline mode applies when either enables it.
+func newImagePolicy(p *domain.Project, cfg *config.Config) ImagePolicy {
+	policy := ImagePolicy{RegistryMirror: p.RegistryMirror, Offline: p.OfflineImages}
+	if cfg != nil {
+		if policy.RegistryMirror == "" {
+			policy.RegistryMirror = cfg.ComposeRegistryMirror
+		}
+		policy.Offline = policy.Offline || cfg.ComposeOffline
+	}
+	return policy
+}
+
 func (p *ComposeProject) Up(startServices bool) (string, string, error) {
 	cmd := p.commandUp(startServices)
 	stdout, stderr, err := p.executeCommand(cmd)
@@ -143,7 +291,7 @@ func (p *ComposeProject) Up(startServices bool) (string, string, error) {
 }
 
 func (p *ComposeProject) UpStreaming(startServices bool, outputChan chan<- StreamMessage) error {
-	cmd := p.commandUp(startServices)
+	cmd := p.streaming().commandUp(startServices)
 	return p.executeCommandStreaming(cmd, outputChan)
 }
 
@@ -152,50 +300,152 @@ func (p *ComposeProject) UpPiping(startServices bool) error {
 	return p.executeCommandPiping(cmd)
 }
 
-func (p *ComposeProject) Down(removeVolumes bool) (string, string, error) {
-	cmd := p.commandDown(removeVolumes)
-	stdout, stderr, err := p.executeCommand(cmd)
+// DownOptions controls how the containers of a project are stopped and removed
+type DownOptions struct {
+	// RemoveVolumes also removes the named volumes of the project
+	RemoveVolumes bool
+	// Timeout is how long containers get to exit after the stop signal before they are killed. Zero
+	// keeps the stop_grace_period of each service, which defaults to 10 seconds.
+	Timeout time.Duration
+	// Signal replaces the stop_signal of each service. It must have been validated with ParseSignal.
+	// Empty keeps the signal from the compose file, which defaults to SIGTERM.
+	Signal string
+	// RemoveImages also removes the images of the services, as docker compose down --rmi does. It must
+	// have been validated with ValidateRemoveImages. Empty keeps the images.
+	RemoveImages string
+}
+
+const (
+	// RemoveImagesLocal removes only the images without a custom tag, i.e. those built by Compose
+	RemoveImagesLocal = "local"
+	// RemoveImagesAll removes every image the services use, including pulled ones that other
+	// projects may use as well
+	RemoveImagesAll = "all"
+)
+
+// ValidateRemoveImages checks an image removal mode for DownOptions.RemoveImages
+func ValidateRemoveImages(mode string) error {
+	switch mode {
+	case "", RemoveImagesLocal, RemoveImagesAll:
+		return nil
+	}
+	return fmt.Errorf("invalid image removal %q: must be %s or %s", mode, RemoveImagesLocal, RemoveImagesAll)
+}
+
+// Down stops and removes the containers of the project. Like DownStreaming and DownPiping, it force
+// removes the containers when docker compose down does not finish within the down timeout.
+func (p *ComposeProject) Down(opts DownOptions) (string, string, error) {
+	var stdout, stderr string
+	err := p.down(opts, func(cmd *exec.Cmd) error {
+		var err error
+		stdout, stderr, err = p.executeCommand(cmd)
+		return err
+	}, func(string) {})
 	if err != nil {
 		return "", "", err
 	}
 	return stdout, stderr, nil
 }
 
-func (p *ComposeProject) DownStreaming(outputChan chan<- StreamMessage) error {
-	cmd := p.commandDown(false)
-	return p.executeCommandStreaming(cmd, outputChan)
+func (p *ComposeProject) DownStreaming(opts DownOptions, outputChan chan<- StreamMessage) error {
+	streaming := p.streaming()
+	return streaming.down(opts, func(cmd *exec.Cmd) error {
+		return streaming.executeCommandStreaming(cmd, outputChan)
+	}, func(message string) {
+		outputChan <- StreamMessage{Type: "info", Content: message}
+	})
 }
 
-func (p *ComposeProject) DownPiping() error {
-	cmd := p.commandDown(false)
-	return p.executeCommandPiping(cmd)
+func (p *ComposeProject) DownPiping(opts DownOptions) error {
+	return p.down(opts, p.executeCommandPiping, func(message string) {
+		fmt.Fprintln(os.Stderr, message)
+	})
+}
+
+// KillStreaming sends a signal to the containers of a service. The signal must have been
+// validated with ParseSignal, since docker compose kill defaults to SIGKILL.
+func (p *ComposeProject) KillStreaming(service, signal string, outputChan chan<- StreamMessage) error {
+	cmd := p.streaming().commandKill(service, signal)
+	return p.executeCommandStreaming(cmd, outputChan)
 }
 
-func (p *ComposeProject) Logs() (string, string, error) {
-	cmd := p.commandLogs(false) // No follow for static logs
+func (p *ComposeProject) Logs(opts LogsOptions) (string, string, error) {
+	grep, err := opts.grepRegexp()
+	if err != nil {
+		return "", "", err
+	}
+
+	cmd := p.commandLogs(false, opts) // No follow for static logs
 	stdout, stderr, err := p.executeCommand(cmd)
 	if err != nil {
 		return "", "", err
 	}
+	if grep != nil {
+		stdout, stderr = filterLogLines(stdout, grep), filterLogLines(stderr, grep)
+	}
+	if opts.StripColor {
+		stdout, stderr = StripANSI(stdout), StripANSI(stderr)
+	}
 	return stdout, stderr, nil
 }
 
-func (p *ComposeProject) LogsPiping() error {
-	cmd := p.commandLogs(true) // Follow for CLI streaming
-	return p.executeCommandPiping(cmd)
+func (p *ComposeProject) LogsPiping(opts LogsOptions) error {
+	grep, err := opts.grepRegexp()
+	if err != nil {
+		return err
+	}
+
+	cmd := p.commandLogs(true, opts) // Follow for CLI streaming
+	if !opts.StripColor && grep == nil {
+		return p.executeCommandPiping(cmd)
+	}
+
+	// Lines are filtered before they are written out, colors are stripped last
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	var stripStdout, stripStderr *ansiStripWriter
+	if opts.StripColor {
+		stripStdout, stripStderr = newANSIStripWriter(stdout), newANSIStripWriter(stderr)
+		stdout, stderr = stripStdout, stripStderr
+	}
+	var grepStdout, grepStderr *logGrepWriter
+	if grep != nil {
+		grepStdout, grepStderr = newLogGrepWriter(stdout, grep), newLogGrepWriter(stderr, grep)
+		stdout, stderr = grepStdout, grepStderr
+	}
+
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err = p.executeCommandPiping(cmd)
+
+	// An unfinished last line held back by the grep writers goes through the strip writers
+	var flushErrs []error
+	if grep != nil {
+		flushErrs = append(flushErrs, grepStdout.Flush(), grepStderr.Flush())
+	}
+	if opts.StripColor {
+		flushErrs = append(flushErrs, stripStdout.Flush(), stripStderr.Flush())
+	}
+	if flushErr := errors.Join(flushErrs...); err == nil {
+		err = flushErr
+	}
+	return err
 }
 
+// GetConfig returns the resolved compose configuration. It gives up with a TimeoutError when
+// Docker does not answer within the query timeout.
 func (p *ComposeProject) GetConfig() (string, string, error) {
-	cmd := p.commandConfig()
+	ctx, cancel := context.WithTimeout(context.Background(), p.queryTimeout())
+	defer cancel()
+
+	cmd := p.commandConfig(ctx)
 	stdout, stderr, err := p.executeCommand(cmd)
 	if err != nil {
-		return "", "", err
+		return "", stderr, p.timeoutError(ctx, "config", err)
 	}
 	return stdout, stderr, nil
 }
 
 func (p *ComposeProject) Pull() (string, string, error) {
-	cmd := p.commandPull()
+	cmd := p.commandPull(p.Services)
 	stdout, stderr, err := p.executeCommand(cmd)
 	if err != nil {
 		return "", "", err
@@ -203,6 +453,31 @@ func (p *ComposeProject) Pull() (string, string, error) {
 	return stdout, stderr, nil
 }
 
+// PullStreaming pulls the images of the selected services, or of all services when none are selected.
+// In offline mode nothing is pulled. With a registry mirror, images are pulled from the mirror and
+// docker compose only pulls what the mirror could not provide.
+func (p *ComposeProject) PullStreaming(outputChan chan<- StreamMessage) error {
+	if p.ImagePolicy.Offline {
+		outputChan <- StreamMessage{Type: "info", Content: "Offline mode: skipping image pull"}
+		return nil
+	}
+
+	services := p.Services
+	if p.ImagePolicy.RegistryMirror != "" {
+		remaining, err := p.pullViaMirror(outputChan)
+		if err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		services = remaining
+	}
+
+	cmd := p.streaming().commandPull(services)
+	return p.executeCommandStreaming(cmd, outputChan)
+}
+
 func (p *ComposeProject) Build() (string, string, error) {
 	cmd := p.commandBuild()
 	stdout, stderr, err := p.executeCommand(cmd)
@@ -213,53 +488,78 @@ func (p *ComposeProject) Build() (string, string, error) {
 }
 
 func (p *ComposeProject) prepareCommand(command string, args []string) *exec.Cmd {
-	// Build docker compose command
-	commandArgs := []string{
-		"compose",
-		"--progress", "plain",
-		"--project-name", p.Name,
-	}
-
-	// Add compose files to the command
-	for _, file := range p.ComposeFiles {
-		commandArgs = append(commandArgs, "--file", filepath.Join(p.WorkingDir, file))
-	}
-
-	// Add override file if we have override content
-	if p.ComposeOverride != nil && *p.ComposeOverride != "" {
-		overridePath := filepath.Join(p.WorkingDir, ".oar-compose-override.yaml")
-		commandArgs = append(commandArgs, "--file", overridePath)
-	}
+	return p.prepareCommandContext(context.Background(), command, args)
+}
 
-	// Add the specific command and its arguments
-	commandArgs = append(commandArgs, command)
-	commandArgs = append(commandArgs, args...)
+// prepareCommandContext builds a Docker Compose command that is killed when ctx is done
+func (p *ComposeProject) prepareCommandContext(ctx context.Context, command string, args []string) *exec.Cmd {
+	commandArgs := p.composeArgs(command, args)
 
+	name, argv := dockerArgv(commandArgs)
 	slog.Debug("Executing Docker Compose command",
-		"command", "docker",
-		"args", commandArgs,
+		"command", name,
+		"args", maskBuildArgs(argv),
 		"project_name", p.Name)
 
 	// Create command
-	cmd := exec.Command("docker", commandArgs...)
+	cmd := exec.CommandContext(ctx, name, argv...)
 	// Do not set cmd.Dir to avoid Docker resolving container paths as host paths.
-	// The compose files are already specified with absolute paths via --file flags.
+	// The compose files and the project directory are already given as absolute paths.
 
 	// Disable color output to simplify parsing logs and status
 	cmd.Env = append(os.Environ(), "NO_COLOR=1")
 
 	// Inject variables if provided
 	if len(p.Variables) > 0 {
-		// Start with existing environment and append/override with user variables
+		// Compose gives the process environment precedence over env files, so inline variables are
+		// also injected here to keep them above any same-named variable in the server's environment
 		cmd.Env = append(cmd.Env, p.Variables...)
 		slog.Debug("Injecting variables",
 			"project_name", p.Name,
 			"var_count", len(p.Variables))
 	}
+	cmd.Env = append(cmd.Env, p.buildSecretsEnv()...)
+	// Later entries win, so the deploy variables override any variable of the same name
+	cmd.Env = append(cmd.Env, p.DeployVariables...)
 
 	return cmd
 }
 
+// composeArgs builds the docker arguments for a Docker Compose command
+func (p *ComposeProject) composeArgs(command string, args []string) []string {
+	commandArgs := []string{
+		"compose",
+		"--progress", p.progressMode(),
+		"--project-name", p.Name,
+		// Resolve relative build contexts and bind mounts against the repository root. Without it
+		// Compose uses the directory of the first compose file, which differs for files in subdirectories.
+		"--project-directory", p.WorkingDir,
+	}
+
+	// Limit the images pulled and built at once. Other commands keep the default, so that stopping
+	// a project is not slowed down.
+	if p.Parallel != 0 && (command == "up" || command == "pull" || command == "build") {
+		commandArgs = append(commandArgs, "--parallel", strconv.Itoa(p.Parallel))
+	}
+
+	// Compose merges the files in the order given, so the override comes last and wins
+	for _, file := range p.ConfigFiles() {
+		commandArgs = append(commandArgs, "--file", filepath.Join(p.WorkingDir, file))
+	}
+
+	// Use the generated env file instead of letting compose auto-load the project .env,
+	// which is already merged into it
+	if p.EnvFile != "" {
+		commandArgs = append(commandArgs, "--env-file", p.EnvFile)
+	}
+
+	// Add the specific command and its arguments
+	commandArgs = append(commandArgs, command)
+	commandArgs = append(commandArgs, args...)
+
+	return commandArgs
+}
+
 func (p *ComposeProject) executeCommand(cmd *exec.Cmd) (stdout string, stderr string, err error) {
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
@@ -346,24 +646,27 @@ func (p *ComposeProject) executeCommandStreaming(cmd *exec.Cmd, outputChan chan<
 			}
 		}()
 		scanner := bufio.NewScanner(stderr)
+		var throttle progressThrottle
 		for scanner.Scan() {
-			rawLine := scanner.Text()
-			// Parse Docker Compose structured logs to extract just the message
-			parsedContent := ParseComposeLogLine(rawLine)
+			// Parse Docker Compose progress and structured logs to extract just the message
+			message := ParseComposeProgress(scanner.Text())
+			if !throttle.changed(message) {
+				continue
+			}
 			select {
-			case outputChan <- StreamMessage{Type: "stderr", Content: parsedContent}:
+			case outputChan <- message:
 			default:
 				return
 			}
 		}
 	}()
 
+	// Read all output before waiting for the command, since Wait closes the pipes
+	wg.Wait()
+
 	// Wait for command to finish
 	cmdErr := cmd.Wait()
 
-	// Wait for all goroutines to finish reading output before checking for errors
-	wg.Wait()
-
 	if cmdErr != nil {
 		slog.Error("Service operation failed",
 			"layer", "docker_compose",
@@ -378,9 +681,13 @@ func (p *ComposeProject) executeCommandStreaming(cmd *exec.Cmd, outputChan chan<
 }
 
 func (p *ComposeProject) executeCommandPiping(cmd *exec.Cmd) error {
-	// Inherit stdout and stderr for direct piping to terminal
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Inherit stdout and stderr for direct piping to terminal, unless the caller filters them
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
 
 	slog.Debug("Executing Docker Compose command with direct piping",
 		"project_name", p.Name,
@@ -402,50 +709,114 @@ func (p *ComposeProject) executeCommandPiping(cmd *exec.Cmd) error {
 }
 
 func (p *ComposeProject) commandUp(startServices bool) *exec.Cmd {
-	args := []string{"--detach", "--quiet-pull", "--quiet-build", "--remove-orphans"}
+	return p.prepareCommand("up", p.upArgs(startServices))
+}
+
+func (p *ComposeProject) upArgs(startServices bool) []string {
+	args := []string{"--detach"}
+	if !p.UpOptions.VerbosePull {
+		args = append(args, "--quiet-pull")
+	}
+	if !p.UpOptions.VerboseBuild {
+		args = append(args, "--quiet-build")
+	}
+	args = append(args, "--remove-orphans")
+	if p.UpOptions.Build {
+		args = append(args, "--build")
+	}
 	if !startServices {
 		args = append(args, "--no-start")
 	}
-	return p.prepareCommand("up", args)
+	if p.ImagePolicy.Offline {
+		args = append(args, "--pull", "never")
+	}
+	if p.NoDeps && len(p.Services) > 0 {
+		args = append(args, "--no-deps")
+	}
+	return append(args, p.Services...)
 }
 
-func (p *ComposeProject) commandDown(removeVolumes bool) *exec.Cmd {
+func (p *ComposeProject) commandDown(ctx context.Context, opts DownOptions) *exec.Cmd {
 	args := []string{"--remove-orphans"}
-	if removeVolumes {
+	if opts.RemoveVolumes {
 		args = append(args, "--volumes")
 	}
-	return p.prepareCommand("down", args)
+	if opts.RemoveImages != "" {
+		args = append(args, "--rmi", opts.RemoveImages)
+	}
+	if opts.Timeout > 0 {
+		args = append(args, "--timeout", timeoutSeconds(opts.Timeout))
+	}
+	cmd := p.prepareCommandContext(ctx, "down", args)
+	killProcessGroupOnCancel(cmd)
+	return cmd
 }
 
-func (p *ComposeProject) commandLogs(follow bool) *exec.Cmd {
+func (p *ComposeProject) commandKill(service, signal string) *exec.Cmd {
+	return p.prepareCommand("kill", []string{"--signal", signal, service})
+}
+
+func (p *ComposeProject) commandLogs(follow bool, opts LogsOptions) *exec.Cmd {
 	args := []string{}
 	if follow {
 		args = append(args, "--follow")
 	}
-	return p.prepareCommand("logs", args)
+	if opts.Timestamps {
+		args = append(args, "--timestamps")
+	}
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	if opts.Tail > 0 {
+		args = append(args, "--tail", strconv.Itoa(opts.Tail))
+	}
+	return p.prepareCommand("logs", append(args, opts.Services...))
 }
 
-func (p *ComposeProject) commandConfig() *exec.Cmd {
-	return p.prepareCommand("config", []string{})
+func (p *ComposeProject) commandConfig(ctx context.Context) *exec.Cmd {
+	cmd := p.prepareCommandContext(ctx, "config", []string{})
+	killProcessGroupOnCancel(cmd)
+	return cmd
 }
 
-func (p *ComposeProject) commandPull() *exec.Cmd {
-	return p.prepareCommand("pull", []string{})
+func (p *ComposeProject) commandPull(services []string) *exec.Cmd {
+	return p.prepareCommand("pull", services)
 }
 
-func (p *ComposeProject) commandBuild() *exec.Cmd {
-	return p.prepareCommand("build", []string{})
+func (p *ComposeProject) commandPs(ctx context.Context, all bool) *exec.Cmd {
+	args := []string{"--format", "json"}
+	if all {
+		args = append(args, "--all")
+	}
+	cmd := p.prepareCommandContext(ctx, "ps", args)
+	killProcessGroupOnCancel(cmd)
+	return cmd
 }
 
-func (p *ComposeProject) commandPs() *exec.Cmd {
-	return p.prepareCommand("ps", []string{"--format", "json"})
+func (p *ComposeProject) Status() (*ComposeStatus, error) {
+	return p.StatusContext(context.Background())
 }
 
-func (p *ComposeProject) Status() (*ComposeStatus, error) {
-	cmd := p.commandPs()
+// StatusContext is like Status but gives up when ctx is done. Either way it returns a TimeoutError
+// when Docker does not answer within the query timeout.
+func (p *ComposeProject) StatusContext(ctx context.Context) (*ComposeStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.queryTimeout())
+	defer cancel()
+
+	containers, err := p.listContainers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return NewComposeStatus(containers), nil
+}
+
+// listContainers returns the containers of the project, only the running ones unless all is set
+func (p *ComposeProject) listContainers(ctx context.Context, all bool) ([]ContainerInfo, error) {
+	cmd := p.commandPs(ctx, all)
 
 	stdout, stderr, err := p.executeCommand(cmd)
 	if err != nil {
+		err = p.timeoutError(ctx, "ps", err)
 		slog.Error("Service operation failed",
 			"layer", "docker_compose",
 			"operation", "docker_compose_status",
@@ -474,12 +845,20 @@ func (p *ComposeProject) Status() (*ComposeStatus, error) {
 		containers = append(containers, container)
 	}
 
-	// Determine overall project status
+	p.addInspectData(ctx, containers)
+
+	return containers, nil
+}
+
+// NewComposeStatus determines the overall project status from its containers
+func NewComposeStatus(containers []ContainerInfo) *ComposeStatus {
 	projectStatus := ComposeProjectStatusStopped
 	uptime := ""
 	if len(containers) > 0 {
 		runningCount := 0
+		pausedCount := 0
 		totalRelevantContainers := 0
+		restartLooping := false
 
 		for _, container := range containers {
 			// Skip containers that have legitimately exited with success (e.g., init containers)
@@ -488,17 +867,28 @@ func (p *ComposeProject) Status() (*ComposeStatus, error) {
 			}
 
 			totalRelevantContainers++
-			if container.State == "running" {
+			// A paused container is up, only frozen
+			if container.State == "running" || container.State == "paused" {
 				runningCount++
 				if uptime == "" {
 					uptime = strings.TrimSuffix(container.RunningFor, " ago")
 				}
 			}
+			if container.State == "paused" {
+				pausedCount++
+			}
+			if container.RestartCount >= DegradedRestartCount {
+				restartLooping = true
+			}
 		}
 
 		if totalRelevantContainers == 0 {
 			// All containers are successfully exited init containers - we can't determine status
 			projectStatus = ComposeProjectStatusUnknown
+		} else if runningCount == totalRelevantContainers && pausedCount > 0 {
+			projectStatus = ComposeProjectStatusPaused
+		} else if runningCount == totalRelevantContainers && restartLooping {
+			projectStatus = ComposeProjectStatusDegraded
 		} else if runningCount == totalRelevantContainers {
 			projectStatus = ComposeProjectStatusRunning
 		} else if runningCount > 0 {
@@ -510,7 +900,79 @@ func (p *ComposeProject) Status() (*ComposeStatus, error) {
 		Status:     projectStatus,
 		Containers: containers,
 		Uptime:     uptime,
-	}, nil
+	}
+}
+
+// containerInspect is the subset of docker inspect output used for the project status
+type containerInspect struct {
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		ExitCode  int    `json:"ExitCode"`
+		Error     string `json:"Error"`
+		OOMKilled bool   `json:"OOMKilled"`
+	} `json:"State"`
+}
+
+// lastExitReason describes why the container last exited, or returns "" if it is not known
+func (c containerInspect) lastExitReason() string {
+	switch {
+	case c.State.OOMKilled:
+		return fmt.Sprintf("killed by the OOM killer (exit code %d)", c.State.ExitCode)
+	case c.State.Error != "":
+		return c.State.Error
+	case c.State.ExitCode != 0:
+		return fmt.Sprintf("exited with code %d", c.State.ExitCode)
+	default:
+		return ""
+	}
+}
+
+// addInspectData fills in restart counts and last exit reasons from docker inspect.
+// The data is informational, so containers that cannot be inspected are left as they are.
+func (p *ComposeProject) addInspectData(ctx context.Context, containers []ContainerInfo) {
+	if len(containers) == 0 {
+		return
+	}
+
+	names := make([]string, len(containers))
+	for i, container := range containers {
+		names[i] = container.Name
+	}
+
+	// docker inspect prints the containers it found even when others are missing
+	cmd := dockerCommand(ctx, append([]string{"inspect"}, names...)...)
+	killProcessGroupOnCancel(cmd)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	if err := cmd.Run(); err != nil {
+		slog.Debug("Failed to inspect some containers",
+			"project_name", p.Name,
+			"error", err,
+			"stderr", stderrBuf.String())
+	}
+
+	var inspected []containerInspect
+	if err := json.Unmarshal(stdoutBuf.Bytes(), &inspected); err != nil {
+		slog.Debug("Failed to parse container inspect output",
+			"project_name", p.Name,
+			"error", err)
+		return
+	}
+
+	byName := make(map[string]containerInspect, len(inspected))
+	for _, info := range inspected {
+		byName[strings.TrimPrefix(info.Name, "/")] = info
+	}
+	for i := range containers {
+		info, ok := byName[containers[i].Name]
+		if !ok {
+			continue
+		}
+		containers[i].RestartCount = info.RestartCount
+		containers[i].LastExitReason = info.lastExitReason()
+	}
 }
 
 // ParseComposeLogLine parses Docker Compose structured log format and extracts the msg value
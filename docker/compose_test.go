@@ -0,0 +1,257 @@
+package docker_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestNewComposeStatus(t *testing.T) {
+	running := func(service string, restarts int) docker.ContainerInfo {
+		return docker.ContainerInfo{
+			Service:      service,
+			Name:         "app-" + service + "-1",
+			State:        "running",
+			RunningFor:   "5 minutes ago",
+			RestartCount: restarts,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		containers []docker.ContainerInfo
+		want       docker.ComposeProjectStatus
+	}{
+		{
+			name: "no containers",
+			want: docker.ComposeProjectStatusStopped,
+		},
+		{
+			name:       "all running without restarts or inspect data",
+			containers: []docker.ContainerInfo{running("web", 0), running("db", 0)},
+			want:       docker.ComposeProjectStatusRunning,
+		},
+		{
+			name: "some running",
+			containers: []docker.ContainerInfo{
+				running("web", 0),
+				{Service: "db", State: "exited", ExitCode: 1},
+			},
+			want: docker.ComposeProjectStatusFailed,
+		},
+		{
+			name: "successful init container is ignored",
+			containers: []docker.ContainerInfo{
+				running("web", 0),
+				{Service: "migrate", State: "exited", ExitCode: 0},
+			},
+			want: docker.ComposeProjectStatusRunning,
+		},
+		{
+			name:       "only successful init containers",
+			containers: []docker.ContainerInfo{{Service: "migrate", State: "exited", ExitCode: 0}},
+			want:       docker.ComposeProjectStatusUnknown,
+		},
+		{
+			name:       "all exited with errors",
+			containers: []docker.ContainerInfo{{Service: "web", State: "exited", ExitCode: 137}},
+			want:       docker.ComposeProjectStatusStopped,
+		},
+		{
+			name:       "few restarts stay running",
+			containers: []docker.ContainerInfo{running("web", docker.DegradedRestartCount-1), running("db", 0)},
+			want:       docker.ComposeProjectStatusRunning,
+		},
+		{
+			name:       "restart loop is degraded",
+			containers: []docker.ContainerInfo{running("web", 14), running("db", 0)},
+			want:       docker.ComposeProjectStatusDegraded,
+		},
+		{
+			name: "restarting container that is down is failed",
+			containers: []docker.ContainerInfo{
+				running("web", 0),
+				{Service: "worker", State: "restarting", ExitCode: 1, RestartCount: 14},
+			},
+			want: docker.ComposeProjectStatusFailed,
+		},
+		{
+			name: "paused containers are paused, not failed",
+			containers: []docker.ContainerInfo{
+				{Service: "web", State: "paused", RunningFor: "5 minutes ago"},
+				{Service: "db", State: "paused", RunningFor: "5 minutes ago"},
+			},
+			want: docker.ComposeProjectStatusPaused,
+		},
+		{
+			name: "partly paused",
+			containers: []docker.ContainerInfo{
+				running("web", 0),
+				{Service: "db", State: "paused", RunningFor: "5 minutes ago"},
+			},
+			want: docker.ComposeProjectStatusPaused,
+		},
+		{
+			name: "paused with a container down is failed",
+			containers: []docker.ContainerInfo{
+				{Service: "web", State: "paused", RunningFor: "5 minutes ago"},
+				{Service: "db", State: "exited", ExitCode: 1},
+			},
+			want: docker.ComposeProjectStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := docker.NewComposeStatus(tt.containers)
+			assert.Equal(t, tt.want, status.Status)
+			assert.Equal(t, tt.containers, status.Containers)
+		})
+	}
+}
+
+func TestNewComposeStatusUptime(t *testing.T) {
+	status := docker.NewComposeStatus([]docker.ContainerInfo{
+		{Service: "web", State: "running", RunningFor: "2 hours ago", RestartCount: 5},
+	})
+
+	assert.Equal(t, docker.ComposeProjectStatusDegraded, status.Status)
+	assert.Equal(t, "2 hours", status.Uptime)
+	assert.Equal(t, "degraded", status.Status.String())
+}
+
+func TestComposeProjectStatusParseAndMapping(t *testing.T) {
+	tests := []struct {
+		status  docker.ComposeProjectStatus
+		name    string
+		project domain.ProjectStatus
+	}{
+		{docker.ComposeProjectStatusUnknown, "unknown", domain.ProjectStatusUnknown},
+		{docker.ComposeProjectStatusRunning, "running", domain.ProjectStatusRunning},
+		{docker.ComposeProjectStatusStopped, "stopped", domain.ProjectStatusStopped},
+		{docker.ComposeProjectStatusFailed, "failed", domain.ProjectStatusError},
+		{docker.ComposeProjectStatusDegraded, "degraded", domain.ProjectStatusRunning},
+		{docker.ComposeProjectStatusDeploying, "deploying", domain.ProjectStatusDeploying},
+		{docker.ComposeProjectStatusPaused, "paused", domain.ProjectStatusPaused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, tt.status.String())
+			parsed, err := docker.ParseComposeProjectStatus(tt.name)
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, parsed)
+
+			// The stored project status survives a round trip through its name
+			assert.Equal(t, tt.project, tt.status.ProjectStatus())
+			stored, err := domain.ParseProjectStatus(tt.project.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.project, stored)
+		})
+	}
+
+	_, err := docker.ParseComposeProjectStatus("restarting")
+	assert.Error(t, err)
+	_, err = domain.ParseProjectStatus("restarting")
+	assert.Error(t, err)
+}
+
+func TestParseSignal(t *testing.T) {
+	for input, want := range map[string]string{
+		"SIGHUP":  "SIGHUP",
+		"hup":     "SIGHUP",
+		" USR1 ":  "SIGUSR1",
+		"sigterm": "SIGTERM",
+	} {
+		signal, err := docker.ParseSignal(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, signal, input)
+	}
+
+	for _, input := range []string{"", "   ", "SIGKILL", "KILL", "SIGFOO", "9"} {
+		_, err := docker.ParseSignal(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// installRecordingDocker puts a docker stub on PATH that succeeds and appends its arguments to the
+// returned file, one invocation per line
+func installRecordingDocker(t *testing.T) string {
+	t.Helper()
+
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> %s\n", argsFile)
+	installStubDocker(t, script)
+	return argsFile
+}
+
+func TestUpSelectedServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []string
+		wantTail string
+	}{
+		{
+			name:     "all services",
+			wantTail: "up --detach --quiet-pull --quiet-build --remove-orphans",
+		},
+		{
+			name:     "single service",
+			services: []string{"web"},
+			wantTail: "up --detach --quiet-pull --quiet-build --remove-orphans web",
+		},
+		{
+			name:     "several services",
+			services: []string{"web", "worker"},
+			wantTail: "up --detach --quiet-pull --quiet-build --remove-orphans web worker",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := installRecordingDocker(t)
+			project := &docker.ComposeProject{
+				Name:         "app",
+				WorkingDir:   t.TempDir(),
+				ComposeFiles: []string{"compose.yaml"},
+				Services:     tt.services,
+			}
+
+			_, _, err := project.Up(true)
+			require.NoError(t, err)
+
+			args, err := os.ReadFile(argsFile)
+			require.NoError(t, err)
+			assert.True(t, strings.HasSuffix(strings.TrimSpace(string(args)), tt.wantTail),
+				"unexpected arguments: %s", args)
+		})
+	}
+}
+
+func TestProjectDirectoryIsRepositoryRoot(t *testing.T) {
+	argsFile := installRecordingDocker(t)
+	workingDir := t.TempDir()
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   workingDir,
+		ComposeFiles: []string{"deploy/compose.yaml"},
+	}
+
+	_, _, err := project.Up(true)
+	require.NoError(t, err)
+
+	// Relative build contexts in deploy/compose.yaml resolve against the repository root,
+	// not against deploy/
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "--project-directory "+workingDir+" ")
+	assert.Contains(t, string(args), "--file "+filepath.Join(workingDir, "deploy/compose.yaml"))
+}
@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ErrContainerNotFound is returned when a container does not exist or belongs to another project
+var ErrContainerNotFound = errors.New("container not found in project")
+
+// ContainerLogs returns the logs of a single container of the project, by container ID or name.
+// Unlike Logs, which reads all containers of the selected services, this reads one replica of a
+// scaled service. The container must carry the project's Compose label, so that the logs of other
+// projects cannot be read through this project. opts.Services must be empty.
+func (p *ComposeProject) ContainerLogs(containerRef string, opts LogsOptions) (string, string, error) {
+	if len(opts.Services) > 0 {
+		return "", "", fmt.Errorf("services cannot be selected when reading the logs of a container")
+	}
+	grep, err := opts.grepRegexp()
+	if err != nil {
+		return "", "", err
+	}
+
+	dc, err := NewDockerClient()
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err := dc.Close(); err != nil {
+			slog.Debug("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.queryTimeout())
+	defer cancel()
+	dc.ctx = ctx
+
+	stdout, stderr, err := dc.projectContainerLogs(p.Name, containerRef, opts)
+	if err != nil {
+		return "", "", p.timeoutError(ctx, "logs "+containerRef, err)
+	}
+	if grep != nil {
+		stdout, stderr = filterLogLines(stdout, grep), filterLogLines(stderr, grep)
+	}
+	if opts.StripColor {
+		stdout, stderr = StripANSI(stdout), StripANSI(stderr)
+	}
+	return stdout, stderr, nil
+}
+
+// projectContainerLogs reads the logs of a container of the Compose project with the given name
+func (dc *DockerClient) projectContainerLogs(
+	projectName, containerRef string,
+	opts LogsOptions,
+) (string, string, error) {
+	inspect, err := dc.cli.ContainerInspect(dc.ctx, containerRef)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return "", "", fmt.Errorf("%w: %s", ErrContainerNotFound, containerRef)
+		}
+		return "", "", fmt.Errorf("failed to inspect container %s: %w", containerRef, err)
+	}
+	if inspect.Config == nil || inspect.Config.Labels[composeProjectLabel] != projectName {
+		return "", "", fmt.Errorf("%w: %s", ErrContainerNotFound, containerRef)
+	}
+
+	logsOptions := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: opts.Timestamps,
+		Since:      opts.Since,
+	}
+	if opts.Tail > 0 {
+		logsOptions.Tail = strconv.Itoa(opts.Tail)
+	}
+	reader, err := dc.cli.ContainerLogs(dc.ctx, inspect.ID, logsOptions)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get logs of container %s: %w", containerRef, err)
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil {
+			slog.Debug("Failed to close container logs reader", "error", closeErr)
+		}
+	}()
+
+	// Logs of a container with a TTY are a single raw stream, otherwise stdout and stderr are multiplexed
+	var stdout, stderr bytes.Buffer
+	if inspect.Config.Tty {
+		_, err = io.Copy(&stdout, reader)
+	} else {
+		_, err = stdcopy.StdCopy(&stdout, &stderr, reader)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read logs of container %s: %w", containerRef, err)
+	}
+	return stdout.String(), stderr.String(), nil
+}
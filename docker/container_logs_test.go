@@ -0,0 +1,144 @@
+package docker_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// fakeContainer is a container served by serveContainerLogsAPI
+type fakeContainer struct {
+	id      string
+	name    string
+	project string
+	stdout  string
+	stderr  string
+}
+
+// serveContainerLogsAPI serves container inspection and logs for containers on a unix socket. It
+// returns the socket path and a function that returns the query of the last logs request.
+func serveContainerLogsAPI(t *testing.T, containers ...fakeContainer) (string, func() url.Values) {
+	t.Helper()
+
+	lookup := func(ref string) (fakeContainer, bool) {
+		for _, c := range containers {
+			if c.id == ref || c.name == ref {
+				return c, true
+			}
+		}
+		return fakeContainer{}, false
+	}
+
+	var mu sync.Mutex
+	var lastQuery url.Values
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", "1.45")
+		_, _ = w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("GET /{version}/containers/{ref}/json", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := lookup(r.PathValue("ref"))
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such container: ` + r.PathValue("ref") + `"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{ID: c.id, Name: "/" + c.name},
+			Config: &container.Config{Labels: map[string]string{
+				"com.docker.compose.project": c.project,
+				"com.docker.compose.service": "web",
+			}},
+		})
+	})
+	mux.HandleFunc("GET /{version}/containers/{ref}/logs", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := lookup(r.PathValue("ref"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		lastQuery = r.URL.Query()
+		mu.Unlock()
+		_, _ = stdcopy.NewStdWriter(w, stdcopy.Stdout).Write([]byte(c.stdout))
+		_, _ = stdcopy.NewStdWriter(w, stdcopy.Stderr).Write([]byte(c.stderr))
+	})
+
+	return serveDockerAPI(t, mux), func() url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastQuery
+	}
+}
+
+func TestContainerLogsOfScaledService(t *testing.T) {
+	socket, lastQuery := serveContainerLogsAPI(t,
+		fakeContainer{id: "a1", name: "shop-web-1", project: "shop", stdout: "GET / 200 from replica 1\n"},
+		fakeContainer{
+			id:      "b2",
+			name:    "shop-web-2",
+			project: "shop",
+			stdout:  "GET / 200 from replica 2\n\x1b[31mGET /cart 500\x1b[0m\n",
+			stderr:  "panic in replica 2\n",
+		},
+		fakeContainer{id: "c3", name: "blog-web-1", project: "blog", stdout: "blog\n"},
+	)
+	configureHost(t, docker.HostSettings{Host: "unix://" + socket})
+
+	project := &docker.ComposeProject{Name: "shop"}
+
+	t.Run("each replica has its own logs", func(t *testing.T) {
+		stdout, stderr, err := project.ContainerLogs("shop-web-1", docker.LogsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "GET / 200 from replica 1\n", stdout)
+		assert.Empty(t, stderr)
+
+		stdout, stderr, err = project.ContainerLogs("b2", docker.LogsOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "GET / 200 from replica 2\n\x1b[31mGET /cart 500\x1b[0m\n", stdout)
+		assert.Equal(t, "panic in replica 2\n", stderr)
+	})
+
+	t.Run("options are passed to the daemon", func(t *testing.T) {
+		_, _, err := project.ContainerLogs("shop-web-2", docker.LogsOptions{Tail: 50, Since: "1h", Timestamps: true})
+		require.NoError(t, err)
+
+		query := lastQuery()
+		assert.Equal(t, "50", query.Get("tail"))
+		assert.NotEmpty(t, query.Get("since"))
+		assert.Equal(t, "1", query.Get("timestamps"))
+	})
+
+	t.Run("lines are filtered and colors stripped", func(t *testing.T) {
+		stdout, _, err := project.ContainerLogs("shop-web-2", docker.LogsOptions{Grep: "cart", StripColor: true})
+		require.NoError(t, err)
+		assert.Equal(t, "GET /cart 500\n", stdout)
+	})
+
+	t.Run("containers of other projects are rejected", func(t *testing.T) {
+		_, _, err := project.ContainerLogs("blog-web-1", docker.LogsOptions{})
+		require.ErrorIs(t, err, docker.ErrContainerNotFound)
+	})
+
+	t.Run("unknown containers are rejected", func(t *testing.T) {
+		_, _, err := project.ContainerLogs("shop-web-3", docker.LogsOptions{})
+		require.ErrorIs(t, err, docker.ErrContainerNotFound)
+	})
+
+	t.Run("services cannot be selected", func(t *testing.T) {
+		_, _, err := project.ContainerLogs("shop-web-1", docker.LogsOptions{Services: []string{"web"}})
+		require.Error(t, err)
+	})
+}
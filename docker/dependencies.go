@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeServices is the part of a rendered compose config needed to build the dependency graph
+type composeServices struct {
+	Services map[string]struct {
+		DependsOn dependsOn `yaml:"depends_on"`
+	} `yaml:"services"`
+}
+
+// dependsOn accepts both the short (list) and long (map) depends_on syntax
+type dependsOn []string
+
+func (d *dependsOn) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var services []string
+		if err := node.Decode(&services); err != nil {
+			return err
+		}
+		*d = services
+	case yaml.MappingNode:
+		var services map[string]yaml.Node
+		if err := node.Decode(&services); err != nil {
+			return err
+		}
+		for service := range services {
+			*d = append(*d, service)
+		}
+	default:
+		return fmt.Errorf("unexpected depends_on value at line %d", node.Line)
+	}
+	sort.Strings(*d)
+	return nil
+}
+
+// CheckDependencyCycles parses a rendered compose config and returns an error describing
+// the first depends_on cycle found, e.g. "dependency cycle: a → b → a"
+func CheckDependencyCycles(config string) error {
+	var parsed composeServices
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return fmt.Errorf("failed to parse compose config: %w", err)
+	}
+
+	graph := make(map[string][]string, len(parsed.Services))
+	names := make([]string, 0, len(parsed.Services))
+	for name, service := range parsed.Services {
+		graph[name] = service.DependsOn
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report the same cycle on every run
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(names))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range graph[name] {
+			switch state[dependency] {
+			case visiting:
+				// The cycle starts where dependency first appears on the current path
+				for i, service := range path {
+					if service == dependency {
+						return append(append([]string{}, path[i:]...), dependency)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dependency); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if state[name] != unvisited {
+			continue
+		}
+		if cycle := visit(name); cycle != nil {
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " → "))
+		}
+	}
+	return nil
+}
+
+// ResolveServices parses a rendered compose config, checks that the selected services exist and returns
+// them together with the services they depend on, directly or indirectly, sorted by name
+func ResolveServices(config string, selected []string) ([]string, error) {
+	var parsed composeServices
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+
+	var unknown []string
+	for _, service := range selected {
+		if _, ok := parsed.Services[service]; !ok {
+			unknown = append(unknown, service)
+		}
+	}
+	if len(unknown) > 0 {
+		available := make([]string, 0, len(parsed.Services))
+		for name := range parsed.Services {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("unknown service(s): %s (available: %s)",
+			strings.Join(unknown, ", "), strings.Join(available, ", "))
+	}
+
+	included := make(map[string]bool)
+	var include func(name string)
+	include = func(name string) {
+		if _, ok := parsed.Services[name]; !ok || included[name] {
+			return
+		}
+		included[name] = true
+		for _, dependency := range parsed.Services[name].DependsOn {
+			include(dependency)
+		}
+	}
+	for _, service := range selected {
+		include(service)
+	}
+
+	resolved := make([]string, 0, len(included))
+	for name := range included {
+		resolved = append(resolved, name)
+	}
+	sort.Strings(resolved)
+	return resolved, nil
+}
+
+// ValidateConfig renders the compose config and checks it for depends_on cycles and, when the
+// deployment is limited to some services, that those services exist
+func (p *ComposeProject) ValidateConfig() error {
+	stdout, stderr, err := p.GetConfig()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" && !IsTimeout(err) {
+			return fmt.Errorf("invalid compose configuration: %s", msg)
+		}
+		return fmt.Errorf("invalid compose configuration: %w", err)
+	}
+	if err := CheckDependencyCycles(stdout); err != nil {
+		return err
+	}
+	if len(p.Services) > 0 {
+		if _, err := ResolveServices(stdout, p.Services); err != nil {
+			return err
+		}
+	}
+	return nil
+}
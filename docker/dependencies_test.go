@@ -0,0 +1,160 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestCheckDependencyCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "no dependencies",
+			config: `services:
+  web:
+    image: nginx
+`,
+		},
+		{
+			name: "acyclic with shared dependency",
+			config: `services:
+  web:
+    depends_on: [api, cache]
+  api:
+    depends_on:
+      db:
+        condition: service_healthy
+      cache:
+        condition: service_started
+  cache: {}
+  db: {}
+`,
+		},
+		{
+			name: "self dependency",
+			config: `services:
+  web:
+    depends_on:
+      web:
+        condition: service_started
+`,
+			wantErr: "dependency cycle: web → web",
+		},
+		{
+			name: "two node cycle",
+			config: `services:
+  a:
+    depends_on: [b]
+  b:
+    depends_on: [a]
+`,
+			wantErr: "dependency cycle: a → b → a",
+		},
+		{
+			name: "multi node cycle behind an acyclic prefix",
+			config: `services:
+  app:
+    depends_on: [worker]
+  worker:
+    depends_on:
+      queue:
+        condition: service_healthy
+  queue:
+    depends_on: [scheduler]
+  scheduler:
+    depends_on: [worker]
+`,
+			wantErr: "dependency cycle: worker → queue → scheduler → worker",
+		},
+		{
+			name: "dependency on an undefined service is not a cycle",
+			config: `services:
+  web:
+    depends_on: [missing]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := docker.CheckDependencyCycles(tt.config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+func TestCheckDependencyCyclesInvalidConfig(t *testing.T) {
+	err := docker.CheckDependencyCycles("services: [")
+	assert.ErrorContains(t, err, "failed to parse compose config")
+
+	err = docker.CheckDependencyCycles("services:\n  web:\n    depends_on: web\n")
+	assert.Error(t, err)
+}
+
+func TestResolveServices(t *testing.T) {
+	config := `services:
+  web:
+    depends_on: [api]
+  api:
+    depends_on:
+      db:
+        condition: service_healthy
+  db: {}
+  worker:
+    depends_on: [db]
+  docs: {}
+`
+
+	tests := []struct {
+		name     string
+		selected []string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "single service without dependencies",
+			selected: []string{"docs"},
+			want:     []string{"docs"},
+		},
+		{
+			name:     "transitive dependencies are included",
+			selected: []string{"web"},
+			want:     []string{"api", "db", "web"},
+		},
+		{
+			name:     "shared dependencies are listed once",
+			selected: []string{"worker", "api"},
+			want:     []string{"api", "db", "worker"},
+		},
+		{
+			name:     "unknown services are reported with the available ones",
+			selected: []string{"web", "cache", "queue"},
+			wantErr:  "unknown service(s): cache, queue (available: api, db, docs, web, worker)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := docker.ResolveServices(config, tt.selected)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
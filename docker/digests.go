@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"gopkg.in/yaml.v3"
+)
+
+// ImagePinsFile is where the image digests pinned for a deployment are written in the git directory.
+// Compose merges it after the compose override, so the pinned images replace the tags of the services.
+const ImagePinsFile = ".oar-image-digests.yaml"
+
+// ResolveImageDigest returns the digest the registry currently serves for image, such as
+// "sha256:...". For multi-platform images it is the digest of the index, so the pinned image still
+// resolves to the platform of the host. The docker CLI resolves it with the credentials it stores
+// for the registry, i.e. those of docker login, like Compose uses to pull.
+func ResolveImageDigest(image string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	out, err := dockerOutput(ctx, "buildx", "imagetools", "inspect", "--format", "{{.Manifest.Digest}}", image)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("resolving the digest of %s timed out after %s", image, timeout)
+		}
+		return "", fmt.Errorf("failed to resolve the digest of %s: %w", image, err)
+	}
+	if out == "" || reference.DigestRegexp.FindString(out) != out {
+		return "", fmt.Errorf("registry returned an invalid digest for %s: %q", image, out)
+	}
+	return out, nil
+}
+
+// PinnedImage returns image pinned to digest, e.g. "nginx:1.27@sha256:...". Compose pulls the digest
+// and ignores the tag, which stays for readability.
+func PinnedImage(image, digest string) string {
+	return image + "@" + digest
+}
+
+// SplitPinnedImage returns the image and the digest of a reference made by PinnedImage
+func SplitPinnedImage(pinned string) (string, string, bool) {
+	return strings.Cut(pinned, "@")
+}
+
+// IsPinnedImage reports whether the image reference already names a digest
+func IsPinnedImage(image string) bool {
+	return strings.Contains(image, "@")
+}
+
+// AllServiceImages returns the images of all services, whatever services are selected, sorted by
+// service. Services that are built are left out, see ParseServiceImages.
+func (p *ComposeProject) AllServiceImages() ([]ServiceImage, error) {
+	config, stderr, err := p.GetConfig()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" && !IsTimeout(err) {
+			return nil, fmt.Errorf("invalid compose configuration: %s", msg)
+		}
+		return nil, fmt.Errorf("invalid compose configuration: %w", err)
+	}
+	return ParseServiceImages(config, nil)
+}
+
+// PinImages replaces the images of services with the pinned references, keyed by service, in the
+// Compose commands that follow. The references are written to ImagePinsFile.
+func (p *ComposeProject) PinImages(pins map[string]string) error {
+	path := filepath.Join(p.WorkingDir, ImagePinsFile)
+	if len(pins) == 0 {
+		p.ImagePins = nil
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove image pins file: %w", err)
+		}
+		return nil
+	}
+
+	type pinnedService struct {
+		Image string `yaml:"image"`
+	}
+	content := struct {
+		Services map[string]pinnedService `yaml:"services"`
+	}{Services: make(map[string]pinnedService, len(pins))}
+	for service, image := range pins {
+		content.Services[service] = pinnedService{Image: image}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Image digests pinned by Oar for the deployment, do not edit\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(content); err != nil {
+		return fmt.Errorf("failed to encode image pins: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode image pins: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write image pins file: %w", err)
+	}
+	p.ImagePins = pins
+	return nil
+}
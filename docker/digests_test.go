@@ -0,0 +1,54 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestPinImages(t *testing.T) {
+	override := "services: {}"
+	project := &docker.ComposeProject{
+		Name:            "my-app",
+		WorkingDir:      t.TempDir(),
+		ComposeFiles:    []string{"compose.yaml"},
+		ComposeOverride: &override,
+	}
+	pinned := docker.PinnedImage("nginx:1.27", "sha256:0123")
+	assert.Equal(t, "nginx:1.27@sha256:0123", pinned)
+	assert.True(t, docker.IsPinnedImage(pinned))
+	assert.False(t, docker.IsPinnedImage("nginx:1.27"))
+	image, digest, ok := docker.SplitPinnedImage(pinned)
+	assert.True(t, ok)
+	assert.Equal(t, "nginx:1.27", image)
+	assert.Equal(t, "sha256:0123", digest)
+
+	require.NoError(t, project.PinImages(map[string]string{"web": pinned}))
+	assert.Equal(t, []string{"compose.yaml", docker.OverrideFile, docker.ImagePinsFile}, project.ConfigFiles(),
+		"The pins should merge after the override")
+
+	content, err := os.ReadFile(filepath.Join(project.WorkingDir, docker.ImagePinsFile))
+	require.NoError(t, err)
+	var pins struct {
+		Services map[string]struct {
+			Image string `yaml:"image"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &pins))
+	require.Len(t, pins.Services, 1)
+	assert.Equal(t, pinned, pins.Services["web"].Image)
+
+	require.NoError(t, project.PinImages(nil))
+	assert.Equal(t, []string{"compose.yaml", docker.OverrideFile}, project.ConfigFiles())
+	assert.NoFileExists(t, filepath.Join(project.WorkingDir, docker.ImagePinsFile))
+
+	assert.ErrorContains(t,
+		docker.ValidateComposeFiles([]string{"compose.yaml", docker.ImagePinsFile}),
+		"reserved for pinned image digests")
+}
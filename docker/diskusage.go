@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+)
+
+// VolumeSize is the disk space taken by a volume
+type VolumeSize struct {
+	Name string
+	Size int64 // -1 when Docker cannot tell, e.g. for volumes of other drivers
+}
+
+// ImageSize is the disk space taken by an image
+type ImageSize struct {
+	Image string
+	Size  int64
+}
+
+// VolumeSizes returns the volumes Compose created for the project with their sizes, sorted by name.
+// Docker measures the volumes by walking their contents, which takes a while for large volumes.
+func (p *ComposeProject) VolumeSizes() ([]VolumeSize, error) {
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = dockerClient.Close() }()
+
+	return dockerClient.VolumeSizes(p.Name)
+}
+
+// ImageSizes returns the sizes of the images that are present locally, in the order given. Images
+// that are not present, e.g. not pulled yet, are left out.
+func ImageSizes(images []string) ([]ImageSize, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = dockerClient.Close() }()
+
+	var sizes []ImageSize
+	for _, image := range images {
+		size, found, err := dockerClient.ImageSize(image)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			sizes = append(sizes, ImageSize{Image: image, Size: size})
+		}
+	}
+	return sizes, nil
+}
+
+// VolumeSizes returns the volumes labelled as belonging to the Compose project with their sizes,
+// sorted by name
+func (dc *DockerClient) VolumeSizes(projectName string) ([]VolumeSize, error) {
+	usage, err := dc.cli.DiskUsage(dc.ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volume disk usage: %w", err)
+	}
+
+	var sizes []VolumeSize
+	for _, volume := range usage.Volumes {
+		if volume == nil || volume.Labels[composeProjectLabel] != projectName {
+			continue
+		}
+		size := int64(-1)
+		if volume.UsageData != nil {
+			size = volume.UsageData.Size
+		}
+		sizes = append(sizes, VolumeSize{Name: volume.Name, Size: size})
+	}
+	slices.SortFunc(sizes, func(a, b VolumeSize) int { return strings.Compare(a.Name, b.Name) })
+	return sizes, nil
+}
+
+// ImageSize returns the size of a local image, and false when the image is not present
+func (dc *DockerClient) ImageSize(imageName string) (int64, bool, error) {
+	imageInspect, err := dc.cli.ImageInspect(dc.ctx, imageName)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return imageInspect.Size, true, nil
+}
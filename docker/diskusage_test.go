@@ -0,0 +1,52 @@
+package docker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestDiskUsageSizes(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/system/df"):
+			assert.Equal(t, "volume", r.URL.Query().Get("type"))
+			_, _ = w.Write([]byte(`{"Volumes":[
+				{"Name":"app_uploads","Labels":{"com.docker.compose.project":"app"}},
+				{"Name":"app_db","Labels":{"com.docker.compose.project":"app"},"UsageData":{"Size":2048,"RefCount":1}},
+				{"Name":"app2_db","Labels":{"com.docker.compose.project":"app2"},"UsageData":{"Size":1,"RefCount":0}},
+				{"Name":"orphan","Labels":{},"UsageData":{"Size":1,"RefCount":0}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/images/nginx:1.27/json"):
+			_, _ = w.Write([]byte(`{"Id":"sha256:abc","Size":190000000}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"not found"}`))
+		}
+	}))
+	t.Cleanup(api.Close)
+	t.Setenv("DOCKER_HOST", "tcp://"+api.Listener.Addr().String())
+
+	t.Run("volumes of the project", func(t *testing.T) {
+		sizes, err := (&docker.ComposeProject{Name: "app"}).VolumeSizes()
+		require.NoError(t, err)
+		assert.Equal(t, []docker.VolumeSize{
+			{Name: "app_db", Size: 2048},
+			{Name: "app_uploads", Size: -1},
+		}, sizes)
+	})
+
+	t.Run("images present locally", func(t *testing.T) {
+		sizes, err := docker.ImageSizes([]string{"nginx:1.27", "app-worker"})
+		require.NoError(t, err)
+		assert.Equal(t, []docker.ImageSize{{Image: "nginx:1.27", Size: 190000000}}, sizes)
+	})
+}
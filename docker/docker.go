@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"strings"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
@@ -25,7 +26,8 @@ type DockerClient struct {
 
 // NewDockerClient creates a new Docker client
 func NewDockerClient() (*DockerClient, error) {
-	cli, err := client.NewClientWithOpts(client.WithAPIVersionNegotiation())
+	// Connect to the same daemon as the docker CLI
+	cli, err := client.NewClientWithOpts(clientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Docker client: %w", err)
 	}
@@ -75,12 +77,50 @@ func (dc *DockerClient) PullImage(imageName string) error {
 	return nil
 }
 
+// ImageExists reports whether an image is present locally, without pulling it
+func (dc *DockerClient) ImageExists(imageName string) (bool, error) {
+	_, err := dc.cli.ImageInspect(dc.ctx, imageName)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect image %s: %w", imageName, err)
+	}
+	return true, nil
+}
+
+// VolumeExists reports whether a volume exists
+func (dc *DockerClient) VolumeExists(volumeName string) (bool, error) {
+	_, err := dc.cli.VolumeInspect(dc.ctx, volumeName)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to inspect volume %s: %w", volumeName, err)
+	}
+	return true, nil
+}
+
+// TagImage adds the reference target to the local image source
+func (dc *DockerClient) TagImage(source, target string) error {
+	if err := dc.cli.ImageTag(dc.ctx, source, target); err != nil {
+		return fmt.Errorf("failed to tag image %s as %s: %w", source, target, err)
+	}
+	return nil
+}
+
 // RunVolumeChowningContainer creates and runs a helper container for permission fixing
 func (dc *DockerClient) RunVolumeChowningContainer(containerName, command string, mounts []mount.Mount) error {
-	// First, ensure busybox image is available
-	err := dc.PullImage(BusyboxImage)
+	// First, ensure busybox image is available. A local image is used as is, so that volume
+	// initialization also works without registry access.
+	exists, err := dc.ImageExists(BusyboxImage)
 	if err != nil {
-		return fmt.Errorf("failed to pull busybox image: %w", err)
+		return err
+	}
+	if !exists {
+		if err := dc.PullImage(BusyboxImage); err != nil {
+			return fmt.Errorf("failed to pull busybox image: %w", err)
+		}
 	}
 	// Create helper container
 	resp, err := dc.cli.ContainerCreate(dc.ctx, &container.Config{
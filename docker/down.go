@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// DefaultDownTimeout bounds stopping a project when the config does not set a timeout
+const DefaultDownTimeout = 2 * time.Minute
+
+// downTimeout returns how long docker compose down may take. A stop timeout given for this stop
+// extends it, so that containers always get their full time to exit before they are force removed.
+func (p *ComposeProject) downTimeout(opts DownOptions) time.Duration {
+	timeout := DefaultDownTimeout
+	if p.Config != nil && p.Config.ComposeDownTimeout > 0 {
+		timeout = p.Config.ComposeDownTimeout
+	}
+	return timeout + opts.Timeout
+}
+
+// down stops and removes the containers with docker compose down, which run executes. A wedged
+// docker compose down would block stopping and removing the project forever, so when it does not
+// finish in time the containers are force removed through the Docker API, and down runs once more
+// to remove the networks and volumes. report tells the user about the escalation.
+func (p *ComposeProject) down(opts DownOptions, run func(cmd *exec.Cmd) error, report func(message string)) error {
+	timeout := p.downTimeout(opts)
+	timedOut, err := p.runDown(timeout, opts, run)
+	if !timedOut {
+		return err
+	}
+
+	slog.Warn("Docker Compose down timed out, force removing containers",
+		"project_name", p.Name,
+		"timeout", timeout)
+	report(fmt.Sprintf("docker compose down did not finish within %s, force removing the containers...", timeout))
+
+	removed, err := p.forceRemoveContainers(opts.RemoveVolumes)
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "docker_compose",
+			"operation", "force_remove_containers",
+			"project_name", p.Name,
+			"error", err)
+		return fmt.Errorf("docker compose down timed out after %s and force removing the containers failed: %w",
+			timeout, err)
+	}
+	if len(removed) > 0 {
+		report("Force removed containers: " + strings.Join(removed, ", "))
+	} else {
+		report("No containers were left to force remove")
+	}
+
+	// Only networks, volumes and images are left, so the signal and stop timeout no longer matter
+	cleanup := DownOptions{RemoveVolumes: opts.RemoveVolumes, RemoveImages: opts.RemoveImages}
+	if timedOut, err := p.runDown(p.downTimeout(cleanup), cleanup, run); err != nil {
+		if timedOut {
+			return fmt.Errorf("docker compose down timed out again after the containers were force removed")
+		}
+		return fmt.Errorf("failed to remove networks, volumes and images after force removing the containers: %w", err)
+	}
+	return nil
+}
+
+// runDown runs docker compose down, stopping the containers with the signal of opts first when one is
+// set. It reports whether the commands were killed because they did not finish within timeout.
+func (p *ComposeProject) runDown(timeout time.Duration, opts DownOptions, run func(cmd *exec.Cmd) error) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if opts.Signal != "" {
+		// docker compose down cannot send another signal, so the containers are stopped first
+		if err := p.stopWithSignal(ctx, opts.Signal, opts.Timeout); err != nil {
+			return ctx.Err() != nil, err
+		}
+	}
+
+	err := run(p.commandDown(ctx, opts))
+	return err != nil && ctx.Err() != nil, err
+}
+
+// forceRemoveContainers removes the containers of the project through the Docker API, killing those
+// still running, and returns their names
+func (p *ComposeProject) forceRemoveContainers(removeVolumes bool) ([]string, error) {
+	dc, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := dc.Close(); err != nil {
+			slog.Debug("Failed to close Docker client", "error", err)
+		}
+	}()
+
+	// The daemon may be what is stuck, so the API calls are bounded as well
+	ctx, cancel := context.WithTimeout(context.Background(), p.downTimeout(DownOptions{}))
+	defer cancel()
+	dc.ctx = ctx
+
+	return dc.RemoveProjectContainers(p.Name, removeVolumes)
+}
+
+// RemoveProjectContainers force removes all containers of a Compose project, running or not, and
+// returns their names. Anonymous volumes are removed with the containers when removeVolumes is set.
+func (dc *DockerClient) RemoveProjectContainers(projectName string, removeVolumes bool) ([]string, error) {
+	containers, err := dc.cli.ContainerList(dc.ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", composeProjectLabel+"="+projectName)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers of project %s: %w", projectName, err)
+	}
+
+	var removed []string
+	for _, c := range containers {
+		name := c.ID
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		err := dc.cli.ContainerRemove(dc.ctx, c.ID, container.RemoveOptions{Force: true, RemoveVolumes: removeVolumes})
+		if err != nil && !cerrdefs.IsNotFound(err) {
+			return removed, fmt.Errorf("failed to remove container %s: %w", name, err)
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
@@ -0,0 +1,132 @@
+package docker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+)
+
+// installWedgedDownDocker puts a docker stub on PATH whose first compose down ignores SIGTERM and
+// never finishes. Later calls succeed. Each call's arguments are appended to the returned file.
+func installWedgedDownDocker(t *testing.T) string {
+	t.Helper()
+
+	stateDir := t.TempDir()
+	callsFile := filepath.Join(stateDir, "calls")
+	marker := filepath.Join(stateDir, "wedged")
+	script := `#!/bin/sh
+echo "$*" >> ` + callsFile + `
+for arg in "$@"; do
+	if [ "$arg" = down ] && [ ! -e ` + marker + ` ]; then
+		touch ` + marker + `
+		trap '' TERM
+		sleep 60
+	fi
+done
+exit 0
+`
+	installStubDocker(t, script)
+	return callsFile
+}
+
+// fakeDockerAPI serves the container endpoints used to force remove containers
+type fakeDockerAPI struct {
+	mu       sync.Mutex
+	filters  string
+	removed  []string
+	failList bool
+}
+
+func (f *fakeDockerAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Api-Version", "1.47")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/_ping"):
+		_, _ = w.Write([]byte("OK"))
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/containers/json"):
+		if f.failList {
+			http.Error(w, `{"message":"daemon is busy"}`, http.StatusInternalServerError)
+			return
+		}
+		f.filters = r.URL.Query().Get("filters")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"Id":"c1","Names":["/wedged-db-1"]},{"Id":"c2","Names":["/wedged-web-1"]}]`))
+	case r.Method == http.MethodDelete && strings.Contains(r.URL.Path, "/containers/"):
+		if r.URL.Query().Get("force") != "1" {
+			http.Error(w, `{"message":"container is running"}`, http.StatusConflict)
+			return
+		}
+		f.removed = append(f.removed, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDownForceRemovesWhenWedged(t *testing.T) {
+	newProject := func(t *testing.T) *docker.ComposeProject {
+		return &docker.ComposeProject{
+			Name:         "wedged",
+			WorkingDir:   t.TempDir(),
+			ComposeFiles: []string{"compose.yaml"},
+			Config:       &config.Config{ComposeDownTimeout: 300 * time.Millisecond},
+		}
+	}
+	startAPI := func(t *testing.T, api *fakeDockerAPI) {
+		server := httptest.NewServer(api)
+		t.Cleanup(server.Close)
+		t.Setenv("DOCKER_HOST", "tcp://"+server.Listener.Addr().String())
+	}
+
+	t.Run("escalates to force remove", func(t *testing.T) {
+		callsFile := installWedgedDownDocker(t)
+		api := &fakeDockerAPI{}
+		startAPI(t, api)
+
+		output := make(chan docker.StreamMessage, 100)
+		start := time.Now()
+		require.NoError(t, newProject(t).DownStreaming(docker.DownOptions{}, output))
+		assert.Less(t, time.Since(start), 10*time.Second, "down must not wait for the wedged command")
+		close(output)
+
+		var messages []string
+		for msg := range output {
+			messages = append(messages, msg.Content)
+		}
+		assert.Contains(t, messages,
+			"docker compose down did not finish within 300ms, force removing the containers...")
+		assert.Contains(t, messages, "Force removed containers: wedged-db-1, wedged-web-1")
+
+		assert.Equal(t, []string{"c1", "c2"}, api.removed)
+		assert.Contains(t, api.filters, "com.docker.compose.project=wedged")
+
+		// down runs again to remove the networks once the containers are gone
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(calls), " down --remove-orphans"))
+	})
+
+	t.Run("force remove fails", func(t *testing.T) {
+		installWedgedDownDocker(t)
+		startAPI(t, &fakeDockerAPI{failList: true})
+
+		_, _, err := newProject(t).Down(docker.DownOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"docker compose down timed out after 300ms and force removing the containers failed")
+		assert.Contains(t, err.Error(), "daemon is busy")
+	})
+}
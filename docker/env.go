@@ -0,0 +1,378 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/redact"
+)
+
+const (
+	// GeneratedEnvFile is the env file Oar writes with the resolved project variables.
+	// The name is prefixed to avoid colliding with any env file in the repository.
+	GeneratedEnvFile = ".oar.env"
+	// repoEnvFile is the env file Docker Compose loads from the project directory by default
+	repoEnvFile = ".env"
+	// EnvSourceVariables marks values coming from the project's inline variables
+	EnvSourceVariables = "variables"
+	// EnvSourceOar marks the deploy variables Oar sets itself
+	EnvSourceOar = "oar"
+)
+
+// Deploy variables Oar sets for every Compose command of a project
+const (
+	// EnvCommit is the commit checked out in the project's repository
+	EnvCommit = "OAR_COMMIT"
+	// EnvProject is the name of the project
+	EnvProject = "OAR_PROJECT"
+	// EnvDeployID is the ID of the deployment being run, or of the project's latest deployment
+	EnvDeployID = "OAR_DEPLOY_ID"
+	// EnvEnvironment is the deploy environment configured for the server
+	EnvEnvironment = "OAR_ENV"
+)
+
+// ReservedVariables are the names of the deploy variables, which inline variables cannot set
+var ReservedVariables = []string{EnvCommit, EnvProject, EnvDeployID, EnvEnvironment}
+
+// ValidateVariables rejects inline variables that set one of the reserved deploy variables
+func ValidateVariables(variables []string) error {
+	for _, variable := range variables {
+		key, _, _ := strings.Cut(variable, "=")
+		if key = strings.TrimSpace(key); slices.Contains(ReservedVariables, key) {
+			return fmt.Errorf("variable %s is set by Oar and cannot be overridden", key)
+		}
+	}
+	return nil
+}
+
+// DeployVariables returns the deploy variables in KEY=value format
+func DeployVariables(commit, projectName, deploymentID, environment string) []string {
+	return []string{
+		EnvCommit + "=" + commit,
+		EnvProject + "=" + projectName,
+		EnvDeployID + "=" + deploymentID,
+		EnvEnvironment + "=" + environment,
+	}
+}
+
+// EnvVar is a resolved environment variable together with the source that set its final value
+type EnvVar struct {
+	Key    string
+	Value  string
+	Source string // env file path relative to the repository root, or EnvSourceVariables
+	Masked bool   // Value was replaced because the variable looks like a secret
+
+	// literal values must not be interpolated by Compose (single-quoted or inline variables)
+	literal bool
+}
+
+// ResolveEnv merges the environment used for compose file interpolation. Sources are applied
+// in increasing order of precedence, later sources overriding earlier ones:
+//
+//  1. the .env file in the compose project directory (the directory of the first compose file),
+//     if it exists and is not listed in envFiles
+//  2. envFiles, in the order they are listed (paths relative to the repository root)
+//  3. inline variables
+//  4. deploy variables, which always win
+//
+// Listing the repository .env in envFiles places it at that position instead of first. Env files are
+// read through an os.Root, so neither a path nor a symlink can reach files outside the repository.
+func ResolveEnv(repoDir string, composeFiles, envFiles, variables, deployVariables []string) ([]EnvVar, error) {
+	if err := ValidateEnvFiles(envFiles); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	defer func() { _ = root.Close() }()
+
+	var sources []string
+
+	projectEnvFile := repoEnvFile
+	if len(composeFiles) > 0 {
+		projectEnvFile = filepath.Join(filepath.Dir(composeFiles[0]), repoEnvFile)
+	}
+	if !containsPath(envFiles, projectEnvFile) {
+		if _, err := root.Stat(projectEnvFile); err == nil {
+			sources = append(sources, projectEnvFile)
+		}
+	}
+	sources = append(sources, envFiles...)
+
+	resolved := newEnvSet()
+	for _, source := range sources {
+		content, err := root.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file %s: %w", source, err)
+		}
+		vars, err := ParseEnvFile(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse env file %s: %w", source, err)
+		}
+		for _, v := range vars {
+			resolved.set(v.Key, v.Value, source, v.literal)
+		}
+	}
+
+	for _, variable := range variables {
+		key, value, ok := strings.Cut(variable, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		resolved.set(key, value, EnvSourceVariables, true)
+	}
+
+	for _, variable := range deployVariables {
+		key, value, _ := strings.Cut(variable, "=")
+		if i, ok := resolved.index[key]; ok {
+			slog.Warn("Deploy variable overrides a project variable",
+				"variable", key,
+				"source", resolved.vars[i].Source)
+		}
+		resolved.set(key, value, EnvSourceOar, true)
+	}
+
+	return resolved.vars, nil
+}
+
+// ValidateEnvFiles checks that env files are paths inside the repository
+func ValidateEnvFiles(files []string) error {
+	for _, file := range files {
+		if !filepath.IsLocal(file) {
+			return fmt.Errorf("env file %s must be a relative path inside the repository", file)
+		}
+	}
+	return nil
+}
+
+// ParseEnvFile parses env file content using the Docker Compose syntax: KEY=VALUE lines,
+// optional "export " prefix, # comments, and single- or double-quoted values.
+// Lines without "=" take their value from the environment in Compose and are skipped here.
+func ParseEnvFile(content string) ([]EnvVar, error) {
+	var vars []EnvVar
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNumber, key)
+		}
+
+		rawValue = strings.TrimSpace(rawValue)
+		value, err := parseEnvValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		vars = append(vars, EnvVar{Key: key, Value: value, literal: strings.HasPrefix(rawValue, "'")})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// parseEnvValue unquotes a value from an env file line
+func parseEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	default:
+		// Unquoted values end at an inline comment
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+// FormatEnvFile renders resolved variables as env file content. Literal values are quoted so that
+// Compose does not interpolate them, other values keep their ${VAR} references.
+func FormatEnvFile(vars []EnvVar) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	literalEscaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", "$$")
+
+	var b strings.Builder
+	b.WriteString("# Generated by Oar from the project's env files and variables. Do not edit.\n")
+	for _, v := range vars {
+		var value string
+		switch {
+		case v.literal && !strings.ContainsAny(v.Value, "'\n"):
+			value = "'" + v.Value + "'"
+		case v.literal:
+			value = `"` + literalEscaper.Replace(v.Value) + `"`
+		default:
+			value = `"` + escaper.Replace(v.Value) + `"`
+		}
+		fmt.Fprintf(&b, "# source: %s\n%s=%s\n", v.Source, v.Key, value)
+	}
+	return b.String()
+}
+
+// writeGeneratedEnvFile resolves the project environment and writes it to the generated env file
+func writeGeneratedEnvFile(
+	repoDir string,
+	composeFiles, envFiles, variables, deployVariables []string,
+) (string, error) {
+	vars, err := ResolveEnv(repoDir, composeFiles, envFiles, variables, deployVariables)
+	if err != nil {
+		return "", err
+	}
+
+	envFilePath := filepath.Join(repoDir, GeneratedEnvFile)
+	if err := os.WriteFile(envFilePath, []byte(FormatEnvFile(vars)), 0600); err != nil {
+		return "", fmt.Errorf("failed to write generated env file: %w", err)
+	}
+
+	slog.Debug("Wrote generated env file",
+		"env_file_path", envFilePath,
+		"var_count", len(vars))
+
+	return envFilePath, nil
+}
+
+// secretKeyParts are name segments that mark a variable as holding a secret
+var secretKeyParts = map[string]bool{
+	"PASSWORD":    true,
+	"PASSWD":      true,
+	"PASS":        true,
+	"PWD":         true,
+	"SECRET":      true,
+	"TOKEN":       true,
+	"KEY":         true,
+	"APIKEY":      true,
+	"PRIVATE":     true,
+	"CREDENTIAL":  true,
+	"CREDENTIALS": true,
+	"AUTH":        true,
+	"DSN":         true,
+}
+
+// maskedValue replaces the value of secret-looking variables
+const maskedValue = "********"
+
+// IsSecret reports whether the variable looks like it holds a secret, based on its name or value
+func (v EnvVar) IsSecret() bool {
+	parts := strings.FieldsFunc(strings.ToUpper(v.Key), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	for _, part := range parts {
+		if secretKeyParts[part] {
+			return true
+		}
+	}
+	return strings.Contains(v.Value, "PRIVATE KEY-----")
+}
+
+// MaskSecrets returns a copy of vars with the values of secret-looking variables replaced
+func MaskSecrets(vars []EnvVar) []EnvVar {
+	masked := make([]EnvVar, len(vars))
+	for i, v := range vars {
+		if v.IsSecret() && v.Value != "" {
+			v.Value = maskedValue
+			v.Masked = true
+		}
+		masked[i] = v
+	}
+	return masked
+}
+
+// NewSecretRedactor returns a replacer that masks the values of the secret-looking variables
+// among vars wherever they appear, such as in the logs of a service that prints its configuration
+func NewSecretRedactor(vars []EnvVar) *strings.Replacer {
+	var secrets []string
+	for _, v := range vars {
+		if v.IsSecret() && !v.Masked {
+			secrets = append(secrets, v.Value)
+		}
+	}
+	return newRedactor(secrets)
+}
+
+// newRedactor returns a replacer that masks the given secret values wherever they appear
+func newRedactor(secrets []string) *strings.Replacer {
+	replacements := make(map[string]string, len(secrets))
+	for _, secret := range secrets {
+		replacements[secret] = maskedValue
+	}
+	return redact.NewReplacer(replacements)
+}
+
+func containsPath(paths []string, target string) bool {
+	for _, p := range paths {
+		if filepath.Clean(p) == filepath.Clean(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// envSet keeps variables in first-seen order while letting later sources override values
+type envSet struct {
+	vars  []EnvVar
+	index map[string]int
+}
+
+func newEnvSet() *envSet {
+	return &envSet{index: make(map[string]int)}
+}
+
+func (s *envSet) set(key, value, source string, literal bool) {
+	if i, ok := s.index[key]; ok {
+		s.vars[i].Value = value
+		s.vars[i].Source = source
+		s.vars[i].literal = literal
+		return
+	}
+	s.index[key] = len(s.vars)
+	s.vars = append(s.vars, EnvVar{Key: key, Value: value, Source: source, literal: literal})
+}
@@ -0,0 +1,209 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func envMap(vars []docker.EnvVar) map[string]docker.EnvVar {
+	result := make(map[string]docker.EnvVar, len(vars))
+	for _, v := range vars {
+		result[v.Key] = v
+	}
+	return result
+}
+
+func TestResolveEnvPrecedence(t *testing.T) {
+	repoDir := t.TempDir()
+	writeFile(t, repoDir, ".env", "SHARED=repo\nREPO_ONLY=repo\n")
+	writeFile(t, repoDir, "env/prod.env", "SHARED=file\nFILE_ONLY=file\nOAR_ENV=file\n")
+
+	vars, err := docker.ResolveEnv(
+		repoDir,
+		[]string{"compose.yaml"},
+		[]string{"env/prod.env"},
+		[]string{"SHARED=inline"},
+		docker.DeployVariables("abc123", "shop", "d-1", "production"),
+	)
+	require.NoError(t, err)
+
+	resolved := envMap(vars)
+	assert.Equal(t, "inline", resolved["SHARED"].Value, "Inline variables override env files and repo .env")
+	assert.Equal(t, docker.EnvSourceVariables, resolved["SHARED"].Source)
+	assert.Equal(t, "repo", resolved["REPO_ONLY"].Value)
+	assert.Equal(t, ".env", resolved["REPO_ONLY"].Source)
+	assert.Equal(t, "file", resolved["FILE_ONLY"].Value)
+	assert.Equal(t, "env/prod.env", resolved["FILE_ONLY"].Source)
+	assert.Equal(t, "production", resolved["OAR_ENV"].Value, "Deploy variables override every source")
+	assert.Equal(t, docker.EnvSourceOar, resolved["OAR_ENV"].Source)
+	assert.Equal(t, "abc123", resolved["OAR_COMMIT"].Value)
+}
+
+func TestResolveEnvFilesOverrideRepoEnv(t *testing.T) {
+	repoDir := t.TempDir()
+	writeFile(t, repoDir, ".env", "SHARED=repo\n")
+	writeFile(t, repoDir, "prod.env", "SHARED=file\n")
+
+	vars, err := docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{"prod.env"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "file", envMap(vars)["SHARED"].Value)
+
+	// Listing the repository .env explicitly moves it to that position
+	vars, err = docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{"prod.env", ".env"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "repo", envMap(vars)["SHARED"].Value)
+}
+
+func TestResolveEnvUsesComposeProjectDirectory(t *testing.T) {
+	repoDir := t.TempDir()
+	writeFile(t, repoDir, ".env", "LOCATION=root\n")
+	writeFile(t, repoDir, "deploy/.env", "LOCATION=deploy\n")
+
+	vars, err := docker.ResolveEnv(repoDir, []string{"deploy/compose.yaml"}, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", envMap(vars)["LOCATION"].Value)
+}
+
+func TestResolveEnvMissingEnvFile(t *testing.T) {
+	repoDir := t.TempDir()
+
+	// A missing repository .env is not an error, a missing configured env file is
+	_, err := docker.ResolveEnv(repoDir, []string{"compose.yaml"}, nil, []string{"A=1"}, nil)
+	require.NoError(t, err)
+
+	_, err = docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{"missing.env"}, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestResolveEnvStaysInsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	repoDir := filepath.Join(dir, "repo")
+	writeFile(t, dir, "secret.env", "SECRET=outside\n")
+	writeFile(t, repoDir, "compose.yaml", "services: {}\n")
+
+	// Paths leaving the repository are rejected before anything is read
+	for _, file := range []string{"../secret.env", filepath.Join(dir, "secret.env"), "config/../../secret.env"} {
+		_, err := docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{file}, nil, nil)
+		require.Error(t, err, file)
+		assert.Contains(t, err.Error(), "inside the repository")
+	}
+
+	// A symlink in the repository cannot point out of it either
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.env"), filepath.Join(repoDir, "linked.env")))
+	_, err := docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{"linked.env"}, nil, nil)
+	require.Error(t, err)
+
+	// Nor can the .env file picked up next to the compose files
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.env"), filepath.Join(repoDir, ".env")))
+	vars, err := docker.ResolveEnv(repoDir, []string{"compose.yaml"}, nil, nil, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, envMap(vars), "SECRET")
+
+	// Symlinks within the repository still work
+	writeFile(t, repoDir, "config/prod.env", "MODE=prod\n")
+	require.NoError(t, os.Symlink("config/prod.env", filepath.Join(repoDir, "prod.env")))
+	vars, err = docker.ResolveEnv(repoDir, []string{"compose.yaml"}, []string{"prod.env"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", envMap(vars)["MODE"].Value)
+}
+
+func TestValidateEnvFiles(t *testing.T) {
+	assert.NoError(t, docker.ValidateEnvFiles([]string{".env", "config/prod.env"}))
+	assert.Error(t, docker.ValidateEnvFiles([]string{"../x"}))
+	assert.Error(t, docker.ValidateEnvFiles([]string{"/etc/passwd"}))
+	assert.Error(t, docker.ValidateEnvFiles([]string{""}))
+}
+
+func TestParseEnvFile(t *testing.T) {
+	content := `# comment
+export EXPORTED=yes
+PLAIN=value # trailing comment
+SINGLE='literal ${NOT_EXPANDED}'
+DOUBLE="line1\nline2 \"quoted\""
+EMPTY=
+FROM_ENVIRONMENT
+`
+	vars, err := docker.ParseEnvFile(content)
+	require.NoError(t, err)
+
+	resolved := envMap(vars)
+	assert.Len(t, vars, 5)
+	assert.Equal(t, "yes", resolved["EXPORTED"].Value)
+	assert.Equal(t, "value", resolved["PLAIN"].Value)
+	assert.Equal(t, "literal ${NOT_EXPANDED}", resolved["SINGLE"].Value)
+	assert.Equal(t, "line1\nline2 \"quoted\"", resolved["DOUBLE"].Value)
+	assert.Equal(t, "", resolved["EMPTY"].Value)
+
+	_, err = docker.ParseEnvFile(`BROKEN="unterminated`)
+	assert.Error(t, err)
+}
+
+func TestFormatEnvFileRoundTrip(t *testing.T) {
+	repoDir := t.TempDir()
+	writeFile(t, repoDir, ".env", "URL=\"http://${HOST}\"\nMULTI=\"a\\nb\"\n")
+
+	vars, err := docker.ResolveEnv(repoDir, nil, nil, []string{"PASSWORD=p@ss'$word"}, nil)
+	require.NoError(t, err)
+
+	formatted := docker.FormatEnvFile(vars)
+	assert.Contains(t, formatted, `URL="http://${HOST}"`, "Interpolated values keep their references")
+	assert.Contains(t, formatted, `PASSWORD="p@ss'$$word"`, "Inline variables are not interpolated")
+
+	parsed, err := docker.ParseEnvFile(formatted)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb", envMap(parsed)["MULTI"].Value)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	vars := []docker.EnvVar{
+		{Key: "DB_PASSWORD", Value: "hunter2", Source: ".env"},
+		{Key: "API_KEY", Value: "abc123", Source: docker.EnvSourceVariables},
+		{Key: "GITHUB_TOKEN", Value: "ghp_xxx", Source: docker.EnvSourceVariables},
+		{Key: "KEYCLOAK_URL", Value: "https://auth.example.com", Source: ".env"},
+		{Key: "PORT", Value: "8080", Source: ".env"},
+		{Key: "EMPTY_SECRET", Value: "", Source: ".env"},
+	}
+
+	masked := envMap(docker.MaskSecrets(vars))
+
+	assert.True(t, masked["DB_PASSWORD"].Masked)
+	assert.NotEqual(t, "hunter2", masked["DB_PASSWORD"].Value)
+	assert.True(t, masked["API_KEY"].Masked)
+	assert.True(t, masked["GITHUB_TOKEN"].Masked)
+	assert.False(t, masked["KEYCLOAK_URL"].Masked, "Only whole name segments mark secrets")
+	assert.Equal(t, "8080", masked["PORT"].Value)
+	assert.False(t, masked["EMPTY_SECRET"].Masked, "Empty values have nothing to hide")
+
+	// The input is left untouched
+	assert.Equal(t, "hunter2", vars[0].Value)
+}
+
+func TestNewSecretRedactor(t *testing.T) {
+	redactor := docker.NewSecretRedactor([]docker.EnvVar{
+		{Key: "DB_PASSWORD", Value: "hunter2"},
+		{Key: "ADMIN_PASSWORD", Value: "hunter2-admin"},
+		{Key: "API_KEY", Value: "abc"},
+		{Key: "PORT", Value: "8080"},
+	})
+
+	assert.Equal(t,
+		"db: connecting with ******** as ******** on port 8080, api key abc",
+		redactor.Replace("db: connecting with hunter2 as hunter2-admin on port 8080, api key abc"),
+		"Longer secrets are masked whole, short ones and values of other variables are left alone")
+
+	// Without secrets the text is unchanged
+	assert.Equal(t, "hunter2", docker.NewSecretRedactor(nil).Replace("hunter2"))
+}
@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// failureLogLines is how many of the last log lines are kept for each failed service
+const failureLogLines = 5
+
+// FailedServices returns the services that have no container or, once up started them, whose
+// container is not running, in the order of services. A container that exited with code 0 is not
+// a failure, since one-off tasks such as migrations exit once they are done.
+func FailedServices(services []string, containers []ContainerInfo, started bool) []domain.ServiceFailure {
+	byService := make(map[string][]ContainerInfo)
+	for _, container := range containers {
+		byService[container.Service] = append(byService[container.Service], container)
+	}
+
+	failures := []domain.ServiceFailure{}
+	for _, service := range services {
+		serviceContainers := byService[service]
+		if len(serviceContainers) == 0 {
+			failures = append(failures, domain.ServiceFailure{Service: service})
+			continue
+		}
+		if !started {
+			continue
+		}
+		for _, container := range serviceContainers {
+			if container.State == "running" || (container.State == "exited" && container.ExitCode == 0) {
+				continue
+			}
+			failures = append(failures, domain.ServiceFailure{
+				Service:  service,
+				State:    container.State,
+				ExitCode: container.ExitCode,
+			})
+			break
+		}
+	}
+	return failures
+}
+
+// DiagnoseFailure returns the services of a failed up that did not come up, with the last lines
+// each of them logged. Set started when up was starting services rather than only creating them.
+func (p *ComposeProject) DiagnoseFailure(started bool) ([]domain.ServiceFailure, error) {
+	services, err := p.upServices()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.queryTimeout())
+	defer cancel()
+	containers, err := p.listContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := FailedServices(services, containers, started)
+	for i, failure := range failures {
+		// A container that was never started has nothing logged
+		if failure.State == "" || failure.State == "created" {
+			continue
+		}
+		stdout, stderr, err := p.Logs(LogsOptions{
+			StripColor: true,
+			Tail:       failureLogLines,
+			Services:   []string{failure.Service},
+		})
+		if err != nil {
+			slog.Debug("Failed to read logs of failed service",
+				"project_name", p.Name,
+				"service", failure.Service,
+				"error", err)
+			continue
+		}
+		failures[i].LastLogs = lastLines(stdout+stderr, failureLogLines)
+	}
+	return failures, nil
+}
+
+// upServices returns the services up brings up: the selected services and the services they depend
+// on, or all services when none are selected
+func (p *ComposeProject) upServices() ([]string, error) {
+	config, _, err := p.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render compose config: %w", err)
+	}
+	if len(p.Services) > 0 {
+		return ResolveServices(config, p.Services)
+	}
+
+	var parsed composeServices
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+	services := make([]string, 0, len(parsed.Services))
+	for name := range parsed.Services {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// lastLines returns up to n of the last non-empty lines of output
+func lastLines(output string, n int) []string {
+	lines := []string{}
+	for line := range strings.Lines(output) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
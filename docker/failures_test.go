@@ -0,0 +1,67 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestFailedServices(t *testing.T) {
+	containers := []docker.ContainerInfo{
+		{Service: "db", State: "exited", ExitCode: 1},
+		{Service: "migrate", State: "exited", ExitCode: 0},
+		{Service: "web", State: "created"},
+		{Service: "worker", State: "running"},
+		{Service: "worker", State: "restarting", ExitCode: 137},
+	}
+	services := []string{"cache", "db", "migrate", "web", "worker"}
+
+	tests := []struct {
+		name    string
+		started bool
+		want    []domain.ServiceFailure
+	}{
+		{
+			name:    "creating containers",
+			started: false,
+			want:    []domain.ServiceFailure{{Service: "cache"}},
+		},
+		{
+			name:    "starting services",
+			started: true,
+			want: []domain.ServiceFailure{
+				{Service: "cache"},
+				{Service: "db", State: "exited", ExitCode: 1},
+				{Service: "web", State: "created"},
+				{Service: "worker", State: "restarting", ExitCode: 137},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, docker.FailedServices(services, containers, tt.started))
+		})
+	}
+}
+
+func TestServiceFailureString(t *testing.T) {
+	tests := []struct {
+		failure domain.ServiceFailure
+		want    string
+	}{
+		{domain.ServiceFailure{Service: "db"}, "service 'db' failed: no container was created"},
+		{domain.ServiceFailure{Service: "db", State: "exited", ExitCode: 1}, "service 'db' failed: exited 1"},
+		{domain.ServiceFailure{Service: "web", State: "created"}, "service 'web' failed: not started"},
+		{domain.ServiceFailure{Service: "web", State: "restarting"}, "service 'web' failed: restarting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.failure.String())
+		})
+	}
+}
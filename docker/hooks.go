@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+// RunStreaming runs args in a new container of service with docker compose run --rm, streaming its
+// output, or the service's command when args is empty. Unlike exec it does not need the service to be
+// running. Compose starts the services it depends on first, and removes the container when it exits.
+// A non-zero exit is an error.
+func (p *ComposeProject) RunStreaming(service string, args []string, outputChan chan<- StreamMessage) error {
+	cmd := p.streaming().prepareCommand("run", append([]string{"--rm", "--no-TTY", service}, args...))
+	return p.executeCommandStreaming(cmd, outputChan)
+}
+
+// HasService reports whether the project declares service. Besides the services of the rendered
+// configuration, it finds services declared in the compose files under a profile that is not enabled,
+// which up leaves alone and run can still run, such as a migration service.
+func (p *ComposeProject) HasService(service string) (bool, error) {
+	services, err := p.ListServices()
+	if err != nil {
+		return false, err
+	}
+	if slices.Contains(services, service) {
+		return true, nil
+	}
+	for _, file := range p.ConfigFiles() {
+		declared, err := declaredEntries(filepath.Join(p.WorkingDir, file))
+		if err != nil {
+			return false, fmt.Errorf("failed to read services: %w", err)
+		}
+		if slices.Contains(declared["services"], service) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
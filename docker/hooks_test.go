@@ -0,0 +1,57 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestRunStreaming(t *testing.T) {
+	argsFile := installRecordingDocker(t)
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	output := make(chan docker.StreamMessage, 100)
+	require.NoError(t, project.RunStreaming("migrate", []string{"./manage.py", "migrate"}, output))
+	close(output)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(string(args)), "run --rm --no-TTY migrate ./manage.py migrate"),
+		"unexpected arguments: %s", args)
+}
+
+func TestHasService(t *testing.T) {
+	installConfigDocker(t)
+	workingDir := t.TempDir()
+	compose := `services:
+  web:
+    image: nginx:1.27
+  worker:
+    image: app-worker
+  migrate:
+    image: app-worker
+    profiles: [tools]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, "compose.yaml"), []byte(compose), 0o644))
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   workingDir,
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	for service, want := range map[string]bool{"web": true, "migrate": true, "db": false} {
+		exists, err := project.HasService(service)
+		require.NoError(t, err)
+		assert.Equal(t, want, exists, service)
+	}
+}
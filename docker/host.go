@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync/atomic"
+
+	"github.com/docker/docker/client"
+)
+
+// HostSettings select the Docker daemon Oar talks to and how the docker CLI is run
+type HostSettings struct {
+	// Host is the daemon address, such as unix:///run/user/1000/docker.sock. When empty, the docker
+	// CLI and the Docker SDK use DOCKER_HOST or their default socket.
+	Host string
+	// CommandPrefix is run in front of every docker command, for example sudo. Each item is a
+	// separate argument, nothing is interpreted by a shell.
+	CommandPrefix []string
+}
+
+var hostSettings atomic.Pointer[HostSettings]
+
+// Configure sets the daemon address and command prefix for all later docker commands and clients
+func Configure(settings HostSettings) {
+	settings.CommandPrefix = append([]string(nil), settings.CommandPrefix...)
+	hostSettings.Store(&settings)
+}
+
+func currentHostSettings() HostSettings {
+	if settings := hostSettings.Load(); settings != nil {
+		return *settings
+	}
+	return HostSettings{}
+}
+
+// dockerArgv returns the program and arguments that run the docker CLI with args, with the
+// command prefix in front and the configured host given as a global flag. The host is passed as
+// an argument rather than in the environment, because commands such as sudo reset the environment.
+func dockerArgv(args []string) (string, []string) {
+	settings := currentHostSettings()
+
+	argv := append([]string(nil), settings.CommandPrefix...)
+	argv = append(argv, "docker")
+	if settings.Host != "" {
+		argv = append(argv, "--host", settings.Host)
+	}
+	argv = append(argv, args...)
+	return argv[0], argv[1:]
+}
+
+// dockerCommand builds a docker CLI command that is killed when ctx is done
+func dockerCommand(ctx context.Context, args ...string) *exec.Cmd {
+	name, argv := dockerArgv(args)
+	return exec.CommandContext(ctx, name, argv...)
+}
+
+// dockerCommandLine returns the docker program of a shell command line, including the command
+// prefix and the host flag
+func dockerCommandLine() []string {
+	name, argv := dockerArgv(nil)
+	parts := []string{shellQuote(name)}
+	for _, arg := range argv {
+		parts = append(parts, shellQuote(arg))
+	}
+	return parts
+}
+
+// clientOptions returns the Docker SDK options that connect to the same daemon as the docker CLI
+func clientOptions() []client.Opt {
+	// Honor DOCKER_HOST like the docker CLI does, unless a host is configured
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host := currentHostSettings().Host; host != "" {
+		opts = append(opts, client.WithHost(host))
+	}
+	return opts
+}
+
+// CheckConnection verifies that both the docker CLI, run with the command prefix, and the Docker
+// SDK reach the daemon. It returns the version of the daemon.
+func CheckConnection(ctx context.Context) (string, error) {
+	version, err := dockerOutput(ctx, "version", "--format", "{{.Server.Version}}")
+	if err != nil {
+		return "", fmt.Errorf("failed to run docker: %w", err)
+	}
+
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = dockerClient.Close() }()
+	if _, err := dockerClient.cli.Ping(ctx); err != nil {
+		return "", fmt.Errorf("failed to reach the Docker API at %s: %w", dockerClient.cli.DaemonHost(), err)
+	}
+
+	return version, nil
+}
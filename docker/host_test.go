@@ -0,0 +1,144 @@
+package docker_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// configureHost applies settings for the test and restores the defaults afterwards
+func configureHost(t *testing.T, settings docker.HostSettings) {
+	t.Helper()
+	docker.Configure(settings)
+	t.Cleanup(func() { docker.Configure(docker.HostSettings{}) })
+}
+
+// serveDockerAPI serves handler as a Docker daemon on a unix socket and returns the socket path
+func serveDockerAPI(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	// Socket paths are limited to about 100 characters, test temp dirs can be longer
+	dir, err := os.MkdirTemp("", "oar-docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := &http.Server{Handler: handler}
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	return socket
+}
+
+// serveFakeDockerAPI serves the Docker API ping on a unix socket and returns the socket path and
+// the number of pings received
+func serveFakeDockerAPI(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var pings atomic.Int32
+	socket := serveDockerAPI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/_ping") {
+			http.NotFound(w, r)
+			return
+		}
+		pings.Add(1)
+		w.Header().Set("API-Version", "1.45")
+		_, _ = w.Write([]byte("OK"))
+	}))
+
+	return socket, &pings
+}
+
+// installPrefixCommand writes a command to use as a prefix. It records its arguments, one per line,
+// and answers like docker version.
+func installPrefixCommand(t *testing.T) (string, string) {
+	t.Helper()
+
+	command := filepath.Join(t.TempDir(), "as-docker-user")
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\necho 27.3.1\n"
+	require.NoError(t, os.WriteFile(command, []byte(script), 0o755))
+	return command, argsFile
+}
+
+func TestCheckConnectionWithConfiguredHost(t *testing.T) {
+	socket, pings := serveFakeDockerAPI(t)
+	prefix, argsFile := installPrefixCommand(t)
+	configureHost(t, docker.HostSettings{
+		Host:          "unix://" + socket,
+		CommandPrefix: []string{prefix, "--preserve-env"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	version, err := docker.CheckConnection(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "27.3.1", version)
+
+	// The Docker API client connected to the configured socket
+	assert.Positive(t, pings.Load())
+
+	// The docker CLI ran behind the prefix, with the host as a flag and each argument kept whole
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--preserve-env", "docker", "--host", "unix://" + socket, "version", "--format", "{{.Server.Version}}",
+	}, strings.Split(strings.TrimSpace(string(args)), "\n"))
+}
+
+func TestCheckConnectionUnreachableHost(t *testing.T) {
+	prefix, _ := installPrefixCommand(t)
+	socket := filepath.Join(t.TempDir(), "missing.sock")
+	configureHost(t, docker.HostSettings{Host: "unix://" + socket, CommandPrefix: []string{prefix}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := docker.CheckConnection(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), socket)
+}
+
+func TestDockerCommandsUseHostSettings(t *testing.T) {
+	prefix, argsFile := installPrefixCommand(t)
+	configureHost(t, docker.HostSettings{
+		Host:          "tcp://10.0.0.5:2375",
+		CommandPrefix: []string{prefix, "-n"},
+	})
+
+	t.Run("commands run behind the prefix", func(t *testing.T) {
+		_, err := docker.RunningConfigHashes("my-app", 10*time.Second)
+		require.NoError(t, err)
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+		assert.Equal(t, []string{"-n", "docker", "--host", "tcp://10.0.0.5:2375", "ps", "--all"}, lines[:6])
+		// The format contains spaces and quotes, it must reach docker as a single argument
+		assert.Equal(t, `{{.Label "com.docker.compose.service"}} {{.Label "com.docker.compose.config-hash"}}`,
+			lines[len(lines)-1])
+	})
+
+	t.Run("command line shows the prefix", func(t *testing.T) {
+		project := &docker.ComposeProject{
+			Name:         "my-app",
+			WorkingDir:   "/data/projects/my-app/git",
+			ComposeFiles: []string{"compose.yaml"},
+		}
+		assert.Contains(t, project.UpCommandLine(),
+			"NO_COLOR=1 "+prefix+" -n docker --host tcp://10.0.0.5:2375 compose --progress plain")
+	})
+}
@@ -0,0 +1,273 @@
+package docker
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	"gopkg.in/yaml.v3"
+)
+
+// ImagePolicy controls where the images of a project come from
+type ImagePolicy struct {
+	// RegistryMirror is a registry that Docker Hub images are pulled from instead of Docker Hub, as
+	// host[:port][/path]. Images from other registries are pulled from their own registry.
+	RegistryMirror string
+	// Offline never pulls images. Deployments fail before up when an image is not present locally.
+	Offline bool
+}
+
+// ServiceImage is the image a service runs
+type ServiceImage struct {
+	Service string
+	Image   string
+}
+
+func (i ServiceImage) String() string {
+	if i.Service == "" {
+		return i.Image
+	}
+	return fmt.Sprintf("%s (%s)", i.Service, i.Image)
+}
+
+// composeImages is the part of a rendered compose config needed to find the images of the services
+type composeImages struct {
+	Services map[string]struct {
+		Image string    `yaml:"image"`
+		Build yaml.Node `yaml:"build"`
+	} `yaml:"services"`
+}
+
+// ParseServiceImages parses a rendered compose config and returns the images of the given services,
+// or of all services when none are given, sorted by service. Services with a build section are left
+// out, since Compose builds their image instead of pulling it.
+func ParseServiceImages(config string, services []string) ([]ServiceImage, error) {
+	var parsed composeImages
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+
+	images := []ServiceImage{}
+	for name, service := range parsed.Services {
+		if len(services) > 0 && !slices.Contains(services, name) {
+			continue
+		}
+		if service.Image == "" || !service.Build.IsZero() {
+			continue
+		}
+		images = append(images, ServiceImage{Service: name, Image: service.Image})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Service < images[j].Service })
+	return images, nil
+}
+
+// MissingImages returns the images that exists reports as not present. Each distinct image is looked
+// up once.
+func MissingImages(images []ServiceImage, exists func(image string) (bool, error)) ([]ServiceImage, error) {
+	present := make(map[string]bool)
+	missing := []ServiceImage{}
+	for _, image := range images {
+		found, checked := present[image.Image]
+		if !checked {
+			var err error
+			found, err = exists(image.Image)
+			if err != nil {
+				return nil, err
+			}
+			present[image.Image] = found
+		}
+		if !found {
+			missing = append(missing, image)
+		}
+	}
+	return missing, nil
+}
+
+// MirrorImage returns the reference to pull image from the mirror, and false when the image cannot
+// be pulled from the mirror. Like the registry-mirrors setting of the Docker daemon, only Docker Hub
+// images are mirrored. Images pinned by digest are not mirrored either, since a digest reference
+// cannot be tagged with its original name.
+func MirrorImage(image, mirror string) (string, bool) {
+	if mirror == "" {
+		return "", false
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil || reference.Domain(named) != "docker.io" {
+		return "", false
+	}
+	if _, ok := named.(reference.Digested); ok {
+		return "", false
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%s:%s", mirror, reference.Path(named), tagged.Tag()), true
+}
+
+// PrepareImages makes the images of the selected services, and the extra images, available before
+// up. In offline mode it checks that all of them are present locally and returns an error naming
+// every missing image. With a registry mirror, missing images are pulled from the mirror; images the
+// mirror cannot provide are left for docker compose to pull.
+func (p *ComposeProject) PrepareImages(outputChan chan<- StreamMessage, extra ...string) error {
+	if !p.ImagePolicy.Offline && p.ImagePolicy.RegistryMirror == "" {
+		return nil
+	}
+
+	images, err := p.serviceImages()
+	if err != nil {
+		return err
+	}
+	for _, image := range extra {
+		images = append(images, ServiceImage{Image: image})
+	}
+
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			slog.Debug("Failed to close Docker client", "error", closeErr)
+		}
+	}()
+
+	missing, err := MissingImages(images, dockerClient.ImageExists)
+	if err != nil {
+		return err
+	}
+
+	if p.ImagePolicy.Offline {
+		if len(missing) > 0 {
+			names := make([]string, 0, len(missing))
+			for _, image := range missing {
+				names = append(names, image.String())
+			}
+			return fmt.Errorf("offline mode: image(s) not present locally: %s", strings.Join(names, ", "))
+		}
+		outputChan <- StreamMessage{
+			Type:    "info",
+			Content: fmt.Sprintf("Offline mode: all %d image(s) are present locally", len(images)),
+		}
+		return nil
+	}
+
+	p.pullFromMirror(dockerClient, missing, outputChan)
+	return nil
+}
+
+// pullFromMirror pulls images from the registry mirror and tags them with their original name, so
+// that Compose finds them locally. It returns the images that could not be pulled from the mirror.
+func (p *ComposeProject) pullFromMirror(
+	dockerClient *DockerClient,
+	images []ServiceImage,
+	outputChan chan<- StreamMessage,
+) []ServiceImage {
+	failed := []ServiceImage{}
+	pulled := make(map[string]bool)
+	for _, image := range images {
+		if ok, done := pulled[image.Image]; done {
+			if !ok {
+				failed = append(failed, image)
+			}
+			continue
+		}
+
+		mirrored, ok := MirrorImage(image.Image, p.ImagePolicy.RegistryMirror)
+		if ok {
+			outputChan <- StreamMessage{
+				Type:    "info",
+				Content: fmt.Sprintf("Pulling %s from %s", image.Image, mirrored),
+			}
+			err := dockerClient.PullImage(mirrored)
+			if err == nil {
+				err = dockerClient.TagImage(mirrored, image.Image)
+			}
+			if err != nil {
+				slog.Warn("Failed to pull image from registry mirror",
+					"project_name", p.Name,
+					"image", image.Image,
+					"mirror", p.ImagePolicy.RegistryMirror,
+					"error", err)
+				outputChan <- StreamMessage{
+					Type:    "stderr",
+					Content: fmt.Sprintf("Failed to pull %s from the registry mirror: %v", image.Image, err),
+				}
+				ok = false
+			}
+		}
+		pulled[image.Image] = ok
+		if !ok {
+			failed = append(failed, image)
+		}
+	}
+	return failed
+}
+
+// serviceImages returns the images of the selected services and the services they depend on, which up
+// starts too, or of all services when none are selected
+func (p *ComposeProject) serviceImages() ([]ServiceImage, error) {
+	config, stderr, err := p.GetConfig()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" && !IsTimeout(err) {
+			return nil, fmt.Errorf("invalid compose configuration: %s", msg)
+		}
+		return nil, fmt.Errorf("invalid compose configuration: %w", err)
+	}
+
+	services := p.Services
+	if len(services) > 0 {
+		if services, err = ResolveServices(config, services); err != nil {
+			return nil, err
+		}
+	}
+	return ParseServiceImages(config, services)
+}
+
+// pullViaMirror pulls the images of the selected services from the registry mirror and returns the
+// services left for docker compose to pull: those whose image could not be pulled from the mirror and
+// those that are built.
+func (p *ComposeProject) pullViaMirror(outputChan chan<- StreamMessage) ([]string, error) {
+	config, _, err := p.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	var parsed composeImages
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse compose config: %w", err)
+	}
+	images, err := ParseServiceImages(config, p.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			slog.Debug("Failed to close Docker client", "error", closeErr)
+		}
+	}()
+
+	failed := p.pullFromMirror(dockerClient, images, outputChan)
+
+	remaining := []string{}
+	for name := range parsed.Services {
+		if len(p.Services) > 0 && !slices.Contains(p.Services, name) {
+			continue
+		}
+		mirrored := slices.ContainsFunc(images, func(image ServiceImage) bool {
+			return image.Service == name && !slices.Contains(failed, image)
+		})
+		if !mirrored {
+			remaining = append(remaining, name)
+		}
+	}
+	sort.Strings(remaining)
+	return remaining, nil
+}
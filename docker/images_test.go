@@ -0,0 +1,116 @@
+package docker_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestParseServiceImages(t *testing.T) {
+	config := `services:
+  web:
+    image: nginx:1.25
+    depends_on:
+      - db
+  db:
+    image: postgres:16
+  worker:
+    build:
+      context: ./worker
+    image: my-app/worker
+  job:
+    build: ./job
+`
+
+	images, err := docker.ParseServiceImages(config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []docker.ServiceImage{
+		{Service: "db", Image: "postgres:16"},
+		{Service: "web", Image: "nginx:1.25"},
+	}, images)
+
+	images, err = docker.ParseServiceImages(config, []string{"web", "worker"})
+	require.NoError(t, err)
+	assert.Equal(t, []docker.ServiceImage{{Service: "web", Image: "nginx:1.25"}}, images)
+
+	_, err = docker.ParseServiceImages("services: [", nil)
+	assert.Error(t, err)
+}
+
+func TestMissingImages(t *testing.T) {
+	images := []docker.ServiceImage{
+		{Service: "db", Image: "postgres:16"},
+		{Service: "web", Image: "nginx:1.25"},
+		{Service: "proxy", Image: "nginx:1.25"},
+	}
+
+	t.Run("one image local and another missing", func(t *testing.T) {
+		lookups := map[string]int{}
+		missing, err := docker.MissingImages(images, func(image string) (bool, error) {
+			lookups[image]++
+			return image == "postgres:16", nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []docker.ServiceImage{
+			{Service: "web", Image: "nginx:1.25"},
+			{Service: "proxy", Image: "nginx:1.25"},
+		}, missing)
+		assert.Equal(t, map[string]int{"postgres:16": 1, "nginx:1.25": 1}, lookups)
+	})
+
+	t.Run("all images local", func(t *testing.T) {
+		missing, err := docker.MissingImages(images, func(string) (bool, error) { return true, nil })
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		_, err := docker.MissingImages(images, func(string) (bool, error) {
+			return false, errors.New("daemon unavailable")
+		})
+		assert.EqualError(t, err, "daemon unavailable")
+	})
+}
+
+func TestMirrorImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  string
+		mirror string
+		want   string
+		ok     bool
+	}{
+		{"official image", "nginx:1.25", "mirror.local:5000", "mirror.local:5000/library/nginx:1.25", true},
+		{"latest tag added", "nginx", "mirror.local", "mirror.local/library/nginx:latest", true},
+		{"user image", "grafana/grafana:11.0.0", "mirror.local", "mirror.local/grafana/grafana:11.0.0", true},
+		{"explicit docker hub", "docker.io/library/redis:7", "mirror.local", "mirror.local/library/redis:7", true},
+		{"mirror with path", "nginx:1.25", "mirror.local/hub", "mirror.local/hub/library/nginx:1.25", true},
+		{"other registry", "ghcr.io/oar-cd/oar:1.0", "mirror.local", "", false},
+		{
+			"pinned by digest",
+			"nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+			"mirror.local",
+			"",
+			false,
+		},
+		{"no mirror", "nginx:1.25", "", "", false},
+		{"invalid image", "Nginx", "mirror.local", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := docker.MirrorImage(tt.image, tt.mirror)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServiceImageString(t *testing.T) {
+	assert.Equal(t, "web (nginx:1.25)", docker.ServiceImage{Service: "web", Image: "nginx:1.25"}.String())
+	assert.Equal(t, "busybox:1.36.1-glibc", docker.ServiceImage{Image: docker.BusyboxImage}.String())
+}
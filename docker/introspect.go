@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ListServices returns the services declared in the rendered configuration, including services
+// from included files and services that extend others. It only reads the configuration, so it
+// works whether or not the project is running.
+func (p *ComposeProject) ListServices() ([]string, error) {
+	return p.configList("--services")
+}
+
+// ListVolumes returns the named volumes declared in the rendered configuration
+func (p *ComposeProject) ListVolumes() ([]string, error) {
+	return p.configList("--volumes")
+}
+
+// ListImages returns the images used by the services in the rendered configuration. Services that
+// are built and have no image name are listed with the name Compose gives the built image.
+func (p *ComposeProject) ListImages() ([]string, error) {
+	return p.configList("--images")
+}
+
+// configList runs docker compose config with flags that print one entry per line
+func (p *ComposeProject) configList(flags ...string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), p.queryTimeout())
+	defer cancel()
+
+	cmd := p.prepareCommandContext(ctx, "config", flags)
+	killProcessGroupOnCancel(cmd)
+
+	stdout, stderr, err := p.executeCommand(cmd)
+	if err != nil {
+		err = p.timeoutError(ctx, "config "+strings.Join(flags, " "), err)
+		if stderr = strings.TrimSpace(stderr); stderr != "" && !IsTimeout(err) {
+			return nil, fmt.Errorf("%w: %s", err, stderr)
+		}
+		return nil, err
+	}
+
+	names := []string{}
+	for line := range strings.Lines(stdout) {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,65 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// installConfigDocker puts a docker stub on PATH that answers config --services, --volumes and
+// --images like docker compose does, one name per line
+func installConfigDocker(t *testing.T) {
+	t.Helper()
+
+	script := `#!/bin/sh
+for arg in "$@"; do last="$arg"; done
+case "$last" in
+--services) printf 'web\nworker\n\n' ;;
+--volumes) printf 'data\n' ;;
+--images) printf 'nginx:1.27\napp-worker\n' ;;
+*) exit 1 ;;
+esac
+`
+	installStubDocker(t, script)
+}
+
+func TestConfigLists(t *testing.T) {
+	installConfigDocker(t)
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	services, err := project.ListServices()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web", "worker"}, services)
+
+	volumes, err := project.ListVolumes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data"}, volumes)
+
+	images, err := project.ListImages()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nginx:1.27", "app-worker"}, images)
+}
+
+func TestConfigListError(t *testing.T) {
+	script := "#!/bin/sh\necho 'service \"base\" not found in extends' >&2\nexit 15\n"
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	// The error carries Compose's explanation, e.g. a broken extends
+	_, err := project.ListServices()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `service "base" not found in extends`)
+	assert.False(t, docker.IsTimeout(err))
+}
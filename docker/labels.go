@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContainerLabelsFile is where the labels of the project's containers are written in the git directory.
+// Compose merges it last and merges labels key by key, so they are added to the labels of the compose
+// files and win over labels of the same name.
+const ContainerLabelsFile = ".oar-container-labels.yaml"
+
+// LabelContainers sets the project's container labels on every service, in the Compose commands that
+// follow. Compose has no setting for all services, so the services are read from the configuration and
+// the labels are written to ContainerLabelsFile for each of them.
+func (p *ComposeProject) LabelContainers() error {
+	path := filepath.Join(p.WorkingDir, ContainerLabelsFile)
+	p.LabelledServices = nil
+	if len(p.ContainerLabels) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove container labels file: %w", err)
+		}
+		return nil
+	}
+
+	config, stderr, err := p.GetConfig()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" && !IsTimeout(err) {
+			return fmt.Errorf("invalid compose configuration: %s", msg)
+		}
+		return fmt.Errorf("invalid compose configuration: %w", err)
+	}
+	var parsed struct {
+		Services map[string]any `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(config), &parsed); err != nil {
+		return fmt.Errorf("failed to parse compose config: %w", err)
+	}
+	services := slices.Sorted(maps.Keys(parsed.Services))
+	if len(services) == 0 {
+		return nil
+	}
+
+	type labelledService struct {
+		Labels map[string]string `yaml:"labels"`
+	}
+	content := struct {
+		Services map[string]labelledService `yaml:"services"`
+	}{Services: make(map[string]labelledService, len(services))}
+	for _, service := range services {
+		content.Services[service] = labelledService{Labels: p.ContainerLabels}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Container labels set by Oar for the deployment, do not edit\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(content); err != nil {
+		return fmt.Errorf("failed to encode container labels: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode container labels: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write container labels file: %w", err)
+	}
+	p.LabelledServices = services
+	return nil
+}
@@ -0,0 +1,54 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestLabelContainers(t *testing.T) {
+	// Stub docker: renders a configuration with two services
+	script := "#!/bin/sh\nprintf 'services:\\n  web:\\n    image: nginx\\n  db:\\n    image: postgres\\n'\n"
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:            "my-app",
+		WorkingDir:      t.TempDir(),
+		ComposeFiles:    []string{"compose.yaml"},
+		ImagePins:       map[string]string{"web": "nginx@sha256:0123"},
+		ContainerLabels: map[string]string{"team": "payments", "com.example.cost-center": "42"},
+	}
+
+	require.NoError(t, project.LabelContainers())
+	assert.Equal(t, []string{"db", "web"}, project.LabelledServices)
+	assert.Equal(t, []string{"compose.yaml", docker.ImagePinsFile, docker.ContainerLabelsFile}, project.ConfigFiles(),
+		"The labels should merge last")
+
+	content, err := os.ReadFile(filepath.Join(project.WorkingDir, docker.ContainerLabelsFile))
+	require.NoError(t, err)
+	var labels struct {
+		Services map[string]struct {
+			Labels map[string]string `yaml:"labels"`
+		} `yaml:"services"`
+	}
+	require.NoError(t, yaml.Unmarshal(content, &labels))
+	require.Len(t, labels.Services, 2)
+	for _, service := range []string{"web", "db"} {
+		assert.Equal(t, project.ContainerLabels, labels.Services[service].Labels)
+	}
+
+	project.ContainerLabels = nil
+	require.NoError(t, project.LabelContainers())
+	assert.Equal(t, []string{"compose.yaml", docker.ImagePinsFile}, project.ConfigFiles())
+	assert.NoFileExists(t, filepath.Join(project.WorkingDir, docker.ContainerLabelsFile))
+
+	assert.ErrorContains(t,
+		docker.ValidateComposeFiles([]string{"compose.yaml", docker.ContainerLabelsFile}),
+		"reserved for container labels")
+}
@@ -0,0 +1,132 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogsOptions controls how service logs are read
+type LogsOptions struct {
+	// Timestamps prefixes each line with the time the service wrote it
+	Timestamps bool
+	// StripColor removes terminal escape sequences that services write, such as colors
+	StripColor bool
+	// Since only shows logs written after this time, a duration such as 1h or a timestamp
+	Since string
+	// Tail only shows this many of the last lines of each service, 0 for all lines
+	Tail int
+	// Grep only shows lines that match this regular expression
+	Grep string
+	// IgnoreCase matches Grep regardless of case
+	IgnoreCase bool
+	// Services only shows the logs of these services, all services when empty
+	Services []string
+}
+
+// Validate checks the options before docker compose logs is started, so that a mistake is
+// reported clearly instead of as a failure of the command
+func (o LogsOptions) Validate() error {
+	if o.Since != "" && !validSince(o.Since) {
+		return fmt.Errorf("invalid since value %q: use a duration such as 1h or a timestamp such as %s",
+			o.Since, "2025-01-02T15:04:05Z")
+	}
+	if o.Tail < 0 {
+		return fmt.Errorf("tail must not be negative, got: %d", o.Tail)
+	}
+	_, err := o.grepRegexp()
+	return err
+}
+
+// grepRegexp compiles the Grep pattern, or returns nil when there is none
+func (o LogsOptions) grepRegexp() (*regexp.Regexp, error) {
+	if o.Grep == "" {
+		return nil, nil
+	}
+	pattern := o.Grep
+	if o.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	grep, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grep pattern %q: %w", o.Grep, err)
+	}
+	return grep, nil
+}
+
+// validSince reports whether docker compose logs accepts value for --since
+func validSince(value string) bool {
+	if _, err := time.ParseDuration(value); err == nil {
+		return true
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// matchLogLine reports whether a log line matches grep. The line is matched as it is shown: without
+// colors, and with only the message of Docker Compose's own structured lines.
+func matchLogLine(line string, grep *regexp.Regexp) bool {
+	return grep.MatchString(ParseComposeLogLine(StripANSI(line)))
+}
+
+// filterLogLines keeps the lines of logs that match grep
+func filterLogLines(logs string, grep *regexp.Regexp) string {
+	var kept strings.Builder
+	for line := range strings.SplitAfterSeq(logs, "\n") {
+		if matchLogLine(strings.TrimSuffix(line, "\n"), grep) {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
+
+// logGrepWriter writes the lines that match grep and drops the others. A line is held back until
+// it is complete, so Flush must be called at the end.
+type logGrepWriter struct {
+	w       io.Writer
+	grep    *regexp.Regexp
+	pending []byte
+}
+
+func newLogGrepWriter(w io.Writer, grep *regexp.Regexp) *logGrepWriter {
+	return &logGrepWriter{w: w, grep: grep}
+}
+
+func (g *logGrepWriter) Write(p []byte) (int, error) {
+	g.pending = append(g.pending, p...)
+	for {
+		i := bytes.IndexByte(g.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := g.pending[:i+1]
+		if matchLogLine(string(line[:i]), g.grep) {
+			if _, err := g.w.Write(line); err != nil {
+				return 0, err
+			}
+		}
+		g.pending = g.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes out the last line when it did not end with a line break and matches
+func (g *logGrepWriter) Flush() error {
+	if len(g.pending) == 0 {
+		return nil
+	}
+	line := g.pending
+	g.pending = nil
+	if !matchLogLine(string(line), g.grep) {
+		return nil
+	}
+	_, err := g.w.Write(line)
+	return err
+}
@@ -0,0 +1,113 @@
+package docker_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// installLogsDocker puts a docker stub on PATH that records its arguments and runs logsScript
+func installLogsDocker(t *testing.T, logsScript string) string {
+	t.Helper()
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := "#!/bin/sh\necho \"$*\" >> " + argsFile + "\n" + logsScript
+	installStubDocker(t, script)
+	return argsFile
+}
+
+func TestLogsOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    docker.LogsOptions
+		wantErr string
+	}{
+		{name: "defaults", opts: docker.LogsOptions{}},
+		{name: "duration", opts: docker.LogsOptions{Since: "90m", Tail: 50}},
+		{name: "timestamp", opts: docker.LogsOptions{Since: "2025-01-02T15:04:05Z"}},
+		{name: "date", opts: docker.LogsOptions{Since: "2025-01-02"}},
+		{name: "bad since", opts: docker.LogsOptions{Since: "yesterday"}, wantErr: `invalid since value "yesterday"`},
+		{name: "negative tail", opts: docker.LogsOptions{Tail: -1}, wantErr: "tail must not be negative"},
+		{name: "grep", opts: docker.LogsOptions{Grep: `time(d )?out`, IgnoreCase: true}},
+		{name: "bad grep", opts: docker.LogsOptions{Grep: "error("}, wantErr: `invalid grep pattern "error("`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLogsGrep(t *testing.T) {
+	argsFile := installLogsDocker(t, `printf 'web-1  | \033[31mERROR\033[0m connection timed out\n'
+printf 'web-1  | GET /health 200\n'
+printf 'db-1   | checkpoint complete\n'
+printf 'time="2025-01-01T00:00:00Z" level=warning msg="web-1 exited, Timeout reached"\n' >&2
+printf 'time="2025-01-01T00:00:00Z" level=warning msg="db-1 healthy"\n' >&2
+`)
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+
+	// Colors are ignored when matching and kept in the output
+	stdout, stderr, err := project.Logs(docker.LogsOptions{Grep: "ERROR connection", Since: "1h", Tail: 20})
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | \x1b[31mERROR\x1b[0m connection timed out\n", stdout)
+	assert.Empty(t, stderr)
+
+	// Structured lines of Docker Compose are matched on their message
+	stdout, stderr, err = project.Logs(docker.LogsOptions{Grep: "timed? ?out", IgnoreCase: true, StripColor: true})
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | ERROR connection timed out\n", stdout)
+	assert.Equal(t, "time=\"2025-01-01T00:00:00Z\" level=warning msg=\"web-1 exited, Timeout reached\"\n", stderr)
+
+	_, _, err = project.Logs(docker.LogsOptions{Grep: "("})
+	assert.ErrorContains(t, err, "invalid grep pattern")
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	require.Len(t, lines, 2, "docker should not run with an invalid pattern")
+	assert.True(t, strings.HasSuffix(lines[0], " logs --since 1h --tail 20"), "unexpected arguments: %s", lines[0])
+	assert.True(t, strings.HasSuffix(lines[1], " logs"), "unexpected arguments: %s", lines[1])
+}
+
+func TestLogsPiping_GrepAcrossWrites(t *testing.T) {
+	// Lines are split across separate writes and the last one has no line break
+	installLogsDocker(t, "printf 'web-1  | dis'\nsleep 0.1\nprintf 'carded\\nweb-1  | \\033[3'\nsleep 0.1\n"+
+		"printf '1mkept\\033[0m\\nweb-1  | also kept'\n")
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+	}
+	err = project.LogsPiping(docker.LogsOptions{Grep: "KEPT", IgnoreCase: true, StripColor: true})
+	os.Stdout = stdout
+	require.NoError(t, writer.Close())
+	require.NoError(t, err)
+
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "web-1  | kept\nweb-1  | also kept", string(output))
+}
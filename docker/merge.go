@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OverrideFile is where the project's compose override is written in the git directory. The name avoids
+// colliding with a compose.override.yaml in the repository.
+const OverrideFile = ".oar-compose-override.yaml"
+
+// mergedSections are the top-level sections whose entries are annotated with the files declaring them
+var mergedSections = []string{"services", "networks", "volumes", "configs", "secrets"}
+
+// ValidateComposeFiles rejects compose file lists whose merge order would be ambiguous: a file listed
+// twice, or the file Oar writes the compose override to
+func ValidateComposeFiles(files []string) error {
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		clean := filepath.Clean(strings.TrimSpace(file))
+		if clean == OverrideFile {
+			return fmt.Errorf("compose file %s is reserved for the compose override", OverrideFile)
+		}
+		if clean == ImagePinsFile {
+			return fmt.Errorf("compose file %s is reserved for pinned image digests", ImagePinsFile)
+		}
+		if clean == ContainerLabelsFile {
+			return fmt.Errorf("compose file %s is reserved for container labels", ContainerLabelsFile)
+		}
+		if seen[clean] {
+			return fmt.Errorf("compose file %s is listed more than once", file)
+		}
+		seen[clean] = true
+	}
+	return nil
+}
+
+// ConfigFiles returns the compose files relative to the working directory in the order Compose merges
+// them: the project's compose files as listed, the compose override, the pinned image digests, then the
+// container labels. Later files override earlier ones, so the override wins over the project's files.
+func (p *ComposeProject) ConfigFiles() []string {
+	files := make([]string, 0, len(p.ComposeFiles)+3)
+	files = append(files, p.ComposeFiles...)
+	if p.ComposeOverride != nil && *p.ComposeOverride != "" {
+		files = append(files, OverrideFile)
+	}
+	if len(p.ImagePins) > 0 {
+		files = append(files, ImagePinsFile)
+	}
+	if len(p.LabelledServices) > 0 {
+		files = append(files, ContainerLabelsFile)
+	}
+	return files
+}
+
+// GetMergedConfig returns the resolved configuration like GetConfig, preceded by the files in merge
+// order and with each service, network, volume, config and secret annotated with the files that declare
+// it. Entries that come only from included files are not annotated.
+func (p *ComposeProject) GetMergedConfig() (string, string, error) {
+	config, stderr, err := p.GetConfig()
+	if err != nil {
+		return "", stderr, err
+	}
+
+	files := p.ConfigFiles()
+	sources := make(map[string]map[string][]string)
+	for _, file := range files {
+		declared, err := declaredEntries(filepath.Join(p.WorkingDir, file))
+		if err != nil {
+			return "", stderr, err
+		}
+		for section, names := range declared {
+			if sources[section] == nil {
+				sources[section] = make(map[string][]string)
+			}
+			for _, name := range names {
+				sources[section][name] = append(sources[section][name], file)
+			}
+		}
+	}
+
+	annotated, err := annotateSources(config, files, sources)
+	if err != nil {
+		return "", stderr, err
+	}
+	return annotated, stderr, nil
+}
+
+// declaredEntries returns the names declared in the top-level sections of a compose file
+func declaredEntries(path string) (map[string][]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+
+	var parsed map[string]any
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file %s: %w", filepath.Base(path), err)
+	}
+
+	declared := make(map[string][]string)
+	for _, section := range mergedSections {
+		entries, _ := parsed[section].(map[string]any)
+		for name := range entries {
+			declared[section] = append(declared[section], name)
+		}
+	}
+	return declared, nil
+}
+
+// annotateSources adds the merge order as a head comment and the declaring files of each entry as line
+// comments to a resolved configuration
+func annotateSources(config string, files []string, sources map[string]map[string][]string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(config), &doc); err != nil {
+		return "", fmt.Errorf("failed to parse merged configuration: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return config, nil
+	}
+	root := doc.Content[0]
+
+	var header strings.Builder
+	header.WriteString("Merged from, in order (later files override earlier ones):")
+	for i, file := range files {
+		fmt.Fprintf(&header, "\n  %d. %s", i+1, file)
+		switch file {
+		case OverrideFile:
+			header.WriteString(" (compose override)")
+		case ImagePinsFile:
+			header.WriteString(" (pinned image digests)")
+		case ContainerLabelsFile:
+			header.WriteString(" (container labels)")
+		}
+	}
+	root.HeadComment = header.String()
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		section, entries := root.Content[i].Value, root.Content[i+1]
+		if sources[section] == nil || entries.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(entries.Content); j += 2 {
+			if declaredIn := sources[section][entries.Content[j].Value]; len(declaredIn) > 0 {
+				entries.Content[j].LineComment = strings.Join(declaredIn, ", ")
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode merged configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode merged configuration: %w", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,114 @@
+package docker_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestConfigFilesOrder(t *testing.T) {
+	override := "services: {}"
+	project := &docker.ComposeProject{
+		Name:            "my-app",
+		WorkingDir:      "/data/projects/my-app/git",
+		ComposeFiles:    []string{"compose.yaml", "compose.prod.yaml", "ops/compose.monitoring.yaml"},
+		ComposeOverride: &override,
+	}
+
+	want := []string{"compose.yaml", "compose.prod.yaml", "ops/compose.monitoring.yaml", docker.OverrideFile}
+	for range 3 {
+		assert.Equal(t, want, project.ConfigFiles(), "Files should merge as listed, with the override last")
+	}
+
+	// The --file arguments follow the same order
+	commandLine := project.UpCommandLine()
+	var positions []int
+	for _, file := range want {
+		position := strings.Index(commandLine, "--file /data/projects/my-app/git/"+file+" ")
+		require.NotEqual(t, -1, position, file)
+		positions = append(positions, position)
+	}
+	assert.IsIncreasing(t, positions)
+
+	empty := ""
+	project.ComposeOverride = &empty
+	assert.Equal(t, want[:3], project.ConfigFiles(), "An empty override should not be merged")
+}
+
+func TestValidateComposeFiles(t *testing.T) {
+	assert.NoError(t, docker.ValidateComposeFiles([]string{"compose.yaml", "compose.override.yaml"}))
+	assert.ErrorContains(t,
+		docker.ValidateComposeFiles([]string{"compose.yaml", "./compose.yaml"}),
+		"listed more than once")
+	assert.ErrorContains(t,
+		docker.ValidateComposeFiles([]string{"compose.yaml", docker.OverrideFile}),
+		"reserved for the compose override")
+}
+
+func TestGetMergedConfig(t *testing.T) {
+	workingDir := t.TempDir()
+	files := map[string]string{
+		"compose.yaml": "name: shop\nservices:\n  web:\n    image: nginx\n  db:\n    image: postgres\n" +
+			"volumes:\n  data: {}\n",
+		"compose.prod.yaml": "services:\n  web:\n    environment:\n      ENV: production\n",
+		docker.OverrideFile: "services:\n  web:\n    environment:\n      ENV: staging\n" +
+			"  cache:\n    image: redis\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(workingDir, name), []byte(content), 0o644))
+	}
+
+	// Stub docker: config prints the merged result Compose would produce
+	merged := `name: shop
+services:
+  cache:
+    image: redis
+  db:
+    image: postgres
+  web:
+    environment:
+      ENV: staging
+    image: nginx
+volumes:
+  data:
+    name: shop_data
+`
+	script := fmt.Sprintf("#!/bin/sh\ncat <<'EOF'\n%sEOF\n", merged)
+	installStubDocker(t, script)
+
+	override := files[docker.OverrideFile]
+	project := &docker.ComposeProject{
+		Name:            "shop",
+		WorkingDir:      workingDir,
+		ComposeFiles:    []string{"compose.yaml", "compose.prod.yaml"},
+		ComposeOverride: &override,
+	}
+
+	annotated, _, err := project.GetMergedConfig()
+	require.NoError(t, err)
+	assert.Equal(t, `# Merged from, in order (later files override earlier ones):
+#   1. compose.yaml
+#   2. compose.prod.yaml
+#   3. .oar-compose-override.yaml (compose override)
+name: shop
+services:
+  cache: # .oar-compose-override.yaml
+    image: redis
+  db: # compose.yaml
+    image: postgres
+  web: # compose.yaml, compose.prod.yaml, .oar-compose-override.yaml
+    environment:
+      ENV: staging
+    image: nginx
+volumes:
+  data: # compose.yaml
+    name: shop_data
+`, annotated)
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+)
+
+// ManagedNetworkLabel marks networks created by Oar, so that only those are ever removed
+const ManagedNetworkLabel = "oar.managed"
+
+// networkNamePattern is the name format the Docker daemon accepts
+var networkNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+
+// ValidateNetworkName returns an error if the Docker daemon would reject the network name
+func ValidateNetworkName(name string) error {
+	if !networkNamePattern.MatchString(name) {
+		return fmt.Errorf(
+			"invalid network name %q: must be at least two characters of letters, digits, '_', '.' or '-', "+
+				"starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// EnsureNetwork creates the network unless it already exists and reports whether it was created.
+// A network created concurrently by someone else counts as existing.
+func (dc *DockerClient) EnsureNetwork(name string) (bool, error) {
+	if _, err := dc.cli.NetworkInspect(dc.ctx, name, network.InspectOptions{}); err == nil {
+		return false, nil
+	} else if !cerrdefs.IsNotFound(err) {
+		return false, fmt.Errorf("failed to inspect network %s: %w", name, err)
+	}
+
+	_, err := dc.cli.NetworkCreate(dc.ctx, name, network.CreateOptions{
+		Labels: map[string]string{ManagedNetworkLabel: "true"},
+	})
+	if err != nil {
+		if cerrdefs.IsConflict(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create network %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// RemoveManagedNetwork removes the network if Oar created it and reports whether it was removed.
+// Networks that do not exist or were created by someone else are left alone.
+func (dc *DockerClient) RemoveManagedNetwork(name string) (bool, error) {
+	networks, err := dc.cli.NetworkList(dc.ctx, network.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", ManagedNetworkLabel+"=true")),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	for _, n := range networks {
+		// The name filter of the API matches substrings, so compare names here
+		if n.Name != name {
+			continue
+		}
+		if err := dc.cli.NetworkRemove(dc.ctx, n.ID); err != nil {
+			if cerrdefs.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to remove network %s: %w", name, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
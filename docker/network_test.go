@@ -0,0 +1,37 @@
+package docker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestValidateNetworkName(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		wantErr bool
+	}{
+		{name: "simple", network: "proxy"},
+		{name: "with separators", network: "shared_net.v2-internal"},
+		{name: "starts with digit", network: "1net"},
+		{name: "single character", network: "a", wantErr: true},
+		{name: "empty", network: "", wantErr: true},
+		{name: "starts with separator", network: "-proxy", wantErr: true},
+		{name: "space", network: "my network", wantErr: true},
+		{name: "slash", network: "team/proxy", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := docker.ValidateNetworkName(tt.network)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pause freezes the running containers of the project with docker compose pause. Unlike Down, it keeps
+// the containers, their memory and their network connections.
+func (p *ComposeProject) Pause() error {
+	return p.runPause("pause")
+}
+
+// Unpause resumes the paused containers of the project
+func (p *ComposeProject) Unpause() error {
+	return p.runPause("unpause")
+}
+
+func (p *ComposeProject) runPause(command string) error {
+	_, stderr, err := p.executeCommand(p.prepareCommand(command, nil))
+	if err != nil {
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			return fmt.Errorf("docker compose %s failed: %s", command, msg)
+		}
+		return fmt.Errorf("docker compose %s failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// progressMode returns the --progress mode for the project's commands
+func (p *ComposeProject) progressMode() string {
+	if p.progress == "" {
+		return "plain"
+	}
+	return p.progress
+}
+
+// streaming returns a copy of p whose commands use the configured progress mode. Only output
+// streamed as StreamMessages uses it; piped and captured output always uses plain progress.
+func (p *ComposeProject) streaming() *ComposeProject {
+	streaming := *p
+	if p.Config != nil {
+		streaming.progress = p.Config.ComposeProgress
+	}
+	return &streaming
+}
+
+// Progress describes how far a step of a pull or build got, such as downloading one image layer
+type Progress struct {
+	// ID identifies the step, for example a layer ID
+	ID string `json:"id"`
+	// Parent is what the step belongs to, for example the image a layer is pulled for
+	Parent string `json:"parent,omitempty"`
+	// Status is what the step is doing, for example "Downloading" or "Extracting"
+	Status string `json:"status"`
+	// Percent is how far the step got, from 0 to 100
+	Percent int `json:"percent"`
+}
+
+// progressEvent is a line of docker compose --progress json output
+type progressEvent struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parent_id"`
+	Text     string `json:"text"`
+	Status   string `json:"status"`
+	Current  int64  `json:"current"`
+	Total    int64  `json:"total"`
+	Percent  int    `json:"percent"`
+}
+
+// ParseComposeProgress turns a line of Docker Compose progress output into a StreamMessage. JSON
+// events that report how far a pull or build step got become "progress" messages. Other JSON
+// events, such as containers being created and started, become "stderr" messages with the event
+// as text. Terminal escape sequences from tty progress are removed, and lines that are not JSON
+// events are parsed with ParseComposeLogLine.
+func ParseComposeProgress(line string) StreamMessage {
+	line = StripANSI(line)
+
+	if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "{") {
+		var event progressEvent
+		if err := json.Unmarshal([]byte(trimmed), &event); err == nil {
+			if message := event.message(); message != "" {
+				if percent, ok := event.percent(); ok {
+					return StreamMessage{Type: "progress", Content: message, Progress: &Progress{
+						ID:      event.ID,
+						Parent:  event.ParentID,
+						Status:  event.Text,
+						Percent: percent,
+					}}
+				}
+				return StreamMessage{Type: "stderr", Content: message}
+			}
+		}
+	}
+
+	return StreamMessage{Type: "stderr", Content: ParseComposeLogLine(line)}
+}
+
+// layerDoneTexts are the texts of pull events for layers that need no more work
+var layerDoneTexts = map[string]bool{"Pull complete": true, "Already exists": true}
+
+// percent returns how far the event's step got, if the event reports it
+func (e progressEvent) percent() (int, bool) {
+	switch {
+	case e.ParentID != "" && layerDoneTexts[e.Text]:
+		return 100, true
+	case e.Percent > 0:
+		return min(e.Percent, 100), true
+	case e.Total > 0:
+		return int(min(e.Current, e.Total) * 100 / e.Total), true
+	default:
+		return 0, false
+	}
+}
+
+// message formats the event, or returns "" if it carries nothing to show
+func (e progressEvent) message() string {
+	var parts []string
+	for _, part := range []string{e.ID, e.Text, e.Status} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	// Completed layers already say so in their text
+	if e.Percent > 0 || e.Current > 0 {
+		percent, _ := e.percent()
+		parts = append(parts, fmt.Sprintf("%d%%", percent))
+	}
+	return strings.Join(parts, " ")
+}
+
+// progressThrottle drops progress messages whose step, status and percentage were already sent.
+// Pulls report every few kilobytes, which would flood the output channel.
+type progressThrottle struct {
+	last map[Progress]struct{}
+}
+
+// changed reports whether message should be sent
+func (t *progressThrottle) changed(message StreamMessage) bool {
+	if message.Progress == nil {
+		return true
+	}
+	if t.last == nil {
+		t.last = make(map[Progress]struct{})
+	}
+	if _, seen := t.last[*message.Progress]; seen {
+		return false
+	}
+	t.last[*message.Progress] = struct{}{}
+	return true
+}
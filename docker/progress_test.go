@@ -0,0 +1,171 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestParseComposeProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want docker.StreamMessage
+	}{
+		{
+			name: "json event",
+			line: `{"id":"Container app-web-1","text":"Started"}`,
+			want: docker.StreamMessage{Type: "stderr", Content: "Container app-web-1 Started"},
+		},
+		{
+			name: "json pull progress with percent",
+			line: `{"id":"a1b2c3","parent_id":"Image nginx","text":"Downloading","percent":45}`,
+			want: docker.StreamMessage{
+				Type:     "progress",
+				Content:  "a1b2c3 Downloading 45%",
+				Progress: &docker.Progress{ID: "a1b2c3", Parent: "Image nginx", Status: "Downloading", Percent: 45},
+			},
+		},
+		{
+			name: "json pull progress with byte counts",
+			line: `{"id":"a1b2c3","text":"Extracting","current":512,"total":2048}`,
+			want: docker.StreamMessage{
+				Type:     "progress",
+				Content:  "a1b2c3 Extracting 25%",
+				Progress: &docker.Progress{ID: "a1b2c3", Status: "Extracting", Percent: 25},
+			},
+		},
+		{
+			name: "completed layer",
+			line: `{"id":"a1b2c3","parent_id":"nginx","text":"Pull complete"}`,
+			want: docker.StreamMessage{
+				Type:     "progress",
+				Content:  "a1b2c3 Pull complete",
+				Progress: &docker.Progress{ID: "a1b2c3", Parent: "nginx", Status: "Pull complete", Percent: 100},
+			},
+		},
+		{
+			name: "json without message fields falls back to the line",
+			line: `{"dry-run":false}`,
+			want: docker.StreamMessage{Type: "stderr", Content: `{"dry-run":false}`},
+		},
+		{
+			name: "plain structured log",
+			line: `time="2025-01-01T00:00:00Z" level=warning msg="the attribute version is obsolete"`,
+			want: docker.StreamMessage{Type: "stderr", Content: "the attribute version is obsolete"},
+		},
+		{
+			name: "tty progress escapes are removed",
+			line: "\x1b[1A\x1b[2K \x1b[32m✔\x1b[0m Container app-web-1  \x1b[32mStarted\x1b[0m",
+			want: docker.StreamMessage{Type: "stderr", Content: " ✔ Container app-web-1  Started"},
+		},
+		{
+			name: "plain text starting with a brace is kept",
+			line: "{not json} from a build step",
+			want: docker.StreamMessage{Type: "stderr", Content: "{not json} from a build step"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, docker.ParseComposeProgress(tt.line))
+		})
+	}
+}
+
+func TestParseComposeProgress_CapturedOutput(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "progress.jsonl"))
+	require.NoError(t, err)
+
+	var text []string
+	last := map[string]int{}
+	for line := range strings.Lines(string(data)) {
+		message := docker.ParseComposeProgress(strings.TrimSuffix(line, "\n"))
+		switch message.Type {
+		case "progress":
+			require.NotNil(t, message.Progress)
+			key := message.Progress.Parent + "/" + message.Progress.ID
+			assert.GreaterOrEqual(t, message.Progress.Percent, 0)
+			assert.LessOrEqual(t, message.Progress.Percent, 100)
+			last[key] = message.Progress.Percent
+		case "stderr":
+			assert.Nil(t, message.Progress)
+			text = append(text, message.Content)
+		default:
+			t.Fatalf("unexpected message type %q for line %s", message.Type, line)
+		}
+	}
+
+	// Each layer reports progress under its image, up to the completed pull
+	assert.Equal(t, map[string]int{"redis/5d0a5f6e8b3c": 100, "nginx/a2318d6c47ec": 100}, last)
+
+	// Everything else reads as text, in order
+	assert.Equal(t, []string{
+		"redis Pulling",
+		"nginx Pulling",
+		"5d0a5f6e8b3c Pulling fs layer",
+		"a2318d6c47ec Pulling fs layer",
+		"5d0a5f6e8b3c Download complete",
+		"redis Pulled",
+		"a2318d6c47ec Download complete",
+		"nginx Pulled",
+		"Service worker Building",
+		"#5 [worker 2/3] RUN pip install -r requirements.txt",
+		"Service worker Built",
+		"Network app_default Creating",
+		"Network app_default Created",
+		"Container app-redis-1 Creating",
+		"Container app-redis-1 Created",
+		"Container app-web-1 Starting",
+		"Container app-web-1 Started",
+		"/srv/app/compose.yaml: the attribute `version` is obsolete",
+	}, text)
+}
+
+func TestStreamingProgressMode(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	captured, err := filepath.Abs(filepath.Join("testdata", "progress.jsonl"))
+	require.NoError(t, err)
+	script := "#!/bin/sh\necho \"$*\" >> " + argsFile + "\ncat " + captured + " >&2\n"
+	installStubDocker(t, script)
+
+	project := &docker.ComposeProject{
+		Name:         "app",
+		WorkingDir:   t.TempDir(),
+		ComposeFiles: []string{"compose.yaml"},
+		Config:       &config.Config{ComposeProgress: "json"},
+	}
+
+	outputChan := make(chan docker.StreamMessage, 100)
+	require.NoError(t, project.UpStreaming(true, outputChan))
+	close(outputChan)
+	var messages []docker.StreamMessage
+	for message := range outputChan {
+		messages = append(messages, message)
+	}
+
+	// The repeated "Downloading 1%" line is sent once
+	capturedOutput, err := os.ReadFile(captured)
+	require.NoError(t, err)
+	require.Len(t, messages, strings.Count(string(capturedOutput), "\n")-1)
+	assert.Equal(t, docker.StreamMessage{Type: "stderr", Content: "redis Pulling"}, messages[0])
+	assert.Equal(t, "Container app-web-1 Started", messages[len(messages)-2].Content)
+
+	// Captured output keeps plain progress
+	_, _, err = project.Up(true)
+	require.NoError(t, err)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(args)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "--progress json ")
+	assert.Contains(t, lines[1], "--progress plain ")
+}
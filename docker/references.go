@@ -0,0 +1,206 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BrokenReferenceError is a compose file that includes or extends a file that does not exist, or extends
+// a service its file does not define. Paths are relative to the project's directory.
+type BrokenReferenceError struct {
+	File          string // The compose file with the reference
+	Service       string // The service that extends, empty for an include
+	Target        string // The referenced file
+	TargetService string // The extended service, empty for an include or a missing file
+}
+
+func (e *BrokenReferenceError) Error() string {
+	switch {
+	case e.Service == "":
+		return fmt.Sprintf("%s includes %s, which does not exist", e.File, e.Target)
+	case e.TargetService == "":
+		return fmt.Sprintf("service %s in %s extends a service from %s, which does not exist",
+			e.Service, e.File, e.Target)
+	default:
+		return fmt.Sprintf("service %s in %s extends service %s, which %s does not define",
+			e.Service, e.File, e.TargetService, e.Target)
+	}
+}
+
+// referencingFile are the parts of a compose file that refer to other files
+type referencingFile struct {
+	Include  []yaml.Node `yaml:"include"`
+	Services map[string]struct {
+		Extends yaml.Node `yaml:"extends"`
+	} `yaml:"services"`
+}
+
+// CheckReferences follows the include and extends references of the compose files in dir, and of the
+// files they refer to, and returns a BrokenReferenceError for the first one that cannot be resolved.
+// Compose reports these only when it renders the configuration, with the path of the missing file but
+// not where it was referenced. References with variables or to remote files are left to Compose, as
+// are files that do not exist or do not parse.
+func CheckReferences(dir string, composeFiles []string) error {
+	checker := &referenceChecker{dir: dir, visited: make(map[string]bool), services: make(map[string][]string)}
+	for _, file := range composeFiles {
+		if err := checker.check(checker.resolve(dir, file)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type referenceChecker struct {
+	dir      string
+	visited  map[string]bool
+	services map[string][]string // Sorted services of the files that parsed
+}
+
+func (c *referenceChecker) resolve(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// relative returns path relative to the project's directory, for messages
+func (c *referenceChecker) relative(path string) string {
+	if rel, err := filepath.Rel(c.dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// parse reads a compose file, returning nil when it does not exist or does not parse
+func (c *referenceChecker) parse(path string) *referencingFile {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var parsed referencingFile
+	if err := yaml.Unmarshal(content, &parsed); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(parsed.Services))
+	for name := range parsed.Services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	c.services[path] = names
+	return &parsed
+}
+
+func (c *referenceChecker) check(path string) error {
+	if c.visited[path] {
+		return nil
+	}
+	c.visited[path] = true
+
+	parsed := c.parse(path)
+	if parsed == nil {
+		return nil
+	}
+	base := filepath.Dir(path)
+
+	for _, include := range parsed.Include {
+		for _, target := range includePaths(&include) {
+			if !resolvable(target) {
+				continue
+			}
+			targetPath := c.resolve(base, target)
+			if !fileExists(targetPath) {
+				return &BrokenReferenceError{File: c.relative(path), Target: c.relative(targetPath)}
+			}
+			if err := c.check(targetPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, service := range c.services[path] {
+		extends := parsed.Services[service].Extends
+		file, extended := extendsTarget(&extends)
+		if extended == "" || !resolvable(file) || !resolvable(extended) {
+			continue
+		}
+		targetPath := path
+		if file != "" {
+			targetPath = c.resolve(base, file)
+		}
+		brokenErr := &BrokenReferenceError{File: c.relative(path), Service: service, Target: c.relative(targetPath)}
+		if !fileExists(targetPath) {
+			return brokenErr
+		}
+		if err := c.check(targetPath); err != nil {
+			return err
+		}
+		if services, parsed := c.services[targetPath]; parsed && !slices.Contains(services, extended) {
+			brokenErr.TargetService = extended
+			return brokenErr
+		}
+	}
+	return nil
+}
+
+// includePaths returns the files of an include entry, given as a path, or as a mapping with a path or
+// a list of paths
+func includePaths(node *yaml.Node) []string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}
+	case yaml.MappingNode:
+		var entry struct {
+			Path yaml.Node `yaml:"path"`
+		}
+		if err := node.Decode(&entry); err != nil {
+			return nil
+		}
+		switch entry.Path.Kind {
+		case yaml.ScalarNode:
+			return []string{entry.Path.Value}
+		case yaml.SequenceNode:
+			var paths []string
+			if err := entry.Path.Decode(&paths); err != nil {
+				return nil
+			}
+			return paths
+		}
+	}
+	return nil
+}
+
+// extendsTarget returns the file and service an extends entry names. The file is empty for a service
+// of the same file.
+func extendsTarget(node *yaml.Node) (file, service string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return "", node.Value
+	case yaml.MappingNode:
+		var entry struct {
+			File    string `yaml:"file"`
+			Service string `yaml:"service"`
+		}
+		if err := node.Decode(&entry); err != nil {
+			return "", ""
+		}
+		return entry.File, entry.Service
+	}
+	return "", ""
+}
+
+// resolvable reports whether a reference can be checked without Compose: it has no variables and is
+// not a remote resource such as a git repository or an OCI artifact
+func resolvable(reference string) bool {
+	return !strings.Contains(reference, "$") && !strings.Contains(reference, "://") &&
+		!strings.HasPrefix(reference, "git@") && !strings.HasPrefix(reference, "oci:")
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,116 @@
+package docker_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestCheckReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "resolvable references",
+			files: map[string]string{
+				"compose.yaml": `include:
+  - db/compose.yaml
+  - path: [cache/compose.yaml, cache/compose.override.yaml]
+services:
+  web:
+    extends:
+      file: common/base.yaml
+      service: app
+  worker:
+    extends: web
+`,
+				"db/compose.yaml": "services:\n  db:\n    extends:\n" +
+					"      file: ../common/base.yaml\n      service: app\n",
+				"cache/compose.yaml":          "services:\n  cache:\n    image: redis\n",
+				"cache/compose.override.yaml": "services:\n  cache:\n    restart: always\n",
+				"common/base.yaml":            "services:\n  app:\n    image: app\n",
+			},
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"compose.yaml": "include:\n  - path: db/compose.yaml\n",
+			},
+			wantErr: "compose.yaml includes db/compose.yaml, which does not exist",
+		},
+		{
+			name: "missing file of an included file",
+			files: map[string]string{
+				"compose.yaml":    "include:\n  - db/compose.yaml\n",
+				"db/compose.yaml": "services:\n  db:\n    extends:\n      file: base.yaml\n      service: db\n",
+			},
+			wantErr: "service db in db/compose.yaml extends a service from db/base.yaml, which does not exist",
+		},
+		{
+			name: "missing extended service",
+			files: map[string]string{
+				"compose.yaml": "services:\n  web:\n    extends:\n" +
+					"      file: common/base.yaml\n      service: web\n",
+				"common/base.yaml": "services:\n  app:\n    image: app\n",
+			},
+			wantErr: "service web in compose.yaml extends service web, which common/base.yaml does not define",
+		},
+		{
+			name: "missing service of the same file",
+			files: map[string]string{
+				"compose.yaml": "services:\n  worker:\n    extends: web\n",
+			},
+			wantErr: "service worker in compose.yaml extends service web, which compose.yaml does not define",
+		},
+		{
+			name: "references left to Compose",
+			files: map[string]string{
+				"compose.yaml": `include:
+  - ${STACK_DIR}/compose.yaml
+  - oci://registry.example.com/stacks/db:1
+services:
+  web:
+    extends:
+      file: ${BASE_FILE:-base.yaml}
+      service: web
+`,
+			},
+		},
+		{
+			name: "cyclic includes",
+			files: map[string]string{
+				"compose.yaml": "include:\n  - other.yaml\n",
+				"other.yaml":   "include:\n  - compose.yaml\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+			}
+
+			err := docker.CheckReferences(dir, []string{"compose.yaml"})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+			var referenceErr *docker.BrokenReferenceError
+			assert.True(t, errors.As(err, &referenceErr))
+		})
+	}
+}
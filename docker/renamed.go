@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ComposeProjectContainers returns the names of the containers of a Compose project, in any state,
+// e.g. those left behind under the previous name of a renamed project
+func ComposeProjectContainers(projectName string, timeout time.Duration) ([]string, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	out, err := dockerOutput(ctx, "ps", "--all",
+		"--filter", "label="+composeProjectLabel+"="+projectName,
+		"--format", "{{.Names}}")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("docker ps timed out after %s", timeout)
+		}
+		return nil, fmt.Errorf("failed to list containers of compose project %s: %w", projectName, err)
+	}
+	return strings.Fields(out), nil
+}
+
+// RemoveComposeProject removes the containers and networks of a Compose project known only by its
+// name, as docker compose --project-name <name> down does. Volumes are kept. It returns the output
+// of Compose.
+func RemoveComposeProject(projectName string) (string, error) {
+	// Compose would load a compose file from the working directory, so it runs in an empty one
+	emptyDir, err := os.MkdirTemp("", "oar-down-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(emptyDir) }()
+
+	cmd := dockerCommand(context.Background(), "compose", "--project-name", projectName, "down", "--remove-orphans")
+	cmd.Dir = emptyDir
+	cmd.Env = append(os.Environ(), "NO_COLOR=1")
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		return output, fmt.Errorf("failed to remove compose project %s: %w", projectName, err)
+	}
+	return output, nil
+}
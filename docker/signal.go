@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReloadSignal is the signal conventionally used to make a service reload its configuration
+const ReloadSignal = "SIGHUP"
+
+// allowedSignals are the signals that can be sent to a service. SIGKILL is left out on purpose,
+// stopping a service is done by stopping the project.
+var allowedSignals = map[string]bool{
+	"SIGHUP":   true,
+	"SIGINT":   true,
+	"SIGQUIT":  true,
+	"SIGTERM":  true,
+	"SIGUSR1":  true,
+	"SIGUSR2":  true,
+	"SIGWINCH": true,
+	"SIGCONT":  true,
+	"SIGSTOP":  true,
+	"SIGTSTP":  true,
+	"SIGALRM":  true,
+}
+
+// ParseSignal validates a signal name and returns it in canonical form (e.g. "hup" becomes "SIGHUP").
+// An empty name is rejected, because docker compose kill without a signal sends SIGKILL.
+func ParseSignal(name string) (string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("a signal is required")
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	if !allowedSignals[name] {
+		return "", fmt.Errorf("unsupported signal %q", name)
+	}
+	return name, nil
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stopWithSignal stops the running containers of the project by sending them signal. Containers
+// that have not exited after timeout are killed; zero keeps the stop timeout of each container.
+// The docker commands are killed when ctx is done.
+func (p *ComposeProject) stopWithSignal(ctx context.Context, signal string, timeout time.Duration) error {
+	ps := p.prepareCommandContext(ctx, "ps", []string{"--quiet"})
+	killProcessGroupOnCancel(ps)
+	stdout, stderr, err := p.executeCommand(ps)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w: %s", err, strings.TrimSpace(stderr))
+	}
+	containerIDs := strings.Fields(stdout)
+	if len(containerIDs) == 0 {
+		return nil
+	}
+
+	args := []string{"stop", "--signal", signal}
+	if timeout > 0 {
+		args = append(args, "--timeout", timeoutSeconds(timeout))
+	}
+	args = append(args, containerIDs...)
+
+	slog.Info("Stopping containers with signal",
+		"project_name", p.Name,
+		"signal", signal,
+		"timeout", timeout,
+		"containers", len(containerIDs))
+
+	cmd := dockerCommand(ctx, args...)
+	killProcessGroupOnCancel(cmd)
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+	if err := cmd.Run(); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "docker",
+			"operation", "stop_with_signal",
+			"project_name", p.Name,
+			"signal", signal,
+			"error", err,
+			"stderr", stderrBuf.String())
+		return fmt.Errorf("failed to stop containers: %w: %s", err, strings.TrimSpace(stderrBuf.String()))
+	}
+	return nil
+}
+
+// timeoutSeconds formats a timeout for the --timeout flag of docker, which takes whole seconds.
+// Partial seconds are rounded up, so that containers never get less time than asked for.
+func timeoutSeconds(timeout time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(timeout.Seconds())))
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultQueryTimeout bounds status and config calls when the config does not set a timeout
+	DefaultQueryTimeout = 10 * time.Second
+	// killWaitDelay bounds how long to wait for output pipes after the process group was killed
+	killWaitDelay = 2 * time.Second
+)
+
+// TimeoutError is returned when a read-only Docker Compose call does not finish in time,
+// usually because the Docker daemon is unresponsive
+type TimeoutError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("docker compose %s timed out after %s", e.Command, e.Timeout)
+}
+
+// IsTimeout reports whether err was caused by a Docker Compose call timing out
+func IsTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// queryTimeout returns the timeout for status and config calls
+func (p *ComposeProject) queryTimeout() time.Duration {
+	if p.Config == nil || p.Config.ComposeQueryTimeout <= 0 {
+		return DefaultQueryTimeout
+	}
+	return p.Config.ComposeQueryTimeout
+}
+
+// timeoutError converts err to a TimeoutError if ctx expired while the command ran
+func (p *ComposeProject) timeoutError(ctx context.Context, command string, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Command: command, Timeout: p.queryTimeout()}
+	}
+	return err
+}
+
+// killProcessGroupOnCancel runs cmd in its own process group and kills the whole group when the
+// command's context is done. docker runs compose as a plugin subprocess, so killing only the
+// docker process would leave the plugin running and holding the output pipes open.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = killWaitDelay
+}
@@ -0,0 +1,110 @@
+package docker_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+)
+
+// installHangingDocker puts a docker stub on PATH that never answers. Like the real CLI, it leaves
+// the work to a child process, whose PID it writes to the returned file.
+func installHangingDocker(t *testing.T) string {
+	t.Helper()
+
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	installStubDocker(t, fmt.Sprintf("#!/bin/sh\nsleep 60 &\necho $! > %s\nwait\n", pidFile))
+	return pidFile
+}
+
+// installStubDocker puts a docker executable running script first on PATH for the test
+func installStubDocker(t *testing.T, script string) {
+	t.Helper()
+
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "docker"), []byte(script), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// processGone reports whether the process has exited. An exited process may linger as a zombie
+// until its new parent reaps it, which counts as gone.
+func processGone(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return true
+	}
+	// The state follows the parenthesized command name
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] == "Z"
+}
+
+func TestQueryTimeout(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("requires /proc")
+	}
+
+	timeout := 200 * time.Millisecond
+	project := &docker.ComposeProject{
+		Name:       "hanging",
+		WorkingDir: t.TempDir(),
+		Config:     &config.Config{ComposeQueryTimeout: timeout},
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "status",
+			call: func() error {
+				_, err := project.Status()
+				return err
+			},
+		},
+		{
+			name: "config",
+			call: func() error {
+				_, _, err := project.GetConfig()
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pidFile := installHangingDocker(t)
+
+			start := time.Now()
+			err := tt.call()
+			elapsed := time.Since(start)
+
+			require.Error(t, err)
+			assert.True(t, docker.IsTimeout(err), "expected a timeout error, got: %v", err)
+			assert.Contains(t, err.Error(), "timed out after 200ms")
+			assert.Less(t, elapsed, 5*time.Second, "the call must not wait for the stub to finish")
+
+			content, err := os.ReadFile(pidFile)
+			require.NoError(t, err)
+			pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+			require.NoError(t, err)
+			assert.Eventually(t, func() bool { return processGone(pid) }, 2*time.Second, 20*time.Millisecond,
+				"the child process must be killed on timeout")
+		})
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	err := fmt.Errorf("failed to get status: %w", &docker.TimeoutError{Command: "ps", Timeout: time.Second})
+	assert.True(t, docker.IsTimeout(err))
+	assert.Equal(t, "failed to get status: docker compose ps timed out after 1s", err.Error())
+	assert.False(t, docker.IsTimeout(fmt.Errorf("exit status 1")))
+}
@@ -0,0 +1,49 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Versions are the versions of the Docker daemon and the Compose plugin that deploy projects
+type Versions struct {
+	Docker  string // Version of the daemon, e.g. 27.3.1
+	Compose string // Version of the Compose plugin, e.g. 2.29.7
+}
+
+// ToolVersions queries the version of the Docker daemon and of the Compose plugin, through the
+// docker CLI with the configured host and command prefix
+func ToolVersions(timeout time.Duration) (Versions, error) {
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	dockerVersion, err := dockerOutput(ctx, "version", "--format", "{{.Server.Version}}")
+	if err != nil {
+		return Versions{}, fmt.Errorf("failed to get the Docker version: %w", err)
+	}
+	composeVersion, err := dockerOutput(ctx, "compose", "version", "--short")
+	if err != nil {
+		return Versions{}, fmt.Errorf("failed to get the Docker Compose version: %w", err)
+	}
+	// Older releases print the version with a leading v
+	return Versions{Docker: dockerVersion, Compose: strings.TrimPrefix(composeVersion, "v")}, nil
+}
+
+// dockerOutput runs the docker CLI with args and returns its trimmed output
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := dockerCommand(ctx, args...)
+	killProcessGroupOnCancel(cmd)
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderrBuf.String()))
+	}
+	return strings.TrimSpace(stdoutBuf.String()), nil
+}
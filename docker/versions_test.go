@@ -0,0 +1,43 @@
+package docker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestToolVersions(t *testing.T) {
+	// Runs as the command prefix, so its arguments start with docker
+	prefix := filepath.Join(t.TempDir(), "fake-docker")
+	script := `#!/bin/sh
+shift
+case "$1 $2" in
+"version --format") echo 27.3.1 ;;
+"compose version") echo v2.29.7 ;;
+*) exit 1 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(prefix, []byte(script), 0o755))
+	configureHost(t, docker.HostSettings{CommandPrefix: []string{prefix}})
+
+	versions, err := docker.ToolVersions(10 * time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, docker.Versions{Docker: "27.3.1", Compose: "2.29.7"}, versions)
+}
+
+func TestToolVersionsDaemonUnreachable(t *testing.T) {
+	prefix := filepath.Join(t.TempDir(), "fake-docker")
+	script := "#!/bin/sh\necho 'Cannot connect to the Docker daemon' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(prefix, []byte(script), 0o755))
+	configureHost(t, docker.HostSettings{CommandPrefix: []string{prefix}})
+
+	_, err := docker.ToolVersions(10 * time.Second)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Cannot connect to the Docker daemon")
+}
@@ -256,8 +256,60 @@ func (config *ComposeConfig) HasBuildServices() bool {
 
 // InitializeVolumeMounts fixes volume mount point ownership using helper containers
 func (p *ComposeProject) InitializeVolumeMounts() error {
+	_, err := p.initializeVolumeMounts(false)
+	return err
+}
+
+// InitializeVolumeMountsBeforeCreate fixes volume mount point ownership like InitializeVolumeMounts,
+// before the containers are created. The images must be present, see PrepareImagesForVolumeInit, since
+// the users of the services come from them. Named volumes Compose has not created yet, which it creates
+// along with the containers, are left out; it reports whether there were any, so that
+// InitializeVolumeMounts runs for them once the containers are created.
+func (p *ComposeProject) InitializeVolumeMountsBeforeCreate() (bool, error) {
+	return p.initializeVolumeMounts(true)
+}
+
+// PrepareImagesForVolumeInit pulls the missing images of the selected services and builds those with a
+// build section, unless built is true because the deployment built them already. Compose does both when
+// it creates the containers, which is too late when the mounts are initialized before. In offline mode
+// nothing is pulled, and with a registry mirror the images were pulled from the mirror already.
+func (p *ComposeProject) PrepareImagesForVolumeInit(built bool, outputChan chan<- StreamMessage) error {
+	if !p.ImagePolicy.Offline && p.ImagePolicy.RegistryMirror == "" {
+		args := []string{"--ignore-buildable", "--policy", "missing"}
+		if len(p.Services) > 0 && !p.NoDeps {
+			args = append(args, "--include-deps")
+		}
+		cmd := p.streaming().prepareCommand("pull", append(args, p.Services...))
+		if err := p.executeCommandStreaming(cmd, outputChan); err != nil {
+			return fmt.Errorf("failed to pull images: %w", err)
+		}
+	}
+	if built {
+		return nil
+	}
+	configYAML, _, err := p.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get compose config: %w", err)
+	}
+	var config ComposeConfig
+	if err := yaml.Unmarshal([]byte(configYAML), &config); err != nil {
+		return fmt.Errorf("failed to parse compose config: %w", err)
+	}
+	if !config.HasBuildServices() {
+		return nil
+	}
+	if err := p.BuildStreaming(outputChan); err != nil {
+		return fmt.Errorf("failed to build images: %w", err)
+	}
+	return nil
+}
+
+// initializeVolumeMounts fixes volume mount point ownership, only of the mounts that exist before the
+// containers are created when beforeCreate is true. It reports whether named volumes were left out.
+func (p *ComposeProject) initializeVolumeMounts(beforeCreate bool) (bool, error) {
 	slog.Debug("Starting volume mounts initialization",
-		"project_name", p.Name)
+		"project_name", p.Name,
+		"before_create", beforeCreate)
 
 	// Get compose config first to check what we need to do
 	configYAML, stderr, err := p.GetConfig()
@@ -265,7 +317,7 @@ func (p *ComposeProject) InitializeVolumeMounts() error {
 		slog.Error("Failed to get compose config",
 			"project_name", p.Name,
 			"error", err)
-		return fmt.Errorf("failed to get compose config: %w", err)
+		return false, fmt.Errorf("failed to get compose config: %w", err)
 	}
 	// Log stderr warnings but don't include them in the YAML processing
 	if stderr != "" {
@@ -278,29 +330,50 @@ func (p *ComposeProject) InitializeVolumeMounts() error {
 		slog.Error("Failed to parse compose config YAML",
 			"project_name", p.Name,
 			"error", err)
-		return fmt.Errorf("failed to parse compose config: %w", err)
+		return false, fmt.Errorf("failed to parse compose config: %w", err)
 	}
 
 	// Note: Images and containers are now created by the calling code
 	// before volume initialization using docker compose up --no-start
 
+	// Only the selected services and their dependencies have containers to initialize
+	if len(p.Services) > 0 {
+		resolved, err := ResolveServices(configYAML, p.Services)
+		if err != nil {
+			return false, err
+		}
+		selected := make(map[string]Service, len(resolved))
+		for _, name := range resolved {
+			selected[name] = config.Services[name]
+		}
+		config.Services = selected
+	}
+
+	pending := false
+	if beforeCreate {
+		pending, err = p.leaveOutMissingVolumes(&config)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// Get all services that need volume permission fixing
 	services, err := config.ServicesWithVolumes(p)
 	if err != nil {
-		return fmt.Errorf("failed to get services with volumes: %w", err)
+		return false, fmt.Errorf("failed to get services with volumes: %w", err)
 	}
 
 	if len(services) == 0 {
 		slog.Debug("No services need volume permission fixing, skipping initialization",
 			"project_name", p.Name)
-		return nil
+		return pending, nil
 	}
 
 	// Fix permissions for each service
 	for _, service := range services {
 		err := p.fixServiceVolumePermissions(service)
 		if err != nil {
-			return fmt.Errorf("failed to fix permissions for service %s: %w", service.Name, err)
+			return false, fmt.Errorf("failed to fix permissions for service %s: %w", service.Name, err)
 		}
 	}
 
@@ -308,7 +381,55 @@ func (p *ComposeProject) InitializeVolumeMounts() error {
 		"project_name", p.Name,
 		"services_processed", len(services))
 
-	return nil
+	return pending, nil
+}
+
+// leaveOutMissingVolumes removes the named volumes that do not exist yet from the services of config,
+// and anonymous volumes, which each new container gets afresh. It reports whether it removed any.
+func (p *ComposeProject) leaveOutMissingVolumes(config *ComposeConfig) (bool, error) {
+	dockerClient, err := NewDockerClient()
+	if err != nil {
+		return false, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			slog.Debug("Failed to close Docker client", "error", closeErr)
+		}
+	}()
+
+	exists := make(map[string]bool)
+	pending := false
+	for name, service := range config.Services {
+		var volumes []Volume
+		for _, volume := range service.Volumes {
+			if volume.Type != "volume" {
+				volumes = append(volumes, volume)
+				continue
+			}
+			if volume.Source == "" {
+				pending = true
+				continue
+			}
+			// Docker Compose prefixes named volumes with project name
+			volumeName := fmt.Sprintf("%s_%s", p.Name, volume.Source)
+			found, checked := exists[volumeName]
+			if !checked {
+				found, err = dockerClient.VolumeExists(volumeName)
+				if err != nil {
+					return false, err
+				}
+				exists[volumeName] = found
+			}
+			if found {
+				volumes = append(volumes, volume)
+			} else {
+				pending = true
+			}
+		}
+		service.Volumes = volumes
+		config.Services[name] = service
+	}
+	return pending, nil
 }
 
 // fixServiceVolumePermissions creates a helper container to fix permissions for a service's volumes
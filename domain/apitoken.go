@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APITokenAction is what an API token allows on the JSON API
+type APITokenAction string
+
+const (
+	// APITokenActionRead reads the state of projects: deployments, logs, declared services and statuses
+	APITokenActionRead APITokenAction = "read"
+	// APITokenActionDeploy checks a project for new commits and deploys them, by a watcher check or a push webhook
+	APITokenActionDeploy APITokenAction = "deploy"
+	// APITokenActionAnnotate annotates deployments
+	APITokenActionAnnotate APITokenAction = "annotate"
+)
+
+// APITokenActions are all actions, in the order they are listed
+var APITokenActions = []APITokenAction{APITokenActionRead, APITokenActionDeploy, APITokenActionAnnotate}
+
+// ParseAPITokenAction parses the name of an action
+func ParseAPITokenAction(s string) (APITokenAction, error) {
+	action := APITokenAction(s)
+	if !slices.Contains(APITokenActions, action) {
+		return "", fmt.Errorf("unknown API token action %q, valid actions: read, deploy, annotate", s)
+	}
+	return action, nil
+}
+
+// APIToken authenticates requests to the JSON API. A token scoped to a project can only be used for
+// that project, one without a project for every project and the endpoints that concern none.
+type APIToken struct {
+	ID        uuid.UUID
+	Name      string
+	ProjectID *uuid.UUID // nil when the token is not scoped to a project
+	Actions   []APITokenAction
+	CreatedAt time.Time
+}
+
+// Allows reports whether the token may perform action on the project. projectID is nil for endpoints
+// that concern no single project, which tokens scoped to a project cannot use.
+func (t *APIToken) Allows(action APITokenAction, projectID *uuid.UUID) bool {
+	if !slices.Contains(t.Actions, action) {
+		return false
+	}
+	if t.ProjectID == nil {
+		return true
+	}
+	return projectID != nil && *projectID == *t.ProjectID
+}
@@ -1,20 +1,30 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Deployment struct {
-	ID         uuid.UUID
-	ProjectID  uuid.UUID
-	CommitHash string
-	Status     DeploymentStatus
-	Stdout     string
-	Stderr     string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID             uuid.UUID
+	ProjectID      uuid.UUID
+	CommitHash     string
+	PreviousCommit string // Commit the deployment replaced, empty for the first deployment of a project
+	Status         DeploymentStatus
+	Stdout         string
+	Stderr         string
+	CommandLine    string           // Compose command that started the services, with variable values masked
+	Notes          string           // Free-form annotation, e.g. the incident a hotfix was deployed for
+	Labels         []string         // Short tags for filtering and correlating deployments
+	FailedServices []ServiceFailure // Services that were not running when compose up failed
+	DockerVersion  string           // Version of the Docker daemon that ran the deployment, empty if unknown
+	ComposeVersion string           // Version of the Compose plugin that ran the deployment, empty if unknown
+	ImageDigests   []string         // Images pinned for the deployment, as image:tag@digest
+	Summary        string           // What the deployment changed: commits, files, images and configuration
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 }
 
 func NewDeployment(projectID uuid.UUID, commitHash string) Deployment {
@@ -25,6 +35,42 @@ func NewDeployment(projectID uuid.UUID, commitHash string) Deployment {
 	}
 }
 
+// ShortCommit shortens a commit hash to 8 characters, like git does
+func ShortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
+
+// ServiceFailure describes a service that was not running after compose up failed
+type ServiceFailure struct {
+	Service  string
+	State    string   // State of its container, empty when no container was created
+	ExitCode int      // Exit code of its container, when it exited
+	LastLogs []string // Last lines the service logged
+}
+
+// String returns a one-line summary, such as "service 'db' failed: exited 1"
+func (f ServiceFailure) String() string {
+	switch f.State {
+	case "":
+		return fmt.Sprintf("service '%s' failed: no container was created", f.Service)
+	case "created":
+		return fmt.Sprintf("service '%s' failed: not started", f.Service)
+	case "exited", "dead":
+		return fmt.Sprintf("service '%s' failed: exited %d", f.Service, f.ExitCode)
+	default:
+		return fmt.Sprintf("service '%s' failed: %s", f.Service, f.State)
+	}
+}
+
+// DeploymentAnnotation holds the user-provided notes and labels of a deployment
+type DeploymentAnnotation struct {
+	Notes  string
+	Labels []string
+}
+
 type DeploymentResult struct {
 	Status DeploymentStatus
 	Stdout string
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/redact"
+)
+
+// gitVariablePattern matches the ${VAR} references in the git URL and branch of a project
+var gitVariablePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// variableNamePattern matches the names a ${VAR} reference may use
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// GitSource is the git URL and branch of a project with their ${VAR} references resolved
+type GitSource struct {
+	URL    string
+	Branch string
+	// WatchURL is where new commits are detected when it differs from URL, e.g. the canonical repository of
+	// a read-only mirror that is deployed from. Empty when they are detected where the project is pulled from.
+	WatchURL string
+	// urlValues are the values substituted in the URLs by variable name. They may hold credentials.
+	urlValues map[string]string
+}
+
+// ResolveGitSource replaces the ${VAR} references in the git URL and branch of the project with the
+// values of its variables, so that one project definition works across environments. References to
+// variables that are not set are an error, which names the variables but never shows values.
+func (p *Project) ResolveGitSource() (*GitSource, error) {
+	values := make(map[string]string, len(p.Variables))
+	for _, variable := range p.Variables {
+		key, value, ok := strings.Cut(variable, "=")
+		if key = strings.TrimSpace(key); ok && key != "" {
+			values[key] = value
+		}
+	}
+
+	source := &GitSource{urlValues: make(map[string]string)}
+	var err error
+	if source.URL, err = interpolateGitField("git URL", p.GitURL, values, source.urlValues); err != nil {
+		return nil, err
+	}
+	if source.Branch, err = interpolateGitField("git branch", p.GitBranch, values, nil); err != nil {
+		return nil, err
+	}
+	if source.WatchURL, err = interpolateGitField("watch URL", p.WatchURL, values, source.urlValues); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// interpolateGitField resolves the references in the value of field, recording the values it
+// substitutes in used when not nil
+func interpolateGitField(field, s string, values, used map[string]string) (string, error) {
+	var missing, invalid []string
+	resolved := gitVariablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if !variableNamePattern.MatchString(name) {
+			invalid = append(invalid, ref)
+			return ref
+		}
+		value, ok := values[name]
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return ref
+		}
+		if used != nil {
+			used[name] = value
+		}
+		return value
+	})
+
+	switch {
+	case len(invalid) > 0:
+		return "", fmt.Errorf("%s has invalid variable references: %s", field, strings.Join(invalid, ", "))
+	case len(missing) > 0:
+		return "", fmt.Errorf("%s references variables that are not set: %s", field, strings.Join(missing, ", "))
+	case s != "" && strings.TrimSpace(resolved) == "":
+		return "", fmt.Errorf("%s is empty once its variables are resolved", field)
+	}
+	return resolved, nil
+}
+
+// Redact replaces the values substituted in the git URLs with the references they came from, so that
+// credentials passed to the URL through variables do not end up in logs and messages
+func (g *GitSource) Redact(s string) string {
+	references := make(map[string]string, len(g.urlValues))
+	for _, name := range slices.Sorted(maps.Keys(g.urlValues)) {
+		// Of variables with the same value, the first name is used
+		if _, ok := references[g.urlValues[name]]; !ok {
+			references[g.urlValues[name]] = "${" + name + "}"
+		}
+	}
+	return redact.NewReplacer(references).Replace(s)
+}
+
+// RedactError returns err with its message redacted by Redact. The result still wraps err.
+func (g *GitSource) RedactError(err error) error {
+	return redact.Error(err, g.Redact)
+}
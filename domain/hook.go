@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HookPhase is the point of a deployment at which a deploy hook runs
+type HookPhase string
+
+const (
+	// HookPhasePreDeploy runs once images are pulled and built, before the containers of the
+	// deployment are created, e.g. to migrate a database before the new code starts on it
+	HookPhasePreDeploy HookPhase = "pre-deploy"
+	// HookPhasePostDeploy runs once the services of the deployment have started
+	HookPhasePostDeploy HookPhase = "post-deploy"
+)
+
+// HookPhases lists the phases a deploy hook can run at, in the order they run
+var HookPhases = []HookPhase{HookPhasePreDeploy, HookPhasePostDeploy}
+
+// DeployHook runs a command in a new container of a service of the project, with
+// 'docker compose run --rm', during each deployment. A hook that exits non-zero fails the deployment.
+type DeployHook struct {
+	Phase   HookPhase
+	Service string
+	Command string // Command line run instead of the service's command, which runs when empty
+}
+
+// ParseDeployHook parses a hook written as PHASE:SERVICE[:COMMAND], such as
+// "pre-deploy:migrate:./manage.py migrate"
+func ParseDeployHook(s string) (DeployHook, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 {
+		return DeployHook{}, fmt.Errorf("invalid deploy hook %q: use PHASE:SERVICE[:COMMAND]", s)
+	}
+	hook := DeployHook{Phase: HookPhase(strings.TrimSpace(parts[0])), Service: strings.TrimSpace(parts[1])}
+	if len(parts) == 3 {
+		hook.Command = strings.TrimSpace(parts[2])
+	}
+	return hook, nil
+}
+
+// String returns the hook as ParseDeployHook reads it
+func (h DeployHook) String() string {
+	if h.Command == "" {
+		return string(h.Phase) + ":" + h.Service
+	}
+	return string(h.Phase) + ":" + h.Service + ":" + h.Command
+}
+
+// Args splits the hook's command into arguments like a shell does, with single and double quotes and
+// backslash escapes, but without expanding variables or globs: the command does not run in a shell
+func (h DeployHook) Args() ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range h.Command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in the command of deploy hook %s", h)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -4,6 +4,7 @@ package domain
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -64,22 +65,119 @@ func ParseGitAuthType(s string) (GitAuthType, error) {
 	return authType, nil
 }
 
+// ProjectSource is where the files of a project come from
+type ProjectSource string
+
+const (
+	// ProjectSourceGit projects are cloned from a git repository and deployments pull its branch
+	ProjectSourceGit ProjectSource = "git"
+	// ProjectSourceArchive projects are extracted from an uploaded tar archive and have no repository
+	ProjectSourceArchive ProjectSource = "archive"
+)
+
+// String implements the Stringer interface
+func (s ProjectSource) String() string {
+	return string(s)
+}
+
+// VolumeInitPhase is when a deployment fixes the ownership of volume mount points
+type VolumeInitPhase string
+
+const (
+	// VolumeInitAfterCreate fixes ownership once Compose has created the containers and their
+	// volumes, before starting them. This is the default.
+	VolumeInitAfterCreate VolumeInitPhase = "after-create"
+	// VolumeInitBeforeCreate fixes ownership before any container is created, with the images
+	// pulled and built and the named volumes created first, so that the running containers are
+	// only replaced once the mounts are ready
+	VolumeInitBeforeCreate VolumeInitPhase = "before-create"
+)
+
+// String implements the Stringer interface
+func (p VolumeInitPhase) String() string {
+	return string(p)
+}
+
+// ParseVolumeInitPhase parses a volume initialization phase, empty meaning the default
+func ParseVolumeInitPhase(s string) (VolumeInitPhase, error) {
+	switch phase := VolumeInitPhase(s); phase {
+	case "":
+		return VolumeInitAfterCreate, nil
+	case VolumeInitAfterCreate, VolumeInitBeforeCreate:
+		return phase, nil
+	}
+	return "", fmt.Errorf("invalid volume initialization phase %q: use %s or %s",
+		s, VolumeInitAfterCreate, VolumeInitBeforeCreate)
+}
+
+// EmailNotifyConfig holds the email notification settings of a project. With a host, the project uses
+// its own SMTP server; with only recipients, it uses the configured server.
+type EmailNotifyConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      string // starttls, tls or none
+}
+
 type Project struct {
-	ID                uuid.UUID
-	Name              string
-	GitURL            string
-	GitBranch         string         // Git branch to use (never empty, always set to default branch if not specified)
-	GitAuth           *GitAuthConfig // Git authentication configuration
-	WorkingDir        string
-	ComposeFiles      []string
-	ComposeOverride   *string  // Optional Docker Compose override content
-	Variables         []string // Variables in .env format, one per string
-	Status            ProjectStatus
-	LocalCommit       *string
-	RemoteCommit      *string
-	AutoDeployEnabled bool // Enable automatic deployments on git changes
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	ID                    uuid.UUID
+	Name                  string
+	ComposeName           string        // Compose project name, unique among projects; Name when empty
+	PreviousNames         []string      // Compose project names the project had before, which may still have containers
+	Source                ProjectSource // Where the project's files come from, git when empty
+	GitURL                string
+	GitBranch             string         // Git branch to use (never empty, always set to default branch if not specified)
+	GitBranchDetected     bool           // GitBranch was detected as the repository's default branch, not given
+	WatchURL              string         // Repository new commits are detected in, GitURL when empty
+	GitAuth               *GitAuthConfig // Git authentication configuration
+	WorkingDir            string
+	ComposeFiles          []string
+	DisabledComposeFiles  []string // Files of ComposeFiles left out of Compose commands, kept for switching back on
+	ComposeOverride       *string  // Optional Docker Compose override content
+	EnvFiles              []string // Env files relative to the repository root, later files override earlier ones
+	Variables             []string // Variables in .env format, one per string
+	Status                ProjectStatus
+	LocalCommit           *string
+	RemoteCommit          *string
+	AutoDeployEnabled     bool               // Enable automatic deployments on git changes
+	AutoDeployPullImages  bool               // Pull images before bringing up services on automatic deployments
+	AutoDeploySkipGitPull bool               // Redeploy the current commit on automatic deployments instead of pulling
+	WatcherDisabled       bool               // The watcher neither checks nor deploys the project on its own
+	WebhookSecret         string             // Push webhooks must be signed with this secret, stored encrypted; unsigned ones are accepted when empty
+	SkipVolumeInit        bool               // Skip preparing volume mount ownership before starting services
+	VolumeInitPhase       VolumeInitPhase    // When volume mount ownership is prepared, VolumeInitAfterCreate if empty
+	DependsOn             []uuid.UUID        // Projects that must be running before this project is deployed
+	ExternalNetworks      []string           // Docker networks created before deployment and shared with other projects
+	RegistryMirror        string             // Registry to pull Docker Hub images from, the configured default when empty
+	OfflineImages         bool               // Never pull images, deploy only with images present locally
+	PinImageDigests       bool               // Deploy images pinned to the digests their tags had when first deployed
+	ImageDigests          []string           // Pinned images, as image:tag@digest, reused until refreshed
+	VerboseBuild          bool               // Show image build output on deployments, not only build errors
+	VerbosePull           bool               // Show image pull progress on deployments
+	BuildOnDeploy         bool               // Rebuild images on every deployment, with up --build
+	BuildArgs             map[string]string  // Build arguments passed to docker compose build as --build-arg
+	BuildSecrets          map[string]string  // Build secrets by variable name, stored encrypted and masked in output
+	ContainerLabels       map[string]string  // Labels set on every container of the project, e.g. team or cost-center
+	DeployHooks           []DeployHook       // Commands run in new containers of services during deployments
+	NotifyWebhookURL      string             // Deployment notifications webhook, the configured default when empty
+	NotifyChannel         string             // Channel to post deployment notifications to, overriding the webhook's own
+	NotifyTemplate        string             // Go template for deployment notifications, the configured default when empty
+	NotifyEmail           *EmailNotifyConfig // Email notification settings, the configured default when nil
+	SortOrder             int                // Position on the dashboard, 0 for projects that were never placed by hand
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// ComposeProjectName returns the name Docker Compose runs the project under. Projects created before
+// compose project names were resolved have none stored and keep running under their name.
+func (p *Project) ComposeProjectName() string {
+	if p.ComposeName != "" {
+		return p.ComposeName
+	}
+	return p.Name
 }
 
 func (p *Project) GitDir() (string, error) {
@@ -89,6 +187,24 @@ func (p *Project) GitDir() (string, error) {
 	return filepath.Join(p.WorkingDir, GitDir), nil
 }
 
+// UsesGit reports whether the project is backed by a git repository. Archive projects have no
+// repository: their URL and branch are empty and their commit is the SHA-256 digest of the archive.
+func (p *Project) UsesGit() bool {
+	return p.Source != ProjectSourceArchive
+}
+
+// EnabledComposeFiles returns the compose files that Compose commands use: ComposeFiles, in order,
+// without the disabled ones
+func (p *Project) EnabledComposeFiles() []string {
+	files := make([]string, 0, len(p.ComposeFiles))
+	for _, file := range p.ComposeFiles {
+		if !slices.Contains(p.DisabledComposeFiles, file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 func (p *Project) LocalCommitStr() string {
 	if p.LocalCommit == nil {
 		return ""
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// ValidateRegistryMirror checks that mirror is a registry host with an optional port and path prefix,
+// such as "mirror.example.com:5000" or "registry.example.com/dockerhub". Schemes are not accepted.
+func ValidateRegistryMirror(mirror string) error {
+	if strings.Contains(mirror, "://") {
+		return fmt.Errorf("registry mirror %q must not include a scheme", mirror)
+	}
+	if strings.HasSuffix(mirror, "/") {
+		return fmt.Errorf("registry mirror %q must not end with a slash", mirror)
+	}
+	if _, err := reference.ParseNamed(mirror + "/library/busybox"); err != nil {
+		return fmt.Errorf("invalid registry mirror %q: %w", mirror, err)
+	}
+	return nil
+}
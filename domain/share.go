@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink grants read-only access to the status and logs of one project, without an account
+type ShareLink struct {
+	ID        uuid.UUID
+	ProjectID uuid.UUID
+	ExpiresAt time.Time
+	RevokedAt *time.Time // nil while the link has not been revoked
+	CreatedAt time.Time
+}
+
+// Active reports whether the link can still be used at now
+func (l *ShareLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
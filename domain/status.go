@@ -10,6 +10,10 @@ const (
 	ProjectStatusRunning
 	ProjectStatusStopped
 	ProjectStatusError
+	// ProjectStatusDeploying is stored while a deployment of the project runs
+	ProjectStatusDeploying
+	// ProjectStatusPaused means the containers of the project are frozen, not stopped
+	ProjectStatusPaused
 )
 
 func (s ProjectStatus) String() string {
@@ -20,6 +24,10 @@ func (s ProjectStatus) String() string {
 		return "stopped"
 	case ProjectStatusError:
 		return "error"
+	case ProjectStatusDeploying:
+		return "deploying"
+	case ProjectStatusPaused:
+		return "paused"
 	case ProjectStatusUnknown:
 		return "unknown"
 	default:
@@ -35,6 +43,10 @@ func ParseProjectStatus(s string) (ProjectStatus, error) {
 		return ProjectStatusStopped, nil
 	case "error":
 		return ProjectStatusError, nil
+	case "deploying":
+		return ProjectStatusDeploying, nil
+	case "paused":
+		return ProjectStatusPaused, nil
 	case "unknown":
 		return ProjectStatusUnknown, nil
 	default:
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WatcherCheck is the result of the most recent watcher check of a project. It is stored so that the
+// last check and the count of consecutive failures survive a restart of the server.
+type WatcherCheck struct {
+	ProjectID           uuid.UUID
+	ProjectName         string // the current name of the project, not stored with the check
+	CheckedAt           time.Time
+	Outcome             string
+	Error               string // empty when the check succeeded
+	ConsecutiveFailures int
+	Manual              bool
+}
@@ -152,3 +152,76 @@ func (e *EncryptionService) DecryptGitAuthConfig(
 
 	return auth, nil
 }
+
+// EncryptEmailNotifyConfig encrypts email notification settings, which hold SMTP credentials, for database
+// storage
+func (e *EncryptionService) EncryptEmailNotifyConfig(config *domain.EmailNotifyConfig) (string, error) {
+	if config == nil {
+		return "", nil
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize email settings: %w", err)
+	}
+
+	encryptedData, err := e.Encrypt(string(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt email settings: %w", err)
+	}
+	return encryptedData, nil
+}
+
+// DecryptEmailNotifyConfig decrypts email notification settings
+func (e *EncryptionService) DecryptEmailNotifyConfig(encrypted string) (*domain.EmailNotifyConfig, error) {
+	if encrypted == "" {
+		return nil, nil
+	}
+
+	data, err := e.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt email settings: %w", err)
+	}
+
+	var config domain.EmailNotifyConfig
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize email settings: %w", err)
+	}
+	return &config, nil
+}
+
+// EncryptBuildSecrets encrypts the build secrets of a project for database storage
+func (e *EncryptionService) EncryptBuildSecrets(secrets map[string]string) (string, error) {
+	if len(secrets) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize build secrets: %w", err)
+	}
+
+	encryptedData, err := e.Encrypt(string(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt build secrets: %w", err)
+	}
+	return encryptedData, nil
+}
+
+// DecryptBuildSecrets decrypts the build secrets of a project
+func (e *EncryptionService) DecryptBuildSecrets(encrypted string) (map[string]string, error) {
+	if encrypted == "" {
+		return nil, nil
+	}
+
+	data, err := e.Decrypt(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt build secrets: %w", err)
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal([]byte(data), &secrets); err != nil {
+		return nil, fmt.Errorf("failed to deserialize build secrets: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// Changes is what a repository changed from one commit to another
+type Changes struct {
+	// Commits are the commits applied, newest first, as "abc12345 Subject (Author)"
+	Commits []string
+	// Files are the changed files, as " A path", " D path" or " M path" like the pull output
+	Files []string
+	// MoreCommits and MoreFiles count what was left out to keep the lists within the limit
+	MoreCommits int
+	MoreFiles   int
+}
+
+// GetChanges lists the commits and the files that changed from one commit to another, like a
+// shortlog and a diff stat of from..to. Each list is cut to limit entries, what is left out is counted.
+func (s *GitService) GetChanges(workingDir, from, to string, limit int) (*Changes, error) {
+	repo, err := git.PlainOpen(workingDir)
+	if err != nil {
+		return nil, err
+	}
+	fromCommit, err := repo.CommitObject(plumbing.NewHash(from))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s: %w", domain.ShortCommit(from), err)
+	}
+	toCommit, err := repo.CommitObject(plumbing.NewHash(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s: %w", domain.ShortCommit(to), err)
+	}
+
+	changes := &Changes{}
+
+	// Commits reachable from the merge base were already deployed, even when the history was replaced
+	var ignore []plumbing.Hash
+	if bases, err := toCommit.MergeBase(fromCommit); err == nil {
+		for _, base := range bases {
+			ignore = append(ignore, base.Hash)
+		}
+	} else {
+		slog.Warn("Failed to find the merge base", "working_dir", workingDir, "from", from, "to", to, "error", err)
+	}
+	err = object.NewCommitPreorderIter(toCommit, nil, ignore).ForEach(func(commit *object.Commit) error {
+		if len(changes.Commits) == limit {
+			changes.MoreCommits++
+			return nil
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		changes.Commits = append(changes.Commits,
+			fmt.Sprintf("%s %s (%s)", domain.ShortCommit(commit.Hash.String()), subject, commit.Author.Name))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	files, err := fileChanges(fromCommit, toCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	if len(files) > limit {
+		changes.MoreFiles = len(files) - limit
+		files = files[:limit]
+	}
+	changes.Files = files
+	return changes, nil
+}
@@ -0,0 +1,58 @@
+package git_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetChanges(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	initRepoWithCommit(t, repo, map[string]string{"compose.yaml": "services: {}\n"})
+	base := getCommitHash(t, repo)
+
+	addCommitToRepo(t, repo, map[string]string{"a.txt": "a"})
+	addCommitToRepo(t, repo, map[string]string{"b.txt": "b", "compose.yaml": "services:\n  web: {}\n"})
+	head := getCommitHash(t, repo)
+	gitService := setupGitService(t)
+
+	t.Run("commits and files", func(t *testing.T) {
+		changes, err := gitService.GetChanges(repo, base, head, 10)
+		require.NoError(t, err)
+		require.Len(t, changes.Commits, 2)
+		assert.Equal(t, head[:8]+" Add files (Test User)", changes.Commits[0])
+		assert.Equal(t, []string{" A a.txt", " A b.txt", " M compose.yaml"}, changes.Files)
+		assert.Zero(t, changes.MoreCommits)
+		assert.Zero(t, changes.MoreFiles)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		changes, err := gitService.GetChanges(repo, base, head, 1)
+		require.NoError(t, err)
+		assert.Len(t, changes.Commits, 1)
+		assert.Equal(t, 1, changes.MoreCommits)
+		assert.Equal(t, []string{" A a.txt"}, changes.Files)
+		assert.Equal(t, 2, changes.MoreFiles)
+	})
+
+	t.Run("replaced history", func(t *testing.T) {
+		// A branch from the base replaces the deployed commits: only its own commit was applied
+		checkoutCommit(t, repo, base)
+		createBranch(t, repo, "rewritten")
+		checkoutBranch(t, repo, "rewritten")
+		addCommitToRepo(t, repo, map[string]string{"c.txt": "c"})
+		rewritten := getCommitHash(t, repo)
+
+		changes, err := gitService.GetChanges(repo, head, rewritten, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{rewritten[:8] + " Add files (Test User)"}, changes.Commits)
+		assert.Equal(t, []string{" D a.txt", " D b.txt", " A c.txt", " M compose.yaml"}, changes.Files)
+	})
+
+	t.Run("unknown commit", func(t *testing.T) {
+		_, err := gitService.GetChanges(repo, "0123456789abcdef0123456789abcdef01234567", head, 10)
+		assert.ErrorContains(t, err, "failed to find commit 01234567")
+	})
+}
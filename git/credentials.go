@@ -0,0 +1,44 @@
+package git
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/oar-cd/oar/redact"
+)
+
+// urlCredentialsPattern matches the scheme and user information of the URLs in a text
+var urlCredentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*)://[^/@\s"]+@`)
+
+// logURL returns gitURL for logs and messages, with the credentials it may hold masked, e.g. a token
+// resolved from the project's variables. SSH URLs keep their user, which names an account.
+func logURL(gitURL string) string {
+	u, err := url.Parse(gitURL)
+	if err != nil || u.User == nil {
+		return gitURL
+	}
+	if u.Scheme == "ssh" {
+		return u.Redacted()
+	}
+	u.User = url.User("xxxxx")
+	return u.String()
+}
+
+// maskCredentials masks the user information of the URLs in s other than SSH ones. The errors of
+// requests to a remote quote its URL, with a token given as user name.
+func maskCredentials(s string) string {
+	return urlCredentialsPattern.ReplaceAllStringFunc(s, func(match string) string {
+		scheme, _, _ := strings.Cut(match, "://")
+		if strings.EqualFold(scheme, "ssh") {
+			return match
+		}
+		return scheme + "://xxxxx@"
+	})
+}
+
+// maskError returns err with the credentials in its message masked by maskCredentials. The result
+// still wraps err; err is returned as is when there is nothing to mask.
+func maskError(err error) error {
+	return redact.Error(err, maskCredentials)
+}
@@ -3,8 +3,14 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/config"
@@ -14,6 +20,7 @@ import (
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
 	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
 	oarconfig "github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 )
 
@@ -66,9 +73,16 @@ func (s *GitService) createSSHAuth(config *domain.GitSSHAuthConfig) (transport.A
 	return ssh.NewPublicKeys(user, keyBytes, "") // Empty password for passwordless keys
 }
 
-// Clone clones a repository with optional authentication and branch
-func (s *GitService) Clone(gitURL string, gitBranch string, gitAuth *domain.GitAuthConfig, workingDir string) error {
-	slog.Info("Cloning repository", "git_url", gitURL, "git_branch", gitBranch, "working_dir", workingDir)
+// Clone clones a repository with optional authentication and branch, and returns the git output.
+// Unless outputChan is nil, the progress lines of the remote are sent to it while the clone runs.
+func (s *GitService) Clone(
+	gitURL string,
+	gitBranch string,
+	gitAuth *domain.GitAuthConfig,
+	workingDir string,
+	outputChan chan<- docker.StreamMessage,
+) (string, error) {
+	slog.Info("Cloning repository", "git_url", logURL(gitURL), "git_branch", gitBranch, "working_dir", workingDir)
 
 	// Create authentication method
 	authMethod, err := s.createAuthMethod(gitAuth)
@@ -76,20 +90,24 @@ func (s *GitService) Clone(gitURL string, gitBranch string, gitAuth *domain.GitA
 		slog.Error("Service operation failed",
 			"layer", "git",
 			"operation", "git_clone_auth",
-			"git_url", gitURL,
+			"git_url", logURL(gitURL),
 			"working_dir", workingDir,
 			"error", err)
-		return fmt.Errorf("failed to create auth method: %w", err)
+		return "", fmt.Errorf("failed to create auth method: %w", err)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
-	defer cancel()
-
+	var output strings.Builder
+	var progressWriter io.Writer = &output
+	var reporter *progressReporter
+	if outputChan != nil {
+		reporter = &progressReporter{output: outputChan}
+		progressWriter = io.MultiWriter(&output, reporter)
+	}
 	cloneOptions := &git.CloneOptions{
 		URL:          gitURL,
 		SingleBranch: true,
 		Auth:         authMethod,
+		Progress:     progressWriter,
 	}
 
 	// If a specific branch is requested, set it in clone options
@@ -97,29 +115,67 @@ func (s *GitService) Clone(gitURL string, gitBranch string, gitAuth *domain.GitA
 		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(gitBranch)
 	}
 
-	_, err = git.PlainCloneContext(ctx, workingDir, cloneOptions)
+	var repo *git.Repository
+	attempts := 0
+	report := func(line string) {
+		output.WriteString(line + "\n")
+		if reporter != nil {
+			reporter.report(line)
+		}
+	}
+	err = s.retry("Clone", report, func() error {
+		attempts++
+		if attempts > 1 {
+			// The clone needs an empty directory
+			if err := clearDir(workingDir); err != nil {
+				return fmt.Errorf("failed to clean up after the failed clone: %w", err)
+			}
+		}
+
+		// Each attempt gets the full timeout
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
+		defer cancel()
+
+		var err error
+		repo, err = git.PlainCloneContext(ctx, workingDir, cloneOptions)
+		if reporter != nil {
+			// A fast clone may end without a line break, or report no progress at all
+			reporter.flush()
+		}
+		return maskError(err)
+	})
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "git",
 			"operation", "git_clone",
-			"git_url", gitURL,
+			"git_url", logURL(gitURL),
 			"git_branch", gitBranch,
 			"working_dir", workingDir,
 			"error", err)
-		return fmt.Errorf("failed to clone repository: %w", err)
+		return "", fmt.Errorf("failed to clone repository: %w", err)
 	}
 
-	slog.Info("Repository cloned successfully", "git_url", gitURL, "git_branch", gitBranch, "working_dir", workingDir)
-	return nil
+	slog.Info("Repository cloned successfully",
+		"git_url", logURL(gitURL),
+		"git_branch", gitBranch,
+		"working_dir", workingDir)
+
+	lines := progressLines(output.String())
+	if head, err := repo.Head(); err == nil {
+		commit := domain.ShortCommit(head.Hash().String())
+		lines = append(lines, fmt.Sprintf("Checked out %s at %s", head.Name().Short(), commit))
+	}
+	return strings.Join(lines, "\n"), nil
 }
 
 // Pull pulls latest changes from remote with optional authentication
 // Uses fetch + reset approach to handle force-pushes (equivalent to git fetch && git reset --hard origin/branch)
-func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, workingDir string) error {
+func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, workingDir string) (string, error) {
 	slog.Debug("Pulling repository changes", "git_branch", gitBranch, "working_dir", workingDir)
 
 	// First, fetch the latest changes from remote
-	err := s.Fetch(gitBranch, gitAuth, workingDir)
+	var progress strings.Builder
+	_, err := s.fetch(gitBranch, gitAuth, workingDir, &progress)
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "git",
@@ -127,7 +183,7 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"git_branch", gitBranch,
 			"working_dir", workingDir,
 			"error", err)
-		return fmt.Errorf("failed to fetch changes: %w", err)
+		return "", fmt.Errorf("failed to fetch changes: %w", err)
 	}
 
 	// Open the repository
@@ -138,7 +194,7 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"operation", "git_pull",
 			"working_dir", workingDir,
 			"error", err)
-		return err
+		return "", err
 	}
 
 	// Get the worktree
@@ -149,7 +205,7 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"operation", "git_pull",
 			"working_dir", workingDir,
 			"error", err)
-		return err
+		return "", err
 	}
 
 	// Branch name is required - should never be empty in production
@@ -159,7 +215,7 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"operation", "git_pull",
 			"working_dir", workingDir,
 			"error", "git branch is required")
-		return fmt.Errorf("git branch is required")
+		return "", fmt.Errorf("git branch is required")
 	}
 	branchName := gitBranch
 
@@ -181,14 +237,14 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"working_dir", workingDir,
 			"remote_ref", remoteBranchName,
 			"error", err)
-		return fmt.Errorf("failed to get remote reference %s: %w", remoteBranchName, err)
+		return "", fmt.Errorf("failed to get remote reference %s: %w", remoteBranchName, err)
 	}
 
 	// Check if we're already up to date with the remote commit
 	upToDate := currentCommit == ref.Hash().String()
 
-	// Check for modified tracked files first (before checking untracked conflicts)
-	err = s.checkModifiedTrackedFiles(worktree)
+	// Detect drift: modified tracked files, and untracked files that incoming tracked files would overwrite
+	modifiedFiles, err := s.modifiedTrackedFiles(worktree)
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "git",
@@ -196,11 +252,9 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"git_branch", branchName,
 			"working_dir", workingDir,
 			"error", err)
-		return err
+		return "", err
 	}
-
-	// Check for conflicts between untracked files and incoming tracked files
-	err = s.checkUntrackedConflicts(repo, worktree, ref.Hash())
+	conflictingFiles, err := s.untrackedConflicts(repo, worktree, ref.Hash())
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "git",
@@ -209,11 +263,42 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"working_dir", workingDir,
 			"target_commit", ref.Hash().String(),
 			"error", err)
-		return err
+		return "", err
+	}
+
+	// By default, refuse to touch local changes. With reset on deploy, discard them instead.
+	var discarded []string
+	if len(modifiedFiles) > 0 || len(conflictingFiles) > 0 {
+		if !s.config.GitResetOnDeploy {
+			err = dirtyWorkingDirError(modifiedFiles, conflictingFiles)
+			slog.Error("Service operation failed",
+				"layer", "git",
+				"operation", "git_pull_check_dirty",
+				"git_branch", branchName,
+				"working_dir", workingDir,
+				"error", err)
+			return "", err
+		}
+
+		discarded, err = s.discardLocalChanges(worktree, workingDir, modifiedFiles, conflictingFiles)
+		if err != nil {
+			slog.Error("Service operation failed",
+				"layer", "git",
+				"operation", "git_pull_discard_changes",
+				"git_branch", branchName,
+				"working_dir", workingDir,
+				"error", err)
+			return "", err
+		}
+		slog.Warn("Discarded local changes in git working directory",
+			"git_branch", branchName,
+			"working_dir", workingDir,
+			"modified_files", modifiedFiles,
+			"conflicting_files", conflictingFiles)
 	}
 
 	// Reset worktree to match remote commit exactly
-	// At this point we've verified there are no modified tracked files and no conflicting untracked files
+	// At this point there are no modified tracked files and no conflicting untracked files
 	// We use MergeReset which, with the fix in PR #1540, preserves untracked files
 	err = worktree.Reset(&git.ResetOptions{
 		Mode:   git.MergeReset,
@@ -227,20 +312,23 @@ func (s *GitService) Pull(gitBranch string, gitAuth *domain.GitAuthConfig, worki
 			"working_dir", workingDir,
 			"target_commit", ref.Hash().String(),
 			"error", err)
-		return fmt.Errorf("failed to reset to %s: %w", ref.Hash().String(), err)
+		return "", fmt.Errorf("failed to reset to %s: %w", ref.Hash().String(), err)
 	}
 
+	output := append(progressLines(progress.String()), discarded...)
 	if upToDate {
 		slog.Debug("Working directory synchronized with remote", "git_branch", branchName, "working_dir", workingDir)
+		output = append(output, upToDateMessage)
 	} else {
 		slog.Info("Repository updated successfully",
 			"git_branch", branchName,
 			"working_dir", workingDir,
 			"from_commit", currentCommit,
 			"to_commit", ref.Hash().String())
+		output = append(output, describeUpdate(repo, currentCommit, ref.Hash())...)
 	}
 
-	return nil
+	return strings.Join(output, "\n"), nil
 }
 
 // GetLatestCommit returns the latest commit hash
@@ -268,8 +356,29 @@ func (s *GitService) GetLatestCommit(workingDir string) (string, error) {
 	return ref.Hash().String(), nil
 }
 
-// Fetch fetches the latest changes from remote without merging
-func (s *GitService) Fetch(gitBranch string, gitAuth *domain.GitAuthConfig, workingDir string) error {
+// Fetch fetches the latest changes from remote without merging, and returns the git output
+func (s *GitService) Fetch(gitBranch string, gitAuth *domain.GitAuthConfig, workingDir string) (string, error) {
+	var progress strings.Builder
+	upToDate, err := s.fetch(gitBranch, gitAuth, workingDir, &progress)
+	if err != nil {
+		return "", err
+	}
+
+	output := progressLines(progress.String())
+	if upToDate {
+		output = append(output, upToDateMessage)
+	}
+	return strings.Join(output, "\n"), nil
+}
+
+// fetch is Fetch with the progress reported by the remote written to progress. It reports
+// whether the remote-tracking branch was already up to date.
+func (s *GitService) fetch(
+	gitBranch string,
+	gitAuth *domain.GitAuthConfig,
+	workingDir string,
+	progress io.Writer,
+) (bool, error) {
 	slog.Debug("Fetching from Git repository", "git_branch", gitBranch, "working_dir", workingDir)
 
 	// Branch name is required - should never be empty in production
@@ -279,7 +388,7 @@ func (s *GitService) Fetch(gitBranch string, gitAuth *domain.GitAuthConfig, work
 			"operation", "git_fetch",
 			"working_dir", workingDir,
 			"error", "git branch is required")
-		return fmt.Errorf("git branch is required")
+		return false, fmt.Errorf("git branch is required")
 	}
 
 	repo, err := git.PlainOpen(workingDir)
@@ -290,26 +399,32 @@ func (s *GitService) Fetch(gitBranch string, gitAuth *domain.GitAuthConfig, work
 			"git_branch", gitBranch,
 			"working_dir", workingDir,
 			"error", err)
-		return err
+		return false, err
 	}
 
 	authMethod, err := s.createAuthMethod(gitAuth)
 	if err != nil {
-		return fmt.Errorf("failed to create auth method: %w", err)
+		return false, fmt.Errorf("failed to create auth method: %w", err)
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
-	defer cancel()
-
 	fetchOptions := &git.FetchOptions{
-		Auth: authMethod,
+		Auth:     authMethod,
+		Progress: progress,
 		RefSpecs: []config.RefSpec{
 			config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", gitBranch, gitBranch)),
 		},
 	}
 
-	err = repo.FetchContext(ctx, fetchOptions)
+	report := func(line string) {
+		_, _ = io.WriteString(progress, "\n"+line+"\n")
+	}
+	err = s.retry("Fetch", report, func() error {
+		// Each attempt gets the full timeout
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
+		defer cancel()
+
+		return maskError(repo.FetchContext(ctx, fetchOptions))
+	})
 	if err != nil && err != git.NoErrAlreadyUpToDate {
 		slog.Error("Service operation failed",
 			"layer", "git",
@@ -317,16 +432,16 @@ func (s *GitService) Fetch(gitBranch string, gitAuth *domain.GitAuthConfig, work
 			"git_branch", gitBranch,
 			"working_dir", workingDir,
 			"error", err)
-		return err
+		return false, err
 	}
 
 	if err == git.NoErrAlreadyUpToDate {
 		slog.Debug("Repository already up to date", "git_branch", gitBranch, "working_dir", workingDir)
-	} else {
-		slog.Info("Repository fetched successfully", "git_branch", gitBranch, "working_dir", workingDir)
+		return true, nil
 	}
 
-	return nil
+	slog.Info("Repository fetched successfully", "git_branch", gitBranch, "working_dir", workingDir)
+	return false, nil
 }
 
 // GetRemoteLatestCommit returns the latest commit hash from the remote branch
@@ -369,10 +484,75 @@ func (s *GitService) GetRemoteLatestCommit(workingDir string, gitBranch string)
 	return ref.Hash().String(), nil
 }
 
+// LocalRepository describes a git checkout found on the host
+type LocalRepository struct {
+	RemoteURL string // URL of the origin remote, or of the first remote if there is no origin
+	Branch    string // Checked out branch, empty when HEAD is detached
+}
+
+// InspectLocalRepository reads the remote URL and checked out branch of the repository at dir.
+// git.ErrRepositoryNotExists is returned, wrapped, when dir is not the root of a repository.
+func (s *GitService) InspectLocalRepository(dir string) (*LocalRepository, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", dir, err)
+	}
+
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		remotes, listErr := repo.Remotes()
+		if listErr != nil {
+			return nil, fmt.Errorf("failed to list remotes: %w", listErr)
+		}
+		if len(remotes) == 0 {
+			return nil, fmt.Errorf("repository has no remote")
+		}
+		slices.SortFunc(remotes, func(a, b *git.Remote) int {
+			return strings.Compare(a.Config().Name, b.Config().Name)
+		})
+		remote = remotes[0]
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("remote %s has no URL", remote.Config().Name)
+	}
+
+	info := &LocalRepository{RemoteURL: urls[0]}
+	if head, err := repo.Head(); err == nil && head.Name().IsBranch() {
+		info.Branch = head.Name().Short()
+	}
+	return info, nil
+}
+
+// SetRemoteURL points the origin remote of the repository at dir to gitURL, which fetches and pulls
+// use from then on. The configuration is only written when the URL changed.
+func (s *GitService) SetRemoteURL(dir, gitURL string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open repository %s: %w", dir, err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+	remote, ok := cfg.Remotes[git.DefaultRemoteName]
+	if !ok {
+		return fmt.Errorf("repository has no %s remote", git.DefaultRemoteName)
+	}
+	if slices.Equal(remote.URLs, []string{gitURL}) {
+		return nil
+	}
+	remote.URLs = []string{gitURL}
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update the URL of remote %s: %w", git.DefaultRemoteName, err)
+	}
+	return nil
+}
+
 // TestAuthentication tests Git authentication using ls-remote operation
 // This is more resistant to credential caching than clone operations
 func (s *GitService) TestAuthentication(gitURL string, gitAuth *domain.GitAuthConfig) error {
-	slog.Info("Testing Git authentication", "git_url", gitURL)
+	slog.Info("Testing Git authentication", "git_url", logURL(gitURL))
 
 	// Use ls-remote operation instead of clone to avoid credential caching
 	err := s.testGitLsRemote(gitURL, gitAuth)
@@ -380,12 +560,12 @@ func (s *GitService) TestAuthentication(gitURL string, gitAuth *domain.GitAuthCo
 		slog.Error("Git authentication test failed",
 			"layer", "service",
 			"operation", "test_git_authentication",
-			"git_url", gitURL,
+			"git_url", logURL(gitURL),
 			"error", err)
 		return err
 	}
 
-	slog.Info("Git authentication test successful", "git_url", gitURL)
+	slog.Info("Git authentication test successful", "git_url", logURL(gitURL))
 	return nil
 }
 
@@ -411,12 +591,12 @@ func (s *GitService) testGitLsRemote(gitURL string, gitAuth *domain.GitAuthConfi
 		Auth: authMethod,
 	})
 
-	return err
+	return maskError(err)
 }
 
 // GetDefaultBranch determines the default branch of a remote Git repository
 func (s *GitService) GetDefaultBranch(gitURL string, gitAuth *domain.GitAuthConfig) (string, error) {
-	slog.Info("Getting default branch", "git_url", gitURL)
+	slog.Info("Getting default branch", "git_url", logURL(gitURL))
 
 	// Create authentication method
 	authMethod, err := s.createAuthMethod(gitAuth)
@@ -437,11 +617,12 @@ func (s *GitService) GetDefaultBranch(gitURL string, gitAuth *domain.GitAuthConf
 	refs, err := remote.ListContext(ctx, &git.ListOptions{
 		Auth: authMethod,
 	})
+	err = maskError(err)
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "git",
 			"operation", "get_default_branch",
-			"git_url", gitURL,
+			"git_url", logURL(gitURL),
 			"error", err)
 		return "", fmt.Errorf("failed to list remote references: %w", err)
 	}
@@ -458,7 +639,7 @@ func (s *GitService) GetDefaultBranch(gitURL string, gitAuth *domain.GitAuthConf
 					slog.Info(
 						"Found default branch via symbolic reference",
 						"git_url",
-						gitURL,
+						logURL(gitURL),
 						"default_branch",
 						branchName,
 					)
@@ -470,7 +651,7 @@ func (s *GitService) GetDefaultBranch(gitURL string, gitAuth *domain.GitAuthConf
 					if otherRef.Hash() == ref.Hash() && otherRef.Name().IsBranch() {
 						branchName := otherRef.Name().Short()
 						slog.Info("Found default branch via commit hash",
-							"git_url", gitURL,
+							"git_url", logURL(gitURL),
 							"default_branch", branchName)
 						return branchName, nil
 					}
@@ -480,20 +661,99 @@ func (s *GitService) GetDefaultBranch(gitURL string, gitAuth *domain.GitAuthConf
 	}
 
 	// Could not determine default branch
-	slog.Error("Could not determine default branch", "git_url", gitURL)
-	return "", fmt.Errorf("could not determine default branch for repository %s", gitURL)
+	slog.Error("Could not determine default branch", "git_url", logURL(gitURL))
+	return "", fmt.Errorf("could not determine default branch for repository %s", logURL(gitURL))
 }
 
-// checkUntrackedConflicts checks if any untracked files would conflict with incoming tracked files
-func (s *GitService) checkUntrackedConflicts(
+// BranchExists reports whether the remote Git repository has the branch, like
+// 'git ls-remote --heads <url> <branch>'. An error means the remote could not be listed, e.g.
+// because authentication failed, not that the branch is missing.
+func (s *GitService) BranchExists(gitURL, gitBranch string, gitAuth *domain.GitAuthConfig) (bool, error) {
+	authMethod, err := s.createAuthMethod(gitAuth)
+	if err != nil {
+		return false, fmt.Errorf("failed to create auth method: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
+	defer cancel()
+
+	remote := git.NewRemote(nil, &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{gitURL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth: authMethod,
+	})
+	err = maskError(err)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return false, nil
+	}
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "git",
+			"operation", "branch_exists",
+			"git_url", logURL(gitURL),
+			"git_branch", gitBranch,
+			"error", err)
+		return false, fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(gitBranch)
+	return slices.ContainsFunc(refs, func(ref *plumbing.Reference) bool {
+		return ref.Name() == branchRef
+	}), nil
+}
+
+// GetBranchHead returns the commit the branch points to on the remote Git repository, like
+// 'git ls-remote <url> refs/heads/<branch>', without fetching anything
+func (s *GitService) GetBranchHead(gitURL, gitBranch string, gitAuth *domain.GitAuthConfig) (string, error) {
+	authMethod, err := s.createAuthMethod(gitAuth)
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth method: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitTimeout)
+	defer cancel()
+
+	remote := git.NewRemote(nil, &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{gitURL},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth: authMethod,
+	})
+	err = maskError(err)
+	if err != nil && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		slog.Error("Service operation failed",
+			"layer", "git",
+			"operation", "get_branch_head",
+			"git_url", logURL(gitURL),
+			"git_branch", gitBranch,
+			"error", err)
+		return "", fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(gitBranch)
+	for _, ref := range refs {
+		if ref.Name() == branchRef {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("branch '%s' not found on the remote", gitBranch)
+}
+
+// untrackedConflicts returns the untracked files that incoming tracked files would overwrite
+func (s *GitService) untrackedConflicts(
 	repo *git.Repository,
 	worktree *git.Worktree,
 	targetHash plumbing.Hash,
-) error {
+) ([]string, error) {
 	// Get current worktree status to find untracked files
 	status, err := worktree.Status()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree status: %w", err)
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
 	}
 
 	// Collect untracked files
@@ -506,18 +766,18 @@ func (s *GitService) checkUntrackedConflicts(
 
 	// If no untracked files, no conflicts possible
 	if len(untrackedFiles) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Get target commit to check which files it contains
 	targetCommit, err := repo.CommitObject(targetHash)
 	if err != nil {
-		return fmt.Errorf("failed to get target commit: %w", err)
+		return nil, fmt.Errorf("failed to get target commit: %w", err)
 	}
 
 	targetTree, err := targetCommit.Tree()
 	if err != nil {
-		return fmt.Errorf("failed to get target tree: %w", err)
+		return nil, fmt.Errorf("failed to get target tree: %w", err)
 	}
 
 	// Check if any untracked files exist in the target commit
@@ -529,23 +789,19 @@ func (s *GitService) checkUntrackedConflicts(
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to iterate target tree files: %w", err)
+		return nil, fmt.Errorf("failed to iterate target tree files: %w", err)
 	}
 
-	// If there are conflicts, return an error
-	if len(conflictingFiles) > 0 {
-		return fmt.Errorf("untracked files would be overwritten by checkout: %v", conflictingFiles)
-	}
-
-	return nil
+	slices.Sort(conflictingFiles)
+	return conflictingFiles, nil
 }
 
-// checkModifiedTrackedFiles checks if any tracked files have been modified (staged or unstaged)
-func (s *GitService) checkModifiedTrackedFiles(worktree *git.Worktree) error {
+// modifiedTrackedFiles returns the tracked files that have been modified (staged or unstaged)
+func (s *GitService) modifiedTrackedFiles(worktree *git.Worktree) ([]string, error) {
 	// Get current worktree status
 	status, err := worktree.Status()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree status: %w", err)
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
 	}
 
 	// Collect modified tracked files
@@ -562,10 +818,47 @@ func (s *GitService) checkModifiedTrackedFiles(worktree *git.Worktree) error {
 		}
 	}
 
-	// If there are modified tracked files, return an error
+	slices.Sort(modifiedFiles)
+	return modifiedFiles, nil
+}
+
+// dirtyWorkingDirError describes the local changes that prevent a pull
+func dirtyWorkingDirError(modifiedFiles, conflictingFiles []string) error {
+	const hint = "set git.reset_on_deploy to discard local changes on deploy"
 	if len(modifiedFiles) > 0 {
-		return fmt.Errorf("cannot pull with modified tracked files: %v", modifiedFiles)
+		return fmt.Errorf("cannot pull with modified tracked files: %v (%s)", modifiedFiles, hint)
 	}
+	return fmt.Errorf("untracked files would be overwritten by checkout: %v (%s)", conflictingFiles, hint)
+}
 
-	return nil
+// discardLocalChanges restores modified tracked files to the current commit, removes the untracked
+// files that the pull would overwrite and describes the discarded changes. Other untracked files are
+// kept, they may be data written by the services. A hard reset is not used because it would remove them.
+func (s *GitService) discardLocalChanges(
+	worktree *git.Worktree,
+	workingDir string,
+	modifiedFiles []string,
+	conflictingFiles []string,
+) ([]string, error) {
+	if len(modifiedFiles) > 0 {
+		err := worktree.Restore(&git.RestoreOptions{Staged: true, Worktree: true, Files: modifiedFiles})
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore modified tracked files: %w", err)
+		}
+	}
+	for _, file := range conflictingFiles {
+		if err := os.Remove(filepath.Join(workingDir, filepath.FromSlash(file))); err != nil {
+			return nil, fmt.Errorf("failed to remove untracked file %s: %w", file, err)
+		}
+	}
+
+	var discarded []string
+	if len(modifiedFiles) > 0 {
+		discarded = append(discarded, "Discarded local changes to tracked files: "+strings.Join(modifiedFiles, ", "))
+	}
+	if len(conflictingFiles) > 0 {
+		discarded = append(discarded,
+			"Removed untracked files that conflict with the remote branch: "+strings.Join(conflictingFiles, ", "))
+	}
+	return discarded, nil
 }
@@ -0,0 +1,109 @@
+package git_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-billy/v6/osfs"
+	githttp "github.com/go-git/go-git/v6/backend/http"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// serveRepos serves the repositories below dir over smart HTTP, requiring the given credentials
+func serveRepos(t *testing.T, dir, username, password string) string {
+	backend := githttp.NewBackend(transport.NewFilesystemLoader(osfs.New(dir), false))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestBranchExists(t *testing.T) {
+	gitService := setupGitService(t)
+
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	initRepoWithCommit(t, sourceDir, map[string]string{"compose.yaml": "services: {}\n"})
+	cmd := exec.Command("git", "branch", "release/1.x")
+	cmd.Dir = sourceDir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "Failed to create branch: %s", string(output))
+
+	t.Run("local repository", func(t *testing.T) {
+		exists, err := gitService.BranchExists(sourceDir, "release/1.x", nil)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = gitService.BranchExists(sourceDir, "relaese/1.x", nil)
+		require.NoError(t, err)
+		assert.False(t, exists, "A typo'd branch must not be found")
+
+		exists, err = gitService.BranchExists(sourceDir, "1.x", nil)
+		require.NoError(t, err)
+		assert.False(t, exists, "Only full branch names match")
+	})
+
+	t.Run("authenticated repository", func(t *testing.T) {
+		reposDir := filepath.Join(tempDir, "served")
+		cmd := exec.Command("git", "clone", "--bare", sourceDir, filepath.Join(reposDir, "app.git"))
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, "Failed to create bare clone: %s", string(output))
+
+		gitURL := serveRepos(t, reposDir, "deploy", "s3cret") + "/app.git"
+		auth := &domain.GitAuthConfig{HTTPAuth: &domain.GitHTTPAuthConfig{Username: "deploy", Password: "s3cret"}}
+
+		exists, err := gitService.BranchExists(gitURL, "main", auth)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = gitService.BranchExists(gitURL, "mian", auth)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		// Failing to list the remote is an error, not a missing branch
+		_, err = gitService.BranchExists(gitURL, "main", nil)
+		assert.Error(t, err)
+
+		wrongAuth := &domain.GitAuthConfig{HTTPAuth: &domain.GitHTTPAuthConfig{Username: "deploy", Password: "wrong"}}
+		_, err = gitService.BranchExists(gitURL, "main", wrongAuth)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetBranchHead(t *testing.T) {
+	gitService := setupGitService(t)
+
+	sourceDir := filepath.Join(t.TempDir(), "source")
+	initRepoWithCommit(t, sourceDir, map[string]string{"compose.yaml": "services: {}\n"})
+	initial := getCommitHash(t, sourceDir)
+	createBranch(t, sourceDir, "release")
+	addCommitToRepo(t, sourceDir, map[string]string{"a.txt": "a"})
+	head := getCommitHash(t, sourceDir)
+
+	commit, err := gitService.GetBranchHead(sourceDir, "main", nil)
+	require.NoError(t, err)
+	assert.Equal(t, head, commit)
+
+	commit, err = gitService.GetBranchHead(sourceDir, "release", nil)
+	require.NoError(t, err)
+	assert.Equal(t, initial, commit)
+
+	_, err = gitService.GetBranchHead(sourceDir, "missing", nil)
+	assert.EqualError(t, err, "branch 'missing' not found on the remote")
+
+	_, err = gitService.GetBranchHead(filepath.Join(t.TempDir(), "none"), "main", nil)
+	assert.ErrorContains(t, err, "failed to list remote references")
+}
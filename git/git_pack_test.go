@@ -36,8 +36,9 @@ func TestClone_PackFilePermissions(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Clone(bareRepo, "main", nil, cloneRepo)
+	output, err := gitService.Clone(bareRepo, "main", nil, cloneRepo, nil)
 	require.NoError(t, err, "Clone should succeed")
+	require.Contains(t, output, "Checked out main at "+getCommitHash(t, workRepo)[:8])
 
 	packDir := filepath.Join(cloneRepo, ".git", "objects", "pack")
 	entries, err := os.ReadDir(packDir)
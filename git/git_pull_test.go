@@ -267,8 +267,9 @@ func TestPull_NoRemoteChanges(t *testing.T) {
 	gitService := setupGitService(t)
 	initialHash := getCommitHash(t, localRepo)
 
-	err := gitService.Pull("main", nil, localRepo)
+	output, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with no changes")
+	require.True(t, strings.HasSuffix(output, "Already up to date."), "Output should report no changes: %q", output)
 
 	finalHash := getCommitHash(t, localRepo)
 	require.Equal(t, initialHash, finalHash, "Commit hash should remain the same")
@@ -298,12 +299,19 @@ func TestPull_RemoteHasChanges(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	output, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with remote changes")
 
 	finalHash := getCommitHash(t, localRepo)
 	require.NotEqual(t, initialHash, finalHash, "Commit hash should change")
 	require.Equal(t, getCommitHash(t, workRepo), finalHash, "Commit hash should match remote")
+
+	// The output describes the update like git pull does
+	require.Contains(t, output, "Updating "+initialHash[:8]+".."+finalHash[:8])
+	require.Contains(t, output, "Fast-forward")
+	require.Contains(t, output, " A file2.txt")
+	require.Contains(t, output, " 1 file changed")
+	require.NotContains(t, output, "Already up to date.")
 	require.True(t, fileExists(t, localRepo, "file1.txt"), "file1.txt should exist")
 	require.True(t, fileExists(t, localRepo, "file2.txt"), "file2.txt should exist")
 	require.Equal(t, "content2", fileContent(t, localRepo, "file2.txt"), "file2.txt content should match")
@@ -326,7 +334,7 @@ func TestPull_NoRemoteChanges_WithUntrackedFiles(t *testing.T) {
 	createUntrackedFile(t, localRepo, "untracked.txt", "untracked content")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with untracked files")
 
 	require.True(t, fileExists(t, localRepo, "untracked.txt"), "untracked.txt should still exist")
@@ -360,7 +368,7 @@ func TestPull_RemoteHasChanges_WithUntrackedFiles(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with remote changes and untracked files")
 
 	require.True(t, fileExists(t, localRepo, "untracked.txt"), "untracked.txt should still exist")
@@ -390,7 +398,7 @@ func TestPull_NoRemoteChanges_WithTrackedFilesChanged(t *testing.T) {
 	modifyTrackedFile(t, localRepo, "file1.txt", "modified content")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail when tracked files are modified")
 	require.Contains(
 		t,
@@ -430,7 +438,7 @@ func TestPull_RemoteHasChanges_WithTrackedFilesChanged(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail when tracked files are modified")
 	require.Contains(
 		t,
@@ -464,7 +472,7 @@ func TestPull_RemoteAndLocalModifySameFile(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail when tracked files are modified")
 	require.Contains(
 		t,
@@ -496,7 +504,7 @@ func TestPull_RemoteDeletesFile(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with file deletion")
 
 	require.True(t, fileExists(t, localRepo, "file1.txt"), "file1.txt should still exist")
@@ -522,7 +530,7 @@ func TestPull_RemoteRenamesFile(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with file rename")
 
 	require.False(t, fileExists(t, localRepo, "oldname.txt"), "oldname.txt should not exist")
@@ -560,7 +568,7 @@ func TestPull_MultipleCommitsAhead(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with multiple commits")
 
 	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Should be at latest commit")
@@ -587,7 +595,7 @@ func TestPull_WithStagedChanges(t *testing.T) {
 	stageFile(t, localRepo, "file1.txt")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail with staged changes")
 	require.Contains(
 		t,
@@ -623,7 +631,7 @@ func TestPull_WithDivergedHistory(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed with diverged history")
 
 	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Should match remote commit")
@@ -666,7 +674,7 @@ func TestPull_AfterForcePush(t *testing.T) {
 	require.NoError(t, err, "Failed to force push: %s", string(output))
 
 	gitService := setupGitService(t)
-	err = gitService.Pull("main", nil, localRepo)
+	_, err = gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed after force push")
 
 	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Should match new remote history")
@@ -696,7 +704,7 @@ func TestPull_UntrackedFileConflictsWithIncoming(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail when untracked file conflicts with incoming tracked file")
 	require.Contains(
 		t,
@@ -731,7 +739,7 @@ func TestPull_FromDetachedHead(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed from detached HEAD")
 
 	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Should be at latest commit")
@@ -775,7 +783,7 @@ func TestPull_FromNonDefaultBranch(t *testing.T) {
 	pushToRemote(t, workRepo, "main")
 
 	gitService := setupGitService(t)
-	err = gitService.Pull("main", nil, localRepo)
+	_, err = gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed from non-default branch")
 
 	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Should be at latest main commit")
@@ -815,7 +823,7 @@ func TestPull_FromNonDefaultBranch_WithUntrackedFiles(t *testing.T) {
 	createUntrackedFile(t, localRepo, "untracked.txt", "untracked content")
 
 	gitService := setupGitService(t)
-	err = gitService.Pull("main", nil, localRepo)
+	_, err = gitService.Pull("main", nil, localRepo)
 	require.NoError(t, err, "Pull should succeed from non-default branch with untracked files")
 
 	require.True(t, fileExists(t, localRepo, "untracked.txt"), "untracked.txt should be preserved")
@@ -859,7 +867,7 @@ func TestPull_FromNonDefaultBranch_WithChangedTrackedFiles(t *testing.T) {
 	modifyTrackedFile(t, localRepo, "feature.txt", "modified feature content")
 
 	gitService := setupGitService(t)
-	err = gitService.Pull("main", nil, localRepo)
+	_, err = gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail when tracked files are modified")
 	require.Contains(
 		t,
@@ -906,7 +914,7 @@ func TestPull_WhenTrackedBranchDeletedOnRemote(t *testing.T) {
 	require.NoError(t, err, "Failed to delete remote branch: %s", string(output))
 
 	gitService := setupGitService(t)
-	err = gitService.Pull("feature", nil, localRepo)
+	_, err = gitService.Pull("feature", nil, localRepo)
 	require.Error(t, err, "Pull should fail when remote branch is deleted")
 }
 
@@ -928,7 +936,7 @@ func TestPull_WithUncommittedDeletionOfTrackedFile(t *testing.T) {
 	deleteFile(t, localRepo, "file2.txt")
 
 	gitService := setupGitService(t)
-	err := gitService.Pull("main", nil, localRepo)
+	_, err := gitService.Pull("main", nil, localRepo)
 	require.Error(t, err, "Pull should fail with uncommitted deletion")
 	require.Contains(
 		t,
@@ -939,3 +947,24 @@ func TestPull_WithUncommittedDeletionOfTrackedFile(t *testing.T) {
 
 	require.False(t, fileExists(t, localRepo, "file2.txt"), "Deleted file should remain deleted")
 }
+
+func TestFetch_Output(t *testing.T) {
+	_, workRepo, localRepo := setupTestRepos(t, map[string]string{
+		"file1.txt": "content1",
+	})
+	gitService := setupGitService(t)
+
+	output, err := gitService.Fetch("main", nil, localRepo)
+	require.NoError(t, err, "Fetch should succeed with no changes")
+	require.Equal(t, "Already up to date.", output)
+
+	addCommitToRepo(t, workRepo, map[string]string{
+		"file2.txt": "content2",
+	})
+	pushToRemote(t, workRepo, "main")
+
+	output, err = gitService.Fetch("main", nil, localRepo)
+	require.NoError(t, err, "Fetch should succeed with remote changes")
+	require.NotContains(t, output, "Already up to date.")
+	require.False(t, fileExists(t, localRepo, "file2.txt"), "Fetch should not update the working directory")
+}
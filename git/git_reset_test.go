@@ -0,0 +1,103 @@
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/git"
+)
+
+func setupResettingGitService(t *testing.T) *git.GitService {
+	cfg := &config.Config{
+		GitTimeout:       30 * time.Second,
+		GitResetOnDeploy: true,
+	}
+	return git.NewGitService(cfg)
+}
+
+func TestPull_DirtyWorkingDir_FailsWithHint(t *testing.T) {
+	_, workRepo, localRepo := setupTestRepos(t, map[string]string{
+		"file1.txt": "content1",
+	})
+	modifyTrackedFile(t, localRepo, "file1.txt", "local modification")
+
+	addCommitToRepo(t, workRepo, map[string]string{
+		"file2.txt": "content2",
+	})
+	pushToRemote(t, workRepo, "main")
+
+	gitService := setupGitService(t)
+	_, err := gitService.Pull("main", nil, localRepo)
+	require.Error(t, err, "Pull should fail when the working directory is dirty")
+	require.Contains(t, err.Error(), "[file1.txt]", "Error should list the modified files")
+	require.Contains(t, err.Error(), "git.reset_on_deploy", "Error should point to the reset setting")
+
+	require.Equal(t, "local modification", fileContent(t, localRepo, "file1.txt"), "Local changes should be kept")
+	require.False(t, fileExists(t, localRepo, "file2.txt"), "Remote changes should not be pulled")
+}
+
+func TestPull_ResetOnDeploy_DiscardsModifiedTrackedFiles(t *testing.T) {
+	_, workRepo, localRepo := setupTestRepos(t, map[string]string{
+		"file1.txt": "content1",
+		"file2.txt": "content2",
+	})
+	modifyTrackedFile(t, localRepo, "file1.txt", "local modification")
+	stageFile(t, localRepo, "file1.txt")
+	deleteFile(t, localRepo, "file2.txt")
+	createUntrackedFile(t, localRepo, "data/state.db", "service data")
+
+	addCommitToRepo(t, workRepo, map[string]string{
+		"file3.txt": "content3",
+	})
+	pushToRemote(t, workRepo, "main")
+
+	gitService := setupResettingGitService(t)
+	output, err := gitService.Pull("main", nil, localRepo)
+	require.NoError(t, err, "Pull should discard local changes")
+	require.Contains(t, output, "Discarded local changes to tracked files: file1.txt, file2.txt")
+
+	require.Equal(t, getCommitHash(t, workRepo), getCommitHash(t, localRepo), "Commit hash should match remote")
+	require.Equal(t, "content1", fileContent(t, localRepo, "file1.txt"), "file1.txt should be restored")
+	require.Equal(t, "content2", fileContent(t, localRepo, "file2.txt"), "file2.txt should be restored")
+	require.True(t, fileExists(t, localRepo, "file3.txt"), "file3.txt should be pulled")
+	require.Equal(t, "service data", fileContent(t, localRepo, "data/state.db"), "Untracked files should be kept")
+}
+
+func TestPull_ResetOnDeploy_WithoutRemoteChanges(t *testing.T) {
+	_, _, localRepo := setupTestRepos(t, map[string]string{
+		"file1.txt": "content1",
+	})
+	modifyTrackedFile(t, localRepo, "file1.txt", "local modification")
+
+	gitService := setupResettingGitService(t)
+	output, err := gitService.Pull("main", nil, localRepo)
+	require.NoError(t, err, "Pull should discard local changes")
+	require.Contains(t, output, "Discarded local changes to tracked files: file1.txt")
+	require.Contains(t, output, "Already up to date.")
+	require.Equal(t, "content1", fileContent(t, localRepo, "file1.txt"), "file1.txt should be restored")
+}
+
+func TestPull_ResetOnDeploy_RemovesOnlyConflictingUntrackedFiles(t *testing.T) {
+	_, workRepo, localRepo := setupTestRepos(t, map[string]string{
+		"file1.txt": "content1",
+	})
+	createUntrackedFile(t, localRepo, "newfile.txt", "local untracked content")
+	createUntrackedFile(t, localRepo, "data/state.db", "service data")
+
+	addCommitToRepo(t, workRepo, map[string]string{
+		"newfile.txt": "remote tracked content",
+	})
+	pushToRemote(t, workRepo, "main")
+
+	gitService := setupResettingGitService(t)
+	output, err := gitService.Pull("main", nil, localRepo)
+	require.NoError(t, err, "Pull should remove the conflicting untracked file")
+	require.Contains(t, output, "Removed untracked files that conflict with the remote branch: newfile.txt")
+	require.NotContains(t, output, "Discarded local changes to tracked files")
+
+	require.Equal(t, "remote tracked content", fileContent(t, localRepo, "newfile.txt"))
+	require.Equal(t, "service data", fileContent(t, localRepo, "data/state.db"), "Other untracked files should be kept")
+}
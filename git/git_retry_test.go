@@ -0,0 +1,108 @@
+package git_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v6/osfs"
+	githttp "github.com/go-git/go-git/v6/backend/http"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/git"
+)
+
+// serveFlakyRepos serves the repositories below dir over smart HTTP, answering the first failures
+// requests with status instead. It returns the server URL and the number of requests served.
+func serveFlakyRepos(t *testing.T, dir string, status int, failures *atomic.Int32) (string, *atomic.Int32) {
+	backend := githttp.NewBackend(transport.NewFilesystemLoader(osfs.New(dir), false))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failures.Add(-1) >= 0 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, &requests
+}
+
+func TestRetryTransientFailures(t *testing.T) {
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	initRepoWithCommit(t, sourceDir, map[string]string{"compose.yaml": "services: {}\n"})
+
+	gitService := git.NewGitService(&config.Config{
+		GitTimeout:    30 * time.Second,
+		GitRetries:    2,
+		GitRetryDelay: 10 * time.Millisecond,
+	})
+
+	t.Run("clone succeeds after a transient failure", func(t *testing.T) {
+		var failures atomic.Int32
+		failures.Store(1)
+		serverURL, _ := serveFlakyRepos(t, tempDir, http.StatusServiceUnavailable, &failures)
+
+		outputChan := make(chan docker.StreamMessage, 100)
+		cloneDir := filepath.Join(t.TempDir(), "clone")
+		output, err := gitService.Clone(serverURL+"/source", "main", nil, cloneDir, outputChan)
+		require.NoError(t, err)
+		close(outputChan)
+
+		assert.Contains(t, output, "Checked out main at")
+		assert.Contains(t, output, "Clone failed:")
+		var streamed []string
+		for msg := range outputChan {
+			streamed = append(streamed, msg.Content)
+		}
+		require.NotEmpty(t, streamed)
+		assert.True(t, strings.HasPrefix(streamed[0], "Clone failed:"), "retry not announced: %v", streamed)
+		assert.Contains(t, streamed[0], "Retrying in 10ms (1 of 2)...")
+		assert.Equal(t, getCommitHash(t, sourceDir), getCommitHash(t, cloneDir))
+	})
+
+	t.Run("fetch succeeds after a transient failure", func(t *testing.T) {
+		cloneDir := filepath.Join(t.TempDir(), "clone")
+		var failures atomic.Int32
+		serverURL, _ := serveFlakyRepos(t, tempDir, http.StatusBadGateway, &failures)
+		_, err := gitService.Clone(serverURL+"/source", "main", nil, cloneDir, nil)
+		require.NoError(t, err)
+
+		addCommitToRepo(t, sourceDir, map[string]string{"compose.yaml": "services:\n  web: {}\n"})
+		failures.Store(2)
+		output, err := gitService.Pull("main", nil, cloneDir)
+		require.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(output, "Fetch failed:"), output)
+		assert.Equal(t, getCommitHash(t, sourceDir), getCommitHash(t, cloneDir))
+	})
+
+	t.Run("gives up once retries are spent", func(t *testing.T) {
+		var failures atomic.Int32
+		failures.Store(10)
+		serverURL, requests := serveFlakyRepos(t, tempDir, http.StatusServiceUnavailable, &failures)
+
+		_, err := gitService.Clone(serverURL+"/source", "main", nil, filepath.Join(t.TempDir(), "clone"), nil)
+		require.Error(t, err)
+		assert.Equal(t, int32(3), requests.Load(), "one attempt and two retries")
+	})
+
+	t.Run("authentication errors are not retried", func(t *testing.T) {
+		var failures atomic.Int32
+		failures.Store(10)
+		serverURL, requests := serveFlakyRepos(t, tempDir, http.StatusUnauthorized, &failures)
+
+		_, err := gitService.Clone(serverURL+"/source", "main", nil, filepath.Join(t.TempDir(), "clone"), nil)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
@@ -0,0 +1,151 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/utils/merkletrie"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// upToDateMessage is the pull output when the remote branch has no new commits
+const upToDateMessage = "Already up to date."
+
+// progressLines turns the progress written by the remote during a fetch into lines. Progress
+// updates overwrite the current line with a carriage return, only the final state is kept.
+func progressLines(progress string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(progress, "\n") {
+		if i := strings.LastIndexByte(strings.TrimRight(line, "\r"), '\r'); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// progressReporter is the progress writer of a clone that reports lines as they arrive. Each
+// phase, such as "Receiving objects", rewrites its line with a carriage return for every update,
+// so only the first and the final state of a phase are sent to output.
+type progressReporter struct {
+	output  chan<- docker.StreamMessage
+	pending []byte
+	phase   string
+}
+
+func (w *progressReporter) report(line string) {
+	w.output <- docker.StreamMessage{Type: "stdout", Content: line}
+}
+
+// flush reports the last line when the progress did not end with a line break
+func (w *progressReporter) flush() {
+	if line := strings.TrimSpace(string(w.pending)); line != "" {
+		w.report(line)
+	}
+	w.pending = w.pending[:0]
+	w.phase = ""
+}
+
+func (w *progressReporter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != '\r' && b != '\n' {
+			w.pending = append(w.pending, b)
+			continue
+		}
+		line := strings.TrimSpace(string(w.pending))
+		w.pending = w.pending[:0]
+		if line == "" {
+			continue
+		}
+		phase, _, _ := strings.Cut(line, ":")
+		// The final state of a phase ends with "done.", and may end with a carriage return too
+		done := b == '\n' || strings.HasSuffix(line, "done.")
+		if phase != w.phase || done {
+			w.report(line)
+		}
+		w.phase = phase
+		if done {
+			w.phase = ""
+		}
+	}
+	return len(p), nil
+}
+
+// describeUpdate describes moving the working directory from one commit to another, in the
+// style of git pull: the commit range, whether it was a fast-forward and the changed files
+func describeUpdate(repo *git.Repository, from string, to plumbing.Hash) []string {
+	lines := []string{fmt.Sprintf("Updating %s..%s", domain.ShortCommit(from), domain.ShortCommit(to.String()))}
+
+	fromCommit, err := repo.CommitObject(plumbing.NewHash(from))
+	if err != nil {
+		return lines
+	}
+	toCommit, err := repo.CommitObject(to)
+	if err != nil {
+		return lines
+	}
+
+	if fastForward, err := fromCommit.IsAncestor(toCommit); err == nil && fastForward {
+		lines = append(lines, "Fast-forward")
+	} else {
+		lines = append(lines, "Forced update (local history was replaced by the remote branch)")
+	}
+
+	changes, err := fileChanges(fromCommit, toCommit)
+	if err != nil {
+		return lines
+	}
+	lines = append(lines, changes...)
+
+	switch len(changes) {
+	case 0:
+		lines = append(lines, " no files changed")
+	case 1:
+		lines = append(lines, " 1 file changed")
+	default:
+		lines = append(lines, fmt.Sprintf(" %d files changed", len(changes)))
+	}
+	return lines
+}
+
+// fileChanges lists the files changed from one commit to another, one per line as " A path" for an
+// added file, " D path" for a deleted one and " M path" for a modified one
+func fileChanges(from, to *object.Commit) ([]string, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			continue
+		}
+		switch action {
+		case merkletrie.Insert:
+			lines = append(lines, " A "+change.To.Name)
+		case merkletrie.Delete:
+			lines = append(lines, " D "+change.From.Name)
+		case merkletrie.Modify:
+			lines = append(lines, " M "+change.To.Name)
+		}
+	}
+	return lines, nil
+}
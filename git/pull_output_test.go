@@ -0,0 +1,65 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// receivedLines drains output and returns the content of the messages sent to it
+func receivedLines(t *testing.T, output chan docker.StreamMessage) []string {
+	t.Helper()
+	close(output)
+	var lines []string
+	for msg := range output {
+		assert.Equal(t, "stdout", msg.Type)
+		lines = append(lines, msg.Content)
+	}
+	return lines
+}
+
+func TestProgressReporter(t *testing.T) {
+	output := make(chan docker.StreamMessage, 100)
+	w := &progressReporter{output: output}
+
+	// Written in pieces that split lines, like the sideband of a remote
+	chunks := []string{
+		"Enumerating objects: 5, done.\n",
+		"Counting objects:  20% (1/5)\rCounting objects:  40% (2/5)\rCount",
+		"ing objects: 100% (5/5)\rCounting objects: 100% (5/5), done.\n",
+		"Receiving objects:  50% (1/2)\r",
+		"Receiving objects: 100% (2/2), done.\r\n",
+	}
+	for _, chunk := range chunks {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+	w.flush()
+
+	assert.Equal(t, []string{
+		"Enumerating objects: 5, done.",
+		"Counting objects:  20% (1/5)",
+		"Counting objects: 100% (5/5), done.",
+		"Receiving objects:  50% (1/2)",
+		"Receiving objects: 100% (2/2), done.",
+	}, receivedLines(t, output))
+}
+
+func TestProgressReporterFlush(t *testing.T) {
+	// A clone that finishes before the remote ends its last line
+	output := make(chan docker.StreamMessage, 10)
+	w := &progressReporter{output: output}
+	_, err := w.Write([]byte("Total 3 (delta 0), reused 0 (delta 0)"))
+	require.NoError(t, err)
+	w.flush()
+	assert.Equal(t, []string{"Total 3 (delta 0), reused 0 (delta 0)"}, receivedLines(t, output))
+
+	// A clone without any progress, such as one from a local path, sends nothing
+	output = make(chan docker.StreamMessage, 10)
+	(&progressReporter{output: output}).flush()
+	assert.Empty(t, receivedLines(t, output))
+}
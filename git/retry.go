@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// permanentMessages are parts of the errors a remote reports, e.g. on the stderr of git over SSH, that
+// retrying does not fix
+var permanentMessages = []string{
+	"unable to authenticate",
+	"permission denied",
+	"access denied",
+	"not authorized",
+	"authentication",
+	"could not read username",
+	"repository not found",
+	"does not appear to be a git repository",
+	"couldn't find remote ref",
+}
+
+// transientMessages are parts of the errors a remote reports when it is overloaded or the connection
+// broke, which may not happen again
+var transientMessages = []string{
+	"connection reset",
+	"connection refused",
+	"connection timed out",
+	"broken pipe",
+	"unexpected eof",
+	"early eof",
+	"temporarily unavailable",
+	"temporary failure",
+	"try again",
+	"too many requests",
+	"service unavailable",
+	"bad gateway",
+	"gateway timeout",
+	"internal server error",
+	"handshake failed: eof",
+	"the remote end hung up unexpectedly",
+}
+
+// isTransient reports whether a clone, pull or fetch failed for a reason that may be gone on the next
+// attempt, such as a dropped connection or an overloaded host. Failed authentication, a missing
+// repository or branch and a spent timeout are not transient.
+func isTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, permanent := range []error{
+		context.Canceled,
+		context.DeadlineExceeded,
+		transport.ErrAuthenticationRequired,
+		transport.ErrAuthorizationFailed,
+		transport.ErrRepositoryNotFound,
+		transport.ErrEmptyRemoteRepository,
+		transport.ErrInvalidAuthMethod,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+
+	var statusErr *http.Err
+	if errors.As(err, &statusErr) {
+		status := statusErr.StatusCode()
+		return status == nethttp.StatusRequestTimeout || status == nethttp.StatusTooManyRequests || status >= 500
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		// A host that does not exist is a mistake, a failing resolver is not
+		return !dnsErr.IsNotFound
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// What the remote reported is only known by its message
+	message := strings.ToLower(err.Error())
+	for _, permanent := range permanentMessages {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs attempt, and runs it again while it fails with a transient error and retries are left,
+// waiting the configured delay before the first retry and twice as long before each further one. Each
+// retry is announced to report, so that it shows in the output of the operation.
+func (s *GitService) retry(operation string, report func(line string), attempt func() error) error {
+	delay := s.config.GitRetryDelay
+	for retry := 1; ; retry++ {
+		err := attempt()
+		if err == nil || retry > s.config.GitRetries || !isTransient(err) {
+			return err
+		}
+		slog.Warn("Git operation failed with a transient error, retrying",
+			"layer", "git",
+			"operation", operation,
+			"retry", retry,
+			"retries", s.config.GitRetries,
+			"delay", delay,
+			"error", err)
+		report(fmt.Sprintf("%s failed: %v. Retrying in %s (%d of %d)...",
+			operation, err, delay, retry, s.config.GitRetries))
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// clearDir removes the contents of dir, such as what a failed clone left behind, keeping dir itself
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
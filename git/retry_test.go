@@ -0,0 +1,48 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsTransient(t *testing.T) {
+	remote, _ := url.Parse("https://example.com/repo.git")
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no error", nil, false},
+		{"unexpected EOF", fmt.Errorf("failed to read pack: %w", io.ErrUnexpectedEOF), true},
+		{"connection reset", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true},
+		{"resolver failure", &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}, true},
+		{"unknown host", &net.DNSError{Err: "no such host", Name: "exmaple.com", IsNotFound: true}, false},
+		{"service unavailable", &http.Err{URL: remote, Status: 503}, true},
+		{"rate limited", &http.Err{URL: remote, Status: 429}, true},
+		{"bad request", &http.Err{URL: remote, Status: 400}, false},
+		{"authentication required", fmt.Errorf("%w: %w", transport.ErrAuthenticationRequired,
+			&http.Err{URL: remote, Status: 401}), false},
+		{"repository not found", transport.ErrRepositoryNotFound, false},
+		{"timeout spent", context.DeadlineExceeded, false},
+		{"remote overloaded", transport.NewRemoteError("the server is temporarily unavailable, try again later"), true},
+		{"ssh authentication", errors.New("ssh: handshake failed: ssh: unable to authenticate"), false},
+		{"ssh connection dropped", errors.New("ssh: handshake failed: EOF"), true},
+		{"masked", maskError(fmt.Errorf("Get \"https://token@example.com\": %w", io.ErrUnexpectedEOF)), true},
+		{"unknown", errors.New("object not found"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransient(tt.err))
+		})
+	}
+}
@@ -34,7 +34,8 @@ UPGRADE_MODE=false
 CURRENT_VERSION="unknown"
 if [ -d "/opt/oar" ] && [ -f "/opt/oar/bin/oar" ]; then
     UPGRADE_MODE=true
-    CURRENT_VERSION=$(/opt/oar/bin/oar version 2>/dev/null || echo "unknown")
+    # The version is on the first line, followed by build details in newer releases
+    CURRENT_VERSION=$( (/opt/oar/bin/oar version 2>/dev/null || echo "unknown") | head -n 1)
 fi
 
 # Get latest release information
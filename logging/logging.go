@@ -3,6 +3,7 @@ package logging
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"slices"
@@ -33,16 +34,72 @@ func ValidLogLevels() []string {
 	return []string{"debug", "info", "warning", "error", "silent"}
 }
 
-// InitLogging initializes logging with the specified log level
+// ValidLogFormats returns the list of valid log formats
+func ValidLogFormats() []string {
+	return []string{"text", "json"}
+}
+
+// Options configures the level, format and destination of the logs
+type Options struct {
+	Level      string
+	Format     string // "text" (default) or "json"
+	File       string // Log file path, logs are written to stderr when empty
+	MaxSizeMB  int    // Size at which the log file is rotated, 0 disables rotation
+	MaxBackups int    // Number of rotated log files to keep
+}
+
+var (
+	// level is the log level of the default logger, it can be changed after Setup with SetLogLevel
+	level slog.LevelVar
+
+	// logFile is the file the default logger writes to, if any
+	logFile *rotatingFile
+)
+
+// InitLogging initializes text logging to stderr with the specified log level
 func InitLogging(logLevel string) {
-	level := ParseLogLevel(logLevel)
+	// Without a log file, Setup cannot fail
+	_ = Setup(Options{Level: logLevel})
+}
 
-	handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: level,
-	})
+// Setup initializes the default logger. A log file set up by an earlier call is closed. When the log
+// file cannot be opened, an error is returned and the default logger is left unchanged.
+func Setup(opts Options) error {
+	var w io.Writer = os.Stderr
+	var file *rotatingFile
+	if opts.File != "" {
+		var err error
+		file, err = openRotatingFile(opts.File, int64(opts.MaxSizeMB)*1024*1024, opts.MaxBackups)
+		if err != nil {
+			return err
+		}
+		w = file
+	}
+
+	level.Set(ParseLogLevel(opts.Level))
+	slog.SetDefault(slog.New(NewHandler(w, opts.Format, &level)))
+
+	if logFile != nil {
+		if err := logFile.Close(); err != nil {
+			slog.Debug("Failed to close previous log file", "error", err)
+		}
+	}
+	logFile = file
+	return nil
+}
+
+// NewHandler returns a handler writing to w in the given format, text unless format is "json"
+func NewHandler(w io.Writer, format string, level slog.Leveler) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
 
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
+// SetLogLevel changes the log level of the logger set up by Setup or InitLogging
+func SetLogLevel(logLevel string) {
+	level.Set(ParseLogLevel(logLevel))
 }
 
 // CLI flag for setting the log level
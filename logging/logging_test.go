@@ -0,0 +1,119 @@
+package logging_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/logging"
+)
+
+// restoreDefaultLogger closes the log file set up by the test and restores the previous logger
+func restoreDefaultLogger(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() {
+		logging.InitLogging("silent")
+		slog.SetDefault(previous)
+	})
+}
+
+func TestSetupJSONFile(t *testing.T) {
+	restoreDefaultLogger(t)
+
+	logPath := filepath.Join(t.TempDir(), "logs", "oar.log")
+	require.NoError(t, logging.Setup(logging.Options{Level: "info", Format: "json", File: logPath}))
+
+	slog.Debug("Hidden below the level", "project_id", "p1")
+	slog.Info("Project deployed", "project_id", "p1")
+	slog.Warn("Deployment slow", "project_id", "p2", "seconds", 42)
+
+	logging.SetLogLevel("debug")
+	slog.Debug("Shown after raising the level")
+
+	file, err := os.Open(logPath)
+	require.NoError(t, err)
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "Line should be JSON: %s", scanner.Text())
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, records, 3)
+	assert.Equal(t, "Project deployed", records[0]["msg"])
+	assert.Equal(t, "INFO", records[0]["level"])
+	assert.Equal(t, "p1", records[0]["project_id"])
+	assert.Equal(t, "WARN", records[1]["level"])
+	assert.Equal(t, float64(42), records[1]["seconds"])
+	assert.Equal(t, "Shown after raising the level", records[2]["msg"])
+}
+
+func TestSetupTextFormat(t *testing.T) {
+	restoreDefaultLogger(t)
+
+	logPath := filepath.Join(t.TempDir(), "oar.log")
+	require.NoError(t, logging.Setup(logging.Options{Level: "warning", File: logPath}))
+
+	slog.Info("Hidden below the level")
+	slog.Error("Service operation failed", "layer", "service")
+
+	content, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "Hidden below the level")
+	assert.Contains(t, string(content), `level=ERROR msg="Service operation failed" layer=service`)
+}
+
+func TestSetupRotatesLogFile(t *testing.T) {
+	restoreDefaultLogger(t)
+
+	logPath := filepath.Join(t.TempDir(), "oar.log")
+	require.NoError(t, logging.Setup(logging.Options{
+		Level:      "info",
+		Format:     "json",
+		File:       logPath,
+		MaxSizeMB:  1,
+		MaxBackups: 2,
+	}))
+
+	// About 3.5 MB of logs, enough to rotate more often than backups are kept
+	payload := strings.Repeat("x", 1000)
+	for i := range 3500 {
+		slog.Info("Filler", "i", i, "payload", payload)
+	}
+
+	for _, path := range []string{logPath, logPath + ".1", logPath + ".2"} {
+		info, err := os.Stat(path)
+		require.NoError(t, err, "%s should exist", path)
+		assert.LessOrEqual(t, info.Size(), int64(1024*1024), "%s should not exceed the maximum size", path)
+	}
+	assert.NoFileExists(t, logPath+".3", "Only the configured number of backups should be kept")
+
+	// Every line of a rotated file is complete
+	content, err := os.ReadFile(logPath + ".1")
+	require.NoError(t, err)
+	for line := range strings.Lines(string(content)) {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+	}
+}
+
+func TestSetupFailsForUnwritableFile(t *testing.T) {
+	restoreDefaultLogger(t)
+
+	// A directory cannot be opened as the log file
+	err := logging.Setup(logging.Options{Level: "info", File: t.TempDir()})
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+// Package maintenance runs periodic housekeeping tasks, such as pruning history tables.
+package maintenance
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Task is a maintenance job. Run returns the number of records it removed.
+type Task struct {
+	Name string
+	Run  func(ctx context.Context) (int, error)
+}
+
+// Result is the outcome of one run of a task
+type Result struct {
+	Task    string
+	Removed int
+	Err     error
+}
+
+// Scheduler runs every task once per interval. New history tables register their retention task
+// here, so that all pruning happens in one place.
+type Scheduler struct {
+	interval time.Duration
+	tasks    []Task
+}
+
+func NewScheduler(interval time.Duration, tasks ...Task) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		tasks:    tasks,
+	}
+}
+
+// Interval returns how often the tasks run, 0 when they only run on request
+func (s *Scheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// RunAll runs each task once and logs what it removed. A failing task does not stop the others.
+func (s *Scheduler) RunAll(ctx context.Context) []Result {
+	results := make([]Result, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if ctx.Err() != nil {
+			break
+		}
+
+		start := time.Now()
+		removed, err := task.Run(ctx)
+		if err != nil {
+			slog.Error("Service operation failed",
+				"layer", "maintenance",
+				"operation", task.Name,
+				"removed", removed,
+				"error", err)
+		} else {
+			slog.Info("Maintenance task finished",
+				"task", task.Name,
+				"removed", removed,
+				"duration", time.Since(start))
+		}
+		results = append(results, Result{Task: task.Name, Removed: removed, Err: err})
+	}
+	return results
+}
+
+// Start runs the tasks right away and then every interval until ctx is done. It returns
+// immediately when the interval is 0.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if s.interval <= 0 {
+		slog.Info("Periodic maintenance is disabled")
+		return nil
+	}
+	slog.Info("Maintenance scheduler starting", "interval", s.interval, "tasks", len(s.tasks))
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.RunAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Maintenance scheduler shutting down")
+			return nil
+		case <-ticker.C:
+			s.RunAll(ctx)
+		}
+	}
+}
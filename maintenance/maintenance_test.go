@@ -0,0 +1,176 @@
+package maintenance_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/maintenance"
+	"github.com/oar-cd/oar/repository"
+)
+
+func TestSchedulerRunAll(t *testing.T) {
+	var ran []string
+	task := func(name string, removed int, err error) maintenance.Task {
+		return maintenance.Task{Name: name, Run: func(context.Context) (int, error) {
+			ran = append(ran, name)
+			return removed, err
+		}}
+	}
+	scheduler := maintenance.NewScheduler(0,
+		task("deployments", 3, nil),
+		task("broken", 0, errors.New("database is locked")),
+		task("audit", 1, nil),
+	)
+
+	results := scheduler.RunAll(context.Background())
+
+	// A failing task does not stop the ones after it
+	assert.Equal(t, []string{"deployments", "broken", "audit"}, ran)
+	require.Len(t, results, 3)
+	assert.Equal(t, maintenance.Result{Task: "deployments", Removed: 3}, results[0])
+	assert.EqualError(t, results[1].Err, "database is locked")
+	assert.Equal(t, 1, results[2].Removed)
+
+	// Without an interval the scheduler only runs on request
+	assert.NoError(t, scheduler.Start(context.Background()))
+	assert.Len(t, ran, 3)
+}
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+	return database
+}
+
+func TestDeploymentRetention(t *testing.T) {
+	database := setupTestDB(t)
+	deployments := repository.NewDeploymentRepository(database)
+	now := time.Now()
+
+	newProject := func(name string) uuid.UUID {
+		m := db.ProjectModel{
+			BaseModel:    db.BaseModel{ID: uuid.New()},
+			Name:         name,
+			GitURL:       "https://example.com/" + name + ".git",
+			GitBranch:    "main",
+			WorkingDir:   "/tmp/" + name,
+			ComposeFiles: "compose.yaml",
+			Status:       "running",
+		}
+		require.NoError(t, database.Create(&m).Error)
+		return m.ID
+	}
+	// addDeployment records a deployment started age ago and returns its ID
+	addDeployment := func(projectID uuid.UUID, status domain.DeploymentStatus, age time.Duration) uuid.UUID {
+		deployment := domain.NewDeployment(projectID, "0123456789abcdef")
+		deployment.Status = status
+		require.NoError(t, deployments.Create(&deployment))
+		require.NoError(t, database.Model(&db.DeploymentModel{}).
+			Where("id = ?", deployment.ID).
+			Update("created_at", now.Add(-age)).Error)
+		return deployment.ID
+	}
+	remaining := func(projectID uuid.UUID) []uuid.UUID {
+		list, err := deployments.ListByProjectID(projectID)
+		require.NoError(t, err)
+		ids := make([]uuid.UUID, len(list))
+		for i, d := range list {
+			ids[i] = d.ID
+		}
+		return ids
+	}
+
+	// The running deployment is old and followed by failed attempts, one still in progress
+	billing := newProject("billing")
+	running := addDeployment(billing, domain.DeploymentStatusCompleted, 100*24*time.Hour)
+	addDeployment(billing, domain.DeploymentStatusCompleted, 120*24*time.Hour)
+	addDeployment(billing, domain.DeploymentStatusFailed, 90*24*time.Hour)
+	stuck := addDeployment(billing, domain.DeploymentStatusStarted, 60*24*time.Hour)
+	recentFailure := addDeployment(billing, domain.DeploymentStatusFailed, 2*24*time.Hour)
+	latest := addDeployment(billing, domain.DeploymentStatusFailed, time.Hour)
+
+	// Only old deployments, the latest one is kept
+	search := newProject("search")
+	searchLatest := addDeployment(search, domain.DeploymentStatusFailed, 40*24*time.Hour)
+	addDeployment(search, domain.DeploymentStatusFailed, 50*24*time.Hour)
+
+	// Retention disabled, nothing is removed
+	limits := maintenance.NewRetentionLimits(0, 0)
+	task := maintenance.DeploymentRetention(deployments, limits)
+	removed, err := task.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+
+	// A change of the limits applies from the next run
+	limits.Set(30*24*time.Hour, 0)
+	removed, err = task.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, []uuid.UUID{latest, recentFailure, stuck, running}, remaining(billing))
+	assert.Equal(t, []uuid.UUID{searchLatest}, remaining(search))
+
+	// The count limit applies per project and still keeps the relevant deployments
+	limits.Set(0, 1)
+	removed, err = task.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []uuid.UUID{latest, stuck, running}, remaining(billing))
+	assert.Equal(t, []uuid.UUID{searchLatest}, remaining(search))
+}
+
+func TestShareLinkRetention(t *testing.T) {
+	database := setupTestDB(t)
+	links := repository.NewShareLinkRepository(database)
+	now := time.Now()
+
+	project := db.ProjectModel{
+		BaseModel:    db.BaseModel{ID: uuid.New()},
+		Name:         "shared",
+		GitURL:       "https://example.com/shared.git",
+		GitBranch:    "main",
+		WorkingDir:   "/tmp/shared",
+		ComposeFiles: "compose.yaml",
+		Status:       "running",
+	}
+	require.NoError(t, database.Create(&project).Error)
+
+	// addLink creates a link expiring at expiresAt, revoked at revokedAt unless that is zero
+	addLink := func(expiresAt, revokedAt time.Time) uuid.UUID {
+		link := &domain.ShareLink{ID: uuid.New(), ProjectID: project.ID, ExpiresAt: expiresAt}
+		require.NoError(t, links.Create(link))
+		if !revokedAt.IsZero() {
+			require.NoError(t, links.Revoke(link.ID, revokedAt))
+		}
+		return link.ID
+	}
+	active := addLink(now.Add(time.Hour), time.Time{})
+	recentlyExpired := addLink(now.Add(-time.Hour), time.Time{})
+	recentlyRevoked := addLink(now.Add(24*time.Hour), now.Add(-time.Hour))
+	addLink(now.Add(-10*24*time.Hour), time.Time{})
+	addLink(now.Add(24*time.Hour), now.Add(-10*24*time.Hour))
+
+	task := maintenance.ShareLinkRetention(links, 7*24*time.Hour)
+	removed, err := task.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	remaining, err := links.ListByProjectID(project.ID, 0)
+	require.NoError(t, err)
+	ids := make([]uuid.UUID, len(remaining))
+	for i, link := range remaining {
+		ids[i] = link.ID
+	}
+	assert.ElementsMatch(t, []uuid.UUID{active, recentlyExpired, recentlyRevoked}, ids)
+}
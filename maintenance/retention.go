@@ -0,0 +1,67 @@
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oar-cd/oar/repository"
+)
+
+// RetentionLimits are the limits of the deployment retention. They can change while the scheduler
+// runs, when the configuration is reloaded; the next run of the task applies them.
+type RetentionLimits struct {
+	mu       sync.RWMutex
+	maxAge   time.Duration
+	maxCount int
+}
+
+func NewRetentionLimits(maxAge time.Duration, maxCount int) *RetentionLimits {
+	return &RetentionLimits{
+		maxAge:   maxAge,
+		maxCount: maxCount,
+	}
+}
+
+// Set replaces the limits
+func (l *RetentionLimits) Set(maxAge time.Duration, maxCount int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxAge = maxAge
+	l.maxCount = maxCount
+}
+
+// Get returns the maximum age and the maximum count per project, 0 when a limit is not set
+func (l *RetentionLimits) Get() (time.Duration, int) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.maxAge, l.maxCount
+}
+
+// DeploymentRetention returns the task that prunes deployments older than the maximum age or beyond
+// the maximum count most recent of each project. A zero limit is not applied. The deployment a
+// project runs, its latest one and those in progress are never removed.
+func DeploymentRetention(deployments repository.DeploymentRepository, limits *RetentionLimits) Task {
+	return Task{
+		Name: "deployments",
+		Run: func(ctx context.Context) (int, error) {
+			maxAge, maxCount := limits.Get()
+			var olderThan time.Time
+			if maxAge > 0 {
+				olderThan = time.Now().Add(-maxAge)
+			}
+			return deployments.Prune(olderThan, maxCount)
+		},
+	}
+}
+
+// ShareLinkRetention returns the task that deletes share links that expired or were revoked more
+// than keepFor ago. Such links no longer grant access and are only listed for reference.
+func ShareLinkRetention(links repository.ShareLinkRepository, keepFor time.Duration) Task {
+	return Task{
+		Name: "share links",
+		Run: func(ctx context.Context) (int, error) {
+			return links.Prune(time.Now().Add(-keepFor))
+		},
+	}
+}
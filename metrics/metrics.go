@@ -0,0 +1,217 @@
+// Package metrics keeps per-project deployment metrics and serves them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeployDurationBuckets are the upper bounds, in seconds, of the deployment duration histogram
+var DeployDurationBuckets = []float64{5, 10, 30, 60, 120, 300, 600, 1800}
+
+// Registry holds the metrics of all projects. They are updated as deployments finish and statuses are
+// fetched, so serving them does not query anything. A nil Registry ignores all updates.
+type Registry struct {
+	mu       sync.Mutex
+	projects map[uuid.UUID]*projectMetrics
+	lastPoll time.Time
+}
+
+// projectMetrics are the series of one project, labelled with its current name
+type projectMetrics struct {
+	name          string
+	bucketCounts  []uint64 // Deployments per bucket of DeployDurationBuckets, not cumulative
+	durationSum   float64
+	durationCount uint64
+	deploys       map[string]uint64 // Finished deployments by status
+	up            *bool             // Unset until a status was fetched
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{projects: make(map[uuid.UUID]*projectMetrics)}
+}
+
+// project returns the metrics of a project, creating them when needed. The caller holds the lock.
+func (r *Registry) project(projectID uuid.UUID, name string) *projectMetrics {
+	p := r.projects[projectID]
+	if p == nil {
+		p = &projectMetrics{
+			bucketCounts: make([]uint64, len(DeployDurationBuckets)),
+			deploys:      make(map[string]uint64),
+		}
+		r.projects[projectID] = p
+	}
+	p.name = name
+	return p
+}
+
+// ObserveDeployment records a finished deployment of a project with its status, e.g. completed or failed
+func (r *Registry) ObserveDeployment(projectID uuid.UUID, name, status string, duration time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p := r.project(projectID, name)
+	seconds := duration.Seconds()
+	if i := slices.IndexFunc(DeployDurationBuckets, func(bound float64) bool { return seconds <= bound }); i >= 0 {
+		p.bucketCounts[i]++
+	}
+	p.durationSum += seconds
+	p.durationCount++
+	p.deploys[status]++
+}
+
+// SetProjectUp records whether the containers of a project are running
+func (r *Registry) SetProjectUp(projectID uuid.UUID, name string, up bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.project(projectID, name).up = &up
+}
+
+// RemoveProject drops the series of a removed project
+func (r *Registry) RemoveProject(projectID uuid.UUID) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.projects, projectID)
+}
+
+// RetainProjects drops the series of all projects but the given ones and takes over their current
+// names. Projects removed by another process, e.g. the CLI, are cleaned up this way.
+func (r *Registry) RetainProjects(names map[uuid.UUID]string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for projectID, p := range r.projects {
+		name, ok := names[projectID]
+		if !ok {
+			delete(r.projects, projectID)
+			continue
+		}
+		p.name = name
+	}
+}
+
+// SetLastPoll records when the watcher last polled the projects
+func (r *Registry) SetLastPoll(t time.Time) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastPoll = t
+}
+
+// WriteText writes the metrics in the Prometheus text exposition format, with projects sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	projects := make([]projectMetrics, 0, len(r.projects))
+	for _, p := range r.projects {
+		copied := *p
+		copied.bucketCounts = slices.Clone(p.bucketCounts)
+		copied.deploys = make(map[string]uint64, len(p.deploys))
+		for status, count := range p.deploys {
+			copied.deploys[status] = count
+		}
+		projects = append(projects, copied)
+	}
+	lastPoll := r.lastPoll
+	r.mu.Unlock()
+
+	slices.SortFunc(projects, func(a, b projectMetrics) int { return strings.Compare(a.name, b.name) })
+
+	var b strings.Builder
+	writeHeader(&b, "oar_deploy_duration_seconds", "histogram", "Duration of finished deployments.")
+	for _, p := range projects {
+		if p.durationCount == 0 {
+			continue
+		}
+		project := `project="` + escapeLabel(p.name) + `"`
+		var cumulative uint64
+		for i, bound := range DeployDurationBuckets {
+			cumulative += p.bucketCounts[i]
+			fmt.Fprintf(&b, "oar_deploy_duration_seconds_bucket{%s,le=%q} %d\n",
+				project, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(&b, "oar_deploy_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", project, p.durationCount)
+		fmt.Fprintf(&b, "oar_deploy_duration_seconds_sum{%s} %s\n", project, formatFloat(p.durationSum))
+		fmt.Fprintf(&b, "oar_deploy_duration_seconds_count{%s} %d\n", project, p.durationCount)
+	}
+
+	writeHeader(&b, "oar_deploy_total", "counter", "Finished deployments by status.")
+	for _, p := range projects {
+		statuses := make([]string, 0, len(p.deploys))
+		for status := range p.deploys {
+			statuses = append(statuses, status)
+		}
+		slices.Sort(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "oar_deploy_total{project=\"%s\",status=\"%s\"} %d\n",
+				escapeLabel(p.name), escapeLabel(status), p.deploys[status])
+		}
+	}
+
+	writeHeader(&b, "oar_project_up", "gauge", "Whether the containers of the project are running (1) or not (0).")
+	for _, p := range projects {
+		if p.up == nil {
+			continue
+		}
+		up := 0
+		if *p.up {
+			up = 1
+		}
+		fmt.Fprintf(&b, "oar_project_up{project=\"%s\"} %d\n", escapeLabel(p.name), up)
+	}
+
+	if !lastPoll.IsZero() {
+		writeHeader(&b, "oar_watcher_last_poll_timestamp", "gauge",
+			"Unix time of the last poll of the watcher, in seconds.")
+		fmt.Fprintf(&b, "oar_watcher_last_poll_timestamp %d\n", lastPoll.Unix())
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the metrics to Prometheus
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+func writeHeader(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// escapeLabel escapes a label value as the text format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
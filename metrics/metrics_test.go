@@ -0,0 +1,100 @@
+package metrics_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/metrics"
+)
+
+func TestRegistryWriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+	shop, blog := uuid.New(), uuid.New()
+
+	registry.ObserveDeployment(shop, "shop", "completed", 20*time.Second)
+	registry.ObserveDeployment(shop, "shop", "failed", 90*time.Second)
+	registry.ObserveDeployment(blog, `blog "new"`, "completed", time.Hour)
+	registry.SetProjectUp(shop, "shop", true)
+	registry.SetProjectUp(blog, `blog "new"`, false)
+	registry.SetLastPoll(time.Unix(1700000000, 0))
+
+	var out strings.Builder
+	require.NoError(t, registry.WriteText(&out))
+	text := out.String()
+
+	for _, line := range []string{
+		"# TYPE oar_deploy_duration_seconds histogram",
+		`oar_deploy_duration_seconds_bucket{project="shop",le="10"} 0`,
+		`oar_deploy_duration_seconds_bucket{project="shop",le="30"} 1`,
+		`oar_deploy_duration_seconds_bucket{project="shop",le="120"} 2`,
+		`oar_deploy_duration_seconds_bucket{project="shop",le="+Inf"} 2`,
+		`oar_deploy_duration_seconds_sum{project="shop"} 110`,
+		`oar_deploy_duration_seconds_count{project="shop"} 2`,
+		`oar_deploy_duration_seconds_bucket{project="blog \"new\"",le="1800"} 0`,
+		`oar_deploy_duration_seconds_bucket{project="blog \"new\"",le="+Inf"} 1`,
+		"# TYPE oar_deploy_total counter",
+		`oar_deploy_total{project="shop",status="completed"} 1`,
+		`oar_deploy_total{project="shop",status="failed"} 1`,
+		`oar_deploy_total{project="blog \"new\"",status="completed"} 1`,
+		"# TYPE oar_project_up gauge",
+		`oar_project_up{project="shop"} 1`,
+		`oar_project_up{project="blog \"new\""} 0`,
+		"oar_watcher_last_poll_timestamp 1700000000",
+	} {
+		assert.Contains(t, text, line+"\n")
+	}
+
+	// Sorted by project name
+	assert.Less(t,
+		strings.Index(text, `oar_project_up{project="blog`),
+		strings.Index(text, `oar_project_up{project="shop"}`))
+}
+
+func TestRegistryDropsRemovedProjects(t *testing.T) {
+	registry := metrics.NewRegistry()
+	shop, blog, wiki := uuid.New(), uuid.New(), uuid.New()
+	registry.SetProjectUp(shop, "shop", true)
+	registry.SetProjectUp(blog, "blog", true)
+	registry.SetProjectUp(wiki, "wiki", true)
+
+	registry.RemoveProject(blog)
+	// The wiki was removed by another process and the shop renamed
+	registry.RetainProjects(map[uuid.UUID]string{shop: "store"})
+
+	var out strings.Builder
+	require.NoError(t, registry.WriteText(&out))
+	assert.Contains(t, out.String(), `oar_project_up{project="store"} 1`)
+	assert.NotContains(t, out.String(), `project="shop"`)
+	assert.NotContains(t, out.String(), `project="blog"`)
+	assert.NotContains(t, out.String(), `project="wiki"`)
+	assert.NotContains(t, out.String(), "oar_watcher_last_poll_timestamp")
+}
+
+func TestRegistryHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.ObserveDeployment(uuid.New(), "shop", "completed", time.Second)
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+	assert.Contains(t, recorder.Body.String(), `oar_deploy_total{project="shop",status="completed"} 1`)
+}
+
+func TestNilRegistryIgnoresUpdates(t *testing.T) {
+	var registry *metrics.Registry
+	assert.NotPanics(t, func() {
+		registry.ObserveDeployment(uuid.New(), "shop", "completed", time.Second)
+		registry.SetProjectUp(uuid.New(), "shop", true)
+		registry.RemoveProject(uuid.New())
+		registry.RetainProjects(nil)
+		registry.SetLastPoll(time.Now())
+	})
+}
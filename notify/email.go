@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes of an SMTP connection
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection with STARTTLS, usually on port 587
+	SMTPTLSImplicit = "tls"      // Connect with TLS from the start, usually on port 465
+	SMTPTLSNone     = "none"     // Never encrypt, only for relays on a trusted network
+)
+
+// SMTPConfig holds the settings for sending notifications by email
+type SMTPConfig struct {
+	Host     string
+	Port     int // 0 for the usual port of the TLS mode
+	Username string
+	Password string
+	From     string
+	To       []string
+	TLS      string // SMTPTLSStartTLS when empty
+}
+
+// tlsMode returns the TLS mode, defaulting to STARTTLS
+func (c SMTPConfig) tlsMode() string {
+	if c.TLS == "" {
+		return SMTPTLSStartTLS
+	}
+	return c.TLS
+}
+
+// address returns host:port, with the usual port of the TLS mode when no port is set
+func (c SMTPConfig) address() string {
+	port := c.Port
+	if port == 0 {
+		switch c.tlsMode() {
+		case SMTPTLSImplicit:
+			port = 465
+		case SMTPTLSNone:
+			port = 25
+		default:
+			port = 587
+		}
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// Validate checks that the settings are complete enough to send email
+func (c SMTPConfig) Validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("invalid email notification settings: SMTP host is required")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid email notification settings: invalid SMTP port %d", c.Port)
+	}
+	switch c.tlsMode() {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return fmt.Errorf("invalid email notification settings: invalid TLS mode %q (must be %s, %s or %s)",
+			c.TLS, SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone)
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid email notification settings: invalid sender %q: %w", c.From, err)
+	}
+	return ValidateRecipients(c.To)
+}
+
+// ValidateRecipients checks that there is at least one recipient and that every address is valid
+func ValidateRecipients(to []string) error {
+	if len(to) == 0 {
+		return fmt.Errorf("invalid email notification settings: at least one recipient is required")
+	}
+	for _, address := range to {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid email notification settings: invalid recipient %q: %w", address, err)
+		}
+	}
+	return nil
+}
+
+// emailTarget sends messages over SMTP
+type emailTarget struct {
+	config  SMTPConfig
+	rootCAs *x509.CertPool
+}
+
+// Send delivers the message to all recipients in one email. The context bounds the whole SMTP session.
+func (t *emailTarget) Send(ctx context.Context, msg Message) error {
+	c := t.config
+	tlsConfig := &tls.Config{ServerName: c.Host, RootCAs: t.rootCAs, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if c.tlsMode() == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", c.address())
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", c.address())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("failed to set SMTP deadline: %w", err)
+		}
+	}
+
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer func() {
+		// Quit already closed the connection when the email was sent
+		_ = client.Close()
+	}()
+
+	if c.tlsMode() == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	// PLAIN auth refuses to send credentials over an unencrypted connection, except to localhost
+	if c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(c.From)); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	for _, to := range c.To {
+		if err := client.Rcpt(envelopeAddress(to)); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start email data: %w", err)
+	}
+	if _, err := io.WriteString(w, formatEmail(c.From, c.To, msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected email: %w", err)
+	}
+	return client.Quit()
+}
+
+// String returns the recipients and server, without credentials
+func (t *emailTarget) String() string {
+	return fmt.Sprintf("email to %s via %s", strings.Join(t.config.To, ", "), t.config.address())
+}
+
+// envelopeAddress returns the bare address of "Name <address>", which is what MAIL and RCPT take
+func envelopeAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
+
+// formatEmail returns a plain text email. Line breaks are removed from the subject, so that a project
+// name cannot add headers.
+func formatEmail(from string, to []string, msg Message, date time.Time) string {
+	subject := strings.Join(strings.Fields(msg.Subject), " ")
+
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Text + "\r\n")
+	return b.String()
+}
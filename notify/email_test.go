@@ -0,0 +1,260 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receivedEmail is what the fake SMTP server got in one session
+type receivedEmail struct {
+	tls  bool
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer accepts mail over plain connections with STARTTLS, or over implicit TLS
+type fakeSMTPServer struct {
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+	noStartTLS  bool
+	received    chan receivedEmail
+}
+
+// startFakeSMTPServer starts a server with a certificate for 127.0.0.1, which the returned pool trusts
+func startFakeSMTPServer(t *testing.T, implicitTLS, noStartTLS bool) (*fakeSMTPServer, *x509.CertPool) {
+	// httptest comes with a certificate for 127.0.0.1
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	certificate := certServer.TLS.Certificates[0]
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(certServer.Certificate())
+	certServer.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	s := &fakeSMTPServer{
+		listener:    listener,
+		tlsConfig:   &tls.Config{Certificates: []tls.Certificate{certificate}},
+		implicitTLS: implicitTLS,
+		noStartTLS:  noStartTLS,
+		received:    make(chan receivedEmail, 1),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s, rootCAs
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	var email receivedEmail
+	if s.implicitTLS {
+		conn = tls.Server(conn, s.tlsConfig)
+		email.tls = true
+	}
+	defer func() { _ = conn.Close() }()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 127.0.0.1 ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			extensions := []string{"127.0.0.1"}
+			if !email.tls && !s.noStartTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if email.tls {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				_ = tp.PrintfLine("250%s%s", separator, extension)
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 Ready to start TLS")
+			conn = tls.Server(conn, s.tlsConfig)
+			tp = textproto.NewConn(conn)
+			email.tls = true
+		case "AUTH":
+			encoded := strings.TrimPrefix(arg, "PLAIN ")
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			email.auth = string(decoded)
+			_ = tp.PrintfLine("235 Authentication successful")
+		case "MAIL":
+			email.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			_ = tp.PrintfLine("250 OK")
+		case "RCPT":
+			email.to = append(email.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			lines, err := tp.ReadDotLines()
+			if err != nil {
+				return
+			}
+			email.data = strings.Join(lines, "\n")
+			_ = tp.PrintfLine("250 OK")
+			s.received <- email
+		case "QUIT":
+			_ = tp.PrintfLine("221 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmailTargetSend(t *testing.T) {
+	message := Message{Subject: "Deployment of billing failed", Text: "billing failed: port is already allocated"}
+
+	tests := []struct {
+		name string
+		tls  string
+	}{
+		{name: "starttls", tls: SMTPTLSStartTLS},
+		{name: "implicit tls", tls: SMTPTLSImplicit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, rootCAs := startFakeSMTPServer(t, tt.tls == SMTPTLSImplicit, false)
+			notifier := &Notifier{rootCAs: rootCAs}
+			route := Route{Email: &SMTPConfig{
+				Host:     "127.0.0.1",
+				Port:     server.port(),
+				Username: "oar",
+				Password: "secret",
+				From:     "Oar <oar@example.com>",
+				To:       []string{"billing@example.com", "Ops <ops@example.com>"},
+				TLS:      tt.tls,
+			}}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			require.NoError(t, notifier.Send(ctx, route, message))
+
+			email := <-server.received
+			assert.True(t, email.tls, "Credentials and message should only be sent over TLS")
+			assert.Equal(t, "\x00oar\x00secret", email.auth)
+			assert.Equal(t, "oar@example.com", email.from)
+			assert.Equal(t, []string{"billing@example.com", "ops@example.com"}, email.to)
+			assert.Contains(t, email.data, "From: Oar <oar@example.com>\n")
+			assert.Contains(t, email.data, "To: billing@example.com, Ops <ops@example.com>\n")
+			assert.Contains(t, email.data, "Subject: Deployment of billing failed\n")
+			assert.True(t, strings.HasSuffix(email.data, "\n\nbilling failed: port is already allocated"))
+		})
+	}
+}
+
+func TestEmailTargetSendErrors(t *testing.T) {
+	config := func(server *fakeSMTPServer, mode string) SMTPConfig {
+		return SMTPConfig{
+			Host: "127.0.0.1",
+			Port: server.port(),
+			From: "oar@example.com",
+			To:   []string{"ops@example.com"},
+			TLS:  mode,
+		}
+	}
+	message := Message{Subject: "Test", Text: "test"}
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		server, _ := startFakeSMTPServer(t, false, false)
+		target := &emailTarget{config: config(server, SMTPTLSStartTLS)}
+		assert.ErrorContains(t, target.Send(context.Background(), message), "failed to start TLS")
+	})
+
+	t.Run("starttls not offered", func(t *testing.T) {
+		server, rootCAs := startFakeSMTPServer(t, false, true)
+		target := &emailTarget{config: config(server, SMTPTLSStartTLS), rootCAs: rootCAs}
+		assert.ErrorContains(t, target.Send(context.Background(), message), "does not support STARTTLS")
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		server, _ := startFakeSMTPServer(t, false, false)
+		c := config(server, SMTPTLSStartTLS)
+		require.NoError(t, server.listener.Close())
+		target := &emailTarget{config: c}
+		assert.ErrorContains(t, target.Send(context.Background(), message), "failed to connect to SMTP server")
+	})
+}
+
+func TestSMTPConfig(t *testing.T) {
+	valid := SMTPConfig{Host: "smtp.example.com", From: "oar@example.com", To: []string{"ops@example.com"}}
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, "smtp.example.com:587", valid.address())
+
+	implicit := valid
+	implicit.TLS = SMTPTLSImplicit
+	assert.Equal(t, "smtp.example.com:465", implicit.address())
+
+	for name, modify := range map[string]func(c *SMTPConfig){
+		"no host":      func(c *SMTPConfig) { c.Host = "" },
+		"bad port":     func(c *SMTPConfig) { c.Port = 70000 },
+		"bad tls":      func(c *SMTPConfig) { c.TLS = "ssl" },
+		"bad sender":   func(c *SMTPConfig) { c.From = "oar" },
+		"no recipient": func(c *SMTPConfig) { c.To = nil },
+		"bad recipient": func(c *SMTPConfig) {
+			c.To = []string{"ops@example.com", "not an address"}
+		},
+	} {
+		c := valid
+		modify(&c)
+		assert.Error(t, c.Validate(), name)
+	}
+
+	// The target description is safe to log
+	target := &emailTarget{config: SMTPConfig{
+		Host: "smtp.example.com", Port: 2525, Username: "oar", Password: "secret", To: []string{"ops@example.com"},
+	}}
+	assert.Equal(t, "email to ops@example.com via smtp.example.com:2525", target.String())
+	assert.NotContains(t, target.String(), "secret")
+}
+
+func TestFormatEmail(t *testing.T) {
+	date := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	email := formatEmail("oar@example.com", []string{"ops@example.com"}, Message{
+		Subject: "Deployment of evil\r\nBcc: victim@example.com failed",
+		Text:    "failed",
+	}, date)
+
+	assert.Contains(t, email, "Subject: Deployment of evil Bcc: victim@example.com failed\r\n")
+	assert.NotContains(t, email, "\r\nBcc:")
+	assert.Contains(t, email, "Date: Thu, 02 Jan 2025 03:04:05 +0000\r\n")
+
+	accented := formatEmail("oar@example.com", []string{"ops@example.com"}, Message{Subject: "Déploiement"}, date)
+	assert.Contains(t, accented, "Subject: =?utf-8?q?D=C3=A9ploiement?=\r\n")
+}
@@ -0,0 +1,190 @@
+// Package notify sends deployment notifications to chat webhooks.
+package notify
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// DefaultTemplate is used when neither the project nor the configuration sets a template
+const DefaultTemplate = `Deployment of {{.ProjectName}}{{with .ShortCommit}} ({{.}}){{end}} {{.Status}}{{with .Error}}: {{.}}{{end}}`
+
+// Status is the outcome of a deployment
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Event is a finished deployment. Its fields and methods are what notification templates can use.
+type Event struct {
+	Status       Status
+	ProjectID    string
+	ProjectName  string
+	GitURL       string
+	GitBranch    string
+	DeploymentID string
+	Commit       string
+	Error        string // Why the deployment failed, empty on success
+	Notes        string
+	Labels       []string
+	Duration     time.Duration
+}
+
+// Succeeded reports whether the deployment succeeded
+func (e Event) Succeeded() bool {
+	return e.Status == StatusSucceeded
+}
+
+// ShortCommit returns the first 8 characters of the commit hash
+func (e Event) ShortCommit() string {
+	return domain.ShortCommit(e.Commit)
+}
+
+// Subject returns the subject of notifications about the event, for targets that have one
+func (e Event) Subject() string {
+	return fmt.Sprintf("Deployment of %s %s", e.ProjectName, e.Status)
+}
+
+// sampleEvent is rendered to validate templates, so that unknown fields are caught when they are saved
+var sampleEvent = Event{
+	Status:       StatusFailed,
+	ProjectID:    "00000000-0000-0000-0000-000000000000",
+	ProjectName:  "example",
+	GitURL:       "https://example.com/example.git",
+	GitBranch:    "main",
+	DeploymentID: "00000000-0000-0000-0000-000000000000",
+	Commit:       "0123456789abcdef0123456789abcdef01234567",
+	Error:        "example error",
+	Labels:       []string{"example"},
+	Duration:     time.Minute,
+}
+
+// Route is where notifications are delivered. A route may have a webhook, email, both or neither.
+type Route struct {
+	WebhookURL string
+	Channel    string      // Overrides the channel configured for the webhook, if the chat service allows it
+	Email      *SMTPConfig // Sends email when set with a host
+}
+
+// IsEmpty reports whether the route has nowhere to deliver to
+func (r Route) IsEmpty() bool {
+	return r.WebhookURL == "" && (r.Email == nil || r.Email.Host == "")
+}
+
+// Resolve returns the route of a project, falling back to the default route. A project with its own
+// webhook URL only uses its own channel; a project without one may still pick the channel. Likewise, a
+// project with its own SMTP host uses only its own email settings, and a project with just recipients
+// gets the default SMTP server, sending to those recipients.
+func Resolve(project, fallback Route) Route {
+	route := Route{WebhookURL: fallback.WebhookURL, Channel: fallback.Channel, Email: fallback.Email}
+	if project.WebhookURL != "" {
+		route.WebhookURL = project.WebhookURL
+		route.Channel = project.Channel
+	} else if project.Channel != "" {
+		route.Channel = project.Channel
+	}
+
+	switch {
+	case project.Email == nil:
+	case project.Email.Host != "":
+		route.Email = project.Email
+	case len(project.Email.To) > 0 && fallback.Email != nil:
+		email := *fallback.Email
+		email.To = project.Email.To
+		route.Email = &email
+	}
+	return route
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute HTTP or HTTPS URL
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid notification webhook URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid notification webhook URL %q: must be an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// ValidateTemplate checks that a template parses and renders an event
+func ValidateTemplate(text string) error {
+	if _, err := Render(text, sampleEvent); err != nil {
+		return fmt.Errorf("invalid notification template: %w", err)
+	}
+	return nil
+}
+
+// Render executes a notification template for an event
+func Render(text string, event Event) (string, error) {
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Message is a rendered notification
+type Message struct {
+	Subject string // Used by targets that have one, e.g. email
+	Text    string
+}
+
+// Target delivers notifications to one destination
+type Target interface {
+	Send(ctx context.Context, msg Message) error
+	// String describes the destination for logs and errors, without credentials
+	String() string
+}
+
+// Notifier sends notifications to the targets of a route
+type Notifier struct {
+	client  *http.Client
+	rootCAs *x509.CertPool // Trusted by email targets instead of the system roots when set
+}
+
+// NewNotifier creates a Notifier whose webhook requests time out after the given duration. Email
+// delivery is bounded by the context passed to Send.
+func NewNotifier(timeout time.Duration) *Notifier {
+	return &Notifier{client: &http.Client{Timeout: timeout}}
+}
+
+// Send delivers a message to every target of the route. A failing target does not keep the message
+// from the others; their errors are joined.
+func (n *Notifier) Send(ctx context.Context, route Route, msg Message) error {
+	var errs []error
+	for _, target := range n.targets(route) {
+		if err := target.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// targets returns the targets of a route
+func (n *Notifier) targets(route Route) []Target {
+	var targets []Target
+	if route.WebhookURL != "" {
+		targets = append(targets, &webhookTarget{client: n.client, url: route.WebhookURL, channel: route.Channel})
+	}
+	if route.Email != nil && route.Email.Host != "" {
+		targets = append(targets, &emailTarget{config: *route.Email, rootCAs: n.rootCAs})
+	}
+	return targets
+}
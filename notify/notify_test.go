@@ -0,0 +1,143 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/notify"
+)
+
+func TestRender(t *testing.T) {
+	event := notify.Event{
+		Status:      notify.StatusFailed,
+		ProjectName: "billing",
+		Commit:      "0123456789abcdef",
+		Error:       "container creation failed",
+	}
+
+	message, err := notify.Render(notify.DefaultTemplate, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment of billing (01234567) failed: container creation failed", message)
+
+	event.Status = notify.StatusSucceeded
+	event.Error = ""
+	message, err = notify.Render(notify.DefaultTemplate, event)
+	require.NoError(t, err)
+	assert.Equal(t, "Deployment of billing (01234567) succeeded", message)
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{name: "default", template: notify.DefaultTemplate},
+		{name: "methods", template: "{{if .Succeeded}}ok{{else}}{{.Error}}{{end}} {{.ShortCommit}} {{.Duration}}"},
+		{name: "range over labels", template: "{{range .Labels}}#{{.}} {{end}}"},
+		{name: "syntax error", template: "{{.ProjectName", wantErr: true},
+		{name: "unknown field", template: "{{.Project}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notify.ValidateTemplate(tt.template)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid notification template")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	fallback := notify.Route{WebhookURL: "https://chat.example.com/default", Channel: "#ops"}
+
+	// A project with its own webhook does not inherit the default channel
+	own := notify.Route{WebhookURL: "https://chat.example.com/team"}
+	assert.Equal(t, own, notify.Resolve(own, fallback))
+
+	// A project without a webhook may still pick the channel of the default webhook
+	assert.Equal(t,
+		notify.Route{WebhookURL: fallback.WebhookURL, Channel: "#billing"},
+		notify.Resolve(notify.Route{Channel: "#billing"}, fallback))
+	assert.Equal(t, fallback, notify.Resolve(notify.Route{}, fallback))
+
+	// Without any webhook or email there is nowhere to send to
+	assert.True(t, notify.Resolve(notify.Route{}, notify.Route{}).IsEmpty())
+}
+
+func TestResolveEmail(t *testing.T) {
+	defaultEmail := &notify.SMTPConfig{
+		Host: "smtp.example.com",
+		From: "oar@example.com",
+		To:   []string{"ops@example.com"},
+	}
+	fallback := notify.Route{Email: defaultEmail}
+
+	assert.Equal(t, defaultEmail, notify.Resolve(notify.Route{}, fallback).Email)
+
+	// Recipients alone use the default server
+	route := notify.Resolve(notify.Route{Email: &notify.SMTPConfig{To: []string{"billing@example.com"}}}, fallback)
+	require.NotNil(t, route.Email)
+	assert.Equal(t, "smtp.example.com", route.Email.Host)
+	assert.Equal(t, []string{"billing@example.com"}, route.Email.To)
+	assert.Equal(t, []string{"ops@example.com"}, defaultEmail.To, "Default route should not be modified")
+
+	// A project with its own server does not inherit anything
+	own := &notify.SMTPConfig{
+		Host: "smtp.team.example.com",
+		From: "ci@team.example.com",
+		To:   []string{"team@example.com"},
+	}
+	assert.Equal(t, own, notify.Resolve(notify.Route{Email: own}, fallback).Email)
+
+	// Recipients without a default server cannot be sent to
+	recipientsOnly := notify.Route{Email: &notify.SMTPConfig{To: []string{"billing@example.com"}}}
+	assert.True(t, notify.Resolve(recipientsOnly, notify.Route{}).IsEmpty())
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, notify.ValidateWebhookURL("https://hooks.slack.com/services/T000/B000/XXXX"))
+	assert.NoError(t, notify.ValidateWebhookURL("http://mattermost.internal:8065/hooks/abc"))
+	assert.Error(t, notify.ValidateWebhookURL("hooks.slack.com/services/T000"))
+	assert.Error(t, notify.ValidateWebhookURL("ftp://example.com/hook"))
+	assert.Error(t, notify.ValidateWebhookURL("https://"))
+}
+
+func TestNotifierSend(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		received = nil
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received["text"] == "reject" {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	notifier := notify.NewNotifier(5 * time.Second)
+	message := notify.Message{Subject: "Deployment of billing succeeded", Text: "deployed"}
+
+	err := notifier.Send(context.Background(), notify.Route{WebhookURL: server.URL, Channel: "#ops"}, message)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "deployed", "channel": "#ops"}, received)
+
+	// The channel is left out, so that the webhook's own channel is used
+	err = notifier.Send(context.Background(), notify.Route{WebhookURL: server.URL}, message)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"text": "deployed"}, received)
+
+	err = notifier.Send(context.Background(), notify.Route{WebhookURL: server.URL}, notify.Message{Text: "reject"})
+	assert.ErrorContains(t, err, "400 Bad Request: invalid_payload")
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// webhookTarget posts messages to incoming webhooks in the format used by Slack and Mattermost
+type webhookTarget struct {
+	client  *http.Client
+	url     string
+	channel string
+}
+
+// Send posts the message text to the webhook. The subject is not used, chat messages have none.
+func (t *webhookTarget) Send(ctx context.Context, msg Message) error {
+	payload := struct {
+		Text    string `json:"text"`
+		Channel string `json:"channel,omitempty"`
+	}{Text: msg.Text, Channel: t.channel}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Debug("Failed to close notification response body", "error", closeErr)
+		}
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification webhook returned %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// String returns the webhook host, the path of webhook URLs usually holds a secret token
+func (t *webhookTarget) String() string {
+	if u, err := url.Parse(t.url); err == nil {
+		return "webhook " + u.Host
+	}
+	return "webhook"
+}
@@ -0,0 +1,84 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// ImportExisting creates a project for a Compose stack that is already running on the host, without
+// restarting its containers. Oar runs Compose with the project name, so the project must be named
+// after the running stack. The project status is taken from the running containers. The returned
+// warnings describe services whose running configuration differs from the repository; the next
+// deployment recreates those services.
+func (s *ProjectService) ImportExisting(
+	project *domain.Project,
+	composeProjectName string,
+) (*domain.Project, []string, error) {
+	if composeProjectName == "" {
+		return nil, nil, fmt.Errorf("compose project name is required")
+	}
+	if project.Name != composeProjectName {
+		return nil, nil, fmt.Errorf(
+			"project name %q must match the compose project name %q", project.Name, composeProjectName)
+	}
+	if err := s.resolveComposeName(project); err != nil {
+		return nil, nil, err
+	}
+	if project.ComposeName != composeProjectName {
+		return nil, nil, fmt.Errorf("compose project name %q is used by another project", composeProjectName)
+	}
+
+	running, err := docker.RunningConfigHashes(composeProjectName, s.config.ComposeQueryTimeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find compose project %s: %w", composeProjectName, err)
+	}
+	if len(running) == 0 {
+		return nil, nil, fmt.Errorf("no containers found for compose project %s", composeProjectName)
+	}
+
+	createdProject, err := s.Create(project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	warnings := s.configDrift(createdProject, running)
+
+	status, err := s.GetStatus(createdProject.ID)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not read the status of the running containers: %v", err))
+		return createdProject, warnings, nil
+	}
+	createdProject.Status = status.Status.ProjectStatus()
+	if err := s.projectRepository.Update(createdProject); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "import_existing_project",
+			"project_id", createdProject.ID,
+			"error", err)
+		warnings = append(warnings, fmt.Sprintf("could not save the project status: %v", err))
+	}
+
+	slog.Info("Imported running compose project",
+		"project_id", createdProject.ID,
+		"project_name", createdProject.Name,
+		"status", createdProject.Status,
+		"warnings", len(warnings))
+	return createdProject, warnings, nil
+}
+
+// configDrift compares the running containers with the project's configuration. A failed
+// comparison is reported as a warning, since the import itself has already succeeded.
+func (s *ProjectService) configDrift(project *domain.Project, running map[string]string) []string {
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return []string{fmt.Sprintf("could not compare the running stack with the repository: %v", err)}
+	}
+	declared, err := composeProject.ConfigHashes()
+	if err != nil {
+		return []string{fmt.Sprintf("could not compare the running stack with the repository: %v", err)}
+	}
+	return docker.CompareConfigHashes(declared, running)
+}
@@ -0,0 +1,81 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestAnnotateDeployment(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(name string) uuid.UUID {
+		projectID := uuid.New()
+		_, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusRunning,
+		})
+		require.NoError(t, err)
+		return projectID
+	}
+	projectID := createProject("annotated-project")
+	otherProjectID := createProject("other-project")
+
+	deployment := domain.NewDeployment(projectID, "aaaaaaaaaaaa")
+	deployment.Status = domain.DeploymentStatusCompleted
+	require.NoError(t, repos.deployments.Create(&deployment))
+
+	t.Run("sets notes and labels", func(t *testing.T) {
+		annotated, err := projectService.AnnotateDeployment(projectID, deployment.ID, domain.DeploymentAnnotation{
+			Notes:  "  hotfix for incident 123\n",
+			Labels: []string{"hotfix", " incident-123 ", "", "hotfix"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "hotfix for incident 123", annotated.Notes)
+		assert.Equal(t, []string{"hotfix", "incident-123"}, annotated.Labels)
+
+		stored, err := repos.deployments.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "hotfix for incident 123", stored.Notes)
+		assert.Equal(t, []string{"hotfix", "incident-123"}, stored.Labels)
+		assert.Equal(t, domain.DeploymentStatusCompleted, stored.Status, "Annotating must not change the status")
+	})
+
+	t.Run("clears notes and labels", func(t *testing.T) {
+		_, err := projectService.AnnotateDeployment(projectID, deployment.ID, domain.DeploymentAnnotation{})
+		require.NoError(t, err)
+
+		stored, err := repos.deployments.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.Notes)
+		assert.Empty(t, stored.Labels)
+	})
+
+	t.Run("nonexistent deployment", func(t *testing.T) {
+		_, err := projectService.AnnotateDeployment(projectID, uuid.New(), domain.DeploymentAnnotation{Notes: "x"})
+		require.Error(t, err)
+	})
+
+	t.Run("deployment of another project", func(t *testing.T) {
+		_, err := projectService.AnnotateDeployment(otherProjectID, deployment.ID, domain.DeploymentAnnotation{
+			Notes: "not mine",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not belong to project")
+
+		stored, err := repos.deployments.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.Notes, "A foreign annotation must not be stored")
+	})
+}
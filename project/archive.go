@@ -0,0 +1,223 @@
+package project
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CreateFromArchive creates a project from a tar archive, optionally gzip-compressed, instead of a
+// git repository. The archive is extracted into the project's working directory and must contain
+// the project's compose files. The project's commit is the SHA-256 digest of the archive. Archive
+// projects are deployed from the extracted files as they are, and are not watched for changes.
+func (s *ProjectService) CreateFromArchive(project *domain.Project, archive io.Reader) (*domain.Project, error) {
+	if strings.TrimSpace(project.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	project.Source = domain.ProjectSourceArchive
+	project.GitURL = ""
+	project.GitBranch = ""
+	project.GitAuth = nil
+	project.AutoDeployEnabled = false
+	project.AutoDeploySkipGitPull = false
+	if err := s.validateNewProject(project); err != nil {
+		return nil, err
+	}
+	project.WorkingDir = s.workingDir(project)
+
+	gitDir, err := project.GitDir()
+	if err != nil {
+		return nil, err
+	}
+
+	// The directory must be new, so that cleaning up never removes files of another project
+	workingDir := project.WorkingDir
+	if _, err := os.Stat(workingDir); err == nil {
+		return nil, fmt.Errorf("project directory %s already exists", workingDir)
+	}
+	cleanup := func() {
+		if cleanupErr := os.RemoveAll(workingDir); cleanupErr != nil {
+			slog.Error("Failed to remove project directory after creation failure",
+				"working_dir", workingDir,
+				"error", cleanupErr)
+		}
+	}
+
+	digest, err := extractArchive(archive, gitDir)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	if len(project.ComposeFiles) == 0 {
+		if err := discoverProjectComposeFiles(project, gitDir); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+
+	// Unlike a repository, an archive cannot get the missing files before the first deployment
+	if missing := missingComposeFiles(gitDir, project.EnabledComposeFiles()); len(missing) > 0 {
+		cleanup()
+		return nil, fmt.Errorf("archive does not contain compose files: %s", strings.Join(missing, ", "))
+	}
+
+	project.LocalCommit = &digest
+	project.Status = domain.ProjectStatusStopped
+
+	createdProject, err := s.projectRepository.Create(project)
+	if err != nil {
+		cleanup()
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "create_project_from_archive",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"error", err)
+		return nil, err // Pass through as-is
+	}
+
+	slog.Info("Project created from archive",
+		"project_id", createdProject.ID,
+		"project_name", createdProject.Name,
+		"digest", digest)
+	return createdProject, nil
+}
+
+// extractArchive extracts a tar archive, optionally gzip-compressed, into dir, which must not exist.
+// It returns the hex SHA-256 digest of the archive as read. Entries that would be written outside
+// dir, links that point outside it, and entries other than files, directories and symbolic links
+// are rejected.
+func extractArchive(archive io.Reader, dir string) (string, error) {
+	hash := sha256.New()
+	buffered := bufio.NewReader(io.TeeReader(archive, hash))
+
+	var stream io.Reader = buffered
+	if magic, _ := buffered.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = gz.Close() }()
+		stream = gz
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// Entries are written through a root, so that no entry can follow a link out of dir
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = root.Close() }()
+
+	reader := tar.NewReader(stream)
+	entries := 0
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := extractEntry(root, reader, header); err != nil {
+			return "", err
+		}
+		entries++
+	}
+	if entries == 0 {
+		return "", fmt.Errorf("archive is empty")
+	}
+
+	// Links are checked again once all entries exist: a link that points inside dir when it is
+	// extracted can point outside it through links extracted after it
+	if err := checkLinks(dir); err != nil {
+		return "", err
+	}
+
+	// Read what follows the tar stream, so that the digest covers the whole archive
+	if _, err := io.Copy(io.Discard, buffered); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// extractEntry writes a single archive entry below root
+func extractEntry(root *os.Root, reader io.Reader, header *tar.Header) error {
+	name := filepath.Clean(filepath.FromSlash(header.Name))
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("archive entry %s is outside the project directory", header.Name)
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return root.MkdirAll(name, 0o755)
+	case tar.TypeReg:
+		if err := root.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		file, err := root.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, header.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, reader); err != nil {
+			_ = file.Close()
+			return err
+		}
+		return file.Close()
+	case tar.TypeSymlink:
+		target := filepath.FromSlash(header.Linkname)
+		if filepath.IsAbs(target) || !filepath.IsLocal(filepath.Join(filepath.Dir(name), target)) {
+			return fmt.Errorf("archive entry %s links outside the project directory", header.Name)
+		}
+		if err := root.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		return root.Symlink(target, name)
+	case tar.TypeXGlobalHeader:
+		return nil
+	default:
+		return fmt.Errorf("archive entry %s is not a file, directory or symbolic link", header.Name)
+	}
+}
+
+// checkLinks returns an error when a symbolic link below dir resolves to a path outside it, or to
+// nothing at all
+func checkLinks(dir string) error {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.Type()&fs.ModeSymlink == 0 {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return fmt.Errorf("archive entry %s is a broken link", filepath.ToSlash(rel))
+		}
+		if inside, _ := filepath.Rel(realDir, resolved); !filepath.IsLocal(inside) {
+			return fmt.Errorf("archive entry %s links outside the project directory", filepath.ToSlash(rel))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,215 @@
+package project_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// archiveEntry is an entry of a test archive
+type archiveEntry struct {
+	name     string
+	content  string
+	typeflag byte   // tar.TypeReg when zero
+	linkname string // Target of links
+}
+
+// buildArchive returns a tar archive of entries, gzip-compressed when compress is set
+func buildArchive(t *testing.T, compress bool, entries ...archiveEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if compress {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	}
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Mode:     0o644,
+			Size:     int64(len(entry.content)),
+		}
+		switch entry.typeflag {
+		case 0:
+			header.Typeflag = tar.TypeReg
+		case tar.TypeDir:
+			header.Mode = 0o755
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+func newArchiveTestService(t *testing.T) (*project.ProjectService, *config.Config) {
+	t.Helper()
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+	return projectService, cfg
+}
+
+func TestCreateFromArchive(t *testing.T) {
+	// Stub docker: config prints a minimal configuration, everything else succeeds
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	projectService, _ := newArchiveTestService(t)
+
+	archive := buildArchive(t, true,
+		archiveEntry{name: "config/", typeflag: tar.TypeDir},
+		archiveEntry{name: "config/app.env", content: "PORT=8080\n"},
+		archiveEntry{name: "compose.yaml", content: "services:\n  web:\n    image: busybox\n"},
+		archiveEntry{name: "app.env", typeflag: tar.TypeSymlink, linkname: "config/app.env"},
+	)
+	sum := sha256.Sum256(archive)
+	digest := hex.EncodeToString(sum[:])
+
+	p := domain.NewProject("bundle", "", []string{"compose.yaml"}, nil)
+	p.SkipVolumeInit = true
+	created, err := projectService.CreateFromArchive(&p, bytes.NewReader(archive))
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.ProjectSourceArchive, created.Source)
+	assert.False(t, created.AutoDeployEnabled)
+	assert.Equal(t, digest, created.LocalCommitStr())
+
+	gitDir, err := created.GitDir()
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(gitDir, "app.env"))
+	require.NoError(t, err)
+	assert.Equal(t, "PORT=8080\n", string(content))
+
+	t.Run("stored without a repository", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.UsesGit())
+		assert.Empty(t, stored.GitURL)
+		assert.Empty(t, stored.GitBranch)
+	})
+
+	t.Run("deploys without pulling", func(t *testing.T) {
+		require.NoError(t, projectService.DeployPiping(created.ID, true, nil, domain.DeploymentAnnotation{}))
+
+		deployments, err := projectService.ListDeployments(created.ID)
+		require.NoError(t, err)
+		require.Len(t, deployments, 1)
+		assert.Equal(t, domain.DeploymentStatusCompleted, deployments[0].Status)
+		assert.Equal(t, digest, deployments[0].CommitHash)
+	})
+
+	t.Run("updates keep automatic deployments off", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.AutoDeployEnabled = true
+		require.NoError(t, projectService.Update(stored))
+
+		stored, err = projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.AutoDeployEnabled)
+	})
+}
+
+func TestCreateFromArchiveRejectsInvalidArchives(t *testing.T) {
+	compose := archiveEntry{name: "compose.yaml", content: "services: {}\n"}
+
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		wantErr string
+	}{
+		{
+			name:    "parent directory",
+			entries: []archiveEntry{compose, {name: "../escape.sh", content: "echo"}},
+			wantErr: "outside the project directory",
+		},
+		{
+			name:    "nested parent directory",
+			entries: []archiveEntry{compose, {name: "config/../../escape.sh", content: "echo"}},
+			wantErr: "outside the project directory",
+		},
+		{
+			name:    "absolute path",
+			entries: []archiveEntry{compose, {name: "/etc/cron.d/escape", content: "echo"}},
+			wantErr: "outside the project directory",
+		},
+		{
+			name:    "absolute link",
+			entries: []archiveEntry{compose, {name: "passwd", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+			wantErr: "links outside the project directory",
+		},
+		{
+			name:    "relative link",
+			entries: []archiveEntry{compose, {name: "up", typeflag: tar.TypeSymlink, linkname: "../.."}},
+			wantErr: "links outside the project directory",
+		},
+		{
+			name: "chained links",
+			entries: []archiveEntry{
+				compose,
+				{name: "d/", typeflag: tar.TypeDir},
+				{name: "d/l", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "d/l/e", typeflag: tar.TypeSymlink, linkname: ".."},
+			},
+			wantErr: "links outside the project directory",
+		},
+		{
+			name:    "hard link",
+			entries: []archiveEntry{compose, {name: "hosts", typeflag: tar.TypeLink, linkname: "/etc/hosts"}},
+			wantErr: "not a file, directory or symbolic link",
+		},
+		{
+			name:    "missing compose file",
+			entries: []archiveEntry{{name: "docker-compose.yml", content: "services: {}\n"}},
+			wantErr: "archive does not contain compose files: compose.yaml",
+		},
+		{
+			name:    "empty archive",
+			wantErr: "archive is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectService, cfg := newArchiveTestService(t)
+
+			p := domain.NewProject("bundle", "", []string{"compose.yaml"}, nil)
+			_, err := projectService.CreateFromArchive(&p, bytes.NewReader(buildArchive(t, false, tt.entries...)))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			// Nothing is left behind in the workspace
+			entries, err := os.ReadDir(cfg.WorkspaceDir)
+			if err == nil {
+				assert.Empty(t, entries)
+			}
+		})
+	}
+}
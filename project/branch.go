@@ -0,0 +1,59 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// RedetectDefaultBranch detects the default branch of the project's repository again, e.g. after it was
+// renamed from master to main, and returns it. When it differs from the project's branch, confirm is
+// asked whether to switch to it; a nil confirm switches without asking. The switch takes effect at the
+// next deployment with a pull.
+func (s *ProjectService) RedetectDefaultBranch(
+	projectID uuid.UUID,
+	confirm func(current, detected string) bool,
+) (string, error) {
+	unlock := s.locks.lock(projectID, nil)
+	defer unlock()
+
+	project, err := s.projectRepository.FindByID(projectID)
+	if err != nil {
+		return "", err
+	}
+	if !project.UsesGit() {
+		return "", fmt.Errorf("project %s is deployed from an archive and has no branch", project.Name)
+	}
+
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return "", err
+	}
+	detected, err := s.gitService.GetDefaultBranch(source.URL, project.GitAuth)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine default branch: %w", source.RedactError(err))
+	}
+	if detected == source.Branch {
+		// A branch given through variables stays as it was given
+		if !project.GitBranchDetected && project.GitBranch == detected {
+			project.GitBranchDetected = true
+			return detected, s.Update(project)
+		}
+		return detected, nil
+	}
+	if confirm != nil && !confirm(project.GitBranch, detected) {
+		return detected, nil
+	}
+
+	slog.Info("Switching project to the redetected default branch",
+		"project_id", project.ID,
+		"project_name", project.Name,
+		"old_branch", project.GitBranch,
+		"new_branch", detected)
+	project.GitBranch = detected
+	project.GitBranchDetected = true
+	// The remote commit was of the old branch; the next check fetches the new one
+	project.RemoteCommit = nil
+	return detected, s.Update(project)
+}
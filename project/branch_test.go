@@ -0,0 +1,204 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+func TestCreateChecksBranch(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	t.Run("typo'd branch", func(t *testing.T) {
+		p := domain.NewProject("typo", originDir, []string{"compose.yaml"}, nil)
+		p.GitBranch = "mastre"
+
+		_, err := projectService.Create(&p)
+		require.Error(t, err)
+		assert.Equal(t, "branch 'mastre' not found on remote", err.Error())
+		assert.Equal(t, "branch 'mastre' not found on remote", project.FormatErrorForUser(err))
+
+		entries, err := os.ReadDir(cfg.WorkspaceDir)
+		if err == nil {
+			assert.Empty(t, entries, "Nothing must be cloned for a missing branch")
+		}
+	})
+
+	t.Run("default branch", func(t *testing.T) {
+		p := domain.NewProject("default", originDir, []string{"compose.yaml"}, nil)
+
+		created, err := projectService.Create(&p)
+		require.NoError(t, err)
+		assert.Equal(t, "master", created.GitBranch)
+	})
+
+	t.Run("branch change on update", func(t *testing.T) {
+		p := domain.NewProject("update", originDir, []string{"compose.yaml"}, nil)
+		p.GitBranch = "master"
+		created, err := projectService.Create(&p)
+		require.NoError(t, err)
+
+		created.GitBranch = "mastre"
+		err = projectService.Update(created)
+		require.Error(t, err)
+		assert.Equal(t, "branch 'mastre' not found on remote", err.Error())
+
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "master", stored.GitBranch)
+
+		// Updates that keep the branch do not contact the remote
+		stored.Name = "renamed"
+		stored.GitURL = filepath.Join(cfg.DataDir, "gone")
+		require.NoError(t, projectService.Update(stored))
+	})
+}
+
+// renameDefaultBranch renames the master branch of the repository in dir to main, and makes main its
+// default branch, as a hosting service does
+func renameDefaultBranch(t *testing.T, dir string) {
+	t.Helper()
+	repo, err := gogit.PlainOpen(dir)
+	require.NoError(t, err)
+	master, err := repo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.NoError(t, err)
+
+	main := plumbing.NewBranchReferenceName("main")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(main, master.Hash())))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, main)))
+	require.NoError(t, repo.Storer.RemoveReference(master.Name()))
+}
+
+func TestRedetectDefaultBranch(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	p := domain.NewProject("renamed-branch", originDir, []string{"compose.yaml"}, nil)
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+	assert.Equal(t, "master", created.GitBranch)
+	assert.True(t, created.GitBranchDetected)
+
+	given := domain.NewProject("given-branch", originDir, []string{"compose.yaml"}, nil)
+	given.GitBranch = "master"
+	createdGiven, err := projectService.Create(&given)
+	require.NoError(t, err)
+	assert.False(t, createdGiven.GitBranchDetected)
+
+	t.Run("unchanged default branch", func(t *testing.T) {
+		detected, err := projectService.RedetectDefaultBranch(created.ID, func(string, string) bool {
+			t.Fatal("Nothing must be confirmed when the default branch is unchanged")
+			return false
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "master", detected)
+	})
+
+	renameDefaultBranch(t, originDir)
+
+	t.Run("declined", func(t *testing.T) {
+		var asked []string
+		detected, err := projectService.RedetectDefaultBranch(created.ID, func(current, detected string) bool {
+			asked = append(asked, current, detected)
+			return false
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "main", detected)
+		assert.Equal(t, []string{"master", "main"}, asked)
+
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "master", stored.GitBranch)
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		detected, err := projectService.RedetectDefaultBranch(created.ID, func(string, string) bool { return true })
+		require.NoError(t, err)
+		assert.Equal(t, "main", detected)
+
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "main", stored.GitBranch)
+		assert.True(t, stored.GitBranchDetected)
+		assert.Nil(t, stored.RemoteCommit)
+	})
+
+	t.Run("without confirmation", func(t *testing.T) {
+		detected, err := projectService.RedetectDefaultBranch(createdGiven.ID, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "main", detected)
+
+		stored, err := projectService.Get(createdGiven.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "main", stored.GitBranch)
+		assert.True(t, stored.GitBranchDetected)
+	})
+}
+
+func TestWatchURLIsChecked(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	canonicalDir := filepath.Join(cfg.DataDir, "canonical")
+	initDeployableRepo(t, canonicalDir)
+	mirrorDir := filepath.Join(cfg.DataDir, "mirror")
+	_, err := gogit.PlainClone(mirrorDir, &gogit.CloneOptions{URL: canonicalDir, Mirror: true})
+	require.NoError(t, err)
+
+	t.Run("unreachable on create", func(t *testing.T) {
+		p := domain.NewProject("unreachable", mirrorDir, []string{"compose.yaml"}, nil)
+		p.GitBranch = "master"
+		p.WatchURL = filepath.Join(cfg.DataDir, "gone")
+
+		_, err := projectService.Create(&p)
+		assert.ErrorContains(t, err, "failed to check branch 'master' on the watch URL")
+	})
+
+	t.Run("mirror and canonical repository", func(t *testing.T) {
+		p := domain.NewProject("mirrored", mirrorDir, []string{"compose.yaml"}, nil)
+		p.WatchURL = " " + canonicalDir + " "
+
+		created, err := projectService.Create(&p)
+		require.NoError(t, err)
+		assert.Equal(t, canonicalDir, created.WatchURL)
+		assert.Equal(t, "master", created.GitBranch)
+
+		// The branch must exist on both
+		otherDir := filepath.Join(cfg.DataDir, "other")
+		initDeployableRepo(t, otherDir)
+		repo, err := gogit.PlainOpen(otherDir)
+		require.NoError(t, err)
+		head, err := repo.Head()
+		require.NoError(t, err)
+		main := plumbing.NewBranchReferenceName("main")
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(main, head.Hash())))
+		require.NoError(t, repo.Storer.RemoveReference(plumbing.NewBranchReferenceName("master")))
+
+		created.WatchURL = otherDir
+		err = projectService.Update(created)
+		require.Error(t, err)
+		assert.Equal(t, "branch 'master' not found on the watch URL", err.Error())
+
+		// Watching the git URL itself is the same as not setting a watch URL
+		created.WatchURL = mirrorDir
+		require.NoError(t, projectService.Update(created))
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.WatchURL)
+	})
+}
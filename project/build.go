@@ -0,0 +1,99 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// buildNamePattern matches the names of build arguments and build secrets. Build secrets are passed
+// as environment variables, so both follow the rules of variable names.
+var buildNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// normalizeBuildArgs trims the names of the project's build arguments and secrets and validates them
+func normalizeBuildArgs(project *domain.Project) error {
+	args, err := normalizeBuildNames(project.BuildArgs, "build argument")
+	if err != nil {
+		return err
+	}
+	secrets, err := normalizeBuildNames(project.BuildSecrets, "build secret")
+	if err != nil {
+		return err
+	}
+	for name := range secrets {
+		if slices.Contains(docker.ReservedVariables, name) {
+			return fmt.Errorf("build secret %s is set by Oar and cannot be overridden", name)
+		}
+	}
+	project.BuildArgs = args
+	project.BuildSecrets = secrets
+	return nil
+}
+
+func normalizeBuildNames(values map[string]string, kind string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(values))
+	for name, value := range values {
+		name = strings.TrimSpace(name)
+		if !buildNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid %s name %q: use letters, digits and underscores", kind, name)
+		}
+		if _, ok := normalized[name]; ok {
+			return nil, fmt.Errorf("duplicate %s %s", kind, name)
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
+
+// buildImages builds the images of the deployed services with the project's build arguments, keeping
+// the output in the deployment record. Build secrets are masked in the output.
+func (s *ProjectService) buildImages(
+	composeProject *docker.ComposeProject,
+	outputChan chan<- docker.StreamMessage,
+	stdoutBuffer, stderrBuffer *strings.Builder,
+) error {
+	msg := "Building images..."
+	if len(composeProject.Services) > 0 {
+		msg = fmt.Sprintf("Building images of services: %s...", strings.Join(composeProject.Services, ", "))
+	}
+	outputChan <- docker.StreamMessage{Type: "info", Content: msg}
+
+	return s.runCaptured(composeProject, outputChan, stdoutBuffer, stderrBuffer, composeProject.BuildStreaming)
+}
+
+// runCaptured runs a streaming Compose command, forwarding its output and keeping it in the deployment
+// record. Build secrets are masked in the output, since builds may print them.
+func (s *ProjectService) runCaptured(
+	composeProject *docker.ComposeProject,
+	outputChan chan<- docker.StreamMessage,
+	stdoutBuffer, stderrBuffer *strings.Builder,
+	run func(outputChan chan<- docker.StreamMessage) error,
+) error {
+	capturingChan := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range capturingChan {
+			msg.Content = composeProject.RedactBuildSecrets(msg.Content)
+			switch msg.Type {
+			case "stdout":
+				stdoutBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
+			case "stderr":
+				stderrBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
+			}
+			outputChan <- msg
+		}
+	}()
+
+	err := run(capturingChan)
+	close(capturingChan)
+	<-done
+	return err
+}
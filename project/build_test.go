@@ -0,0 +1,136 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestBuildArgs deploys a project whose image is built with build arguments and a build secret, with
+// a stub docker that records its calls
+func TestBuildArgs(t *testing.T) {
+	// Stub docker: config renders the secret, as a compose file interpolating it would, and build
+	// prints the argument it was given and the secret from its environment
+	callsFile := newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  app:\n    build: .\n    image: app\n    environment:\n      TOKEN: %s\n' "$NPM_TOKEN"
+		exit 0
+		;;
+	build)
+		for arg in "$@"; do
+			case "$arg" in
+			VERSION=*) echo "building $arg with $NPM_TOKEN" ;;
+			esac
+		done
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	const secret = "npm_0123456789abcdef"
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+		BuildArgs:      map[string]string{"VERSION": "1"},
+		BuildSecrets:   map[string]string{"NPM_TOKEN": secret},
+	})
+	require.NoError(t, err)
+
+	// deploy deploys the project and returns the stored deployment and the build call
+	deploy := func(t *testing.T) (*domain.Deployment, string) {
+		t.Helper()
+		_ = os.Remove(callsFile)
+		require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+
+		var buildCall string
+		for call := range strings.Lines(string(calls)) {
+			if strings.Contains(call, " build ") {
+				buildCall = strings.TrimSpace(call)
+			}
+			if strings.Contains(call, " up ") {
+				assert.NotEmpty(t, buildCall, "The images should be built before up")
+			}
+		}
+		return deployments[0], buildCall
+	}
+
+	t.Run("build consumes the build argument", func(t *testing.T) {
+		deployment, buildCall := deploy(t)
+		assert.Equal(t, domain.DeploymentStatusCompleted, deployment.Status)
+		assert.True(t, strings.HasSuffix(buildCall, "build --build-arg VERSION=1"),
+			"unexpected build call: %s", buildCall)
+		assert.Contains(t, deployment.Stdout, "building VERSION=1 with ********")
+		assert.NotContains(t, deployment.Stdout, secret)
+		assert.NotContains(t, deployment.CommandLine, secret)
+	})
+
+	t.Run("redeploy picks up a changed argument", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.BuildArgs = map[string]string{"VERSION": "2"}
+		require.NoError(t, projectService.Update(stored))
+
+		deployment, buildCall := deploy(t)
+		assert.Equal(t, domain.DeploymentStatusCompleted, deployment.Status)
+		assert.True(t, strings.HasSuffix(buildCall, "build --build-arg VERSION=2"),
+			"unexpected build call: %s", buildCall)
+		assert.Contains(t, deployment.Stdout, "building VERSION=2 with ********")
+	})
+
+	t.Run("secret is masked in the configuration", func(t *testing.T) {
+		stdout, _, err := projectService.GetConfig(projectID)
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "TOKEN: ********")
+		assert.NotContains(t, stdout, secret)
+	})
+
+	t.Run("secret is stored encrypted", func(t *testing.T) {
+		var model db.ProjectModel
+		require.NoError(t, repos.projects.DB().First(&model, "id = ?", projectID).Error)
+		require.NotNil(t, model.BuildSecrets)
+		assert.NotContains(t, *model.BuildSecrets, secret)
+		assert.Equal(t, "VERSION=2", model.BuildArgs)
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"NPM_TOKEN": secret}, stored.BuildSecrets)
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.BuildArgs = map[string]string{"NODE ENV": "production"}
+		assert.EqualError(t, projectService.Update(stored),
+			`invalid build argument name "NODE ENV": use letters, digits and underscores`)
+
+		stored.BuildArgs = nil
+		stored.BuildSecrets = map[string]string{"OAR_COMMIT": "x"}
+		assert.EqualError(t, projectService.Update(stored),
+			"build secret OAR_COMMIT is set by Oar and cannot be overridden")
+	})
+}
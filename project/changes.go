@@ -0,0 +1,103 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// DeployChangesPiping pulls the latest commit and deploys only what its configuration change needs,
+// printing the output to the terminal. When only the environment or image of some services
+// changed, just those services are recreated, after pulling their images if needed. Anything else,
+// and anything that cannot be compared, is deployed in full.
+func (s *ProjectService) DeployChangesPiping(projectID uuid.UUID) error {
+	return pipeOutput(func(outputChan chan<- docker.StreamMessage) error {
+		return s.deploy(projectID, deployOptions{pull: true, planChanges: true}, outputChan)
+	})
+}
+
+// planConfigChange compares the configuration rendered before the pull with the one rendered after.
+// Without either of them the whole project is deployed.
+func (s *ProjectService) planConfigChange(
+	project *domain.Project,
+	previousConfig, config string,
+) docker.ConfigChangePlan {
+	if previousConfig == "" {
+		return docker.ConfigChangePlan{
+			Change: docker.ConfigChangeFull,
+			Reason: "the previous configuration could not be rendered",
+		}
+	}
+
+	if config == "" {
+		return docker.ConfigChangePlan{
+			Change: docker.ConfigChangeFull,
+			Reason: "the new configuration could not be rendered",
+		}
+	}
+
+	plan := docker.ClassifyConfigChange(previousConfig, config)
+	slog.Info("Classified configuration change",
+		"project_id", project.ID,
+		"project_name", project.Name,
+		"change", plan.Change,
+		"services", plan.Services,
+		"pull", plan.Pull,
+		"reason", plan.Reason)
+	return plan
+}
+
+// pullImages pulls the images of the selected services, forwarding the output to outputChan and
+// keeping it for the deployment record
+func (s *ProjectService) pullImages(
+	composeProject *docker.ComposeProject,
+	outputChan chan<- docker.StreamMessage,
+	stdoutBuffer, stderrBuffer *strings.Builder,
+) error {
+	msg := "Pulling images..."
+	if len(composeProject.Services) > 0 {
+		msg = fmt.Sprintf("Pulling images of services: %s...", strings.Join(composeProject.Services, ", "))
+	}
+	outputChan <- docker.StreamMessage{Type: "info", Content: msg}
+
+	capturingChan := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range capturingChan {
+			switch msg.Type {
+			case "stdout":
+				stdoutBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
+			case "stderr":
+				stderrBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
+			}
+			outputChan <- msg
+		}
+	}()
+
+	err := composeProject.PullStreaming(capturingChan)
+	close(capturingChan)
+	<-done
+	return err
+}
+
+// pipeOutput runs fn, printing the messages it sends to the terminal
+func pipeOutput(fn func(outputChan chan<- docker.StreamMessage) error) error {
+	outputChan := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range outputChan {
+			fmt.Println(msg.Content)
+		}
+	}()
+
+	err := fn(outputChan)
+	close(outputChan)
+	<-done
+	return err
+}
@@ -0,0 +1,200 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployChanges deploys new commits with a stub docker whose config command prints the compose
+// file, and checks which services are pulled and brought up for each kind of change
+func TestDeployChanges(t *testing.T) {
+	// Stub docker: config prints the compose file given with --file, pull and up are recorded
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	newStubDocker(t, `file=""
+prev=""
+for arg in "$@"; do
+	if [ "$prev" = "--file" ]; then
+		file="$arg"
+	fi
+	case "$arg" in
+	config)
+		cat "$file"
+		exit 0
+		;;
+	pull|up)
+		echo "$@" | sed "s/.* $arg/$arg/" >> "`+logPath+`"
+		exit 0
+		;;
+	esac
+	prev="$arg"
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+	commitCompose := func(t *testing.T, content string) {
+		repo, err := gogit.PlainOpen(originDir)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(originDir, "compose.yaml"), []byte(content), 0o644))
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add("compose.yaml")
+		require.NoError(t, err)
+		_, err = worktree.Commit("Update compose.yaml", &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	commitCompose(t, `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+  worker:
+    image: app/worker:1
+`)
+
+	p := domain.NewProject("changes", originDir, []string{"compose.yaml"}, nil)
+	p.SkipVolumeInit = true
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+
+	// calls returns the recorded pull and up commands since the last call
+	calls := func(t *testing.T) []string {
+		content, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(logPath))
+		return strings.Split(strings.TrimSpace(string(content)), "\n")
+	}
+
+	tests := []struct {
+		name      string
+		compose   string
+		wantCalls []string
+		wantPlan  string
+	}{
+		{
+			name: "environment",
+			compose: `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: staging
+  worker:
+    image: app/worker:1
+`,
+			wantCalls: []string{
+				"up --detach --quiet-pull --quiet-build --remove-orphans --no-start web",
+				"up --detach --quiet-pull --quiet-build --remove-orphans web",
+			},
+			wantPlan: "Configuration change: environment (environment changed for web)",
+		},
+		{
+			name: "image",
+			compose: `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: staging
+  worker:
+    image: app/worker:2
+`,
+			wantCalls: []string{
+				"pull worker",
+				"up --detach --quiet-pull --quiet-build --remove-orphans --no-start worker",
+				"up --detach --quiet-pull --quiet-build --remove-orphans worker",
+			},
+			wantPlan: "Configuration change: image (image changed for worker)",
+		},
+		{
+			name: "network",
+			compose: `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: staging
+  worker:
+    image: app/worker:2
+networks:
+  backend: {}
+`,
+			wantCalls: []string{
+				"up --detach --quiet-pull --quiet-build --remove-orphans --no-start",
+				"up --detach --quiet-pull --quiet-build --remove-orphans",
+			},
+			wantPlan: "Configuration change: full (networks changed)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitCompose(t, tt.compose)
+
+			require.NoError(t, projectService.DeployChangesPiping(created.ID))
+			assert.Equal(t, tt.wantCalls, calls(t))
+
+			deployments, err := projectService.ListDeployments(created.ID)
+			require.NoError(t, err)
+			require.NotEmpty(t, deployments)
+			assert.Contains(t, deployments[0].Stdout, tt.wantPlan)
+		})
+	}
+
+	t.Run("automatic deployment that also pulls images", func(t *testing.T) {
+		commitCompose(t, `services:
+  web:
+    image: nginx:1.27
+    environment:
+      MODE: production
+  worker:
+    image: app/worker:2
+networks:
+  backend: {}
+`)
+
+		opts := project.AutoDeployOptions{GitPull: true, PullImages: true, ChangesOnly: true}
+		require.NoError(t, projectService.DeployAutomaticPiping(created.ID, opts))
+		assert.Equal(t, []string{
+			"pull web",
+			"up --detach --quiet-pull --quiet-build --remove-orphans --no-start web",
+			"up --detach --quiet-pull --quiet-build --remove-orphans web",
+		}, calls(t))
+	})
+
+	t.Run("automatic deployment of the current commit", func(t *testing.T) {
+		opts := project.AutoDeployOptions{PullImages: true}
+		require.NoError(t, projectService.DeployAutomaticPiping(created.ID, opts))
+		assert.Equal(t, []string{
+			"pull",
+			"up --detach --quiet-pull --quiet-build --remove-orphans --no-start",
+			"up --detach --quiet-pull --quiet-build --remove-orphans",
+		}, calls(t))
+	})
+
+	t.Run("deployments record the commit they replaced", func(t *testing.T) {
+		deployments, err := projectService.ListDeployments(created.ID)
+		require.NoError(t, err)
+		require.Len(t, deployments, 5)
+
+		// Newest first: each deployment replaced the commit of the one before it
+		for i := 0; i < len(deployments)-1; i++ {
+			assert.NotEmpty(t, deployments[i].CommitHash)
+			assert.Equal(t, deployments[i+1].CommitHash, deployments[i].PreviousCommit)
+		}
+		assert.Empty(t, deployments[len(deployments)-1].PreviousCommit, "first deployment has no previous commit")
+	})
+}
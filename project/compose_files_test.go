@@ -0,0 +1,286 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployFailsOnMissingComposeFiles deploys projects whose compose files are not all in the
+// repository, as after a pull that removed them, and checks that Compose is never run
+func TestDeployFailsOnMissingComposeFiles(t *testing.T) {
+	upMarker := filepath.Join(t.TempDir(), "up-ran")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	up)
+		touch `+upMarker+`
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	// newProject stores a running project cloned from originDir
+	newProject := func(t *testing.T, originDir string, composeFiles []string) uuid.UUID {
+		t.Helper()
+		projectID := uuid.New()
+		workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+		gitDir := filepath.Join(workingDir, domain.GitDir)
+		_, err := gogit.PlainClone(gitDir, &gogit.CloneOptions{URL: originDir})
+		require.NoError(t, err)
+
+		_, err = repos.projects.Create(&domain.Project{
+			ID:             projectID,
+			Name:           "shop-" + projectID.String()[:8],
+			GitURL:         originDir,
+			GitBranch:      "master",
+			WorkingDir:     workingDir,
+			ComposeFiles:   composeFiles,
+			Status:         domain.ProjectStatusRunning,
+			SkipVolumeInit: true,
+		})
+		require.NoError(t, err)
+		return projectID
+	}
+
+	// checkFailed checks the deployment failed with wantErr before Compose ran, and left the project as it was
+	checkFailed := func(t *testing.T, projectID uuid.UUID, deployErr error, wantErr string) {
+		t.Helper()
+		require.Error(t, deployErr)
+		assert.Contains(t, deployErr.Error(), wantErr)
+		assert.NoFileExists(t, upMarker)
+
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		require.Len(t, deployments, 1)
+		assert.Equal(t, domain.DeploymentStatusFailed, deployments[0].Status)
+		assert.Contains(t, deployments[0].Stderr, wantErr)
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ProjectStatusRunning, stored.Status)
+	}
+
+	t.Run("pull removes one of several files", func(t *testing.T) {
+		originDir := filepath.Join(t.TempDir(), "origin")
+		initDeployableRepo(t, originDir)
+		commitFile(t, originDir, "compose.prod.yaml", "services: {}\n")
+		projectID := newProject(t, originDir, []string{"compose.yaml", "compose.prod.yaml"})
+
+		// The repository is restructured after the project was cloned
+		repo, err := gogit.PlainOpen(originDir)
+		require.NoError(t, err)
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Remove("compose.prod.yaml")
+		require.NoError(t, err)
+		removal, err := worktree.Commit("Move the production settings", &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		deployErr := projectService.DeployPiping(projectID, true, nil, domain.DeploymentAnnotation{})
+		checkFailed(t, projectID, deployErr,
+			"compose file compose.prod.yaml no longer exists in the repository at commit "+removal.String()[:8])
+	})
+
+	t.Run("several files missing without a pull", func(t *testing.T) {
+		originDir := filepath.Join(t.TempDir(), "origin")
+		initDeployableRepo(t, originDir)
+		projectID := newProject(t, originDir,
+			[]string{"compose.yaml", "compose.prod.yaml", "compose.monitoring.yaml"})
+
+		deployErr := projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+		checkFailed(t, projectID, deployErr,
+			"compose files compose.prod.yaml, compose.monitoring.yaml no longer exist in the repository")
+	})
+
+	t.Run("service extends a file that does not exist", func(t *testing.T) {
+		originDir := filepath.Join(t.TempDir(), "origin")
+		initDeployableRepo(t, originDir)
+		commitFile(t, originDir, "compose.prod.yaml",
+			"services:\n  web:\n    extends:\n      file: common/base.yaml\n      service: web\n")
+		projectID := newProject(t, originDir, []string{"compose.yaml", "compose.prod.yaml"})
+
+		deployErr := projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+		checkFailed(t, projectID, deployErr, "broken compose file reference: service web in compose.prod.yaml "+
+			"extends a service from common/base.yaml, which does not exist")
+	})
+}
+
+// commitFile writes a file to the repository in dir and commits it
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	repo, err := gogit.PlainOpen(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add(name)
+	require.NoError(t, err)
+	_, err = worktree.Commit("Add "+name, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+// TestDisabledComposeFiles switches compose files of a project off and checks that Compose commands
+// leave them out, and that a project keeps at least one enabled file
+func TestDisabledComposeFiles(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "config-args")
+	newStubDocker(t, `echo "$@" > `+argsFile+`
+printf 'services: {}\n'`)
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(t.TempDir(), "origin")
+	initDeployableRepo(t, originDir)
+	commitFile(t, originDir, "compose.override.yaml", "services: {}\n")
+	commitFile(t, originDir, "compose.debug.yaml", "services: {}\n")
+
+	p := domain.NewProject("shop", originDir,
+		[]string{"compose.yaml", "compose.override.yaml", "compose.debug.yaml"}, nil)
+	p.DisabledComposeFiles = []string{"compose.debug.yaml"}
+	p.SkipVolumeInit = true
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+
+	// configFiles returns the compose files given to the last docker compose config
+	configFiles := func(t *testing.T) []string {
+		t.Helper()
+		_, _, err := projectService.GetConfig(created.ID)
+		require.NoError(t, err)
+		content, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		var files []string
+		args := strings.Fields(string(content))
+		for i, arg := range args {
+			if arg == "--file" && i+1 < len(args) {
+				files = append(files, filepath.Base(args[i+1]))
+			}
+		}
+		return files
+	}
+
+	t.Run("disabled file is left out", func(t *testing.T) {
+		assert.Equal(t, []string{"compose.yaml", "compose.override.yaml"}, configFiles(t))
+	})
+
+	t.Run("files are switched without changing the list", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.DisabledComposeFiles = []string{"compose.override.yaml"}
+		require.NoError(t, projectService.Update(stored))
+
+		stored, err = projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose.yaml", "compose.override.yaml", "compose.debug.yaml"}, stored.ComposeFiles)
+		assert.Equal(t, []string{"compose.yaml", "compose.debug.yaml"}, configFiles(t))
+	})
+
+	t.Run("files removed from the list are no longer disabled", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.ComposeFiles = []string{"compose.yaml", "compose.debug.yaml"}
+		require.NoError(t, projectService.Update(stored))
+		assert.Empty(t, stored.DisabledComposeFiles)
+	})
+
+	t.Run("one file must stay enabled", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.DisabledComposeFiles = stored.ComposeFiles
+		assert.ErrorContains(t, projectService.Update(stored), "at least one compose file must be enabled")
+	})
+}
+
+// TestCreateDefaultComposeFiles creates projects with and without compose files, which fall back to the
+// configured defaults or else to the file Docker Compose would pick in the repository
+func TestCreateDefaultComposeFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	newService := func(t *testing.T, defaultFiles []string) *project.ProjectService {
+		cfg := newTestConfig(t)
+		cfg.DataDir = tempDir
+		cfg.WorkspaceDir = filepath.Join(tempDir, "projects")
+		cfg.ComposeDefaultFiles = defaultFiles
+		projectService, _ := newTestProjectService(t, cfg)
+		return projectService
+	}
+
+	originDir := filepath.Join(tempDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	t.Run("explicit files are kept", func(t *testing.T) {
+		projectService := newService(t, []string{"compose.yaml", "compose.prod.yaml"})
+		p := domain.NewProject("explicit", originDir, []string{"compose.yaml"}, nil)
+
+		created, _, err := collectCreate(t, projectService, &p)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose.yaml"}, created.ComposeFiles)
+	})
+
+	t.Run("configured defaults", func(t *testing.T) {
+		projectService := newService(t, []string{"compose.yaml", "compose.prod.yaml"})
+		p := domain.NewProject("defaults", originDir, nil, nil)
+
+		created, messages, err := collectCreate(t, projectService, &p)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose.yaml", "compose.prod.yaml"}, created.ComposeFiles)
+		assert.Contains(t, messages, docker.StreamMessage{
+			Type:    "stderr",
+			Content: "Warning: compose file compose.prod.yaml is not in the repository",
+		})
+
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose.yaml", "compose.prod.yaml"}, stored.ComposeFiles)
+	})
+
+	t.Run("discovered without defaults", func(t *testing.T) {
+		projectService := newService(t, nil)
+		p := domain.NewProject("discovered", originDir, nil, nil)
+
+		created, messages, err := collectCreate(t, projectService, &p)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"compose.yaml"}, created.ComposeFiles)
+		assert.Contains(t, messages, docker.StreamMessage{Type: "info", Content: "Using compose files compose.yaml"})
+	})
+
+	t.Run("repository without compose file", func(t *testing.T) {
+		emptyOrigin := filepath.Join(tempDir, "empty-origin")
+		repo, err := gogit.PlainInit(emptyOrigin, false)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(emptyOrigin, "README.md"), []byte("# Docs\n"), 0o644))
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add("README.md")
+		require.NoError(t, err)
+		_, err = worktree.Commit("Initial commit", &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+
+		projectService := newService(t, nil)
+		p := domain.NewProject("no-compose", emptyOrigin, nil, nil)
+
+		_, _, err = collectCreate(t, projectService, &p)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "compose files are required")
+		assert.NoDirExists(t, p.WorkingDir, "The clone must be removed")
+	})
+}
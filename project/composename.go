@@ -0,0 +1,54 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gosimple/slug"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// composeNamePattern matches the project names Docker Compose accepts
+var composeNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// baseComposeName returns the compose project name a project name maps to: the name itself when
+// Compose accepts it, so that stacks started by hand can be adopted, and its slug otherwise
+func baseComposeName(name string) string {
+	if composeNamePattern.MatchString(name) {
+		return name
+	}
+	if base := slug.Make(name); composeNamePattern.MatchString(base) {
+		return base
+	}
+	return "project"
+}
+
+// resolveComposeName sets the compose project name of a project being created or renamed. Different
+// names can map to the same compose project name, e.g. "My App" and "my-app", and two projects
+// deployed under one name would replace each other's containers. A name taken by another project,
+// now or before a rename, gets the first free numeric suffix, as in "my-app-2".
+func (s *ProjectService) resolveComposeName(project *domain.Project) error {
+	projects, err := s.projectRepository.List()
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	taken := make(map[string]bool)
+	for _, other := range projects {
+		if other.ID == project.ID {
+			continue
+		}
+		taken[other.ComposeProjectName()] = true
+		for _, name := range other.PreviousNames {
+			taken[name] = true
+		}
+	}
+
+	base := baseComposeName(project.Name)
+	name := base
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	project.ComposeName = name
+	return nil
+}
@@ -0,0 +1,82 @@
+package project_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestComposeProjectNames(t *testing.T) {
+	// Stub docker: config prints a minimal configuration, everything else succeeds
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	projectService, cfg := newArchiveTestService(t)
+	archive := buildArchive(t, false,
+		archiveEntry{name: "compose.yaml", content: "services:\n  web:\n    image: busybox\n"})
+
+	// create creates a project named name and returns it as stored
+	create := func(t *testing.T, name string) *domain.Project {
+		t.Helper()
+		p := domain.NewProject(name, "", []string{"compose.yaml"}, nil)
+		p.SkipVolumeInit = true
+		created, err := projectService.CreateFromArchive(&p, bytes.NewReader(archive))
+		require.NoError(t, err)
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		return stored
+	}
+
+	first := create(t, "my-app")
+	assert.Equal(t, "my-app", first.ComposeProjectName())
+
+	// "My App" slugs to the name of the first project, so it gets a suffix
+	second := create(t, "My App")
+	assert.Equal(t, "my-app-2", second.ComposeProjectName())
+	composeProject, err := docker.NewComposeProject(second, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "my-app-2", composeProject.Name, "Compose should run the project under the resolved name")
+	assert.Contains(t, composeProject.UpCommandLine(), "--project-name my-app-2 ")
+
+	third := create(t, "My App!")
+	assert.Equal(t, "my-app-3", third.ComposeProjectName())
+
+	t.Run("names valid for compose are kept", func(t *testing.T) {
+		assert.Equal(t, "my_app", create(t, "my_app").ComposeProjectName())
+	})
+
+	t.Run("updates keep the resolved name", func(t *testing.T) {
+		second.ComposeName = ""
+		second.SkipVolumeInit = false
+		require.NoError(t, projectService.Update(second))
+		stored, err := projectService.Get(second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "my-app-2", stored.ComposeName)
+		assert.Empty(t, stored.PreviousNames)
+	})
+
+	t.Run("renames resolve the name again", func(t *testing.T) {
+		p, err := projectService.Get(first.ID)
+		require.NoError(t, err)
+		p.Name = "Shop"
+		require.NoError(t, projectService.Update(p))
+		renamed, err := projectService.Get(first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "shop", renamed.ComposeName)
+		assert.Equal(t, []string{"my-app"}, renamed.PreviousNames)
+
+		// The containers of the old name may still run, so the name is not given to another project
+		assert.Equal(t, "my-app-4", create(t, "MY APP").ComposeProjectName())
+	})
+}
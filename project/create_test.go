@@ -0,0 +1,81 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// collectCreate runs CreateStreaming and returns the messages it sent
+func collectCreate(
+	t *testing.T,
+	projectService *project.ProjectService,
+	p *domain.Project,
+) (*domain.Project, []docker.StreamMessage, error) {
+	t.Helper()
+	outputChan := make(chan docker.StreamMessage, 100)
+	created, err := projectService.CreateStreaming(p, outputChan)
+	close(outputChan)
+
+	var messages []docker.StreamMessage
+	for msg := range outputChan {
+		messages = append(messages, msg)
+	}
+	return created, messages, err
+}
+
+func TestCreateStreaming(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	p := domain.NewProject("streamed", originDir, []string{"compose.yaml", "compose.prod.yaml"}, nil)
+	created, messages, err := collectCreate(t, projectService, &p)
+	require.NoError(t, err)
+	shortCommit := created.LocalCommitStr()[:8]
+
+	assert.Equal(t, []docker.StreamMessage{
+		{Type: "info", Content: "Detecting the default branch of " + originDir + "..."},
+		{Type: "info", Content: "Using default branch master"},
+		{Type: "info", Content: "Cloning " + originDir + " (branch master)..."},
+		{Type: "info", Content: "Cloned commit " + shortCommit},
+		{Type: "info", Content: "Validating compose files..."},
+		{Type: "stderr", Content: "Warning: compose file compose.prod.yaml is not in the repository"},
+		{Type: "info", Content: "Saving project..."},
+		{Type: "success", Content: "Project streamed created with ID " + created.ID.String()},
+	}, messages)
+
+	t.Run("failed save removes the clone", func(t *testing.T) {
+		// Same ID as the created project, so saving fails after the clone into a new directory
+		duplicate := domain.NewProject("duplicate", originDir, []string{"compose.yaml"}, nil)
+		duplicate.ID = created.ID
+
+		_, messages, err := collectCreate(t, projectService, &duplicate)
+		require.Error(t, err)
+		assert.Equal(t, docker.StreamMessage{Type: "info", Content: "Removed the project directory"},
+			messages[len(messages)-1])
+		assert.NoDirExists(t, duplicate.WorkingDir)
+		assert.DirExists(t, created.WorkingDir, "The directory of the existing project must be kept")
+	})
+
+	t.Run("existing directory", func(t *testing.T) {
+		again := domain.NewProject("streamed", originDir, []string{"compose.yaml"}, nil)
+		again.ID = created.ID
+
+		_, _, err := collectCreate(t, projectService, &again)
+		assert.ErrorContains(t, err, "already exists")
+
+		entries, err := os.ReadDir(filepath.Join(created.WorkingDir, domain.GitDir))
+		require.NoError(t, err)
+		assert.NotEmpty(t, entries, "The existing clone must be kept")
+	})
+}
@@ -0,0 +1,269 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// BulkDeployOptions controls a deployment of several projects
+type BulkDeployOptions struct {
+	Pull       bool
+	Services   []string // Only used when a single project is deployed
+	Annotation domain.DeploymentAnnotation
+	// NoDeps leaves the services that Services depend on untouched. It has no effect without Services.
+	NoDeps bool
+	// RemovePreviousStacks removes the containers left under the previous names of renamed projects
+	RemovePreviousStacks bool
+	// RefreshImageDigests resolves the digests of the images of projects that pin them again, instead
+	// of reusing those pinned by earlier deployments
+	RefreshImageDigests bool
+	// EnsureDependencies also deploys prerequisites that were not selected and are not running
+	EnsureDependencies bool
+	// UpOptions enables up settings, such as showing the build output, on top of those of each project
+	UpOptions docker.UpOptions
+	// OnStart is called before each project is deployed
+	OnStart func(project *domain.Project)
+}
+
+// BulkDeployResult is the outcome of one project of a bulk deployment
+type BulkDeployResult struct {
+	Project *domain.Project
+	// Skipped is set when the project was not deployed because a prerequisite failed or is not running
+	Skipped bool
+	// Err is nil when the project was deployed
+	Err error
+}
+
+// validateDependencies checks that the project's prerequisites exist and that they do not form a
+// cycle together with the dependencies of the other projects
+func (s *ProjectService) validateDependencies(project *domain.Project) error {
+	if len(project.DependsOn) == 0 {
+		return nil
+	}
+
+	projects, err := s.projectRepository.List()
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	byID := make(map[uuid.UUID]*domain.Project, len(projects)+1)
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+	byID[project.ID] = project
+
+	for _, id := range project.DependsOn {
+		if _, ok := byID[id]; !ok {
+			return fmt.Errorf("prerequisite project %s does not exist", id)
+		}
+	}
+
+	if cycle := findDependencyCycle(byID, project.ID); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, id := range cycle {
+			names[i] = byID[id].Name
+		}
+		return fmt.Errorf("dependency cycle: %s", strings.Join(names, " -> "))
+	}
+	return nil
+}
+
+// findDependencyCycle returns a cycle of prerequisites through start, beginning and ending with
+// start, or nil if there is none. Prerequisites missing from projects are ignored.
+func findDependencyCycle(projects map[uuid.UUID]*domain.Project, start uuid.UUID) []uuid.UUID {
+	visited := make(map[uuid.UUID]bool)
+	var path []uuid.UUID
+
+	var visit func(id uuid.UUID) bool
+	visit = func(id uuid.UUID) bool {
+		path = append(path, id)
+		for _, dep := range projects[id].DependsOn {
+			if dep == start {
+				path = append(path, dep)
+				return true
+			}
+			if _, ok := projects[dep]; !ok || visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			if visit(dep) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if visit(start) {
+		return path
+	}
+	return nil
+}
+
+// DeploymentOrder returns the projects ordered so that each comes after its prerequisites. With
+// withPrerequisites, the prerequisites of the given projects are included, transitively, and must
+// exist; otherwise only the order among the given projects is considered.
+func (s *ProjectService) DeploymentOrder(
+	projectIDs []uuid.UUID,
+	withPrerequisites bool,
+) ([]*domain.Project, error) {
+	projects, err := s.projectRepository.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	byID := make(map[uuid.UUID]*domain.Project, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+
+	selected := make(map[uuid.UUID]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("project %s not found", id)
+		}
+		selected[id] = true
+	}
+
+	var order []*domain.Project
+	state := make(map[uuid.UUID]int) // 1 while visiting, 2 once ordered
+
+	var visit func(id uuid.UUID, dependent *domain.Project) error
+	visit = func(id uuid.UUID, dependent *domain.Project) error {
+		project, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("project %s depends on project %s, which does not exist", dependent.Name, id)
+		}
+		switch state[id] {
+		case 1:
+			return fmt.Errorf("dependency cycle through project %s", project.Name)
+		case 2:
+			return nil
+		}
+		state[id] = 1
+		for _, dep := range project.DependsOn {
+			if !withPrerequisites && !selected[dep] {
+				continue
+			}
+			if err := visit(dep, project); err != nil {
+				return err
+			}
+		}
+		state[id] = 2
+		order = append(order, project)
+		return nil
+	}
+
+	for _, id := range projectIDs {
+		if err := visit(id, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// CheckDependencies returns an error if a prerequisite of the project does not exist or is not running
+func (s *ProjectService) CheckDependencies(projectID uuid.UUID) error {
+	project, err := s.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+
+	for _, id := range project.DependsOn {
+		prerequisite, err := s.projectRepository.FindByID(id)
+		if err != nil {
+			return fmt.Errorf("prerequisite project %s does not exist", id)
+		}
+		status, err := s.GetStatus(id)
+		if err != nil {
+			return fmt.Errorf("failed to get status of prerequisite project %s: %w", prerequisite.Name, err)
+		}
+		if projectStatus := status.Status.ProjectStatus(); projectStatus != domain.ProjectStatusRunning {
+			return fmt.Errorf("prerequisite project %s is %s", prerequisite.Name, projectStatus)
+		}
+	}
+	return nil
+}
+
+// DeployManyPiping deploys projects one after another, each after its prerequisites. A project is
+// skipped when one of its prerequisites failed to deploy or is not running. With
+// EnsureDependencies, prerequisites that were not selected are deployed first unless they are
+// already running. Output is piped to the terminal, as with DeployPiping.
+func (s *ProjectService) DeployManyPiping(
+	projectIDs []uuid.UUID,
+	opts BulkDeployOptions,
+) ([]BulkDeployResult, error) {
+	order, err := s.DeploymentOrder(projectIDs, opts.EnsureDependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	services := opts.Services
+	if len(projectIDs) > 1 {
+		services = nil
+	}
+	if opts.NoDeps && len(services) == 0 {
+		slog.Warn("Deploying all services, no-deps only applies when services are selected")
+	}
+
+	names := make(map[uuid.UUID]string, len(order))
+	for _, project := range order {
+		names[project.ID] = project.Name
+	}
+
+	failed := make(map[uuid.UUID]bool)
+	var results []BulkDeployResult
+	for _, project := range order {
+		result := BulkDeployResult{Project: project}
+
+		if i := slices.IndexFunc(project.DependsOn, func(id uuid.UUID) bool { return failed[id] }); i >= 0 {
+			result.Skipped = true
+			result.Err = fmt.Errorf("prerequisite project %s was not deployed", names[project.DependsOn[i]])
+		} else if err := s.CheckDependencies(project.ID); err != nil {
+			result.Skipped = true
+			result.Err = err
+		}
+		if result.Skipped {
+			slog.Warn("Skipping deployment",
+				"project_id", project.ID,
+				"project_name", project.Name,
+				"reason", result.Err)
+			failed[project.ID] = true
+			results = append(results, result)
+			continue
+		}
+
+		// Prerequisites pulled in by EnsureDependencies are left alone when they already run
+		if !slices.Contains(projectIDs, project.ID) {
+			if status, err := s.GetStatus(project.ID); err == nil &&
+				status.Status.ProjectStatus() == domain.ProjectStatusRunning {
+				continue
+			}
+		}
+
+		if opts.OnStart != nil {
+			opts.OnStart(project)
+		}
+		err := pipeOutput(func(outputChan chan<- docker.StreamMessage) error {
+			return s.deploy(project.ID, deployOptions{
+				pull:                 opts.Pull,
+				services:             services,
+				noDeps:               opts.NoDeps,
+				annotation:           opts.Annotation,
+				removePreviousStacks: opts.RemovePreviousStacks,
+				refreshDigests:       opts.RefreshImageDigests,
+				up:                   opts.UpOptions,
+			}, outputChan)
+		})
+		if err != nil {
+			result.Err = err
+			failed[project.ID] = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
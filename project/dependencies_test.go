@@ -0,0 +1,121 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestProjectDependencies(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(name string, dependsOn ...uuid.UUID) *domain.Project {
+		projectID := uuid.New()
+		created, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusStopped,
+			DependsOn:    dependsOn,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	databaseProject := createProject("database")
+	backend := createProject("backend", databaseProject.ID)
+	frontend := createProject("frontend", backend.ID)
+
+	orderedNames := func(projects []*domain.Project) []string {
+		names := make([]string, len(projects))
+		for i, p := range projects {
+			names[i] = p.Name
+		}
+		return names
+	}
+
+	t.Run("dependencies are stored", func(t *testing.T) {
+		stored, err := projectService.Get(frontend.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{backend.ID}, stored.DependsOn)
+	})
+
+	t.Run("orders selected projects after their prerequisites", func(t *testing.T) {
+		order, err := projectService.DeploymentOrder([]uuid.UUID{frontend.ID, databaseProject.ID, backend.ID}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database", "backend", "frontend"}, orderedNames(order))
+	})
+
+	t.Run("ignores prerequisites that were not selected", func(t *testing.T) {
+		order, err := projectService.DeploymentOrder([]uuid.UUID{frontend.ID}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"frontend"}, orderedNames(order))
+	})
+
+	t.Run("includes prerequisites transitively", func(t *testing.T) {
+		order, err := projectService.DeploymentOrder([]uuid.UUID{frontend.ID}, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database", "backend", "frontend"}, orderedNames(order))
+	})
+
+	t.Run("rejects a cycle", func(t *testing.T) {
+		stored, err := projectService.Get(databaseProject.ID)
+		require.NoError(t, err)
+		stored.DependsOn = []uuid.UUID{frontend.ID}
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dependency cycle: database -> frontend -> backend -> database")
+
+		unchanged, err := projectService.Get(databaseProject.ID)
+		require.NoError(t, err)
+		assert.Empty(t, unchanged.DependsOn)
+	})
+
+	t.Run("rejects a self dependency", func(t *testing.T) {
+		stored, err := projectService.Get(backend.ID)
+		require.NoError(t, err)
+		stored.DependsOn = []uuid.UUID{backend.ID}
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dependency cycle: backend -> backend")
+	})
+
+	t.Run("rejects a missing prerequisite", func(t *testing.T) {
+		missingID := uuid.New()
+		stored, err := projectService.Get(frontend.ID)
+		require.NoError(t, err)
+		stored.DependsOn = []uuid.UUID{backend.ID, missingID}
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "prerequisite project "+missingID.String()+" does not exist")
+	})
+
+	t.Run("reports a prerequisite removed later", func(t *testing.T) {
+		orphan := createProject("orphan", uuid.New())
+
+		_, err := projectService.DeploymentOrder([]uuid.UUID{orphan.ID}, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "project orphan depends on project")
+
+		order, err := projectService.DeploymentOrder([]uuid.UUID{orphan.ID}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orphan"}, orderedNames(order))
+	})
+
+	t.Run("unknown project", func(t *testing.T) {
+		_, err := projectService.DeploymentOrder([]uuid.UUID{uuid.New()}, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
@@ -0,0 +1,93 @@
+package project_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployConcurrencyLimit deploys many projects at once with a stub docker whose up command records
+// how many up commands run at the same time
+func TestDeployConcurrencyLimit(t *testing.T) {
+	const limit = 2
+
+	// Stub docker: each up leaves a marker while it runs and logs how many markers it saw
+	runDir := filepath.Join(t.TempDir(), "running")
+	require.NoError(t, os.Mkdir(runDir, 0o755))
+	logPath := filepath.Join(t.TempDir(), "concurrency.log")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		touch "`+runDir+`/$$"
+		ls "`+runDir+`" | wc -l >> "`+logPath+`"
+		sleep 0.2
+		rm "`+runDir+`/$$"
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	cfg.ComposeMaxDeploys = limit
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	var projects []*domain.Project
+	for i := range 6 {
+		p := domain.NewProject(fmt.Sprintf("burst-%d", i), originDir, []string{"compose.yaml"}, nil)
+		p.SkipVolumeInit = true
+		created, err := projectService.Create(&p)
+		require.NoError(t, err)
+		projects = append(projects, created)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(projects))
+	for _, p := range projects {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- projectService.DeployPiping(p.ID, false, nil, domain.DeploymentAnnotation{})
+		}()
+	}
+
+	// Deployments beyond the limit wait for a slot
+	require.Eventually(t, func() bool {
+		stats := projectService.DeployStats()
+		return stats.Running == limit && stats.Queued > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	logged, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	counts := strings.Fields(string(logged))
+	require.GreaterOrEqual(t, len(counts), len(projects), "Every deployment should run up")
+	for _, count := range counts {
+		n, err := strconv.Atoi(count)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, n, limit, "No more than %d deployments should run at once", limit)
+	}
+	assert.Equal(t, project.DeployStats{Limit: limit}, projectService.DeployStats())
+}
@@ -0,0 +1,92 @@
+package project
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// DeployStats reports how many deployments hold a slot and how many wait for one
+type DeployStats struct {
+	Limit   int `json:"limit"` // 0 when deployments are not limited
+	Running int `json:"running"`
+	Queued  int `json:"queued"`
+}
+
+// deploySlots limits the deployments that run at once across all projects, so that bulk deploys and
+// many projects changing at once do not overwhelm the host. Deployments beyond the limit wait in
+// arrival order.
+//
+// The slots are a queue of their own rather than part of projectLocks. The project locks serialize
+// the operations on one project and are plain mutexes: they do not hand the lock over in arrival
+// order and cannot tell how many wait, which the position shown to the user and DeployStats need.
+// A slot is also shared by all projects, so a deployment that waits for its own project must not
+// hold one, or it would block deployments of other projects.
+//
+// Lock order: a deployment takes the project lock (projectLocks.lockDeployment), then starts
+// sharing its output (deploymentOutputs.start) and takes a slot last. They are released in reverse
+// order. Nothing acquires the project lock or the output of a project while holding a slot.
+type deploySlots struct {
+	limit int
+
+	mu      sync.Mutex
+	running int
+	waiters []chan struct{} // Closed when a slot is handed to the waiter
+}
+
+func newDeploySlots(limit int) *deploySlots {
+	return &deploySlots{limit: limit}
+}
+
+// acquire takes a slot, telling the user when they have to wait for one. outputChan may be nil. The
+// returned function releases the slot, handing it to the longest waiting deployment.
+func (s *deploySlots) acquire(outputChan chan<- docker.StreamMessage) func() {
+	s.mu.Lock()
+	if s.limit <= 0 || s.running < s.limit {
+		s.running++
+		s.mu.Unlock()
+		return s.release
+	}
+	ready := make(chan struct{})
+	s.waiters = append(s.waiters, ready)
+	position := len(s.waiters)
+	s.mu.Unlock()
+
+	if outputChan != nil {
+		outputChan <- docker.StreamMessage{
+			Type: "info",
+			Content: fmt.Sprintf("Waiting for one of %d running deployments to finish (position %d in queue)...",
+				s.limit, position),
+		}
+	}
+	<-ready
+	return s.release
+}
+
+// release hands the slot to the first waiter, or frees it
+func (s *deploySlots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.waiters) > 0 {
+		// The slot passes on without being freed, so that a new deployment cannot take it first
+		next := s.waiters[0]
+		s.waiters = s.waiters[1:]
+		close(next)
+		return
+	}
+	s.running--
+}
+
+// stats returns the current slot usage
+func (s *deploySlots) stats() DeployStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return DeployStats{Limit: s.limit, Running: s.running, Queued: len(s.waiters)}
+}
+
+// DeployStats returns how many deployments run and how many wait for a slot across all projects
+func (s *ProjectService) DeployStats() DeployStats {
+	return s.deploySlots.stats()
+}
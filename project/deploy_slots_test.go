@@ -0,0 +1,86 @@
+package project
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestDeploySlotsLimitBurst(t *testing.T) {
+	slots := newDeploySlots(2)
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := slots.acquire(nil)
+			defer release()
+
+			n := running.Add(1)
+			for {
+				highest := maxRunning.Load()
+				if n <= highest || maxRunning.CompareAndSwap(highest, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning.Load(), "Burst should run exactly as many deployments as the limit allows")
+	assert.Equal(t, DeployStats{Limit: 2}, slots.stats())
+}
+
+func TestDeploySlotsQueueInOrder(t *testing.T) {
+	slots := newDeploySlots(1)
+	releaseFirst := slots.acquire(nil)
+
+	output := make(chan docker.StreamMessage, 1)
+	order := make(chan int, 2)
+	for i := range 2 {
+		go func() {
+			var out chan<- docker.StreamMessage
+			if i == 0 {
+				out = output
+			}
+			release := slots.acquire(out)
+			order <- i
+			release()
+		}()
+		// Queue the waiters one after the other
+		require.Eventually(t, func() bool { return slots.stats().Queued == i+1 }, time.Second, time.Millisecond)
+	}
+
+	assert.Equal(t, DeployStats{Limit: 1, Running: 1, Queued: 2}, slots.stats())
+	msg := <-output
+	assert.Equal(t, "info", msg.Type)
+	assert.Contains(t, msg.Content, "position 1 in queue")
+
+	releaseFirst()
+	assert.Equal(t, 0, <-order)
+	assert.Equal(t, 1, <-order)
+	require.Eventually(t, func() bool { return slots.stats() == DeployStats{Limit: 1} }, time.Second, time.Millisecond)
+}
+
+func TestDeploySlotsUnlimited(t *testing.T) {
+	slots := newDeploySlots(0)
+	releases := make([]func(), 0, 5)
+	for range 5 {
+		releases = append(releases, slots.acquire(nil))
+	}
+	assert.Equal(t, DeployStats{Running: 5}, slots.stats(), "Running deployments are counted without a limit")
+	for _, release := range releases {
+		release()
+	}
+	assert.Equal(t, DeployStats{}, slots.stats())
+}
@@ -0,0 +1,127 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestDeployVariables deploys a project with a stub docker that renders the deploy variables it
+// receives, and checks them in the deployment, the effective environment and the rendered config
+func TestDeployVariables(t *testing.T) {
+	upEnvFile := filepath.Join(t.TempDir(), "up-env")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		echo "services:"
+		echo "  web:"
+		echo "    labels:"
+		echo "      commit: $OAR_COMMIT"
+		echo "      deploy: $OAR_DEPLOY_ID"
+		echo "      project: $OAR_PROJECT"
+		echo "      env: $OAR_ENV"
+		exit 0
+		;;
+	up)
+		env | grep '^OAR_' | sort > `+upEnvFile+`
+		exit 0
+		;;
+	ps)
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	cfg.ComposeDeployEnv = "staging"
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	gitDir := filepath.Join(workingDir, domain.GitDir)
+	initDeployableRepo(t, gitDir)
+	// Env files cannot set deploy variables either, the deploy variables win
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, ".env"), []byte("OAR_ENV=from-repo\n"), 0o644))
+
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Variables:      []string{"DEPLOY_TARGET=eu"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+
+	deployments, err := projectService.ListDeployments(projectID)
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	deployment := deployments[0]
+	require.Equal(t, domain.DeploymentStatusCompleted, deployment.Status)
+
+	want := map[string]string{
+		docker.EnvCommit:      deployment.CommitHash,
+		docker.EnvProject:     "shop",
+		docker.EnvDeployID:    deployment.ID.String(),
+		docker.EnvEnvironment: "staging",
+	}
+
+	t.Run("compose up receives the deploy variables", func(t *testing.T) {
+		content, err := os.ReadFile(upEnvFile)
+		require.NoError(t, err)
+		upEnv := make(map[string]string)
+		for line := range strings.SplitSeq(strings.TrimSpace(string(content)), "\n") {
+			key, value, _ := strings.Cut(line, "=")
+			upEnv[key] = value
+		}
+		assert.Equal(t, want, upEnv)
+		assert.Contains(t, deployment.CommandLine, "OAR_DEPLOY_ID="+deployment.ID.String())
+	})
+
+	t.Run("effective environment", func(t *testing.T) {
+		vars, err := projectService.GetEffectiveEnv(projectID, false)
+		require.NoError(t, err)
+
+		resolved := make(map[string]docker.EnvVar)
+		for _, v := range vars {
+			resolved[v.Key] = v
+		}
+		for key, value := range want {
+			assert.Equal(t, value, resolved[key].Value, key)
+			assert.Equal(t, docker.EnvSourceOar, resolved[key].Source, key)
+		}
+		assert.Equal(t, "eu", resolved["DEPLOY_TARGET"].Value)
+	})
+
+	t.Run("rendered config", func(t *testing.T) {
+		rendered, _, err := projectService.GetConfig(projectID)
+		require.NoError(t, err)
+		assert.Contains(t, rendered, "commit: "+deployment.CommitHash)
+		assert.Contains(t, rendered, "deploy: "+deployment.ID.String())
+		assert.Contains(t, rendered, "project: shop")
+		assert.Contains(t, rendered, "env: staging")
+	})
+
+	t.Run("variables cannot set deploy variables", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.Variables = []string{"DEPLOY_TARGET=eu", "OAR_COMMIT=main"}
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "OAR_COMMIT is set by Oar")
+	})
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// summaryLimit is the most commits, files, images and configuration keys a deployment summary lists
+const summaryLimit = 20
+
+// deploymentBaseline is what a deployment is compared with to summarize what it changed
+type deploymentBaseline struct {
+	imageDigests   []string // Images pinned by the previous deployment
+	previousConfig string   // Configuration rendered before the pull, empty when it was not rendered
+	config         string   // Configuration rendered after the pull, empty when it was not rendered
+}
+
+// summarizeDeployment describes what a deployment changes compared with the previous one: the
+// commits applied, the files changed, the images whose digest changed and the configuration keys
+// that changed. Long lists are cut, with a count of what was left out.
+func (s *ProjectService) summarizeDeployment(
+	project *domain.Project,
+	deployment *domain.Deployment,
+	baseline deploymentBaseline,
+) string {
+	if deployment.PreviousCommit == "" {
+		return fmt.Sprintf("First deployment (%s), nothing to compare with", domain.ShortCommit(deployment.CommitHash))
+	}
+
+	var sections []string
+	if deployment.PreviousCommit != deployment.CommitHash {
+		sections = append(sections, s.summarizeCommits(project, deployment)...)
+	}
+	if images := summarizeImages(baseline.imageDigests, deployment.ImageDigests); len(images) > 0 {
+		sections = append(sections, summarySection("Images changed", images, 0))
+	}
+	if baseline.previousConfig != "" && baseline.config != "" {
+		keys, err := docker.ChangedConfigKeys(baseline.previousConfig, baseline.config)
+		if err != nil {
+			slog.Warn("Failed to compare configurations", "project_id", project.ID, "error", err)
+		} else if len(keys) > 0 {
+			more := max(len(keys)-summaryLimit, 0)
+			sections = append(sections, summarySection("Configuration changed", keys[:len(keys)-more], more))
+		}
+	}
+
+	if len(sections) == 0 {
+		return "No changes since the previous deployment"
+	}
+	return strings.Join(sections, "\n")
+}
+
+// summarizeCommits returns the sections of a summary for the commits and the files a deployment
+// changes. Archive projects have no history, only the archive they were deployed from changes.
+func (s *ProjectService) summarizeCommits(project *domain.Project, deployment *domain.Deployment) []string {
+	if !project.UsesGit() {
+		return []string{fmt.Sprintf("Archive changed from %s to %s",
+			domain.ShortCommit(deployment.PreviousCommit), domain.ShortCommit(deployment.CommitHash))}
+	}
+
+	gitDir, err := project.GitDir()
+	if err != nil {
+		slog.Warn("Failed to get git directory", "project_id", project.ID, "error", err)
+		return nil
+	}
+	changes, err := s.gitService.GetChanges(gitDir, deployment.PreviousCommit, deployment.CommitHash, summaryLimit)
+	if err != nil {
+		slog.Warn("Failed to list the changes of the deployment",
+			"project_id", project.ID,
+			"deployment_id", deployment.ID,
+			"error", err)
+		return []string{fmt.Sprintf("Changed from %s to %s",
+			domain.ShortCommit(deployment.PreviousCommit), domain.ShortCommit(deployment.CommitHash))}
+	}
+
+	var sections []string
+	if len(changes.Commits) > 0 {
+		sections = append(sections, summarySection("Commits", changes.Commits, changes.MoreCommits))
+	}
+	if len(changes.Files) > 0 {
+		sections = append(sections, summarySection("Files changed", changes.Files, changes.MoreFiles))
+	}
+	return sections
+}
+
+// summarizeImages lists the images whose pinned digest changed, or that were pinned for the first time
+func summarizeImages(previous, current []string) []string {
+	previousDigests := pinnedDigests(previous)
+	currentDigests := pinnedDigests(current)
+
+	var lines []string
+	for _, image := range slices.Sorted(maps.Keys(currentDigests)) {
+		digest := currentDigests[image]
+		previousDigest, ok := previousDigests[image]
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("%s %s (new)", image, shortDigest(digest)))
+		case previousDigest != digest:
+			lines = append(lines, fmt.Sprintf("%s %s -> %s", image, shortDigest(previousDigest), shortDigest(digest)))
+		}
+	}
+	return lines
+}
+
+// pinnedDigests maps pinned images, as image:tag@digest, to their digest
+func pinnedDigests(pinned []string) map[string]string {
+	digests := make(map[string]string, len(pinned))
+	for _, p := range pinned {
+		if image, digest, ok := docker.SplitPinnedImage(p); ok {
+			digests[image] = digest
+		}
+	}
+	return digests
+}
+
+// summarySection formats a titled list of a summary, noting how many entries were left out
+func summarySection(title string, lines []string, more int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d):", title, len(lines)+more)
+	for _, line := range lines {
+		b.WriteString("\n  " + strings.TrimSpace(line))
+	}
+	if more > 0 {
+		fmt.Fprintf(&b, "\n  ... and %d more", more)
+	}
+	return b.String()
+}
+
+// shortDigest shortens an image digest to 12 hex characters, like docker does
+func shortDigest(digest string) string {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || len(hex) <= 12 {
+		return digest
+	}
+	return algorithm + ":" + hex[:12]
+}
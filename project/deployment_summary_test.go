@@ -0,0 +1,114 @@
+package project_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeploymentSummary checks that each deployment records what it changed compared with the
+// previous one, with a stub docker whose registry serves the digest in a file
+func TestDeploymentSummary(t *testing.T) {
+	digestFile := filepath.Join(t.TempDir(), "digest")
+	require.NoError(t, os.WriteFile(digestFile, []byte(firstDigest), 0o644))
+	newStubDocker(t, `if [ "$1" = "buildx" ]; then
+	cat `+digestFile+`
+	exit 0
+fi
+for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: nginx:1.27\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	gitDir := filepath.Join(workingDir, domain.GitDir)
+	initDeployableRepo(t, gitDir)
+	_, err := repos.projects.Create(&domain.Project{
+		ID:              projectID,
+		Name:            "shop",
+		GitURL:          "https://example.com/repo.git",
+		GitBranch:       "main",
+		WorkingDir:      workingDir,
+		ComposeFiles:    []string{"compose.yaml"},
+		Status:          domain.ProjectStatusStopped,
+		SkipVolumeInit:  true,
+		PinImageDigests: true,
+	})
+	require.NoError(t, err)
+
+	// deploy deploys the project and returns the summary of the stored deployment
+	deploy := func(t *testing.T, refresh bool) string {
+		t.Helper()
+		results, err := projectService.DeployManyPiping([]uuid.UUID{projectID},
+			project.BulkDeployOptions{RefreshImageDigests: refresh})
+		require.NoError(t, err)
+		require.NoError(t, results[0].Err)
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		return deployments[0].Summary
+	}
+	// commit commits a new file to the repository of the project
+	commit := func(t *testing.T, name string) {
+		t.Helper()
+		repo, err := gogit.PlainOpen(gitDir)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(gitDir, name), []byte(name), 0o644))
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add(name)
+		require.NoError(t, err)
+		_, err = worktree.Commit("Add "+name, &gogit.CommitOptions{
+			Author: &object.Signature{Name: "Jane Doe", Email: "jane@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("first deployment", func(t *testing.T) {
+		assert.Regexp(t, `^First deployment \([0-9a-f]{8}\), nothing to compare with$`, deploy(t, false))
+	})
+
+	t.Run("redeployment without changes", func(t *testing.T) {
+		assert.Equal(t, "No changes since the previous deployment", deploy(t, false))
+	})
+
+	t.Run("commits, files and images", func(t *testing.T) {
+		commit(t, "README.md")
+		require.NoError(t, os.WriteFile(digestFile, []byte(movedDigest), 0o644))
+
+		summary := deploy(t, true)
+		assert.Regexp(t, `(?m)^Commits \(1\):\n  [0-9a-f]{8} Add README\.md \(Jane Doe\)$`, summary)
+		assert.Contains(t, summary, "Files changed (1):\n  A README.md")
+		assert.Contains(t, summary, "Images changed (1):\n  nginx:1.27 sha256:aaaaaaaaaaaa -> sha256:bbbbbbbbbbbb")
+	})
+
+	t.Run("large changes are truncated", func(t *testing.T) {
+		for i := range 25 {
+			commit(t, fmt.Sprintf("file%02d.txt", i))
+		}
+
+		summary := deploy(t, false)
+		assert.Contains(t, summary, "Commits (25):")
+		assert.Contains(t, summary, "Files changed (25):")
+		assert.Contains(t, summary, "  ... and 5 more")
+		assert.NotContains(t, summary, "Images changed")
+	})
+}
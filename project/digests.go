@@ -0,0 +1,116 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// pinImageDigests pins the images of the project's services to digests for the deployment, when the
+// project pins them. Digests pinned by earlier deployments are reused, so that a redeployment runs the
+// same images; images not pinned yet, e.g. after a tag changed in the repository, and all of them when
+// refresh is set, are resolved from their registry. A refresh reports tags that moved since they were
+// pinned. Images pinned by the compose files and images that are built are left alone. An image whose
+// digest cannot be resolved is deployed by tag. The pins are stored with the project when the
+// deployment ends, and recorded with the deployment.
+func (s *ProjectService) pinImageDigests(
+	project *domain.Project,
+	composeProject *docker.ComposeProject,
+	deployment *domain.Deployment,
+	refresh bool,
+	report func(msg, msgType string),
+) error {
+	if !project.PinImageDigests {
+		return nil
+	}
+
+	images, err := composeProject.AllServiceImages()
+	if err != nil {
+		return err
+	}
+
+	previous := make(map[string]string, len(project.ImageDigests))
+	for _, pinned := range project.ImageDigests {
+		if image, _, ok := docker.SplitPinnedImage(pinned); ok {
+			previous[image] = pinned
+		}
+	}
+
+	// Offline, the registry cannot be asked, so only digests pinned before are used
+	offline := composeProject.ImagePolicy.Offline
+	if refresh && !offline {
+		report("Resolving image digests...", "info")
+	}
+
+	pins := make(map[string]string)
+	pinnedImages := make(map[string]string)
+	for _, image := range images {
+		if docker.IsPinnedImage(image.Image) {
+			continue
+		}
+		pinned, done := pinnedImages[image.Image]
+		if !done {
+			pinned = s.pinImage(image.Image, previous[image.Image], refresh, offline, report)
+			pinnedImages[image.Image] = pinned
+		}
+		if pinned != "" {
+			pins[image.Service] = pinned
+		}
+	}
+
+	if err := composeProject.PinImages(pins); err != nil {
+		return err
+	}
+
+	project.ImageDigests = nil
+	for _, pinned := range pinnedImages {
+		if pinned != "" {
+			project.ImageDigests = append(project.ImageDigests, pinned)
+		}
+	}
+	slices.Sort(project.ImageDigests)
+	deployment.ImageDigests = project.ImageDigests
+	for _, pinned := range project.ImageDigests {
+		report("Pinned image "+pinned, "stdout")
+	}
+	return nil
+}
+
+// pinImage returns image pinned to a digest, or an empty string when it is deployed by tag. previous
+// is the reference pinned by an earlier deployment, empty if none.
+func (s *ProjectService) pinImage(
+	image, previous string,
+	refresh, offline bool,
+	report func(msg, msgType string),
+) string {
+	if previous != "" && (!refresh || offline) {
+		return previous
+	}
+	if offline {
+		report(fmt.Sprintf("Warning: offline mode, the digest of %s cannot be resolved, it is deployed by tag",
+			image), "stderr")
+		return ""
+	}
+
+	digest, err := docker.ResolveImageDigest(image, s.config.ComposeQueryTimeout)
+	if err != nil {
+		slog.Warn("Failed to resolve image digest", "image", image, "error", err)
+		if previous != "" {
+			report(fmt.Sprintf("Warning: %v, keeping the digest pinned before", err), "stderr")
+			return previous
+		}
+		report(fmt.Sprintf("Warning: %v, it is deployed by tag", err), "stderr")
+		return ""
+	}
+
+	pinned := docker.PinnedImage(image, digest)
+	if previous != "" && previous != pinned {
+		_, previousDigest, _ := docker.SplitPinnedImage(previous)
+		report(fmt.Sprintf("Warning: tag %s moved from %s to %s since it was pinned", image, previousDigest, digest),
+			"stderr")
+	}
+	return pinned
+}
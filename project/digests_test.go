@@ -0,0 +1,135 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+const (
+	firstDigest  = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	movedDigest  = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	pinnedDigest = "sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc"
+)
+
+// TestDeployPinsImageDigests checks that a deployment pins the images of its services to digests and
+// records them, and that redeployments keep the pins until they are refreshed
+func TestDeployPinsImageDigests(t *testing.T) {
+	stateDir := t.TempDir()
+	digestFile := filepath.Join(stateDir, "digest")
+	require.NoError(t, os.WriteFile(digestFile, []byte(firstDigest), 0o644))
+	// Stub docker: the registry serves the digest in digestFile for any tag. Of the services, app is
+	// built and db is already pinned by the compose file, so only web is pinned by Oar.
+	callsFile := newStubDocker(t, `if [ "$1" = "buildx" ]; then
+	cat `+digestFile+`
+	exit 0
+fi
+for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: nginx:1.27\n  app:\n    build: .\n    image: app\n'
+		printf '  db:\n    image: postgres@`+pinnedDigest+`\n'
+		exit 0
+		;;
+	up|ps)
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:              projectID,
+		Name:            "shop",
+		GitURL:          "https://example.com/repo.git",
+		GitBranch:       "main",
+		WorkingDir:      workingDir,
+		ComposeFiles:    []string{"compose.yaml"},
+		Status:          domain.ProjectStatusStopped,
+		SkipVolumeInit:  true,
+		PinImageDigests: true,
+	})
+	require.NoError(t, err)
+
+	// deploy deploys the project and returns the stored record of the deployment and the docker calls
+	deploy := func(t *testing.T, refresh bool) (*domain.Deployment, string) {
+		t.Helper()
+		require.NoError(t, os.Remove(callsFile), "stub docker called before the deployment")
+		results, err := projectService.DeployManyPiping([]uuid.UUID{projectID},
+			project.BulkDeployOptions{RefreshImageDigests: refresh})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		return deployments[0], string(calls)
+	}
+	// upCall returns the arguments of the compose up call
+	upCall := func(t *testing.T, calls string) string {
+		t.Helper()
+		for call := range strings.Lines(calls) {
+			if strings.Contains(call, " up ") {
+				return call
+			}
+		}
+		t.Fatalf("compose up not called: %s", calls)
+		return ""
+	}
+	require.NoError(t, os.WriteFile(callsFile, nil, 0o644))
+
+	t.Run("first deployment pins and records digests", func(t *testing.T) {
+		deployment, calls := deploy(t, false)
+		assert.Equal(t, []string{"nginx:1.27@" + firstDigest}, deployment.ImageDigests)
+		assert.Contains(t, calls, "buildx imagetools inspect --format {{.Manifest.Digest}} nginx:1.27")
+		assert.NotContains(t, calls, "imagetools inspect --format {{.Manifest.Digest}} app")
+		assert.NotContains(t, calls, "imagetools inspect --format {{.Manifest.Digest}} postgres")
+		assert.Contains(t, upCall(t, calls), "--file "+filepath.Join(workingDir, domain.GitDir, docker.ImagePinsFile))
+
+		pins, err := os.ReadFile(filepath.Join(workingDir, domain.GitDir, docker.ImagePinsFile))
+		require.NoError(t, err)
+		assert.Contains(t, string(pins), "image: nginx:1.27@"+firstDigest)
+		assert.NotContains(t, string(pins), "app:")
+		assert.NotContains(t, string(pins), "db:")
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nginx:1.27@" + firstDigest}, stored.ImageDigests)
+	})
+
+	// The tag moves in the registry
+	require.NoError(t, os.WriteFile(digestFile, []byte(movedDigest), 0o644))
+
+	t.Run("redeployment keeps the pinned digests", func(t *testing.T) {
+		deployment, calls := deploy(t, false)
+		assert.Equal(t, []string{"nginx:1.27@" + firstDigest}, deployment.ImageDigests)
+		assert.NotContains(t, calls, "imagetools")
+	})
+
+	t.Run("refresh pins the digest the tag moved to", func(t *testing.T) {
+		deployment, calls := deploy(t, true)
+		assert.Contains(t, calls, "imagetools")
+		assert.Equal(t, []string{"nginx:1.27@" + movedDigest}, deployment.ImageDigests)
+		assert.Contains(t, deployment.Stderr,
+			"tag nginx:1.27 moved from "+firstDigest+" to "+movedDigest+" since it was pinned")
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nginx:1.27@" + movedDigest}, stored.ImageDigests)
+	})
+}
@@ -0,0 +1,157 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+)
+
+// diskUsageCacheTTL is how long a project's measured disk usage is shown before it is measured again
+const diskUsageCacheTTL = 5 * time.Minute
+
+// DiskUsage is the disk space a project takes, in bytes
+type DiskUsage struct {
+	WorkingDir int64 // The checked out repository and other files in the project's directory
+	Volumes    []docker.VolumeSize
+	Images     []ImageUsage
+	Total      int64 // Working directory, volumes and images not shared with other projects
+	Warning    int64 // The configured warning threshold, 0 when none is configured
+	Exceeds    bool  // Total is above the warning threshold
+	ComputedAt time.Time
+}
+
+// ImageUsage is the disk space taken by an image of a project
+type ImageUsage struct {
+	docker.ImageSize
+	Shared bool // Other projects use the image as well, so it is left out of the total
+}
+
+// diskUsageCache remembers the measured disk usage of projects, since walking large directories and
+// volumes takes a while
+type diskUsageCache struct {
+	mu     sync.Mutex
+	usages map[uuid.UUID]*DiskUsage
+}
+
+func newDiskUsageCache() *diskUsageCache {
+	return &diskUsageCache{usages: make(map[uuid.UUID]*DiskUsage)}
+}
+
+// get returns the usage of a project measured less than diskUsageCacheTTL ago, or nil
+func (c *diskUsageCache) get(projectID uuid.UUID) *DiskUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usage := c.usages[projectID]
+	if usage == nil || time.Since(usage.ComputedAt) >= diskUsageCacheTTL {
+		return nil
+	}
+	return usage
+}
+
+func (c *diskUsageCache) set(projectID uuid.UUID, usage *DiskUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.usages[projectID] = usage
+}
+
+// GetDiskUsage returns the disk space taken by the project's working directory, by the volumes Compose
+// created for it and by its images. Measuring is slow for large projects, so the result is reused for
+// a few minutes.
+func (s *ProjectService) GetDiskUsage(projectID uuid.UUID) (*DiskUsage, error) {
+	if usage := s.diskUsage.get(projectID); usage != nil {
+		return usage, nil
+	}
+
+	project, err := s.Get(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	usage := &DiskUsage{}
+	if usage.WorkingDir, err = directorySize(project.WorkingDir); err != nil {
+		return nil, fmt.Errorf("failed to measure working directory: %w", err)
+	}
+	if usage.Volumes, err = composeProject.VolumeSizes(); err != nil {
+		return nil, err
+	}
+
+	images, err := composeProject.ListImages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	imageSizes, err := docker.ImageSizes(images)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := s.SharedImages(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Total = usage.WorkingDir
+	for _, volume := range usage.Volumes {
+		if volume.Size > 0 {
+			usage.Total += volume.Size
+		}
+	}
+	for _, image := range imageSizes {
+		imageUsage := ImageUsage{ImageSize: image, Shared: slices.Contains(shared, image.Image)}
+		if !imageUsage.Shared {
+			usage.Total += image.Size
+		}
+		usage.Images = append(usage.Images, imageUsage)
+	}
+	usage.ComputedAt = time.Now()
+
+	usage.Warning = s.config.DiskUsageWarning
+	if usage.Warning > 0 && usage.Total > usage.Warning {
+		usage.Exceeds = true
+		slog.Warn("Project uses more disk space than the warning threshold",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"disk_usage", usage.Total,
+			"threshold", s.config.DiskUsageWarning)
+	}
+
+	s.diskUsage.set(projectID, usage)
+	return usage, nil
+}
+
+// directorySize sums the sizes of the regular files under dir, which may not exist yet
+func directorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,92 @@
+package project_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestGetDiskUsage measures a project with a large named volume, with a stub docker that lists the
+// project's images and a fake Docker API that reports the sizes of volumes and images
+func TestGetDiskUsage(t *testing.T) {
+	newStubDocker(t, `case "$*" in
+*" config --images")
+	printf 'postgres:16\nshop-web\n'
+	;;
+esac`)
+
+	var diskUsageRequests atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/_ping"):
+			_, _ = w.Write([]byte("OK"))
+		case strings.HasSuffix(r.URL.Path, "/system/df"):
+			diskUsageRequests.Add(1)
+			_, _ = w.Write([]byte(`{"Volumes":[
+				{"Name":"shop_pgdata","Labels":{"com.docker.compose.project":"shop"},
+				 "UsageData":{"Size":53687091200,"RefCount":1}},
+				{"Name":"blog_data","Labels":{"com.docker.compose.project":"blog"},
+				 "UsageData":{"Size":1024,"RefCount":1}}]}`))
+		case strings.HasSuffix(r.URL.Path, "/images/postgres:16/json"):
+			_, _ = w.Write([]byte(`{"Id":"sha256:abc","Size":450000000}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such image"}`))
+		}
+	}))
+	t.Cleanup(api.Close)
+	t.Setenv("DOCKER_HOST", "tcp://"+api.Listener.Addr().String())
+
+	cfg := newTestConfig(t)
+	cfg.DiskUsageWarning = 10_000_000_000
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	require.NoError(t, os.MkdirAll(filepath.Join(workingDir, domain.GitDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workingDir, domain.GitDir, "compose.yaml"),
+		[]byte(strings.Repeat("x", 4096)), 0o644))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:           projectID,
+		Name:         "shop",
+		GitURL:       "https://example.com/repo.git",
+		GitBranch:    "main",
+		WorkingDir:   workingDir,
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusRunning,
+	})
+	require.NoError(t, err)
+
+	usage, err := projectService.GetDiskUsage(projectID)
+	require.NoError(t, err)
+
+	// The working directory holds the env file generated for Compose as well
+	assert.GreaterOrEqual(t, usage.WorkingDir, int64(4096))
+	assert.Equal(t, []docker.VolumeSize{{Name: "shop_pgdata", Size: 53687091200}}, usage.Volumes)
+	require.Len(t, usage.Images, 1, "images that are not present are left out")
+	assert.Equal(t, "postgres:16", usage.Images[0].Image)
+	assert.Equal(t, int64(450000000), usage.Images[0].Size)
+	assert.False(t, usage.Images[0].Shared)
+	assert.Equal(t, usage.WorkingDir+53687091200+450000000, usage.Total)
+	assert.True(t, usage.Exceeds)
+	assert.Equal(t, cfg.DiskUsageWarning, usage.Warning)
+
+	t.Run("recent measurement is reused", func(t *testing.T) {
+		again, err := projectService.GetDiskUsage(projectID)
+		require.NoError(t, err)
+		assert.Same(t, usage, again)
+		assert.Equal(t, int32(1), diskUsageRequests.Load())
+	})
+}
@@ -0,0 +1,71 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestGetEffectiveEnv resolves the environment of a project whose repository is already on disk
+func TestGetEffectiveEnv(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-env-project")
+	gitDir := filepath.Join(workingDir, domain.GitDir)
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, ".env"), []byte("PORT=80\nDB_PASSWORD=from-repo\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "prod.env"), []byte("PORT=8080\n"), 0o644))
+
+	_, err := repos.projects.Create(&domain.Project{
+		ID:           projectID,
+		Name:         "env-project",
+		GitURL:       "https://example.com/repo.git",
+		GitBranch:    "main",
+		WorkingDir:   workingDir,
+		ComposeFiles: []string{"compose.yaml"},
+		EnvFiles:     []string{"prod.env"},
+		Variables:    []string{"PORT=9000", "DB_PASSWORD=from-variables"},
+		Status:       domain.ProjectStatusStopped,
+	})
+	require.NoError(t, err)
+
+	vars, err := projectService.GetEffectiveEnv(projectID, false)
+	require.NoError(t, err)
+
+	resolved := make(map[string]docker.EnvVar)
+	for _, v := range vars {
+		resolved[v.Key] = v
+	}
+	assert.Equal(t, "9000", resolved["PORT"].Value)
+	assert.Equal(t, docker.EnvSourceVariables, resolved["PORT"].Source)
+	assert.True(t, resolved["DB_PASSWORD"].Masked, "Secrets are masked by default")
+	assert.NotContains(t, resolved["DB_PASSWORD"].Value, "from-variables")
+
+	vars, err = projectService.GetEffectiveEnv(projectID, true)
+	require.NoError(t, err)
+	for _, v := range vars {
+		if v.Key == "DB_PASSWORD" {
+			assert.Equal(t, "from-variables", v.Value)
+			assert.False(t, v.Masked)
+		}
+	}
+
+	// Env files outside the repository are refused
+	stored, err := projectService.Get(projectID)
+	require.NoError(t, err)
+	for _, file := range []string{"../secret.env", "/etc/passwd"} {
+		stored.EnvFiles = []string{file}
+		err := projectService.Update(stored)
+		require.Error(t, err, file)
+		assert.Contains(t, err.Error(), "inside the repository")
+	}
+}
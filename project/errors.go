@@ -37,6 +37,8 @@ func FormatErrorForUser(err error) string {
 		return "git authentication required - repository needs credentials to access"
 	case strings.Contains(errStr, "access denied") && strings.Contains(errStr, "git"):
 		return "git access denied - please check your repository permissions"
+	case strings.Contains(errStr, "not found on remote"):
+		return err.Error()
 	case strings.Contains(errStr, "repository not found") || strings.Contains(errStr, "not found"):
 		return "git repository not found - please check the URL and your access permissions"
 	case strings.Contains(errStr, "invalid credentials"):
@@ -0,0 +1,139 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestDeploymentFailedServices deploys a two-service project with a stub docker whose up fails, and
+// checks that the deployment records and streams which service failed
+func TestDeploymentFailedServices(t *testing.T) {
+	// Stub docker: db has an image that cannot be pulled. STUB_FAIL selects whether up fails while
+	// creating the containers or while starting them; ps and logs report the matching state.
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  db:\n    image: nope/missing:1\n  web:\n    image: nginx:1.27\n'
+		exit 0
+		;;
+	up)
+		case " $* " in
+		*" --no-start "*)
+			if [ "$STUB_FAIL" = "create" ]; then
+				echo "pull access denied for nope/missing" >&2
+				exit 1
+			fi
+			;;
+		*)
+			echo "dependency failed to start: container failures-db-1 exited (1)" >&2
+			exit 1
+			;;
+		esac
+		exit 0
+		;;
+	ps)
+		if [ "$STUB_FAIL" = "create" ]; then
+			echo '{"Service":"web","Name":"failures-web-1","State":"created","ExitCode":0}'
+		else
+			echo '{"Service":"db","Name":"failures-db-1","State":"exited","ExitCode":1}'
+			echo '{"Service":"web","Name":"failures-web-1","State":"created","ExitCode":0}'
+		fi
+		exit 0
+		;;
+	logs)
+		printf 'db-1  | starting\ndb-1  | fatal: invalid configuration\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	tests := []struct {
+		name         string
+		fail         string
+		wantFailures []domain.ServiceFailure
+		wantSummary  []string
+	}{
+		{
+			name:         "image cannot be pulled",
+			fail:         "create",
+			wantFailures: []domain.ServiceFailure{{Service: "db"}},
+			wantSummary:  []string{"service 'db' failed: no container was created"},
+		},
+		{
+			name: "service exits",
+			fail: "start",
+			wantFailures: []domain.ServiceFailure{
+				{
+					Service:  "db",
+					State:    "exited",
+					ExitCode: 1,
+					LastLogs: []string{"db-1  | starting", "db-1  | fatal: invalid configuration"},
+				},
+				{Service: "web", State: "created"},
+			},
+			wantSummary: []string{"service 'db' failed: exited 1", "service 'web' failed: not started"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STUB_FAIL", tt.fail)
+
+			cfg := newTestConfig(t)
+			projectService, repos := newTestProjectService(t, cfg)
+
+			projectID := uuid.New()
+			workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-failures")
+			initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+
+			_, err := repos.projects.Create(&domain.Project{
+				ID:             projectID,
+				Name:           "failures",
+				GitURL:         "https://example.com/repo.git",
+				GitBranch:      "main",
+				WorkingDir:     workingDir,
+				ComposeFiles:   []string{"compose.yaml"},
+				Status:         domain.ProjectStatusStopped,
+				SkipVolumeInit: true,
+			})
+			require.NoError(t, err)
+
+			outputChan := make(chan docker.StreamMessage, 100)
+			var errorMessages []string
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for msg := range outputChan {
+					if msg.Type == "error" {
+						errorMessages = append(errorMessages, msg.Content)
+					}
+				}
+			}()
+
+			err = projectService.DeployStreaming(projectID, false, nil, domain.DeploymentAnnotation{}, outputChan)
+			close(outputChan)
+			<-done
+			require.Error(t, err)
+
+			for _, summary := range tt.wantSummary {
+				assert.Contains(t, errorMessages, summary)
+			}
+
+			deployments, err := projectService.ListDeployments(projectID)
+			require.NoError(t, err)
+			require.Len(t, deployments, 1)
+			assert.Equal(t, domain.DeploymentStatusFailed, deployments[0].Status)
+			assert.Equal(t, tt.wantFailures, deployments[0].FailedServices)
+			for _, summary := range tt.wantSummary {
+				assert.Contains(t, deployments[0].Stderr, summary)
+			}
+		})
+	}
+}
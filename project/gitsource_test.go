@@ -0,0 +1,140 @@
+package project_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/git"
+	"github.com/oar-cd/oar/project"
+)
+
+// commitOnBranch creates branch in the repository at dir, from its current commit, and commits file
+// to it
+func commitOnBranch(t *testing.T, dir, branch, file string) {
+	t.Helper()
+	repo, err := gogit.PlainOpen(dir)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+		Create: true,
+	}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(file+"\n"), 0o644))
+	_, err = worktree.Add(file)
+	require.NoError(t, err)
+	_, err = worktree.Commit("Add "+file, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+func TestGitSourceVariables(t *testing.T) {
+	// Stub docker: config prints a minimal configuration, everything else succeeds
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+	gitService := git.NewGitService(cfg)
+
+	// The same repository on two hosts, whose staging branches differ
+	hostA := filepath.Join(cfg.DataDir, "host-a")
+	hostB := filepath.Join(cfg.DataDir, "host-b")
+	initDeployableRepo(t, filepath.Join(hostA, "repo"))
+	commitOnBranch(t, filepath.Join(hostA, "repo"), "staging", "a.txt")
+	initDeployableRepo(t, filepath.Join(hostB, "repo"))
+	commitOnBranch(t, filepath.Join(hostB, "repo"), "staging", "b.txt")
+
+	p := domain.NewProject("templated", "${GIT_HOST}/repo", []string{"compose.yaml"},
+		[]string{"GIT_HOST=" + hostA, "BRANCH=staging"})
+	p.GitBranch = "${BRANCH}"
+	p.SkipVolumeInit = true
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+	gitDir, err := created.GitDir()
+	require.NoError(t, err)
+
+	t.Run("host and branch are interpolated on clone", func(t *testing.T) {
+		assert.Equal(t, "${GIT_HOST}/repo", created.GitURL, "The URL should be stored as given")
+		assert.Equal(t, "${BRANCH}", created.GitBranch)
+		assert.FileExists(t, filepath.Join(gitDir, "a.txt"))
+
+		local, err := gitService.InspectLocalRepository(gitDir)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(hostA, "repo"), local.RemoteURL)
+		assert.Equal(t, "staging", local.Branch)
+	})
+
+	t.Run("pulls use the variables set at the time", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.Variables = []string{"GIT_HOST=" + hostB, "BRANCH=staging"}
+		require.NoError(t, projectService.Update(stored))
+
+		results, err := projectService.DeployManyPiping([]uuid.UUID{created.ID}, project.BulkDeployOptions{Pull: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+
+		assert.FileExists(t, filepath.Join(gitDir, "b.txt"))
+		assert.NoFileExists(t, filepath.Join(gitDir, "a.txt"))
+		local, err := gitService.InspectLocalRepository(gitDir)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(hostB, "repo"), local.RemoteURL)
+	})
+
+	t.Run("unresolved variables", func(t *testing.T) {
+		missing := domain.NewProject("missing", "${GIT_HOST}/${ORG}/repo", []string{"compose.yaml"},
+			[]string{"GIT_HOST=" + hostA})
+		_, err := projectService.Create(&missing)
+		require.Error(t, err)
+		assert.Equal(t, "git URL references variables that are not set: ORG", err.Error())
+
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.Variables = []string{"GIT_HOST=" + hostB}
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Equal(t, "git branch references variables that are not set: BRANCH", err.Error())
+	})
+}
+
+func TestGitSourceCredentialsNotLogged(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	cfg := newTestConfig(t)
+	cfg.GitTimeout = 5 * time.Second
+	projectService, _ := newTestProjectService(t, cfg)
+
+	// Nothing listens on the port, so every git operation fails
+	const token = "ghp_0123456789abcdef"
+	p := domain.NewProject("private", "http://${GIT_TOKEN}@127.0.0.1:1/org/repo.git", []string{"compose.yaml"},
+		[]string{"GIT_TOKEN=" + token})
+	_, err := projectService.Create(&p)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), token)
+	assert.NotContains(t, logs.String(), token)
+	assert.Contains(t, logs.String(), "${GIT_TOKEN}", "Logs should show the URL as configured")
+}
@@ -0,0 +1,95 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// hookServicePattern matches the service names Compose accepts
+var hookServicePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// normalizeDeployHooks trims the project's deploy hooks and validates their phases, services and commands.
+// Whether the services exist is checked on deployment, against the configuration being deployed.
+func normalizeDeployHooks(project *domain.Project) error {
+	if len(project.DeployHooks) == 0 {
+		project.DeployHooks = nil
+		return nil
+	}
+	for i := range project.DeployHooks {
+		hook := &project.DeployHooks[i]
+		hook.Phase = domain.HookPhase(strings.TrimSpace(string(hook.Phase)))
+		hook.Service = strings.TrimSpace(hook.Service)
+		hook.Command = strings.TrimSpace(hook.Command)
+		if !slices.Contains(domain.HookPhases, hook.Phase) {
+			return fmt.Errorf("invalid deploy hook phase %q: use pre-deploy or post-deploy", hook.Phase)
+		}
+		if !hookServicePattern.MatchString(hook.Service) {
+			return fmt.Errorf("invalid deploy hook service %q", hook.Service)
+		}
+		if _, err := hook.Args(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkHookServices returns an error when a deploy hook of the project runs a service its configuration
+// does not declare. All hooks are checked before the first one runs, so that a mistake in a post-deploy
+// hook does not fail a deployment that has already replaced the containers.
+func checkHookServices(project *domain.Project, composeProject *docker.ComposeProject) error {
+	checked := make(map[string]bool)
+	for _, hook := range project.DeployHooks {
+		if checked[hook.Service] {
+			continue
+		}
+		exists, err := composeProject.HasService(hook.Service)
+		if err != nil {
+			return fmt.Errorf("failed to check deploy hook service %s: %w", hook.Service, err)
+		}
+		if !exists {
+			return fmt.Errorf("deploy hook service %s is not in the project's configuration", hook.Service)
+		}
+		checked[hook.Service] = true
+	}
+	return nil
+}
+
+// runDeployHooks runs the project's deploy hooks of the phase in the order they are listed, keeping
+// their output in the deployment record. The first hook that fails stops the deployment.
+func (s *ProjectService) runDeployHooks(
+	project *domain.Project,
+	composeProject *docker.ComposeProject,
+	phase domain.HookPhase,
+	outputChan chan<- docker.StreamMessage,
+	stdoutBuffer, stderrBuffer *strings.Builder,
+) error {
+	for _, hook := range project.DeployHooks {
+		if hook.Phase != phase {
+			continue
+		}
+		args, err := hook.Args()
+		if err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("Running %s hook: docker compose run --rm %s", phase, hook.Service)
+		if hook.Command != "" {
+			msg += " " + hook.Command
+		}
+		stdoutBuffer.WriteString(s.storedOutput(msg) + "\n")
+		outputChan <- docker.StreamMessage{Type: "info", Content: msg}
+
+		err = s.runCaptured(composeProject, outputChan, stdoutBuffer, stderrBuffer,
+			func(outputChan chan<- docker.StreamMessage) error {
+				return composeProject.RunStreaming(hook.Service, args, outputChan)
+			})
+		if err != nil {
+			return fmt.Errorf("%s hook of service %s failed: %w", phase, hook.Service, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestDeployHooks deploys a project with a migration hook, with a stub docker that records its calls and
+// whose run fails while the file it checks exists
+func TestDeployHooks(t *testing.T) {
+	failFile := filepath.Join(t.TempDir(), "fail")
+	callsFile := newStubDocker(t, `case "$*" in
+*" config --services")
+	printf 'web\nmigrate\n'
+	;;
+*" config")
+	printf 'services:\n  web:\n    image: nginx:1.27\n  migrate:\n    image: app/migrate:1\n'
+	;;
+*" run "*)
+	echo "applying migrations"
+	if [ -e `+failFile+` ]; then
+		echo "relation already exists" >&2
+		exit 1
+	fi
+	;;
+esac`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+		DeployHooks: []domain.DeployHook{
+			{Phase: domain.HookPhasePreDeploy, Service: "migrate", Command: `./manage.py migrate --plan "all apps"`},
+		},
+	})
+	require.NoError(t, err)
+
+	readCalls := func(t *testing.T) []string {
+		t.Helper()
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(callsFile))
+		return strings.Split(strings.TrimSpace(string(calls)), "\n")
+	}
+	indexOf := func(calls []string, command string) int {
+		for i, call := range calls {
+			if strings.Contains(call, " "+command+" ") {
+				return i
+			}
+		}
+		return -1
+	}
+
+	t.Run("runs before the services start", func(t *testing.T) {
+		require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+
+		calls := readCalls(t)
+		run, up := indexOf(calls, "run"), indexOf(calls, "up")
+		require.NotEqual(t, -1, run, "the hook should run: %v", calls)
+		assert.True(t, strings.HasSuffix(calls[run], "run --rm --no-TTY migrate ./manage.py migrate --plan all apps"))
+		assert.Less(t, run, up, "the hook should run before up")
+
+		deployments, err := repos.deployments.ListByProjectID(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeploymentStatusCompleted, deployments[0].Status)
+		assert.Contains(t, deployments[0].Stdout, "applying migrations")
+	})
+
+	t.Run("a failing migration aborts the deployment", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(failFile, nil, 0o644))
+
+		err := projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pre-deploy hook of service migrate failed")
+
+		calls := readCalls(t)
+		assert.NotEqual(t, -1, indexOf(calls, "run"))
+		assert.Equal(t, -1, indexOf(calls, "up"), "no service should start after the migration failed: %v", calls)
+
+		deployments, err := repos.deployments.ListByProjectID(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeploymentStatusFailed, deployments[0].Status)
+		assert.Contains(t, deployments[0].Stderr, "relation already exists")
+	})
+
+	t.Run("hook services must exist", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.DeployHooks = []domain.DeployHook{{Phase: domain.HookPhasePostDeploy, Service: "seed"}}
+		require.NoError(t, projectService.Update(stored))
+
+		err = projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deploy hook service seed is not in the project's configuration")
+		assert.Equal(t, -1, indexOf(readCalls(t), "up"))
+	})
+
+	t.Run("invalid hooks", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+
+		stored.DeployHooks = []domain.DeployHook{{Phase: "before-deploy", Service: "migrate"}}
+		assert.EqualError(t, projectService.Update(stored),
+			`invalid deploy hook phase "before-deploy": use pre-deploy or post-deploy`)
+
+		stored.DeployHooks = []domain.DeployHook{{Phase: domain.HookPhasePreDeploy, Service: "-migrate"}}
+		assert.EqualError(t, projectService.Update(stored), `invalid deploy hook service "-migrate"`)
+
+		stored.DeployHooks = []domain.DeployHook{
+			{Phase: domain.HookPhasePreDeploy, Service: "migrate", Command: `echo "hi`},
+		}
+		assert.EqualError(t, projectService.Update(stored),
+			`unterminated quote or escape in the command of deploy hook pre-deploy:migrate:echo "hi`)
+
+		_, err = domain.ParseDeployHook("pre-deploy")
+		assert.EqualError(t, err, `invalid deploy hook "pre-deploy": use PHASE:SERVICE[:COMMAND]`)
+	})
+}
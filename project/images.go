@@ -0,0 +1,56 @@
+package project
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/domain"
+)
+
+// normalizeRegistryMirror trims the project's registry mirror and validates it
+func normalizeRegistryMirror(project *domain.Project) error {
+	project.RegistryMirror = strings.TrimSpace(project.RegistryMirror)
+	if project.RegistryMirror == "" {
+		return nil
+	}
+	return domain.ValidateRegistryMirror(project.RegistryMirror)
+}
+
+// SharedImages returns the images of the project that other projects use as well, sorted. Removing
+// the project with docker.RemoveImagesAll removes them too: Docker keeps an image while containers use
+// it, but a project without containers, e.g. a stopped offline one, has to pull it again. Projects
+// whose configuration cannot be read are left out.
+func (s *ProjectService) SharedImages(projectID uuid.UUID) ([]string, error) {
+	images, err := s.ListImages(projectID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	for _, other := range projects {
+		if other.ID == projectID {
+			continue
+		}
+		otherImages, err := s.ListImages(other.ID)
+		if err != nil {
+			slog.Warn("Failed to list images of project, leaving it out of shared images",
+				"project_id", other.ID,
+				"project_name", other.Name,
+				"error", err)
+			continue
+		}
+		for _, image := range images {
+			if slices.Contains(otherImages, image) && !slices.Contains(shared, image) {
+				shared = append(shared, image)
+			}
+		}
+	}
+	slices.Sort(shared)
+	return shared, nil
+}
@@ -0,0 +1,59 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestRegistryMirror(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	created, err := repos.projects.Create(&domain.Project{
+		ID:            projectID,
+		Name:          "air-gapped",
+		GitURL:        "https://example.com/repo.git",
+		GitBranch:     "main",
+		WorkingDir:    filepath.Join(cfg.WorkspaceDir, projectID.String()+"-air-gapped"),
+		ComposeFiles:  []string{"compose.yaml"},
+		Status:        domain.ProjectStatusStopped,
+		OfflineImages: true,
+	})
+	require.NoError(t, err)
+
+	t.Run("offline mode is stored", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.OfflineImages)
+		assert.Empty(t, stored.RegistryMirror)
+	})
+
+	t.Run("update trims the mirror", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.RegistryMirror = " mirror.local:5000 "
+
+		require.NoError(t, projectService.Update(stored))
+
+		updated, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "mirror.local:5000", updated.RegistryMirror)
+	})
+
+	t.Run("update rejects a mirror with a scheme", func(t *testing.T) {
+		stored, err := projectService.Get(created.ID)
+		require.NoError(t, err)
+		stored.RegistryMirror = "https://mirror.local:5000"
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "must not include a scheme")
+	})
+}
@@ -0,0 +1,196 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// DirectoryImportStatus is the outcome of importing one directory
+type DirectoryImportStatus string
+
+const (
+	DirectoryImported DirectoryImportStatus = "imported"
+	DirectorySkipped  DirectoryImportStatus = "skipped"
+	DirectoryFailed   DirectoryImportStatus = "failed"
+)
+
+// DirectoryImportResult describes what was, or with a dry run would be, imported from a directory
+type DirectoryImportResult struct {
+	Dir          string
+	Name         string
+	GitURL       string
+	GitBranch    string // Empty when the default branch of the remote is used
+	ComposeFiles []string
+	Adopted      bool // The stack is running and was imported without restarting it
+	Status       DirectoryImportStatus
+	Reason       string   // Why the directory was skipped or failed
+	Warnings     []string // Differences between the running stack and the repository
+	Project      *domain.Project
+}
+
+// defaultComposeFiles are the file names Docker Compose looks for, in its order of preference
+var defaultComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// composeOverrideFiles are the override files Docker Compose applies next to each default file
+var composeOverrideFiles = map[string]string{
+	"compose.yaml":        "compose.override.yaml",
+	"compose.yml":         "compose.override.yml",
+	"docker-compose.yaml": "docker-compose.override.yaml",
+	"docker-compose.yml":  "docker-compose.override.yml",
+}
+
+// invalidComposeNameChars matches what Docker Compose removes from a directory name to get the
+// default project name
+var invalidComposeNameChars = regexp.MustCompile(`[^a-z0-9_-]`)
+
+// ImportDirectory creates a project for each subdirectory of root that is a git repository with a
+// compose file. The name comes from the directory, as Docker Compose derives it; the git URL and
+// branch come from the checkout. A stack that is already running is adopted without restarting it,
+// as with ImportExisting. With dryRun, nothing is created and the results show what would be.
+func (s *ProjectService) ImportDirectory(root string, dryRun bool) ([]DirectoryImportResult, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", root, err)
+	}
+
+	existing, err := s.projectRepository.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	names := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		names[p.Name] = true
+	}
+
+	var results []DirectoryImportResult
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		result := s.inspectImportDirectory(filepath.Join(root, entry.Name()), names)
+		if result.Status == "" {
+			// Names are reserved as they are planned, so two directories cannot claim the same one
+			names[result.Name] = true
+			if !dryRun {
+				s.importDirectory(&result)
+			} else {
+				result.Status = DirectoryImported
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// inspectImportDirectory fills in what would be imported from dir. The status is only set when the
+// directory is skipped.
+func (s *ProjectService) inspectImportDirectory(dir string, names map[string]bool) DirectoryImportResult {
+	result := DirectoryImportResult{
+		Dir:  dir,
+		Name: invalidComposeNameChars.ReplaceAllString(strings.ToLower(filepath.Base(dir)), ""),
+	}
+	skip := func(reason string) DirectoryImportResult {
+		result.Status = DirectorySkipped
+		result.Reason = reason
+		return result
+	}
+
+	result.ComposeFiles = discoverComposeFiles(dir)
+	if len(result.ComposeFiles) == 0 {
+		return skip("no compose file")
+	}
+
+	repo, err := s.gitService.InspectLocalRepository(dir)
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return skip("not a git repository")
+	}
+	if err != nil {
+		return skip(err.Error())
+	}
+	result.GitURL = repo.RemoteURL
+	result.GitBranch = repo.Branch
+
+	if result.Name == "" {
+		return skip("cannot derive a project name from the directory name")
+	}
+	if names[result.Name] {
+		return skip(fmt.Sprintf("a project named %s already exists", result.Name))
+	}
+	return result
+}
+
+// importDirectory creates the project planned in result and records the outcome
+func (s *ProjectService) importDirectory(result *DirectoryImportResult) {
+	project := domain.NewProject(result.Name, result.GitURL, result.ComposeFiles, nil)
+	project.GitBranch = result.GitBranch
+
+	running, err := docker.RunningConfigHashes(result.Name, s.config.ComposeQueryTimeout)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("could not check for running containers: %v", err))
+	}
+
+	var created *domain.Project
+	if len(running) > 0 {
+		var warnings []string
+		created, warnings, err = s.ImportExisting(&project, result.Name)
+		result.Warnings = append(result.Warnings, warnings...)
+		result.Adopted = true
+	} else {
+		created, err = s.Create(&project)
+	}
+	if err != nil {
+		slog.Warn("Failed to import directory", "dir", result.Dir, "project_name", result.Name, "error", err)
+		result.Status = DirectoryFailed
+		result.Reason = err.Error()
+		return
+	}
+
+	slog.Info("Imported directory",
+		"dir", result.Dir,
+		"project_id", created.ID,
+		"project_name", created.Name,
+		"adopted", result.Adopted)
+	result.Status = DirectoryImported
+	result.Project = created
+	result.GitBranch = created.GitBranch
+}
+
+// discoverComposeFiles returns the compose files Docker Compose uses in dir when it is given none: the
+// first default file found, followed by its override file if there is one. It returns nil when dir
+// has no default file.
+func discoverComposeFiles(dir string) []string {
+	for _, name := range defaultComposeFiles {
+		if fileExists(filepath.Join(dir, name)) {
+			files := []string{name}
+			if override := composeOverrideFiles[name]; fileExists(filepath.Join(dir, override)) {
+				files = append(files, override)
+			}
+			return files
+		}
+	}
+	return nil
+}
+
+// fileExists reports whether path exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// CountImportResults returns how many directories have each status
+func CountImportResults(results []DirectoryImportResult) map[DirectoryImportStatus]int {
+	counts := make(map[DirectoryImportStatus]int, 3)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	return counts
+}
@@ -0,0 +1,135 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gogit "github.com/go-git/go-git/v6"
+	gitconfig "github.com/go-git/go-git/v6/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/project"
+)
+
+func TestImportDirectory(t *testing.T) {
+	// Stub docker that reports no running containers, so every stack is created rather than adopted
+	newStubDocker(t, "")
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	// The remote that the stack directories were cloned from
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	root := filepath.Join(cfg.DataDir, "stacks")
+	require.NoError(t, os.MkdirAll(root, 0o755))
+
+	// A clone with an override file, whose name needs normalizing
+	appDir := filepath.Join(root, "My.App")
+	_, err := gogit.PlainClone(appDir, &gogit.CloneOptions{URL: originDir})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(appDir, "compose.override.yaml"), []byte("services: {}\n"), 0o644))
+
+	// Not a git repository
+	plainDir := filepath.Join(root, "plain")
+	require.NoError(t, os.MkdirAll(plainDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(plainDir, "docker-compose.yml"), []byte("services: {}\n"), 0o644))
+
+	// A git repository without a compose file
+	_, err = gogit.PlainInit(filepath.Join(root, "scripts"), false)
+	require.NoError(t, err)
+
+	// A git repository without a remote
+	initDeployableRepo(t, filepath.Join(root, "local-only"))
+
+	// A git repository whose remote cannot be cloned
+	brokenDir := filepath.Join(root, "broken")
+	initDeployableRepo(t, brokenDir)
+	brokenRepo, err := gogit.PlainOpen(brokenDir)
+	require.NoError(t, err)
+	_, err = brokenRepo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{filepath.Join(cfg.DataDir, "missing")},
+	})
+	require.NoError(t, err)
+
+	// Files next to the stacks are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("stacks\n"), 0o644))
+
+	byName := func(results []project.DirectoryImportResult) map[string]project.DirectoryImportResult {
+		m := make(map[string]project.DirectoryImportResult, len(results))
+		for _, result := range results {
+			m[filepath.Base(result.Dir)] = result
+		}
+		return m
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		results, err := projectService.ImportDirectory(root, true)
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+		dirs := byName(results)
+
+		app := dirs["My.App"]
+		assert.Equal(t, project.DirectoryImported, app.Status)
+		assert.Equal(t, "myapp", app.Name)
+		assert.Equal(t, originDir, app.GitURL)
+		assert.Equal(t, "master", app.GitBranch)
+		assert.Equal(t, []string{"compose.yaml", "compose.override.yaml"}, app.ComposeFiles)
+		assert.Nil(t, app.Project)
+
+		assert.Equal(t, project.DirectorySkipped, dirs["plain"].Status)
+		assert.Equal(t, "not a git repository", dirs["plain"].Reason)
+		assert.Equal(t, project.DirectorySkipped, dirs["scripts"].Status)
+		assert.Equal(t, "no compose file", dirs["scripts"].Reason)
+		assert.Equal(t, project.DirectorySkipped, dirs["local-only"].Status)
+		assert.Equal(t, "repository has no remote", dirs["local-only"].Reason)
+		assert.Equal(t, project.DirectoryImported, dirs["broken"].Status, "A dry run does not clone")
+
+		projects, err := projectService.List()
+		require.NoError(t, err)
+		assert.Empty(t, projects, "A dry run must not create projects")
+	})
+
+	t.Run("import", func(t *testing.T) {
+		results, err := projectService.ImportDirectory(root, false)
+		require.NoError(t, err)
+		dirs := byName(results)
+
+		app := dirs["My.App"]
+		require.Equal(t, project.DirectoryImported, app.Status, app.Reason)
+		require.NotNil(t, app.Project)
+		assert.False(t, app.Adopted)
+
+		stored, err := projectService.Get(app.Project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "myapp", stored.Name)
+		assert.Equal(t, originDir, stored.GitURL)
+		assert.Equal(t, "master", stored.GitBranch)
+		assert.Equal(t, []string{"compose.yaml", "compose.override.yaml"}, stored.ComposeFiles)
+
+		assert.Equal(t, project.DirectoryFailed, dirs["broken"].Status)
+		assert.NotEmpty(t, dirs["broken"].Reason)
+
+		counts := project.CountImportResults(results)
+		assert.Equal(t, 1, counts[project.DirectoryImported])
+		assert.Equal(t, 3, counts[project.DirectorySkipped])
+		assert.Equal(t, 1, counts[project.DirectoryFailed])
+	})
+
+	t.Run("existing project is skipped", func(t *testing.T) {
+		results, err := projectService.ImportDirectory(root, true)
+		require.NoError(t, err)
+		app := byName(results)["My.App"]
+		assert.Equal(t, project.DirectorySkipped, app.Status)
+		assert.Equal(t, "a project named myapp already exists", app.Reason)
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := projectService.ImportDirectory(filepath.Join(cfg.DataDir, "nope"), true)
+		assert.Error(t, err)
+	})
+}
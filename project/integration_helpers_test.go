@@ -73,6 +73,48 @@ func setupTestEncryption(t *testing.T) *encryption.EncryptionService {
 	return encryptionSvc
 }
 
+// testRepos are the repositories of a project service created by newTestProjectService
+type testRepos struct {
+	projects    repository.ProjectRepository
+	deployments repository.DeploymentRepository
+}
+
+// newTestConfig returns the configuration of unit tests, with the data and workspace directories in a
+// temporary directory. Tests set the fields they exercise on top of it.
+func newTestConfig(t *testing.T) *config.Config {
+	tempDir := t.TempDir()
+	return &config.Config{
+		DataDir:             tempDir,
+		WorkspaceDir:        filepath.Join(tempDir, "projects"),
+		GitTimeout:          10 * time.Second,
+		ComposeQueryTimeout: 10 * time.Second,
+		ComposeProgress:     "plain",
+	}
+}
+
+// newTestProjectService creates a project service over an in-memory database, with a git service
+// using cfg
+func newTestProjectService(t *testing.T, cfg *config.Config) (*project.ProjectService, testRepos) {
+	database := setupTestDB(t)
+	repos := testRepos{
+		projects:    repository.NewProjectRepository(database, setupTestEncryption(t)),
+		deployments: repository.NewDeploymentRepository(database),
+	}
+	return project.NewProjectService(repos.projects, repos.deployments, git.NewGitService(cfg), cfg), repos
+}
+
+// newStubDocker puts a docker script first on the PATH for the rest of the test. The script appends
+// the arguments of each call to the returned file, then runs script, which exits 0 when it falls
+// through.
+func newStubDocker(t *testing.T, script string) (callsFile string) {
+	binDir := t.TempDir()
+	callsFile = filepath.Join(t.TempDir(), "calls")
+	content := "#!/bin/sh\necho \"$@\" >> " + callsFile + "\n" + script + "\nexit 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "docker"), []byte(content), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return callsFile
+}
+
 // setupProjectService creates a project service with real Git and Docker dependencies for integration testing
 func setupProjectService(
 	t *testing.T,
@@ -140,12 +182,19 @@ func setupTest(t *testing.T) *testContext {
 // deployProject deploys a project with streaming and waits for completion
 // Returns error if deployment fails
 func (ctx *testContext) deployProject(projectID uuid.UUID, pull bool, timeoutSeconds int) error {
+	return ctx.deployServices(projectID, pull, nil, timeoutSeconds)
+}
+
+// deployServices deploys the given services of a project (all services if empty) and waits for completion
+func (ctx *testContext) deployServices(projectID uuid.UUID, pull bool, services []string, timeoutSeconds int) error {
 	outputChan := make(chan docker.StreamMessage, 100)
 	deployDone := make(chan error, 1)
 
 	go func() {
 		defer close(outputChan)
-		deployDone <- ctx.projectManager.DeployStreaming(projectID, pull, outputChan)
+		deployDone <- ctx.projectManager.DeployStreaming(
+			projectID, pull, services, domain.DeploymentAnnotation{}, outputChan,
+		)
 	}()
 
 	timeout := time.After(time.Duration(timeoutSeconds) * time.Second)
@@ -170,6 +219,30 @@ func (ctx *testContext) deployProject(projectID uuid.UUID, pull bool, timeoutSec
 	}
 }
 
+// verifyDeclaredServices checks that the services listed from the configuration cover the running
+// containers, and that images and volumes can be listed. It returns the listed services.
+func (ctx *testContext) verifyDeclaredServices(projectID uuid.UUID) []string {
+	services, err := ctx.projectManager.ListServices(projectID)
+	require.NoError(ctx.t, err, "Listing services should succeed")
+	require.NotEmpty(ctx.t, services, "Project should declare services")
+
+	status, err := ctx.projectManager.GetStatus(projectID)
+	require.NoError(ctx.t, err, "Getting status should succeed")
+	for _, container := range status.Containers {
+		require.Contains(ctx.t, services, container.Service, "Running service should be listed")
+	}
+
+	images, err := ctx.projectManager.ListImages(projectID)
+	require.NoError(ctx.t, err, "Listing images should succeed")
+	require.NotEmpty(ctx.t, images, "Services should use images")
+
+	_, err = ctx.projectManager.ListDeclaredVolumes(projectID)
+	require.NoError(ctx.t, err, "Listing volumes should succeed")
+
+	ctx.t.Logf("Declared services: %v, images: %v", services, images)
+	return services
+}
+
 // waitForProjectStatus waits for the project to reach the expected status within the timeout
 func (ctx *testContext) waitForProjectStatus(
 	projectID uuid.UUID,
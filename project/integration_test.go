@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/oar-cd/oar/config"
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 	"github.com/oar-cd/oar/project"
@@ -145,7 +146,7 @@ func TestCompleteLifecycle(t *testing.T) {
 	go func() {
 		defer close(logsChan)
 		// Get static logs instead of streaming
-		stdout, stderr, err := ctx.projectManager.GetLogs(createdProject.ID)
+		stdout, stderr, err := ctx.projectManager.GetLogs(createdProject.ID, docker.LogsOptions{})
 		if err != nil {
 			logsDone <- err
 			return
@@ -184,7 +185,7 @@ func TestCompleteLifecycle(t *testing.T) {
 
 	go func() {
 		defer close(firstStopChan)
-		firstStopDone <- ctx.projectManager.StopStreaming(createdProject.ID, firstStopChan)
+		firstStopDone <- ctx.projectManager.StopStreaming(createdProject.ID, false, firstStopChan)
 	}()
 
 	// Wait for first stop to complete
@@ -262,7 +263,7 @@ func TestCompleteLifecycle(t *testing.T) {
 
 	go func() {
 		defer close(stopChan)
-		stopDone <- ctx.projectManager.StopStreaming(createdProject.ID, stopChan)
+		stopDone <- ctx.projectManager.StopStreaming(createdProject.ID, false, stopChan)
 	}()
 
 	// Collect stop output
@@ -299,7 +300,7 @@ func TestCompleteLifecycle(t *testing.T) {
 	// Step 10: Remove the project
 	t.Log("Step 10: Removing projects...")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Project removed successfully")
@@ -386,15 +387,65 @@ func TestMergeStrategy(t *testing.T) {
 	t.Logf("Merge strategy configuration verified (%d characters)", len(config))
 
 	// Cleanup
-	err = ctx.projectManager.StopStreaming(createdProject.ID, make(chan docker.StreamMessage, 100))
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
 	require.NoError(t, err, "Stopping should succeed")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Merge strategy test completed successfully")
 }
 
+// TestDeploySelectedServices deploys a single service of a multi-service project
+func TestDeploySelectedServices(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	testProjectName := "test-project-selected-services"
+
+	proj := &domain.Project{
+		ID:           uuid.New(),
+		Name:         testProjectName,
+		GitURL:       ctx.testRepoURL,
+		GitBranch:    "compose-files-merge", // web, redis and db
+		ComposeFiles: []string{"compose.yaml", "compose.override.yaml"},
+	}
+
+	createdProject, err := ctx.projectManager.Create(proj)
+	require.NoError(t, err, "Project creation should succeed")
+	ctx.setupCleanup(createdProject)
+
+	// Unknown services are rejected before anything is started
+	err = ctx.deployServices(createdProject.ID, false, []string{"missing"}, 60)
+	require.Error(t, err, "Deploying an unknown service should fail")
+	assert.Contains(t, err.Error(), "unknown service(s): missing")
+
+	// The full config is still rendered, so the expected set is the service and its dependencies
+	config, _, err := ctx.projectManager.GetConfig(createdProject.ID)
+	require.NoError(t, err, "Getting config should succeed")
+	expected, err := docker.ResolveServices(config, []string{"redis"})
+	require.NoError(t, err, "Resolving services should succeed")
+	require.Less(t, len(expected), 3, "redis should not depend on every other service")
+
+	err = ctx.deployServices(createdProject.ID, false, []string{"redis"}, 60)
+	require.NoError(t, err, "Deployment should succeed")
+
+	status, err := ctx.projectManager.GetStatus(createdProject.ID)
+	require.NoError(t, err, "Getting status should succeed")
+
+	serviceNames := verifyContainersRunning(t, status.Containers, testProjectName)
+	assert.ElementsMatch(t, expected, serviceNames, "Only redis and its dependencies should be running")
+
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
+	require.NoError(t, err, "Stopping should succeed")
+
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+	require.NoError(t, err, "Project removal should succeed")
+}
+
 // TestProjectManager_Integration_ComposeOverride tests the compose override functionality
 // using ComposeOverride field instead of a separate compose.override.yaml file
 func TestComposeOverride(t *testing.T) {
@@ -484,15 +535,64 @@ func TestComposeOverride(t *testing.T) {
 	t.Logf("Compose override configuration verified against golden file (%d characters)", len(config))
 
 	// Cleanup
-	err = ctx.projectManager.StopStreaming(createdProject.ID, make(chan docker.StreamMessage, 100))
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
 	require.NoError(t, err, "Stopping should succeed")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Compose override test completed successfully")
 }
 
+// TestMergeOrderWithOverride combines several compose files with a compose override and checks that
+// the override wins and that the merged configuration is the same every time it is resolved
+func TestMergeOrderWithOverride(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	// compose.override.yaml sets NGINX_ENV to development, the compose override merged after it wins
+	overrideContent := `services:
+  web:
+    environment:
+      NGINX_ENV: production`
+
+	proj := &domain.Project{
+		ID:              uuid.New(),
+		Name:            "test-project-merge-order",
+		GitURL:          ctx.testRepoURL,
+		GitBranch:       "compose-files-merge",
+		ComposeFiles:    []string{"compose.yaml", "compose.override.yaml"},
+		ComposeOverride: &overrideContent,
+	}
+
+	createdProject, err := ctx.projectManager.Create(proj)
+	require.NoError(t, err, "Project creation should succeed")
+	defer func() {
+		err := ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+		require.NoError(t, err, "Project removal should succeed")
+	}()
+
+	config, _, err := ctx.projectManager.GetConfig(createdProject.ID)
+	require.NoError(t, err, "Getting config should succeed")
+	assert.Contains(t, config, "NGINX_ENV: production", "Compose override should be merged last")
+	assert.NotContains(t, config, "NGINX_ENV: development")
+
+	for range 3 {
+		again, _, err := ctx.projectManager.GetConfig(createdProject.ID)
+		require.NoError(t, err)
+		assert.Equal(t, config, again, "Merged configuration should be stable")
+	}
+
+	annotated, _, err := ctx.projectManager.GetMergedConfig(createdProject.ID)
+	require.NoError(t, err, "Getting merged config should succeed")
+	assert.Contains(t, annotated, "#   1. compose.yaml\n#   2. compose.override.yaml\n"+
+		"#   3. .oar-compose-override.yaml (compose override)\n")
+	assert.Contains(t, annotated, "web: # compose.yaml, compose.override.yaml, .oar-compose-override.yaml")
+}
+
 // TestProjectManager_Integration_ExtendStrategy tests the extend strategy where one compose file extends another
 func TestExtendStrategy(t *testing.T) {
 	if testing.Short() {
@@ -537,11 +637,19 @@ func TestExtendStrategy(t *testing.T) {
 	assert.Contains(t, config, "services:", "Config should contain services section")
 	t.Logf("Extend strategy configuration verified (%d characters)", len(config))
 
+	// Services from the extended files are listed
+	services := ctx.verifyDeclaredServices(createdProject.ID)
+
 	// Cleanup
-	err = ctx.projectManager.StopStreaming(createdProject.ID, make(chan docker.StreamMessage, 100))
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
 	require.NoError(t, err, "Stopping should succeed")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	// Listing only reads the configuration, so it also works for a stopped project
+	stoppedServices, err := ctx.projectManager.ListServices(createdProject.ID)
+	require.NoError(t, err, "Listing services of a stopped project should succeed")
+	assert.Equal(t, services, stoppedServices)
+
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Extend strategy test completed successfully")
@@ -591,11 +699,19 @@ func TestIncludeStrategy(t *testing.T) {
 	assert.Contains(t, config, "services:", "Config should contain services section")
 	t.Logf("Include strategy configuration verified (%d characters)", len(config))
 
+	// Services from the included files are listed
+	services := ctx.verifyDeclaredServices(createdProject.ID)
+
 	// Cleanup
-	err = ctx.projectManager.StopStreaming(createdProject.ID, make(chan docker.StreamMessage, 100))
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
 	require.NoError(t, err, "Stopping should succeed")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	// Listing only reads the configuration, so it also works for a stopped project
+	stoppedServices, err := ctx.projectManager.ListServices(createdProject.ID)
+	require.NoError(t, err, "Listing services of a stopped project should succeed")
+	assert.Equal(t, services, stoppedServices)
+
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Include strategy test completed successfully")
@@ -666,10 +782,10 @@ func TestVariableInterpolation(t *testing.T) {
 	t.Logf("Variable interpolation verified in configuration (%d characters)", len(config))
 
 	// Cleanup
-	err = ctx.projectManager.StopStreaming(createdProject.ID, make(chan docker.StreamMessage, 100))
+	err = ctx.projectManager.StopStreaming(createdProject.ID, false, make(chan docker.StreamMessage, 100))
 	require.NoError(t, err, "Stopping should succeed")
 
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Variables test completed successfully")
@@ -768,7 +884,7 @@ func TestVolumeMounts(t *testing.T) {
 
 	go func() {
 		defer close(stopChan)
-		stopDone <- ctx.projectManager.StopStreaming(createdProject.ID, stopChan)
+		stopDone <- ctx.projectManager.StopStreaming(createdProject.ID, false, stopChan)
 	}()
 
 	// Wait for stop to complete and show output
@@ -776,12 +892,403 @@ func TestVolumeMounts(t *testing.T) {
 	require.NoError(t, err, "Stopping should succeed")
 
 	// Remove the project (this should clean up everything)
-	err = ctx.projectManager.Remove(createdProject.ID, true)
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
 	require.NoError(t, err, "Project removal should succeed")
 
 	t.Logf("Volume mounts integration test completed successfully")
 }
 
+// TestVolumeMountsBeforeCreate deploys the volume mount test suite with the mounts initialized before the
+// containers are created, which must leave every service as healthy as the default order does
+func TestVolumeMountsBeforeCreate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	proj := &domain.Project{
+		ID:              uuid.New(),
+		Name:            "test-project-volume-mounts-before-create",
+		GitURL:          ctx.testRepoURL,
+		GitBranch:       "volume-mounts",
+		ComposeFiles:    []string{"compose.yaml"},
+		VolumeInitPhase: domain.VolumeInitBeforeCreate,
+	}
+
+	createdProject, err := ctx.projectManager.Create(proj)
+	require.NoError(t, err, "Project creation should succeed")
+
+	deployChan := make(chan docker.StreamMessage, 100)
+	deployDone := make(chan error, 1)
+
+	go func() {
+		defer close(deployChan)
+		deployDone <- ctx.projectManager.DeployStreaming(
+			createdProject.ID, true, nil, domain.DeploymentAnnotation{}, deployChan,
+		)
+	}()
+
+	messages, err := consumeStreamingMessages(t, deployChan, deployDone, 180*time.Second, "Deploy")
+	require.NoError(t, err, "Deployment should succeed")
+	ctx.setupCleanup(createdProject)
+	assert.Contains(t, messages, "Initializing volume mounts before creating containers...")
+	// The named volumes of a first deployment only exist once Compose has created the containers
+	assert.Contains(t, messages, "Initializing new volume mounts...")
+
+	status, err := waitForAllContainersRunning(t, ctx.projectManager, createdProject.ID, 21, 30*time.Second)
+	require.NoError(t, err)
+	verifyExpectedServices(t, collectServiceNames(status.Containers), []string{
+		"nonroot-user-with-volumes",
+		"build-nonroot-user",
+		"named-volumes-only",
+		"bind-mounts-only",
+		"mixed-volumes",
+		"file-bind-mount-nonexisting",
+		"user-group-format",
+	})
+
+	// Redeployed, all mounts exist before the containers are replaced
+	deployChan = make(chan docker.StreamMessage, 100)
+	deployDone = make(chan error, 1)
+	go func() {
+		defer close(deployChan)
+		deployDone <- ctx.projectManager.DeployStreaming(
+			createdProject.ID, false, nil, domain.DeploymentAnnotation{}, deployChan,
+		)
+	}()
+
+	messages, err = consumeStreamingMessages(t, deployChan, deployDone, 180*time.Second, "Redeploy")
+	require.NoError(t, err, "Redeployment should succeed")
+	assert.NotContains(t, messages, "Initializing new volume mounts...")
+
+	_, err = waitForAllContainersRunning(t, ctx.projectManager, createdProject.ID, 21, 30*time.Second)
+	require.NoError(t, err)
+
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+	require.NoError(t, err, "Project removal should succeed")
+}
+
+// TestSkipVolumeInit verifies that the volume mount initialization step runs by default and is
+// skipped, with a visible message, when the project opts out of it
+func TestSkipVolumeInit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testCases := []struct {
+		name            string
+		skipVolumeInit  bool
+		expectedMessage string
+	}{
+		{
+			name:            "enabled",
+			skipVolumeInit:  false,
+			expectedMessage: "Initializing volume mounts...",
+		},
+		{
+			name:            "skipped",
+			skipVolumeInit:  true,
+			expectedMessage: "Initializing volume mounts... (skipped)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := setupTest(t)
+
+			proj := &domain.Project{
+				ID:             uuid.New(),
+				Name:           "test-project-volume-init-" + tc.name,
+				GitURL:         ctx.testRepoURL,
+				GitAuth:        nil,
+				ComposeFiles:   []string{"compose.yaml"},
+				SkipVolumeInit: tc.skipVolumeInit,
+			}
+
+			createdProject, err := ctx.projectManager.Create(proj)
+			require.NoError(t, err, "Project creation should succeed")
+			ctx.setupCleanup(createdProject)
+
+			storedProject, err := ctx.projectManager.Get(createdProject.ID)
+			require.NoError(t, err, "Getting project should succeed")
+			assert.Equal(t, tc.skipVolumeInit, storedProject.SkipVolumeInit, "Setting should be persisted")
+
+			deployChan := make(chan docker.StreamMessage, 100)
+			deployDone := make(chan error, 1)
+
+			go func() {
+				defer close(deployChan)
+				deployDone <- ctx.projectManager.DeployStreaming(
+					createdProject.ID, true, nil, domain.DeploymentAnnotation{}, deployChan,
+				)
+			}()
+
+			messages, err := consumeStreamingMessages(t, deployChan, deployDone, 60*time.Second, "Deploy")
+			require.NoError(t, err, "Deployment should succeed")
+			assert.Contains(t, messages, tc.expectedMessage)
+			if tc.skipVolumeInit {
+				assert.NotContains(t, messages, "Initializing volume mounts...")
+			}
+
+			err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+			require.NoError(t, err, "Project removal should succeed")
+		})
+	}
+}
+
+// TestSharedExternalNetwork deploys two projects attached to the same external network, which Oar
+// creates for the first deployment and removes once no project references it
+func TestSharedExternalNetwork(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	networkName := "oar-test-shared-" + uuid.New().String()[:8]
+	overrideContent := fmt.Sprintf(`services:
+  web:
+    networks:
+      - default
+      - shared
+
+networks:
+  shared:
+    name: %s
+    external: true`, networkName)
+
+	dockerClient, err := docker.NewDockerClient()
+	require.NoError(t, err, "Creating Docker client should succeed")
+	defer func() {
+		_ = dockerClient.Close()
+	}()
+	t.Cleanup(func() {
+		if _, err := dockerClient.RemoveManagedNetwork(networkName); err != nil {
+			t.Logf("Warning: Failed to remove network %s: %v", networkName, err)
+		}
+	})
+
+	var projects []*domain.Project
+	for _, name := range []string{"test-project-network-a", "test-project-network-b"} {
+		createdProject, err := ctx.projectManager.Create(&domain.Project{
+			ID:               uuid.New(),
+			Name:             name,
+			GitURL:           ctx.testRepoURL,
+			ComposeFiles:     []string{"compose.yaml"},
+			ComposeOverride:  &overrideContent,
+			ExternalNetworks: []string{networkName, " " + networkName},
+		})
+		require.NoError(t, err, "Project creation should succeed")
+		ctx.setupCleanup(createdProject)
+		assert.Equal(t, []string{networkName}, createdProject.ExternalNetworks, "Networks should be normalized")
+
+		// The first deployment creates the network, the second finds it
+		err = ctx.deployProject(createdProject.ID, true, 60)
+		require.NoError(t, err, "Deployment should succeed")
+		err = ctx.waitForProjectStatus(createdProject.ID, docker.ComposeProjectStatusRunning, 30*time.Second)
+		require.NoError(t, err, "Project should reach running status")
+
+		projects = append(projects, createdProject)
+	}
+
+	// Both web containers are attached to the shared network
+	for _, p := range projects {
+		status, err := ctx.projectManager.GetStatus(p.ID)
+		require.NoError(t, err, "Getting status should succeed")
+		var attached bool
+		for _, c := range status.Containers {
+			if c.Service != "web" {
+				continue
+			}
+			inspect, err := dockerClient.ContainerInspect(c.Name)
+			require.NoError(t, err, "Inspecting container should succeed")
+			_, attached = inspect.NetworkSettings.Networks[networkName]
+		}
+		assert.True(t, attached, "Web service of %s should be attached to the shared network", p.Name)
+	}
+
+	// The network stays while the second project still references it
+	removeOptions := project.RemoveOptions{RemoveVolumes: true}
+	require.NoError(t, ctx.projectManager.Remove(projects[0].ID, removeOptions), "Project removal should succeed")
+	removed, err := ctx.projectManager.RemoveUnusedNetworks(projects[0].ExternalNetworks)
+	require.NoError(t, err, "Removing unused networks should succeed")
+	assert.Empty(t, removed, "Network referenced by another project should be kept")
+
+	require.NoError(t, ctx.projectManager.Remove(projects[1].ID, removeOptions), "Project removal should succeed")
+	removed, err = ctx.projectManager.RemoveUnusedNetworks(projects[1].ExternalNetworks)
+	require.NoError(t, err, "Removing unused networks should succeed")
+	assert.Equal(t, []string{networkName}, removed, "Unreferenced network should be removed")
+}
+
+// TestContainerLabelsOnContainers checks that the project's container labels are set on its running containers,
+// next to the labels of Compose
+func TestContainerLabelsOnContainers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	labels := map[string]string{"team": "payments", "environment": "test", "cost-center": "42"}
+	createdProject, err := ctx.projectManager.Create(&domain.Project{
+		ID:              uuid.New(),
+		Name:            "test-project-container-labels",
+		GitURL:          ctx.testRepoURL,
+		ComposeFiles:    []string{"compose.yaml"},
+		ContainerLabels: labels,
+	})
+	require.NoError(t, err, "Project creation should succeed")
+	ctx.setupCleanup(createdProject)
+
+	err = ctx.deployProject(createdProject.ID, true, 60)
+	require.NoError(t, err, "Deployment should succeed")
+	err = ctx.waitForProjectStatus(createdProject.ID, docker.ComposeProjectStatusRunning, 30*time.Second)
+	require.NoError(t, err, "Project should reach running status")
+
+	dockerClient, err := docker.NewDockerClient()
+	require.NoError(t, err, "Creating Docker client should succeed")
+	defer func() {
+		_ = dockerClient.Close()
+	}()
+
+	status, err := ctx.projectManager.GetStatus(createdProject.ID)
+	require.NoError(t, err, "Getting status should succeed")
+	require.NotEmpty(t, status.Containers, "Project should have containers")
+	for _, c := range status.Containers {
+		inspect, err := dockerClient.ContainerInspect(c.Name)
+		require.NoError(t, err, "Inspecting container should succeed")
+		for key, value := range labels {
+			assert.Equal(t, value, inspect.Config.Labels[key], "Container %s should have label %s", c.Name, key)
+		}
+		assert.Equal(t, createdProject.ComposeProjectName(), inspect.Config.Labels["com.docker.compose.project"],
+			"Compose labels should be kept")
+	}
+
+	err = ctx.projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+	require.NoError(t, err, "Project removal should succeed")
+}
+
+func TestRemoveGracefulShutdown(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	// A database stand-in: it ignores SIGTERM, and on SIGINT takes a few seconds to write a checkpoint
+	overrideContent := `services:
+  db:
+    image: busybox:latest
+    command:
+      - sh
+      - -c
+      - trap 'sleep 3; echo checkpoint > /data/checkpoint; exit 0' INT; while true; do sleep 1; done
+    volumes:
+      - ./data:/data`
+
+	tests := []struct {
+		name           string
+		options        project.RemoveOptions
+		wantCheckpoint bool
+	}{
+		{
+			name:           "test-project-remove-killed",
+			options:        project.RemoveOptions{StopTimeout: time.Second},
+			wantCheckpoint: false,
+		},
+		{
+			name:           "test-project-remove-graceful",
+			options:        project.RemoveOptions{StopSignal: "SIGINT", StopTimeout: 20 * time.Second},
+			wantCheckpoint: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createdProject, err := ctx.projectManager.Create(&domain.Project{
+				ID:              uuid.New(),
+				Name:            tt.name,
+				GitURL:          ctx.testRepoURL,
+				ComposeFiles:    []string{"compose.yaml"},
+				ComposeOverride: &overrideContent,
+			})
+			require.NoError(t, err, "Project creation should succeed")
+			ctx.setupCleanup(createdProject)
+
+			err = ctx.deployProject(createdProject.ID, true, 60)
+			require.NoError(t, err, "Deployment should succeed")
+			err = ctx.waitForProjectStatus(createdProject.ID, docker.ComposeProjectStatusRunning, 30*time.Second)
+			require.NoError(t, err, "Project should reach running status")
+
+			err = ctx.projectManager.Remove(createdProject.ID, tt.options)
+			require.NoError(t, err, "Project removal should succeed")
+
+			gitDir := filepath.Join(domain.GetDeletedDirectoryPath(createdProject.WorkingDir), domain.GitDir)
+			checkpoint, err := os.ReadFile(filepath.Join(gitDir, "data", "checkpoint"))
+			if tt.wantCheckpoint {
+				require.NoError(t, err, "The database should have written its checkpoint before it stopped")
+				assert.Equal(t, "checkpoint\n", string(checkpoint))
+			} else {
+				assert.ErrorIs(t, err, os.ErrNotExist, "The database should have been killed before its checkpoint")
+			}
+		})
+	}
+}
+
+func TestStopForceRemovesAfterDownTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := setupTest(t)
+
+	// A service that ignores SIGTERM with a grace period far longer than the down timeout, so that
+	// docker compose down is still waiting for it when the timeout expires
+	overrideContent := `services:
+  stubborn:
+    image: busybox:latest
+    command: ["sh", "-c", "trap '' TERM; while true; do sleep 1; done"]
+    stop_grace_period: 5m`
+
+	createdProject, err := ctx.projectManager.Create(&domain.Project{
+		ID:              uuid.New(),
+		Name:            "test-project-stop-force-remove",
+		GitURL:          ctx.testRepoURL,
+		ComposeFiles:    []string{"compose.yaml"},
+		ComposeOverride: &overrideContent,
+	})
+	require.NoError(t, err, "Project creation should succeed")
+	ctx.setupCleanup(createdProject)
+
+	err = ctx.deployProject(createdProject.ID, true, 60)
+	require.NoError(t, err, "Deployment should succeed")
+	err = ctx.waitForProjectStatus(createdProject.ID, docker.ComposeProjectStatusRunning, 30*time.Second)
+	require.NoError(t, err, "Project should reach running status")
+
+	composeProject, err := docker.NewComposeProject(createdProject, &config.Config{ComposeDownTimeout: 3 * time.Second})
+	require.NoError(t, err)
+
+	output := make(chan docker.StreamMessage, 1000)
+	start := time.Now()
+	require.NoError(t, composeProject.DownStreaming(docker.DownOptions{}, output),
+		"Stop should succeed by force removing the containers")
+	assert.Less(t, time.Since(start), time.Minute, "Stop should not wait for the grace period")
+	close(output)
+
+	var escalated bool
+	for msg := range output {
+		if strings.HasPrefix(msg.Content, "Force removed containers: ") {
+			escalated = true
+		}
+	}
+	assert.True(t, escalated, "Stop output should report the force removal")
+
+	status, err := composeProject.Status()
+	require.NoError(t, err)
+	assert.Empty(t, status.Containers, "No containers should be left")
+}
+
 // setupProjectCleanup registers a cleanup function that ensures the project is properly removed
 // regardless of test outcome, including Docker resources and bind mount files
 func setupProjectCleanup(
@@ -800,7 +1307,8 @@ func setupProjectCleanup(
 			// Project still exists in database - normal cleanup
 			t.Logf("Project %s found in database, removing normally", createdProject.ID)
 
-			if removeErr := projectManager.Remove(createdProject.ID, true); removeErr != nil {
+			removeErr := projectManager.Remove(createdProject.ID, project.RemoveOptions{RemoveVolumes: true})
+			if removeErr != nil {
 				t.Logf("Warning: Failed to remove existing project during cleanup: %v", removeErr)
 			}
 
@@ -836,7 +1344,8 @@ func setupProjectCleanup(
 
 				t.Logf("Created temporary project %s in database for cleanup, calling Remove", tempProject.ID)
 
-				if removeErr := projectManager.Remove(tempProject.ID, true); removeErr != nil {
+				removeErr := projectManager.Remove(tempProject.ID, project.RemoveOptions{RemoveVolumes: true})
+				if removeErr != nil {
 					t.Logf("Warning: Failed to remove temporary project during cleanup: %v", removeErr)
 				}
 
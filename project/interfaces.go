@@ -1,6 +1,9 @@
 package project
 
 import (
+	"io"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
@@ -11,16 +14,65 @@ type ProjectManager interface {
 	List() ([]*domain.Project, error)
 	Get(id uuid.UUID) (*domain.Project, error)
 	Create(project *domain.Project) (*domain.Project, error)
+	CreateStreaming(project *domain.Project, outputChan chan<- docker.StreamMessage) (*domain.Project, error)
+	CreateFromArchive(project *domain.Project, archive io.Reader) (*domain.Project, error)
+	ImportExisting(project *domain.Project, composeProjectName string) (*domain.Project, []string, error)
+	ImportDirectory(root string, dryRun bool) ([]DirectoryImportResult, error)
 	Update(project *domain.Project) error
-	Remove(projectID uuid.UUID, removeVolumes bool) error
-	DeployStreaming(projectID uuid.UUID, pull bool, outputChan chan<- docker.StreamMessage) error
-	DeployPiping(projectID uuid.UUID, pull bool) error
+	RedetectDefaultBranch(projectID uuid.UUID, confirm func(current, detected string) bool) (string, error)
+	Reorder(projectIDs []uuid.UUID) error
+	SetWatcherDisabled(projectIDs []uuid.UUID, disabled bool) error
+	PreviewVariableUpdate(selector ProjectSelector, key, value string) ([]VariableChange, error)
+	BulkUpdateVariable(selector ProjectSelector, key, value string) ([]VariableChange, error)
+	DeploymentOrder(projectIDs []uuid.UUID, withPrerequisites bool) ([]*domain.Project, error)
+	CheckDependencies(projectID uuid.UUID) error
+	DeployManyPiping(projectIDs []uuid.UUID, opts BulkDeployOptions) ([]BulkDeployResult, error)
+	Remove(projectID uuid.UUID, opts RemoveOptions) error
+	RemoveUnusedNetworks(names []string) ([]string, error)
+	SharedImages(projectID uuid.UUID) ([]string, error)
+	GetDiskUsage(projectID uuid.UUID) (*DiskUsage, error)
+	DeployStreaming(
+		projectID uuid.UUID,
+		pull bool,
+		services []string,
+		annotation domain.DeploymentAnnotation,
+		outputChan chan<- docker.StreamMessage,
+	) error
+	DeployPiping(projectID uuid.UUID, pull bool, services []string, annotation domain.DeploymentAnnotation) error
+	DeployChangesPiping(projectID uuid.UUID) error
+	DeployAutomaticPiping(projectID uuid.UUID, opts AutoDeployOptions) error
+	SubscribeDeployment(projectID uuid.UUID) (<-chan docker.StreamMessage, func(), error)
+	IsDeploying(projectID uuid.UUID) bool
+	IsRemoving(projectID uuid.UUID) bool
+	DeployStats() DeployStats
 	Stop(projectID uuid.UUID, removeVolumes bool) error
-	StopStreaming(projectID uuid.UUID, outputChan chan<- docker.StreamMessage) error
-	StopPiping(projectID uuid.UUID) error
-	GetLogs(projectID uuid.UUID) (string, string, error)
-	GetLogsPiping(projectID uuid.UUID) error
+	StopStreaming(projectID uuid.UUID, removeVolumes bool, outputChan chan<- docker.StreamMessage) error
+	StopPiping(projectID uuid.UUID, removeVolumes bool) error
+	// Pause freezes the containers of a project, Unpause resumes them
+	Pause(projectID uuid.UUID) error
+	Unpause(projectID uuid.UUID) error
+	KillService(projectID uuid.UUID, service, signal string, outputChan chan<- docker.StreamMessage) error
+	GetLogs(projectID uuid.UUID, opts docker.LogsOptions) (string, string, error)
+	GetContainerLogs(projectID uuid.UUID, container string, opts docker.LogsOptions) (string, string, error)
+	GetLogsPiping(projectID uuid.UUID, opts docker.LogsOptions) error
 	GetConfig(projectID uuid.UUID) (string, string, error)
+	GetMergedConfig(projectID uuid.UUID) (string, string, error)
+	ListServices(projectID uuid.UUID) ([]string, error)
+	ListDeclaredVolumes(projectID uuid.UUID) ([]string, error)
+	ListImages(projectID uuid.UUID) ([]string, error)
+	GetEffectiveEnv(projectID uuid.UUID, showSecrets bool) ([]docker.EnvVar, error)
 	GetStatus(projectID uuid.UUID) (*docker.ComposeStatus, error)
+	GetStatusMany(projectIDs []uuid.UUID) (map[uuid.UUID]*docker.ComposeStatus, map[uuid.UUID]error)
 	ListDeployments(projectID uuid.UUID) ([]*domain.Deployment, error)
+	GetDeployment(deploymentID uuid.UUID) (*domain.Deployment, error)
+	AnnotateDeployment(
+		projectID uuid.UUID,
+		deploymentID uuid.UUID,
+		annotation domain.DeploymentAnnotation,
+	) (*domain.Deployment, error)
+	GetDashboardSummary() (*DashboardSummary, error)
+	ReconcileInterruptedDeployments() error
+	SendTestNotification(projectID uuid.UUID) error
+	NotifyStatusCorrection(project *domain.Project, previous domain.ProjectStatus, containers []docker.ContainerInfo)
+	LastManualOperation(projectID uuid.UUID) time.Time
 }
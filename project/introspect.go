@@ -0,0 +1,53 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+)
+
+// ListServices returns the services declared in the project's rendered Compose configuration
+func (s *ProjectService) ListServices(projectID uuid.UUID) ([]string, error) {
+	return s.listFromConfig(projectID, "list_services", (*docker.ComposeProject).ListServices)
+}
+
+// ListDeclaredVolumes returns the named volumes declared in the project's rendered Compose configuration
+func (s *ProjectService) ListDeclaredVolumes(projectID uuid.UUID) ([]string, error) {
+	return s.listFromConfig(projectID, "list_declared_volumes", (*docker.ComposeProject).ListVolumes)
+}
+
+// ListImages returns the images used by the project's rendered Compose configuration
+func (s *ProjectService) ListImages(projectID uuid.UUID) ([]string, error) {
+	return s.listFromConfig(projectID, "list_images", (*docker.ComposeProject).ListImages)
+}
+
+// listFromConfig reads a list of names from the project's configuration. Only the configuration
+// is read, so this works for stopped projects as well.
+func (s *ProjectService) listFromConfig(
+	projectID uuid.UUID,
+	operation string,
+	list func(*docker.ComposeProject) ([]string, error),
+) ([]string, error) {
+	project, err := s.Get(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	names, err := list(composeProject)
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", operation,
+			"project_id", project.ID,
+			"error", err)
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	return names, nil
+}
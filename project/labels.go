@@ -0,0 +1,76 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// labelKeyPattern matches container label keys: lowercase or uppercase letters and digits, with dots,
+// dashes and underscores inside, as in "team" or "com.example.cost-center"
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+
+// reservedLabelPrefixes are the label namespaces Docker reserves for itself, where Compose keeps the
+// labels it finds the containers of a project by
+var reservedLabelPrefixes = []string{"com.docker.", "io.docker.", "org.dockerproject."}
+
+// normalizeContainerLabels trims the keys and values of the project's container labels and validates them
+func normalizeContainerLabels(project *domain.Project) error {
+	if len(project.ContainerLabels) == 0 {
+		project.ContainerLabels = nil
+		return nil
+	}
+	normalized := make(map[string]string, len(project.ContainerLabels))
+	for key, value := range project.ContainerLabels {
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("invalid container label %q: use letters, digits, dots, dashes and underscores", key)
+		}
+		for _, prefix := range reservedLabelPrefixes {
+			if strings.HasPrefix(strings.ToLower(key), prefix) {
+				return fmt.Errorf("container label %s is in the %s namespace reserved by Docker", key, prefix)
+			}
+		}
+		if strings.ContainsFunc(value, unicode.IsControl) {
+			return fmt.Errorf("value of container label %s must not contain control characters", key)
+		}
+		if _, ok := normalized[key]; ok {
+			return fmt.Errorf("duplicate container label %s", key)
+		}
+		normalized[key] = value
+	}
+	project.ContainerLabels = normalized
+	return nil
+}
+
+// FilterByLabels returns the projects whose container labels match all selectors. A selector is a
+// label key, which matches whatever its value, or key=value.
+func FilterByLabels(projects []*domain.Project, selectors []string) ([]*domain.Project, error) {
+	for _, selector := range selectors {
+		if key, _, _ := strings.Cut(selector, "="); strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid label selector %q: use key or key=value", selector)
+		}
+	}
+
+	var matching []*domain.Project
+	for _, project := range projects {
+		if matchesLabels(project, selectors) {
+			matching = append(matching, project)
+		}
+	}
+	return matching, nil
+}
+
+func matchesLabels(project *domain.Project, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, hasValue := strings.Cut(selector, "=")
+		actual, ok := project.ContainerLabels[strings.TrimSpace(key)]
+		if !ok || hasValue && actual != strings.TrimSpace(value) {
+			return false
+		}
+	}
+	return true
+}
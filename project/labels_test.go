@@ -0,0 +1,103 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestContainerLabels deploys a project with container labels, with a stub docker that records its calls
+func TestContainerLabels(t *testing.T) {
+	callsFile := newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: nginx:1.27\n  worker:\n    image: app/worker:1\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	gitDir := filepath.Join(workingDir, domain.GitDir)
+	initDeployableRepo(t, gitDir)
+	_, err := repos.projects.Create(&domain.Project{
+		ID:              projectID,
+		Name:            "shop",
+		GitURL:          "https://example.com/repo.git",
+		GitBranch:       "main",
+		WorkingDir:      workingDir,
+		ComposeFiles:    []string{"compose.yaml"},
+		Status:          domain.ProjectStatusStopped,
+		SkipVolumeInit:  true,
+		ContainerLabels: map[string]string{"team": "payments", "cost-center": "42"},
+	})
+	require.NoError(t, err)
+
+	t.Run("labels every service on deploy", func(t *testing.T) {
+		require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		var upCall string
+		for call := range strings.Lines(string(calls)) {
+			if strings.Contains(call, " up ") {
+				upCall = call
+			}
+		}
+		assert.Contains(t, upCall, "--file "+filepath.Join(gitDir, docker.ContainerLabelsFile))
+
+		content, err := os.ReadFile(filepath.Join(gitDir, docker.ContainerLabelsFile))
+		require.NoError(t, err)
+		for _, service := range []string{"web", "worker"} {
+			assert.Contains(t, string(content),
+				"  "+service+":\n    labels:\n      cost-center: \"42\"\n      team: payments\n")
+		}
+	})
+
+	t.Run("filter by labels", func(t *testing.T) {
+		projects, err := projectService.List()
+		require.NoError(t, err)
+
+		matching, err := project.FilterByLabels(projects, []string{"team=payments", "cost-center"})
+		require.NoError(t, err)
+		require.Len(t, matching, 1)
+		assert.Equal(t, projectID, matching[0].ID)
+
+		matching, err = project.FilterByLabels(projects, []string{"team=search"})
+		require.NoError(t, err)
+		assert.Empty(t, matching)
+
+		_, err = project.FilterByLabels(projects, []string{"=payments"})
+		assert.EqualError(t, err, `invalid label selector "=payments": use key or key=value`)
+	})
+
+	t.Run("invalid labels", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+
+		stored.ContainerLabels = map[string]string{"cost center": "42"}
+		assert.EqualError(t, projectService.Update(stored),
+			`invalid container label "cost center": use letters, digits, dots, dashes and underscores`)
+
+		stored.ContainerLabels = map[string]string{"com.docker.compose.project": "other"}
+		assert.EqualError(t, projectService.Update(stored),
+			"container label com.docker.compose.project is in the com.docker. namespace reserved by Docker")
+
+		stored.ContainerLabels = map[string]string{"team": "pay\nments"}
+		assert.EqualError(t, projectService.Update(stored),
+			"value of container label team must not contain control characters")
+	})
+}
@@ -0,0 +1,155 @@
+package project
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+)
+
+// ErrDeploymentDeferred is returned for a deployment started by the watcher when another operation on
+// the project, such as a deployment started by a user, is running or waiting. The watcher checks the
+// project again on its next poll instead of deploying it twice.
+var ErrDeploymentDeferred = errors.New("another operation on the project is in progress")
+
+// ErrDeploymentInProgress is returned when removing a project while a deployment of it is running or
+// waiting. The project is left as it is; remove it once the deployment has finished.
+var ErrDeploymentInProgress = errors.New("a deployment of the project is in progress")
+
+// ErrProjectRemoving is returned for a deployment of a project that is being removed
+var ErrProjectRemoving = errors.New("the project is being removed")
+
+// projectLocks serializes operations that change a project's containers or working directory.
+// The web interface and the watcher share one ProjectService, so a deployment triggered by the
+// watcher must not run at the same time as one started by a user.
+type projectLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*sync.Mutex
+	// Projects locked by a deployment the watcher started
+	watcherDeployments map[uuid.UUID]bool
+	// Deployments started by users, per project, while they wait for the lock or hold it
+	userDeployments map[uuid.UUID]int
+	// Projects being removed, from the time the removal waits for the lock until it ends
+	removals map[uuid.UUID]bool
+}
+
+func newProjectLocks() *projectLocks {
+	return &projectLocks{
+		locks:              make(map[uuid.UUID]*sync.Mutex),
+		watcherDeployments: make(map[uuid.UUID]bool),
+		userDeployments:    make(map[uuid.UUID]int),
+		removals:           make(map[uuid.UUID]bool),
+	}
+}
+
+func (l *projectLocks) get(projectID uuid.UUID) *sync.Mutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.getLocked(projectID)
+}
+
+func (l *projectLocks) getLocked(projectID uuid.UUID) *sync.Mutex {
+	lock, ok := l.locks[projectID]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[projectID] = lock
+	}
+	return lock
+}
+
+// lock acquires the project lock, telling the user when they have to wait for another operation.
+// outputChan may be nil. The returned function releases the lock.
+func (l *projectLocks) lock(projectID uuid.UUID, outputChan chan<- docker.StreamMessage) func() {
+	lock := l.get(projectID)
+	if !lock.TryLock() {
+		if outputChan != nil {
+			outputChan <- docker.StreamMessage{Type: "info", Content: l.waitMessage(projectID)}
+		}
+		lock.Lock()
+	}
+	return lock.Unlock
+}
+
+// waitMessage tells the user what their operation waits for
+func (l *projectLocks) waitMessage(projectID uuid.UUID) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.watcherDeployments[projectID] {
+		return "Waiting for the automatic deployment started by the watcher to finish..."
+	}
+	return "Waiting for another operation on this project to finish..."
+}
+
+// lockDeployment acquires the project lock for a deployment. A deployment started by a user waits
+// for the lock like any other operation. One started by the watcher never waits: it fails with
+// ErrDeploymentDeferred when another operation holds the lock, or a user's deployment waits for it.
+// Neither deploys a project that is being removed.
+func (l *projectLocks) lockDeployment(
+	projectID uuid.UUID,
+	automatic bool,
+	outputChan chan<- docker.StreamMessage,
+) (func(), error) {
+	if automatic {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		lock := l.getLocked(projectID)
+		if l.userDeployments[projectID] > 0 || l.removals[projectID] || !lock.TryLock() {
+			return nil, ErrDeploymentDeferred
+		}
+		l.watcherDeployments[projectID] = true
+		return func() {
+			l.mu.Lock()
+			delete(l.watcherDeployments, projectID)
+			l.mu.Unlock()
+			lock.Unlock()
+		}, nil
+	}
+
+	l.mu.Lock()
+	if l.removals[projectID] {
+		l.mu.Unlock()
+		return nil, ErrProjectRemoving
+	}
+	l.userDeployments[projectID]++
+	l.mu.Unlock()
+
+	unlock := l.lock(projectID, outputChan)
+	return func() {
+		l.mu.Lock()
+		if l.userDeployments[projectID]--; l.userDeployments[projectID] == 0 {
+			delete(l.userDeployments, projectID)
+		}
+		l.mu.Unlock()
+		unlock()
+	}, nil
+}
+
+// lockRemoval acquires the project lock for removing the project. It fails with
+// ErrDeploymentInProgress when a deployment holds the lock or waits for it, and waits for any other
+// operation. Deployments started once the removal is under way are refused.
+func (l *projectLocks) lockRemoval(projectID uuid.UUID) (func(), error) {
+	l.mu.Lock()
+	if l.userDeployments[projectID] > 0 || l.watcherDeployments[projectID] {
+		l.mu.Unlock()
+		return nil, ErrDeploymentInProgress
+	}
+	l.removals[projectID] = true
+	l.mu.Unlock()
+
+	unlock := l.lock(projectID, nil)
+	return func() {
+		l.mu.Lock()
+		delete(l.removals, projectID)
+		l.mu.Unlock()
+		unlock()
+	}, nil
+}
+
+// removing reports whether the project is being removed
+func (l *projectLocks) removing(projectID uuid.UUID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.removals[projectID]
+}
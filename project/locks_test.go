@@ -0,0 +1,154 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func TestLockDeploymentUserFirst(t *testing.T) {
+	locks := newProjectLocks()
+	projectID := uuid.New()
+
+	unlockUser, err := locks.lockDeployment(projectID, false, nil)
+	require.NoError(t, err)
+
+	// The watcher gives way to the running deployment instead of queueing a second one
+	_, err = locks.lockDeployment(projectID, true, nil)
+	assert.ErrorIs(t, err, ErrDeploymentDeferred)
+
+	// Another project is not affected
+	unlockOther, err := locks.lockDeployment(uuid.New(), true, nil)
+	require.NoError(t, err)
+	unlockOther()
+
+	unlockUser()
+	unlockWatcher, err := locks.lockDeployment(projectID, true, nil)
+	require.NoError(t, err, "The next check deploys once the user's deployment has ended")
+	unlockWatcher()
+}
+
+func TestLockDeploymentUserWaiting(t *testing.T) {
+	locks := newProjectLocks()
+	projectID := uuid.New()
+
+	// A stop holds the lock, and a user's deployment waits for it
+	unlockStop := locks.lock(projectID, nil)
+	userLocked := make(chan func())
+	go func() {
+		unlock, err := locks.lockDeployment(projectID, false, nil)
+		assert.NoError(t, err)
+		userLocked <- unlock
+	}()
+	require.Eventually(t, func() bool {
+		locks.mu.Lock()
+		defer locks.mu.Unlock()
+		return locks.userDeployments[projectID] == 1
+	}, time.Second, time.Millisecond)
+
+	_, err := locks.lockDeployment(projectID, true, nil)
+	assert.ErrorIs(t, err, ErrDeploymentDeferred)
+
+	unlockStop()
+	unlockUser := <-userLocked
+	_, err = locks.lockDeployment(projectID, true, nil)
+	assert.ErrorIs(t, err, ErrDeploymentDeferred)
+	unlockUser()
+}
+
+func TestLockDeploymentWatcherFirst(t *testing.T) {
+	locks := newProjectLocks()
+	projectID := uuid.New()
+
+	unlockWatcher, err := locks.lockDeployment(projectID, true, nil)
+	require.NoError(t, err)
+
+	// The user's deployment is told what it waits for, and runs after the watcher's
+	outputChan := make(chan docker.StreamMessage, 1)
+	userLocked := make(chan func())
+	go func() {
+		unlock, err := locks.lockDeployment(projectID, false, outputChan)
+		assert.NoError(t, err)
+		userLocked <- unlock
+	}()
+
+	select {
+	case msg := <-outputChan:
+		assert.Equal(t, "Waiting for the automatic deployment started by the watcher to finish...", msg.Content)
+	case <-time.After(time.Second):
+		t.Fatal("The user's deployment should report that it waits")
+	}
+	select {
+	case <-userLocked:
+		t.Fatal("The user's deployment must wait for the watcher's")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlockWatcher()
+	unlockUser := <-userLocked
+
+	// With the watcher done, other operations wait with the generic message
+	assert.Equal(t, "Waiting for another operation on this project to finish...", locks.waitMessage(projectID))
+	unlockUser()
+}
+
+func TestLockRemoval(t *testing.T) {
+	locks := newProjectLocks()
+	projectID := uuid.New()
+
+	// A removal is refused while a deployment runs
+	unlockUser, err := locks.lockDeployment(projectID, false, nil)
+	require.NoError(t, err)
+	_, err = locks.lockRemoval(projectID)
+	assert.ErrorIs(t, err, ErrDeploymentInProgress)
+	assert.False(t, locks.removing(projectID), "A refused removal must not block deployments")
+	unlockUser()
+
+	unlockWatcher, err := locks.lockDeployment(projectID, true, nil)
+	require.NoError(t, err)
+	_, err = locks.lockRemoval(projectID)
+	assert.ErrorIs(t, err, ErrDeploymentInProgress)
+	unlockWatcher()
+
+	// Once under way, it refuses new deployments until it ends
+	unlockRemoval, err := locks.lockRemoval(projectID)
+	require.NoError(t, err)
+	assert.True(t, locks.removing(projectID))
+	_, err = locks.lockDeployment(projectID, true, nil)
+	assert.ErrorIs(t, err, ErrDeploymentDeferred)
+	_, err = locks.lockDeployment(projectID, false, nil)
+	assert.ErrorIs(t, err, ErrProjectRemoving)
+
+	unlockRemoval()
+	assert.False(t, locks.removing(projectID))
+	unlockUser, err = locks.lockDeployment(projectID, false, nil)
+	require.NoError(t, err)
+	unlockUser()
+}
+
+func TestLockRemovalWaitsForOtherOperations(t *testing.T) {
+	locks := newProjectLocks()
+	projectID := uuid.New()
+
+	unlockStop := locks.lock(projectID, nil)
+	removalLocked := make(chan func())
+	go func() {
+		unlock, err := locks.lockRemoval(projectID)
+		assert.NoError(t, err)
+		removalLocked <- unlock
+	}()
+	require.Eventually(t, func() bool { return locks.removing(projectID) }, time.Second, time.Millisecond)
+
+	// A deployment started while the removal waits is refused, not queued behind it
+	_, err := locks.lockDeployment(projectID, false, nil)
+	assert.ErrorIs(t, err, ErrProjectRemoving)
+
+	unlockStop()
+	unlockRemoval := <-removalLocked
+	unlockRemoval()
+}
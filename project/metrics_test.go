@@ -0,0 +1,70 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/metrics"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeploymentMetrics deploys a project with a stub docker and checks what the registry recorded
+func TestDeploymentMetrics(t *testing.T) {
+	failFile := filepath.Join(t.TempDir(), "fail")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: nginx:1.27\n'
+		exit 0
+		;;
+	up)
+		[ -f `+failFile+` ] && exit 1
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+	registry := metrics.NewRegistry()
+	projectService.SetMetrics(registry)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	text := func() string {
+		var out strings.Builder
+		require.NoError(t, registry.WriteText(&out))
+		return out.String()
+	}
+
+	require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+	require.NoError(t, os.WriteFile(failFile, nil, 0o644))
+	require.Error(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+
+	assert.Contains(t, text(), `oar_deploy_total{project="shop",status="completed"} 1`)
+	assert.Contains(t, text(), `oar_deploy_total{project="shop",status="failed"} 1`)
+	assert.Contains(t, text(), `oar_deploy_duration_seconds_count{project="shop"} 2`)
+
+	require.NoError(t, projectService.Remove(projectID, project.RemoveOptions{}))
+	assert.NotContains(t, text(), `project="shop"`)
+}
@@ -0,0 +1,114 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// normalizeExternalNetworks trims and deduplicates the project's external networks and validates
+// their names
+func normalizeExternalNetworks(project *domain.Project) error {
+	networks := []string{}
+	for _, name := range project.ExternalNetworks {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(networks, name) {
+			continue
+		}
+		if err := docker.ValidateNetworkName(name); err != nil {
+			return err
+		}
+		networks = append(networks, name)
+	}
+	project.ExternalNetworks = networks
+	return nil
+}
+
+// ensureExternalNetworks creates the project's external networks that do not exist yet and returns
+// a message per created network
+func ensureExternalNetworks(project *domain.Project) ([]string, error) {
+	if len(project.ExternalNetworks) == 0 {
+		return nil, nil
+	}
+
+	dockerClient, err := docker.NewDockerClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			slog.Debug("Failed to close Docker client", "error", closeErr)
+		}
+	}()
+
+	var messages []string
+	for _, name := range project.ExternalNetworks {
+		created, err := dockerClient.EnsureNetwork(name)
+		if err != nil {
+			return messages, err
+		}
+		if created {
+			slog.Info("Created external network",
+				"project_id", project.ID,
+				"project_name", project.Name,
+				"network", name)
+			messages = append(messages, fmt.Sprintf("Created network %s", name))
+		}
+	}
+	return messages, nil
+}
+
+// UnreferencedNetworks returns the networks among names that no project lists as external network
+func (s *ProjectService) UnreferencedNetworks(names []string) ([]string, error) {
+	projects, err := s.projectRepository.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	unreferenced := []string{}
+	for _, name := range names {
+		referenced := slices.ContainsFunc(projects, func(p *domain.Project) bool {
+			return slices.Contains(p.ExternalNetworks, name)
+		})
+		if !referenced && !slices.Contains(unreferenced, name) {
+			unreferenced = append(unreferenced, name)
+		}
+	}
+	return unreferenced, nil
+}
+
+// RemoveUnusedNetworks removes the networks among names that no project references anymore and
+// returns the removed ones. Only networks created by Oar are removed.
+func (s *ProjectService) RemoveUnusedNetworks(names []string) ([]string, error) {
+	unreferenced, err := s.UnreferencedNetworks(names)
+	if err != nil || len(unreferenced) == 0 {
+		return nil, err
+	}
+
+	dockerClient, err := docker.NewDockerClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Docker client: %w", err)
+	}
+	defer func() {
+		if closeErr := dockerClient.Close(); closeErr != nil {
+			slog.Debug("Failed to close Docker client", "error", closeErr)
+		}
+	}()
+
+	removed := []string{}
+	for _, name := range unreferenced {
+		ok, err := dockerClient.RemoveManagedNetwork(name)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			slog.Info("Removed unused network", "network", name)
+			removed = append(removed, name)
+		}
+	}
+	return removed, nil
+}
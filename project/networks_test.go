@@ -0,0 +1,83 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+func TestExternalNetworks(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(name string, networks ...string) *domain.Project {
+		projectID := uuid.New()
+		created, err := repos.projects.Create(&domain.Project{
+			ID:               projectID,
+			Name:             name,
+			GitURL:           "https://example.com/repo.git",
+			GitBranch:        "main",
+			WorkingDir:       filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles:     []string{"compose.yaml"},
+			Status:           domain.ProjectStatusStopped,
+			ExternalNetworks: networks,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	frontend := createProject("frontend", "proxy")
+	backend := createProject("backend", "proxy", "internal")
+
+	t.Run("networks are stored", func(t *testing.T) {
+		stored, err := projectService.Get(backend.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"proxy", "internal"}, stored.ExternalNetworks)
+	})
+
+	t.Run("update trims and deduplicates", func(t *testing.T) {
+		stored, err := projectService.Get(frontend.ID)
+		require.NoError(t, err)
+		stored.ExternalNetworks = []string{" proxy ", "", "proxy", "monitoring"}
+
+		require.NoError(t, projectService.Update(stored))
+
+		updated, err := projectService.Get(frontend.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"proxy", "monitoring"}, updated.ExternalNetworks)
+	})
+
+	t.Run("update rejects an invalid name", func(t *testing.T) {
+		stored, err := projectService.Get(frontend.ID)
+		require.NoError(t, err)
+		stored.ExternalNetworks = []string{"proxy", "my network"}
+
+		err = projectService.Update(stored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid network name "my network"`)
+	})
+
+	t.Run("shared network stays referenced", func(t *testing.T) {
+		unreferenced, err := projectService.UnreferencedNetworks([]string{"proxy", "internal"})
+		require.NoError(t, err)
+		assert.Empty(t, unreferenced)
+	})
+
+	t.Run("network of a removed project", func(t *testing.T) {
+		require.NoError(t, repos.projects.Delete(backend.ID))
+
+		unreferenced, err := projectService.UnreferencedNetworks([]string{"proxy", "internal"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"internal"}, unreferenced, "proxy is still used by frontend")
+
+		require.NoError(t, repos.projects.Delete(frontend.ID))
+
+		unreferenced, err = projectService.UnreferencedNetworks([]string{"proxy", "internal"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"proxy", "internal"}, unreferenced)
+	})
+}
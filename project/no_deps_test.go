@@ -0,0 +1,92 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployServiceWithoutDependencies redeploys one service of a project whose database it depends
+// on, and checks what docker compose up is asked to touch
+func TestDeployServiceWithoutDependencies(t *testing.T) {
+	upArgsFile := filepath.Join(t.TempDir(), "up-args")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  app:\n    image: app\n    depends_on:\n      db:\n        condition: service_started\n'
+		printf '  db:\n    image: postgres\n'
+		exit 0
+		;;
+	up)
+		echo "$@" > `+upArgsFile+`
+		exit 0
+		;;
+	ps)
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusRunning,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	// deploy deploys the project and returns the arguments of docker compose up after "up"
+	deploy := func(t *testing.T, opts project.BulkDeployOptions) []string {
+		t.Helper()
+		_ = os.Remove(upArgsFile)
+		results, err := projectService.DeployManyPiping([]uuid.UUID{projectID}, opts)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+
+		content, err := os.ReadFile(upArgsFile)
+		require.NoError(t, err)
+		_, upArgs, _ := strings.Cut(strings.TrimSpace(string(content)), " up ")
+		return strings.Fields(upArgs)
+	}
+
+	t.Run("selected service only", func(t *testing.T) {
+		upArgs := deploy(t, project.BulkDeployOptions{Services: []string{"app"}, NoDeps: true})
+		assert.Equal(t, []string{"--no-deps", "app"}, upArgs[len(upArgs)-2:])
+		assert.NotContains(t, upArgs, "db")
+
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		assert.Contains(t, deployments[0].CommandLine, "--no-deps app")
+	})
+
+	t.Run("selected service with its dependencies", func(t *testing.T) {
+		upArgs := deploy(t, project.BulkDeployOptions{Services: []string{"app"}})
+		assert.NotContains(t, upArgs, "--no-deps")
+		assert.Equal(t, "app", upArgs[len(upArgs)-1])
+	})
+
+	t.Run("no effect without services", func(t *testing.T) {
+		upArgs := deploy(t, project.BulkDeployOptions{NoDeps: true})
+		assert.NotContains(t, upArgs, "--no-deps")
+		assert.NotContains(t, upArgs, "app")
+	})
+}
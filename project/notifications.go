@@ -0,0 +1,234 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/notify"
+)
+
+// notifyTimeout bounds sending a deployment notification, which delays the end of the deployment
+const notifyTimeout = 10 * time.Second
+
+// normalizeNotifications trims the project's notification settings and rejects an invalid webhook URL,
+// template or email settings, so that mistakes are reported when the project is saved rather than after
+// a deployment
+func normalizeNotifications(project *domain.Project) error {
+	project.NotifyWebhookURL = strings.TrimSpace(project.NotifyWebhookURL)
+	project.NotifyChannel = strings.TrimSpace(project.NotifyChannel)
+	if strings.TrimSpace(project.NotifyTemplate) == "" {
+		project.NotifyTemplate = ""
+	}
+
+	if project.NotifyWebhookURL != "" {
+		if err := notify.ValidateWebhookURL(project.NotifyWebhookURL); err != nil {
+			return err
+		}
+	}
+	if project.NotifyTemplate != "" {
+		if err := notify.ValidateTemplate(project.NotifyTemplate); err != nil {
+			return err
+		}
+	}
+
+	if email := project.NotifyEmail; email != nil {
+		email.Host = strings.TrimSpace(email.Host)
+		email.From = strings.TrimSpace(email.From)
+		var to []string
+		for _, address := range email.To {
+			if address = strings.TrimSpace(address); address != "" {
+				to = append(to, address)
+			}
+		}
+		email.To = to
+
+		switch {
+		case email.Host != "":
+			if err := projectSMTPConfig(email).Validate(); err != nil {
+				return err
+			}
+		case len(email.To) > 0:
+			// Recipients alone are sent to through the configured SMTP server
+			if err := notify.ValidateRecipients(email.To); err != nil {
+				return err
+			}
+		default:
+			project.NotifyEmail = nil
+		}
+	}
+	return nil
+}
+
+// projectSMTPConfig converts the email settings of a project
+func projectSMTPConfig(email *domain.EmailNotifyConfig) *notify.SMTPConfig {
+	return &notify.SMTPConfig{
+		Host:     email.Host,
+		Port:     email.Port,
+		Username: email.Username,
+		Password: email.Password,
+		From:     email.From,
+		To:       email.To,
+		TLS:      email.TLS,
+	}
+}
+
+// notifyRoute returns where the project's notifications go: its own settings, falling back to the
+// configured defaults
+func (s *ProjectService) notifyRoute(project *domain.Project) notify.Route {
+	own := notify.Route{WebhookURL: project.NotifyWebhookURL, Channel: project.NotifyChannel}
+	if project.NotifyEmail != nil {
+		own.Email = projectSMTPConfig(project.NotifyEmail)
+	}
+	return notify.Resolve(own, s.config.NotifyRoute())
+}
+
+// notifyDeployment sends a notification about a finished deployment to the project's route, or to the
+// configured default route. Nothing is sent when neither has a webhook URL or SMTP host. Failing to
+// notify does not fail the deployment.
+func (s *ProjectService) notifyDeployment(
+	project *domain.Project,
+	deployment *domain.Deployment,
+	commitHash string,
+	startedAt time.Time,
+	deployErr error,
+) {
+	route := s.notifyRoute(project)
+	if route.IsEmpty() {
+		return
+	}
+
+	event := notify.Event{
+		Status:       notify.StatusSucceeded,
+		ProjectID:    project.ID.String(),
+		ProjectName:  project.Name,
+		GitURL:       project.GitURL,
+		GitBranch:    project.GitBranch,
+		DeploymentID: deployment.ID.String(),
+		Commit:       commitHash,
+		Notes:        deployment.Notes,
+		Labels:       deployment.Labels,
+		Duration:     time.Since(startedAt).Round(time.Second),
+	}
+	if deployErr != nil {
+		event.Status = notify.StatusFailed
+		event.Error = deployErr.Error()
+	}
+
+	if err := s.sendNotification(project, route, event); err != nil {
+		// Logged with the targets but never the credentials, which errors do not include
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "notify_deployment",
+			"project_id", project.ID,
+			"deployment_id", deployment.ID,
+			"error", err)
+	}
+}
+
+// NotifyStatusCorrection sends a notification that the stored status of a project was corrected to match
+// its containers, which usually means that they crashed or were changed outside of Oar. The message is
+// fixed, notification templates describe deployments. Failing to notify is only logged.
+func (s *ProjectService) NotifyStatusCorrection(
+	project *domain.Project,
+	previous domain.ProjectStatus,
+	containers []docker.ContainerInfo,
+) {
+	route := s.notifyRoute(project)
+	if route.IsEmpty() {
+		return
+	}
+
+	text := fmt.Sprintf("Status of %s corrected from %s to %s", project.Name, previous, project.Status)
+	if len(containers) > 0 {
+		states := make([]string, 0, len(containers))
+		for _, container := range containers {
+			states = append(states, containerState(container))
+		}
+		text += ": " + strings.Join(states, ", ")
+	}
+	msg := notify.Message{Subject: fmt.Sprintf("Status of %s changed to %s", project.Name, project.Status), Text: text}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := s.notifier.Send(ctx, route, msg); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "notify_status_correction",
+			"project_id", project.ID,
+			"error", err)
+	}
+}
+
+// containerState describes the state of a container for a status correction notification
+func containerState(container docker.ContainerInfo) string {
+	state := container.Name + " " + container.State
+	switch {
+	case container.LastExitReason != "":
+		state += " (" + container.LastExitReason + ")"
+	case container.State == "exited":
+		state += fmt.Sprintf(" (exit code %d)", container.ExitCode)
+	}
+	return state
+}
+
+// sendNotification renders the project's template, or the configured default, for the event and sends it
+func (s *ProjectService) sendNotification(project *domain.Project, route notify.Route, event notify.Event) error {
+	tmpl := project.NotifyTemplate
+	if tmpl == "" {
+		tmpl = s.config.NotifyTemplate
+	}
+	if tmpl == "" {
+		tmpl = notify.DefaultTemplate
+	}
+	text, err := notify.Render(tmpl, event)
+	if err != nil {
+		// Templates are validated when saved, but the default template still gets the message out
+		slog.Warn("Failed to render notification template, using the default",
+			"project_id", project.ID,
+			"error", err)
+		text, _ = notify.Render(notify.DefaultTemplate, event)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	return s.notifier.Send(ctx, route, notify.Message{Subject: event.Subject(), Text: text})
+}
+
+// SendTestNotification sends a test notification through the route of the project, or through the
+// configured default route when projectID is uuid.Nil. Unlike deployment notifications, failures are
+// returned, so that the settings can be checked.
+func (s *ProjectService) SendTestNotification(projectID uuid.UUID) error {
+	project := &domain.Project{Name: "oar-test"}
+	route := s.config.NotifyRoute()
+	if projectID != uuid.Nil {
+		var err error
+		if project, err = s.Get(projectID); err != nil {
+			return err
+		}
+		route = s.notifyRoute(project)
+	}
+	if route.IsEmpty() {
+		return errors.New("no notification webhook URL or SMTP host is configured")
+	}
+
+	event := notify.Event{
+		Status:      notify.StatusSucceeded,
+		ProjectID:   project.ID.String(),
+		ProjectName: project.Name,
+		GitURL:      project.GitURL,
+		GitBranch:   project.GitBranch,
+		Commit:      project.LocalCommitStr(),
+		Notes:       "Test notification sent with oar notify test",
+	}
+	if err := s.sendNotification(project, route, event); err != nil {
+		return fmt.Errorf("failed to send test notification: %w", err)
+	}
+	return nil
+}
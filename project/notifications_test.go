@@ -0,0 +1,255 @@
+package project_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// notificationPayload is the message posted to a chat webhook
+type notificationPayload struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel"`
+}
+
+// newNotificationServer returns a webhook that records the notifications posted to it
+func newNotificationServer(t *testing.T) (*httptest.Server, chan notificationPayload) {
+	received := make(chan notificationPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload notificationPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- payload
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+// TestDeploymentNotifications deploys with a stub docker whose up command fails on request, and checks
+// the notifications sent to a project's own route with its template and to the default route
+func TestDeploymentNotifications(t *testing.T) {
+	// Stub docker: config prints a minimal configuration, up fails while the fail marker exists
+	failPath := filepath.Join(t.TempDir(), "fail")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		if [ -e "`+failPath+`" ]; then
+			echo "port is already allocated" >&2
+			exit 1
+		fi
+		exit 0
+		;;
+	esac
+done`)
+
+	teamServer, teamNotifications := newNotificationServer(t)
+	defaultServer, defaultNotifications := newNotificationServer(t)
+
+	cfg := newTestConfig(t)
+	cfg.NotifyWebhookURL = defaultServer.URL
+	cfg.NotifyChannel = "#ops"
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	createProject := func(t *testing.T, p domain.Project) *domain.Project {
+		p.SkipVolumeInit = true
+		created, err := projectService.Create(&p)
+		require.NoError(t, err)
+		return created
+	}
+
+	teamProject := domain.NewProject("billing", originDir, []string{"compose.yaml"}, nil)
+	teamProject.NotifyWebhookURL = teamServer.URL
+	teamProject.NotifyChannel = "#billing"
+	teamProject.NotifyTemplate = `{{if .Succeeded}}{{.ProjectName}} is live on {{.GitBranch}} at {{.ShortCommit}}` +
+		`{{else}}{{.ProjectName}} deployment {{.Status}}: {{.Error}}{{end}}`
+	team := createProject(t, teamProject)
+	shortCommit := team.LocalCommitStr()[:8]
+
+	t.Run("success with project template", func(t *testing.T) {
+		require.NoError(t, projectService.DeployPiping(team.ID, false, nil, domain.DeploymentAnnotation{}))
+
+		payload := <-teamNotifications
+		assert.Equal(t, "billing is live on master at "+shortCommit, payload.Text)
+		assert.Equal(t, "#billing", payload.Channel)
+	})
+
+	t.Run("failure with project template", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(failPath, nil, 0o644))
+		defer func() { require.NoError(t, os.Remove(failPath)) }()
+
+		require.Error(t, projectService.DeployPiping(team.ID, false, nil, domain.DeploymentAnnotation{}))
+
+		payload := <-teamNotifications
+		assert.Contains(t, payload.Text, "billing deployment failed: container creation failed")
+		assert.Equal(t, "#billing", payload.Channel)
+	})
+
+	t.Run("default route and template", func(t *testing.T) {
+		other := createProject(t, domain.NewProject("search", originDir, []string{"compose.yaml"}, nil))
+		require.NoError(t, projectService.DeployPiping(other.ID, false, nil, domain.DeploymentAnnotation{}))
+
+		payload := <-defaultNotifications
+		assert.Equal(t, "Deployment of search ("+shortCommit+") succeeded", payload.Text)
+		assert.Equal(t, "#ops", payload.Channel)
+	})
+
+	assert.Empty(t, teamNotifications, "Project route should get one notification per deployment")
+	assert.Empty(t, defaultNotifications, "Default route should not get notifications of projects with a route")
+}
+
+// TestStatusCorrectionNotification checks the notification sent when the watcher corrects a stored status
+func TestStatusCorrectionNotification(t *testing.T) {
+	server, notifications := newNotificationServer(t)
+	cfg := &config.Config{DataDir: t.TempDir(), NotifyWebhookURL: server.URL}
+	projectService := project.NewProjectService(nil, nil, nil, cfg)
+
+	p := &domain.Project{ID: uuid.New(), Name: "shop", Status: domain.ProjectStatusStopped}
+	projectService.NotifyStatusCorrection(p, domain.ProjectStatusError, []docker.ContainerInfo{
+		{
+			Name:           "shop-web-1",
+			State:          "exited",
+			ExitCode:       137,
+			LastExitReason: "killed by the OOM killer (exit code 137)",
+		},
+		{Name: "shop-db-1", State: "exited"},
+	})
+
+	payload := <-notifications
+	assert.Equal(t, "Status of shop corrected from error to stopped: "+
+		"shop-web-1 exited (killed by the OOM killer (exit code 137)), shop-db-1 exited (exit code 0)", payload.Text)
+}
+
+// TestNotificationSettingsValidation checks that invalid notification settings are rejected when a project
+// is saved
+func TestNotificationSettingsValidation(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	p := domain.NewProject("notified", originDir, []string{"compose.yaml"}, nil)
+	p.NotifyTemplate = "{{.ProjectName"
+	_, err := projectService.Create(&p)
+	assert.ErrorContains(t, err, "invalid notification template")
+
+	// Unknown fields are only found when the template is rendered
+	p.NotifyTemplate = "{{.Project}} deployed"
+	_, err = projectService.Create(&p)
+	assert.ErrorContains(t, err, "invalid notification template")
+
+	p.NotifyTemplate = ""
+	p.NotifyWebhookURL = "hooks.example.com/oar"
+	_, err = projectService.Create(&p)
+	assert.ErrorContains(t, err, "invalid notification webhook URL")
+
+	p.NotifyWebhookURL = " https://hooks.example.com/oar "
+	p.NotifyTemplate = "{{.ProjectName}} {{.Status}}"
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/oar", created.NotifyWebhookURL)
+
+	created.NotifyTemplate = "{{.Nope}}"
+	assert.ErrorContains(t, projectService.Update(created), "invalid notification template")
+}
+
+// TestEmailNotificationSettings checks that a project's email settings are validated, and stored with the
+// SMTP password encrypted
+func TestEmailNotificationSettings(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+
+	p := domain.NewProject("mailed", originDir, []string{"compose.yaml"}, nil)
+	p.NotifyEmail = &domain.EmailNotifyConfig{Host: "smtp.example.com", To: []string{"team@example.com"}}
+	_, err := projectService.Create(&p)
+	assert.ErrorContains(t, err, "invalid email notification settings")
+
+	p.NotifyEmail = &domain.EmailNotifyConfig{To: []string{"not an address"}}
+	_, err = projectService.Create(&p)
+	assert.ErrorContains(t, err, "invalid recipient")
+
+	p.NotifyEmail = &domain.EmailNotifyConfig{
+		Host:     " smtp.example.com ",
+		Username: "oar",
+		Password: "smtp-secret",
+		From:     "oar@example.com",
+		To:       []string{" team@example.com", ""},
+	}
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+
+	var stored string
+	query := repos.projects.DB().Raw("SELECT notify_email FROM projects WHERE id = ?", created.ID)
+	require.NoError(t, query.Scan(&stored).Error)
+	assert.NotEmpty(t, stored)
+	assert.NotContains(t, stored, "smtp-secret", "SMTP password should be stored encrypted")
+
+	fetched, err := projectService.Get(created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, fetched.NotifyEmail)
+	assert.Equal(t, "smtp.example.com", fetched.NotifyEmail.Host)
+	assert.Equal(t, "smtp-secret", fetched.NotifyEmail.Password)
+	assert.Equal(t, []string{"team@example.com"}, fetched.NotifyEmail.To)
+
+	// Clearing the recipients and server removes the settings
+	fetched.NotifyEmail = &domain.EmailNotifyConfig{}
+	require.NoError(t, projectService.Update(fetched))
+	fetched, err = projectService.Get(created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, fetched.NotifyEmail)
+}
+
+// TestSendTestNotification checks that test notifications go to the default route or a project's route,
+// and that missing settings and delivery failures are reported
+func TestSendTestNotification(t *testing.T) {
+	defaultServer, defaultNotifications := newNotificationServer(t)
+	teamServer, teamNotifications := newNotificationServer(t)
+
+	cfg := newTestConfig(t)
+	projectService, _ := newTestProjectService(t, cfg)
+
+	assert.ErrorContains(t, projectService.SendTestNotification(uuid.Nil), "no notification webhook URL or SMTP host")
+
+	cfg.NotifyWebhookURL = defaultServer.URL
+	require.NoError(t, projectService.SendTestNotification(uuid.Nil))
+	assert.Equal(t, "Deployment of oar-test succeeded", (<-defaultNotifications).Text)
+
+	originDir := filepath.Join(cfg.DataDir, "origin")
+	initDeployableRepo(t, originDir)
+	p := domain.NewProject("billing", originDir, []string{"compose.yaml"}, nil)
+	p.NotifyWebhookURL = teamServer.URL
+	p.NotifyTemplate = "{{.ProjectName}}: {{.Notes}}"
+	created, err := projectService.Create(&p)
+	require.NoError(t, err)
+
+	require.NoError(t, projectService.SendTestNotification(created.ID))
+	assert.Equal(t, "billing: Test notification sent with oar notify test", (<-teamNotifications).Text)
+	assert.Empty(t, defaultNotifications)
+
+	teamServer.Close()
+	assert.ErrorContains(t, projectService.SendTestNotification(created.ID), "failed to send test notification")
+}
@@ -0,0 +1,39 @@
+package project
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// manualOperations remembers when each project was last stopped or had a service killed by a user,
+// so that the status changes they cause are not mistaken for crashes
+type manualOperations struct {
+	mu   sync.RWMutex
+	last map[uuid.UUID]time.Time
+}
+
+func newManualOperations() *manualOperations {
+	return &manualOperations{last: make(map[uuid.UUID]time.Time)}
+}
+
+// mark records an operation on the project at the current time. Operations mark both their start
+// and their end, so that changes seen while they run are covered too.
+func (o *manualOperations) mark(projectID uuid.UUID) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.last[projectID] = time.Now()
+}
+
+func (o *manualOperations) get(projectID uuid.UUID) time.Time {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.last[projectID]
+}
+
+// LastManualOperation returns when the project was last stopped or had a service killed through Oar,
+// or the zero time if it has not been since Oar started
+func (s *ProjectService) LastManualOperation(projectID uuid.UUID) time.Time {
+	return s.manualOperations.get(projectID)
+}
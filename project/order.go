@@ -0,0 +1,66 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// Orders in which projects can be listed
+const (
+	// ProjectOrderDashboard lists projects placed by hand first, in their order, then the others by name
+	ProjectOrderDashboard = "dashboard"
+	// ProjectOrderName lists projects by name
+	ProjectOrderName = "name"
+	// ProjectOrderUpdated lists the most recently updated projects first
+	ProjectOrderUpdated = "updated"
+)
+
+// ProjectOrders are the orders accepted by SortProjects
+var ProjectOrders = []string{ProjectOrderDashboard, ProjectOrderName, ProjectOrderUpdated}
+
+// Reorder places the given projects first on the dashboard, in the given order. The other projects
+// follow by name, so an empty list restores the order by name. The order is replaced in a single
+// transaction: when a project does not exist, nothing changes.
+func (s *ProjectService) Reorder(projectIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		if seen[id] {
+			return fmt.Errorf("project %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+
+	if err := s.projectRepository.Reorder(projectIDs); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "reorder_projects",
+			"error", err)
+		return err
+	}
+	return nil
+}
+
+// SortProjects sorts projects, as returned by List, in the given order
+func SortProjects(projects []*domain.Project, order string) error {
+	switch order {
+	case ProjectOrderDashboard:
+		// List already returns projects in dashboard order
+	case ProjectOrderName:
+		slices.SortStableFunc(projects, func(a, b *domain.Project) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	case ProjectOrderUpdated:
+		slices.SortStableFunc(projects, func(a, b *domain.Project) int {
+			return b.UpdatedAt.Compare(a.UpdatedAt)
+		})
+	default:
+		return fmt.Errorf("invalid order %q: use one of %s", order, strings.Join(ProjectOrders, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package project_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+func TestReorderProjects(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(name string) *domain.Project {
+		projectID := uuid.New()
+		created, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusStopped,
+		})
+		require.NoError(t, err)
+		return created
+	}
+	web := createProject("web")
+	api := createProject("api")
+	db := createProject("db")
+
+	listedNames := func(t *testing.T) []string {
+		projects, err := projectService.List()
+		require.NoError(t, err)
+		names := make([]string, len(projects))
+		for i, p := range projects {
+			names[i] = p.Name
+		}
+		return names
+	}
+
+	t.Run("lists by name by default", func(t *testing.T) {
+		assert.Equal(t, []string{"api", "db", "web"}, listedNames(t))
+	})
+
+	t.Run("lists projects placed by hand first", func(t *testing.T) {
+		require.NoError(t, projectService.Reorder([]uuid.UUID{web.ID, db.ID}))
+		assert.Equal(t, []string{"web", "db", "api"}, listedNames(t))
+	})
+
+	t.Run("keeps the order when a project is saved", func(t *testing.T) {
+		// Loaded before the projects were reordered again
+		stale, err := projectService.Get(web.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, projectService.Reorder([]uuid.UUID{api.ID, web.ID, db.ID}))
+		stale.Status = domain.ProjectStatusRunning
+		require.NoError(t, projectService.Update(stale))
+
+		assert.Equal(t, []string{"api", "web", "db"}, listedNames(t))
+	})
+
+	t.Run("rejects a project listed twice", func(t *testing.T) {
+		err := projectService.Reorder([]uuid.UUID{db.ID, db.ID})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "listed more than once")
+		assert.Equal(t, []string{"api", "web", "db"}, listedNames(t))
+	})
+
+	t.Run("changes nothing when a project does not exist", func(t *testing.T) {
+		require.Error(t, projectService.Reorder([]uuid.UUID{db.ID, uuid.New()}))
+		assert.Equal(t, []string{"api", "web", "db"}, listedNames(t))
+	})
+
+	t.Run("concurrent reorders apply one order as a whole", func(t *testing.T) {
+		orders := [][]uuid.UUID{
+			{db.ID, web.ID, api.ID},
+			{web.ID, api.ID, db.ID},
+		}
+		var wg sync.WaitGroup
+		for _, order := range orders {
+			wg.Go(func() {
+				assert.NoError(t, projectService.Reorder(order))
+			})
+		}
+		wg.Wait()
+
+		assert.Contains(t, [][]string{{"db", "web", "api"}, {"web", "api", "db"}}, listedNames(t))
+	})
+
+	t.Run("an empty order restores the order by name", func(t *testing.T) {
+		require.NoError(t, projectService.Reorder(nil))
+		assert.Equal(t, []string{"api", "db", "web"}, listedNames(t))
+	})
+}
+
+func TestSortProjects(t *testing.T) {
+	now := time.Now()
+	projects := func() []*domain.Project {
+		return []*domain.Project{
+			{Name: "web", UpdatedAt: now.Add(-time.Hour)},
+			{Name: "api", UpdatedAt: now.Add(-2 * time.Hour)},
+			{Name: "db", UpdatedAt: now},
+		}
+	}
+	names := func(projects []*domain.Project) []string {
+		result := make([]string, len(projects))
+		for i, p := range projects {
+			result[i] = p.Name
+		}
+		return result
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: project.ProjectOrderDashboard, want: []string{"web", "api", "db"}},
+		{order: project.ProjectOrderName, want: []string{"api", "db", "web"}},
+		{order: project.ProjectOrderUpdated, want: []string{"db", "web", "api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted := projects()
+			require.NoError(t, project.SortProjects(sorted, tt.order))
+			assert.Equal(t, tt.want, names(sorted))
+		})
+	}
+
+	t.Run("invalid order", func(t *testing.T) {
+		err := project.SortProjects(projects(), "size")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "dashboard, name, updated")
+	})
+}
@@ -0,0 +1,77 @@
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+)
+
+// TestDeploymentOutcomeRollback checks that the end of a deployment saves the deployment and the
+// project together: when the project cannot be saved, the deployment is not left completed or failed
+func TestDeploymentOutcomeRollback(t *testing.T) {
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+	projectRepo := repository.NewProjectRepository(database, nil)
+	deploymentRepo := repository.NewDeploymentRepository(database)
+	s := NewProjectService(projectRepo, deploymentRepo, nil, &config.Config{})
+
+	project, err := projectRepo.Create(&domain.Project{
+		ID:           uuid.New(),
+		Name:         "shop",
+		GitURL:       "https://example.com/shop.git",
+		GitBranch:    "main",
+		WorkingDir:   "/data/projects/shop",
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusDeploying,
+	})
+	require.NoError(t, err)
+	deployment := &domain.Deployment{
+		ID:         uuid.New(),
+		ProjectID:  project.ID,
+		CommitHash: "abc123",
+		Status:     domain.DeploymentStatusStarted,
+	}
+	require.NoError(t, deploymentRepo.Create(deployment))
+
+	// The database rejects every change of the project
+	require.NoError(t, database.Exec(`CREATE TRIGGER reject_project_update BEFORE UPDATE ON projects
+		BEGIN SELECT RAISE(ABORT, 'project update rejected'); END`).Error)
+	unsavable := func() *domain.Project {
+		p := *project
+		return &p
+	}
+	// assertUnchanged checks that neither record was saved
+	assertUnchanged := func(t *testing.T) {
+		t.Helper()
+		stored, err := deploymentRepo.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeploymentStatusStarted, stored.Status)
+		storedProject, err := projectRepo.FindByID(project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ProjectStatusDeploying, storedProject.Status)
+		assert.Nil(t, storedProject.LocalCommit)
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		err := s.completeDeployment(unsavable(), "def456", *deployment)
+		assert.ErrorContains(t, err, "failed to update project status")
+		assertUnchanged(t)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		failed := *deployment
+		err := s.handleDeploymentError(unsavable(), &failed, errors.New("compose up failed"))
+		assert.ErrorContains(t, err, "compose up failed")
+		assertUnchanged(t)
+	})
+}
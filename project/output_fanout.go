@@ -0,0 +1,229 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+)
+
+// subscriberBufferSize is how many messages a deployment subscriber may fall behind before
+// messages are dropped for it
+const subscriberBufferSize = 256
+
+// recentOutputSize is how many of the latest messages of a deployment are kept for subscribers
+// that join while it runs. With the notice about older messages, the replay must fit into a
+// subscriber's buffer.
+const recentOutputSize = 200
+
+// ErrNoDeploymentInProgress is returned when subscribing to a project that is not being deployed
+var ErrNoDeploymentInProgress = errors.New("no deployment in progress")
+
+// outputFanout delivers the output of a running deployment to subscribers other than the one that
+// started it, e.g. a second browser tab watching the deployment. Delivery never blocks: a
+// subscriber that does not keep up loses messages and is told how many it missed. The latest
+// messages are kept in memory, so that a subscriber joining late starts with recent context.
+type outputFanout struct {
+	mu          sync.Mutex
+	subscribers map[*outputSubscriber]struct{}
+	recent      *outputRing
+	closed      bool
+}
+
+type outputSubscriber struct {
+	ch      chan docker.StreamMessage
+	dropped int
+}
+
+func newOutputFanout() *outputFanout {
+	return &outputFanout{
+		subscribers: make(map[*outputSubscriber]struct{}),
+		recent:      newOutputRing(recentOutputSize),
+	}
+}
+
+// subscribe registers a subscriber, whose channel starts with the recent messages. The channel is
+// closed when the deployment finishes or when the returned function is called, whichever happens
+// first. The function may be called more than once.
+func (f *outputFanout) subscribe() (<-chan docker.StreamMessage, func()) {
+	sub := &outputSubscriber{ch: make(chan docker.StreamMessage, subscriberBufferSize)}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	// Replayed under the lock, so that no message is missed or repeated between the replay and
+	// the live output
+	if omitted := f.recent.omitted(); omitted > 0 {
+		sub.ch <- docker.StreamMessage{
+			Type:    "info",
+			Content: fmt.Sprintf("(%d earlier messages not shown)", omitted),
+		}
+	}
+	for _, msg := range f.recent.messages() {
+		sub.ch <- msg
+	}
+	f.subscribers[sub] = struct{}{}
+
+	return sub.ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subscribers[sub]; ok {
+			delete(f.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// publish delivers msg to every subscriber that has room for it
+func (f *outputFanout) publish(msg docker.StreamMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recent.add(msg)
+	for sub := range f.subscribers {
+		sub.send(msg)
+	}
+}
+
+// close ends the deployment's output, closing all subscriber channels and releasing the recent
+// messages
+func (f *outputFanout) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.recent = newOutputRing(0)
+	for sub := range f.subscribers {
+		delete(f.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// send delivers msg without blocking. After messages were dropped, a notice goes out first, so
+// it takes two free slots to resume delivery.
+func (s *outputSubscriber) send(msg docker.StreamMessage) {
+	if s.dropped > 0 {
+		if cap(s.ch)-len(s.ch) < 2 {
+			s.dropped++
+			return
+		}
+		s.ch <- docker.StreamMessage{
+			Type:    "info",
+			Content: fmt.Sprintf("(%d messages skipped, the connection was too slow)", s.dropped),
+		}
+		s.dropped = 0
+	}
+
+	select {
+	case s.ch <- msg:
+	default:
+		s.dropped++
+	}
+}
+
+// outputRing keeps the latest messages up to its size, dropping the oldest. It is not safe for
+// concurrent use; outputFanout guards it with its mutex.
+type outputRing struct {
+	buf   []docker.StreamMessage
+	start int // Index of the oldest message once the ring is full
+	total int // Messages added, including those dropped
+}
+
+func newOutputRing(size int) *outputRing {
+	return &outputRing{buf: make([]docker.StreamMessage, 0, size)}
+}
+
+func (r *outputRing) add(msg docker.StreamMessage) {
+	r.total++
+	if cap(r.buf) == 0 {
+		return
+	}
+	if len(r.buf) < cap(r.buf) {
+		r.buf = append(r.buf, msg)
+		return
+	}
+	r.buf[r.start] = msg
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// messages returns the kept messages, oldest first
+func (r *outputRing) messages() []docker.StreamMessage {
+	messages := make([]docker.StreamMessage, 0, len(r.buf))
+	messages = append(messages, r.buf[r.start:]...)
+	return append(messages, r.buf[:r.start]...)
+}
+
+// omitted returns how many messages were dropped to stay within the size
+func (r *outputRing) omitted() int {
+	return r.total - len(r.buf)
+}
+
+// deploymentOutputs tracks the output fan-out of the deployment running for each project
+type deploymentOutputs struct {
+	mu      sync.Mutex
+	fanouts map[uuid.UUID]*outputFanout
+}
+
+func newDeploymentOutputs() *deploymentOutputs {
+	return &deploymentOutputs{fanouts: make(map[uuid.UUID]*outputFanout)}
+}
+
+// start forwards everything sent to the returned channel to outputChan, without dropping, and to
+// the project's subscribers. Calling the returned function drains the channel and ends the fan-out.
+func (o *deploymentOutputs) start(
+	projectID uuid.UUID,
+	outputChan chan<- docker.StreamMessage,
+) (chan<- docker.StreamMessage, func()) {
+	fanout := newOutputFanout()
+	o.mu.Lock()
+	o.fanouts[projectID] = fanout
+	o.mu.Unlock()
+
+	forwarded := make(chan docker.StreamMessage, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range forwarded {
+			outputChan <- msg
+			fanout.publish(msg)
+		}
+	}()
+
+	return forwarded, func() {
+		close(forwarded)
+		<-done
+
+		o.mu.Lock()
+		if o.fanouts[projectID] == fanout {
+			delete(o.fanouts, projectID)
+		}
+		o.mu.Unlock()
+		fanout.close()
+	}
+}
+
+// inProgress reports whether a deployment of the project is running. A deployment counts from when
+// it holds the project lock until it ends, including while it waits for a deployment slot.
+func (o *deploymentOutputs) inProgress(projectID uuid.UUID) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.fanouts[projectID]
+	return ok
+}
+
+// subscribe subscribes to the output of the deployment running for the project
+func (o *deploymentOutputs) subscribe(projectID uuid.UUID) (<-chan docker.StreamMessage, func(), error) {
+	o.mu.Lock()
+	fanout, ok := o.fanouts[projectID]
+	o.mu.Unlock()
+	if !ok {
+		return nil, nil, ErrNoDeploymentInProgress
+	}
+
+	ch, unsubscribe := fanout.subscribe()
+	return ch, unsubscribe, nil
+}
@@ -0,0 +1,214 @@
+package project
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+func message(i int) docker.StreamMessage {
+	return docker.StreamMessage{Type: "stdout", Content: fmt.Sprintf("line %d", i)}
+}
+
+func TestDeploymentOutputsDeliverToAll(t *testing.T) {
+	outputs := newDeploymentOutputs()
+	projectID := uuid.New()
+
+	_, _, err := outputs.subscribe(projectID)
+	require.ErrorIs(t, err, ErrNoDeploymentInProgress)
+
+	primary := make(chan docker.StreamMessage, 10)
+	forwarded, end := outputs.start(projectID, primary)
+
+	first, _, err := outputs.subscribe(projectID)
+	require.NoError(t, err)
+	second, _, err := outputs.subscribe(projectID)
+	require.NoError(t, err)
+
+	for i := range 3 {
+		forwarded <- message(i)
+	}
+	end()
+
+	// The starter of the deployment gets everything, without a close: the caller owns the channel
+	require.Len(t, primary, 3)
+	for i := range 3 {
+		assert.Equal(t, message(i), <-primary)
+	}
+
+	// Subscribers get everything, and their channels are closed when the deployment ends
+	for _, sub := range []<-chan docker.StreamMessage{first, second} {
+		var received []docker.StreamMessage
+		for msg := range sub {
+			received = append(received, msg)
+		}
+		assert.Equal(t, []docker.StreamMessage{message(0), message(1), message(2)}, received)
+	}
+
+	_, _, err = outputs.subscribe(projectID)
+	require.ErrorIs(t, err, ErrNoDeploymentInProgress, "Subscribing after the deployment must fail")
+}
+
+func TestOutputFanoutSlowSubscriber(t *testing.T) {
+	fanout := newOutputFanout()
+	slow, _ := fanout.subscribe()
+	fast, unsubscribeFast := fanout.subscribe()
+	defer unsubscribeFast()
+
+	// Publishing must not block on the slow subscriber, which never reads while the output is produced
+	total := subscriberBufferSize + 50
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range total {
+			fanout.publish(message(i))
+			<-fast
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish blocked on a slow subscriber")
+	}
+
+	// The slow subscriber got the buffered messages, then learns how many it missed
+	for i := range subscriberBufferSize {
+		assert.Equal(t, message(i), <-slow)
+	}
+	fanout.publish(message(total))
+	notice := <-slow
+	assert.Equal(t, "info", notice.Type)
+	assert.Contains(t, notice.Content, "50 messages skipped")
+	assert.Equal(t, message(total), <-slow)
+
+	fanout.close()
+	_, open := <-slow
+	assert.False(t, open, "Closing the fan-out must close subscriber channels")
+}
+
+func TestOutputFanoutReplaysRecentOutput(t *testing.T) {
+	fanout := newOutputFanout()
+	for i := range 3 {
+		fanout.publish(message(i))
+	}
+
+	// A late subscriber starts with what was published before, then gets the live output
+	late, unsubscribe := fanout.subscribe()
+	defer unsubscribe()
+	fanout.publish(message(3))
+	for i := range 4 {
+		assert.Equal(t, message(i), <-late)
+	}
+
+	// Only the latest messages are kept, and a subscriber is told about the older ones
+	total := recentOutputSize + 30
+	for i := 4; i < total; i++ {
+		fanout.publish(message(i))
+	}
+	later, unsubscribeLater := fanout.subscribe()
+	defer unsubscribeLater()
+	require.Len(t, later, recentOutputSize+1)
+	notice := <-later
+	assert.Equal(t, "info", notice.Type)
+	assert.Equal(t, "(30 earlier messages not shown)", notice.Content)
+	for i := total - recentOutputSize; i < total; i++ {
+		assert.Equal(t, message(i), <-later)
+	}
+
+	// The recent output is released when the deployment ends
+	fanout.close()
+	afterEnd, _ := fanout.subscribe()
+	_, open := <-afterEnd
+	assert.False(t, open)
+	assert.Empty(t, fanout.recent.messages())
+}
+
+func TestOutputFanoutConcurrentSubscribers(t *testing.T) {
+	fanout := newOutputFanout()
+	total := recentOutputSize / 2
+
+	// Subscribers joining while output is published see each message once, in order, without gaps.
+	// Fewer messages are published than are kept, so every replay starts with the first one.
+	results := make(chan []docker.StreamMessage)
+	for range 10 {
+		go func() {
+			sub, _ := fanout.subscribe()
+			var received []docker.StreamMessage
+			for msg := range sub {
+				received = append(received, msg)
+			}
+			results <- received
+		}()
+	}
+	for i := range total {
+		fanout.publish(message(i))
+	}
+	fanout.close()
+
+	for range 10 {
+		received := <-results
+		if len(received) == 0 {
+			continue // Subscribed after the deployment ended
+		}
+		require.Len(t, received, total)
+		for i, msg := range received {
+			assert.Equal(t, message(i), msg)
+		}
+	}
+}
+
+func TestOutputFanoutUnsubscribe(t *testing.T) {
+	fanout := newOutputFanout()
+	sub, unsubscribe := fanout.subscribe()
+
+	unsubscribe()
+	unsubscribe() // Safe to call again
+	_, open := <-sub
+	assert.False(t, open, "Unsubscribing must close the channel")
+
+	// Publishing and closing after unsubscribing must not touch the closed channel
+	fanout.publish(message(0))
+	fanout.close()
+	unsubscribe()
+
+	// Subscribing after the deployment ended gives a closed channel
+	late, unsubscribeLate := fanout.subscribe()
+	_, open = <-late
+	assert.False(t, open)
+	unsubscribeLate()
+}
+
+func TestDeploymentOutputsNoGoroutineLeak(t *testing.T) {
+	outputs := newDeploymentOutputs()
+	before := runtime.NumGoroutine()
+
+	for range 50 {
+		projectID := uuid.New()
+		primary := make(chan docker.StreamMessage, 100)
+		forwarded, end := outputs.start(projectID, primary)
+
+		_, unsubscribe, err := outputs.subscribe(projectID)
+		require.NoError(t, err)
+		_, _, err = outputs.subscribe(projectID) // Left subscribed until the end
+		require.NoError(t, err)
+
+		forwarded <- message(0)
+		unsubscribe()
+		end()
+	}
+
+	// Polled here rather than with assert.Eventually, which runs the condition in a goroutine of its own
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "forwarding goroutines must exit when the deployment ends")
+	assert.Empty(t, outputs.fanouts)
+}
@@ -0,0 +1,68 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/domain"
+)
+
+// Pause freezes the containers of a project with docker compose pause, e.g. to inspect it in a given
+// state. It is lighter than Stop: the containers are kept as they are, and Unpause resumes them. The
+// watcher leaves a paused project alone, it neither counts it as crashed nor deploys it.
+func (s *ProjectService) Pause(projectID uuid.UUID) error {
+	return s.setPaused(projectID, true)
+}
+
+// Unpause resumes the containers of a project frozen by Pause
+func (s *ProjectService) Unpause(projectID uuid.UUID) error {
+	return s.setPaused(projectID, false)
+}
+
+func (s *ProjectService) setPaused(projectID uuid.UUID, paused bool) error {
+	unlock := s.locks.lock(projectID, nil)
+	defer unlock()
+
+	s.manualOperations.mark(projectID)
+	defer s.manualOperations.mark(projectID)
+
+	project, err := s.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	switch {
+	case paused && project.Status == domain.ProjectStatusPaused:
+		return fmt.Errorf("project %s is already paused", project.Name)
+	case paused && project.Status == domain.ProjectStatusStopped:
+		return fmt.Errorf("project %s is stopped, there are no containers to pause", project.Name)
+	case !paused && project.Status != domain.ProjectStatusPaused:
+		return fmt.Errorf("project %s is not paused", project.Name)
+	}
+
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	operation, status, message := "pause_project", domain.ProjectStatusPaused, "Project paused"
+	run := composeProject.Pause
+	if !paused {
+		operation, status, message = "unpause_project", domain.ProjectStatusRunning, "Project resumed"
+		run = composeProject.Unpause
+	}
+	if err := run(); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", operation,
+			"project_id", project.ID,
+			"error", err)
+		return err
+	}
+
+	s.setStatus(project, status)
+	slog.Info(message,
+		"project_id", project.ID,
+		"project_name", project.Name)
+	return nil
+}
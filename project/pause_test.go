@@ -0,0 +1,84 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestPauseAndUnpause freezes and resumes a project with a stub docker whose containers follow pause
+// and unpause
+func TestPauseAndUnpause(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	require.NoError(t, os.WriteFile(stateFile, []byte("running"), 0o644))
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	pause)
+		printf paused > `+stateFile+`
+		exit 0
+		;;
+	unpause)
+		printf running > `+stateFile+`
+		exit 0
+		;;
+	ps)
+		printf '{"Service":"web","Name":"web-1","State":"%s","RunningFor":"5 minutes ago"}\n' "$(cat `+
+		stateFile+`)"
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-web")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:           projectID,
+		Name:         "web",
+		GitURL:       "https://example.com/repo.git",
+		GitBranch:    "main",
+		WorkingDir:   workingDir,
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusRunning,
+	})
+	require.NoError(t, err)
+
+	// checkStatus checks the stored status and the one Docker reports
+	checkStatus := func(t *testing.T, stored domain.ProjectStatus, live docker.ComposeProjectStatus) {
+		t.Helper()
+		p, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, stored, p.Status)
+		status, err := projectService.GetStatus(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, live, status.Status)
+		assert.Equal(t, stored, status.Status.ProjectStatus())
+	}
+
+	require.NoError(t, projectService.Pause(projectID))
+	checkStatus(t, domain.ProjectStatusPaused, docker.ComposeProjectStatusPaused)
+	assert.ErrorContains(t, projectService.Pause(projectID), "already paused")
+
+	require.NoError(t, projectService.Unpause(projectID))
+	checkStatus(t, domain.ProjectStatusRunning, docker.ComposeProjectStatusRunning)
+	assert.ErrorContains(t, projectService.Unpause(projectID), "not paused")
+
+	t.Run("stopped project cannot be paused", func(t *testing.T) {
+		p, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		p.Status = domain.ProjectStatusStopped
+		require.NoError(t, projectService.Update(p))
+
+		assert.ErrorContains(t, projectService.Pause(projectID), "no containers to pause")
+	})
+}
@@ -2,11 +2,15 @@
 package project
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
@@ -14,6 +18,8 @@ import (
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 	"github.com/oar-cd/oar/git"
+	"github.com/oar-cd/oar/metrics"
+	"github.com/oar-cd/oar/notify"
 	"github.com/oar-cd/oar/repository"
 )
 
@@ -23,11 +29,27 @@ type ProjectService struct {
 	deploymentRepository repository.DeploymentRepository
 	gitService           *git.GitService
 	config               *config.Config
+	statusCache          *statusCache
+	locks                *projectLocks
+	deploySlots          *deploySlots
+	deploymentOutputs    *deploymentOutputs
+	versions             *versionCache
+	diskUsage            *diskUsageCache
+	notifier             *notify.Notifier
+	manualOperations     *manualOperations
+	metrics              *metrics.Registry
 }
 
 // Ensure ProjectService implements ProjectManager
 var _ ProjectManager = (*ProjectService)(nil)
 
+const (
+	// statusConcurrency limits concurrent Docker Compose status calls in GetStatusMany
+	statusConcurrency = 4
+	// statusManyTimeout bounds the total time GetStatusMany may take
+	statusManyTimeout = 15 * time.Second
+)
+
 // List returns all projects
 func (s *ProjectService) List() ([]*domain.Project, error) {
 	projects, err := s.projectRepository.List()
@@ -57,6 +79,31 @@ func (s *ProjectService) Get(id uuid.UUID) (*domain.Project, error) {
 
 // Create creates a new project
 func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error) {
+	return s.create(project, nil)
+}
+
+// CreateStreaming is like Create, but reports each step and the progress of the clone to outputChan
+func (s *ProjectService) CreateStreaming(
+	project *domain.Project,
+	outputChan chan<- docker.StreamMessage,
+) (*domain.Project, error) {
+	return s.create(project, outputChan)
+}
+
+// create creates a new project, reporting each step to outputChan unless it is nil
+func (s *ProjectService) create(
+	project *domain.Project,
+	outputChan chan<- docker.StreamMessage,
+) (*domain.Project, error) {
+	report := func(msg docker.StreamMessage) {
+		if outputChan != nil {
+			outputChan <- msg
+		}
+	}
+	info := func(format string, args ...any) {
+		report(docker.StreamMessage{Type: "info", Content: fmt.Sprintf(format, args...)})
+	}
+
 	// Validate required fields
 	if strings.TrimSpace(project.Name) == "" {
 		return nil, fmt.Errorf("name is required")
@@ -64,14 +111,27 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 	if strings.TrimSpace(project.GitURL) == "" {
 		return nil, fmt.Errorf("git URL is required")
 	}
-	if len(project.ComposeFiles) == 0 {
-		return nil, fmt.Errorf("compose files are required")
+	if err := normalizeWatchURL(project); err != nil {
+		return nil, err
 	}
-
-	// Create directory name: <project_id>-<normalized_project_name>
-	normalizedName := slug.Make(project.Name)
-	dirName := fmt.Sprintf("%s-%s", project.ID.String(), normalizedName)
-	project.WorkingDir = filepath.Join(s.config.WorkspaceDir, dirName)
+	if err := s.validateNewProject(project); err != nil {
+		return nil, err
+	}
+	if base := baseComposeName(project.Name); project.ComposeName != base {
+		report(docker.StreamMessage{
+			Type: "stderr",
+			Content: fmt.Sprintf("Warning: compose project name %s is used by another project, using %s",
+				base, project.ComposeName),
+		})
+	} else if project.ComposeName != project.Name {
+		info("Using compose project name %s", project.ComposeName)
+	}
+	// Variables referenced by the URL and branch must be set before anything is cloned
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return nil, err
+	}
+	project.WorkingDir = s.workingDir(project)
 
 	gitDir, err := project.GitDir()
 	if err != nil {
@@ -86,8 +146,10 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 
 	// Detect default branch if none specified
 	if project.GitBranch == "" {
-		defaultBranch, err := s.gitService.GetDefaultBranch(project.GitURL, project.GitAuth)
+		info("Detecting the default branch of %s...", project.GitURL)
+		defaultBranch, err := s.gitService.GetDefaultBranch(source.URL, project.GitAuth)
 		if err != nil {
+			err = source.RedactError(err)
 			slog.Error("Service operation failed",
 				"layer", "service",
 				"operation", "create_project_get_default_branch",
@@ -98,6 +160,9 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 			return nil, fmt.Errorf("failed to determine default branch: %w", err)
 		}
 		project.GitBranch = defaultBranch
+		project.GitBranchDetected = true
+		source.Branch = defaultBranch
+		info("Using default branch %s", defaultBranch)
 		slog.Info(
 			"Using detected default branch",
 			"project_id",
@@ -107,10 +172,60 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 			"default_branch",
 			defaultBranch,
 		)
+	} else {
+		info("Checking that branch %s exists...", project.GitBranch)
+		if err := s.checkBranchExists(project); err != nil {
+			// Fails here with a clear message rather than deep in the clone
+			slog.Error("Service operation failed",
+				"layer", "service",
+				"operation", "create_project_check_branch",
+				"project_id", project.ID,
+				"project_name", project.Name,
+				"git_url", project.GitURL,
+				"git_branch", project.GitBranch,
+				"error", err)
+			return nil, err
+		}
+	}
+	if project.WatchURL != "" {
+		info("Checking that branch %s exists on the watch URL...", project.GitBranch)
+		if err := s.checkWatchURL(project); err != nil {
+			slog.Error("Service operation failed",
+				"layer", "service",
+				"operation", "create_project_check_watch_url",
+				"project_id", project.ID,
+				"project_name", project.Name,
+				"git_branch", project.GitBranch,
+				"error", err)
+			return nil, err
+		}
+	}
+
+	// Save working directory for cleanup, the clone may leave files behind even when it fails. The
+	// directory must be new, so that cleaning up never removes files of another project.
+	workingDir := project.WorkingDir
+	if _, err := os.Stat(workingDir); err == nil {
+		return nil, fmt.Errorf("project directory %s already exists", workingDir)
+	}
+	cleanup := func() {
+		if cleanupErr := os.RemoveAll(workingDir); cleanupErr != nil {
+			slog.Error(
+				"Failed to remove project directory after creation failure",
+				"working_dir",
+				workingDir,
+				"error",
+				cleanupErr,
+			)
+			return
+		}
+		info("Removed the project directory")
 	}
 
 	// Clone repository
-	if err := s.gitService.Clone(project.GitURL, project.GitBranch, project.GitAuth, gitDir); err != nil {
+	info("Cloning %s (branch %s)...", project.GitURL, source.Branch)
+	cloneOutput, err := s.gitService.Clone(source.URL, source.Branch, project.GitAuth, gitDir, outputChan)
+	if err != nil {
+		err = source.RedactError(err)
 		slog.Error("Service operation failed",
 			"layer", "service",
 			"operation", "create_project",
@@ -118,31 +233,42 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 			"project_name", project.Name,
 			"git_url", project.GitURL,
 			"error", err)
+		cleanup()
 		return nil, err
 	}
+	slog.Debug("Repository cloned", "project_id", project.ID, "output", cloneOutput)
 
 	// Get commit info
 	commit, _ := s.gitService.GetLatestCommit(gitDir)
 	project.LocalCommit = &commit
+	if commit != "" {
+		info("Cloned commit %s", commit[:min(8, len(commit))])
+	}
+
+	if len(project.ComposeFiles) == 0 {
+		if err := discoverProjectComposeFiles(project, gitDir); err != nil {
+			cleanup()
+			return nil, err
+		}
+		info("Using compose files %s", strings.Join(project.ComposeFiles, ", "))
+	}
+
+	// Missing files are only a warning, they may be added to the repository before the first deployment
+	info("Validating compose files...")
+	for _, file := range missingComposeFiles(gitDir, project.ComposeFiles) {
+		report(docker.StreamMessage{
+			Type:    "stderr",
+			Content: fmt.Sprintf("Warning: compose file %s is not in the repository", file),
+		})
+	}
 
 	// Set initial status to stopped
 	project.Status = domain.ProjectStatusStopped
 
-	// Save working directory for cleanup before repository call
-	workingDir := project.WorkingDir
-
+	info("Saving project...")
 	createdProject, err := s.projectRepository.Create(project)
 	if err != nil {
-		// Cleanup on failure using saved working directory
-		if cleanupErr := os.RemoveAll(workingDir); cleanupErr != nil {
-			slog.Error(
-				"Failed to remove project directory after creation failure",
-				"working_dir",
-				workingDir,
-				"error",
-				cleanupErr,
-			)
-		}
+		cleanup()
 		slog.Error("Service operation failed",
 			"layer", "service",
 			"operation", "create_project",
@@ -153,40 +279,416 @@ func (s *ProjectService) Create(project *domain.Project) (*domain.Project, error
 		return nil, err // Pass through as-is
 	}
 
+	report(docker.StreamMessage{
+		Type:    "success",
+		Content: fmt.Sprintf("Project %s created with ID %s", createdProject.Name, createdProject.ID),
+	})
 	return createdProject, nil
 }
 
+// validateNewProject validates and normalizes the settings of a project being created, other than
+// where its files come from
+func (s *ProjectService) validateNewProject(project *domain.Project) error {
+	if len(project.ComposeFiles) == 0 {
+		project.ComposeFiles = slices.Clone(s.config.ComposeDefaultFiles)
+	}
+	// Without compose files, they are discovered once the repository is there
+	if len(project.ComposeFiles) > 0 {
+		if err := docker.ValidateComposeFiles(project.ComposeFiles); err != nil {
+			return err
+		}
+		if err := normalizeDisabledComposeFiles(project); err != nil {
+			return err
+		}
+	}
+	if err := docker.ValidateEnvFiles(project.EnvFiles); err != nil {
+		return err
+	}
+	if err := docker.ValidateVariables(project.Variables); err != nil {
+		return err
+	}
+	if err := s.validateDependencies(project); err != nil {
+		return err
+	}
+	if err := normalizeExternalNetworks(project); err != nil {
+		return err
+	}
+	if err := normalizeRegistryMirror(project); err != nil {
+		return err
+	}
+	if err := normalizeNotifications(project); err != nil {
+		return err
+	}
+	if err := normalizeBuildArgs(project); err != nil {
+		return err
+	}
+	if err := normalizeContainerLabels(project); err != nil {
+		return err
+	}
+	if err := normalizeDeployHooks(project); err != nil {
+		return err
+	}
+	if err := normalizeVolumeInitPhase(project); err != nil {
+		return err
+	}
+	return s.resolveComposeName(project)
+}
+
+// workingDir returns the directory of a new project: <project_id>-<normalized_project_name>
+func (s *ProjectService) workingDir(project *domain.Project) string {
+	dirName := fmt.Sprintf("%s-%s", project.ID.String(), slug.Make(project.Name))
+	return filepath.Join(s.config.WorkspaceDir, dirName)
+}
+
+// normalizeDisabledComposeFiles drops disabled compose files that are no longer listed, as when a file
+// is removed from the list, and rejects projects with every compose file disabled
+func normalizeDisabledComposeFiles(project *domain.Project) error {
+	var disabled []string
+	for _, file := range project.DisabledComposeFiles {
+		if slices.Contains(project.ComposeFiles, file) && !slices.Contains(disabled, file) {
+			disabled = append(disabled, file)
+		}
+	}
+	project.DisabledComposeFiles = disabled
+	if len(project.EnabledComposeFiles()) == 0 {
+		return fmt.Errorf("at least one compose file must be enabled")
+	}
+	return nil
+}
+
+// discoverProjectComposeFiles sets the compose files of a new project created without any, and without
+// configured defaults, to those Docker Compose would use in the repository
+func discoverProjectComposeFiles(project *domain.Project, gitDir string) error {
+	project.ComposeFiles = discoverComposeFiles(gitDir)
+	if len(project.ComposeFiles) == 0 {
+		return fmt.Errorf("compose files are required: none were given and none of %s was found",
+			strings.Join(defaultComposeFiles, ", "))
+	}
+	return nil
+}
+
+// missingComposeFiles returns the compose files that do not exist in the repository
+func missingComposeFiles(gitDir string, composeFiles []string) []string {
+	var missing []string
+	for _, file := range composeFiles {
+		if _, err := os.Stat(filepath.Join(gitDir, file)); errors.Is(err, os.ErrNotExist) {
+			missing = append(missing, file)
+		}
+	}
+	return missing
+}
+
+// failOnMissingComposeFiles fails the deployment when some of the project's compose files do not
+// exist in its directory at the deployment's commit, or include or extend files or services that do
+// not exist
+func (s *ProjectService) failOnMissingComposeFiles(
+	project *domain.Project,
+	deployment *domain.Deployment,
+	stdout string,
+	sendMessage func(msg, msgType string),
+) error {
+	err := checkComposeFiles(project, deployment.CommitHash)
+	if err == nil {
+		return nil
+	}
+	sendMessage(err.Error(), "error")
+	s.failDeployment(deployment, err, stdout, "")
+	return err
+}
+
+// failDeployment records a deployment that failed before its containers were touched, with the
+// output captured so far and the error. The project keeps the status it had before the deployment.
+func (s *ProjectService) failDeployment(deployment *domain.Deployment, err error, stdout, stderr string) {
+	deployment.Status = domain.DeploymentStatusFailed
+	deployment.Stdout = stdout
+	deployment.Stderr = stderr + s.storedOutput(fmt.Sprintf("ERROR: %v", err))
+	if updateErr := s.deploymentRepository.Update(deployment); updateErr != nil {
+		slog.Error("Failed to update deployment status", "error", updateErr)
+	}
+}
+
+// MissingComposeFilesError is returned when a deployment fails because some of the project's compose
+// files do not exist, for example after a pull removed them from the repository
+type MissingComposeFilesError struct {
+	Files  []string
+	Commit string // Empty for projects without a repository
+}
+
+func (e *MissingComposeFilesError) Error() string {
+	where := "the project directory"
+	if e.Commit != "" {
+		where = "the repository at commit " + e.Commit[:min(8, len(e.Commit))]
+	}
+	if len(e.Files) == 1 {
+		return fmt.Sprintf("compose file %s no longer exists in %s: restore it or change the project's compose files",
+			e.Files[0], where)
+	}
+	return fmt.Sprintf("compose files %s no longer exist in %s: restore them or change the project's compose files",
+		strings.Join(e.Files, ", "), where)
+}
+
+// checkComposeFiles returns a MissingComposeFilesError naming the project's compose files that do not
+// exist in its directory at commitHash, or a docker.BrokenReferenceError for an include or extends
+// reference of them that cannot be resolved. It returns nil when all of them exist and resolve.
+func checkComposeFiles(project *domain.Project, commitHash string) error {
+	gitDir, err := project.GitDir()
+	if err != nil {
+		return fmt.Errorf("failed to get git directory: %w", err)
+	}
+	missing := missingComposeFiles(gitDir, project.EnabledComposeFiles())
+	if len(missing) == 0 {
+		if err := docker.CheckReferences(gitDir, project.EnabledComposeFiles()); err != nil {
+			return fmt.Errorf("broken compose file reference: %w", err)
+		}
+		return nil
+	}
+	missingErr := &MissingComposeFilesError{Files: missing}
+	if project.UsesGit() {
+		missingErr.Commit = commitHash
+	}
+	return missingErr
+}
+
 func (s *ProjectService) Update(project *domain.Project) error {
 	// Validate required fields
 	if strings.TrimSpace(project.Name) == "" {
 		return fmt.Errorf("name is required")
 	}
-	if strings.TrimSpace(project.GitURL) == "" {
+	if project.UsesGit() && strings.TrimSpace(project.GitURL) == "" {
 		return fmt.Errorf("git URL is required")
 	}
+	if !project.UsesGit() {
+		// There is no repository to watch or pull from
+		project.AutoDeployEnabled = false
+		project.AutoDeploySkipGitPull = false
+	} else if _, err := project.ResolveGitSource(); err != nil {
+		return err
+	}
+	if err := normalizeWatchURL(project); err != nil {
+		return err
+	}
 	if len(project.ComposeFiles) == 0 {
 		return fmt.Errorf("compose files are required")
 	}
+	if err := docker.ValidateComposeFiles(project.ComposeFiles); err != nil {
+		return err
+	}
+	if err := normalizeDisabledComposeFiles(project); err != nil {
+		return err
+	}
+	if err := docker.ValidateEnvFiles(project.EnvFiles); err != nil {
+		return err
+	}
+	if err := docker.ValidateVariables(project.Variables); err != nil {
+		return err
+	}
+	if err := normalizeExternalNetworks(project); err != nil {
+		return err
+	}
+	if err := normalizeRegistryMirror(project); err != nil {
+		return err
+	}
+	if err := normalizeNotifications(project); err != nil {
+		return err
+	}
+	if err := normalizeBuildArgs(project); err != nil {
+		return err
+	}
+	if err := normalizeContainerLabels(project); err != nil {
+		return err
+	}
+	if err := normalizeDeployHooks(project); err != nil {
+		return err
+	}
+	if err := normalizeVolumeInitPhase(project); err != nil {
+		return err
+	}
+
+	// Only changed dependencies are validated, so a removed prerequisite does not block other updates
+	stored, err := s.projectRepository.FindByID(project.ID)
+	if err != nil {
+		return err
+	}
+	// The status of a project being deployed belongs to the deployment. A copy loaded before the
+	// deployment ended must not bring back, or overwrite, the deploying status.
+	if project.Status == domain.ProjectStatusDeploying || s.IsDeploying(project.ID) {
+		project.Status = stored.Status
+	}
+	if !slices.Equal(stored.DependsOn, project.DependsOn) {
+		if err := s.validateDependencies(project); err != nil {
+			return err
+		}
+	}
+	if project.UsesGit() && project.GitBranch != stored.GitBranch && project.GitBranch != "" {
+		if err := s.checkBranchExists(project); err != nil {
+			return err
+		}
+	}
+	// A new watch URL is checked along with the git URL, the project is deployed from the latter
+	watchChanged := project.WatchURL != stored.WatchURL || project.GitURL != stored.GitURL ||
+		project.GitBranch != stored.GitBranch
+	if project.WatchURL != "" && watchChanged {
+		if project.GitBranch == stored.GitBranch {
+			if err := s.checkBranchExists(project); err != nil {
+				return err
+			}
+		}
+		if err := s.checkWatchURL(project); err != nil {
+			return err
+		}
+	}
+	// The compose project name follows renames and is resolved by the service only
+	project.ComposeName = stored.ComposeName
+	if project.Name != stored.Name {
+		if err := s.resolveComposeName(project); err != nil {
+			return err
+		}
+		if project.ComposeName != stored.ComposeProjectName() {
+			recordRename(project, stored.ComposeProjectName())
+		}
+	}
 	return s.projectRepository.Update(project)
 }
 
+// checkBranchExists returns an error when the project's branch does not exist on its remote
+func (s *ProjectService) checkBranchExists(project *domain.Project) error {
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return err
+	}
+	exists, err := s.gitService.BranchExists(source.URL, source.Branch, project.GitAuth)
+	if err != nil {
+		return fmt.Errorf("failed to check branch '%s': %w", source.Branch, source.RedactError(err))
+	}
+	if !exists {
+		return fmt.Errorf("branch '%s' not found on remote", source.Branch)
+	}
+	return nil
+}
+
+// DeployStreaming deploys the project, streaming progress to outputChan. A non-empty services list
+// limits the deployment to those services; Compose also starts the services they depend on.
+// The annotation is stored with the deployment record.
 func (s *ProjectService) DeployStreaming(
 	projectID uuid.UUID,
 	pull bool,
+	services []string,
+	annotation domain.DeploymentAnnotation,
 	outputChan chan<- docker.StreamMessage,
 ) error {
-	project, commitHash, deployment, composeProject, err := s.prepareDeployment(projectID, pull)
+	return s.deploy(projectID, deployOptions{pull: pull, services: services, annotation: annotation}, outputChan)
+}
+
+// deployOptions controls a deployment
+type deployOptions struct {
+	pull        bool
+	services    []string
+	noDeps      bool // Leave the services that services depend on untouched
+	annotation  domain.DeploymentAnnotation
+	planChanges bool // Limit the deployment to what the pulled configuration change needs
+	pullImages  bool // Pull the images of the deployed services before bringing them up
+	// removePreviousStacks removes the containers left under the project's previous names
+	removePreviousStacks bool
+	// refreshDigests resolves the digests of pinned images again, see pinImageDigests
+	refreshDigests bool
+	// automatic deployments are started by the watcher and give way to other operations, see
+	// projectLocks.lockDeployment
+	automatic bool
+	// up enables up settings for this deployment on top of those of the project
+	up docker.UpOptions
+}
+
+// upOptions returns the up settings of a project with those enabled for the deployment added
+func (o deployOptions) upOptions(project docker.UpOptions) docker.UpOptions {
+	return docker.UpOptions{
+		VerboseBuild: project.VerboseBuild || o.up.VerboseBuild,
+		VerbosePull:  project.VerbosePull || o.up.VerbosePull,
+		Build:        project.Build || o.up.Build,
+	}
+}
+
+func (s *ProjectService) deploy(
+	projectID uuid.UUID,
+	opts deployOptions,
+	outputChan chan<- docker.StreamMessage,
+) (err error) {
+	startedAt := time.Now()
+	unlock, err := s.locks.lockDeployment(projectID, opts.automatic, outputChan)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	// Share the output with other subscribers, e.g. a second browser tab watching the deployment
+	outputChan, endOutput := s.deploymentOutputs.start(projectID, outputChan)
+	defer endOutput()
+
+	// Taken after the project lock, so that a deployment waiting for its project does not hold a slot.
+	// See deploySlots for the lock order.
+	release := s.deploySlots.acquire(outputChan)
+	defer release()
+
+	pull := opts.pull
+	project, commitHash, deployment, composeProject, err := s.prepareDeployment(projectID, pull, opts.annotation)
+	if err != nil {
+		return err
+	}
+	if !project.UsesGit() {
+		// Archive projects are deployed from the extracted files as they are
+		pull = false
+	}
+	// What the deployment replaces, to summarize what it changes. Pinning overwrites the project's digests.
+	baseline := deploymentBaseline{imageDigests: project.ImageDigests}
+
+	// The stored status shows the deployment while it runs. A deployment that ends without
+	// recording its result, e.g. because the git pull failed, restores the previous status.
+	previousStatus := project.Status
+	s.setStatus(project, domain.ProjectStatusDeploying)
+	defer func() {
+		if project.Status == domain.ProjectStatusDeploying {
+			s.setStatus(project, previousStatus)
+		}
+	}()
+	composeProject.Services = opts.services
+	composeProject.NoDeps = opts.noDeps
+	composeProject.UpOptions = opts.upOptions(composeProject.UpOptions)
+	deployment.CommandLine = composeProject.UpCommandLine()
+
+	// Notify once the deployment record is final, with the commit pulled for the deployment
+	defer func() {
+		s.notifyDeployment(project, &deployment, commitHash, startedAt, err)
+		status := domain.DeploymentStatusCompleted
+		if err != nil {
+			status = domain.DeploymentStatusFailed
+		}
+		s.metrics.ObserveDeployment(project.ID, project.Name, status.String(), time.Since(startedAt))
+	}()
 
 	// Create buffers to capture stdout and stderr for the deployment record
 	var stdoutBuffer, stderrBuffer strings.Builder
+	imagesPulled := false
 
 	// Helper function to send StreamMessage for web UI only (not stored in DB)
 	sendMessage := func(msg, msgType string) {
 		outputChan <- docker.StreamMessage{Type: msgType, Content: msg}
 	}
+	// report sends a message and keeps it in the deployment record
+	report := func(msg, msgType string) {
+		if msgType == "stderr" {
+			stderrBuffer.WriteString(s.storedOutput(msg) + "\n")
+		} else {
+			stdoutBuffer.WriteString(s.storedOutput(msg) + "\n")
+		}
+		sendMessage(msg, msgType)
+	}
+	pinImages := func() error {
+		if err := s.pinImageDigests(project, composeProject, &deployment, opts.refreshDigests, report); err != nil {
+			sendMessage(fmt.Sprintf("Failed to pin image digests: %v", err), "error")
+			return s.handleDeploymentError(project, &deployment, fmt.Errorf("failed to pin image digests: %w", err))
+		}
+		deployment.CommandLine = composeProject.UpCommandLine()
+		return nil
+	}
 
 	// Streaming-specific messages
 	if pull {
@@ -206,12 +708,32 @@ func (s *ProjectService) DeployStreaming(
 			beforeCommit = "unknown"
 		}
 
-		if err := s.pullLatestChanges(project); err != nil {
+		if opts.planChanges || deployment.PreviousCommit != "" {
+			// Rendered with the deploy variables of the running deployment, so that a change of them,
+			// such as a new OAR_COMMIT, counts as a configuration change
+			baseline.previousConfig, err = s.renderDeployedConfig(project)
+			if err != nil {
+				slog.Warn("Failed to render configuration before pull", "project_id", project.ID, "error", err)
+				baseline.previousConfig = ""
+			}
+		}
+
+		pullOutput, err := s.pullLatestChanges(project)
+		if err != nil {
 			errMsg := fmt.Sprintf("Failed to pull latest changes: %v", err)
 			sendMessage(errMsg, "error")
 			return err
 		}
 
+		// Stream the git output and keep it in the deployment record, ahead of the Docker output
+		for line := range strings.SplitSeq(pullOutput, "\n") {
+			if line == "" {
+				continue
+			}
+			stdoutBuffer.WriteString(s.storedOutput(line) + "\n")
+			sendMessage(line, "stdout")
+		}
+
 		// Get commit hash after pull
 		afterCommit, err := s.gitService.GetLatestCommit(gitDir)
 		if err != nil {
@@ -222,22 +744,178 @@ func (s *ProjectService) DeployStreaming(
 		// Update commitHash to the new commit after pull
 		// This ensures we save the correct commit to the database
 		commitHash = afterCommit
-
-		// Format commit hashes (use first 8 characters, or full string if "unknown")
-		beforeHash := beforeCommit
-		if beforeCommit != "unknown" {
-			beforeHash = beforeCommit[:8]
-		}
-		afterHash := afterCommit
 		if afterCommit != "unknown" {
-			afterHash = afterCommit[:8]
+			deployment.CommitHash = afterCommit
 		}
 
-		successMsg := fmt.Sprintf("Git pull completed successfully (from %s to %s)", beforeHash, afterHash)
+		successMsg := fmt.Sprintf("Git pull completed successfully (from %s to %s)",
+			domain.ShortCommit(beforeCommit), domain.ShortCommit(afterCommit))
 		sendMessage(successMsg, "success")
+
+		// A pull can remove compose files from the repository, Compose would only fail on them opaquely
+		if err := s.failOnMissingComposeFiles(project, &deployment, stdoutBuffer.String(), sendMessage); err != nil {
+			return err
+		}
+
+		// The environment file is generated from the repository, so it is regenerated for the new commit
+		composeProject, err = docker.NewComposeProject(project, s.config,
+			docker.WithDeployment(deployment.ID.String(), commitHash))
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to create compose project: %v", err)
+			sendMessage(errMsg, "error")
+			return s.handleDeploymentError(project, &deployment, err)
+		}
+		composeProject.Services = opts.services
+		composeProject.NoDeps = opts.noDeps
+		composeProject.UpOptions = opts.upOptions(composeProject.UpOptions)
+
+		if baseline.previousConfig != "" {
+			baseline.config, _, err = composeProject.GetConfig()
+			if err != nil {
+				slog.Warn("Failed to render configuration after pull", "project_id", project.ID, "error", err)
+				baseline.config = ""
+			}
+		}
+
+		planPull := false
+		if opts.planChanges {
+			plan := s.planConfigChange(project, baseline.previousConfig, baseline.config)
+			msg := fmt.Sprintf("Configuration change: %s (%s)", plan.Change, plan.Reason)
+			stdoutBuffer.WriteString(s.storedOutput(msg) + "\n")
+			sendMessage(msg, "info")
+
+			composeProject.Services = plan.Services
+			planPull = plan.Pull
+		}
+		// Pinned after the change is planned, which compares the configurations as the repository has them
+		if err := pinImages(); err != nil {
+			return err
+		}
+		if planPull {
+			if err := s.pullImages(composeProject, outputChan, &stdoutBuffer, &stderrBuffer); err != nil {
+				sendMessage(fmt.Sprintf("Failed to pull images: %v", err), "error")
+				return s.handleDeploymentError(project, &deployment, err)
+			}
+			imagesPulled = true
+		}
 	}
 
-	sendMessage("Starting Docker Compose deployment...", "info")
+	if !pull {
+		if err := s.failOnMissingComposeFiles(project, &deployment, stdoutBuffer.String(), sendMessage); err != nil {
+			return err
+		}
+		if err := pinImages(); err != nil {
+			return err
+		}
+	}
+
+	// Labels are written for the services of the configuration being deployed, once it is final
+	if len(composeProject.ContainerLabels) > 0 {
+		if err := composeProject.LabelContainers(); err != nil {
+			sendMessage(fmt.Sprintf("Failed to label containers: %v", err), "error")
+			return s.handleDeploymentError(project, &deployment, fmt.Errorf("failed to label containers: %w", err))
+		}
+		deployment.CommandLine = composeProject.UpCommandLine()
+	}
+
+	deployment.Summary = s.summarizeDeployment(project, &deployment, baseline)
+	for line := range strings.SplitSeq(deployment.Summary, "\n") {
+		sendMessage(line, "info")
+	}
+
+	if opts.pullImages && !imagesPulled {
+		if err := s.pullImages(composeProject, outputChan, &stdoutBuffer, &stderrBuffer); err != nil {
+			sendMessage(fmt.Sprintf("Failed to pull images: %v", err), "error")
+			return s.handleDeploymentError(project, &deployment, err)
+		}
+	}
+
+	// A renamed project is deployed as a new Compose project, which leaves the old containers running
+	if len(project.PreviousNames) > 0 {
+		s.checkPreviousNames(project, opts.removePreviousStacks, report)
+	}
+
+	if services := composeProject.Services; len(services) > 0 && composeProject.NoDeps {
+		sendMessage(fmt.Sprintf("Starting Docker Compose deployment of services: %s, without their dependencies...",
+			strings.Join(services, ", ")), "info")
+	} else if len(services) > 0 {
+		sendMessage(fmt.Sprintf("Starting Docker Compose deployment of services: %s...",
+			strings.Join(services, ", ")), "info")
+	} else {
+		sendMessage("Starting Docker Compose deployment...", "info")
+	}
+
+	// Catch depends_on cycles and unknown services before up fails on them with a less helpful error
+	if err := composeProject.ValidateConfig(); err != nil {
+		sendMessage(fmt.Sprintf("Invalid project configuration: %v", err), "error")
+		s.failDeployment(&deployment, err, stdoutBuffer.String(), "")
+		return fmt.Errorf("invalid project configuration: %w", err)
+	}
+
+	// Compose refuses to start services attached to an external network that does not exist
+	if len(project.ExternalNetworks) > 0 {
+		sendMessage("Ensuring external networks...", "info")
+		messages, err := ensureExternalNetworks(project)
+		for _, msg := range messages {
+			stdoutBuffer.WriteString(s.storedOutput(msg) + "\n")
+			sendMessage(msg, "stdout")
+		}
+		if err != nil {
+			sendMessage(fmt.Sprintf("Failed to ensure external networks: %v", err), "error")
+			s.failDeployment(&deployment, err, stdoutBuffer.String(), "")
+			return fmt.Errorf("failed to ensure external networks: %w", err)
+		}
+	}
+
+	// In offline mode, fail on missing images before anything is created; with a registry mirror,
+	// pull them from the mirror. Volume initialization runs a helper image of its own.
+	if policy := composeProject.ImagePolicy; policy.Offline || policy.RegistryMirror != "" {
+		sendMessage("Preparing images...", "info")
+		var helperImages []string
+		if !project.SkipVolumeInit {
+			helperImages = append(helperImages, docker.BusyboxImage)
+		}
+		if err := composeProject.PrepareImages(outputChan, helperImages...); err != nil {
+			sendMessage(fmt.Sprintf("Failed to prepare images: %v", err), "error")
+			s.failDeployment(&deployment, err, stdoutBuffer.String(), "")
+			return fmt.Errorf("failed to prepare images: %w", err)
+		}
+	}
+
+	// docker compose up cannot take build arguments, so the images are built before
+	if len(composeProject.BuildArgs) > 0 {
+		if err := s.buildImages(composeProject, outputChan, &stdoutBuffer, &stderrBuffer); err != nil {
+			sendMessage(fmt.Sprintf("Failed to build images: %v", err), "error")
+			s.failDeployment(&deployment, err, stdoutBuffer.String(), stderrBuffer.String())
+			return fmt.Errorf("failed to build images: %w", err)
+		}
+	}
+
+	// Volume mounts initialized before creating the containers are ready before the hooks use them, and
+	// the running containers are only replaced once they are
+	initAfterCreate := !project.SkipVolumeInit
+	if !project.SkipVolumeInit && project.VolumeInitPhase == domain.VolumeInitBeforeCreate {
+		initAfterCreate, err = s.initVolumeMountsBeforeCreate(composeProject, outputChan, &stdoutBuffer, &stderrBuffer)
+		if err != nil {
+			sendMessage(fmt.Sprintf("Failed to initialize volume mounts: %v", err), "error")
+			s.failDeployment(&deployment, err, stdoutBuffer.String(), stderrBuffer.String())
+			return fmt.Errorf("volume initialization failed: %w", err)
+		}
+	}
+
+	// Hooks run in new containers of the deployed configuration, e.g. a migration before the new code starts
+	if len(project.DeployHooks) > 0 {
+		err := checkHookServices(project, composeProject)
+		if err == nil {
+			err = s.runDeployHooks(project, composeProject, domain.HookPhasePreDeploy, outputChan,
+				&stdoutBuffer, &stderrBuffer)
+		}
+		if err != nil {
+			sendMessage(err.Error(), "error")
+			s.failDeployment(&deployment, err, stdoutBuffer.String(), stderrBuffer.String())
+			return err
+		}
+	}
 
 	// Create a capturing channel that forwards Docker stdout/stderr and stores for database
 	capturingChan := make(chan docker.StreamMessage, 100)
@@ -246,12 +924,14 @@ func (s *ProjectService) DeployStreaming(
 	go func() {
 		defer func() { done <- true }()
 		for msg := range capturingChan {
+			// up builds missing images, whose output may show build secrets
+			msg.Content = composeProject.RedactBuildSecrets(msg.Content)
 			// Store Docker output in appropriate buffer for database
 			switch msg.Type {
 			case "stdout":
-				stdoutBuffer.WriteString(msg.Content + "\n")
+				stdoutBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
 			case "stderr":
-				stderrBuffer.WriteString(msg.Content + "\n")
+				stderrBuffer.WriteString(s.storedOutput(msg.Content) + "\n")
 			}
 			// Forward message directly to user
 			outputChan <- msg
@@ -270,15 +950,12 @@ func (s *ProjectService) DeployStreaming(
 
 		errMsg := fmt.Sprintf("Failed to create containers: %v", err)
 		sendMessage(errMsg, "error")
+		s.reportFailedServices(composeProject, &deployment, false, outputChan)
 		deployment.Status = domain.DeploymentStatusFailed
-		if updateErr := s.deploymentRepository.Update(&deployment); updateErr != nil {
-			slog.Error("Failed to update deployment status", "error", updateErr)
-		}
-
-		// Update project status to error
 		project.Status = domain.ProjectStatusError
-		if updateErr := s.projectRepository.Update(project); updateErr != nil {
-			slog.Error("Failed to update project status to error",
+		if updateErr := s.saveDeploymentOutcome(project, &deployment); updateErr != nil {
+			slog.Error("Failed to record failed deployment",
+				"deployment_id", deployment.ID,
 				"project_id", project.ID,
 				"error", updateErr)
 		}
@@ -286,35 +963,41 @@ func (s *ProjectService) DeployStreaming(
 		return fmt.Errorf("container creation failed: %w", err)
 	}
 
-	// Initialize volume permissions
-	sendMessage("Initializing volume mounts...", "info")
-	if err := composeProject.InitializeVolumeMounts(); err != nil {
-		// Ensure we capture any output that was generated before failure
-		close(capturingChan)
-		<-done
-		deployment.Stdout = stdoutBuffer.String()
-		deployment.Stderr = stderrBuffer.String()
-
-		errMsg := fmt.Sprintf("Failed to initialize volume permissions: %v", err)
-		sendMessage(errMsg, "error")
-		deployment.Status = domain.DeploymentStatusFailed
-		if updateErr := s.deploymentRepository.Update(&deployment); updateErr != nil {
-			slog.Error("Failed to update deployment status", "error", updateErr)
+	// Initialize volume permissions, unless the project opted out (e.g. NFS or read-only mounts) or they
+	// were initialized before creating the containers
+	if project.SkipVolumeInit {
+		sendMessage("Initializing volume mounts... (skipped)", "info")
+	} else if initAfterCreate {
+		if project.VolumeInitPhase == domain.VolumeInitBeforeCreate {
+			sendMessage("Initializing new volume mounts...", "info")
+		} else {
+			sendMessage("Initializing volume mounts...", "info")
 		}
+		if err := composeProject.InitializeVolumeMounts(); err != nil {
+			// Ensure we capture any output that was generated before failure
+			close(capturingChan)
+			<-done
+			deployment.Stdout = stdoutBuffer.String()
+			deployment.Stderr = stderrBuffer.String()
+
+			errMsg := fmt.Sprintf("Failed to initialize volume permissions: %v", err)
+			sendMessage(errMsg, "error")
+			deployment.Status = domain.DeploymentStatusFailed
+			project.Status = domain.ProjectStatusError
+			if updateErr := s.saveDeploymentOutcome(project, &deployment); updateErr != nil {
+				slog.Error("Failed to record failed deployment",
+					"deployment_id", deployment.ID,
+					"project_id", project.ID,
+					"error", updateErr)
+			}
 
-		// Update project status to error
-		project.Status = domain.ProjectStatusError
-		if updateErr := s.projectRepository.Update(project); updateErr != nil {
-			slog.Error("Failed to update project status to error",
-				"project_id", project.ID,
-				"error", updateErr)
+			return fmt.Errorf("volume initialization failed: %w", err)
 		}
-
-		return fmt.Errorf("volume initialization failed: %w", err)
 	}
 
-	// Start services with streaming
+	// Start services with streaming. Images to build were built when the containers were created.
 	sendMessage("Starting services...", "info")
+	composeProject.UpOptions.Build = false
 	err = composeProject.UpStreaming(true, capturingChan)
 	close(capturingChan) // Signal that we're done sending to the capturing channel
 	<-done               // Wait for the goroutine to finish processing all messages
@@ -324,9 +1007,22 @@ func (s *ProjectService) DeployStreaming(
 	deployment.Stderr = stderrBuffer.String()
 
 	if err != nil {
+		s.reportFailedServices(composeProject, &deployment, true, outputChan)
 		return s.handleDeploymentError(project, &deployment, err)
 	}
 
+	// The services are up, a failing hook fails the deployment but leaves them running
+	err = s.runDeployHooks(project, composeProject, domain.HookPhasePostDeploy, outputChan,
+		&stdoutBuffer, &stderrBuffer)
+	if err != nil {
+		sendMessage(err.Error(), "error")
+		deployment.Stdout = stdoutBuffer.String()
+		deployment.Stderr = stderrBuffer.String()
+		return s.handleDeploymentError(project, &deployment, err)
+	}
+	deployment.Stdout = stdoutBuffer.String()
+	deployment.Stderr = stderrBuffer.String()
+
 	// Complete deployment
 	if err := s.completeDeployment(project, commitHash, deployment); err != nil {
 		return err
@@ -338,34 +1034,86 @@ func (s *ProjectService) DeployStreaming(
 	return nil
 }
 
-func (s *ProjectService) DeployPiping(projectID uuid.UUID, pull bool) error {
-	// Create a local channel to capture streaming output
-	outputChan := make(chan docker.StreamMessage, 100)
-	done := make(chan bool)
+// SubscribeDeployment returns the output of the deployment running for the project, starting with
+// its latest messages, which are kept in memory while it runs. The channel is closed when the
+// deployment finishes; call the returned function to stop receiving earlier. Messages are dropped
+// for a subscriber that does not keep up, so that it cannot slow down the deployment.
+// ErrNoDeploymentInProgress is returned when no deployment runs.
+func (s *ProjectService) SubscribeDeployment(projectID uuid.UUID) (<-chan docker.StreamMessage, func(), error) {
+	return s.deploymentOutputs.subscribe(projectID)
+}
 
-	// Start a goroutine to consume StreamMessages and display to terminal
-	go func() {
-		defer func() { done <- true }()
-		for msg := range outputChan {
-			// Display raw message content to terminal without modification
-			fmt.Println(msg.Content)
-		}
-	}()
+// IsDeploying reports whether a deployment of the project is in progress
+func (s *ProjectService) IsDeploying(projectID uuid.UUID) bool {
+	return s.deploymentOutputs.inProgress(projectID)
+}
 
-	// Use DeployStreaming internally (it now stores clean output in database)
-	err := s.DeployStreaming(projectID, pull, outputChan)
+// IsRemoving reports whether the project is being removed
+func (s *ProjectService) IsRemoving(projectID uuid.UUID) bool {
+	return s.locks.removing(projectID)
+}
 
-	// Close channel and wait for goroutine to finish
-	close(outputChan)
-	<-done
+// setStatus stores a new status for the project without saving its other fields
+func (s *ProjectService) setStatus(project *domain.Project, status domain.ProjectStatus) {
+	project.Status = status
+	if err := s.projectRepository.UpdateStatus(project.ID, status); err != nil {
+		slog.Error("Failed to update project status",
+			"project_id", project.ID,
+			"status", status.String(),
+			"error", err)
+	}
+}
 
-	return err
+func (s *ProjectService) DeployPiping(
+	projectID uuid.UUID,
+	pull bool,
+	services []string,
+	annotation domain.DeploymentAnnotation,
+) error {
+	return pipeOutput(func(outputChan chan<- docker.StreamMessage) error {
+		return s.DeployStreaming(projectID, pull, services, annotation, outputChan)
+	})
+}
+
+// AutoDeployOptions controls a deployment started by the watcher
+type AutoDeployOptions struct {
+	// GitPull pulls the latest commit before deploying
+	GitPull bool
+	// PullImages pulls the images of the deployed services before bringing them up
+	PullImages bool
+	// ChangesOnly deploys only what the configuration change of the pulled commit needs, as
+	// DeployChangesPiping does. It requires GitPull.
+	ChangesOnly bool
+}
+
+// DeployAutomaticPiping deploys the project for the watcher, printing the output to the terminal.
+// It returns ErrDeploymentDeferred without deploying when another operation on the project is in
+// progress, including a deployment started by a user that waits for it.
+func (s *ProjectService) DeployAutomaticPiping(projectID uuid.UUID, opts AutoDeployOptions) error {
+	return pipeOutput(func(outputChan chan<- docker.StreamMessage) error {
+		return s.deploy(projectID, deployOptions{
+			pull:        opts.GitPull,
+			planChanges: opts.GitPull && opts.ChangesOnly,
+			pullImages:  opts.PullImages,
+			automatic:   true,
+		}, outputChan)
+	})
+}
+
+// storedOutput returns deployment output in the format configured for the deployment history.
+// Output streamed to clients is not affected.
+func (s *ProjectService) storedOutput(text string) string {
+	if s.config.ComposeStoredOutput == "plain" {
+		return docker.StripANSI(text)
+	}
+	return text
 }
 
 // prepareDeployment handles the common setup logic for both streaming and piping deployments
 func (s *ProjectService) prepareDeployment(
 	projectID uuid.UUID,
 	pull bool,
+	annotation domain.DeploymentAnnotation,
 ) (*domain.Project, string, domain.Deployment, *docker.ComposeProject, error) {
 	// Get project
 	project, err := s.Get(projectID)
@@ -378,7 +1126,7 @@ func (s *ProjectService) prepareDeployment(
 		return nil, "", domain.Deployment{}, nil, fmt.Errorf("failed to get git directory: %w", err)
 	}
 
-	commitHash, err := s.gitService.GetLatestCommit(gitDir)
+	commitHash, err := s.currentCommit(project, gitDir)
 	if err != nil {
 		slog.Error("Service operation failed",
 			"layer", "service",
@@ -390,6 +1138,12 @@ func (s *ProjectService) prepareDeployment(
 
 	deployment := domain.NewDeployment(projectID, commitHash)
 	deployment.Status = domain.DeploymentStatusStarted
+	deployment.PreviousCommit = s.previousCommit(project)
+	deployment.Notes = strings.TrimSpace(annotation.Notes)
+	deployment.Labels = normalizeLabels(annotation.Labels)
+	versions := s.versions.get(s.config.ComposeQueryTimeout)
+	deployment.DockerVersion = versions.Docker
+	deployment.ComposeVersion = versions.Compose
 
 	// Create deployment record immediately
 	if err := s.deploymentRepository.Create(&deployment); err != nil {
@@ -402,15 +1156,102 @@ func (s *ProjectService) prepareDeployment(
 		"project_name", project.Name,
 		"deployment_id", deployment.ID,
 		"commit_hash", commitHash,
-		"compose_files", project.ComposeFiles,
+		"compose_files", project.EnabledComposeFiles(),
 		"pull", pull)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := docker.NewComposeProject(project, s.config,
+		docker.WithDeployment(deployment.ID.String(), commitHash))
+	if err != nil {
+		return nil, "", domain.Deployment{}, nil, fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	return project, commitHash, deployment, composeProject, nil
+}
+
+// currentCommit returns the commit checked out in gitDir. Archive projects have no repository, their
+// commit is the digest of the archive they were created from.
+func (s *ProjectService) currentCommit(project *domain.Project, gitDir string) (string, error) {
+	if !project.UsesGit() {
+		return project.LocalCommitStr(), nil
+	}
+	return s.gitService.GetLatestCommit(gitDir)
+}
+
+// newComposeProject creates the Compose project of a project outside of a deployment. Its deploy
+// variables describe what is deployed, so that commands render the configuration that is running.
+func (s *ProjectService) newComposeProject(project *domain.Project) (*docker.ComposeProject, error) {
+	return docker.NewComposeProject(project, s.config,
+		docker.WithDeployment(s.latestDeploymentID(project), project.LocalCommitStr()))
+}
+
+// renderDeployedConfig renders the configuration of what is deployed
+func (s *ProjectService) renderDeployedConfig(project *domain.Project) (string, error) {
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return "", err
+	}
+	config, _, err := composeProject.GetConfig()
+	return config, err
+}
+
+// deployedVariables returns the deploy variables of what is deployed: the project's deployed commit
+// and its latest completed deployment
+func (s *ProjectService) deployedVariables(project *domain.Project) []string {
+	return docker.DeployVariables(project.LocalCommitStr(), project.Name, s.latestDeploymentID(project),
+		s.config.ComposeDeployEnv)
+}
+
+// latestDeploymentID returns the ID of the project's latest completed deployment, empty when there is none
+func (s *ProjectService) latestDeploymentID(project *domain.Project) string {
+	id, err := s.deploymentRepository.LatestCompletedID(project.ID)
+	if err != nil {
+		slog.Warn("Failed to look up the latest deployment", "project_id", project.ID, "error", err)
+		return ""
+	}
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// previousCommit returns the commit a new deployment of the project replaces: the commit of its last
+// successful deployment. A project that was never deployed successfully has none.
+func (s *ProjectService) previousCommit(project *domain.Project) string {
+	deployed, err := s.deploymentRepository.HasCompleted(project.ID)
+	if err != nil {
+		slog.Warn("Failed to look up earlier deployments", "project_id", project.ID, "error", err)
+		return ""
+	}
+	if !deployed {
+		return ""
+	}
+	return project.LocalCommitStr()
+}
+
+// reportFailedServices finds the services that did not come up after up failed, keeps them in the
+// deployment record and streams a one-line summary for each. Set started when up was starting the
+// services rather than only creating their containers.
+func (s *ProjectService) reportFailedServices(
+	composeProject *docker.ComposeProject,
+	deployment *domain.Deployment,
+	started bool,
+	outputChan chan<- docker.StreamMessage,
+) {
+	failures, err := composeProject.DiagnoseFailure(started)
 	if err != nil {
-		return nil, "", domain.Deployment{}, nil, fmt.Errorf("failed to create compose project: %w", err)
+		slog.Warn("Failed to find the services that did not start",
+			"project_id", deployment.ProjectID,
+			"deployment_id", deployment.ID,
+			"error", err)
+		return
 	}
 
-	return project, commitHash, deployment, composeProject, nil
+	deployment.FailedServices = failures
+	for _, failure := range failures {
+		summary := failure.String()
+		deployment.Stderr += s.storedOutput(summary) + "\n"
+		outputChan <- docker.StreamMessage{Type: "error", Content: summary}
+	}
 }
 
 // handleDeploymentError handles deployment errors consistently
@@ -426,25 +1267,19 @@ func (s *ProjectService) handleDeploymentError(
 	if deployment.Stderr != "" {
 		deployment.Stderr += "\n"
 	}
-	deployment.Stderr += fmt.Sprintf("ERROR: %v", err)
+	deployment.Stderr += s.storedOutput(fmt.Sprintf("ERROR: %v", err))
 
 	// Update project status to error
 	project.Status = domain.ProjectStatusError
 
 	// Update both deployment and project records
-	if updateErr := s.deploymentRepository.Update(deployment); updateErr != nil {
-		slog.Error("Failed to update deployment record as failed",
+	if updateErr := s.saveDeploymentOutcome(project, deployment); updateErr != nil {
+		slog.Error("Failed to record failed deployment",
 			"deployment_id", deployment.ID,
 			"project_id", deployment.ProjectID,
 			"error", updateErr)
 	}
 
-	if updateErr := s.projectRepository.Update(project); updateErr != nil {
-		slog.Error("Failed to update project status to error",
-			"project_id", project.ID,
-			"error", updateErr)
-	}
-
 	slog.Error(
 		"Docker Compose up failed",
 		"project_id", deployment.ProjectID,
@@ -472,19 +1307,38 @@ func (s *ProjectService) completeDeployment(
 	project.Status = domain.ProjectStatusRunning
 	project.LocalCommit = &commitHash
 
-	// TODO: Transaction
-	if err := s.deploymentRepository.Update(&deployment); err != nil {
-		return fmt.Errorf("failed to update deployment record: %w", err)
-	}
-
-	if err := s.projectRepository.Update(project); err != nil {
-		return fmt.Errorf("failed to update project status: %w", err)
-	}
+	return s.saveDeploymentOutcome(project, &deployment)
+}
 
-	return nil
+// saveDeploymentOutcome saves the deployment and the project it changed in a single transaction, so
+// that a failure in between cannot leave the deployment completed and the project still deploying.
+// Only the fields the deployment sets are saved, the project may have been edited while it ran.
+func (s *ProjectService) saveDeploymentOutcome(project *domain.Project, deployment *domain.Deployment) error {
+	return repository.Transaction(s.projectRepository, s.deploymentRepository,
+		func(projects repository.ProjectRepository, deployments repository.DeploymentRepository) error {
+			if err := deployments.Update(deployment); err != nil {
+				return fmt.Errorf("failed to update deployment record: %w", err)
+			}
+			if err := projects.UpdateDeploymentState(project); err != nil {
+				return fmt.Errorf("failed to update project status: %w", err)
+			}
+			return nil
+		})
 }
 
 func (s *ProjectService) Stop(projectID uuid.UUID, removeVolumes bool) error {
+	unlock := s.locks.lock(projectID, nil)
+	defer unlock()
+
+	return s.stop(projectID, docker.DownOptions{RemoveVolumes: removeVolumes})
+}
+
+// stop brings the project's containers down. The caller holds the project lock, Remove through
+// lockRemoval.
+func (s *ProjectService) stop(projectID uuid.UUID, opts docker.DownOptions) error {
+	s.manualOperations.mark(projectID)
+	defer s.manualOperations.mark(projectID)
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -498,14 +1352,16 @@ func (s *ProjectService) Stop(projectID uuid.UUID, removeVolumes bool) error {
 		project.ID,
 		"project_name",
 		project.Name,
+		"remove_volumes",
+		opts.RemoveVolumes,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return fmt.Errorf("failed to create compose project: %w", err)
 	}
 
-	stdout, stderr, err := composeProject.Down(removeVolumes)
+	stdout, stderr, err := composeProject.Down(opts)
 	if err != nil {
 		slog.Error(
 			"Docker Compose down failed",
@@ -530,11 +1386,27 @@ func (s *ProjectService) Stop(projectID uuid.UUID, removeVolumes bool) error {
 		len(stderr),
 	)
 
-	project.Status = domain.ProjectStatusStopped
-	return s.Update(project)
+	s.markStopped(project)
+	return nil
+}
+
+// markStopped records that the containers of a project were stopped. All stop variants end with it,
+// whether or not they removed the volumes.
+func (s *ProjectService) markStopped(project *domain.Project) {
+	s.setStatus(project, domain.ProjectStatusStopped)
 }
 
-func (s *ProjectService) StopStreaming(projectID uuid.UUID, outputChan chan<- docker.StreamMessage) error {
+func (s *ProjectService) StopStreaming(
+	projectID uuid.UUID,
+	removeVolumes bool,
+	outputChan chan<- docker.StreamMessage,
+) error {
+	unlock := s.locks.lock(projectID, outputChan)
+	defer unlock()
+
+	s.manualOperations.mark(projectID)
+	defer s.manualOperations.mark(projectID)
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -548,9 +1420,11 @@ func (s *ProjectService) StopStreaming(projectID uuid.UUID, outputChan chan<- do
 		project.ID,
 		"project_name",
 		project.Name,
+		"remove_volumes",
+		removeVolumes,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return fmt.Errorf("failed to create compose project: %w", err)
 	}
@@ -575,7 +1449,7 @@ func (s *ProjectService) StopStreaming(projectID uuid.UUID, outputChan chan<- do
 	}()
 
 	// Execute stop with streaming
-	err = composeProject.DownStreaming(capturingChan)
+	err = composeProject.DownStreaming(docker.DownOptions{RemoveVolumes: removeVolumes}, capturingChan)
 	close(capturingChan) // Signal that we're done sending to the capturing channel
 	<-done               // Wait for the goroutine to finish processing all messages
 
@@ -598,11 +1472,7 @@ func (s *ProjectService) StopStreaming(projectID uuid.UUID, outputChan chan<- do
 		project.ID,
 	)
 
-	project.Status = domain.ProjectStatusStopped
-	err = s.Update(project)
-	if err != nil {
-		return fmt.Errorf("failed to update project status: %w", err)
-	}
+	s.markStopped(project)
 
 	// Send unified message with both display text and project state
 	sendMessage("Docker Compose shutdown completed successfully", "success")
@@ -610,7 +1480,13 @@ func (s *ProjectService) StopStreaming(projectID uuid.UUID, outputChan chan<- do
 	return nil
 }
 
-func (s *ProjectService) StopPiping(projectID uuid.UUID) error {
+func (s *ProjectService) StopPiping(projectID uuid.UUID, removeVolumes bool) error {
+	unlock := s.locks.lock(projectID, nil)
+	defer unlock()
+
+	s.manualOperations.mark(projectID)
+	defer s.manualOperations.mark(projectID)
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -624,14 +1500,16 @@ func (s *ProjectService) StopPiping(projectID uuid.UUID) error {
 		project.ID,
 		"project_name",
 		project.Name,
+		"remove_volumes",
+		removeVolumes,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return fmt.Errorf("failed to create compose project: %w", err)
 	}
 
-	err = composeProject.DownPiping()
+	err = composeProject.DownPiping(docker.DownOptions{RemoveVolumes: removeVolumes})
 	if err != nil {
 		slog.Error(
 			"Docker Compose down failed",
@@ -648,11 +1526,128 @@ func (s *ProjectService) StopPiping(projectID uuid.UUID) error {
 		project.ID,
 	)
 
-	project.Status = domain.ProjectStatusStopped
-	return s.Update(project)
+	s.markStopped(project)
+	return nil
+}
+
+// KillService sends a signal to the running containers of a service, e.g. SIGHUP to reload its
+// configuration. The signal is required: docker compose kill without one would stop the service.
+func (s *ProjectService) KillService(
+	projectID uuid.UUID,
+	service, signal string,
+	outputChan chan<- docker.StreamMessage,
+) error {
+	signal, err := docker.ParseSignal(signal)
+	if err != nil {
+		return err
+	}
+
+	s.manualOperations.mark(projectID)
+	defer s.manualOperations.mark(projectID)
+
+	project, err := s.Get(projectID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+
+	status, err := s.GetStatus(projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project status: %w", err)
+	}
+
+	found, running := false, false
+	for _, container := range status.Containers {
+		if container.Service != service {
+			continue
+		}
+		found = true
+		if container.State == "running" {
+			running = true
+		}
+	}
+	if !found {
+		// Tell a typo apart from a declared service that has no containers yet
+		if services, err := s.ListServices(projectID); err == nil && slices.Contains(services, service) {
+			return fmt.Errorf("service %q has no containers, deploy the project first", service)
+		}
+		return fmt.Errorf("service %q not found in project %s", service, project.Name)
+	}
+	if !running {
+		return fmt.Errorf("service %q is not running", service)
+	}
+
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	slog.Info("Sending signal to service",
+		"project_id", project.ID,
+		"project_name", project.Name,
+		"service", service,
+		"signal", signal)
+
+	outputChan <- docker.StreamMessage{Type: "info", Content: fmt.Sprintf("Sending %s to %s...", signal, service)}
+
+	if err := composeProject.KillStreaming(service, signal, outputChan); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "kill_service",
+			"project_id", project.ID,
+			"service", service,
+			"signal", signal,
+			"error", err)
+		outputChan <- docker.StreamMessage{Type: "error", Content: fmt.Sprintf("Signal failed: %v", err)}
+		return fmt.Errorf("failed to send %s to service %s: %w", signal, service, err)
+	}
+
+	outputChan <- docker.StreamMessage{Type: "success", Content: fmt.Sprintf("Sent %s to %s", signal, service)}
+	return nil
 }
 
-func (s *ProjectService) Remove(projectID uuid.UUID, removeVolumes bool) error {
+// RemoveOptions controls how the containers of a project are shut down when it is removed
+type RemoveOptions struct {
+	// RemoveVolumes also removes the named volumes of the project. Volumes are kept by default.
+	RemoveVolumes bool
+	// StopSignal replaces the stop signal of the services, e.g. SIGINT for a fast database shutdown.
+	// Empty keeps the stop_signal from the compose file.
+	StopSignal string
+	// StopTimeout is how long services get to shut down before they are killed. Zero keeps the
+	// stop_grace_period from the compose file, which defaults to 10 seconds.
+	StopTimeout time.Duration
+	// RemoveImages also removes the images of the services: docker.RemoveImagesLocal those built by
+	// Compose, docker.RemoveImagesAll every one of them. Images are kept by default. SharedImages
+	// lists the images that other projects use too.
+	RemoveImages string
+}
+
+func (s *ProjectService) Remove(projectID uuid.UUID, opts RemoveOptions) error {
+	downOptions := docker.DownOptions{
+		RemoveVolumes: opts.RemoveVolumes,
+		Timeout:       opts.StopTimeout,
+		RemoveImages:  opts.RemoveImages,
+	}
+	if err := docker.ValidateRemoveImages(opts.RemoveImages); err != nil {
+		return err
+	}
+	if opts.StopSignal != "" {
+		signal, err := docker.ParseSignal(opts.StopSignal)
+		if err != nil {
+			return err
+		}
+		downOptions.Signal = signal
+	}
+	if opts.StopTimeout < 0 {
+		return fmt.Errorf("stop timeout must not be negative")
+	}
+
+	// A deployment removed mid-way would leave containers behind, or recreate the working directory
+	unlock, err := s.locks.lockRemoval(projectID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -660,7 +1655,7 @@ func (s *ProjectService) Remove(projectID uuid.UUID, removeVolumes bool) error {
 	}
 
 	// Stop Docker Compose project if running
-	if err := s.Stop(projectID, removeVolumes); err != nil {
+	if err := s.stop(projectID, downOptions); err != nil {
 		slog.Warn("Failed to stop project before removal", "project_id", project.ID, "error", err)
 		return fmt.Errorf("failed to stop project before removal: %w", err)
 	}
@@ -685,6 +1680,8 @@ func (s *ProjectService) Remove(projectID uuid.UUID, removeVolumes bool) error {
 	if err := s.projectRepository.Delete(projectID); err != nil {
 		return fmt.Errorf("failed to delete project from database: %w", err)
 	}
+	s.statusCache.delete(projectID)
+	s.metrics.RemoveProject(projectID)
 
 	slog.Info(
 		"Project removed successfully",
@@ -696,7 +1693,12 @@ func (s *ProjectService) Remove(projectID uuid.UUID, removeVolumes bool) error {
 	return nil
 }
 
-func (s *ProjectService) GetLogs(projectID uuid.UUID) (string, string, error) {
+// GetLogs returns the logs of the project's containers
+func (s *ProjectService) GetLogs(projectID uuid.UUID, opts docker.LogsOptions) (string, string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", "", err
+	}
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -712,11 +1714,11 @@ func (s *ProjectService) GetLogs(projectID uuid.UUID) (string, string, error) {
 		project.Name,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create compose project: %w", err)
 	}
-	stdout, stderr, err := composeProject.Logs()
+	stdout, stderr, err := composeProject.Logs(opts)
 	if err != nil {
 		slog.Error(
 			"Failed to get logs",
@@ -742,7 +1744,44 @@ func (s *ProjectService) GetLogs(projectID uuid.UUID) (string, string, error) {
 	return stdout, stderr, nil
 }
 
-func (s *ProjectService) GetLogsPiping(projectID uuid.UUID) error {
+// GetContainerLogs returns the logs of a single container of the project, by container ID or name,
+// such as one replica of a scaled service. The container must belong to the project.
+func (s *ProjectService) GetContainerLogs(
+	projectID uuid.UUID,
+	container string,
+	opts docker.LogsOptions,
+) (string, string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", "", err
+	}
+
+	project, err := s.Get(projectID)
+	if err != nil {
+		return "", "", fmt.Errorf("project not found: %w", err)
+	}
+
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create compose project: %w", err)
+	}
+	stdout, stderr, err := composeProject.ContainerLogs(container, opts)
+	if err != nil {
+		slog.Error("Failed to get container logs",
+			"project_id", project.ID,
+			"container", container,
+			"error", err)
+		return "", "", fmt.Errorf("failed to get logs: %w", err)
+	}
+	return stdout, stderr, nil
+}
+
+// GetLogsPiping follows the logs of the project's containers on the process's stdout and stderr.
+// Lines that do not match opts.Grep are dropped before they are written.
+func (s *ProjectService) GetLogsPiping(projectID uuid.UUID, opts docker.LogsOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -758,12 +1797,12 @@ func (s *ProjectService) GetLogsPiping(projectID uuid.UUID) error {
 		project.Name,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return fmt.Errorf("failed to create compose project: %w", err)
 	}
 
-	err = composeProject.LogsPiping()
+	err = composeProject.LogsPiping(opts)
 	if err != nil {
 		slog.Error(
 			"Failed to stream logs",
@@ -800,12 +1839,13 @@ func (s *ProjectService) GetConfig(projectID uuid.UUID) (string, string, error)
 		project.Name,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create compose project: %w", err)
 	}
 
 	stdout, stderr, err := composeProject.GetConfig()
+	stdout, stderr = composeProject.RedactBuildSecrets(stdout), composeProject.RedactBuildSecrets(stderr)
 	if err != nil {
 		slog.Error(
 			"Failed to get configuration",
@@ -831,8 +1871,117 @@ func (s *ProjectService) GetConfig(projectID uuid.UUID) (string, string, error)
 	return stdout, stderr, nil
 }
 
+// GetMergedConfig returns the resolved configuration annotated with the compose files in merge order
+// and the files that declare each service, network, volume, config and secret
+func (s *ProjectService) GetMergedConfig(projectID uuid.UUID) (string, string, error) {
+	project, err := s.Get(projectID)
+	if err != nil {
+		return "", "", fmt.Errorf("project not found: %w", err)
+	}
+
+	composeProject, err := s.newComposeProject(project)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create compose project: %w", err)
+	}
+
+	stdout, stderr, err := composeProject.GetMergedConfig()
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "get_merged_config",
+			"project_id", project.ID,
+			"error", err)
+		return "", stderr, fmt.Errorf("failed to get configuration: %w", err)
+	}
+	return stdout, stderr, nil
+}
+
+// GetEffectiveEnv returns the variables Docker Compose uses for interpolation, after merging the
+// repository .env, the project env files and inline variables, with the source of each value.
+// Secret-looking values are masked unless showSecrets is set.
+func (s *ProjectService) GetEffectiveEnv(projectID uuid.UUID, showSecrets bool) ([]docker.EnvVar, error) {
+	project, err := s.Get(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found: %w", err)
+	}
+
+	gitDir, err := project.GitDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git directory: %w", err)
+	}
+
+	vars, err := docker.ResolveEnv(gitDir, project.EnabledComposeFiles(), project.EnvFiles, project.Variables,
+		s.deployedVariables(project))
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "get_effective_env",
+			"project_id", project.ID,
+			"error", err)
+		return nil, fmt.Errorf("failed to resolve environment: %w", err)
+	}
+
+	if !showSecrets {
+		vars = docker.MaskSecrets(vars)
+	}
+	return vars, nil
+}
+
 // GetStatus gets the current status of a project's containers
 func (s *ProjectService) GetStatus(projectID uuid.UUID) (*docker.ComposeStatus, error) {
+	return s.getStatus(context.Background(), projectID)
+}
+
+// GetStatusMany gets the status of several projects concurrently, with at most statusConcurrency
+// Docker Compose calls in flight and an overall limit of statusManyTimeout. Projects whose status
+// could not be determined, including ones cut off by the timeout, are reported in the error map.
+func (s *ProjectService) GetStatusMany(
+	projectIDs []uuid.UUID,
+) (map[uuid.UUID]*docker.ComposeStatus, map[uuid.UUID]error) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusManyTimeout)
+	defer cancel()
+
+	type statusResult struct {
+		projectID uuid.UUID
+		status    *docker.ComposeStatus
+		err       error
+	}
+
+	results := make(chan statusResult, len(projectIDs))
+	slots := make(chan struct{}, statusConcurrency)
+
+	for _, projectID := range projectIDs {
+		go func() {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				err := fmt.Errorf("timed out waiting for status: %w", ctx.Err())
+				results <- statusResult{projectID: projectID, err: err}
+				return
+			}
+
+			status, err := s.getStatus(ctx, projectID)
+			results <- statusResult{projectID: projectID, status: status, err: err}
+		}()
+	}
+
+	// Compose commands are killed once ctx is done, so every goroutine reports back soon after the timeout
+	statuses := make(map[uuid.UUID]*docker.ComposeStatus, len(projectIDs))
+	errs := make(map[uuid.UUID]error)
+	for range projectIDs {
+		result := <-results
+		if result.err != nil {
+			errs[result.projectID] = result.err
+			continue
+		}
+		statuses[result.projectID] = result.status
+	}
+
+	return statuses, errs
+}
+
+func (s *ProjectService) getStatus(ctx context.Context, projectID uuid.UUID) (*docker.ComposeStatus, error) {
 	// Get project
 	project, err := s.Get(projectID)
 	if err != nil {
@@ -848,12 +1997,13 @@ func (s *ProjectService) GetStatus(projectID uuid.UUID) (*docker.ComposeStatus,
 		project.Name,
 	)
 
-	composeProject, err := docker.NewComposeProject(project, s.config)
+	composeProject, err := s.newComposeProject(project)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compose project: %w", err)
 	}
 
-	status, err := composeProject.Status()
+	deploying := s.IsDeploying(project.ID)
+	status, err := composeProject.StatusContext(ctx)
 	if err != nil {
 		slog.Error(
 			"Failed to get status",
@@ -864,6 +2014,15 @@ func (s *ProjectService) GetStatus(projectID uuid.UUID) (*docker.ComposeStatus,
 		)
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
+	// Containers seen during a deployment may be half replaced. Checked before and after the query,
+	// so that a deployment that starts or ends while it runs is not missed.
+	if deploying || s.IsDeploying(project.ID) {
+		status.Status = docker.ComposeProjectStatusDeploying
+	}
+	s.statusCache.set(project.ID, status)
+	if status.Status != docker.ComposeProjectStatusDeploying {
+		s.metrics.SetProjectUp(project.ID, project.Name, status.Status == docker.ComposeProjectStatusRunning)
+	}
 	slog.Debug(
 		"Status retrieved successfully",
 		"project_id",
@@ -876,21 +2035,33 @@ func (s *ProjectService) GetStatus(projectID uuid.UUID) (*docker.ComposeStatus,
 	return status, nil
 }
 
-func (s *ProjectService) pullLatestChanges(project *domain.Project) error {
+// pullLatestChanges pulls the project's branch and returns the git output
+func (s *ProjectService) pullLatestChanges(project *domain.Project) (string, error) {
 	slog.Debug("Pulling latest changes", "project_id", project.ID, "git_url", project.GitURL)
 
 	gitDir, err := project.GitDir()
 	if err != nil {
-		return fmt.Errorf("failed to get git directory: %w", err)
+		return "", fmt.Errorf("failed to get git directory: %w", err)
 	}
 
-	if err = s.gitService.Pull(project.GitBranch, project.GitAuth, gitDir); err != nil {
+	// The variables may have changed since the repository was cloned
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return "", err
+	}
+	if err := s.gitService.SetRemoteURL(gitDir, source.URL); err != nil {
+		return "", err
+	}
+
+	output, err := s.gitService.Pull(source.Branch, project.GitAuth, gitDir)
+	if err != nil {
+		err = source.RedactError(err)
 		slog.Error("Failed to pull changes", "project_id", project.ID, "error", err)
-		return fmt.Errorf("failed to pull changes: %w", err)
+		return "", fmt.Errorf("failed to pull changes: %w", err)
 	}
 
 	slog.Debug("Git pull completed", "project_id", project.ID)
-	return nil
+	return source.Redact(output), nil
 }
 
 // ListDeployments lists all deployments for a specific project
@@ -918,6 +2089,170 @@ func (s *ProjectService) ListDeployments(projectID uuid.UUID) ([]*domain.Deploym
 	return deployments, nil
 }
 
+// GetDeployment returns a single deployment
+func (s *ProjectService) GetDeployment(deploymentID uuid.UUID) (*domain.Deployment, error) {
+	deployment, err := s.deploymentRepository.FindByID(deploymentID)
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "get_deployment",
+			"deployment_id", deploymentID,
+			"error", err)
+		return nil, fmt.Errorf("deployment not found: %w", err)
+	}
+	return deployment, nil
+}
+
+// AnnotateDeployment replaces the notes and labels of a deployment of the given project
+func (s *ProjectService) AnnotateDeployment(
+	projectID uuid.UUID,
+	deploymentID uuid.UUID,
+	annotation domain.DeploymentAnnotation,
+) (*domain.Deployment, error) {
+	deployment, err := s.GetDeployment(deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	if deployment.ProjectID != projectID {
+		return nil, fmt.Errorf("deployment %s does not belong to project %s", deploymentID, projectID)
+	}
+
+	deployment.Notes = strings.TrimSpace(annotation.Notes)
+	deployment.Labels = normalizeLabels(annotation.Labels)
+	if err := s.deploymentRepository.Update(deployment); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "annotate_deployment",
+			"project_id", projectID,
+			"deployment_id", deploymentID,
+			"error", err)
+		return nil, fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	slog.Info("Deployment annotated", "project_id", projectID, "deployment_id", deploymentID)
+	return deployment, nil
+}
+
+// normalizeLabels trims labels and drops empty and duplicate ones, keeping the original order
+func normalizeLabels(labels []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+	return normalized
+}
+
+// errDeploymentInterrupted is the error of deployments that did not finish because Oar stopped
+var errDeploymentInterrupted = errors.New("deployment was interrupted before it completed (Oar stopped or crashed)")
+
+// ReconcileInterruptedDeployments marks deployments left in the started state as failed and
+// syncs the affected projects' status from Docker, as well as that of projects left deploying.
+// Deployments only stay in the started state when the process running them died (crash,
+// restart), so this must only be called at startup.
+func (s *ProjectService) ReconcileInterruptedDeployments() error {
+	deployments, err := s.deploymentRepository.ListByStatus(domain.DeploymentStatusStarted)
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "reconcile_interrupted_deployments",
+			"error", err)
+		return fmt.Errorf("failed to list started deployments: %w", err)
+	}
+
+	// No deployment runs yet, so a deploying status was left behind by one that died with Oar
+	projects, err := s.projectRepository.List()
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	affectedProjects := make(map[uuid.UUID]bool)
+	for _, project := range projects {
+		if project.Status == domain.ProjectStatusDeploying {
+			affectedProjects[project.ID] = true
+		}
+	}
+
+	if len(deployments) == 0 && len(affectedProjects) == 0 {
+		slog.Debug("No interrupted deployments found")
+		return nil
+	}
+
+	for _, deployment := range deployments {
+		slog.Warn("Marking interrupted deployment as failed",
+			"deployment_id", deployment.ID,
+			"project_id", deployment.ProjectID,
+			"commit_hash", deployment.CommitHash,
+			"started_at", deployment.CreatedAt)
+
+		deployment.Status = domain.DeploymentStatusFailed
+		if deployment.Stderr != "" {
+			deployment.Stderr += "\n"
+		}
+		deployment.Stderr += fmt.Sprintf("ERROR: %v", errDeploymentInterrupted)
+
+		if err := s.deploymentRepository.Update(deployment); err != nil {
+			slog.Error("Failed to mark interrupted deployment as failed",
+				"deployment_id", deployment.ID,
+				"project_id", deployment.ProjectID,
+				"error", err)
+			continue
+		}
+		affectedProjects[deployment.ProjectID] = true
+
+		// Nobody was told when the deployment died with Oar, so this is the notification of its outcome
+		if project, err := s.Get(deployment.ProjectID); err == nil {
+			s.notifyDeployment(project, deployment, deployment.CommitHash, deployment.CreatedAt,
+				errDeploymentInterrupted)
+		}
+	}
+
+	for projectID := range affectedProjects {
+		project, err := s.Get(projectID)
+		if err != nil {
+			continue
+		}
+
+		// The deployment may have been interrupted at any step, so ask Docker what is actually running
+		status := domain.ProjectStatusUnknown
+		composeStatus, err := s.GetStatus(projectID)
+		if err != nil {
+			slog.Warn("Failed to get Docker status for project with interrupted deployment",
+				"project_id", projectID,
+				"error", err)
+		} else {
+			status = composeStatus.Status.ProjectStatus()
+		}
+
+		if project.Status == status {
+			continue
+		}
+
+		slog.Info("Updating project status after interrupted deployment",
+			"project_id", projectID,
+			"project_name", project.Name,
+			"previous_status", project.Status.String(),
+			"new_status", status.String())
+
+		project.Status = status
+		if err := s.projectRepository.Update(project); err != nil {
+			slog.Error("Failed to update project status after interrupted deployment",
+				"project_id", projectID,
+				"error", err)
+		}
+	}
+
+	slog.Info("Reconciled interrupted deployments",
+		"deployment_count", len(deployments),
+		"project_count", len(affectedProjects))
+
+	return nil
+}
+
 // NewProjectService creates a new ProjectService with dependency injection
 func NewProjectService(
 	projectRepository repository.ProjectRepository,
@@ -930,5 +2265,18 @@ func NewProjectService(
 		deploymentRepository: deploymentRepository,
 		gitService:           gitService,
 		config:               cfg,
+		statusCache:          newStatusCache(),
+		locks:                newProjectLocks(),
+		deploySlots:          newDeploySlots(cfg.ComposeMaxDeploys),
+		deploymentOutputs:    newDeploymentOutputs(),
+		versions:             &versionCache{},
+		diskUsage:            newDiskUsageCache(),
+		notifier:             notify.NewNotifier(notifyTimeout),
+		manualOperations:     newManualOperations(),
 	}
 }
+
+// SetMetrics makes the service record deployments and fetched statuses in the registry
+func (s *ProjectService) SetMetrics(registry *metrics.Registry) {
+	s.metrics = registry
+}
@@ -0,0 +1,95 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestReconcileInterruptedDeployments seeds a deployment stuck in the started state and verifies
+// that reconciliation marks it as failed without touching finished deployments
+func TestReconcileInterruptedDeployments(t *testing.T) {
+	cfg := newTestConfig(t)
+	require.NoError(t, os.MkdirAll(cfg.WorkspaceDir, 0o755))
+	projectService, repos := newTestProjectService(t, cfg)
+
+	// The project has no compose files on disk, so Docker cannot report its status
+	projectID := uuid.New()
+	_, err := repos.projects.Create(&domain.Project{
+		ID:           projectID,
+		Name:         "interrupted-project",
+		GitURL:       "https://example.com/repo.git",
+		GitBranch:    "main",
+		WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-interrupted-project"),
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusRunning,
+	})
+	require.NoError(t, err)
+
+	completed := domain.NewDeployment(projectID, "aaaaaaaaaaaa")
+	completed.Status = domain.DeploymentStatusCompleted
+	require.NoError(t, repos.deployments.Create(&completed))
+
+	stuck := domain.NewDeployment(projectID, "bbbbbbbbbbbb")
+	stuck.Status = domain.DeploymentStatusStarted
+	stuck.Stdout = "Creating containers..."
+	require.NoError(t, repos.deployments.Create(&stuck))
+
+	err = projectService.ReconcileInterruptedDeployments()
+	require.NoError(t, err)
+
+	reconciled, err := repos.deployments.FindByID(stuck.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeploymentStatusFailed, reconciled.Status)
+	assert.Equal(t, "Creating containers...", reconciled.Stdout, "Captured output should be kept")
+	assert.Contains(t, reconciled.Stderr, "interrupted")
+
+	untouched, err := repos.deployments.FindByID(completed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeploymentStatusCompleted, untouched.Status)
+
+	// The stored "running" status can no longer be trusted
+	reconciledProject, err := repos.projects.FindByID(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusUnknown, reconciledProject.Status)
+
+	// Running reconciliation again is a no-op
+	err = projectService.ReconcileInterruptedDeployments()
+	require.NoError(t, err)
+
+	started, err := repos.deployments.ListByStatus(domain.DeploymentStatusStarted)
+	require.NoError(t, err)
+	assert.Empty(t, started)
+}
+
+// TestReconcileProjectLeftDeploying verifies that a deploying status left behind without a started
+// deployment is replaced at startup, so that the project is not shown as deploying forever
+func TestReconcileProjectLeftDeploying(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	// The project has no compose files on disk, so Docker cannot report its status
+	projectID := uuid.New()
+	_, err := repos.projects.Create(&domain.Project{
+		ID:           projectID,
+		Name:         "left-deploying",
+		GitURL:       "https://example.com/repo.git",
+		GitBranch:    "main",
+		WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-left-deploying"),
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusDeploying,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, projectService.ReconcileInterruptedDeployments())
+
+	reconciled, err := repos.projects.FindByID(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusUnknown, reconciled.Status)
+}
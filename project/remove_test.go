@@ -0,0 +1,237 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestRemoveStopOptions removes projects with a stub docker that records its commands, and checks
+// how the containers are stopped before they are taken down
+func TestRemoveStopOptions(t *testing.T) {
+	// Stub docker: ps lists two containers, every call is appended to the log
+	logPath := newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	ps)
+		printf 'c1\nc2\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(t *testing.T) uuid.UUID {
+		projectID := uuid.New()
+		workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-db")
+		require.NoError(t, os.MkdirAll(filepath.Join(workingDir, domain.GitDir), 0o755))
+		_, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         "db-" + projectID.String()[:8],
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   workingDir,
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusRunning,
+		})
+		require.NoError(t, err)
+		return projectID
+	}
+
+	// calls returns the docker commands run since the last call, with the compose flags left out
+	calls := func(t *testing.T) []string {
+		content, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(logPath))
+
+		var commands []string
+		for line := range strings.Lines(string(content)) {
+			line = strings.TrimSpace(line)
+			for _, command := range []string{" ps ", " down "} {
+				if _, rest, ok := strings.Cut(line+" ", command); ok {
+					line = strings.TrimSpace(strings.TrimSpace(command) + " " + rest)
+				}
+			}
+			commands = append(commands, line)
+		}
+		return commands
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		projectID := createProject(t)
+		require.NoError(t, projectService.Remove(projectID, project.RemoveOptions{}))
+
+		// The compose file decides the signal and grace period, and volumes are kept
+		assert.Equal(t, []string{"down --remove-orphans"}, calls(t))
+	})
+
+	t.Run("signal and timeout", func(t *testing.T) {
+		projectID := createProject(t)
+		err := projectService.Remove(projectID, project.RemoveOptions{
+			StopSignal:  "int",
+			StopTimeout: 90500 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		// The containers get the signal and the full grace period before down removes them
+		assert.Equal(t, []string{
+			"ps --quiet",
+			"stop --signal SIGINT --timeout 91 c1 c2",
+			"down --remove-orphans --timeout 91",
+		}, calls(t))
+
+		_, err = projectService.Get(projectID)
+		assert.Error(t, err, "Project should be removed")
+	})
+
+	t.Run("volumes on request", func(t *testing.T) {
+		projectID := createProject(t)
+		require.NoError(t, projectService.Remove(projectID, project.RemoveOptions{RemoveVolumes: true}))
+		assert.Equal(t, []string{"down --remove-orphans --volumes"}, calls(t))
+	})
+
+	t.Run("built images on request", func(t *testing.T) {
+		projectID := createProject(t)
+		require.NoError(t, projectService.Remove(projectID, project.RemoveOptions{RemoveImages: "local"}))
+		assert.Equal(t, []string{"down --remove-orphans --rmi local"}, calls(t))
+	})
+
+	t.Run("all images on request", func(t *testing.T) {
+		projectID := createProject(t)
+		err := projectService.Remove(projectID, project.RemoveOptions{RemoveVolumes: true, RemoveImages: "all"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"down --remove-orphans --volumes --rmi all"}, calls(t))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		projectID := createProject(t)
+
+		err := projectService.Remove(projectID, project.RemoveOptions{RemoveImages: "built"})
+		assert.EqualError(t, err, `invalid image removal "built": must be local or all`)
+
+		err = projectService.Remove(projectID, project.RemoveOptions{StopSignal: "SIGNOPE"})
+		assert.ErrorContains(t, err, "unsupported signal")
+		err = projectService.Remove(projectID, project.RemoveOptions{StopTimeout: -time.Second})
+		assert.ErrorContains(t, err, "must not be negative")
+
+		assert.NoFileExists(t, logPath, "Nothing should be stopped with invalid options")
+		_, err = projectService.Get(projectID)
+		assert.NoError(t, err, "Project should be kept")
+	})
+}
+
+// TestRemoveDuringDeployment checks that a project is not removed while a deployment of it runs
+func TestRemoveDuringDeployment(t *testing.T) {
+	// Stub docker: up waits until the release file exists, everything else succeeds
+	releasePath := filepath.Join(t.TempDir(), "release")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		while [ ! -f "`+releasePath+`" ]; do sleep 0.01; done
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	deployed := make(chan error, 1)
+	go func() {
+		deployed <- projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+	}()
+	require.Eventually(t, func() bool { return projectService.IsDeploying(projectID) }, 5*time.Second, time.Millisecond)
+
+	err = projectService.Remove(projectID, project.RemoveOptions{})
+	assert.ErrorIs(t, err, project.ErrDeploymentInProgress)
+	_, err = projectService.Get(projectID)
+	assert.NoError(t, err, "Project should be kept")
+	assert.DirExists(t, workingDir)
+	assert.False(t, projectService.IsRemoving(projectID))
+
+	require.NoError(t, os.WriteFile(releasePath, nil, 0o644))
+	require.NoError(t, <-deployed, "The deployment should finish undisturbed")
+
+	require.NoError(t, projectService.Remove(projectID, project.RemoveOptions{}))
+	_, err = projectService.Get(projectID)
+	assert.Error(t, err, "Project should be removed once the deployment has finished")
+}
+
+func TestSharedImages(t *testing.T) {
+	// Stub docker: every project uses postgres and an image of its own, named after the project
+	newStubDocker(t, `name=""
+previous=""
+for arg in "$@"; do
+	if [ "$previous" = "--project-name" ]; then
+		name="$arg"
+	fi
+	previous="$arg"
+done
+case "$name" in
+broken) echo "invalid compose file" >&2; exit 1 ;;
+esac
+printf 'postgres:16\n%s:latest\n' "$name"`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	createProject := func(t *testing.T, name string) uuid.UUID {
+		projectID := uuid.New()
+		workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name)
+		require.NoError(t, os.MkdirAll(filepath.Join(workingDir, domain.GitDir), 0o755))
+		_, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   workingDir,
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusStopped,
+		})
+		require.NoError(t, err)
+		return projectID
+	}
+
+	shopID := createProject(t, "shop")
+
+	shared, err := projectService.SharedImages(shopID)
+	require.NoError(t, err)
+	assert.Empty(t, shared, "No image is shared without other projects")
+
+	createProject(t, "blog")
+	createProject(t, "broken")
+
+	// The project whose configuration cannot be read is left out
+	shared, err = projectService.SharedImages(shopID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres:16"}, shared)
+}
@@ -0,0 +1,69 @@
+package project
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// recordRename adds the compose project name a project had before a rename to its previous names.
+// Compose projects are named after their Oar project, so the containers deployed under the old name
+// are not replaced by the next deployment and are looked for until they are gone.
+func recordRename(project *domain.Project, oldName string) {
+	// A project renamed back to an earlier name deploys over the containers of that name again
+	names := slices.DeleteFunc(slices.Clone(project.PreviousNames), func(name string) bool {
+		return name == oldName || name == project.ComposeName
+	})
+	project.PreviousNames = append(names, oldName)
+}
+
+// checkPreviousNames looks for containers left under the previous names of a project. They are removed
+// when remove is set, and reported as a warning otherwise. Names without containers are forgotten; the
+// deployment stores the project when it ends. Failing to check or remove does not fail the deployment.
+func (s *ProjectService) checkPreviousNames(
+	project *domain.Project,
+	remove bool,
+	report func(msg, msgType string),
+) {
+	var kept []string
+	for _, name := range project.PreviousNames {
+		containers, err := docker.ComposeProjectContainers(name, s.config.ComposeQueryTimeout)
+		if err != nil {
+			slog.Warn("Failed to look for containers of a previous project name",
+				"project_id", project.ID,
+				"previous_name", name,
+				"error", err)
+			kept = append(kept, name)
+			continue
+		}
+		if len(containers) == 0 {
+			continue
+		}
+
+		if !remove {
+			report(fmt.Sprintf("Warning: containers of compose project %s, from before the project was renamed, "+
+				"are still present: %s. Deploy with --remove-previous-stacks to remove them",
+				name, strings.Join(containers, ", ")), "stderr")
+			kept = append(kept, name)
+			continue
+		}
+
+		report(fmt.Sprintf("Removing containers of compose project %s, from before the project was renamed: %s",
+			name, strings.Join(containers, ", ")), "info")
+		output, err := docker.RemoveComposeProject(name)
+		for line := range strings.SplitSeq(output, "\n") {
+			if line != "" {
+				report(line, "stdout")
+			}
+		}
+		if err != nil {
+			report(fmt.Sprintf("Warning: %v", err), "stderr")
+			kept = append(kept, name)
+		}
+	}
+	project.PreviousNames = kept
+}
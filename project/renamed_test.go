@@ -0,0 +1,130 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployRenamedProject renames a deployed project, so that its containers are left under the old
+// compose project name, and checks that deployments report them and remove them on request
+func TestDeployRenamedProject(t *testing.T) {
+	stateDir := t.TempDir()
+	removedMarker := filepath.Join(stateDir, "removed")
+	downArgsFile := filepath.Join(stateDir, "down-args")
+	// Stub docker: the containers of compose project shop exist until it is brought down by name
+	newStubDocker(t, `if [ "$1" = "ps" ]; then
+	case "$*" in
+	*com.docker.compose.project=shop\ *)
+		[ -e `+removedMarker+` ] || printf 'shop-web-1\nshop-db-1\n'
+		;;
+	esac
+	exit 0
+fi
+case "$*" in
+*"--project-name shop down"*)
+	echo "$@" > `+downArgsFile+`
+	touch `+removedMarker+`
+	echo "Container shop-web-1 Removed"
+	exit 0
+	;;
+esac
+for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: web\n'
+		exit 0
+		;;
+	up|ps)
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusRunning,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	// rename renames the project and returns it as stored
+	rename := func(t *testing.T, name string) *domain.Project {
+		t.Helper()
+		p, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		p.Name = name
+		require.NoError(t, projectService.Update(p))
+		p, err = projectService.Get(projectID)
+		require.NoError(t, err)
+		return p
+	}
+
+	// deploy deploys the project and returns the stored record of the deployment
+	deploy := func(t *testing.T, removePreviousStacks bool) *domain.Deployment {
+		t.Helper()
+		results, err := projectService.DeployManyPiping([]uuid.UUID{projectID},
+			project.BulkDeployOptions{RemovePreviousStacks: removePreviousStacks})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		return deployments[0]
+	}
+
+	renamed := rename(t, "store")
+	assert.Equal(t, []string{"shop"}, renamed.PreviousNames)
+
+	t.Run("containers of the old name are reported", func(t *testing.T) {
+		deployment := deploy(t, false)
+		assert.Contains(t, deployment.Stderr,
+			"containers of compose project shop, from before the project was renamed, are still present: "+
+				"shop-web-1, shop-db-1")
+		assert.NoFileExists(t, downArgsFile)
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shop"}, stored.PreviousNames)
+	})
+
+	t.Run("containers of the old name are removed on request", func(t *testing.T) {
+		deployment := deploy(t, true)
+		assert.Contains(t, deployment.Stdout, "Removing containers of compose project shop")
+		assert.Contains(t, deployment.Stdout, "Container shop-web-1 Removed")
+
+		content, err := os.ReadFile(downArgsFile)
+		require.NoError(t, err)
+		downArgs := strings.TrimSpace(string(content))
+		assert.True(t, strings.HasSuffix(downArgs, "--project-name shop down --remove-orphans"), downArgs)
+
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Empty(t, stored.PreviousNames, "name kept after its containers were removed")
+	})
+
+	t.Run("renaming back forgets the name in use again", func(t *testing.T) {
+		rename(t, "shop-old")
+		renamed := rename(t, "store")
+		assert.Equal(t, []string{"shop-old"}, renamed.PreviousNames)
+	})
+}
@@ -0,0 +1,55 @@
+package project
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+)
+
+// statusCacheTTL is how long a live Docker status is considered current
+const statusCacheTTL = time.Minute
+
+// statusCache remembers the last live status fetched for each project, so that pages showing
+// aggregates do not have to query Docker for every project
+type statusCache struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID]cachedStatus
+}
+
+type cachedStatus struct {
+	status    *docker.ComposeStatus
+	fetchedAt time.Time
+}
+
+func newStatusCache() *statusCache {
+	return &statusCache{entries: make(map[uuid.UUID]cachedStatus)}
+}
+
+func (c *statusCache) set(projectID uuid.UUID, status *docker.ComposeStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[projectID] = cachedStatus{status: status, fetchedAt: time.Now()}
+}
+
+func (c *statusCache) delete(projectID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, projectID)
+}
+
+// fresh returns the statuses fetched within statusCacheTTL
+func (c *statusCache) fresh() map[uuid.UUID]*docker.ComposeStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cutoff := time.Now().Add(-statusCacheTTL)
+	statuses := make(map[uuid.UUID]*docker.ComposeStatus, len(c.entries))
+	for projectID, entry := range c.entries {
+		if entry.fetchedAt.After(cutoff) {
+			statuses[projectID] = entry.status
+		}
+	}
+	return statuses
+}
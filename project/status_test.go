@@ -0,0 +1,146 @@
+package project_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestGetStatusMany verifies that failing projects are reported individually without failing the batch
+func TestGetStatusMany(t *testing.T) {
+	cfg := newTestConfig(t)
+	require.NoError(t, os.MkdirAll(cfg.WorkspaceDir, 0o755))
+	projectService, repos := newTestProjectService(t, cfg)
+
+	// None of the projects have compose files on disk, so Docker cannot report their status
+	var projectIDs []uuid.UUID
+	for i := range 6 {
+		projectID := uuid.New()
+		name := fmt.Sprintf("status-project-%d", i)
+		_, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       domain.ProjectStatusRunning,
+		})
+		require.NoError(t, err)
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	unknownID := uuid.New()
+	projectIDs = append(projectIDs, unknownID)
+
+	statuses, errs := projectService.GetStatusMany(projectIDs)
+
+	assert.Empty(t, statuses)
+	require.Len(t, errs, len(projectIDs), "Every project should be reported exactly once")
+	for _, projectID := range projectIDs {
+		assert.Error(t, errs[projectID])
+	}
+
+	t.Run("empty input", func(t *testing.T) {
+		statuses, errs := projectService.GetStatusMany(nil)
+		assert.Empty(t, statuses)
+		assert.Empty(t, errs)
+	})
+}
+
+// TestGetStatusDuringDeployment polls the status while a deployment is bringing the project up, and
+// checks that it is reported as deploying rather than from the half replaced containers
+func TestGetStatusDuringDeployment(t *testing.T) {
+	// Stub docker: up waits until released, ps reports no running containers
+	signalDir := t.TempDir()
+	started := filepath.Join(signalDir, "started")
+	release := filepath.Join(signalDir, "release")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		touch `+started+`
+		while [ ! -f `+release+` ]; do sleep 0.05; done
+		exit 0
+		;;
+	ps)
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-web")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "web",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	status, err := projectService.GetStatus(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, docker.ComposeProjectStatusStopped, status.Status)
+
+	deployErr := make(chan error, 1)
+	go func() {
+		deployErr <- projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(started)
+		return err == nil
+	}, 10*time.Second, 10*time.Millisecond)
+
+	assert.True(t, projectService.IsDeploying(projectID))
+	for range 5 {
+		status, err := projectService.GetStatus(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, docker.ComposeProjectStatusDeploying, status.Status)
+	}
+
+	// The stored status shows the deployment, and a copy saved meanwhile does not change it
+	stored, err := projectService.Get(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusDeploying, stored.Status)
+	stored.Status = domain.ProjectStatusStopped
+	require.NoError(t, projectService.Update(stored))
+	stored, err = projectService.Get(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusDeploying, stored.Status)
+
+	require.NoError(t, os.WriteFile(release, nil, 0o644))
+	require.NoError(t, <-deployErr)
+
+	assert.False(t, projectService.IsDeploying(projectID))
+	status, err = projectService.GetStatus(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, docker.ComposeProjectStatusStopped, status.Status)
+
+	// The deployment recorded its result, a copy loaded while it ran does not bring back deploying
+	stored.Status = domain.ProjectStatusDeploying
+	require.NoError(t, projectService.Update(stored))
+	final, err := projectService.Get(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusRunning, final.Status)
+}
@@ -0,0 +1,145 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestStopVariants checks that the plain, streaming and piping stops all control volume removal the
+// same way and leave the project stopped
+func TestStopVariants(t *testing.T) {
+	// Stub docker: records its calls and succeeds
+	callsFile := newStubDocker(t, "")
+
+	cfg := newTestConfig(t)
+	service, repos := newTestProjectService(t, cfg)
+	var projectService project.ProjectManager = service
+
+	stops := map[string]func(projectID uuid.UUID, removeVolumes bool) error{
+		"plain": projectService.Stop,
+		"streaming": func(projectID uuid.UUID, removeVolumes bool) error {
+			output := make(chan docker.StreamMessage, 100)
+			defer close(output)
+			return projectService.StopStreaming(projectID, removeVolumes, output)
+		},
+		"piping": projectService.StopPiping,
+	}
+
+	for name, stop := range stops {
+		for _, removeVolumes := range []bool{false, true} {
+			volumes := "keep volumes"
+			if removeVolumes {
+				volumes = "remove volumes"
+			}
+			t.Run(name+"/"+volumes, func(t *testing.T) {
+				projectID := uuid.New()
+				workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+				initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+				_, err := repos.projects.Create(&domain.Project{
+					ID:           projectID,
+					Name:         "shop-" + projectID.String()[:8],
+					GitURL:       "https://example.com/repo.git",
+					GitBranch:    "main",
+					WorkingDir:   workingDir,
+					ComposeFiles: []string{"compose.yaml"},
+					Status:       domain.ProjectStatusRunning,
+				})
+				require.NoError(t, err)
+				_ = os.Remove(callsFile)
+
+				require.NoError(t, stop(projectID, removeVolumes))
+
+				calls, err := os.ReadFile(callsFile)
+				require.NoError(t, err)
+				var downCall string
+				for call := range strings.Lines(string(calls)) {
+					if strings.Contains(call, " down ") {
+						downCall = call
+					}
+				}
+				require.NotEmpty(t, downCall, "docker compose down should be called")
+				if removeVolumes {
+					assert.Contains(t, downCall, "--volumes")
+				} else {
+					assert.NotContains(t, downCall, "--volumes")
+				}
+
+				stored, err := projectService.Get(projectID)
+				require.NoError(t, err)
+				assert.Equal(t, domain.ProjectStatusStopped, stored.Status)
+			})
+		}
+	}
+}
+
+// TestStopWaitsForDeployment checks that a stop waits for a running deployment of the project, so that
+// the end of the deployment cannot mark the stopped project running again
+func TestStopWaitsForDeployment(t *testing.T) {
+	// Stub docker: up waits until the release file exists, everything else succeeds
+	releasePath := filepath.Join(t.TempDir(), "release")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		while [ ! -f "`+releasePath+`" ]; do sleep 0.01; done
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	deployed := make(chan error, 1)
+	go func() {
+		deployed <- projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+	}()
+	require.Eventually(t, func() bool { return projectService.IsDeploying(projectID) }, 5*time.Second, time.Millisecond)
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- projectService.Stop(projectID, false)
+	}()
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned while the deployment was running: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(releasePath, nil, 0o644))
+	require.NoError(t, <-deployed)
+	require.NoError(t, <-stopped)
+
+	stored, err := projectService.Get(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusStopped, stored.Status)
+}
@@ -0,0 +1,114 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestDeploymentStoredOutput deploys with a stub docker whose up command writes colored output, and
+// checks that the deployment record keeps or strips the colors as configured while the streamed
+// output keeps them
+func TestDeploymentStoredOutput(t *testing.T) {
+	// Stub docker: config prints a minimal configuration, up prints a colored line on stdout
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		printf '\033[32mweb started\033[0m\n'
+		exit 0
+		;;
+	esac
+done`)
+
+	const colored = "\x1b[32mweb started\x1b[0m"
+
+	tests := []struct {
+		name         string
+		storedOutput string
+		wantStdout   string
+	}{
+		{name: "ansi", storedOutput: "ansi", wantStdout: colored},
+		{name: "plain", storedOutput: "plain", wantStdout: "web started"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.ComposeStoredOutput = tt.storedOutput
+			projectService, repos := newTestProjectService(t, cfg)
+
+			projectID := uuid.New()
+			workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-colored")
+			initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+
+			_, err := repos.projects.Create(&domain.Project{
+				ID:             projectID,
+				Name:           "colored",
+				GitURL:         "https://example.com/repo.git",
+				GitBranch:      "main",
+				WorkingDir:     workingDir,
+				ComposeFiles:   []string{"compose.yaml"},
+				Status:         domain.ProjectStatusStopped,
+				SkipVolumeInit: true,
+			})
+			require.NoError(t, err)
+
+			outputChan := make(chan docker.StreamMessage, 100)
+			var streamed []string
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for msg := range outputChan {
+					streamed = append(streamed, msg.Content)
+				}
+			}()
+
+			err = projectService.DeployStreaming(projectID, false, nil, domain.DeploymentAnnotation{}, outputChan)
+			close(outputChan)
+			<-done
+			require.NoError(t, err)
+
+			assert.Contains(t, streamed, colored, "Streamed output should keep colors")
+
+			deployments, err := projectService.ListDeployments(projectID)
+			require.NoError(t, err)
+			require.Len(t, deployments, 1)
+			lines := strings.Split(strings.TrimSpace(deployments[0].Stdout), "\n")
+			assert.Contains(t, lines, tt.wantStdout)
+			if tt.storedOutput == "plain" {
+				assert.NotContains(t, deployments[0].Stdout, "\x1b[")
+			}
+		})
+	}
+}
+
+// initDeployableRepo creates a git repository with a committed compose file
+func initDeployableRepo(t *testing.T, dir string) {
+	repo, err := gogit.PlainInit(dir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}\n"), 0o644))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("compose.yaml")
+	require.NoError(t, err)
+	_, err = worktree.Commit("Initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
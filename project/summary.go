@@ -0,0 +1,70 @@
+package project
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// DashboardSummary holds totals across all projects for the home page
+type DashboardSummary struct {
+	TotalProjects int
+	Running       int // Projects whose stored status is running
+	Stopped       int
+	Error         int
+	Unknown       int
+	Deploying     int
+
+	// LiveRunning counts projects whose containers were recently seen running. It only covers
+	// the LiveChecked projects with a cached status, Docker is not queried to compute it.
+	LiveRunning int
+	LiveChecked int
+
+	DeploymentsToday int
+	LastDeploymentAt *time.Time
+}
+
+// GetDashboardSummary computes project and deployment totals using aggregate queries and
+// the cached live statuses
+func (s *ProjectService) GetDashboardSummary() (*DashboardSummary, error) {
+	counts, err := s.projectRepository.CountByStatus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	deploymentsToday, err := s.deploymentRepository.CountSince(startOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count deployments: %w", err)
+	}
+
+	lastDeploymentAt, err := s.deploymentRepository.LatestCreatedAt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last deployment: %w", err)
+	}
+
+	summary := &DashboardSummary{
+		Running:          counts[domain.ProjectStatusRunning],
+		Stopped:          counts[domain.ProjectStatusStopped],
+		Error:            counts[domain.ProjectStatusError],
+		Unknown:          counts[domain.ProjectStatusUnknown],
+		Deploying:        counts[domain.ProjectStatusDeploying],
+		DeploymentsToday: deploymentsToday,
+		LastDeploymentAt: lastDeploymentAt,
+	}
+	for _, count := range counts {
+		summary.TotalProjects += count
+	}
+
+	for _, status := range s.statusCache.fresh() {
+		summary.LiveChecked++
+		if status.Status == docker.ComposeProjectStatusRunning || status.Status == docker.ComposeProjectStatusDegraded {
+			summary.LiveRunning++
+		}
+	}
+
+	return summary, nil
+}
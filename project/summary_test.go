@@ -0,0 +1,80 @@
+package project_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestGetDashboardSummary seeds projects and deployments and verifies the aggregated totals
+func TestGetDashboardSummary(t *testing.T) {
+	cfg := newTestConfig(t)
+	require.NoError(t, os.MkdirAll(cfg.WorkspaceDir, 0o755))
+	projectService, repos := newTestProjectService(t, cfg)
+
+	t.Run("empty", func(t *testing.T) {
+		summary, err := projectService.GetDashboardSummary()
+		require.NoError(t, err)
+		assert.Equal(t, &project.DashboardSummary{}, summary)
+	})
+
+	statuses := []domain.ProjectStatus{
+		domain.ProjectStatusRunning,
+		domain.ProjectStatusRunning,
+		domain.ProjectStatusStopped,
+		domain.ProjectStatusError,
+	}
+	var projectIDs []uuid.UUID
+	for i, status := range statuses {
+		projectID := uuid.New()
+		name := fmt.Sprintf("summary-project-%d", i)
+		_, err := repos.projects.Create(&domain.Project{
+			ID:           projectID,
+			Name:         name,
+			GitURL:       "https://example.com/repo.git",
+			GitBranch:    "main",
+			WorkingDir:   filepath.Join(cfg.WorkspaceDir, projectID.String()+"-"+name),
+			ComposeFiles: []string{"compose.yaml"},
+			Status:       status,
+		})
+		require.NoError(t, err)
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	yesterday := domain.NewDeployment(projectIDs[0], "aaaaaaaaaaaa")
+	yesterday.Status = domain.DeploymentStatusCompleted
+	yesterday.CreatedAt = time.Now().Add(-48 * time.Hour)
+	require.NoError(t, repos.deployments.Create(&yesterday))
+
+	var latest domain.Deployment
+	for _, projectID := range projectIDs[:2] {
+		latest = domain.NewDeployment(projectID, "bbbbbbbbbbbb")
+		latest.Status = domain.DeploymentStatusCompleted
+		require.NoError(t, repos.deployments.Create(&latest))
+	}
+
+	summary, err := projectService.GetDashboardSummary()
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, summary.TotalProjects)
+	assert.Equal(t, 2, summary.Running)
+	assert.Equal(t, 1, summary.Stopped)
+	assert.Equal(t, 1, summary.Error)
+	assert.Equal(t, 0, summary.Unknown)
+	assert.Equal(t, 2, summary.DeploymentsToday)
+	require.NotNil(t, summary.LastDeploymentAt)
+	assert.WithinDuration(t, latest.CreatedAt, *summary.LastDeploymentAt, time.Second)
+
+	// Without cached live statuses Docker is not queried
+	assert.Equal(t, 0, summary.LiveChecked)
+	assert.Equal(t, 0, summary.LiveRunning)
+}
@@ -0,0 +1,139 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestDeployWithVerboseBuild deploys a project whose image up builds, with a stub docker that prints
+// the build steps unless up is told to keep the build quiet
+func TestDeployWithVerboseBuild(t *testing.T) {
+	callsFile := newStubDocker(t, `case " $* " in
+*" up "*" --no-start"*)
+	case " $* " in
+	*" --quiet-build "*) ;;
+	*)
+		echo "#5 [app 2/3] RUN npm ci" >&2
+		echo "#5 DONE 4.2s" >&2
+		;;
+	esac
+	;;
+esac`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	// upCalls returns the arguments of the up calls of the last deployment
+	upCalls := func(t *testing.T) []string {
+		t.Helper()
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		var ups []string
+		for call := range strings.Lines(string(calls)) {
+			if strings.Contains(call, " up ") {
+				ups = append(ups, strings.TrimSpace(call))
+			}
+		}
+		require.Len(t, ups, 2)
+		return ups
+	}
+	latestDeployment := func(t *testing.T) *domain.Deployment {
+		t.Helper()
+		deployments, err := projectService.ListDeployments(projectID)
+		require.NoError(t, err)
+		return deployments[0]
+	}
+
+	t.Run("quiet by default", func(t *testing.T) {
+		_ = os.Remove(callsFile)
+		require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+
+		for _, call := range upCalls(t) {
+			assert.Contains(t, call, "--quiet-pull --quiet-build")
+			assert.NotContains(t, call, "--build ")
+		}
+		assert.NotContains(t, latestDeployment(t).Stderr, "RUN npm ci")
+	})
+
+	t.Run("build output of the project in the stream and the record", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.VerboseBuild = true
+		stored.BuildOnDeploy = true
+		require.NoError(t, projectService.Update(stored))
+		_ = os.Remove(callsFile)
+
+		outputChan := make(chan docker.StreamMessage, 100)
+		var streamed []string
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for msg := range outputChan {
+				streamed = append(streamed, msg.Content)
+			}
+		}()
+		err = projectService.DeployStreaming(projectID, false, nil, domain.DeploymentAnnotation{}, outputChan)
+		close(outputChan)
+		<-done
+		require.NoError(t, err)
+
+		assert.Contains(t, streamed, "#5 [app 2/3] RUN npm ci")
+		deployment := latestDeployment(t)
+		assert.Equal(t, domain.DeploymentStatusCompleted, deployment.Status)
+		assert.Contains(t, deployment.Stderr, "#5 [app 2/3] RUN npm ci\n#5 DONE 4.2s")
+		assert.Contains(t, deployment.CommandLine, "--quiet-pull --remove-orphans --build")
+
+		// Built when the containers are created, not again when they are started
+		ups := upCalls(t)
+		assert.Contains(t, ups[0], "--quiet-pull --remove-orphans --build --no-start")
+		assert.NotContains(t, ups[1], "--build")
+	})
+
+	t.Run("build output for a single deployment", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		stored.VerboseBuild = false
+		stored.BuildOnDeploy = false
+		require.NoError(t, projectService.Update(stored))
+		_ = os.Remove(callsFile)
+
+		results, err := projectService.DeployManyPiping([]uuid.UUID{projectID}, project.BulkDeployOptions{
+			UpOptions: docker.UpOptions{VerboseBuild: true, VerbosePull: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.NoError(t, results[0].Err)
+
+		assert.NotContains(t, upCalls(t)[0], "--quiet")
+		assert.Contains(t, latestDeployment(t).Stderr, "RUN npm ci")
+
+		stored, err = projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.False(t, stored.VerboseBuild, "a deployment option changed the project")
+	})
+}
@@ -0,0 +1,177 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+)
+
+// ProjectSelector selects projects by ID, or by container labels given as key or key=value
+type ProjectSelector struct {
+	ProjectIDs []uuid.UUID
+	Labels     []string
+}
+
+// VariableChange is what setting a variable does to one project
+type VariableChange struct {
+	Project   *domain.Project
+	Added     bool // The project did not set the variable before
+	Unchanged bool // The project already sets the variable to the value
+}
+
+// PreviewVariableUpdate returns what BulkUpdateVariable would change, without saving anything
+func (s *ProjectService) PreviewVariableUpdate(
+	selector ProjectSelector,
+	key, value string,
+) ([]VariableChange, error) {
+	if err := validateVariable(key, value); err != nil {
+		return nil, err
+	}
+	projects, err := selectProjects(s.projectRepository, selector)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]VariableChange, len(projects))
+	for i, project := range projects {
+		changes[i], _ = setVariable(project, key, value)
+	}
+	return changes, nil
+}
+
+// BulkUpdateVariable sets a variable of all selected projects, e.g. to rotate a token they share. A
+// project that sets the variable gets its lines for it replaced, one that does not gets it appended;
+// its other variables are left as they are. The projects are saved in a single transaction, so either
+// all of them are changed or none. They are not redeployed.
+func (s *ProjectService) BulkUpdateVariable(
+	selector ProjectSelector,
+	key, value string,
+) ([]VariableChange, error) {
+	if err := validateVariable(key, value); err != nil {
+		return nil, err
+	}
+
+	var changes []VariableChange
+	err := repository.Transaction(s.projectRepository, s.deploymentRepository,
+		func(projects repository.ProjectRepository, _ repository.DeploymentRepository) error {
+			// Loaded within the transaction, so that concurrent edits of other variables are kept
+			selected, err := selectProjects(projects, selector)
+			if err != nil {
+				return err
+			}
+			changes = make([]VariableChange, len(selected))
+			for i, project := range selected {
+				var variables []string
+				changes[i], variables = setVariable(project, key, value)
+				if changes[i].Unchanged {
+					continue
+				}
+				project.Variables = variables
+				if err := projects.Update(project); err != nil {
+					return fmt.Errorf("failed to update project %s: %w", project.Name, err)
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "bulk_update_variable",
+			"variable", key,
+			"error", err)
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if change.Unchanged {
+			continue
+		}
+		slog.Info("Project variable updated",
+			"project_id", change.Project.ID,
+			"project_name", change.Project.Name,
+			"variable", key,
+			"added", change.Added)
+	}
+	return changes, nil
+}
+
+// selectProjects returns the projects of a selector from projects, by ID in the order given or by
+// label in dashboard order
+func selectProjects(projects repository.ProjectRepository, selector ProjectSelector) ([]*domain.Project, error) {
+	switch {
+	case len(selector.ProjectIDs) == 0 && len(selector.Labels) == 0:
+		return nil, errors.New("select projects by ID or by label")
+	case len(selector.ProjectIDs) > 0 && len(selector.Labels) > 0:
+		return nil, errors.New("select projects by ID or by label, not both")
+	case len(selector.Labels) > 0:
+		all, err := projects.List()
+		if err != nil {
+			return nil, err
+		}
+		return FilterByLabels(all, selector.Labels)
+	}
+
+	selected := make([]*domain.Project, 0, len(selector.ProjectIDs))
+	for _, projectID := range selector.ProjectIDs {
+		project, err := projects.FindByID(projectID)
+		if err != nil {
+			return nil, fmt.Errorf("project %s not found: %w", projectID, err)
+		}
+		selected = append(selected, project)
+	}
+	return selected, nil
+}
+
+// validateVariable checks a variable set on several projects at once, which must fit on one line
+func validateVariable(key, value string) error {
+	if key == "" || strings.ContainsAny(key, "= \t\n#") {
+		return fmt.Errorf("invalid variable name %q", key)
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("variable value must be a single line")
+	}
+	return docker.ValidateVariables([]string{key + "=" + value})
+}
+
+// setVariable returns the variables of the project with key set to value. Lines of other variables,
+// comments and blank lines are kept in place.
+func setVariable(project *domain.Project, key, value string) (VariableChange, []string) {
+	change := VariableChange{Project: project, Added: true, Unchanged: true}
+	line := key + "=" + value
+	variables := make([]string, 0, len(project.Variables)+1)
+	for _, variable := range project.Variables {
+		if variableKey(variable) != key {
+			variables = append(variables, variable)
+			continue
+		}
+		change.Added = false
+		if variable != line {
+			change.Unchanged = false
+		}
+		variables = append(variables, line)
+	}
+	if change.Added {
+		change.Unchanged = false
+		variables = append(variables, line)
+	}
+	return change, variables
+}
+
+// variableKey returns the name a variable line sets, or an empty string for comments and lines
+// without a value
+func variableKey(variable string) string {
+	line := strings.TrimSpace(variable)
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	key, _, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(key)
+}
@@ -0,0 +1,151 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestBulkUpdateVariable rotates a token shared by three projects of a team, the way oar project
+// set-variable --label does, and checks that nothing else changes
+func TestBulkUpdateVariable(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	create := func(name, team string, variables ...string) uuid.UUID {
+		p, err := repos.projects.Create(&domain.Project{
+			ID:              uuid.New(),
+			Name:            name,
+			GitURL:          "https://example.com/" + name + ".git",
+			GitBranch:       "main",
+			WorkingDir:      filepath.Join(cfg.WorkspaceDir, name),
+			ComposeFiles:    []string{"compose.yaml"},
+			Status:          domain.ProjectStatusRunning,
+			Variables:       variables,
+			ContainerLabels: map[string]string{"team": team},
+		})
+		require.NoError(t, err)
+		return p.ID
+	}
+	shop := create("shop", "shop", "# Registry access", "REGISTRY_TOKEN=old", "LOG_LEVEL=info")
+	cart := create("cart", "shop", "export REGISTRY_TOKEN='old'", "PORT=8080")
+	checkout := create("checkout", "shop", "PORT=9090")
+	blog := create("blog", "blog", "REGISTRY_TOKEN=old")
+
+	variables := func(id uuid.UUID) []string {
+		p, err := projectService.Get(id)
+		require.NoError(t, err)
+		return p.Variables
+	}
+	selector := project.ProjectSelector{Labels: []string{"team=shop"}}
+
+	preview, err := projectService.PreviewVariableUpdate(selector, "REGISTRY_TOKEN", "new")
+	require.NoError(t, err)
+	require.Len(t, preview, 3)
+	assert.Equal(t, []string{"REGISTRY_TOKEN=old"}, variables(blog), "preview saved changes")
+	assert.Equal(t, []string{"PORT=9090"}, variables(checkout), "preview saved changes")
+
+	changes, err := projectService.BulkUpdateVariable(selector, "REGISTRY_TOKEN", "new")
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	added := map[string]bool{}
+	for _, change := range changes {
+		assert.False(t, change.Unchanged, change.Project.Name)
+		added[change.Project.Name] = change.Added
+	}
+	assert.Equal(t, map[string]bool{"shop": false, "cart": false, "checkout": true}, added)
+
+	assert.Equal(t, []string{"# Registry access", "REGISTRY_TOKEN=new", "LOG_LEVEL=info"}, variables(shop))
+	assert.Equal(t, []string{"REGISTRY_TOKEN=new", "PORT=8080"}, variables(cart))
+	assert.Equal(t, []string{"PORT=9090", "REGISTRY_TOKEN=new"}, variables(checkout))
+	assert.Equal(t, []string{"REGISTRY_TOKEN=old"}, variables(blog))
+
+	t.Run("setting the same value again changes nothing", func(t *testing.T) {
+		changes, err := projectService.BulkUpdateVariable(selector, "REGISTRY_TOKEN", "new")
+		require.NoError(t, err)
+		for _, change := range changes {
+			assert.True(t, change.Unchanged, change.Project.Name)
+		}
+	})
+
+	t.Run("invalid variables are refused", func(t *testing.T) {
+		for _, key := range []string{"", "TWO WORDS", "OAR_COMMIT"} {
+			_, err := projectService.BulkUpdateVariable(selector, key, "x")
+			assert.Error(t, err, key)
+		}
+		_, err := projectService.BulkUpdateVariable(selector, "TOKEN", "line\nbreak")
+		assert.Error(t, err)
+	})
+
+	t.Run("a missing project changes none", func(t *testing.T) {
+		_, err := projectService.BulkUpdateVariable(
+			project.ProjectSelector{ProjectIDs: []uuid.UUID{blog, uuid.New()}}, "REGISTRY_TOKEN", "newer")
+		require.Error(t, err)
+		assert.Equal(t, []string{"REGISTRY_TOKEN=old"}, variables(blog))
+	})
+}
+
+// TestBulkUpdateVariableDuringDeployment sets a variable while a deployment of the project runs, and
+// checks that the end of the deployment keeps it
+func TestBulkUpdateVariableDuringDeployment(t *testing.T) {
+	// Stub docker: up waits until the release file exists, everything else succeeds
+	releasePath := filepath.Join(t.TempDir(), "release")
+	newStubDocker(t, `for arg in "$@"; do
+	case "$arg" in
+	config)
+		printf 'services:\n  web:\n    image: busybox\n'
+		exit 0
+		;;
+	up)
+		while [ ! -f "`+releasePath+`" ]; do sleep 0.01; done
+		exit 0
+		;;
+	esac
+done`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		Variables:      []string{"REGISTRY_TOKEN=old"},
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	deployed := make(chan error, 1)
+	go func() {
+		deployed <- projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{})
+	}()
+	require.Eventually(t, func() bool { return projectService.IsDeploying(projectID) }, 5*time.Second, time.Millisecond)
+
+	_, err = projectService.BulkUpdateVariable(
+		project.ProjectSelector{ProjectIDs: []uuid.UUID{projectID}}, "REGISTRY_TOKEN", "new")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(releasePath, nil, 0o644))
+	require.NoError(t, <-deployed)
+
+	p, err := projectService.Get(projectID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProjectStatusRunning, p.Status)
+	assert.NotNil(t, p.LocalCommit, "The deployment should record its commit")
+	assert.Equal(t, []string{"REGISTRY_TOKEN=new"}, p.Variables, "The variable set during the deployment should be kept")
+}
@@ -0,0 +1,39 @@
+package project
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/oar-cd/oar/docker"
+)
+
+// versionCacheTTL is how long the queried Docker and Compose versions are reused by deployments
+const versionCacheTTL = time.Minute
+
+// versionCache remembers the Docker and Compose versions, so that deployments started one after
+// another, e.g. by a bulk deployment, do not each run docker version
+type versionCache struct {
+	mu        sync.Mutex
+	versions  docker.Versions
+	fetchedAt time.Time
+}
+
+// get returns the cached versions, or queries them when they are older than versionCacheTTL. When
+// the query fails, the versions are empty and nothing is cached.
+func (c *versionCache) get(timeout time.Duration) docker.Versions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < versionCacheTTL {
+		return c.versions
+	}
+	versions, err := docker.ToolVersions(timeout)
+	if err != nil {
+		slog.Warn("Failed to get Docker versions for the deployment record", "error", err)
+		return docker.Versions{}
+	}
+	c.versions = versions
+	c.fetchedAt = time.Now()
+	return versions
+}
@@ -0,0 +1,71 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestDeploymentRecordsVersions deploys twice with a stub docker and checks that each deployment
+// records the Docker and Compose versions, which are queried once for both
+func TestDeploymentRecordsVersions(t *testing.T) {
+	queriesFile := filepath.Join(t.TempDir(), "version-queries")
+	newStubDocker(t, `case "$1 $2" in
+"version --format")
+	echo docker >> `+queriesFile+`
+	echo 27.3.1
+	exit 0
+	;;
+"compose version")
+	echo compose >> `+queriesFile+`
+	echo v2.29.7
+	exit 0
+	;;
+esac`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:             projectID,
+		Name:           "shop",
+		GitURL:         "https://example.com/repo.git",
+		GitBranch:      "main",
+		WorkingDir:     workingDir,
+		ComposeFiles:   []string{"compose.yaml"},
+		Status:         domain.ProjectStatusStopped,
+		SkipVolumeInit: true,
+	})
+	require.NoError(t, err)
+
+	for range 2 {
+		require.NoError(t, projectService.DeployPiping(projectID, false, nil, domain.DeploymentAnnotation{}))
+	}
+
+	deployments, err := projectService.ListDeployments(projectID)
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	for _, deployment := range deployments {
+		assert.Equal(t, "27.3.1", deployment.DockerVersion)
+		assert.Equal(t, "2.29.7", deployment.ComposeVersion)
+
+		stored, err := projectService.GetDeployment(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "27.3.1", stored.DockerVersion)
+		assert.Equal(t, "2.29.7", stored.ComposeVersion)
+	}
+
+	queries, err := os.ReadFile(queriesFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker", "compose"}, strings.Fields(string(queries)))
+}
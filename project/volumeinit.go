@@ -0,0 +1,51 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// normalizeVolumeInitPhase validates the project's volume initialization phase, setting the default
+// when it is empty
+func normalizeVolumeInitPhase(project *domain.Project) error {
+	phase, err := domain.ParseVolumeInitPhase(strings.TrimSpace(project.VolumeInitPhase.String()))
+	if err != nil {
+		return err
+	}
+	project.VolumeInitPhase = phase
+	return nil
+}
+
+// initVolumeMountsBeforeCreate prepares the images of the deployment and fixes the ownership of the
+// volume mounts that exist before the containers are created, keeping the output in the deployment
+// record. It reports whether named volumes were left for after creating the containers, since Compose
+// creates them along with the containers.
+func (s *ProjectService) initVolumeMountsBeforeCreate(
+	composeProject *docker.ComposeProject,
+	outputChan chan<- docker.StreamMessage,
+	stdoutBuffer, stderrBuffer *strings.Builder,
+) (bool, error) {
+	outputChan <- docker.StreamMessage{Type: "info", Content: "Preparing images for volume mount initialization..."}
+	// Images with build arguments were built already, see buildImages
+	built := len(composeProject.BuildArgs) > 0
+	err := s.runCaptured(composeProject, outputChan, stdoutBuffer, stderrBuffer,
+		func(outputChan chan<- docker.StreamMessage) error {
+			return composeProject.PrepareImagesForVolumeInit(built, outputChan)
+		})
+	if err != nil {
+		return false, err
+	}
+
+	outputChan <- docker.StreamMessage{
+		Type:    "info",
+		Content: "Initializing volume mounts before creating containers...",
+	}
+	pending, err := composeProject.InitializeVolumeMountsBeforeCreate()
+	if err != nil {
+		return false, fmt.Errorf("failed to initialize volume permissions: %w", err)
+	}
+	return pending, nil
+}
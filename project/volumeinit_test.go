@@ -0,0 +1,88 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// TestVolumeInitBeforeCreate deploys a project that initializes volume mounts before creating the
+// containers, with a stub docker that records its calls
+func TestVolumeInitBeforeCreate(t *testing.T) {
+	callsFile := newStubDocker(t, `case "$*" in
+*" config")
+	printf 'services:\n  web:\n    image: nginx:1.27\n  api:\n    build:\n      context: .\n'
+	;;
+esac`)
+
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	projectID := uuid.New()
+	workingDir := filepath.Join(cfg.WorkspaceDir, projectID.String()+"-shop")
+	initDeployableRepo(t, filepath.Join(workingDir, domain.GitDir))
+	_, err := repos.projects.Create(&domain.Project{
+		ID:              projectID,
+		Name:            "shop",
+		GitURL:          "https://example.com/repo.git",
+		GitBranch:       "main",
+		WorkingDir:      workingDir,
+		ComposeFiles:    []string{"compose.yaml"},
+		Status:          domain.ProjectStatusStopped,
+		VolumeInitPhase: domain.VolumeInitBeforeCreate,
+	})
+	require.NoError(t, err)
+
+	t.Run("images are ready before the containers are created", func(t *testing.T) {
+		output := make(chan docker.StreamMessage, 1000)
+		require.NoError(t, projectService.DeployStreaming(projectID, false, nil, domain.DeploymentAnnotation{}, output))
+		close(output)
+		var messages []string
+		for msg := range output {
+			messages = append(messages, msg.Content)
+		}
+		assert.Contains(t, messages, "Initializing volume mounts before creating containers...")
+		assert.NotContains(t, messages, "Initializing volume mounts...")
+
+		calls, err := os.ReadFile(callsFile)
+		require.NoError(t, err)
+		var order []string
+		for call := range strings.Lines(string(calls)) {
+			for _, command := range []string{" pull ", " build", " up "} {
+				if strings.Contains(call, command) {
+					order = append(order, strings.TrimSpace(call[strings.Index(call, command):]))
+				}
+			}
+		}
+		assert.Equal(t, []string{
+			"pull --ignore-buildable --policy missing",
+			"build",
+			"up --detach --quiet-pull --quiet-build --remove-orphans --no-start",
+			"up --detach --quiet-pull --quiet-build --remove-orphans",
+		}, order)
+	})
+
+	t.Run("invalid phase", func(t *testing.T) {
+		stored, err := projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.VolumeInitBeforeCreate, stored.VolumeInitPhase)
+
+		stored.VolumeInitPhase = "before-start"
+		assert.EqualError(t, projectService.Update(stored),
+			`invalid volume initialization phase "before-start": use after-create or before-create`)
+
+		stored.VolumeInitPhase = ""
+		require.NoError(t, projectService.Update(stored))
+		stored, err = projectService.Get(projectID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.VolumeInitAfterCreate, stored.VolumeInitPhase)
+	})
+}
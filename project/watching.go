@@ -0,0 +1,29 @@
+package project
+
+import (
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// SetWatcherDisabled sets whether the watcher leaves the projects alone. The watcher neither checks
+// nor deploys a disabled project on its polls or for webhooks; deployments and checks started by hand
+// still run. A project that does not exist is ignored.
+func (s *ProjectService) SetWatcherDisabled(projectIDs []uuid.UUID, disabled bool) error {
+	if len(projectIDs) == 0 {
+		return nil
+	}
+	if err := s.projectRepository.SetWatcherDisabled(projectIDs, disabled); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "service",
+			"operation", "set_watcher_disabled",
+			"project_ids", projectIDs,
+			"disabled", disabled,
+			"error", err)
+		return err
+	}
+	slog.Info("Changed watcher of projects",
+		"project_ids", projectIDs,
+		"disabled", disabled)
+	return nil
+}
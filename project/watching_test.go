@@ -0,0 +1,78 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+)
+
+// TestSetWatcherDisabledByLabel disables the watcher for the projects matching a label selector, the way
+// oar watcher disable --label does
+func TestSetWatcherDisabledByLabel(t *testing.T) {
+	cfg := newTestConfig(t)
+	projectService, repos := newTestProjectService(t, cfg)
+
+	create := func(name, env string) uuid.UUID {
+		p, err := repos.projects.Create(&domain.Project{
+			ID:              uuid.New(),
+			Name:            name,
+			GitURL:          "https://example.com/" + name + ".git",
+			GitBranch:       "main",
+			WorkingDir:      filepath.Join(cfg.WorkspaceDir, name),
+			ComposeFiles:    []string{"compose.yaml"},
+			Status:          domain.ProjectStatusRunning,
+			ContainerLabels: map[string]string{"env": env},
+		})
+		require.NoError(t, err)
+		return p.ID
+	}
+	shopStaging := create("shop-staging", "staging")
+	blogStaging := create("blog-staging", "staging")
+	shop := create("shop", "production")
+
+	setByLabel := func(selector string, disabled bool) {
+		projects, err := projectService.List()
+		require.NoError(t, err)
+		matching, err := project.FilterByLabels(projects, []string{selector})
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, p := range matching {
+			ids = append(ids, p.ID)
+		}
+		require.NoError(t, projectService.SetWatcherDisabled(ids, disabled))
+	}
+	watcherDisabled := func(id uuid.UUID) bool {
+		p, err := projectService.Get(id)
+		require.NoError(t, err)
+		return p.WatcherDisabled
+	}
+
+	setByLabel("env=staging", true)
+	assert.True(t, watcherDisabled(shopStaging))
+	assert.True(t, watcherDisabled(blogStaging))
+	assert.False(t, watcherDisabled(shop))
+
+	setByLabel("env=staging", false)
+	assert.False(t, watcherDisabled(shopStaging))
+	assert.False(t, watcherDisabled(blogStaging))
+	assert.False(t, watcherDisabled(shop))
+
+	t.Run("saving a copy loaded before disabling keeps it disabled", func(t *testing.T) {
+		// The watcher saves the project it loaded at the start of its check, e.g. with the remote commit
+		stale, err := projectService.Get(shop)
+		require.NoError(t, err)
+		require.NoError(t, projectService.SetWatcherDisabled([]uuid.UUID{shop}, true))
+
+		remoteCommit := "abc123"
+		stale.RemoteCommit = &remoteCommit
+		require.NoError(t, repos.projects.Update(stale))
+
+		assert.True(t, watcherDisabled(shop))
+	})
+}
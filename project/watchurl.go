@@ -0,0 +1,38 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oar-cd/oar/domain"
+)
+
+// normalizeWatchURL trims the watch URL of the project. A watch URL that is the git URL is cleared, new
+// commits are then detected where the project is pulled from.
+func normalizeWatchURL(project *domain.Project) error {
+	project.WatchURL = strings.TrimSpace(project.WatchURL)
+	if project.WatchURL == strings.TrimSpace(project.GitURL) {
+		project.WatchURL = ""
+	}
+	if project.WatchURL != "" && !project.UsesGit() {
+		return fmt.Errorf("watch URL is only used by projects deployed from git")
+	}
+	return nil
+}
+
+// checkWatchURL returns an error when the project's branch does not exist on its watch URL. The watch URL
+// uses the project's git credentials, like the git URL.
+func (s *ProjectService) checkWatchURL(project *domain.Project) error {
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return err
+	}
+	exists, err := s.gitService.BranchExists(source.WatchURL, source.Branch, project.GitAuth)
+	if err != nil {
+		return fmt.Errorf("failed to check branch '%s' on the watch URL: %w", source.Branch, source.RedactError(err))
+	}
+	if !exists {
+		return fmt.Errorf("branch '%s' not found on the watch URL", source.Branch)
+	}
+	return nil
+}
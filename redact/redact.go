@@ -0,0 +1,59 @@
+// Package redact hides secret values, such as credentials and the values of secret variables, in
+// text and error messages shown to users or written to logs.
+package redact
+
+import (
+	"slices"
+	"strings"
+)
+
+// MinLength is the length below which values are not redacted: a short value would match too many
+// unrelated places and its redaction would hint at the value
+const MinLength = 4
+
+// NewReplacer returns a replacer that replaces each key of replacements with its value wherever it
+// appears. Keys shorter than MinLength are left alone.
+func NewReplacer(replacements map[string]string) *strings.Replacer {
+	values := make([]string, 0, len(replacements))
+	for value := range replacements {
+		if len(value) >= MinLength {
+			values = append(values, value)
+		}
+	}
+	// Longer values first, so that a value containing another one is replaced as a whole
+	slices.SortFunc(values, func(a, b string) int {
+		if n := len(b) - len(a); n != 0 {
+			return n
+		}
+		return strings.Compare(a, b)
+	})
+
+	pairs := make([]string, 0, 2*len(values))
+	for _, value := range values {
+		pairs = append(pairs, value, replacements[value])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Error returns err with its message passed through redact. The result still wraps err; err is
+// returned as is when there is nothing to redact.
+func Error(err error, redact func(string) string) error {
+	if err == nil {
+		return nil
+	}
+	msg := redact(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError is an error whose message was redacted
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
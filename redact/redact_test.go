@@ -0,0 +1,39 @@
+package redact_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/oar-cd/oar/redact"
+)
+
+func TestNewReplacer(t *testing.T) {
+	replacer := redact.NewReplacer(map[string]string{
+		"hunter2":       "***",
+		"hunter2-admin": "[admin]",
+		"abc":           "***",
+	})
+
+	assert.Equal(t, "login [admin] then ***, key abc",
+		replacer.Replace("login hunter2-admin then hunter2, key abc"),
+		"Longer values are replaced whole, short ones are left alone")
+	assert.Equal(t, "hunter2", redact.NewReplacer(nil).Replace("hunter2"))
+}
+
+func TestError(t *testing.T) {
+	mask := strings.NewReplacer("secret", "***").Replace
+
+	assert.NoError(t, redact.Error(nil, mask))
+
+	unchanged := errors.New("nothing to hide")
+	assert.Same(t, unchanged, redact.Error(unchanged, mask))
+
+	cause := errors.New("denied")
+	err := redact.Error(fmt.Errorf("login with secret: %w", cause), mask)
+	assert.EqualError(t, err, "login with ***: denied")
+	assert.ErrorIs(t, err, cause, "The redacted error still wraps the original")
+}
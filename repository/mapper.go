@@ -2,13 +2,24 @@
 package repository
 
 import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 
+	"github.com/google/uuid"
+
 	"github.com/oar-cd/oar/db"
 	"github.com/oar-cd/oar/domain"
 	"github.com/oar-cd/oar/encryption"
 )
 
+// Placeholders stored in the git columns of archive projects, which have no repository
+const (
+	archiveGitURL    = "archive"
+	archiveGitBranch = "-"
+)
+
 type ProjectMapper struct {
 	encryption *encryption.EncryptionService
 }
@@ -41,22 +52,103 @@ func (m *ProjectMapper) ToDomain(p *db.ProjectModel) *domain.Project {
 		}
 	}
 
+	// Email settings hold SMTP credentials, so they are stored encrypted as well
+	var notifyEmail *domain.EmailNotifyConfig
+	if p.NotifyEmail != nil && m.encryption != nil {
+		decryptedEmail, err := m.encryption.DecryptEmailNotifyConfig(*p.NotifyEmail)
+		if err != nil {
+			slog.Error("Failed to decrypt email notification settings",
+				"project_id", p.ID,
+				"project_name", p.Name,
+				"error", err)
+		} else {
+			notifyEmail = decryptedEmail
+		}
+	}
+
+	// A webhook secret that cannot be decrypted is replaced by a random one, so that push webhooks are
+	// refused rather than accepted unsigned until the secret is set again
+	var webhookSecret string
+	if p.WebhookSecret != nil && m.encryption != nil {
+		decryptedSecret, err := m.encryption.Decrypt(*p.WebhookSecret)
+		if err != nil {
+			slog.Error("Failed to decrypt webhook secret",
+				"project_id", p.ID,
+				"project_name", p.Name,
+				"error", err)
+			webhookSecret = rand.Text()
+		} else {
+			webhookSecret = decryptedSecret
+		}
+	}
+
+	// Build secrets are stored encrypted, like the other credentials
+	var buildSecrets map[string]string
+	if p.BuildSecrets != nil && m.encryption != nil {
+		decryptedSecrets, err := m.encryption.DecryptBuildSecrets(*p.BuildSecrets)
+		if err != nil {
+			slog.Error("Failed to decrypt build secrets",
+				"project_id", p.ID,
+				"project_name", p.Name,
+				"error", err)
+		} else {
+			buildSecrets = decryptedSecrets
+		}
+	}
+
+	source := domain.ProjectSource(p.Source)
+	gitURL, gitBranch := p.GitURL, p.GitBranch
+	if source == domain.ProjectSourceArchive {
+		gitURL, gitBranch = "", ""
+	}
+
 	return &domain.Project{
-		ID:                p.ID,
-		Name:              p.Name,
-		GitURL:            p.GitURL,
-		GitBranch:         p.GitBranch,
-		GitAuth:           gitAuth,
-		WorkingDir:        p.WorkingDir,
-		ComposeFiles:      parseFiles(p.ComposeFiles),
-		ComposeOverride:   p.ComposeOverride,
-		Variables:         parseFiles(p.Variables),
-		Status:            status,
-		LocalCommit:       p.LocalCommit,
-		RemoteCommit:      p.RemoteCommit,
-		AutoDeployEnabled: p.AutoDeployEnabled,
-		CreatedAt:         p.CreatedAt,
-		UpdatedAt:         p.UpdatedAt,
+		ID:                    p.ID,
+		Name:                  p.Name,
+		ComposeName:           p.ComposeName,
+		Source:                source,
+		GitURL:                gitURL,
+		GitBranch:             gitBranch,
+		GitBranchDetected:     p.GitBranchDetected,
+		WatchURL:              p.WatchURL,
+		GitAuth:               gitAuth,
+		WorkingDir:            p.WorkingDir,
+		ComposeFiles:          parseFiles(p.ComposeFiles),
+		DisabledComposeFiles:  parseFiles(p.DisabledComposeFiles),
+		PreviousNames:         parseFiles(p.PreviousNames),
+		ComposeOverride:       p.ComposeOverride,
+		EnvFiles:              parseFiles(p.EnvFiles),
+		Variables:             parseFiles(p.Variables),
+		BuildArgs:             parseKeyValues(p.BuildArgs),
+		BuildSecrets:          buildSecrets,
+		ContainerLabels:       parseKeyValues(p.ContainerLabels),
+		DeployHooks:           parseDeployHooks(p.DeployHooks),
+		Status:                status,
+		LocalCommit:           p.LocalCommit,
+		RemoteCommit:          p.RemoteCommit,
+		AutoDeployEnabled:     p.AutoDeployEnabled,
+		AutoDeployPullImages:  p.AutoDeployPullImages,
+		AutoDeploySkipGitPull: p.AutoDeploySkipGitPull,
+		WatcherDisabled:       p.WatcherDisabled,
+		WebhookSecret:         webhookSecret,
+		SkipVolumeInit:        p.SkipVolumeInit,
+		VolumeInitPhase:       domain.VolumeInitPhase(p.VolumeInitPhase),
+		DependsOn:             parseProjectIDs(p.DependsOn),
+		ExternalNetworks:      parseFiles(p.ExternalNetworks),
+		RegistryMirror:        p.RegistryMirror,
+		OfflineImages:         p.OfflineImages,
+		PinImageDigests:       p.PinImageDigests,
+		ImageDigests:          parseFiles(p.ImageDigests),
+		VerboseBuild:          p.VerboseBuild,
+		VerbosePull:           p.VerbosePull,
+		BuildOnDeploy:         p.BuildOnDeploy,
+		NotifyWebhookURL:      p.NotifyWebhookURL,
+		NotifyChannel:         p.NotifyChannel,
+		NotifyTemplate:        p.NotifyTemplate,
+		NotifyEmail:           notifyEmail,
+		SortOrder:             p.SortOrder,
+		CreatedAt:             p.CreatedAt,
+		UpdatedAt:             p.UpdatedAt,
 	}
 }
 
@@ -67,17 +159,85 @@ func (m *ProjectMapper) ToModel(p *domain.Project) *db.ProjectModel {
 			CreatedAt: p.CreatedAt,
 			UpdatedAt: p.UpdatedAt,
 		},
-		Name:              p.Name,
-		GitURL:            p.GitURL,
-		GitBranch:         p.GitBranch,
-		WorkingDir:        p.WorkingDir,
-		ComposeFiles:      serializeFiles(p.ComposeFiles),
-		ComposeOverride:   p.ComposeOverride,
-		Variables:         serializeFiles(p.Variables),
-		Status:            p.Status.String(),
-		LocalCommit:       p.LocalCommit,
-		RemoteCommit:      p.RemoteCommit,
-		AutoDeployEnabled: p.AutoDeployEnabled,
+		Name:                  p.Name,
+		ComposeName:           p.ComposeName,
+		Source:                domain.ProjectSourceGit.String(),
+		GitURL:                p.GitURL,
+		GitBranch:             p.GitBranch,
+		GitBranchDetected:     p.GitBranchDetected,
+		WatchURL:              p.WatchURL,
+		WorkingDir:            p.WorkingDir,
+		ComposeFiles:          serializeFiles(p.ComposeFiles),
+		DisabledComposeFiles:  serializeFiles(p.DisabledComposeFiles),
+		PreviousNames:         serializeFiles(p.PreviousNames),
+		ComposeOverride:       p.ComposeOverride,
+		EnvFiles:              serializeFiles(p.EnvFiles),
+		Variables:             serializeFiles(p.Variables),
+		BuildArgs:             serializeKeyValues(p.BuildArgs),
+		ContainerLabels:       serializeKeyValues(p.ContainerLabels),
+		DeployHooks:           serializeDeployHooks(p.DeployHooks),
+		Status:                p.Status.String(),
+		LocalCommit:           p.LocalCommit,
+		RemoteCommit:          p.RemoteCommit,
+		AutoDeployEnabled:     p.AutoDeployEnabled,
+		AutoDeployPullImages:  p.AutoDeployPullImages,
+		AutoDeploySkipGitPull: p.AutoDeploySkipGitPull,
+		WatcherDisabled:       p.WatcherDisabled,
+		SkipVolumeInit:        p.SkipVolumeInit,
+		VolumeInitPhase:       p.VolumeInitPhase.String(),
+		DependsOn:             serializeProjectIDs(p.DependsOn),
+		ExternalNetworks:      serializeFiles(p.ExternalNetworks),
+		RegistryMirror:        p.RegistryMirror,
+		OfflineImages:         p.OfflineImages,
+		PinImageDigests:       p.PinImageDigests,
+		ImageDigests:          serializeFiles(p.ImageDigests),
+		VerboseBuild:          p.VerboseBuild,
+		VerbosePull:           p.VerbosePull,
+		BuildOnDeploy:         p.BuildOnDeploy,
+		NotifyWebhookURL:      p.NotifyWebhookURL,
+		NotifyChannel:         p.NotifyChannel,
+		NotifyTemplate:        p.NotifyTemplate,
+		SortOrder:             p.SortOrder,
+	}
+
+	// Archive projects have no repository. The git columns must not be empty, so they hold placeholders.
+	if !p.UsesGit() {
+		modelObj.Source = domain.ProjectSourceArchive.String()
+		modelObj.GitURL = archiveGitURL
+		modelObj.GitBranch = archiveGitBranch
+	}
+
+	// Encrypt email settings if present
+	if p.NotifyEmail != nil && m.encryption != nil {
+		encryptedEmail, err := m.encryption.EncryptEmailNotifyConfig(p.NotifyEmail)
+		if err != nil {
+			// Never store SMTP credentials unencrypted
+			slog.Error("Failed to encrypt email notification settings", "project_id", p.ID, "error", err)
+		} else if encryptedEmail != "" {
+			modelObj.NotifyEmail = &encryptedEmail
+		}
+	}
+
+	// Encrypt the webhook secret if present
+	if p.WebhookSecret != "" && m.encryption != nil {
+		encryptedSecret, err := m.encryption.Encrypt(p.WebhookSecret)
+		if err != nil {
+			// Never store the webhook secret unencrypted
+			slog.Error("Failed to encrypt webhook secret", "project_id", p.ID, "error", err)
+		} else {
+			modelObj.WebhookSecret = &encryptedSecret
+		}
+	}
+
+	// Encrypt build secrets if present
+	if len(p.BuildSecrets) > 0 && m.encryption != nil {
+		encryptedSecrets, err := m.encryption.EncryptBuildSecrets(p.BuildSecrets)
+		if err != nil {
+			// Never store build secrets unencrypted
+			slog.Error("Failed to encrypt build secrets", "project_id", p.ID, "error", err)
+		} else if encryptedSecrets != "" {
+			modelObj.BuildSecrets = &encryptedSecrets
+		}
 	}
 
 	// Encrypt authentication data if present
@@ -98,7 +258,11 @@ func (m *ProjectMapper) ToModel(p *domain.Project) *db.ProjectModel {
 	return modelObj
 }
 
-type DeploymentMapper struct{}
+type DeploymentMapper struct {
+	// compressOutput stores Stdout and Stderr gzip-compressed. Compressed output is read back
+	// whatever the setting.
+	compressOutput bool
+}
 
 func (m *DeploymentMapper) ToDomain(d *db.DeploymentModel) *domain.Deployment {
 	status, err := domain.ParseDeploymentStatus(d.Status)
@@ -107,14 +271,23 @@ func (m *DeploymentMapper) ToDomain(d *db.DeploymentModel) *domain.Deployment {
 	}
 
 	return &domain.Deployment{
-		ID:         d.ID,
-		ProjectID:  d.ProjectID,
-		CommitHash: d.CommitHash,
-		Status:     status,
-		Stdout:     d.Stdout,
-		Stderr:     d.Stderr,
-		CreatedAt:  d.CreatedAt,
-		UpdatedAt:  d.UpdatedAt,
+		ID:             d.ID,
+		ProjectID:      d.ProjectID,
+		CommitHash:     d.CommitHash,
+		PreviousCommit: d.PreviousCommit,
+		Status:         status,
+		Stdout:         m.readOutput(d.ID, d.Stdout),
+		Stderr:         m.readOutput(d.ID, d.Stderr),
+		CommandLine:    d.CommandLine,
+		Notes:          d.Notes,
+		Labels:         parseFiles(d.Labels),
+		FailedServices: parseServiceFailures(d.FailedServices),
+		DockerVersion:  d.DockerVersion,
+		ComposeVersion: d.ComposeVersion,
+		ImageDigests:   parseFiles(d.ImageDigests),
+		Summary:        d.Summary,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
 	}
 }
 
@@ -125,10 +298,213 @@ func (m *DeploymentMapper) ToModel(d *domain.Deployment) *db.DeploymentModel {
 			CreatedAt: d.CreatedAt,
 			UpdatedAt: d.UpdatedAt,
 		},
-		ProjectID:  d.ProjectID,
-		CommitHash: d.CommitHash,
-		Status:     d.Status.String(),
-		Stdout:     d.Stdout,
-		Stderr:     d.Stderr,
+		ProjectID:      d.ProjectID,
+		CommitHash:     d.CommitHash,
+		PreviousCommit: d.PreviousCommit,
+		Status:         d.Status.String(),
+		Stdout:         m.storedOutput(d.ID, d.Stdout),
+		Stderr:         m.storedOutput(d.ID, d.Stderr),
+		CommandLine:    d.CommandLine,
+		Notes:          d.Notes,
+		Labels:         serializeFiles(d.Labels),
+		FailedServices: serializeServiceFailures(d.FailedServices),
+		DockerVersion:  d.DockerVersion,
+		ComposeVersion: d.ComposeVersion,
+		ImageDigests:   serializeFiles(d.ImageDigests),
+		Summary:        d.Summary,
+	}
+}
+
+// storedOutput returns deployment output as it is stored, compressed when compression is on. Output
+// that cannot be compressed is stored as is.
+func (m *DeploymentMapper) storedOutput(deploymentID uuid.UUID, output string) string {
+	if !m.compressOutput {
+		return output
+	}
+	compressed, err := compressOutput(output)
+	if err != nil {
+		slog.Warn("Failed to compress deployment output", "deployment_id", deploymentID, "error", err)
+		return output
+	}
+	return compressed
+}
+
+// readOutput returns stored deployment output as it was written
+func (m *DeploymentMapper) readOutput(deploymentID uuid.UUID, stored string) string {
+	output, err := decompressOutput(stored)
+	if err != nil {
+		slog.Error("Failed to read stored deployment output", "deployment_id", deploymentID, "error", err)
+		return fmt.Sprintf("(stored output could not be read: %v)", err)
+	}
+	return output
+}
+
+// serviceFailureRecord is how a failed service is stored with its deployment
+type serviceFailureRecord struct {
+	Service  string   `json:"service"`
+	State    string   `json:"state,omitempty"`
+	ExitCode int      `json:"exit_code"`
+	LastLogs []string `json:"last_logs,omitempty"`
+}
+
+// serializeServiceFailures encodes failed services as JSON, or as an empty string when there are none
+func serializeServiceFailures(failures []domain.ServiceFailure) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	records := make([]serviceFailureRecord, len(failures))
+	for i, failure := range failures {
+		records[i] = serviceFailureRecord(failure)
+	}
+	encoded, err := json.Marshal(records)
+	if err != nil {
+		slog.Warn("Failed to encode failed services", "error", err)
+		return ""
+	}
+	return string(encoded)
+}
+
+// parseServiceFailures decodes failed services stored by serializeServiceFailures
+func parseServiceFailures(encoded string) []domain.ServiceFailure {
+	if encoded == "" {
+		return nil
+	}
+	var records []serviceFailureRecord
+	if err := json.Unmarshal([]byte(encoded), &records); err != nil {
+		slog.Warn("Failed to decode failed services", "error", err)
+		return nil
+	}
+	failures := make([]domain.ServiceFailure, len(records))
+	for i, record := range records {
+		failures[i] = domain.ServiceFailure(record)
+	}
+	return failures
+}
+
+// deployHookRecord is how a deploy hook is stored with its project
+type deployHookRecord struct {
+	Phase   string `json:"phase"`
+	Service string `json:"service"`
+	Command string `json:"command,omitempty"`
+}
+
+// serializeDeployHooks encodes deploy hooks as JSON, or as an empty string when there are none
+func serializeDeployHooks(hooks []domain.DeployHook) string {
+	if len(hooks) == 0 {
+		return ""
+	}
+	records := make([]deployHookRecord, len(hooks))
+	for i, hook := range hooks {
+		records[i] = deployHookRecord{Phase: string(hook.Phase), Service: hook.Service, Command: hook.Command}
+	}
+	encoded, err := json.Marshal(records)
+	if err != nil {
+		slog.Warn("Failed to encode deploy hooks", "error", err)
+		return ""
+	}
+	return string(encoded)
+}
+
+// parseDeployHooks decodes deploy hooks stored by serializeDeployHooks
+func parseDeployHooks(encoded string) []domain.DeployHook {
+	if encoded == "" {
+		return nil
+	}
+	var records []deployHookRecord
+	if err := json.Unmarshal([]byte(encoded), &records); err != nil {
+		slog.Warn("Failed to decode deploy hooks", "error", err)
+		return nil
+	}
+	hooks := make([]domain.DeployHook, len(records))
+	for i, record := range records {
+		hooks[i] = domain.DeployHook{
+			Phase:   domain.HookPhase(record.Phase),
+			Service: record.Service,
+			Command: record.Command,
+		}
+	}
+	return hooks
+}
+
+type WatcherCheckMapper struct{}
+
+func (m *WatcherCheckMapper) ToDomain(c *db.WatcherCheckModel) *domain.WatcherCheck {
+	return &domain.WatcherCheck{
+		ProjectID:           c.ProjectID,
+		ProjectName:         c.Project.Name,
+		CheckedAt:           c.CheckedAt,
+		Outcome:             c.Outcome,
+		Error:               c.Error,
+		ConsecutiveFailures: c.ConsecutiveFailures,
+		Manual:              c.Manual,
+	}
+}
+
+func (m *WatcherCheckMapper) ToModel(c *domain.WatcherCheck) *db.WatcherCheckModel {
+	return &db.WatcherCheckModel{
+		ProjectID:           c.ProjectID,
+		CheckedAt:           c.CheckedAt,
+		Outcome:             c.Outcome,
+		Error:               c.Error,
+		ConsecutiveFailures: c.ConsecutiveFailures,
+		Manual:              c.Manual,
+	}
+}
+
+type ShareLinkMapper struct{}
+
+func (m *ShareLinkMapper) ToDomain(l *db.ShareLinkModel) *domain.ShareLink {
+	return &domain.ShareLink{
+		ID:        l.ID,
+		ProjectID: l.ProjectID,
+		ExpiresAt: l.ExpiresAt,
+		RevokedAt: l.RevokedAt,
+		CreatedAt: l.CreatedAt,
+	}
+}
+
+func (m *ShareLinkMapper) ToModel(l *domain.ShareLink) *db.ShareLinkModel {
+	return &db.ShareLinkModel{
+		BaseModel: db.BaseModel{
+			ID:        l.ID,
+			CreatedAt: l.CreatedAt,
+		},
+		ProjectID: l.ProjectID,
+		ExpiresAt: l.ExpiresAt,
+		RevokedAt: l.RevokedAt,
+	}
+}
+
+type APITokenMapper struct{}
+
+func (m *APITokenMapper) ToDomain(t *db.APITokenModel) *domain.APIToken {
+	actions := []domain.APITokenAction{}
+	for _, action := range parseFiles(t.Actions) {
+		actions = append(actions, domain.APITokenAction(action))
+	}
+	return &domain.APIToken{
+		ID:        t.ID,
+		Name:      t.Name,
+		ProjectID: t.ProjectID,
+		Actions:   actions,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+// ToModel returns the model of the token, which stores the hash of the token in place of the token
+func (m *APITokenMapper) ToModel(t *domain.APIToken, tokenHash string) *db.APITokenModel {
+	actions := make([]string, len(t.Actions))
+	for i, action := range t.Actions {
+		actions[i] = string(action)
+	}
+	return &db.APITokenModel{
+		BaseModel: db.BaseModel{
+			ID:        t.ID,
+			CreatedAt: t.CreatedAt,
+		},
+		Name:      t.Name,
+		TokenHash: tokenHash,
+		ProjectID: t.ProjectID,
+		Actions:   serializeFiles(actions),
 	}
 }
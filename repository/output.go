@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compressedOutputPrefix marks deployment output stored gzip-compressed and base64-encoded. Output
+// stored without compression, including rows written before compression existed, has no prefix and
+// is read as is. Deployment output never starts with a null character, so the prefix cannot be
+// mistaken for output.
+const compressedOutputPrefix = "\x00gzip:"
+
+// compressOutput returns output compressed for storage. Output that does not get smaller, such as
+// empty or very short output, is stored as is.
+func compressOutput(output string) (string, error) {
+	if output == "" {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(output)); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+
+	compressed := compressedOutputPrefix + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(compressed) >= len(output) {
+		return output, nil
+	}
+	return compressed, nil
+}
+
+// decompressOutput returns stored output as it was written, whether or not it was compressed
+func decompressOutput(stored string) (string, error) {
+	encoded, compressed := strings.CutPrefix(stored, compressedOutputPrefix)
+	if !compressed {
+		return stored, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode compressed output: %w", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress output: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	output, err := io.ReadAll(gz)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress output: %w", err)
+	}
+	return string(output), nil
+}
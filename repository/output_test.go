@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+	return database
+}
+
+// sampleDeployOutput resembles the output of a deployment that builds and starts a few services
+func sampleDeployOutput(lines int) string {
+	var b strings.Builder
+	services := []string{"web", "worker", "db", "cache"}
+	for i := range lines {
+		service := services[i%len(services)]
+		switch i % 5 {
+		case 0:
+			fmt.Fprintf(&b, " Container shop-%s-1  Recreate\n", service)
+		case 1:
+			fmt.Fprintf(&b, " Container shop-%s-1  Recreated\n", service)
+		case 2:
+			fmt.Fprintf(&b, "#%d [%s %d/9] RUN npm ci --omit=dev\n", i, service, i%9+1)
+		case 3:
+			fmt.Fprintf(&b, "#%d DONE %d.%ds\n", i, i%7, i%10)
+		case 4:
+			fmt.Fprintf(&b, " Container shop-%s-1  Started\n", service)
+		}
+	}
+	return b.String()
+}
+
+func TestDeploymentOutputCompression(t *testing.T) {
+	database := setupTestDB(t)
+	projectID := uuid.New()
+	require.NoError(t, database.Create(&db.ProjectModel{
+		BaseModel:    db.BaseModel{ID: projectID},
+		Name:         "shop",
+		GitURL:       "https://example.com/shop.git",
+		GitBranch:    "main",
+		WorkingDir:   "/tmp/shop",
+		ComposeFiles: "compose.yaml",
+		Status:       domain.ProjectStatusRunning.String(),
+	}).Error)
+
+	storedStdout := func(t *testing.T, deploymentID uuid.UUID) string {
+		var m db.DeploymentModel
+		require.NoError(t, database.First(&m, deploymentID).Error)
+		return m.Stdout
+	}
+
+	plain := repository.NewDeploymentRepository(database)
+	compressing := repository.NewDeploymentRepository(database, repository.WithOutputCompression(true))
+
+	tests := []struct {
+		name   string
+		stdout string
+		stderr string
+	}{
+		{name: "deployment output", stdout: sampleDeployOutput(2000), stderr: "ERROR: up failed\n"},
+		{name: "colored output", stdout: strings.Repeat("\x1b[32mweb started\x1b[0m\n", 200)},
+		{name: "empty output", stdout: "", stderr: ""},
+		{name: "short output", stdout: "ok\n", stderr: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := domain.NewDeployment(projectID, "abc123")
+			deployment.Status = domain.DeploymentStatusCompleted
+			deployment.Stdout = tt.stdout
+			deployment.Stderr = tt.stderr
+			require.NoError(t, compressing.Create(&deployment))
+
+			assert.LessOrEqual(t, len(storedStdout(t, deployment.ID)), len(tt.stdout))
+
+			for _, repo := range []repository.DeploymentRepository{compressing, plain} {
+				read, err := repo.FindByID(deployment.ID)
+				require.NoError(t, err)
+				assert.Equal(t, tt.stdout, read.Stdout)
+				assert.Equal(t, tt.stderr, read.Stderr)
+			}
+		})
+	}
+
+	t.Run("reduces the size of deployment output", func(t *testing.T) {
+		output := sampleDeployOutput(2000)
+		deployment := domain.NewDeployment(projectID, "abc123")
+		deployment.Stdout = output
+		require.NoError(t, compressing.Create(&deployment))
+
+		stored := storedStdout(t, deployment.ID)
+		ratio := float64(len(stored)) / float64(len(output))
+		t.Logf("stored %d of %d bytes (%.1f%%)", len(stored), len(output), ratio*100)
+		assert.Less(t, ratio, 0.25)
+	})
+
+	t.Run("reads rows stored without compression", func(t *testing.T) {
+		output := sampleDeployOutput(100)
+		deployment := domain.NewDeployment(projectID, "abc123")
+		deployment.Stdout = output
+		require.NoError(t, plain.Create(&deployment))
+		assert.Equal(t, output, storedStdout(t, deployment.ID))
+
+		read, err := compressing.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, output, read.Stdout)
+
+		// Updating the row with compression on compresses it
+		read.UpdatedAt = time.Now()
+		require.NoError(t, compressing.Update(read))
+		assert.Less(t, len(storedStdout(t, deployment.ID)), len(output))
+	})
+}
@@ -1,14 +1,20 @@
 package repository
 
 import (
+	"errors"
 	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/db"
 	"github.com/oar-cd/oar/domain"
 	"github.com/oar-cd/oar/encryption"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProjectRepository interface {
@@ -16,8 +22,18 @@ type ProjectRepository interface {
 	FindByName(name string) (*domain.Project, error)
 	Create(project *domain.Project) (*domain.Project, error)
 	Update(project *domain.Project) error
+	UpdateStatus(id uuid.UUID, status domain.ProjectStatus) error
+	UpdateDeploymentState(project *domain.Project) error
+	SetWatcherDisabled(ids []uuid.UUID, disabled bool) error
 	List() ([]*domain.Project, error)
 	Delete(id uuid.UUID) error
+	CountByStatus() (map[domain.ProjectStatus]int, error)
+	RelocateWorkingDirs(oldRoot, newRoot string) (int, error)
+	Reorder(projectIDs []uuid.UUID) error
+	// DB returns the database the repository uses
+	DB() *gorm.DB
+	// WithTx returns a copy of the repository that works in the transaction tx
+	WithTx(tx *gorm.DB) ProjectRepository
 }
 
 type projectRepository struct {
@@ -25,9 +41,11 @@ type projectRepository struct {
 	mapper *ProjectMapper
 }
 
+// List returns all projects in dashboard order: projects placed by hand first, in their order, then
+// the others by name
 func (r *projectRepository) List() ([]*domain.Project, error) {
 	var models []db.ProjectModel
-	if err := r.db.Find(&models).Error; err != nil {
+	if err := r.db.Order("sort_order = 0, sort_order, name").Find(&models).Error; err != nil {
 		return nil, err
 	}
 
@@ -80,14 +98,80 @@ func (r *projectRepository) Update(project *domain.Project) error {
 	// Use Select to explicitly update all fields except CreatedAt, including empty strings
 	// This ensures that clearing variables (empty string) actually updates the database
 	// CreatedAt should never be updated after initial creation
+	// SortOrder and WatcherDisabled are only changed by Reorder and SetWatcherDisabled, so that saving
+	// a project loaded earlier, e.g. by a watcher check, does not undo a change made in the meantime
 	return r.db.Model(&db.ProjectModel{}).
 		Where("id = ?", m.ID).
 		Select("*").
-		Omit("created_at").
+		Omit("created_at", "sort_order", "watcher_disabled").
 		Updates(m).
 		Error
 }
 
+// UpdateStatus changes only the stored status of a project, leaving changes made to its other
+// fields in the meantime in place
+func (r *projectRepository) UpdateStatus(id uuid.UUID, status domain.ProjectStatus) error {
+	return r.db.Model(&db.ProjectModel{}).
+		Where("id = ?", id).
+		Update("status", status.String()).
+		Error
+}
+
+// UpdateDeploymentState changes only the fields a deployment sets: the status, the local commit, the
+// pinned image digests and the previous names still in use. Changes made to the other fields while
+// the deployment ran, such as variables set on several projects at once, are left in place.
+func (r *projectRepository) UpdateDeploymentState(project *domain.Project) error {
+	m := r.mapper.ToModel(project)
+	return r.db.Model(&db.ProjectModel{}).
+		Where("id = ?", m.ID).
+		Select("status", "local_commit", "image_digests", "previous_names").
+		Updates(m).
+		Error
+}
+
+// SetWatcherDisabled changes only whether the watcher leaves the given projects alone, in a single
+// statement
+func (r *projectRepository) SetWatcherDisabled(ids []uuid.UUID, disabled bool) error {
+	return r.db.Model(&db.ProjectModel{}).
+		Where("id IN ?", ids).
+		Update("watcher_disabled", disabled).
+		Error
+}
+
+// Reorder places the given projects first on the dashboard, in the given order, in a single
+// transaction. All other projects are no longer placed by hand and follow by name. Nothing changes
+// when a project does not exist.
+func (r *projectRepository) Reorder(projectIDs []uuid.UUID) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&db.ProjectModel{}).
+			Where("sort_order <> 0").
+			UpdateColumn("sort_order", 0).Error; err != nil {
+			return err
+		}
+
+		for i, id := range projectIDs {
+			res := tx.Model(&db.ProjectModel{}).
+				Where("id = ?", id).
+				UpdateColumn("sort_order", i+1)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Database operation failed",
+			"layer", "repository",
+			"operation", "reorder_projects",
+			"project_count", len(projectIDs),
+			"error", err)
+	}
+	return err
+}
+
 func (r *projectRepository) Delete(id uuid.UUID) error {
 	err := r.db.Delete(&db.ProjectModel{}, id).Error
 	if err != nil {
@@ -100,6 +184,76 @@ func (r *projectRepository) Delete(id uuid.UUID) error {
 	return err // Pass through as-is
 }
 
+// CountByStatus returns the number of projects per stored status
+func (r *projectRepository) CountByStatus() (map[domain.ProjectStatus]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	if err := r.db.Model(&db.ProjectModel{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[domain.ProjectStatus]int, len(rows))
+	for _, row := range rows {
+		status, err := domain.ParseProjectStatus(row.Status)
+		if err != nil {
+			status = domain.ProjectStatusUnknown
+		}
+		counts[status] += row.Count
+	}
+	return counts, nil
+}
+
+// RelocateWorkingDirs rewrites the working directories under oldRoot to the same path under newRoot
+// in a single transaction and returns the number of projects updated
+func (r *projectRepository) RelocateWorkingDirs(oldRoot, newRoot string) (int, error) {
+	updated := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var models []db.ProjectModel
+		if err := tx.Select("id", "working_dir").Find(&models).Error; err != nil {
+			return err
+		}
+
+		for _, m := range models {
+			rel, err := filepath.Rel(oldRoot, m.WorkingDir)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue // Not under oldRoot
+			}
+
+			workingDir := filepath.Join(newRoot, rel)
+			if err := tx.Model(&db.ProjectModel{}).
+				Where("id = ?", m.ID).
+				Update("working_dir", workingDir).Error; err != nil {
+				return err
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Database operation failed",
+			"layer", "repository",
+			"operation", "relocate_working_dirs",
+			"old_root", oldRoot,
+			"new_root", newRoot,
+			"error", err)
+		return 0, err
+	}
+	return updated, nil
+}
+
+func (r *projectRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *projectRepository) WithTx(tx *gorm.DB) ProjectRepository {
+	return &projectRepository{db: tx, mapper: r.mapper}
+}
+
 func NewProjectRepository(db *gorm.DB, encryptionSvc *encryption.EncryptionService) ProjectRepository {
 	return &projectRepository{
 		db:     db,
@@ -107,11 +261,38 @@ func NewProjectRepository(db *gorm.DB, encryptionSvc *encryption.EncryptionServi
 	}
 }
 
+// Transaction runs fn with copies of projects and deployments bound to a single database transaction,
+// so that what fn saves through them is committed together, or rolled back together when fn returns
+// an error. Both repositories must use the same database.
+func Transaction(
+	projects ProjectRepository,
+	deployments DeploymentRepository,
+	fn func(projects ProjectRepository, deployments DeploymentRepository) error,
+) error {
+	if projects.DB() != deployments.DB() {
+		return errors.New("repositories use different databases")
+	}
+
+	return projects.DB().Transaction(func(tx *gorm.DB) error {
+		return fn(projects.WithTx(tx), deployments.WithTx(tx))
+	})
+}
+
 type DeploymentRepository interface {
 	FindByID(id uuid.UUID) (*domain.Deployment, error)
 	Create(deployment *domain.Deployment) error
 	Update(deployment *domain.Deployment) error
 	ListByProjectID(projectID uuid.UUID) ([]*domain.Deployment, error)
+	ListByStatus(status domain.DeploymentStatus) ([]*domain.Deployment, error)
+	CountSince(since time.Time) (int, error)
+	LatestCreatedAt() (*time.Time, error)
+	HasCompleted(projectID uuid.UUID) (bool, error)
+	LatestCompletedID(projectID uuid.UUID) (uuid.UUID, error)
+	Prune(olderThan time.Time, keepPerProject int) (int, error)
+	// DB returns the database the repository uses
+	DB() *gorm.DB
+	// WithTx returns a copy of the repository that works in the transaction tx
+	WithTx(tx *gorm.DB) DeploymentRepository
 }
 
 type deploymentRepository struct {
@@ -160,11 +341,323 @@ func (r *deploymentRepository) ListByProjectID(projectID uuid.UUID) ([]*domain.D
 	return deployments, nil
 }
 
-func NewDeploymentRepository(db *gorm.DB) DeploymentRepository {
-	return &deploymentRepository{
+func (r *deploymentRepository) ListByStatus(status domain.DeploymentStatus) ([]*domain.Deployment, error) {
+	var models []db.DeploymentModel
+	if err := r.db.Where("status = ?", status.String()).Order("created_at ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	deployments := make([]*domain.Deployment, len(models))
+	for i, m := range models {
+		deployments[i] = r.mapper.ToDomain(&m)
+	}
+	return deployments, nil
+}
+
+// CountSince returns the number of deployments started at or after since
+func (r *deploymentRepository) CountSince(since time.Time) (int, error) {
+	var count int64
+	if err := r.db.Model(&db.DeploymentModel{}).Where("created_at >= ?", since).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// LatestCreatedAt returns when the most recent deployment started, or nil if there are none
+func (r *deploymentRepository) LatestCreatedAt() (*time.Time, error) {
+	var m db.DeploymentModel
+	err := r.db.Select("created_at").Order("created_at DESC").Take(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m.CreatedAt, nil
+}
+
+// HasCompleted reports whether the project has a completed deployment
+func (r *deploymentRepository) HasCompleted(projectID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&db.DeploymentModel{}).
+		Where("project_id = ? AND status = ?", projectID, domain.DeploymentStatusCompleted.String()).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// LatestCompletedID returns the ID of the project's latest completed deployment, or uuid.Nil when the
+// project has none
+func (r *deploymentRepository) LatestCompletedID(projectID uuid.UUID) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&db.DeploymentModel{}).
+		Where("project_id = ? AND status = ?", projectID, domain.DeploymentStatusCompleted.String()).
+		Order("created_at DESC").
+		Limit(1).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return uuid.Nil, err
+	}
+	return ids[0], nil
+}
+
+// pruneBatchSize bounds the number of IDs in one delete statement
+const pruneBatchSize = 500
+
+// Prune deletes the deployments created before olderThan and those beyond the keepPerProject most
+// recent of their project, and returns how many were deleted. A zero olderThan or keepPerProject
+// disables that limit. The latest deployment of each project, its latest completed deployment and
+// deployments still in progress are always kept, whatever their age.
+func (r *deploymentRepository) Prune(olderThan time.Time, keepPerProject int) (int, error) {
+	if olderThan.IsZero() && keepPerProject <= 0 {
+		return 0, nil
+	}
+
+	var models []db.DeploymentModel
+	err := r.db.Select("id", "project_id", "status", "created_at").
+		Order("project_id, created_at DESC").
+		Find(&models).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []uuid.UUID
+	var project uuid.UUID
+	var position int
+	var completedSeen bool
+	for _, m := range models {
+		if m.ProjectID != project {
+			project, position, completedSeen = m.ProjectID, 0, false
+		}
+		position++
+
+		relevant := position == 1 || m.Status == domain.DeploymentStatusStarted.String()
+		if m.Status == domain.DeploymentStatusCompleted.String() && !completedSeen {
+			completedSeen = true
+			relevant = true
+		}
+		if relevant {
+			continue
+		}
+		tooOld := !olderThan.IsZero() && m.CreatedAt.Before(olderThan)
+		if tooOld || (keepPerProject > 0 && position > keepPerProject) {
+			expired = append(expired, m.ID)
+		}
+	}
+
+	deleted := 0
+	for batch := range slices.Chunk(expired, pruneBatchSize) {
+		result := r.db.Where("id IN ?", batch).Delete(&db.DeploymentModel{})
+		if result.Error != nil {
+			return deleted, result.Error
+		}
+		deleted += int(result.RowsAffected)
+	}
+	return deleted, nil
+}
+
+func (r *deploymentRepository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *deploymentRepository) WithTx(tx *gorm.DB) DeploymentRepository {
+	return &deploymentRepository{db: tx, mapper: r.mapper}
+}
+
+// DeploymentRepositoryOption customizes a deployment repository
+type DeploymentRepositoryOption func(*deploymentRepository)
+
+// WithOutputCompression stores the output of deployments gzip-compressed when enabled. Output is read
+// back the same way either way, so the setting can be changed on an existing database.
+func WithOutputCompression(enabled bool) DeploymentRepositoryOption {
+	return func(r *deploymentRepository) {
+		r.mapper.compressOutput = enabled
+	}
+}
+
+func NewDeploymentRepository(db *gorm.DB, opts ...DeploymentRepositoryOption) DeploymentRepository {
+	r := &deploymentRepository{
 		db:     db,
 		mapper: &DeploymentMapper{},
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type ShareLinkRepository interface {
+	FindByID(id uuid.UUID) (*domain.ShareLink, error)
+	Create(link *domain.ShareLink) error
+	ListByProjectID(projectID uuid.UUID, limit int) ([]*domain.ShareLink, error)
+	Revoke(id uuid.UUID, revokedAt time.Time) error
+	Prune(before time.Time) (int, error)
+}
+
+type shareLinkRepository struct {
+	db     *gorm.DB
+	mapper *ShareLinkMapper
+}
+
+func (r *shareLinkRepository) FindByID(id uuid.UUID) (*domain.ShareLink, error) {
+	var m db.ShareLinkModel
+	if err := r.db.First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return r.mapper.ToDomain(&m), nil
+}
+
+func (r *shareLinkRepository) Create(link *domain.ShareLink) error {
+	m := r.mapper.ToModel(link)
+	if err := r.db.Create(m).Error; err != nil {
+		return err
+	}
+	// Update the domain object with the timestamps that GORM populated
+	*link = *r.mapper.ToDomain(m)
+	return nil
+}
+
+// ListByProjectID returns the links of the project, newest first. A positive limit caps how many
+// are returned.
+func (r *shareLinkRepository) ListByProjectID(projectID uuid.UUID, limit int) ([]*domain.ShareLink, error) {
+	query := r.db.Where("project_id = ?", projectID).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var models []db.ShareLinkModel
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	links := make([]*domain.ShareLink, len(models))
+	for i, m := range models {
+		links[i] = r.mapper.ToDomain(&m)
+	}
+	return links, nil
+}
+
+// Revoke marks a link as revoked. A link that was already revoked keeps its first revocation time.
+func (r *shareLinkRepository) Revoke(id uuid.UUID, revokedAt time.Time) error {
+	result := r.db.Model(&db.ShareLinkModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	return result.Error
+}
+
+// Prune deletes links that expired or were revoked before the given time and returns how many
+func (r *shareLinkRepository) Prune(before time.Time) (int, error) {
+	result := r.db.Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&db.ShareLinkModel{})
+	return int(result.RowsAffected), result.Error
+}
+
+func NewShareLinkRepository(db *gorm.DB) ShareLinkRepository {
+	return &shareLinkRepository{
+		db:     db,
+		mapper: &ShareLinkMapper{},
+	}
+}
+
+type WatcherCheckRepository interface {
+	Save(check *domain.WatcherCheck) error
+	List() ([]*domain.WatcherCheck, error)
+}
+
+type watcherCheckRepository struct {
+	db     *gorm.DB
+	mapper *WatcherCheckMapper
+}
+
+// Save stores the check, replacing the previous check of the project
+func (r *watcherCheckRepository) Save(check *domain.WatcherCheck) error {
+	m := r.mapper.ToModel(check)
+	return r.db.Omit(clause.Associations).Clauses(clause.OnConflict{UpdateAll: true}).Create(m).Error
+}
+
+// List returns the last check of every project, with the project's current name
+func (r *watcherCheckRepository) List() ([]*domain.WatcherCheck, error) {
+	var models []db.WatcherCheckModel
+	if err := r.db.Joins("Project").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	checks := make([]*domain.WatcherCheck, len(models))
+	for i, m := range models {
+		checks[i] = r.mapper.ToDomain(&m)
+	}
+	return checks, nil
+}
+
+func NewWatcherCheckRepository(db *gorm.DB) WatcherCheckRepository {
+	return &watcherCheckRepository{
+		db:     db,
+		mapper: &WatcherCheckMapper{},
+	}
+}
+
+type APITokenRepository interface {
+	FindByHash(tokenHash string) (*domain.APIToken, error)
+	Create(token *domain.APIToken, tokenHash string) error
+	List() ([]*domain.APIToken, error)
+	Delete(id uuid.UUID) error
+}
+
+type apiTokenRepository struct {
+	db     *gorm.DB
+	mapper *APITokenMapper
+}
+
+func (r *apiTokenRepository) FindByHash(tokenHash string) (*domain.APIToken, error) {
+	var m db.APITokenModel
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return r.mapper.ToDomain(&m), nil
+}
+
+func (r *apiTokenRepository) Create(token *domain.APIToken, tokenHash string) error {
+	m := r.mapper.ToModel(token, tokenHash)
+	if err := r.db.Omit(clause.Associations).Create(m).Error; err != nil {
+		return err
+	}
+	// Update the domain object with the timestamps that GORM populated
+	*token = *r.mapper.ToDomain(m)
+	return nil
+}
+
+// List returns all tokens, oldest first
+func (r *apiTokenRepository) List() ([]*domain.APIToken, error) {
+	var models []db.APITokenModel
+	if err := r.db.Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.APIToken, len(models))
+	for i, m := range models {
+		tokens[i] = r.mapper.ToDomain(&m)
+	}
+	return tokens, nil
+}
+
+// Delete removes the token, gorm.ErrRecordNotFound when there is none with the ID
+func (r *apiTokenRepository) Delete(id uuid.UUID) error {
+	result := r.db.Delete(&db.APITokenModel{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{
+		db:     db,
+		mapper: &APITokenMapper{},
+	}
 }
 
 // Helper functions
@@ -178,3 +671,45 @@ func parseFiles(s string) []string {
 func serializeFiles(files []string) string {
 	return strings.Join(files, "\x00")
 }
+
+// parseProjectIDs parses null-separated project IDs, skipping any that are not valid UUIDs
+func parseProjectIDs(s string) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, value := range parseFiles(s) {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			slog.Warn("Skipping invalid project ID", "value", value, "error", err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func serializeProjectIDs(ids []uuid.UUID) string {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return serializeFiles(values)
+}
+
+// parseKeyValues parses null-separated KEY=value entries, such as build arguments or container labels
+func parseKeyValues(s string) map[string]string {
+	args := map[string]string{}
+	for _, arg := range parseFiles(s) {
+		key, value, _ := strings.Cut(arg, "=")
+		args[key] = value
+	}
+	return args
+}
+
+// serializeKeyValues stores entries as KEY=value sorted by key, so that the column does not change
+// when the entries do not
+func serializeKeyValues(args map[string]string) string {
+	values := make([]string, 0, len(args))
+	for _, key := range slices.Sorted(maps.Keys(args)) {
+		values = append(values, key+"="+args[key])
+	}
+	return serializeFiles(values)
+}
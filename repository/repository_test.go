@@ -0,0 +1,142 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/fernet/fernet-go"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/encryption"
+	"github.com/oar-cd/oar/repository"
+)
+
+func TestTransaction(t *testing.T) {
+	database := setupTestDB(t)
+	projects := repository.NewProjectRepository(database, nil)
+	deployments := repository.NewDeploymentRepository(database)
+
+	project, err := projects.Create(&domain.Project{
+		ID:           uuid.New(),
+		Name:         "shop",
+		GitURL:       "https://example.com/shop.git",
+		GitBranch:    "main",
+		WorkingDir:   "/data/projects/shop",
+		ComposeFiles: []string{"compose.yaml"},
+		Status:       domain.ProjectStatusDeploying,
+	})
+	require.NoError(t, err)
+	deployment := &domain.Deployment{
+		ID:         uuid.New(),
+		ProjectID:  project.ID,
+		CommitHash: "abc123",
+		Status:     domain.DeploymentStatusStarted,
+	}
+	require.NoError(t, deployments.Create(deployment))
+
+	// save saves the deployment and then the project in a transaction, as deployments end
+	save := func(project *domain.Project, deployment domain.Deployment) error {
+		return repository.Transaction(projects, deployments,
+			func(projects repository.ProjectRepository, deployments repository.DeploymentRepository) error {
+				if err := deployments.Update(&deployment); err != nil {
+					return err
+				}
+				return projects.Update(project)
+			})
+	}
+
+	t.Run("failing second update rolls back both", func(t *testing.T) {
+		completed := *deployment
+		completed.Status = domain.DeploymentStatusCompleted
+		invalid := *project
+		invalid.Status = domain.ProjectStatusRunning
+		invalid.GitURL = "" // Rejected by the database
+
+		err := save(&invalid, completed)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "CHECK constraint failed", "The project update should be the one failing")
+
+		storedDeployment, err := deployments.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeploymentStatusStarted, storedDeployment.Status,
+			"The deployment update should be rolled back with the project update")
+		storedProject, err := projects.FindByID(project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ProjectStatusDeploying, storedProject.Status)
+		assert.Equal(t, "https://example.com/shop.git", storedProject.GitURL)
+	})
+
+	t.Run("both updates are committed", func(t *testing.T) {
+		completed := *deployment
+		completed.Status = domain.DeploymentStatusCompleted
+		running := *project
+		running.Status = domain.ProjectStatusRunning
+
+		require.NoError(t, save(&running, completed))
+
+		storedDeployment, err := deployments.FindByID(deployment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DeploymentStatusCompleted, storedDeployment.Status)
+		storedProject, err := projects.FindByID(project.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ProjectStatusRunning, storedProject.Status)
+	})
+
+	t.Run("repositories of different databases", func(t *testing.T) {
+		other := repository.NewDeploymentRepository(setupTestDB(t))
+		err := repository.Transaction(projects, other,
+			func(repository.ProjectRepository, repository.DeploymentRepository) error {
+				t.Fatal("fn must not run without a shared database")
+				return nil
+			})
+		assert.EqualError(t, err, "repositories use different databases")
+	})
+}
+
+func TestWebhookSecretStoredEncrypted(t *testing.T) {
+	database := setupTestDB(t)
+	newEncryption := func() *encryption.EncryptionService {
+		var key fernet.Key
+		require.NoError(t, key.Generate())
+		encryptionSvc, err := encryption.NewEncryptionService(key.Encode())
+		require.NoError(t, err)
+		return encryptionSvc
+	}
+	projects := repository.NewProjectRepository(database, newEncryption())
+
+	project, err := projects.Create(&domain.Project{
+		ID:            uuid.New(),
+		Name:          "shop",
+		GitURL:        "https://example.com/shop.git",
+		GitBranch:     "main",
+		WorkingDir:    "/data/projects/shop",
+		ComposeFiles:  []string{"compose.yaml"},
+		Status:        domain.ProjectStatusStopped,
+		WebhookSecret: "s3cret-value",
+	})
+	require.NoError(t, err)
+
+	var model db.ProjectModel
+	require.NoError(t, database.First(&model, "id = ?", project.ID).Error)
+	require.NotNil(t, model.WebhookSecret)
+	assert.NotContains(t, *model.WebhookSecret, "s3cret-value")
+
+	stored, err := projects.FindByID(project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret-value", stored.WebhookSecret)
+
+	// With another key the secret cannot be decrypted, and deliveries must not be accepted unsigned
+	stored, err = repository.NewProjectRepository(database, newEncryption()).FindByID(project.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, stored.WebhookSecret)
+	assert.NotEqual(t, "s3cret-value", stored.WebhookSecret)
+
+	// Clearing the secret removes it
+	stored.WebhookSecret = ""
+	require.NoError(t, projects.Update(stored))
+	require.NoError(t, database.First(&model, "id = ?", project.ID).Error)
+	assert.Nil(t, model.WebhookSecret)
+}
@@ -0,0 +1,177 @@
+// Package share provides signed, time-limited links that give read-only access to a project.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultTTL is how long a link stays valid when no lifetime is given
+	DefaultTTL = 24 * time.Hour
+	// MaxTTL bounds the lifetime of a link, so that a forgotten link does not stay open for good
+	MaxTTL = 30 * 24 * time.Hour
+	// EndedRetention is how long expired and revoked links stay listed before maintenance removes them
+	EndedRetention = 7 * 24 * time.Hour
+	// MaxListedLinks caps how many links of a project are listed
+	MaxListedLinks = 50
+
+	// payloadSize is the size of a token's payload: link ID, project ID and expiry as Unix seconds
+	payloadSize = 16 + 16 + 8
+)
+
+var (
+	// ErrInvalidLink is returned for tokens that were not issued by this server or were altered
+	ErrInvalidLink = errors.New("invalid share link")
+	// ErrLinkExpired is returned for links used after their expiry
+	ErrLinkExpired = errors.New("share link has expired")
+	// ErrLinkRevoked is returned for links that were revoked
+	ErrLinkRevoked = errors.New("share link has been revoked")
+)
+
+// Service issues, verifies and revokes share links. A token carries the link's ID, project and
+// expiry, signed with a key derived from the encryption key, so that tokens cannot be forged or
+// extended. The link is also stored, so that it can be listed and revoked before it expires.
+type Service struct {
+	links repository.ShareLinkRepository
+	key   []byte
+	now   func() time.Time
+}
+
+func NewService(links repository.ShareLinkRepository, encryptionKey string) *Service {
+	// A separate key, so that a token never reveals anything about the encryption key itself
+	mac := hmac.New(sha256.New, []byte(encryptionKey))
+	mac.Write([]byte("oar share links"))
+	return &Service{
+		links: links,
+		key:   mac.Sum(nil),
+		now:   time.Now,
+	}
+}
+
+// Create issues a link to the project that expires after ttl, and returns it with its token
+func (s *Service) Create(projectID uuid.UUID, ttl time.Duration) (*domain.ShareLink, string, error) {
+	if ttl <= 0 || ttl > MaxTTL {
+		return nil, "", fmt.Errorf("share link lifetime must be between 1s and %s, got: %s", MaxTTL, ttl)
+	}
+
+	link := &domain.ShareLink{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		// Tokens carry the expiry in whole seconds
+		ExpiresAt: s.now().Add(ttl).Truncate(time.Second),
+	}
+	if err := s.links.Create(link); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "share",
+			"operation", "create_share_link",
+			"project_id", projectID,
+			"error", err)
+		return nil, "", fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	slog.Info("Share link created", "project_id", projectID, "link_id", link.ID, "expires_at", link.ExpiresAt)
+	return link, s.Token(link), nil
+}
+
+// List returns the most recent links of the project, newest first, including expired and revoked
+// ones. At most MaxListedLinks are returned.
+func (s *Service) List(projectID uuid.UUID) ([]*domain.ShareLink, error) {
+	return s.links.ListByProjectID(projectID, MaxListedLinks)
+}
+
+// Revoke stops a link of the project from working before it expires
+func (s *Service) Revoke(projectID, linkID uuid.UUID) error {
+	link, err := s.links.FindByID(linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && link.ProjectID != projectID) {
+		return fmt.Errorf("share link %s not found", linkID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find share link: %w", err)
+	}
+
+	if err := s.links.Revoke(linkID, s.now()); err != nil {
+		slog.Error("Service operation failed",
+			"layer", "share",
+			"operation", "revoke_share_link",
+			"project_id", projectID,
+			"link_id", linkID,
+			"error", err)
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	slog.Info("Share link revoked", "project_id", projectID, "link_id", linkID)
+	return nil
+}
+
+// Token returns the signed token of a link
+func (s *Service) Token(link *domain.ShareLink) string {
+	payload := make([]byte, 0, payloadSize)
+	payload = append(payload, link.ID[:]...)
+	payload = append(payload, link.ProjectID[:]...)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(link.ExpiresAt.Unix()))
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// Verify returns the link of a token. It fails with ErrInvalidLink, ErrLinkExpired or ErrLinkRevoked
+// unless the token was issued by this server and its link can still be used.
+func (s *Service) Verify(token string) (*domain.ShareLink, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != payloadSize {
+		return nil, ErrInvalidLink
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.sign(payload)) {
+		return nil, ErrInvalidLink
+	}
+
+	linkID, _ := uuid.FromBytes(payload[:16])
+	projectID, _ := uuid.FromBytes(payload[16:32])
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[32:])), 0)
+
+	// The signed expiry is checked first, so that expired tokens need no database lookup
+	now := s.now()
+	if !now.Before(expiresAt) {
+		return nil, ErrLinkExpired
+	}
+
+	link, err := s.links.FindByID(linkID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidLink
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find share link: %w", err)
+	}
+	if link.ProjectID != projectID {
+		return nil, ErrInvalidLink
+	}
+	if link.RevokedAt != nil {
+		return nil, ErrLinkRevoked
+	}
+	if !link.Active(now) {
+		return nil, ErrLinkExpired
+	}
+	return link, nil
+}
+
+func (s *Service) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,134 @@
+package share
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/repository"
+)
+
+func setupService(t *testing.T) (*Service, uuid.UUID) {
+	t.Helper()
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+
+	project := db.ProjectModel{
+		BaseModel:    db.BaseModel{ID: uuid.New()},
+		Name:         "shared",
+		GitURL:       "https://example.com/shared.git",
+		GitBranch:    "main",
+		WorkingDir:   "/tmp/shared",
+		ComposeFiles: "compose.yaml",
+		Status:       "running",
+	}
+	require.NoError(t, database.Create(&project).Error)
+
+	return NewService(repository.NewShareLinkRepository(database), "test-encryption-key"), project.ID
+}
+
+func TestCreateAndVerify(t *testing.T) {
+	service, projectID := setupService(t)
+
+	link, token, err := service.Create(projectID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, projectID, link.ProjectID)
+	assert.Equal(t, token, service.Token(link))
+
+	verified, err := service.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, link.ID, verified.ID)
+	assert.Equal(t, projectID, verified.ProjectID)
+
+	links, err := service.List(projectID)
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, link.ID, links[0].ID)
+}
+
+func TestListCapsLinks(t *testing.T) {
+	service, projectID := setupService(t)
+
+	for range MaxListedLinks + 1 {
+		_, _, err := service.Create(projectID, time.Hour)
+		require.NoError(t, err)
+	}
+
+	links, err := service.List(projectID)
+	require.NoError(t, err)
+	assert.Len(t, links, MaxListedLinks)
+}
+
+func TestCreateRejectsLifetime(t *testing.T) {
+	service, projectID := setupService(t)
+
+	for _, ttl := range []time.Duration{0, -time.Hour, MaxTTL + time.Second} {
+		_, _, err := service.Create(projectID, ttl)
+		assert.Error(t, err, ttl)
+	}
+}
+
+func TestVerifyRejectsTamperedTokens(t *testing.T) {
+	service, projectID := setupService(t)
+	_, token, err := service.Create(projectID, time.Hour)
+	require.NoError(t, err)
+	payload, signature, _ := strings.Cut(token, ".")
+
+	// Another server signs with another key
+	other := NewService(service.links, "another-encryption-key")
+	_, forged, err := other.Create(projectID, time.Hour)
+	require.NoError(t, err)
+
+	// A longer expiry with the original signature
+	extended := []byte(payload)
+	extended[len(extended)-2] ^= 1
+
+	for name, tampered := range map[string]string{
+		"empty":           "",
+		"no signature":    payload,
+		"wrong signature": payload + "." + strings.Repeat("A", len(signature)),
+		"changed payload": string(extended) + "." + signature,
+		"not base64":      "!!!." + signature,
+		"other key":       forged,
+	} {
+		_, err := service.Verify(tampered)
+		assert.ErrorIs(t, err, ErrInvalidLink, name)
+	}
+}
+
+func TestVerifyRejectsExpiredLinks(t *testing.T) {
+	service, projectID := setupService(t)
+	_, token, err := service.Create(projectID, time.Hour)
+	require.NoError(t, err)
+
+	service.now = func() time.Time { return time.Now().Add(time.Hour + time.Second) }
+	_, err = service.Verify(token)
+	assert.ErrorIs(t, err, ErrLinkExpired)
+}
+
+func TestRevoke(t *testing.T) {
+	service, projectID := setupService(t)
+	link, token, err := service.Create(projectID, time.Hour)
+	require.NoError(t, err)
+	_, otherToken, err := service.Create(projectID, time.Hour)
+	require.NoError(t, err)
+
+	// A link can only be revoked through its own project
+	assert.Error(t, service.Revoke(uuid.New(), link.ID))
+	assert.Error(t, service.Revoke(projectID, uuid.New()))
+
+	require.NoError(t, service.Revoke(projectID, link.ID))
+	_, err = service.Verify(token)
+	assert.ErrorIs(t, err, ErrLinkRevoked)
+
+	// Other links of the project keep working
+	_, err = service.Verify(otherToken)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,248 @@
+// Package update checks whether a newer Oar release is available. It only informs, Oar never
+// replaces its own binary.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReleasesURL is the GitHub API endpoint of the latest Oar release
+const DefaultReleasesURL = "https://api.github.com/repos/oar-cd/oar/releases/latest"
+
+// requestTimeout bounds a single query of the releases API
+const requestTimeout = 10 * time.Second
+
+// Result is the outcome of a successful check
+type Result struct {
+	Current         string    `json:"current_version"`
+	Latest          string    `json:"latest_version"`
+	URL             string    `json:"release_url"`
+	UpdateAvailable bool      `json:"update_available"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+// Checker queries the releases API for the latest release and keeps the result of the last
+// successful check. Failed checks, such as on a host without internet access, are only logged
+// at debug level and leave the previous result in place.
+type Checker struct {
+	current     string
+	releasesURL string
+	interval    time.Duration
+	client      *http.Client
+
+	mu     sync.RWMutex
+	latest *Result
+}
+
+func NewChecker(current, releasesURL string, interval time.Duration) *Checker {
+	return &Checker{
+		current:     current,
+		releasesURL: releasesURL,
+		interval:    interval,
+		client:      &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Latest returns the result of the last successful check, false when no check succeeded yet
+func (c *Checker) Latest() (Result, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.latest == nil {
+		return Result{}, false
+	}
+	return *c.latest, true
+}
+
+// Check queries the releases API once and records the result. A development build, whose version
+// is not a release version, is never reported as outdated.
+func (c *Checker) Check(ctx context.Context) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.releasesURL, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to query releases: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("failed to query releases: unexpected status %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Result{}, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if _, err := ParseVersion(release.TagName); err != nil {
+		return Result{}, fmt.Errorf("invalid release tag: %w", err)
+	}
+
+	result := Result{
+		Current:   c.current,
+		Latest:    release.TagName,
+		URL:       release.HTMLURL,
+		CheckedAt: time.Now(),
+	}
+	if newer, err := IsNewer(release.TagName, c.current); err == nil {
+		result.UpdateAvailable = newer
+	}
+
+	c.mu.Lock()
+	c.latest = &result
+	c.mu.Unlock()
+	return result, nil
+}
+
+// Start checks right away and then every interval until ctx is done
+func (c *Checker) Start(ctx context.Context) error {
+	slog.Info("Update check starting", "interval", c.interval, "current_version", c.current)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.checkAndLog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Update check shutting down")
+			return nil
+		case <-ticker.C:
+			c.checkAndLog(ctx)
+		}
+	}
+}
+
+func (c *Checker) checkAndLog(ctx context.Context) {
+	result, err := c.Check(ctx)
+	if err != nil {
+		slog.Debug("Update check failed", "error", err)
+		return
+	}
+	if result.UpdateAvailable {
+		slog.Info("A newer Oar release is available",
+			"current_version", result.Current,
+			"latest_version", result.Latest,
+			"release_url", result.URL)
+	}
+}
+
+// Version is a parsed semantic version such as v1.2.3 or 1.2.3-rc.1. Build metadata is ignored.
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+}
+
+// ParseVersion parses a semantic version with an optional leading v
+func ParseVersion(s string) (Version, error) {
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core, _, _ = strings.Cut(core, "+")
+	core, prerelease, hasPrerelease := strings.Cut(core, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("not a semantic version: %q", s)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part == "" || (len(part) > 1 && part[0] == '0') {
+			return Version{}, fmt.Errorf("not a semantic version: %q", s)
+		}
+		numbers[i] = n
+	}
+
+	version := Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}
+	if hasPrerelease {
+		version.Prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range version.Prerelease {
+			if identifier == "" {
+				return Version{}, fmt.Errorf("not a semantic version: %q", s)
+			}
+		}
+	}
+	return version, nil
+}
+
+// Compare returns -1, 0 or 1 when v is older than, equal to or newer than other. A pre-release is
+// older than the release of the same version.
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifiers(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.Prerelease) - len(other.Prerelease))
+}
+
+// IsNewer reports whether candidate is a newer version than current. It fails when either is not
+// a semantic version, such as the version dev of a development build.
+func IsNewer(candidate, current string) (bool, error) {
+	candidateVersion, err := ParseVersion(candidate)
+	if err != nil {
+		return false, err
+	}
+	currentVersion, err := ParseVersion(current)
+	if err != nil {
+		return false, err
+	}
+	return candidateVersion.Compare(currentVersion) > 0, nil
+}
+
+// compareIdentifiers compares pre-release identifiers. Numeric identifiers compare numerically and
+// are older than alphanumeric ones.
+func compareIdentifiers(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(aNum - bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
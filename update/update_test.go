@@ -0,0 +1,126 @@
+package update_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/update"
+)
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		candidate string
+		current   string
+		want      bool
+	}{
+		{"v1.2.4", "v1.2.3", true},
+		{"v1.3.0", "v1.2.9", true},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"1.2.3", "v1.2.3", false},
+		{"v1.2.3", "v1.2.4", false},
+		{"v1.2.3", "v1.2.3-rc.1", true},
+		{"v1.2.3-rc.1", "v1.2.3", false},
+		{"v1.2.3-rc.2", "v1.2.3-rc.1", true},
+		{"v1.2.3-rc.10", "v1.2.3-rc.9", true},
+		{"v1.2.3-rc.1", "v1.2.3-beta.2", true},
+		{"v1.2.3-beta", "v1.2.3-1", true},
+		{"v1.2.3-rc.1.1", "v1.2.3-rc.1", true},
+		{"v1.2.3+build.5", "v1.2.3", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.candidate+" vs "+tt.current, func(t *testing.T) {
+			newer, err := update.IsNewer(tt.candidate, tt.current)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, newer)
+		})
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, version := range []string{"dev", "", "v1.2", "v1.2.3.4", "v1.02.3", "v1.2.x", "v1.2.3-", "v1.2.3-rc..1"} {
+		_, err := update.ParseVersion(version)
+		assert.Error(t, err, version)
+	}
+
+	// A development build cannot be compared
+	_, err := update.IsNewer("v1.2.3", "dev")
+	assert.Error(t, err)
+}
+
+func TestCheckerCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(`{"tag_name": "v1.3.0", "html_url": "https://github.com/oar-cd/oar/releases/tag/v1.3.0"}`))
+	}))
+	defer server.Close()
+
+	checker := update.NewChecker("v1.2.0", server.URL, time.Hour)
+	_, ok := checker.Latest()
+	assert.False(t, ok)
+
+	result, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.UpdateAvailable)
+	assert.Equal(t, "v1.2.0", result.Current)
+	assert.Equal(t, "v1.3.0", result.Latest)
+	assert.Equal(t, "https://github.com/oar-cd/oar/releases/tag/v1.3.0", result.URL)
+
+	latest, ok := checker.Latest()
+	require.True(t, ok)
+	assert.Equal(t, result, latest)
+
+	// The latest release itself and development builds are not outdated
+	for _, current := range []string{"v1.3.0", "dev"} {
+		result, err := update.NewChecker(current, server.URL, time.Hour).Check(context.Background())
+		require.NoError(t, err)
+		assert.False(t, result.UpdateAvailable, current)
+	}
+}
+
+func TestCheckerOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable := server.URL
+	server.Close()
+
+	checker := update.NewChecker("v1.2.0", unreachable, time.Hour)
+	_, err := checker.Check(context.Background())
+	assert.Error(t, err)
+
+	// Start does not fail or record anything while the releases API cannot be reached
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, checker.Start(ctx))
+	_, ok := checker.Latest()
+	assert.False(t, ok)
+}
+
+func TestCheckerKeepsResultOnFailure(t *testing.T) {
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name": "v1.3.0"}`))
+	}))
+	defer server.Close()
+
+	checker := update.NewChecker("v1.2.0", server.URL, time.Hour)
+	_, err := checker.Check(context.Background())
+	require.NoError(t, err)
+
+	fail = true
+	_, err = checker.Check(context.Background())
+	assert.Error(t, err)
+
+	latest, ok := checker.Latest()
+	require.True(t, ok)
+	assert.Equal(t, "v1.3.0", latest.Latest)
+}
@@ -0,0 +1,69 @@
+package watcher
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+)
+
+// manualOperationGrace is how long before a status correction a user may have stopped the project, or
+// killed one of its services, for the correction to be expected rather than notified
+const manualOperationGrace = 2 * time.Minute
+
+// statusCorrection is a correction of a project's stored status that has not been notified yet. It is
+// notified once the next sync finds the corrected status still holding, so that a status that flaps
+// back is never reported.
+type statusCorrection struct {
+	from       domain.ProjectStatus   // Stored status before the first correction
+	at         time.Time              // When the first correction was made
+	containers []docker.ContainerInfo // States of the containers that triggered the last correction
+}
+
+// recordCorrection remembers that the stored status of p was corrected from previous. Corrections
+// following one another keep the status from before the first of them.
+func (w *WatcherService) recordCorrection(
+	p *domain.Project,
+	previous domain.ProjectStatus,
+	containers []docker.ContainerInfo,
+) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	correction, ok := w.corrections[p.ID]
+	if !ok {
+		correction = statusCorrection{from: previous, at: time.Now()}
+	}
+	correction.containers = containers
+	w.corrections[p.ID] = correction
+}
+
+// settleCorrection notifies the pending correction of p, now that its stored status matches its
+// containers. Nothing is sent when the status came back to where it was, or when a user stopped the
+// project or killed one of its services around the time of the correction.
+func (w *WatcherService) settleCorrection(p *domain.Project) {
+	w.mu.Lock()
+	correction, ok := w.corrections[p.ID]
+	delete(w.corrections, p.ID)
+	w.mu.Unlock()
+
+	if !ok || correction.from == p.Status {
+		return
+	}
+	if last := w.projectService.LastManualOperation(p.ID); last.After(correction.at.Add(-manualOperationGrace)) {
+		slog.Debug("Not notifying status correction that followed a manual operation",
+			"project_id", p.ID,
+			"project_name", p.Name,
+			"previous_status", correction.from.String(),
+			"status", p.Status.String())
+		return
+	}
+
+	slog.Info("Notifying project status correction",
+		"project_id", p.ID,
+		"project_name", p.Name,
+		"previous_status", correction.from.String(),
+		"status", p.Status.String())
+	w.projectService.NotifyStatusCorrection(p, correction.from, correction.containers)
+}
@@ -0,0 +1,292 @@
+package watcher
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/metrics"
+	"github.com/oar-cd/oar/project"
+	"github.com/oar-cd/oar/repository"
+)
+
+// CheckOutcome describes what happened during a single project check
+type CheckOutcome string
+
+const (
+	// CheckOutcomeNoChanges means the deployed commit matches the remote
+	CheckOutcomeNoChanges CheckOutcome = "no_changes"
+	// CheckOutcomeUpdatesAvailable means the remote has new commits but no deployment was triggered
+	CheckOutcomeUpdatesAvailable CheckOutcome = "updates_available"
+	// CheckOutcomeDeployed means an automatic deployment was triggered and succeeded
+	CheckOutcomeDeployed CheckOutcome = "deployed"
+	// CheckOutcomeError means the check or the triggered deployment failed
+	CheckOutcomeError CheckOutcome = "error"
+	// CheckOutcomeDeferred means a deployment was due but another operation on the project, e.g. a
+	// deployment started by a user, was in progress. The next check tries again.
+	CheckOutcomeDeferred CheckOutcome = "deferred"
+)
+
+// ProjectCheck holds the result of the most recent check of a project
+type ProjectCheck struct {
+	ProjectID           uuid.UUID    `json:"project_id"`
+	ProjectName         string       `json:"project_name"`
+	CheckedAt           time.Time    `json:"checked_at"`
+	Outcome             CheckOutcome `json:"outcome"`
+	Error               string       `json:"error,omitempty"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	Manual              bool         `json:"manual"`
+}
+
+// WatcherStatus is a point-in-time snapshot of the watcher state
+type WatcherStatus struct {
+	Paused       bool           `json:"paused"`
+	PollInterval time.Duration  `json:"poll_interval"`
+	LastPoll     time.Time      `json:"last_poll"`
+	NextPoll     time.Time      `json:"next_poll"`
+	ProjectCount int            `json:"project_count"`
+	Projects     []ProjectCheck `json:"projects"`
+
+	// Deployments running and waiting for a slot across all projects, whatever started them
+	Deploys project.DeployStats `json:"deploys"`
+}
+
+// Status returns a snapshot of the watcher state. It is safe to call while the poll loop is running.
+func (w *WatcherService) Status() WatcherStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	projects := make([]ProjectCheck, 0, len(w.checks))
+	for _, check := range w.checks {
+		projects = append(projects, *check)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ProjectName < projects[j].ProjectName
+	})
+
+	status := WatcherStatus{
+		Paused:       w.paused,
+		PollInterval: w.pollInterval,
+		LastPoll:     w.lastPoll,
+		NextPoll:     w.nextPoll,
+		ProjectCount: len(projects),
+		Projects:     projects,
+	}
+	if w.projectService != nil {
+		status.Deploys = w.projectService.DeployStats()
+	}
+	return status
+}
+
+// Pause stops the watcher from checking projects until Resume is called
+func (w *WatcherService) Pause() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = true
+}
+
+// Resume re-enables project checks after Pause
+func (w *WatcherService) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = false
+}
+
+// PollInterval returns the interval between poll cycles
+func (w *WatcherService) PollInterval() time.Duration {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.pollInterval
+}
+
+// SetPollInterval changes the interval between poll cycles, starting after the next cycle
+func (w *WatcherService) SetPollInterval(interval time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pollInterval = interval
+}
+
+// SetMetrics makes the watcher record its polls in the registry, which also drops the series of projects
+// that no longer exist. Call it before Start.
+func (w *WatcherService) SetMetrics(registry *metrics.Registry) {
+	w.metrics = registry
+}
+
+// SetCheckRepository makes the watcher store the result of every check and restores the results
+// stored by the previous run, so the last check and the count of consecutive failures survive a
+// restart. Call it before Start.
+func (w *WatcherService) SetCheckRepository(repo repository.WatcherCheckRepository) error {
+	stored, err := repo.List()
+	if err != nil {
+		return fmt.Errorf("failed to load watcher checks: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checkRepo = repo
+	for _, check := range stored {
+		w.checks[check.ProjectID] = &ProjectCheck{
+			ProjectID:           check.ProjectID,
+			ProjectName:         check.ProjectName,
+			CheckedAt:           check.CheckedAt,
+			Outcome:             CheckOutcome(check.Outcome),
+			Error:               check.Error,
+			ConsecutiveFailures: check.ConsecutiveFailures,
+			Manual:              check.Manual,
+		}
+	}
+	return nil
+}
+
+// SetCheckDependencies sets whether automatic deployments wait until the project's prerequisites run
+func (w *WatcherService) SetCheckDependencies(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checkDependencies = enabled
+}
+
+// shouldCheckDependencies reports whether automatic deployments wait for prerequisite projects
+func (w *WatcherService) shouldCheckDependencies() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.checkDependencies
+}
+
+// IsPaused reports whether project checks are currently paused
+func (w *WatcherService) IsPaused() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.paused
+}
+
+// recordCheck stores the result of a project check, in the database too when a check repository is set
+func (w *WatcherService) recordCheck(project *domain.Project, outcome CheckOutcome, checkErr error, manual bool) {
+	check, repo := w.updateCheck(project, outcome, checkErr, manual)
+	if repo == nil {
+		return
+	}
+
+	if err := repo.Save(&domain.WatcherCheck{
+		ProjectID:           check.ProjectID,
+		CheckedAt:           check.CheckedAt,
+		Outcome:             string(check.Outcome),
+		Error:               check.Error,
+		ConsecutiveFailures: check.ConsecutiveFailures,
+		Manual:              check.Manual,
+	}); err != nil {
+		slog.Error("Failed to save watcher check",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"error", err)
+	}
+}
+
+// updateCheck updates the in-memory result of a project check and returns a copy of it
+func (w *WatcherService) updateCheck(
+	project *domain.Project,
+	outcome CheckOutcome,
+	checkErr error,
+	manual bool,
+) (ProjectCheck, repository.WatcherCheckRepository) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	check, ok := w.checks[project.ID]
+	if !ok {
+		check = &ProjectCheck{ProjectID: project.ID}
+		w.checks[project.ID] = check
+	}
+
+	check.ProjectName = project.Name
+	check.CheckedAt = time.Now()
+	check.Outcome = outcome
+	check.Manual = manual
+	check.Error = ""
+	if checkErr != nil {
+		check.Error = checkErr.Error()
+		check.ConsecutiveFailures++
+	} else {
+		check.ConsecutiveFailures = 0
+	}
+	return *check, w.checkRepo
+}
+
+// lastCheck returns a copy of the most recent check of a project
+func (w *WatcherService) lastCheck(projectID uuid.UUID) (ProjectCheck, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	check, ok := w.checks[projectID]
+	if !ok {
+		return ProjectCheck{}, false
+	}
+	return *check, true
+}
+
+// checkedManuallySince reports whether the project was checked via CheckNow after the given time
+func (w *WatcherService) checkedManuallySince(projectID uuid.UUID, since time.Time) bool {
+	check, ok := w.lastCheck(projectID)
+	return ok && check.Manual && check.CheckedAt.After(since)
+}
+
+// projectLock returns the lock serializing checks of a project
+func (w *WatcherService) projectLock(projectID uuid.UUID) *sync.Mutex {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lock, ok := w.locks[projectID]
+	if !ok {
+		lock = &sync.Mutex{}
+		w.locks[projectID] = lock
+	}
+	return lock
+}
+
+// startPollCycle records the start of a poll cycle and forgets projects that no longer exist, including
+// their metrics
+func (w *WatcherService) startPollCycle(projects []*domain.Project) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastPoll = time.Now()
+
+	current := make(map[uuid.UUID]bool, len(projects))
+	names := make(map[uuid.UUID]string, len(projects))
+	for _, project := range projects {
+		current[project.ID] = true
+		names[project.ID] = project.Name
+	}
+	w.metrics.SetLastPoll(w.lastPoll)
+	w.metrics.RetainProjects(names)
+	for id := range w.checks {
+		if !current[id] {
+			delete(w.checks, id)
+		}
+	}
+	for id := range w.locks {
+		if !current[id] {
+			delete(w.locks, id)
+		}
+	}
+	for id := range w.held {
+		if !current[id] {
+			delete(w.held, id)
+		}
+	}
+	for id := range w.corrections {
+		if !current[id] {
+			delete(w.corrections, id)
+		}
+	}
+}
+
+// setNextPoll records when the next scheduled poll cycle will run
+func (w *WatcherService) setNextPoll(next time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.nextPoll = next
+}
@@ -0,0 +1,141 @@
+package watcher
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+)
+
+func TestWatcherStatusTracksConsecutiveFailures(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web"}
+
+	w.recordCheck(project, CheckOutcomeError, errors.New("fetch failed"), false)
+	w.recordCheck(project, CheckOutcomeError, errors.New("fetch failed again"), false)
+
+	status := w.Status()
+	require.Len(t, status.Projects, 1)
+	assert.Equal(t, CheckOutcomeError, status.Projects[0].Outcome)
+	assert.Equal(t, "fetch failed again", status.Projects[0].Error)
+	assert.Equal(t, 2, status.Projects[0].ConsecutiveFailures)
+
+	w.recordCheck(project, CheckOutcomeNoChanges, nil, false)
+
+	status = w.Status()
+	require.Len(t, status.Projects, 1)
+	assert.Equal(t, CheckOutcomeNoChanges, status.Projects[0].Outcome)
+	assert.Empty(t, status.Projects[0].Error)
+	assert.Equal(t, 0, status.Projects[0].ConsecutiveFailures)
+}
+
+func TestWatcherStatusForgetsRemovedProjects(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	kept := &domain.Project{ID: uuid.New(), Name: "kept"}
+	removed := &domain.Project{ID: uuid.New(), Name: "removed"}
+
+	w.recordCheck(kept, CheckOutcomeNoChanges, nil, false)
+	w.recordCheck(removed, CheckOutcomeNoChanges, nil, false)
+	w.startPollCycle([]*domain.Project{kept})
+
+	status := w.Status()
+	require.Len(t, status.Projects, 1)
+	assert.Equal(t, "kept", status.Projects[0].ProjectName)
+	assert.False(t, status.LastPoll.IsZero())
+}
+
+func TestWatcherStatusConcurrentAccess(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	projects := []*domain.Project{
+		{ID: uuid.New(), Name: "a"},
+		{ID: uuid.New(), Name: "b"},
+	}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			w.startPollCycle(projects)
+			w.recordCheck(projects[i%len(projects)], CheckOutcomeNoChanges, nil, false)
+			w.setNextPoll(time.Now().Add(time.Minute))
+		}()
+		go func() {
+			defer wg.Done()
+			w.Pause()
+			_ = w.Status()
+			w.Resume()
+		}()
+	}
+	wg.Wait()
+
+	assert.False(t, w.IsPaused())
+	assert.Len(t, w.Status().Projects, 2)
+}
+
+func TestWatcherChecksSurviveRestart(t *testing.T) {
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+
+	projects := repository.NewProjectRepository(database, nil)
+	project, err := projects.Create(&domain.Project{
+		ID:           uuid.New(),
+		Name:         "web",
+		GitURL:       "https://example.com/web.git",
+		GitBranch:    "main",
+		WorkingDir:   "/data/projects/web",
+		ComposeFiles: []string{"compose.yaml"},
+	})
+	require.NoError(t, err)
+	checks := repository.NewWatcherCheckRepository(database)
+
+	w := NewWatcherService(nil, nil, time.Minute)
+	require.NoError(t, w.SetCheckRepository(checks))
+	w.recordCheck(project, CheckOutcomeNoChanges, nil, false)
+	w.recordCheck(project, CheckOutcomeError, errors.New("fetch failed"), false)
+	w.recordCheck(project, CheckOutcomeError, errors.New("fetch failed again"), true)
+	before := w.Status().Projects
+
+	// A new watcher, as after a restart, starts with the last check and the failure count
+	restarted := NewWatcherService(nil, nil, time.Minute)
+	require.NoError(t, restarted.SetCheckRepository(checks))
+
+	after := restarted.Status().Projects
+	require.Len(t, after, 1)
+	assert.Equal(t, project.ID, after[0].ProjectID)
+	assert.Equal(t, "web", after[0].ProjectName)
+	assert.Equal(t, CheckOutcomeError, after[0].Outcome)
+	assert.Equal(t, "fetch failed again", after[0].Error)
+	assert.Equal(t, 2, after[0].ConsecutiveFailures)
+	assert.True(t, after[0].Manual)
+	assert.True(t, before[0].CheckedAt.Equal(after[0].CheckedAt))
+
+	// The failure count continues from the stored one and resets on success
+	restarted.recordCheck(project, CheckOutcomeError, errors.New("fetch failed once more"), false)
+	check, _ := restarted.lastCheck(project.ID)
+	assert.Equal(t, 3, check.ConsecutiveFailures)
+	restarted.recordCheck(project, CheckOutcomeNoChanges, nil, false)
+
+	stored, err := checks.List()
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, string(CheckOutcomeNoChanges), stored[0].Outcome)
+	assert.Empty(t, stored[0].Error)
+	assert.Zero(t, stored[0].ConsecutiveFailures)
+
+	// The check goes away with its project
+	require.NoError(t, projects.Delete(project.ID))
+	stored, err = checks.List()
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
@@ -3,20 +3,59 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
 	"github.com/oar-cd/oar/git"
+	"github.com/oar-cd/oar/metrics"
 	"github.com/oar-cd/oar/project"
+	"github.com/oar-cd/oar/repository"
 )
 
 type WatcherService struct {
 	projectService project.ProjectManager
 	gitService     *git.GitService
-	pollInterval   time.Duration
+
+	// mu guards the status fields below, which are read by Status() concurrently with the poll loop
+	mu           sync.RWMutex
+	pollInterval time.Duration
+	paused       bool
+	lastPoll     time.Time
+	nextPoll     time.Time
+	checks       map[uuid.UUID]*ProjectCheck
+	// held are automatic deployments that failed on missing compose files or on broken references of
+	// them, by project. They are not retried until the project or its commit changes.
+	held map[uuid.UUID]heldDeployment
+	// corrections are status corrections made by syncProjectStatus that are not notified yet, by project
+	corrections map[uuid.UUID]statusCorrection
+
+	// checkDependencies skips automatic deployments while a prerequisite project is not running
+	checkDependencies bool
+
+	// metrics records the polls, nil when metrics are not collected
+	metrics *metrics.Registry
+
+	// checkRepo stores the last check of each project across restarts, nil when checks are kept in memory only
+	checkRepo repository.WatcherCheckRepository
+
+	// locks serializes checks of the same project between the poll loop and CheckNow
+	locks map[uuid.UUID]*sync.Mutex
+
+	// webhookMu guards the debounced webhook state
+	webhookMu          sync.Mutex
+	webhookQuietPeriod time.Duration
+	webhookMaxDelay    time.Duration
+	webhooks           map[uuid.UUID]*webhookState
+	webhooksStopped    bool // Set when the watcher shuts down, later deliveries are ignored
+	webhookCheck       func(projectID uuid.UUID)
 }
 
 func NewWatcherService(
@@ -24,33 +63,53 @@ func NewWatcherService(
 	gitService *git.GitService,
 	pollInterval time.Duration,
 ) *WatcherService {
-	return &WatcherService{
+	w := &WatcherService{
 		projectService: projectService,
 		gitService:     gitService,
 		pollInterval:   pollInterval,
+		checks:         make(map[uuid.UUID]*ProjectCheck),
+		held:           make(map[uuid.UUID]heldDeployment),
+		corrections:    make(map[uuid.UUID]statusCorrection),
+		locks:          make(map[uuid.UUID]*sync.Mutex),
+		webhooks:       make(map[uuid.UUID]*webhookState),
 	}
+	w.webhookCheck = w.runWebhookCheck
+	return w
 }
 
 func (w *WatcherService) Start(ctx context.Context) error {
-	slog.Info("Watcher service starting", "poll_interval", w.pollInterval)
+	interval := w.PollInterval()
+	slog.Info("Watcher service starting", "poll_interval", interval)
 
-	ticker := time.NewTicker(w.pollInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	// Run initial check immediately
 	if err := w.checkAllProjects(ctx); err != nil {
 		slog.Error("Initial project check failed", "error", err)
 	}
+	w.setNextPoll(time.Now().Add(interval))
 
 	for {
 		select {
 		case <-ctx.Done():
 			slog.Info("Watcher service shutting down")
+			w.stopWebhooks()
 			return nil
 		case <-ticker.C:
-			if err := w.checkAllProjects(ctx); err != nil {
+			if w.IsPaused() {
+				slog.Debug("Watcher paused, skipping project check cycle")
+			} else if err := w.checkAllProjects(ctx); err != nil {
 				slog.Error("Project check failed", "error", err)
 			}
+
+			// Pick up a poll interval changed by a config reload
+			if current := w.PollInterval(); current != interval {
+				slog.Info("Watcher poll interval changed", "old", interval, "new", current)
+				interval = current
+				ticker.Reset(interval)
+			}
+			w.setNextPoll(time.Now().Add(interval))
 		}
 	}
 }
@@ -63,29 +122,12 @@ func (w *WatcherService) checkAllProjects(ctx context.Context) error {
 		return fmt.Errorf("failed to list projects: %w", err)
 	}
 
+	w.startPollCycle(projects)
+
 	projectsChecked := 0
 	for _, project := range projects {
-		// Sync Docker status for all projects - detects mismatches and updates database
-		if err := w.syncProjectStatus(ctx, project); err != nil {
-			slog.Error("Failed to sync project status",
-				"project_id", project.ID,
-				"project_name", project.Name,
-				"error", err)
-		}
-
-		// Check git changes for all projects to keep RemoteCommit updated
-		slog.Debug("Checking project",
-			"project_id", project.ID,
-			"project_name", project.Name,
-			"status", project.Status.String(),
-			"auto_deploy_enabled", project.AutoDeployEnabled)
-
-		projectsChecked++
-		if err := w.checkProject(ctx, project); err != nil {
-			slog.Error("Failed to check project",
-				"project_id", project.ID,
-				"project_name", project.Name,
-				"error", err)
+		if w.runScheduledCheck(ctx, project) {
+			projectsChecked++
 		}
 	}
 
@@ -96,23 +138,155 @@ func (w *WatcherService) checkAllProjects(ctx context.Context) error {
 	return nil
 }
 
-func (w *WatcherService) checkProject(ctx context.Context, project *domain.Project) error {
+// runScheduledCheck syncs and checks a single project as part of a poll cycle.
+// It returns false when the project was skipped because the watcher is disabled for it, or because a
+// manual check is running or has just run.
+func (w *WatcherService) runScheduledCheck(ctx context.Context, project *domain.Project) bool {
+	// Disabled with oar watcher disable, e.g. for all staging projects at once
+	if project.WatcherDisabled {
+		slog.Debug("Skipping project, watcher disabled for it",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return false
+	}
+
+	lock := w.projectLock(project.ID)
+	if !lock.TryLock() {
+		slog.Debug("Skipping project, manual check in progress",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return false
+	}
+	defer lock.Unlock()
+
+	if w.checkedManuallySince(project.ID, time.Now().Add(-w.PollInterval()/2)) {
+		slog.Debug("Skipping project, checked manually moments ago",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return false
+	}
+
+	// The project's containers and directory are going away, and so is the project
+	if w.projectService.IsRemoving(project.ID) {
+		slog.Debug("Skipping project, removal in progress",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return false
+	}
+
+	// Sync Docker status for all projects - detects mismatches and updates database
+	if err := w.syncProjectStatus(ctx, project); err != nil {
+		slog.Error("Failed to sync project status",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"error", err)
+	}
+
+	// Archive projects have no repository to check
+	if !project.UsesGit() {
+		return true
+	}
+
+	// Check git changes for all projects to keep RemoteCommit updated
+	slog.Debug("Checking project",
+		"project_id", project.ID,
+		"project_name", project.Name,
+		"status", project.Status.String(),
+		"auto_deploy_enabled", project.AutoDeployEnabled)
+
+	outcome, err := w.checkProject(ctx, project)
+	if err != nil {
+		slog.Error("Failed to check project",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"error", err)
+	}
+	w.recordCheck(project, outcome, err, false)
+
+	return true
+}
+
+// CheckNow checks a single project immediately, outside the poll schedule, and returns the result.
+// If another check of the project finishes while this one waits for the project lock, its result
+// is returned instead of checking twice.
+func (w *WatcherService) CheckNow(ctx context.Context, projectID uuid.UUID) (ProjectCheck, error) {
+	requestedAt := time.Now()
+
+	lock := w.projectLock(projectID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if check, ok := w.lastCheck(projectID); ok && check.CheckedAt.After(requestedAt) {
+		return check, nil
+	}
+
+	project, err := w.projectService.Get(projectID)
+	if err != nil {
+		return ProjectCheck{}, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	slog.Info("Manual project check requested",
+		"project_id", project.ID,
+		"project_name", project.Name)
+
+	outcome, err := w.checkProject(ctx, project)
+	if err != nil {
+		slog.Error("Manual project check failed",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"error", err)
+	}
+	w.recordCheck(project, outcome, err, true)
+
+	check, _ := w.lastCheck(projectID)
+	return check, nil
+}
+
+func (w *WatcherService) checkProject(ctx context.Context, project *domain.Project) (CheckOutcome, error) {
+	if !project.UsesGit() {
+		return CheckOutcomeError, fmt.Errorf("project is deployed from an archive and has no repository to check")
+	}
+
 	currentCommit := project.LocalCommitStr()
 
 	gitDir, err := project.GitDir()
 	if err != nil {
-		return fmt.Errorf("failed to get git directory: %w", err)
+		return CheckOutcomeError, fmt.Errorf("failed to get git directory: %w", err)
+	}
+
+	// The variables referenced by the URL and branch may have changed since the last check
+	source, err := project.ResolveGitSource()
+	if err != nil {
+		return CheckOutcomeError, err
+	}
+	if err := w.gitService.SetRemoteURL(gitDir, source.URL); err != nil {
+		return CheckOutcomeError, err
 	}
 
 	// Fetch latest changes from remote
-	if err := w.gitService.Fetch(project.GitBranch, project.GitAuth, gitDir); err != nil {
-		return fmt.Errorf("failed to fetch from remote: %w", err)
+	fetchOutput, err := w.gitService.Fetch(source.Branch, project.GitAuth, gitDir)
+	if err != nil {
+		if missingErr := w.checkBranchMissing(project, source); missingErr != nil {
+			return CheckOutcomeError, missingErr
+		}
+		return CheckOutcomeError, fmt.Errorf("failed to fetch from remote: %w", source.RedactError(err))
 	}
+	slog.Debug("Fetched from remote", "project_id", project.ID, "output", source.Redact(fetchOutput))
 
 	// Get latest commit hash from remote
-	remoteCommit, err := w.gitService.GetRemoteLatestCommit(gitDir, project.GitBranch)
+	remoteCommit, err := w.gitService.GetRemoteLatestCommit(gitDir, source.Branch)
 	if err != nil {
-		return fmt.Errorf("failed to get remote commit: %w", err)
+		return CheckOutcomeError, fmt.Errorf("failed to get remote commit: %w", err)
+	}
+
+	// With a watch URL, new commits are detected there and pulled from the git URL once it has them,
+	// e.g. from a read-only mirror that follows the canonical repository with a delay
+	pulledCommit := remoteCommit
+	if source.WatchURL != "" {
+		remoteCommit, err = w.gitService.GetBranchHead(source.WatchURL, source.Branch, project.GitAuth)
+		if err != nil {
+			return CheckOutcomeError, fmt.Errorf("failed to check watch URL: %w", source.RedactError(err))
+		}
 	}
 
 	// Update RemoteCommit for all projects (informational, independent of auto-deploy).
@@ -140,16 +314,35 @@ func (w *WatcherService) checkProject(ctx context.Context, project *domain.Proje
 		"has_updates", currentCommit != remoteCommit,
 		"auto_deploy_enabled", project.AutoDeployEnabled)
 
+	// Deploying before the git URL has the watched commit would deploy an older one, and again on every
+	// check until it does
+	if source.WatchURL != "" && pulledCommit != remoteCommit {
+		slog.Info("Watched commit not yet available to pull, waiting for the git URL to catch up",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"watched_commit", remoteCommit,
+			"pullable_commit", pulledCommit)
+		if currentCommit != remoteCommit {
+			return CheckOutcomeUpdatesAvailable, nil
+		}
+		return CheckOutcomeNoChanges, nil
+	}
+
 	// Only deploy if auto-deploy is enabled AND project is not stopped AND (there are git changes OR project is in error state)
-	// We don't auto-deploy stopped projects even if they have updates - user explicitly stopped them
+	// We don't auto-deploy stopped or paused projects even if they have updates - user explicitly stopped
+	// or froze them.
+	// A project that skips git pull on automatic deployments only has its current commit redeployed.
+	// A project being deployed is left alone, the next check deploys a commit it did not include.
 	hasGitChanges := currentCommit != remoteCommit
-	isInErrorState := project.Status != domain.ProjectStatusRunning && project.Status != domain.ProjectStatusStopped
-	shouldDeploy := project.AutoDeployEnabled && project.Status != domain.ProjectStatusStopped &&
-		(hasGitChanges || isInErrorState)
+	deployNewCommit := hasGitChanges && !project.AutoDeploySkipGitPull
+	deploying := project.Status == domain.ProjectStatusDeploying || w.projectService.IsDeploying(project.ID)
+	heldByUser := project.Status == domain.ProjectStatusStopped || project.Status == domain.ProjectStatusPaused
+	isInErrorState := project.Status != domain.ProjectStatusRunning && !heldByUser
+	shouldDeploy := project.AutoDeployEnabled && !heldByUser && !deploying && (deployNewCommit || isInErrorState)
 
 	if shouldDeploy {
 		var reason string
-		if hasGitChanges {
+		if deployNewCommit {
 			reason = "new commit detected"
 			slog.Info("New commit detected, triggering automatic deployment",
 				"project_id", project.ID,
@@ -165,51 +358,142 @@ func (w *WatcherService) checkProject(ctx context.Context, project *domain.Proje
 				"target_commit", remoteCommit)
 		}
 
-		// TODO: Consider creating a dedicated method for automatic deployments
-		// instead of using DeployPiping(). This would allow for:
-		// - Better logging/tracking of automatic vs manual deployments
-		// - Different error handling strategies
-		// - Deployment throttling/rate limiting
-		// - Automatic deployment-specific configuration
-		if err := w.projectService.DeployPiping(project.ID, true); err != nil {
-			slog.Error("Automatic deployment failed",
+		if w.shouldCheckDependencies() && len(project.DependsOn) > 0 {
+			if err := w.projectService.CheckDependencies(project.ID); err != nil {
+				slog.Warn("Skipping automatic deployment, a prerequisite is not ready",
+					"project_id", project.ID,
+					"project_name", project.Name,
+					"reason", reason,
+					"error", err)
+				return CheckOutcomeError, fmt.Errorf("skipped automatic deployment: %w", err)
+			}
+		}
+
+		// Without git pull, the current commit is redeployed
+		opts := autoDeployOptions(project, hasGitChanges)
+		deployedCommit := remoteCommit
+		if !opts.GitPull {
+			deployedCommit = currentCommit
+		}
+		// Retrying would fail the same way on every poll, until a new commit or a project change fixes it
+		if err := w.heldDeployment(project, deployedCommit); err != nil {
+			slog.Debug("Skipping automatic deployment held after a compose file error",
 				"project_id", project.ID,
 				"project_name", project.Name,
-				"reason", reason,
-				"target_commit", remoteCommit,
-				"error", err)
-			return fmt.Errorf("failed to deploy project: %w", err)
+				"target_commit", deployedCommit)
+			return CheckOutcomeError, fmt.Errorf("skipped automatic deployment: %w", err)
 		}
-
-		// Update the project's LocalCommit to the newly deployed commit
-		project.LocalCommit = &remoteCommit
-		if err := w.projectService.Update(project); err != nil {
-			slog.Error("Failed to update project LocalCommit after successful deployment",
+		err := w.projectService.DeployAutomaticPiping(project.ID, opts)
+		if deploymentDeferred(err) {
+			// The commit is checked again on the next poll, once the other operation has ended
+			slog.Info("Deferring automatic deployment, another operation on the project is in progress",
 				"project_id", project.ID,
 				"project_name", project.Name,
-				"deployed_commit", remoteCommit,
-				"error", err)
-			// Don't return error here as deployment was successful
-			// This is just a tracking issue that won't break functionality
-		} else {
-			slog.Debug("Updated project LocalCommit after successful deployment",
+				"reason", reason,
+				"target_commit", deployedCommit)
+			return CheckOutcomeDeferred, nil
+		}
+		w.recordDeployment(project, deployedCommit, err)
+		if err != nil {
+			slog.Error("Automatic deployment failed",
 				"project_id", project.ID,
 				"project_name", project.Name,
-				"deployed_commit", remoteCommit)
+				"reason", reason,
+				"target_commit", deployedCommit,
+				"error", err)
+			return CheckOutcomeError, fmt.Errorf("failed to deploy project: %w", err)
+		}
+
+		// The deployment stored the new LocalCommit with its outcome. Saving this copy would overwrite
+		// what the deployment stored, such as the pinned image digests, so only the copy is updated.
+		if opts.GitPull {
+			project.LocalCommit = &remoteCommit
 		}
 
 		slog.Info("Automatic deployment completed successfully",
 			"project_id", project.ID,
 			"project_name", project.Name,
 			"reason", reason,
-			"deployed_commit", remoteCommit)
+			"deployed_commit", deployedCommit)
+
+		return CheckOutcomeDeployed, nil
 	}
 
-	return nil
+	if hasGitChanges {
+		return CheckOutcomeUpdatesAvailable, nil
+	}
+	return CheckOutcomeNoChanges, nil
+}
+
+// heldDeployment is an automatic deployment that failed on missing compose files or a broken reference
+type heldDeployment struct {
+	key string
+	err error
+}
+
+// deploymentKey identifies what an automatic deployment of p at commit deploys
+func deploymentKey(p *domain.Project, commit string) string {
+	return commit + "\x00" + strings.Join(p.EnabledComposeFiles(), "\x00")
+}
+
+// heldDeployment returns the error of the failed automatic deployment of p at commit that is held,
+// or nil when the deployment is not held
+func (w *WatcherService) heldDeployment(p *domain.Project, commit string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	held, ok := w.held[p.ID]
+	if !ok || held.key != deploymentKey(p, commit) {
+		return nil
+	}
+	return held.err
+}
+
+// recordDeployment holds the automatic deployment of p at commit when it failed on missing compose
+// files or on a broken include or extends reference, and releases any held deployment of p otherwise
+func (w *WatcherService) recordDeployment(p *domain.Project, commit string, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var missingErr *project.MissingComposeFilesError
+	var referenceErr *docker.BrokenReferenceError
+	if errors.As(err, &missingErr) || errors.As(err, &referenceErr) {
+		w.held[p.ID] = heldDeployment{key: deploymentKey(p, commit), err: err}
+		return
+	}
+	delete(w.held, p.ID)
+}
+
+// deploymentDeferred reports whether an automatic deployment gave way to another operation on its
+// project, see project.ErrDeploymentDeferred
+func deploymentDeferred(err error) bool {
+	return errors.Is(err, project.ErrDeploymentDeferred)
+}
+
+// autoDeployOptions returns how the watcher deploys p, following its automatic deployment settings.
+// A new commit of a running project is deployed with the smallest action its configuration change
+// needs. A project in error state is deployed in full, to bring every service back.
+func autoDeployOptions(p *domain.Project, hasGitChanges bool) project.AutoDeployOptions {
+	opts := project.AutoDeployOptions{
+		GitPull:    !p.AutoDeploySkipGitPull,
+		PullImages: p.AutoDeployPullImages,
+	}
+	opts.ChangesOnly = opts.GitPull && hasGitChanges && p.Status == domain.ProjectStatusRunning
+	return opts
 }
 
 // syncProjectStatus checks if the project's database status matches its actual Docker status and updates it if needed
+// Corrections are notified once the next sync finds them still holding, see settleCorrection.
 func (w *WatcherService) syncProjectStatus(ctx context.Context, project *domain.Project) error {
+	// A deployment records the status of its project when it ends. Until then the containers may
+	// be half replaced, and correcting the status would overwrite the deployment's changes.
+	if w.projectService.IsDeploying(project.ID) {
+		slog.Debug("Skipping status sync, deployment in progress",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return nil
+	}
+
 	// Get the actual Docker status
 	composeStatus, err := w.projectService.GetStatus(project.ID)
 	if err != nil {
@@ -225,32 +509,40 @@ func (w *WatcherService) syncProjectStatus(ctx context.Context, project *domain.
 				"project_name", project.Name,
 				"previous_status", project.Status.String())
 
+			previous := project.Status
 			project.Status = domain.ProjectStatusUnknown
 			if updateErr := w.projectService.Update(project); updateErr != nil {
 				return fmt.Errorf("failed to update project status to unknown: %w", updateErr)
 			}
+			w.recordCorrection(project, previous, nil)
+		} else {
+			w.settleCorrection(project)
 		}
 		return fmt.Errorf("failed to get Docker status: %w", err)
 	}
 
-	// Determine what the database status should be based on Docker status
-	var expectedStatus domain.ProjectStatus
-	switch composeStatus.Status {
-	case docker.ComposeProjectStatusRunning:
-		expectedStatus = domain.ProjectStatusRunning
-	case docker.ComposeProjectStatusStopped:
-		expectedStatus = domain.ProjectStatusStopped
-	case docker.ComposeProjectStatusFailed:
-		// For failed status, we'll consider it as error since containers are in mixed states
-		expectedStatus = domain.ProjectStatusError
-	case docker.ComposeProjectStatusUnknown:
-		// For unknown status, set database to unknown as well
-		expectedStatus = domain.ProjectStatusUnknown
-	default:
-		// Should not happen, but default to unknown
-		expectedStatus = domain.ProjectStatusUnknown
+	if composeStatus.Status == docker.ComposeProjectStatusDeploying {
+		slog.Debug("Skipping status sync, deployment started",
+			"project_id", project.ID,
+			"project_name", project.Name)
+		return nil
 	}
 
+	// A paused project is held by the user. While any of its containers is still frozen, the others
+	// being down does not make it an error; resuming it shows their state. Once no container is
+	// paused, e.g. after docker compose unpause, the status is synced as usual.
+	if project.Status == domain.ProjectStatusPaused && hasPausedContainer(composeStatus.Containers) {
+		slog.Debug("Skipping status sync, project is paused",
+			"project_id", project.ID,
+			"project_name", project.Name,
+			"docker_status", composeStatus.Status.String())
+		w.settleCorrection(project)
+		return nil
+	}
+
+	// Determine what the database status should be based on Docker status
+	expectedStatus := composeStatus.Status.ProjectStatus()
+
 	// Check if there's a mismatch
 	if project.Status != expectedStatus {
 		slog.Warn("Project status mismatch detected - updating database",
@@ -261,16 +553,45 @@ func (w *WatcherService) syncProjectStatus(ctx context.Context, project *domain.
 			"updating_to", expectedStatus.String())
 
 		// Update the project status in the database
+		previous := project.Status
 		project.Status = expectedStatus
 		if err := w.projectService.Update(project); err != nil {
 			return fmt.Errorf("failed to update project status: %w", err)
 		}
+		w.recordCorrection(project, previous, composeStatus.Containers)
 
 		slog.Info("Project status updated successfully",
 			"project_id", project.ID,
 			"project_name", project.Name,
 			"new_status", expectedStatus.String())
+	} else {
+		w.settleCorrection(project)
 	}
 
 	return nil
 }
+
+func hasPausedContainer(containers []docker.ContainerInfo) bool {
+	return slices.ContainsFunc(containers, func(c docker.ContainerInfo) bool { return c.State == "paused" })
+}
+
+// checkBranchMissing returns an error describing a failed fetch when the project's branch no longer
+// exists on the remote, e.g. because the default branch was renamed from master to main, and nil when
+// the branch exists or the remote cannot be listed
+func (w *WatcherService) checkBranchMissing(project *domain.Project, source *domain.GitSource) error {
+	exists, err := w.gitService.BranchExists(source.URL, source.Branch, project.GitAuth)
+	if err != nil || exists {
+		return nil
+	}
+
+	msg := fmt.Sprintf("branch '%s' no longer exists on the remote", source.Branch)
+	if detected, err := w.gitService.GetDefaultBranch(source.URL, project.GitAuth); err == nil {
+		msg += fmt.Sprintf(", whose default branch is now '%s': run 'oar project branch %s --redetect' "+
+			"to switch to it", detected, project.ID)
+	}
+	slog.Warn("Project branch no longer exists on the remote",
+		"project_id", project.ID,
+		"project_name", project.Name,
+		"git_branch", project.GitBranch)
+	return errors.New(msg)
+}
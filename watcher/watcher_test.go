@@ -0,0 +1,597 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/config"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/git"
+	"github.com/oar-cd/oar/project"
+)
+
+// The scheduled checks below return before touching the project or git services, so nil services are fine
+
+func TestScheduledCheckSkipsProjectCheckedManually(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web"}
+
+	w.recordCheck(project, CheckOutcomeNoChanges, nil, true)
+
+	assert.False(t, w.runScheduledCheck(context.Background(), project))
+}
+
+func TestScheduledCheckSkipsProjectWithCheckInProgress(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web"}
+
+	lock := w.projectLock(project.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	assert.False(t, w.runScheduledCheck(context.Background(), project))
+}
+
+func TestCheckNowReusesCheckFinishedWhileWaiting(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web"}
+
+	// Simulate a scheduled check holding the lock and finishing after CheckNow was requested
+	lock := w.projectLock(project.ID)
+	lock.Lock()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		w.recordCheck(project, CheckOutcomeUpdatesAvailable, nil, false)
+		lock.Unlock()
+	}()
+
+	check, err := w.CheckNow(context.Background(), project.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckOutcomeUpdatesAvailable, check.Outcome)
+	assert.False(t, check.Manual)
+}
+
+// removingProjects reports every project as being removed. Any other call fails the test, through the
+// nil embedded interface.
+type removingProjects struct {
+	project.ProjectManager
+}
+
+func (removingProjects) IsRemoving(uuid.UUID) bool { return true }
+
+func TestScheduledCheckSkipsProjectBeingRemoved(t *testing.T) {
+	w := NewWatcherService(removingProjects{}, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web", AutoDeployEnabled: true}
+
+	assert.False(t, w.runScheduledCheck(context.Background(), project))
+}
+
+func TestScheduledCheckSkipsProjectWithWatcherDisabled(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web", AutoDeployEnabled: true, WatcherDisabled: true}
+
+	assert.False(t, w.runScheduledCheck(context.Background(), project))
+}
+
+// listedProjects lists its projects and records which of them the poll cycle went on to check, stopping
+// each check by reporting the project as being removed
+type listedProjects struct {
+	project.ProjectManager
+	projects []*domain.Project
+	checked  []uuid.UUID
+}
+
+func (p *listedProjects) List() ([]*domain.Project, error) { return p.projects, nil }
+
+func (p *listedProjects) IsRemoving(id uuid.UUID) bool {
+	p.checked = append(p.checked, id)
+	return true
+}
+
+func TestCheckAllProjectsSkipsProjectsWithWatcherDisabled(t *testing.T) {
+	staging := &domain.Project{ID: uuid.New(), Name: "shop-staging", WatcherDisabled: true}
+	production := &domain.Project{ID: uuid.New(), Name: "shop"}
+	projects := &listedProjects{projects: []*domain.Project{staging, production}}
+	w := NewWatcherService(projects, nil, time.Minute)
+
+	require.NoError(t, w.checkAllProjects(context.Background()))
+	assert.Equal(t, []uuid.UUID{production.ID}, projects.checked)
+}
+
+// deployingProjects reports every project as being deployed. Any other call fails the test, through
+// the nil embedded interface.
+type deployingProjects struct {
+	project.ProjectManager
+}
+
+func (deployingProjects) IsDeploying(uuid.UUID) bool { return true }
+
+func TestSyncProjectStatusSkipsProjectBeingDeployed(t *testing.T) {
+	w := NewWatcherService(deployingProjects{}, nil, time.Minute)
+	project := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusStopped}
+
+	assert.NoError(t, w.syncProjectStatus(context.Background(), project))
+	assert.Equal(t, domain.ProjectStatusStopped, project.Status)
+}
+
+func TestAutoDeployOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		project       domain.Project
+		hasGitChanges bool
+		want          project.AutoDeployOptions
+	}{
+		{
+			name:          "new commit of a running project",
+			project:       domain.Project{Status: domain.ProjectStatusRunning},
+			hasGitChanges: true,
+			want:          project.AutoDeployOptions{GitPull: true, ChangesOnly: true},
+		},
+		{
+			name:    "project in error state",
+			project: domain.Project{Status: domain.ProjectStatusError},
+			want:    project.AutoDeployOptions{GitPull: true},
+		},
+		{
+			name:          "project that also pulls images",
+			project:       domain.Project{Status: domain.ProjectStatusRunning, AutoDeployPullImages: true},
+			hasGitChanges: true,
+			want:          project.AutoDeployOptions{GitPull: true, PullImages: true, ChangesOnly: true},
+		},
+		{
+			name: "project that skips git pull",
+			project: domain.Project{
+				Status:                domain.ProjectStatusError,
+				AutoDeploySkipGitPull: true,
+				AutoDeployPullImages:  true,
+			},
+			hasGitChanges: true,
+			want:          project.AutoDeployOptions{PullImages: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, autoDeployOptions(&tt.project, tt.hasGitChanges))
+		})
+	}
+}
+
+func TestDeploymentHeldAfterMissingComposeFiles(t *testing.T) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	p := &domain.Project{ID: uuid.New(), Name: "web", ComposeFiles: []string{"compose.yaml", "compose.prod.yaml"}}
+
+	missingErr := fmt.Errorf("deployment failed: %w",
+		&project.MissingComposeFilesError{Files: []string{"compose.prod.yaml"}, Commit: "abc123"})
+	w.recordDeployment(p, "abc123", missingErr)
+
+	t.Run("same commit is held", func(t *testing.T) {
+		assert.ErrorIs(t, w.heldDeployment(p, "abc123"), missingErr)
+	})
+
+	t.Run("new commit is deployed", func(t *testing.T) {
+		assert.NoError(t, w.heldDeployment(p, "def456"))
+	})
+
+	t.Run("changed compose files are deployed", func(t *testing.T) {
+		changed := *p
+		changed.ComposeFiles = []string{"compose.yaml"}
+		assert.NoError(t, w.heldDeployment(&changed, "abc123"))
+	})
+
+	t.Run("broken references are held", func(t *testing.T) {
+		referenceErr := fmt.Errorf("broken compose file reference: %w",
+			&docker.BrokenReferenceError{File: "compose.yaml", Service: "web", Target: "base.yaml"})
+		w.recordDeployment(p, "abc123", referenceErr)
+		assert.ErrorIs(t, w.heldDeployment(p, "abc123"), referenceErr)
+	})
+
+	t.Run("other failures are retried", func(t *testing.T) {
+		w.recordDeployment(p, "abc123", errors.New("docker compose up failed"))
+		assert.NoError(t, w.heldDeployment(p, "abc123"))
+	})
+}
+
+// statusProjects serves the Docker status of a project and records the status corrections it is
+// notified of. Any other call fails the test, through the nil embedded interface.
+type statusProjects struct {
+	project.ProjectManager
+	status          docker.ComposeStatus
+	lastManualOp    time.Time
+	notifiedChanges []string
+}
+
+func (*statusProjects) IsDeploying(uuid.UUID) bool { return false }
+
+func (p *statusProjects) GetStatus(uuid.UUID) (*docker.ComposeStatus, error) {
+	status := p.status
+	return &status, nil
+}
+
+func (*statusProjects) Update(*domain.Project) error { return nil }
+
+func (p *statusProjects) LastManualOperation(uuid.UUID) time.Time { return p.lastManualOp }
+
+func (p *statusProjects) NotifyStatusCorrection(
+	project *domain.Project,
+	previous domain.ProjectStatus,
+	containers []docker.ContainerInfo,
+) {
+	change := previous.String() + " -> " + project.Status.String()
+	for _, container := range containers {
+		change += " " + container.Name + ":" + container.State
+	}
+	p.notifiedChanges = append(p.notifiedChanges, change)
+}
+
+func TestSyncProjectStatusNotifiesCorrections(t *testing.T) {
+	stopped := docker.ComposeStatus{
+		Status:     docker.ComposeProjectStatusStopped,
+		Containers: []docker.ContainerInfo{{Name: "web-1", State: "exited", ExitCode: 137}},
+	}
+	running := docker.ComposeStatus{
+		Status:     docker.ComposeProjectStatusRunning,
+		Containers: []docker.ContainerInfo{{Name: "web-1", State: "running"}},
+	}
+
+	t.Run("error corrected to stopped", func(t *testing.T) {
+		projects := &statusProjects{status: stopped}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusError}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusStopped, p.Status)
+		assert.Empty(t, projects.notifiedChanges, "notified before the correction held")
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, []string{"error -> stopped web-1:exited"}, projects.notifiedChanges)
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Len(t, projects.notifiedChanges, 1, "notified twice")
+	})
+
+	t.Run("status that flaps back is not notified", func(t *testing.T) {
+		projects := &statusProjects{status: stopped}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusRunning}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		projects.status = running
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusRunning, p.Status)
+		assert.Empty(t, projects.notifiedChanges)
+	})
+
+	t.Run("corrections of a series are notified once", func(t *testing.T) {
+		projects := &statusProjects{status: docker.ComposeStatus{Status: docker.ComposeProjectStatusFailed}}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusRunning}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		projects.status = stopped
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, []string{"running -> stopped web-1:exited"}, projects.notifiedChanges)
+	})
+
+	t.Run("correction after a stop by a user is expected", func(t *testing.T) {
+		projects := &statusProjects{status: stopped, lastManualOp: time.Now().Add(-30 * time.Second)}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusRunning}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusStopped, p.Status)
+		assert.Empty(t, projects.notifiedChanges)
+	})
+
+	t.Run("correction long after a stop by a user is notified", func(t *testing.T) {
+		projects := &statusProjects{status: stopped, lastManualOp: time.Now().Add(-time.Hour)}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusRunning}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, []string{"running -> stopped web-1:exited"}, projects.notifiedChanges)
+	})
+}
+
+func TestSyncProjectStatusLeavesPausedProjects(t *testing.T) {
+	partlyDown := docker.ComposeStatus{
+		Status: docker.ComposeProjectStatusFailed,
+		Containers: []docker.ContainerInfo{
+			{Name: "web-1", State: "paused"},
+			{Name: "worker-1", State: "exited", ExitCode: 1},
+		},
+	}
+
+	t.Run("containers down while others are frozen", func(t *testing.T) {
+		projects := &statusProjects{status: partlyDown}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusPaused}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusPaused, p.Status)
+		assert.Empty(t, projects.notifiedChanges)
+	})
+
+	t.Run("unpaused outside of Oar", func(t *testing.T) {
+		projects := &statusProjects{status: docker.ComposeStatus{
+			Status:     docker.ComposeProjectStatusRunning,
+			Containers: []docker.ContainerInfo{{Name: "web-1", State: "running"}},
+		}}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusPaused}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusRunning, p.Status)
+	})
+
+	t.Run("paused outside of Oar", func(t *testing.T) {
+		projects := &statusProjects{status: docker.ComposeStatus{
+			Status:     docker.ComposeProjectStatusPaused,
+			Containers: []docker.ContainerInfo{{Name: "web-1", State: "paused"}},
+		}}
+		w := NewWatcherService(projects, nil, time.Minute)
+		p := &domain.Project{ID: uuid.New(), Name: "web", Status: domain.ProjectStatusRunning}
+
+		assert.NoError(t, w.syncProjectStatus(context.Background(), p))
+		assert.Equal(t, domain.ProjectStatusPaused, p.Status)
+	})
+}
+
+func TestCheckProjectWarnsAboutMissingBranch(t *testing.T) {
+	tempDir := t.TempDir()
+	originDir := filepath.Join(tempDir, "origin")
+	origin, err := gogit.PlainInit(originDir, false)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(originDir, "compose.yaml"), []byte("services: {}\n"), 0o644))
+	worktree, err := origin.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("compose.yaml")
+	require.NoError(t, err)
+	commit, err := worktree.Commit("Initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	p := &domain.Project{
+		ID:         uuid.New(),
+		Name:       "renamed-branch",
+		GitURL:     originDir,
+		GitBranch:  "master",
+		WorkingDir: filepath.Join(tempDir, "project"),
+	}
+	gitDir, err := p.GitDir()
+	require.NoError(t, err)
+	_, err = gogit.PlainClone(gitDir, &gogit.CloneOptions{URL: originDir})
+	require.NoError(t, err)
+
+	// The default branch of the remote is renamed from master to main
+	main := plumbing.NewBranchReferenceName("main")
+	require.NoError(t, origin.Storer.SetReference(plumbing.NewHashReference(main, commit)))
+	require.NoError(t, origin.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, main)))
+	require.NoError(t, origin.Storer.RemoveReference(plumbing.NewBranchReferenceName("master")))
+
+	// The fetch fails before the project service is used
+	w := NewWatcherService(nil, git.NewGitService(&config.Config{GitTimeout: 10 * time.Second}), time.Minute)
+	outcome, err := w.checkProject(context.Background(), p)
+	assert.Equal(t, CheckOutcomeError, outcome)
+	require.Error(t, err)
+	assert.Equal(t, fmt.Sprintf("branch 'master' no longer exists on the remote, whose default branch is now "+
+		"'main': run 'oar project branch %s --redetect' to switch to it", p.ID), err.Error())
+}
+
+// mirrorProjects deploys by pulling the project's repository, like the project service does
+type mirrorProjects struct {
+	project.ProjectManager
+	gitService *git.GitService
+	project    *domain.Project
+	deployed   []string
+}
+
+func (*mirrorProjects) IsDeploying(uuid.UUID) bool { return false }
+
+func (*mirrorProjects) Update(*domain.Project) error { return nil }
+
+func (p *mirrorProjects) DeployAutomaticPiping(_ uuid.UUID, opts project.AutoDeployOptions) error {
+	gitDir, err := p.project.GitDir()
+	if err != nil {
+		return err
+	}
+	if opts.GitPull {
+		if _, err := p.gitService.Pull(p.project.GitBranch, nil, gitDir); err != nil {
+			return err
+		}
+	}
+	commit, err := p.gitService.GetLatestCommit(gitDir)
+	if err != nil {
+		return err
+	}
+	p.deployed = append(p.deployed, commit)
+	return nil
+}
+
+func TestCheckProjectWatchesOneURLAndPullsFromAnother(t *testing.T) {
+	tempDir := t.TempDir()
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	commitFile := func(repo *gogit.Repository, dir, content string) plumbing.Hash {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(content), 0o644))
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add("compose.yaml")
+		require.NoError(t, err)
+		hash, err := worktree.Commit("Update compose file", &gogit.CommitOptions{Author: signature})
+		require.NoError(t, err)
+		return hash
+	}
+
+	// The canonical repository is watched, the project is cloned from and pulled from a read-only mirror
+	canonicalDir := filepath.Join(tempDir, "canonical")
+	canonical, err := gogit.PlainInit(canonicalDir, false)
+	require.NoError(t, err)
+	initial := commitFile(canonical, canonicalDir, "services: {}\n").String()
+	mirrorDir := filepath.Join(tempDir, "mirror")
+	mirror, err := gogit.PlainClone(mirrorDir, &gogit.CloneOptions{URL: canonicalDir})
+	require.NoError(t, err)
+
+	p := &domain.Project{
+		ID:                uuid.New(),
+		Name:              "mirrored",
+		GitURL:            mirrorDir,
+		GitBranch:         "master",
+		WatchURL:          canonicalDir,
+		WorkingDir:        filepath.Join(tempDir, "project"),
+		Status:            domain.ProjectStatusRunning,
+		LocalCommit:       &initial,
+		AutoDeployEnabled: true,
+	}
+	gitDir, err := p.GitDir()
+	require.NoError(t, err)
+	_, err = gogit.PlainClone(gitDir, &gogit.CloneOptions{URL: mirrorDir})
+	require.NoError(t, err)
+
+	gitService := git.NewGitService(&config.Config{GitTimeout: 10 * time.Second})
+	projects := &mirrorProjects{gitService: gitService, project: p}
+	w := NewWatcherService(projects, gitService, time.Minute)
+
+	pushed := commitFile(canonical, canonicalDir, "services:\n  web:\n    image: nginx\n").String()
+
+	t.Run("detects the commit before the mirror has it", func(t *testing.T) {
+		outcome, err := w.checkProject(context.Background(), p)
+		require.NoError(t, err)
+		assert.Equal(t, CheckOutcomeUpdatesAvailable, outcome)
+		require.NotNil(t, p.RemoteCommit)
+		assert.Equal(t, pushed, *p.RemoteCommit)
+		assert.Empty(t, projects.deployed, "The mirror does not have the commit to deploy yet")
+	})
+
+	t.Run("deploys from the mirror once it has the commit", func(t *testing.T) {
+		worktree, err := mirror.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, worktree.Pull(&gogit.PullOptions{RemoteName: "origin"}))
+
+		outcome, err := w.checkProject(context.Background(), p)
+		require.NoError(t, err)
+		assert.Equal(t, CheckOutcomeDeployed, outcome)
+		assert.Equal(t, []string{pushed}, projects.deployed)
+		assert.Equal(t, pushed, p.LocalCommitStr())
+
+		remote, err := gogit.PlainOpen(gitDir)
+		require.NoError(t, err)
+		origin, err := remote.Remote("origin")
+		require.NoError(t, err)
+		assert.Equal(t, []string{mirrorDir}, origin.Config().URLs)
+	})
+
+	t.Run("unreachable watch URL", func(t *testing.T) {
+		p.WatchURL = filepath.Join(tempDir, "missing")
+		outcome, err := w.checkProject(context.Background(), p)
+		assert.Equal(t, CheckOutcomeError, outcome)
+		assert.ErrorContains(t, err, "failed to check watch URL")
+	})
+}
+
+// storedProjects keeps the project as the database does: a deployment saves its outcome on the stored
+// project, and Update replaces the stored project with the copy it is given
+type storedProjects struct {
+	project.ProjectManager
+	gitService *git.GitService
+	stored     domain.Project
+	digests    []string
+}
+
+func (*storedProjects) IsDeploying(uuid.UUID) bool { return false }
+
+func (p *storedProjects) Update(project *domain.Project) error {
+	p.stored = *project
+	return nil
+}
+
+func (p *storedProjects) DeployAutomaticPiping(uuid.UUID, project.AutoDeployOptions) error {
+	gitDir, err := p.stored.GitDir()
+	if err != nil {
+		return err
+	}
+	if _, err := p.gitService.Pull(p.stored.GitBranch, nil, gitDir); err != nil {
+		return err
+	}
+	commit, err := p.gitService.GetLatestCommit(gitDir)
+	if err != nil {
+		return err
+	}
+	p.stored.LocalCommit = &commit
+	p.stored.ImageDigests = p.digests
+	return nil
+}
+
+func TestCheckProjectKeepsDigestsPinnedByDeployment(t *testing.T) {
+	tempDir := t.TempDir()
+	signature := &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	originDir := filepath.Join(tempDir, "origin")
+	origin, err := gogit.PlainInit(originDir, false)
+	require.NoError(t, err)
+	commitFile := func(content string) string {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(originDir, "compose.yaml"), []byte(content), 0o644))
+		worktree, err := origin.Worktree()
+		require.NoError(t, err)
+		_, err = worktree.Add("compose.yaml")
+		require.NoError(t, err)
+		hash, err := worktree.Commit("Update compose file", &gogit.CommitOptions{Author: signature})
+		require.NoError(t, err)
+		return hash.String()
+	}
+	initial := commitFile("services:\n  web:\n    image: nginx\n")
+
+	p := &domain.Project{
+		ID:                uuid.New(),
+		Name:              "pinned",
+		GitURL:            originDir,
+		GitBranch:         "master",
+		WorkingDir:        filepath.Join(tempDir, "project"),
+		Status:            domain.ProjectStatusRunning,
+		LocalCommit:       &initial,
+		RemoteCommit:      &initial,
+		AutoDeployEnabled: true,
+		PinImageDigests:   true,
+	}
+	gitDir, err := p.GitDir()
+	require.NoError(t, err)
+	_, err = gogit.PlainClone(gitDir, &gogit.CloneOptions{URL: originDir})
+	require.NoError(t, err)
+
+	gitService := git.NewGitService(&config.Config{GitTimeout: 10 * time.Second})
+	projects := &storedProjects{
+		gitService: gitService,
+		stored:     *p,
+		digests:    []string{"nginx:latest@sha256:" + strings.Repeat("a", 64)},
+	}
+	w := NewWatcherService(projects, gitService, time.Minute)
+
+	pushed := commitFile("services:\n  web:\n    image: nginx\n    restart: always\n")
+	outcome, err := w.checkProject(context.Background(), p)
+	require.NoError(t, err)
+	assert.Equal(t, CheckOutcomeDeployed, outcome)
+
+	assert.Equal(t, projects.digests, projects.stored.ImageDigests, "The digests pinned by the deployment are kept")
+	assert.Equal(t, pushed, projects.stored.LocalCommitStr())
+	assert.Equal(t, pushed, p.LocalCommitStr())
+}
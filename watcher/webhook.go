@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 signature of a delivery's body, as sha256=<hex>.
+// GitHub, Gitea and Forgejo send it when the webhook has a secret.
+const WebhookSignatureHeader = "X-Hub-Signature-256"
+
+// VerifyWebhookSignature reports whether signature, the value of WebhookSignatureHeader, is the
+// HMAC-SHA256 of body with secret
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// webhookState tracks debounced webhook deliveries for one project
+type webhookState struct {
+	timer      *time.Timer
+	generation uint64    // Bumped on every delivery so that a superseded timer does nothing when it fires
+	firstPush  time.Time // First delivery not yet handled, zero when no check is scheduled
+	running    bool      // A webhook-triggered check is in progress
+	pending    bool      // Another check is queued to run after the current one
+}
+
+// SetWebhookDebounce configures how webhook deliveries are collapsed. A check runs once no delivery
+// arrived for quietPeriod, or maxDelay after the first delivery if pushes keep coming. It must be
+// called before webhooks are handled, the delays come from the configuration.
+func (w *WatcherService) SetWebhookDebounce(quietPeriod, maxDelay time.Duration) {
+	w.webhookMu.Lock()
+	defer w.webhookMu.Unlock()
+	w.webhookQuietPeriod = quietPeriod
+	w.webhookMaxDelay = maxDelay
+}
+
+// HandleWebhook schedules a check of the project after a push notification and returns immediately.
+// Deliveries arriving within the quiet period are collapsed into a single check, which fetches and
+// deploys the latest commit. At most one webhook-triggered check runs per project, with at most one
+// more queued behind it.
+func (w *WatcherService) HandleWebhook(projectID uuid.UUID) {
+	w.webhookMu.Lock()
+	defer w.webhookMu.Unlock()
+
+	if w.webhooksStopped {
+		slog.Debug("Webhook ignored, watcher is shutting down", "project_id", projectID)
+		return
+	}
+
+	state, ok := w.webhooks[projectID]
+	if !ok {
+		state = &webhookState{}
+		w.webhooks[projectID] = state
+	}
+
+	now := time.Now()
+	if state.firstPush.IsZero() {
+		state.firstPush = now
+	}
+
+	// Continuous pushes keep resetting the quiet period, cap the wait so the check eventually runs
+	delay := w.webhookQuietPeriod
+	if deadline := state.firstPush.Add(w.webhookMaxDelay); now.Add(delay).After(deadline) {
+		delay = max(deadline.Sub(now), 0)
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+	state.generation++
+	generation := state.generation
+	state.timer = time.AfterFunc(delay, func() {
+		w.fireWebhook(projectID, generation)
+	})
+
+	slog.Debug("Webhook received, check scheduled",
+		"project_id", projectID,
+		"delay", delay)
+}
+
+// stopWebhooks cancels the scheduled webhook checks and ignores later deliveries. A check already
+// running finishes, the one queued behind it does not run.
+func (w *WatcherService) stopWebhooks() {
+	w.webhookMu.Lock()
+	defer w.webhookMu.Unlock()
+
+	w.webhooksStopped = true
+	for projectID, state := range w.webhooks {
+		if state.timer != nil {
+			state.timer.Stop()
+			state.timer = nil
+		}
+		// A timer that fired already finds its generation superseded
+		state.generation++
+		state.pending = false
+		if !state.running {
+			delete(w.webhooks, projectID)
+		}
+	}
+}
+
+// fireWebhook runs the debounced check, or queues it if a webhook-triggered check is already running
+func (w *WatcherService) fireWebhook(projectID uuid.UUID, generation uint64) {
+	w.webhookMu.Lock()
+	state := w.webhooks[projectID]
+	if state == nil || state.generation != generation {
+		w.webhookMu.Unlock()
+		return
+	}
+	state.timer = nil
+	state.firstPush = time.Time{}
+	if state.running {
+		state.pending = true
+		w.webhookMu.Unlock()
+		slog.Debug("Webhook check already running, queued another", "project_id", projectID)
+		return
+	}
+	state.running = true
+	w.webhookMu.Unlock()
+
+	for {
+		w.webhookCheck(projectID)
+
+		w.webhookMu.Lock()
+		if !state.pending {
+			state.running = false
+			if state.timer == nil {
+				delete(w.webhooks, projectID)
+			}
+			w.webhookMu.Unlock()
+			return
+		}
+		state.pending = false
+		w.webhookMu.Unlock()
+	}
+}
+
+// runWebhookCheck checks the project now, deploying the latest commit if auto-deploy is enabled
+func (w *WatcherService) runWebhookCheck(projectID uuid.UUID) {
+	if project, err := w.projectService.Get(projectID); err == nil && project.WatcherDisabled {
+		slog.Info("Skipping webhook-triggered check, watcher disabled for project",
+			"project_id", projectID,
+			"project_name", project.Name)
+		return
+	}
+	check, err := w.CheckNow(context.Background(), projectID)
+	if err != nil {
+		slog.Error("Webhook-triggered check failed",
+			"project_id", projectID,
+			"error", err)
+		return
+	}
+	slog.Info("Webhook-triggered check completed",
+		"project_id", projectID,
+		"project_name", check.ProjectName,
+		"outcome", check.Outcome)
+}
@@ -0,0 +1,121 @@
+package watcher
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebhookTestWatcher returns a watcher whose webhook checks only count calls, blocking on release if set
+func newWebhookTestWatcher(quietPeriod, maxDelay time.Duration, release <-chan struct{}) (*WatcherService, *atomic.Int32) {
+	w := NewWatcherService(nil, nil, time.Minute)
+	w.SetWebhookDebounce(quietPeriod, maxDelay)
+
+	var calls atomic.Int32
+	w.webhookCheck = func(uuid.UUID) {
+		calls.Add(1)
+		if release != nil {
+			<-release
+		}
+	}
+	return w, &calls
+}
+
+func TestWebhookCollapsesBurstIntoOneCheck(t *testing.T) {
+	w, calls := newWebhookTestWatcher(50*time.Millisecond, time.Second, nil)
+	projectID := uuid.New()
+
+	for range 5 {
+		w.HandleWebhook(projectID)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, int32(0), calls.Load(), "check must wait for the quiet period")
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// No further checks once pushes have settled
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWebhookContinuousPushesStillCheck(t *testing.T) {
+	w, calls := newWebhookTestWatcher(50*time.Millisecond, 150*time.Millisecond, nil)
+	projectID := uuid.New()
+
+	// Push more often than the quiet period for well over the max delay
+	deadline := time.Now().Add(400 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		w.HandleWebhook(projectID)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.GreaterOrEqual(t, calls.Load(), int32(1), "max delay must force a check while pushes continue")
+}
+
+func TestWebhookQueuesOneCheckWhileRunning(t *testing.T) {
+	release := make(chan struct{})
+	w, calls := newWebhookTestWatcher(20*time.Millisecond, time.Second, release)
+	projectID := uuid.New()
+
+	w.HandleWebhook(projectID)
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// Several settled bursts while the first check runs collapse into a single pending check
+	for range 3 {
+		w.HandleWebhook(projectID)
+		time.Sleep(60 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), calls.Load(), "only one check may run at a time")
+
+	close(release)
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+
+	w.webhookMu.Lock()
+	defer w.webhookMu.Unlock()
+	assert.Empty(t, w.webhooks, "state must be cleaned up once idle")
+}
+
+func TestWebhookChecksStopOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	w, calls := newWebhookTestWatcher(20*time.Millisecond, time.Second, release)
+	running, scheduled := uuid.New(), uuid.New()
+
+	// One check runs with another queued behind it, the other project only has its check scheduled
+	w.HandleWebhook(running)
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, 5*time.Millisecond)
+	w.HandleWebhook(running)
+	time.Sleep(60 * time.Millisecond)
+	w.SetWebhookDebounce(50*time.Millisecond, time.Second)
+	w.HandleWebhook(scheduled)
+
+	w.stopWebhooks()
+	w.HandleWebhook(scheduled)
+	close(release)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "only the check that was running may complete")
+
+	w.webhookMu.Lock()
+	defer w.webhookMu.Unlock()
+	assert.Empty(t, w.webhooks)
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	// echo -n '{"ref":"refs/heads/main"}' | openssl dgst -sha256 -hmac s3cret
+	signature := "sha256=232a5e067b6a5aa567ed5a1d21a0dffaf6ef0eeecb6bd9bcf43b3d5ef3fc4f43"
+
+	assert.True(t, VerifyWebhookSignature("s3cret", body, signature))
+	assert.False(t, VerifyWebhookSignature("other", body, signature))
+	assert.False(t, VerifyWebhookSignature("s3cret", []byte(`{"ref":"refs/heads/dev"}`), signature))
+	assert.False(t, VerifyWebhookSignature("s3cret", body, ""))
+	assert.False(t, VerifyWebhookSignature("s3cret", body, strings.TrimPrefix(signature, "sha256=")))
+	assert.False(t, VerifyWebhookSignature("s3cret", body, "sha256=not-hex"))
+}
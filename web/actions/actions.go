@@ -2,28 +2,58 @@
 package actions
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
 	"github.com/oar-cd/oar/web/handlers"
 )
 
 // Project action functions
 
-// CreateProject handles project creation
-func CreateProject(r *http.Request) error {
+// CreateProject handles project creation streaming, reporting the clone and each step of the creation
+func CreateProject(r *http.Request, outputChan chan<- docker.StreamMessage) error {
+	notifyEmail, err := handlers.BuildEmailNotifyConfig(r)
+	if err != nil {
+		return err
+	}
+
 	// Extract form data into request struct
 	req := &ProjectCreateRequest{
-		Name:              r.FormValue("name"),
-		GitURL:            r.FormValue("git_url"),
-		GitBranch:         r.FormValue("git_branch"),
-		ComposeFiles:      r.FormValue("compose_files"),
-		ComposeOverride:   r.FormValue("compose_override"),
-		Variables:         r.FormValue("variables"),
-		GitAuth:           handlers.BuildGitAuthConfig(r),
-		AutoDeployEnabled: r.FormValue("watcher_enabled") == "on",
+		Name:                  r.FormValue("name"),
+		GitURL:                r.FormValue("git_url"),
+		GitBranch:             r.FormValue("git_branch"),
+		WatchURL:              r.FormValue("watch_url"),
+		WebhookSecret:         r.FormValue("webhook_secret"),
+		ComposeFiles:          r.FormValue("compose_files"),
+		ComposeOverride:       r.FormValue("compose_override"),
+		EnvFiles:              r.FormValue("env_files"),
+		Variables:             r.FormValue("variables"),
+		BuildArgs:             r.FormValue("build_args"),
+		BuildSecrets:          r.FormValue("build_secrets"),
+		ContainerLabels:       r.FormValue("container_labels"),
+		DeployHooks:           r.FormValue("deploy_hooks"),
+		ExternalNetworks:      r.FormValue("external_networks"),
+		RegistryMirror:        r.FormValue("registry_mirror"),
+		NotifyWebhookURL:      r.FormValue("notify_webhook_url"),
+		NotifyChannel:         r.FormValue("notify_channel"),
+		NotifyTemplate:        r.FormValue("notify_template"),
+		NotifyEmail:           notifyEmail,
+		GitAuth:               handlers.BuildGitAuthConfig(r),
+		AutoDeployEnabled:     r.FormValue("watcher_enabled") == "on",
+		AutoDeployPullImages:  r.FormValue("auto_deploy_pull_images") == "on",
+		AutoDeploySkipGitPull: r.FormValue("auto_deploy_skip_git_pull") == "on",
+		SkipVolumeInit:        r.FormValue("skip_volume_init") == "on",
+		VolumeInitPhase:       r.FormValue("volume_init_phase"),
+		OfflineImages:         r.FormValue("offline_images") == "on",
+		PinImageDigests:       r.FormValue("pin_image_digests") == "on",
+		VerboseBuild:          r.FormValue("verbose_build") == "on",
+		VerbosePull:           r.FormValue("verbose_pull") == "on",
+		BuildOnDeploy:         r.FormValue("build_on_deploy") == "on",
 	}
 
 	// Validate request
@@ -36,7 +66,7 @@ func CreateProject(r *http.Request) error {
 
 	// Create project using service
 	projectService := app.GetProjectService()
-	_, err := projectService.Create(newProject)
+	_, err = projectService.CreateStreaming(newProject, outputChan)
 	return err
 }
 
@@ -46,18 +76,48 @@ func UpdateProject(r *http.Request) error {
 	if err != nil {
 		return err
 	}
+	notifyEmail, err := handlers.BuildEmailNotifyConfig(r)
+	if err != nil {
+		return err
+	}
 
 	// Extract form data into request struct
 	req := &ProjectUpdateRequest{
-		ID:                projectID,
-		Name:              r.FormValue("name"),
-		ComposeFiles:      r.FormValue("compose_files"),
-		ComposeOverride:   r.FormValue("compose_override"),
-		Variables:         r.FormValue("variables"),
-		GitAuth:           handlers.BuildGitAuthConfig(r),
-		AutoDeployEnabled: r.FormValue("watcher_enabled") == "on",
+		ID:                    projectID,
+		Name:                  r.FormValue("name"),
+		WatchURL:              r.FormValue("watch_url"),
+		WebhookSecret:         r.FormValue("webhook_secret"),
+		ComposeFiles:          r.FormValue("compose_files"),
+		ComposeOverride:       r.FormValue("compose_override"),
+		EnvFiles:              r.FormValue("env_files"),
+		Variables:             r.FormValue("variables"),
+		BuildArgs:             r.FormValue("build_args"),
+		BuildSecrets:          r.FormValue("build_secrets"),
+		ContainerLabels:       r.FormValue("container_labels"),
+		DeployHooks:           r.FormValue("deploy_hooks"),
+		ExternalNetworks:      r.FormValue("external_networks"),
+		RegistryMirror:        r.FormValue("registry_mirror"),
+		NotifyWebhookURL:      r.FormValue("notify_webhook_url"),
+		NotifyChannel:         r.FormValue("notify_channel"),
+		NotifyTemplate:        r.FormValue("notify_template"),
+		NotifyEmail:           notifyEmail,
+		GitAuth:               handlers.BuildGitAuthConfig(r),
+		AutoDeployEnabled:     r.FormValue("watcher_enabled") == "on",
+		AutoDeployPullImages:  r.FormValue("auto_deploy_pull_images") == "on",
+		AutoDeploySkipGitPull: r.FormValue("auto_deploy_skip_git_pull") == "on",
+		SkipVolumeInit:        r.FormValue("skip_volume_init") == "on",
+		VolumeInitPhase:       r.FormValue("volume_init_phase"),
+		OfflineImages:         r.FormValue("offline_images") == "on",
+		PinImageDigests:       r.FormValue("pin_image_digests") == "on",
+		VerboseBuild:          r.FormValue("verbose_build") == "on",
+		VerbosePull:           r.FormValue("verbose_pull") == "on",
+		BuildOnDeploy:         r.FormValue("build_on_deploy") == "on",
 	}
 
+	// Parsed by FormValue above
+	req.ListedComposeFiles = r.Form["listed_compose_files"]
+	req.EnabledComposeFiles = r.Form["enabled_compose_files"]
+
 	// Validate request
 	if err := validateProjectUpdateRequest(req); err != nil {
 		return err
@@ -84,8 +144,48 @@ func DeleteProject(r *http.Request) error {
 		return err
 	}
 
+	// Images are kept unless the checkbox is ticked; the mode is validated by the service
+	var opts project.RemoveOptions
+	if r.FormValue("remove_images") == "on" {
+		opts.RemoveImages = r.FormValue("remove_images_mode")
+	}
+
 	projectService := app.GetProjectService()
-	return projectService.Remove(projectID, false)
+	return projectService.Remove(projectID, opts)
+}
+
+// PauseProject freezes the containers of a project
+func PauseProject(r *http.Request) error {
+	projectID, err := handlers.ParseProjectID(r)
+	if err != nil {
+		return err
+	}
+	return app.GetProjectService().Pause(projectID)
+}
+
+// UnpauseProject resumes the containers of a paused project
+func UnpauseProject(r *http.Request) error {
+	projectID, err := handlers.ParseProjectID(r)
+	if err != nil {
+		return err
+	}
+	return app.GetProjectService().Unpause(projectID)
+}
+
+// ReorderProjects places the projects posted as project_id values first on the dashboard, in the
+// posted order. Posting none restores the order by name.
+func ReorderProjects(r *http.Request) error {
+	projectIDs := make([]uuid.UUID, 0, len(r.Form["project_id"]))
+	for _, value := range r.Form["project_id"] {
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid project ID %q: %w", value, err)
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+
+	projectService := app.GetProjectService()
+	return projectService.Reorder(projectIDs)
 }
 
 // Streaming action functions
@@ -93,11 +193,16 @@ func DeleteProject(r *http.Request) error {
 // DeployProject handles project deployment streaming
 func DeployProject(projectID uuid.UUID, outputChan chan<- docker.StreamMessage) error {
 	projectService := app.GetProjectService()
-	return projectService.DeployStreaming(projectID, true, outputChan)
+	return projectService.DeployStreaming(projectID, true, nil, domain.DeploymentAnnotation{}, outputChan)
+}
+
+// WatchDeployment subscribes to the output of a deployment started elsewhere
+func WatchDeployment(projectID uuid.UUID) (<-chan docker.StreamMessage, func(), error) {
+	return app.GetProjectService().SubscribeDeployment(projectID)
 }
 
-// StopProject handles project stop streaming
+// StopProject handles project stop streaming. Named volumes are kept.
 func StopProject(projectID uuid.UUID, outputChan chan<- docker.StreamMessage) error {
 	projectService := app.GetProjectService()
-	return projectService.StopStreaming(projectID, outputChan)
+	return projectService.StopStreaming(projectID, false, outputChan)
 }
@@ -2,6 +2,7 @@ package actions
 
 import (
 	"errors"
+	"slices"
 	"strings"
 
 	"github.com/google/uuid"
@@ -10,25 +11,71 @@ import (
 
 // ProjectCreateRequest represents the data needed to create a project
 type ProjectCreateRequest struct {
-	Name              string
-	GitURL            string
-	GitBranch         string
-	ComposeFiles      string
-	ComposeOverride   string
-	Variables         string
-	GitAuth           *domain.GitAuthConfig
-	AutoDeployEnabled bool
+	Name                  string
+	GitURL                string
+	GitBranch             string
+	WatchURL              string
+	WebhookSecret         string
+	ComposeFiles          string
+	ComposeOverride       string
+	EnvFiles              string
+	Variables             string
+	BuildArgs             string
+	BuildSecrets          string
+	ContainerLabels       string
+	DeployHooks           string
+	ExternalNetworks      string
+	RegistryMirror        string
+	NotifyWebhookURL      string
+	NotifyChannel         string
+	NotifyTemplate        string
+	NotifyEmail           *domain.EmailNotifyConfig
+	GitAuth               *domain.GitAuthConfig
+	AutoDeployEnabled     bool
+	AutoDeployPullImages  bool
+	AutoDeploySkipGitPull bool
+	SkipVolumeInit        bool
+	VolumeInitPhase       string
+	OfflineImages         bool
+	PinImageDigests       bool
+	VerboseBuild          bool
+	VerbosePull           bool
+	BuildOnDeploy         bool
 }
 
 // ProjectUpdateRequest represents the data needed to update a project
 type ProjectUpdateRequest struct {
-	ID                uuid.UUID
-	Name              string
-	ComposeFiles      string
-	ComposeOverride   string
-	Variables         string
-	GitAuth           *domain.GitAuthConfig
-	AutoDeployEnabled bool
+	ID                    uuid.UUID
+	Name                  string
+	WatchURL              string
+	WebhookSecret         string
+	ComposeFiles          string
+	ListedComposeFiles    []string // Compose files that had a checkbox in the form
+	EnabledComposeFiles   []string // Compose files whose checkbox was checked
+	ComposeOverride       string
+	EnvFiles              string
+	Variables             string
+	BuildArgs             string
+	BuildSecrets          string
+	ContainerLabels       string
+	DeployHooks           string
+	ExternalNetworks      string
+	RegistryMirror        string
+	NotifyWebhookURL      string
+	NotifyChannel         string
+	NotifyTemplate        string
+	NotifyEmail           *domain.EmailNotifyConfig
+	GitAuth               *domain.GitAuthConfig
+	AutoDeployEnabled     bool
+	AutoDeployPullImages  bool
+	AutoDeploySkipGitPull bool
+	SkipVolumeInit        bool
+	VolumeInitPhase       string
+	OfflineImages         bool
+	PinImageDigests       bool
+	VerboseBuild          bool
+	VerbosePull           bool
+	BuildOnDeploy         bool
 }
 
 // validateProjectCreateRequest validates a project creation request
@@ -39,8 +86,8 @@ func validateProjectCreateRequest(req *ProjectCreateRequest) error {
 	if strings.TrimSpace(req.GitURL) == "" {
 		return errors.New("git URL is required")
 	}
-	if strings.TrimSpace(req.ComposeFiles) == "" {
-		return errors.New("compose files are required")
+	if _, err := parseDeployHooks(req.DeployHooks); err != nil {
+		return err
 	}
 	return nil
 }
@@ -53,9 +100,24 @@ func validateProjectUpdateRequest(req *ProjectUpdateRequest) error {
 	if strings.TrimSpace(req.ComposeFiles) == "" {
 		return errors.New("compose files are required")
 	}
+	if _, err := parseDeployHooks(req.DeployHooks); err != nil {
+		return err
+	}
 	return nil
 }
 
+// disabledComposeFiles returns the compose files whose checkbox was left unchecked. Files added to the
+// list in the same edit had no checkbox and are enabled.
+func disabledComposeFiles(listed, enabled []string) []string {
+	var disabled []string
+	for _, file := range listed {
+		if !slices.Contains(enabled, file) {
+			disabled = append(disabled, file)
+		}
+	}
+	return disabled
+}
+
 // parseComposeFiles converts compose files string to slice
 func parseComposeFiles(composeFiles string) []string {
 	if composeFiles == "" {
@@ -64,6 +126,22 @@ func parseComposeFiles(composeFiles string) []string {
 	return strings.Split(strings.TrimSpace(composeFiles), "\n")
 }
 
+// parseEnvFiles converts env files string to slice
+func parseEnvFiles(envFiles string) []string {
+	if strings.TrimSpace(envFiles) == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(envFiles), "\n")
+}
+
+// parseExternalNetworks converts external network names string to slice
+func parseExternalNetworks(networks string) []string {
+	if strings.TrimSpace(networks) == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(networks), "\n")
+}
+
 // parseVariables converts variables string to slice
 func parseVariables(variables string) []string {
 	if variables == "" {
@@ -72,6 +150,38 @@ func parseVariables(variables string) []string {
 	return strings.Split(strings.TrimSpace(variables), "\n")
 }
 
+// parseKeyValues converts KEY=value lines, such as build arguments, to a map. Lines without a value
+// set an empty one.
+func parseKeyValues(text string) map[string]string {
+	values := map[string]string{}
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		values[strings.TrimSpace(key)] = value
+	}
+	return values
+}
+
+// parseDeployHooks converts PHASE:SERVICE[:COMMAND] lines to deploy hooks, in the order they run
+func parseDeployHooks(text string) ([]domain.DeployHook, error) {
+	var hooks []domain.DeployHook
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		hook, err := domain.ParseDeployHook(line)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
 // buildProjectFromCreateRequest converts create request to Project struct
 func buildProjectFromCreateRequest(req *ProjectCreateRequest) *domain.Project {
 	var composeOverride *string
@@ -79,18 +189,42 @@ func buildProjectFromCreateRequest(req *ProjectCreateRequest) *domain.Project {
 		trimmed := strings.TrimSpace(req.ComposeOverride)
 		composeOverride = &trimmed
 	}
+	// Validated with the request
+	deployHooks, _ := parseDeployHooks(req.DeployHooks)
 
 	return &domain.Project{
-		ID:                uuid.New(),
-		Name:              req.Name,
-		GitURL:            req.GitURL,
-		GitBranch:         req.GitBranch,
-		GitAuth:           req.GitAuth,
-		ComposeFiles:      parseComposeFiles(req.ComposeFiles),
-		ComposeOverride:   composeOverride,
-		Variables:         parseVariables(req.Variables),
-		Status:            domain.ProjectStatusStopped,
-		AutoDeployEnabled: req.AutoDeployEnabled,
+		ID:                    uuid.New(),
+		Name:                  req.Name,
+		GitURL:                req.GitURL,
+		GitBranch:             req.GitBranch,
+		WatchURL:              req.WatchURL,
+		WebhookSecret:         req.WebhookSecret,
+		GitAuth:               req.GitAuth,
+		ComposeFiles:          parseComposeFiles(req.ComposeFiles),
+		ComposeOverride:       composeOverride,
+		EnvFiles:              parseEnvFiles(req.EnvFiles),
+		Variables:             parseVariables(req.Variables),
+		BuildArgs:             parseKeyValues(req.BuildArgs),
+		BuildSecrets:          parseKeyValues(req.BuildSecrets),
+		ContainerLabels:       parseKeyValues(req.ContainerLabels),
+		DeployHooks:           deployHooks,
+		ExternalNetworks:      parseExternalNetworks(req.ExternalNetworks),
+		RegistryMirror:        req.RegistryMirror,
+		NotifyWebhookURL:      req.NotifyWebhookURL,
+		NotifyChannel:         req.NotifyChannel,
+		NotifyTemplate:        req.NotifyTemplate,
+		NotifyEmail:           req.NotifyEmail,
+		Status:                domain.ProjectStatusStopped,
+		AutoDeployEnabled:     req.AutoDeployEnabled,
+		AutoDeployPullImages:  req.AutoDeployPullImages,
+		AutoDeploySkipGitPull: req.AutoDeploySkipGitPull,
+		SkipVolumeInit:        req.SkipVolumeInit,
+		VolumeInitPhase:       domain.VolumeInitPhase(req.VolumeInitPhase),
+		OfflineImages:         req.OfflineImages,
+		PinImageDigests:       req.PinImageDigests,
+		VerboseBuild:          req.VerboseBuild,
+		VerbosePull:           req.VerbosePull,
+		BuildOnDeploy:         req.BuildOnDeploy,
 	}
 }
 
@@ -103,9 +237,33 @@ func applyProjectUpdateRequest(project *domain.Project, req *ProjectUpdateReques
 	}
 
 	project.Name = req.Name
+	project.WatchURL = req.WatchURL
+	project.WebhookSecret = req.WebhookSecret
 	project.GitAuth = req.GitAuth
 	project.ComposeFiles = parseComposeFiles(req.ComposeFiles)
+	project.DisabledComposeFiles = disabledComposeFiles(req.ListedComposeFiles, req.EnabledComposeFiles)
 	project.ComposeOverride = composeOverride
+	project.EnvFiles = parseEnvFiles(req.EnvFiles)
 	project.Variables = parseVariables(req.Variables)
+	project.BuildArgs = parseKeyValues(req.BuildArgs)
+	project.BuildSecrets = parseKeyValues(req.BuildSecrets)
+	project.ContainerLabels = parseKeyValues(req.ContainerLabels)
+	// Validated with the request
+	project.DeployHooks, _ = parseDeployHooks(req.DeployHooks)
+	project.ExternalNetworks = parseExternalNetworks(req.ExternalNetworks)
+	project.RegistryMirror = req.RegistryMirror
+	project.NotifyWebhookURL = req.NotifyWebhookURL
+	project.NotifyChannel = req.NotifyChannel
+	project.NotifyTemplate = req.NotifyTemplate
+	project.NotifyEmail = req.NotifyEmail
 	project.AutoDeployEnabled = req.AutoDeployEnabled
+	project.AutoDeployPullImages = req.AutoDeployPullImages
+	project.AutoDeploySkipGitPull = req.AutoDeploySkipGitPull
+	project.SkipVolumeInit = req.SkipVolumeInit
+	project.VolumeInitPhase = domain.VolumeInitPhase(req.VolumeInitPhase)
+	project.OfflineImages = req.OfflineImages
+	project.PinImageDigests = req.PinImageDigests
+	project.VerboseBuild = req.VerboseBuild
+	project.VerbosePull = req.VerbosePull
+	project.BuildOnDeploy = req.BuildOnDeploy
 }
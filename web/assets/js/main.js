@@ -176,10 +176,6 @@ document.addEventListener('DOMContentLoaded', function() {
                         updateResultIndicator('test-auth-result', 'check', 'success', 'Git authentication successful');
                     } else if (successMessage === 'testAuthError') {
                         updateResultIndicator('test-auth-result', 'x', 'error', 'Git authentication failed');
-                    } else if (successMessage === 'projectCreated') {
-                        showToast('Project created successfully', 'success');
-                        // Close the modal after successful project creation
-                        closeModal('modal-container');
                     } else if (successMessage === 'projectUpdated') {
                         showToast('Project updated successfully', 'success');
                         // Close the modal after successful project update
@@ -188,6 +184,10 @@ document.addEventListener('DOMContentLoaded', function() {
                         showToast('Project deleted successfully', 'success');
                         // Close the modal after successful project deletion
                         closeModal('modal-container');
+                    } else if (successMessage === 'projectPaused') {
+                        showToast('Project paused', 'success');
+                    } else if (successMessage === 'projectResumed') {
+                        showToast('Project resumed', 'success');
                     }
                 }
             }
@@ -341,10 +341,57 @@ document.addEventListener('DOMContentLoaded', function() {
         }
     }
 
+    // Weight pull steps so a layer is half done once downloaded and done once extracted
+    function stepPercent(progress) {
+        switch (progress.status) {
+            case 'Downloading':
+                return progress.percent / 2;
+            case 'Download complete':
+            case 'Verifying Checksum':
+                return 50;
+            case 'Extracting':
+                return 50 + progress.percent / 2;
+            default:
+                return progress.percent;
+        }
+    }
+
+    // Helper function to render pull and build progress as one bar per image or service
+    function renderProgress(contentElement, progressGroups, progress) {
+        if (!progress) return;
+
+        const group = progress.parent || progress.id;
+        if (!progressGroups.has(group)) {
+            progressGroups.set(group, new Map());
+        }
+        const steps = progressGroups.get(group);
+        steps.set(progress.id, stepPercent(progress));
+
+        let total = 0;
+        steps.forEach(percent => { total += percent; });
+        const percent = Math.round(total / steps.size);
+
+        let bar = Array.from(contentElement.querySelectorAll('.deploy-progress'))
+            .find(element => element.dataset.group === group);
+        if (!bar) {
+            const escapedGroup = group.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+            contentElement.insertAdjacentHTML('beforeend',
+                `<span class="deploy-progress" data-group="${escapedGroup}">` +
+                `<span class="deploy-progress-label">${escapedGroup}</span>` +
+                `<span class="deploy-progress-track"><span class="deploy-progress-fill"></span></span>` +
+                `<span class="deploy-progress-percent"></span></span>\n`);
+            bar = Array.from(contentElement.querySelectorAll('.deploy-progress'))
+                .find(element => element.dataset.group === group);
+        }
+        bar.querySelector('.deploy-progress-fill').style.width = `${percent}%`;
+        bar.querySelector('.deploy-progress-percent').textContent = `${percent}%`;
+    }
+
     // Helper function to process server-sent events
     function processServerSentEvents(reader, decoder, contentElement, outputElement, onComplete) {
         let buffer = '';
         let hasError = false; // Track if we've seen any error messages
+        const progressGroups = new Map(); // Pull and build progress per image or service
 
         function readChunk() {
             return reader.read().then(({ done, value }) => {
@@ -414,6 +461,13 @@ document.addEventListener('DOMContentLoaded', function() {
                                     }
                                     break;
 
+                                case 'progress':
+                                    renderProgress(contentElement, progressGroups, data.progress);
+                                    if (outputElement) {
+                                        outputElement.scrollTop = outputElement.scrollHeight;
+                                    }
+                                    break;
+
                                 case 'output':
                                     // Legacy fallback for any remaining 'output' type messages
                                     const legacyMessage = data.message;
@@ -582,6 +636,185 @@ document.addEventListener('DOMContentLoaded', function() {
     // Stop streaming functionality
     window.startStop = createStreamingHandler(stopConfig);
 
+    // Share pages follow a deployment started elsewhere, they cannot start one
+    document.addEventListener('click', function(event) {
+        if (event.target.id === 'shared-watch-btn' && event.target.dataset.shareToken) {
+            event.preventDefault();
+            watchSharedDeployment(event.target);
+        }
+    });
+
+    function watchSharedDeployment(button) {
+        const elements = validateElements({
+            content: 'watch-content',
+            output: 'watch-output'
+        });
+
+        if (!elements) return;
+
+        button.disabled = true;
+        elements.content.innerHTML = '<span class="deploy-text-frontend-generic">Connecting to deployment stream...</span>\n';
+
+        fetch(`/share/${button.dataset.shareToken}/deploy/watch`, {
+            headers: {
+                'Accept': 'text/event-stream',
+                'Cache-Control': 'no-cache'
+            }
+        })
+        .then(response => {
+            if (response.status === 404) {
+                button.disabled = false;
+                elements.content.innerHTML += '<span class="deploy-text-frontend-generic">No deployment in progress</span>\n';
+                return;
+            }
+            if (!response.ok) {
+                throw new Error(`HTTP error! status: ${response.status}`);
+            }
+
+            const reader = response.body.getReader();
+            const decoder = new TextDecoder();
+
+            return processServerSentEvents(reader, decoder, elements.content, elements.output, (hasError) => {
+                button.disabled = false;
+                const message = hasError ? 'Deployment failed' : 'Deployment finished';
+                const cssClass = hasError ? 'deploy-text-frontend-error' : 'deploy-text-frontend-success';
+                elements.content.innerHTML += `\n<span class="${cssClass}">${message}</span>\n`;
+                elements.output.scrollTop = elements.output.scrollHeight;
+                htmx.trigger('#shared-status', 'refresh');
+            });
+        })
+        .catch(error => {
+            console.error('Deployment watch error:', error);
+            button.disabled = false;
+            elements.content.innerHTML += '\n<span class="deploy-text-frontend-error">ERROR: Connection to deployment stream failed</span>\n';
+        });
+    }
+
+    // Project creation streams its progress into the create modal instead of submitting with htmx
+    document.addEventListener('submit', function(event) {
+        const form = event.target;
+        if (form.id === 'project-form' && form.dataset.streamAction) {
+            event.preventDefault();
+            startProjectCreation(form);
+        }
+    });
+
+    function startProjectCreation(form) {
+        const elements = validateElements({
+            content: 'create-content',
+            output: 'create-output'
+        });
+
+        if (!elements) return;
+
+        const submitButton = document.querySelector('button[type="submit"][form="project-form"]');
+        if (submitButton) {
+            submitButton.disabled = true;
+        }
+
+        elements.output.classList.remove('hidden');
+        elements.content.innerHTML = '<span class="deploy-text-frontend-generic">Connecting to creation stream...</span>\n';
+
+        fetch(form.dataset.streamAction, {
+            method: 'POST',
+            headers: {
+                'Accept': 'text/event-stream',
+                'Cache-Control': 'no-cache'
+            },
+            body: new URLSearchParams(new FormData(form))
+        })
+        .then(response => {
+            if (!response.ok) {
+                throw new Error(`HTTP error! status: ${response.status}`);
+            }
+
+            const reader = response.body.getReader();
+            const decoder = new TextDecoder();
+
+            return processServerSentEvents(reader, decoder, elements.content, elements.output, (hasError) => {
+                if (submitButton) {
+                    submitButton.disabled = false;
+                }
+
+                if (hasError) {
+                    // Keep the form open, so that the settings can be corrected and submitted again
+                    elements.content.innerHTML += '\n<span class="deploy-text-frontend-error">Project creation failed</span>\n';
+                    elements.output.scrollTop = elements.output.scrollHeight;
+                    showToast('Project creation failed', 'error');
+                    return;
+                }
+
+                htmx.ajax('GET', '/projects/grid', { target: '#project-grid', swap: 'outerHTML' });
+                showToast('Project created successfully', 'success');
+                closeModal('modal-container');
+            });
+        })
+        .catch(error => {
+            console.error('Creation streaming error:', error);
+            if (submitButton) {
+                submitButton.disabled = false;
+            }
+            elements.content.innerHTML += '\n<span class="deploy-text-frontend-error">ERROR: Connection to creation stream failed</span>\n';
+            showToast('Creation connection failed', 'error');
+        });
+    }
+
+    // Project cards are reordered by dragging them. The new order is saved when the card is dropped,
+    // and the grid is rendered again in the saved order.
+    let draggedCard = null;
+    let orderBeforeDrag = '';
+
+    function projectCardOrder(grid) {
+        return Array.from(grid.querySelectorAll('.project-card[data-project-id]'))
+            .map(card => card.dataset.projectId);
+    }
+
+    document.addEventListener('dragstart', function(event) {
+        const card = event.target.closest && event.target.closest('.project-card[draggable="true"]');
+        if (!card) return;
+
+        draggedCard = card;
+        orderBeforeDrag = projectCardOrder(card.parentNode).join(',');
+        card.classList.add('project-card-dragging');
+        event.dataTransfer.effectAllowed = 'move';
+        event.dataTransfer.setData('text/plain', card.dataset.projectId);
+    });
+
+    document.addEventListener('dragover', function(event) {
+        if (!draggedCard) return;
+        const card = event.target.closest('.project-card[draggable="true"]');
+        if (!card || card.parentNode !== draggedCard.parentNode) return;
+
+        event.preventDefault();
+        if (card === draggedCard) return;
+
+        // Move the dragged card to the other side of the card it is dragged over
+        const draggedFollows = card.compareDocumentPosition(draggedCard) & Node.DOCUMENT_POSITION_FOLLOWING;
+        card.parentNode.insertBefore(draggedCard, draggedFollows ? card : card.nextSibling);
+    });
+
+    document.addEventListener('drop', function(event) {
+        if (draggedCard) {
+            event.preventDefault();
+        }
+    });
+
+    document.addEventListener('dragend', function() {
+        if (!draggedCard) return;
+
+        const grid = draggedCard.parentNode;
+        draggedCard.classList.remove('project-card-dragging');
+        draggedCard = null;
+
+        const order = projectCardOrder(grid);
+        if (order.join(',') === orderBeforeDrag || !grid.dataset.reorderUrl) return;
+
+        htmx.ajax('POST', grid.dataset.reorderUrl, {
+            target: '#project-grid',
+            swap: 'outerHTML',
+            values: { project_id: order }
+        });
+    });
 
     // Event delegation for deployment output buttons
     document.addEventListener('click', function(e) {
@@ -590,12 +823,14 @@ document.addEventListener('DOMContentLoaded', function() {
             const deploymentId = button.getAttribute('data-deployment-id');
             const stdout = button.getAttribute('data-deployment-stdout') || '';
             const stderr = button.getAttribute('data-deployment-stderr') || '';
-            showDeploymentOutput(deploymentId, stdout, stderr);
+            const commandLine = button.getAttribute('data-deployment-command') || '';
+            const versions = button.getAttribute('data-deployment-versions') || '';
+            showDeploymentOutput(deploymentId, stdout, stderr, commandLine, versions);
         }
     });
 
     // Show deployment output in a popup
-    window.showDeploymentOutput = function(deploymentId, stdout, stderr) {
+    window.showDeploymentOutput = function(deploymentId, stdout, stderr, commandLine, versions) {
         // Helper function to parse stderr lines
         function parseStderrLine(line) {
             // Simple regex to match msg="..." in Docker Compose log format
@@ -638,6 +873,27 @@ document.addEventListener('DOMContentLoaded', function() {
             formattedOutput = 'No output available';
         }
 
+        // Command line that started the services, recorded since it was added to deployments
+        let commandBlock = '';
+        if (commandLine) {
+            const escapedCommand = commandLine.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+            commandBlock = `
+                    <div class="deployment-command">
+                        <div class="deployment-command-label">Command</div>
+                        <pre class="deployment-command-line">${escapedCommand}</pre>
+                    </div>`;
+        }
+
+        // Docker and Compose versions that ran the deployment, recorded since they were added to deployments
+        if (versions) {
+            const escapedVersions = versions.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+            commandBlock += `
+                    <div class="deployment-command">
+                        <div class="deployment-command-label">Versions</div>
+                        <pre class="deployment-command-line">${escapedVersions}</pre>
+                    </div>`;
+        }
+
         // Create modal overlay without backdrop (since another modal is already active)
         const overlay = document.createElement('div');
         overlay.className = 'fixed inset-0 z-60 overflow-y-auto';
@@ -651,7 +907,7 @@ document.addEventListener('DOMContentLoaded', function() {
                                 <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"/>
                             </svg>
                         </button>
-                    </div>
+                    </div>${commandBlock}
                     <div class="deployment-output-container">
                         <div class="deploy-code-block" style="max-height: 400px;">
                             <pre class="streaming-output">${formattedOutput}</pre>
@@ -8,10 +8,15 @@ package modals
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/oar-cd/oar/web/components/forms"
+import (
+	"strings"
 
-// CreateProjectModal renders the create project modal
-func CreateProjectModal() templ.Component {
+	"github.com/oar-cd/oar/web/components/forms"
+)
+
+// CreateProjectModal renders the create project modal, with the compose files pre-filled with the
+// configured defaults
+func CreateProjectModal(defaultComposeFiles []string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -32,7 +37,7 @@ func CreateProjectModal() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = BaseModal("Create Project", createProjectBody(), createProjectFooter()).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = BaseModal("Create Project", createProjectBody(defaultComposeFiles), createProjectFooter()).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -40,8 +45,8 @@ func CreateProjectModal() templ.Component {
 	})
 }
 
-// createProjectBody renders the modal body content
-func createProjectBody() templ.Component {
+// createProjectBody renders the modal body content, with the progress log shown while the project is created
+func createProjectBody(defaultComposeFiles []string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -64,11 +69,16 @@ func createProjectBody() templ.Component {
 		ctx = templ.ClearChildren(ctx)
 		templ_7745c5c3_Err = forms.ProjectForm(forms.ProjectFormData{
 			IsEdit:            false,
+			ComposeFiles:      strings.Join(defaultComposeFiles, "\n"),
 			AutoDeployEnabled: true, // Default to enabled for new projects
 		}).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div id=\"create-output\" class=\"create-code-block hidden\"><pre id=\"create-content\" class=\"streaming-output\"></pre></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		return nil
 	})
 }
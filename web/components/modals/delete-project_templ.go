@@ -90,7 +90,7 @@ func deleteProjectBody(proj project.ProjectView, deletedDirPath string) templ.Co
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</p></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</p></div><div id=\"delete-project-options\" class=\"delete-options\"><label class=\"flex items-center\"><input type=\"checkbox\" name=\"remove_images\" class=\"mr-2\"> <span class=\"text-sm text-gray-700\">Also remove images</span></label> <select name=\"remove_images_mode\" class=\"form-input\" aria-label=\"Images to remove\"><option value=\"local\" selected>Only images built for the project</option> <option value=\"all\">All images of the project</option></select><p class=\"text-xs text-gray-500\">Removing all images also removes images that other projects use. Images that containers use are kept, the others have to be pulled again.</p></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -120,7 +120,8 @@ func deleteProjectFooter(proj project.ProjectView) templ.Component {
 			templ_7745c5c3_Var5 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = CancelActionFooter("Delete Project", "btn-danger", "/projects/"+proj.ID.String(), "#project-grid", "outerHTML").Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = CancelActionFooter("Delete Project", "btn-danger", "/projects/"+proj.ID.String(), "#project-grid", "outerHTML",
+			"#delete-project-options").Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
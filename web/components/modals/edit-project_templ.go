@@ -8,6 +8,9 @@ package modals
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
+import "maps"
+import "slices"
+import "strconv"
 import "github.com/oar-cd/oar/web/components/forms"
 import "github.com/oar-cd/oar/web/components/project"
 
@@ -64,19 +67,49 @@ func editProjectBody(proj project.ProjectView) templ.Component {
 		}
 		ctx = templ.ClearChildren(ctx)
 		templ_7745c5c3_Err = forms.ProjectForm(forms.ProjectFormData{
-			IsEdit:            true,
-			ProjectID:         proj.ID.String(),
-			Name:              proj.Name,
-			GitURL:            proj.GitURL,
-			GitBranch:         proj.GitBranch,
-			AuthMethod:        getAuthMethodFromProject(proj),
-			Username:          getUsernameFromProject(proj),
-			Password:          getPasswordFromProject(proj),
-			PrivateKey:        getPrivateKeyFromProject(proj),
-			ComposeFiles:      joinStringSlice(proj.ComposeFiles, "\n"),
-			ComposeOverride:   getComposeOverrideFromProject(proj),
-			Variables:         joinStringSlice(proj.Variables, "\n"),
-			AutoDeployEnabled: proj.AutoDeployEnabled,
+			IsEdit:                true,
+			ProjectID:             proj.ID.String(),
+			Name:                  proj.Name,
+			GitURL:                proj.GitURL,
+			GitBranch:             proj.GitBranch,
+			WatchURL:              proj.WatchURL,
+			WebhookSecret:         proj.WebhookSecret,
+			AuthMethod:            getAuthMethodFromProject(proj),
+			Username:              getUsernameFromProject(proj),
+			Password:              getPasswordFromProject(proj),
+			PrivateKey:            getPrivateKeyFromProject(proj),
+			ComposeFiles:          joinStringSlice(proj.ComposeFiles, "\n"),
+			SavedComposeFiles:     proj.ComposeFiles,
+			DisabledComposeFiles:  proj.DisabledComposeFiles,
+			ComposeOverride:       getComposeOverrideFromProject(proj),
+			EnvFiles:              joinStringSlice(proj.EnvFiles, "\n"),
+			Variables:             joinStringSlice(proj.Variables, "\n"),
+			BuildArgs:             joinKeyValues(proj.BuildArgs),
+			BuildSecrets:          joinKeyValues(proj.BuildSecrets),
+			ContainerLabels:       joinKeyValues(proj.ContainerLabels),
+			DeployHooks:           joinStringSlice(proj.DeployHooks, "\n"),
+			ExternalNetworks:      joinStringSlice(proj.ExternalNetworks, "\n"),
+			RegistryMirror:        proj.RegistryMirror,
+			NotifyWebhookURL:      proj.NotifyWebhookURL,
+			NotifyChannel:         proj.NotifyChannel,
+			NotifyTemplate:        proj.NotifyTemplate,
+			NotifyEmailTo:         getNotifyEmailToFromProject(proj),
+			NotifySMTPHost:        getNotifyEmailFromProject(proj).Host,
+			NotifySMTPPort:        getNotifySMTPPortFromProject(proj),
+			NotifySMTPTLS:         getNotifyEmailFromProject(proj).TLS,
+			NotifySMTPFrom:        getNotifyEmailFromProject(proj).From,
+			NotifySMTPUser:        getNotifyEmailFromProject(proj).Username,
+			NotifySMTPPass:        getNotifyEmailFromProject(proj).Password,
+			AutoDeployEnabled:     proj.AutoDeployEnabled,
+			AutoDeployPullImages:  proj.AutoDeployPullImages,
+			AutoDeploySkipGitPull: proj.AutoDeploySkipGitPull,
+			SkipVolumeInit:        proj.SkipVolumeInit,
+			VolumeInitPhase:       proj.VolumeInitPhase,
+			OfflineImages:         proj.OfflineImages,
+			PinImageDigests:       proj.PinImageDigests,
+			VerboseBuild:          proj.VerboseBuild,
+			VerbosePull:           proj.VerbosePull,
+			BuildOnDeploy:         proj.BuildOnDeploy,
 		}).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
@@ -156,6 +189,25 @@ func getPrivateKeyFromProject(proj project.ProjectView) string {
 	return proj.GitAuth.SSHAuth.PrivateKey
 }
 
+// Helper functions to extract email notification settings from project
+func getNotifyEmailFromProject(proj project.ProjectView) project.EmailNotifyConfig {
+	if proj.NotifyEmail == nil {
+		return project.EmailNotifyConfig{}
+	}
+	return *proj.NotifyEmail
+}
+
+func getNotifyEmailToFromProject(proj project.ProjectView) string {
+	return joinStringSlice(getNotifyEmailFromProject(proj).To, "\n")
+}
+
+func getNotifySMTPPortFromProject(proj project.ProjectView) string {
+	if port := getNotifyEmailFromProject(proj).Port; port != 0 {
+		return strconv.Itoa(port)
+	}
+	return ""
+}
+
 func getComposeOverrideFromProject(proj project.ProjectView) string {
 	if proj.ComposeOverride == nil {
 		return ""
@@ -177,4 +229,13 @@ func joinStringSlice(slice []string, separator string) string {
 	return result
 }
 
+// joinKeyValues formats build arguments, build secrets or container labels as KEY=value lines, sorted by name
+func joinKeyValues(values map[string]string) string {
+	lines := make([]string, 0, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		lines = append(lines, key+"="+values[key])
+	}
+	return joinStringSlice(lines, "\n")
+}
+
 var _ = templruntime.GeneratedTemplate
@@ -41,7 +41,13 @@ func ProjectGrid(projects []ProjectView, hasProjects bool) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		if hasProjects {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"project-grid\">")
+			if isReordered(projects) {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"project-grid-toolbar\"><button type=\"button\" class=\"btn-link px-2\" title=\"Forget the order set by dragging the cards\" hx-post=\"/projects/reorder\" hx-target=\"#project-grid\" hx-swap=\"outerHTML\">Sort by name</button></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "  <div class=\"project-grid\" data-reorder-url=\"/projects/reorder\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -51,7 +57,7 @@ func ProjectGrid(projects []ProjectView, hasProjects bool) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div> <div hx-get=\"/projects/statuses\" hx-trigger=\"load\" hx-swap=\"none\"></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -61,7 +67,7 @@ func ProjectGrid(projects []ProjectView, hasProjects bool) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -69,6 +75,16 @@ func ProjectGrid(projects []ProjectView, hasProjects bool) templ.Component {
 	})
 }
 
+// isReordered reports whether some project was placed by hand
+func isReordered(projects []ProjectView) bool {
+	for _, project := range projects {
+		if project.SortOrder > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // EmptyState shows when no projects exist
 func EmptyState() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -91,7 +107,7 @@ func EmptyState() templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<div class=\"empty-state\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<div class=\"empty-state\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -99,7 +115,7 @@ func EmptyState() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<h3 class=\"empty-state-title\">No projects yet</h3><p class=\"empty-state-description\">Get started by creating your first Docker Compose project.</p><button type=\"button\" class=\"btn-primary\" hx-get=\"/projects/create\" hx-target=\"#modal-container\" hx-swap=\"outerHTML\">Create Your First Project</button></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<h3 class=\"empty-state-title\">No projects yet</h3><p class=\"empty-state-description\">Get started by creating your first Docker Compose project.</p><button type=\"button\" class=\"btn-primary\" hx-get=\"/projects/create\" hx-target=\"#modal-container\" hx-swap=\"outerHTML\">Create Your First Project</button></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -109,20 +125,55 @@ func EmptyState() templ.Component {
 
 // ProjectView represents the frontend view data for a project (simplified from backend Project)
 type ProjectView struct {
-	ID                uuid.UUID
-	Name              string
-	GitURL            string
-	GitBranch         string
-	GitAuth           *GitAuthConfig // Git authentication configuration
-	Status            string         // "running", "stopped", "error" (string representation)
-	LocalCommit       *string        // Git commit SHA (first 8 chars)
-	ComposeFiles      []string
-	ComposeOverride   *string
-	Variables         []string
-	AutoDeployEnabled bool
-	IsOutdated        bool // Whether remote has new commits not yet deployed locally
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	ID                    uuid.UUID
+	Name                  string
+	ComposeName           string // Name Docker Compose runs the project under, may differ from Name
+	GitURL                string
+	GitBranch             string
+	WatchURL              string         // Repository new commits are detected in, GitURL when empty
+	WebhookSecret         string         // Secret push webhooks must be signed with, empty when unsigned ones are accepted
+	IsArchive             bool           // Whether the project was extracted from an archive and has no repository
+	GitAuth               *GitAuthConfig // Git authentication configuration
+	Status                string         // "running", "stopped", "error" (string representation)
+	LocalCommit           *string        // Git commit SHA (first 8 chars)
+	ComposeFiles          []string
+	DisabledComposeFiles  []string // Compose files left out of Compose commands
+	ComposeOverride       *string
+	EnvFiles              []string
+	Variables             []string
+	BuildArgs             map[string]string
+	BuildSecrets          map[string]string
+	ContainerLabels       map[string]string
+	DeployHooks           []string // Deploy hooks as PHASE:SERVICE[:COMMAND], in the order they run
+	ExternalNetworks      []string
+	RegistryMirror        string
+	NotifyWebhookURL      string
+	NotifyChannel         string
+	NotifyTemplate        string
+	NotifyEmail           *EmailNotifyConfig // Email notification settings, nil when not set
+	AutoDeployEnabled     bool
+	AutoDeployPullImages  bool
+	AutoDeploySkipGitPull bool
+	WatcherDisabled       bool
+	SkipVolumeInit        bool
+	VolumeInitPhase       string
+	OfflineImages         bool
+	PinImageDigests       bool
+	VerboseBuild          bool
+	VerbosePull           bool
+	BuildOnDeploy         bool
+	IsOutdated            bool // Whether remote has new commits not yet deployed locally
+	SortOrder             int  // Position on the dashboard, 0 when never placed by hand
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// LiveStatusView holds the live Docker status of a project shown on its card
+type LiveStatusView struct {
+	Status         string // "running", "degraded", "deploying", "paused", "stopped", "error", "unavailable", "unknown"
+	RestartCount   int    // Highest restart count among the project's containers
+	LastExitReason string // Why the most restarted container last exited
+	Detail         string // Tooltip on the status pill, e.g. why the status is unavailable
 }
 
 // GitAuthConfig holds Git authentication configuration for a project
@@ -131,6 +182,17 @@ type GitAuthConfig struct {
 	SSHAuth  *GitSSHAuthConfig
 }
 
+// EmailNotifyConfig holds the email notification settings of a project
+type EmailNotifyConfig struct {
+	To       []string
+	Host     string // Empty to use the configured SMTP server
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      string // "starttls", "tls" or "none"
+}
+
 // GitHTTPAuthConfig for HTTP basic authentication (GitHub tokens, etc.)
 type GitHTTPAuthConfig struct {
 	Username string // "token" for GitHub
@@ -2,11 +2,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/a-h/templ"
@@ -15,12 +17,29 @@ import (
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+	"github.com/oar-cd/oar/watcher"
+	"github.com/oar-cd/oar/web/components/base"
 	projectcomponent "github.com/oar-cd/oar/web/components/project"
 )
 
-// GetVersion returns the server version for use in templates
-func GetVersion() string {
-	return app.Version
+// GetBuildInfo returns the server version and build details for use in templates. When the update
+// check found a newer release, it is named too.
+func GetBuildInfo() base.BuildInfoView {
+	info := app.GetBuildInfo()
+	view := base.BuildInfoView{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildDate: info.BuildDate,
+		GoVersion: info.GoVersion,
+	}
+	if checker := app.GetUpdateChecker(); checker != nil {
+		if result, ok := checker.Latest(); ok && result.UpdateAvailable {
+			view.UpdateVersion = result.Latest
+			view.UpdateURL = result.URL
+		}
+	}
+	return view
 }
 
 // Helper functions for common operations
@@ -40,6 +59,21 @@ func ParseProjectID(r *http.Request) (uuid.UUID, error) {
 	return parsedID, nil
 }
 
+// ParseDeploymentID extracts and validates deployment ID from URL parameters
+func ParseDeploymentID(r *http.Request) (uuid.UUID, error) {
+	deploymentID := chi.URLParam(r, "deploymentID")
+	if deploymentID == "" {
+		return uuid.Nil, errors.New("deployment ID is required")
+	}
+
+	parsedID, err := uuid.Parse(deploymentID)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid deployment ID format")
+	}
+
+	return parsedID, nil
+}
+
 // BuildGitAuthConfig creates GitAuthConfig from form values
 func BuildGitAuthConfig(r *http.Request) *domain.GitAuthConfig {
 	authMethod := r.FormValue("auth_method")
@@ -72,23 +106,77 @@ func BuildGitAuthConfig(r *http.Request) *domain.GitAuthConfig {
 	return nil
 }
 
+// BuildEmailNotifyConfig creates the email notification settings from form values. Recipients are one
+// per line; the SMTP server fields are only needed to use a server other than the configured one.
+func BuildEmailNotifyConfig(r *http.Request) (*domain.EmailNotifyConfig, error) {
+	email := &domain.EmailNotifyConfig{
+		Host:     strings.TrimSpace(r.FormValue("notify_smtp_host")),
+		Username: r.FormValue("notify_smtp_username"),
+		Password: r.FormValue("notify_smtp_password"),
+		From:     strings.TrimSpace(r.FormValue("notify_smtp_from")),
+		TLS:      r.FormValue("notify_smtp_tls"),
+	}
+	for _, address := range strings.Split(r.FormValue("notify_email_to"), "\n") {
+		if address = strings.TrimSpace(address); address != "" {
+			email.To = append(email.To, address)
+		}
+	}
+	if port := strings.TrimSpace(r.FormValue("notify_smtp_port")); port != "" {
+		var err error
+		if email.Port, err = strconv.Atoi(port); err != nil {
+			return nil, fmt.Errorf("invalid SMTP port %q", port)
+		}
+	}
+	if len(email.To) == 0 && email.Host == "" {
+		return nil, nil
+	}
+	return email, nil
+}
+
 // ConvertProjectToView converts a backend Project to frontend ProjectView
 func ConvertProjectToView(p *domain.Project) projectcomponent.ProjectView {
 	return projectcomponent.ProjectView{
-		ID:                p.ID,
-		Name:              p.Name,
-		GitURL:            p.GitURL,
-		GitBranch:         p.GitBranch,
-		GitAuth:           ConvertGitAuthConfig(p.GitAuth),
-		Status:            p.Status.String(),
-		LocalCommit:       p.LocalCommit,
-		ComposeFiles:      p.ComposeFiles,
-		ComposeOverride:   p.ComposeOverride,
-		Variables:         p.Variables,
-		AutoDeployEnabled: p.AutoDeployEnabled,
-		IsOutdated:        p.IsOutdated(),
-		CreatedAt:         p.CreatedAt,
-		UpdatedAt:         p.UpdatedAt,
+		ID:                    p.ID,
+		Name:                  p.Name,
+		ComposeName:           p.ComposeProjectName(),
+		GitURL:                p.GitURL,
+		GitBranch:             p.GitBranch,
+		WatchURL:              p.WatchURL,
+		WebhookSecret:         p.WebhookSecret,
+		IsArchive:             !p.UsesGit(),
+		GitAuth:               ConvertGitAuthConfig(p.GitAuth),
+		Status:                p.Status.String(),
+		LocalCommit:           p.LocalCommit,
+		ComposeFiles:          p.ComposeFiles,
+		DisabledComposeFiles:  p.DisabledComposeFiles,
+		ComposeOverride:       p.ComposeOverride,
+		EnvFiles:              p.EnvFiles,
+		Variables:             p.Variables,
+		BuildArgs:             p.BuildArgs,
+		BuildSecrets:          p.BuildSecrets,
+		ContainerLabels:       p.ContainerLabels,
+		DeployHooks:           formatDeployHooks(p.DeployHooks),
+		ExternalNetworks:      p.ExternalNetworks,
+		RegistryMirror:        p.RegistryMirror,
+		NotifyWebhookURL:      p.NotifyWebhookURL,
+		NotifyChannel:         p.NotifyChannel,
+		NotifyTemplate:        p.NotifyTemplate,
+		NotifyEmail:           ConvertEmailNotifyConfig(p.NotifyEmail),
+		AutoDeployEnabled:     p.AutoDeployEnabled,
+		AutoDeployPullImages:  p.AutoDeployPullImages,
+		AutoDeploySkipGitPull: p.AutoDeploySkipGitPull,
+		WatcherDisabled:       p.WatcherDisabled,
+		SkipVolumeInit:        p.SkipVolumeInit,
+		VolumeInitPhase:       p.VolumeInitPhase.String(),
+		OfflineImages:         p.OfflineImages,
+		PinImageDigests:       p.PinImageDigests,
+		VerboseBuild:          p.VerboseBuild,
+		VerbosePull:           p.VerbosePull,
+		BuildOnDeploy:         p.BuildOnDeploy,
+		IsOutdated:            p.IsOutdated(),
+		SortOrder:             p.SortOrder,
+		CreatedAt:             p.CreatedAt,
+		UpdatedAt:             p.UpdatedAt,
 	}
 }
 
@@ -101,6 +189,57 @@ func ConvertProjectsToViews(projects []*domain.Project) []projectcomponent.Proje
 	return views
 }
 
+// ConvertStatusToLiveView converts a Docker Compose status to the live status shown on a project card
+func ConvertStatusToLiveView(status *docker.ComposeStatus) projectcomponent.LiveStatusView {
+	view := projectcomponent.LiveStatusView{Status: status.Status.ProjectStatus().String()}
+	if status.Status == docker.ComposeProjectStatusDegraded {
+		view.Status = status.Status.String()
+	}
+
+	for _, container := range status.Containers {
+		if container.RestartCount > view.RestartCount {
+			view.RestartCount = container.RestartCount
+			view.LastExitReason = container.LastExitReason
+		}
+	}
+	return view
+}
+
+// ConvertStatusErrorToLiveView converts a failed status lookup to the live status shown on a project card.
+// Docker not answering in time is shown as unavailable rather than leaving the card spinning.
+func ConvertStatusErrorToLiveView(err error) projectcomponent.LiveStatusView {
+	if docker.IsTimeout(err) {
+		return projectcomponent.LiveStatusView{Status: "unavailable", Detail: err.Error()}
+	}
+	return projectcomponent.LiveStatusView{Status: "unknown"}
+}
+
+// ConvertSummaryToView converts the dashboard summary to its frontend view
+func ConvertSummaryToView(summary *project.DashboardSummary) projectcomponent.DashboardSummaryView {
+	view := projectcomponent.DashboardSummaryView{
+		TotalProjects:    summary.TotalProjects,
+		Running:          summary.Running,
+		Stopped:          summary.Stopped,
+		Error:            summary.Error,
+		LiveRunning:      summary.LiveRunning,
+		LiveChecked:      summary.LiveChecked,
+		DeploymentsToday: summary.DeploymentsToday,
+	}
+	if summary.LastDeploymentAt != nil {
+		view.LastDeployment = summary.LastDeploymentAt.Format("2006-01-02 15:04")
+	}
+	return view
+}
+
+// formatDeployHooks formats deploy hooks as PHASE:SERVICE[:COMMAND], as the project form takes them
+func formatDeployHooks(hooks []domain.DeployHook) []string {
+	formatted := make([]string, len(hooks))
+	for i, hook := range hooks {
+		formatted[i] = hook.String()
+	}
+	return formatted
+}
+
 // ConvertGitAuthConfig converts backend GitAuthConfig to frontend GitAuthConfig
 func ConvertGitAuthConfig(auth *domain.GitAuthConfig) *projectcomponent.GitAuthConfig {
 	if auth == nil {
@@ -126,6 +265,22 @@ func ConvertGitAuthConfig(auth *domain.GitAuthConfig) *projectcomponent.GitAuthC
 	return result
 }
 
+// ConvertEmailNotifyConfig converts the email notification settings of a project to the frontend view
+func ConvertEmailNotifyConfig(email *domain.EmailNotifyConfig) *projectcomponent.EmailNotifyConfig {
+	if email == nil {
+		return nil
+	}
+	return &projectcomponent.EmailNotifyConfig{
+		To:       email.To,
+		Host:     email.Host,
+		Port:     email.Port,
+		Username: email.Username,
+		Password: email.Password,
+		From:     email.From,
+		TLS:      email.TLS,
+	}
+}
+
 // renderProjectGrid fetches projects and renders the project grid
 func renderProjectGrid(w http.ResponseWriter, r *http.Request, trigger string) error {
 	projectService := app.GetProjectService()
@@ -143,7 +298,9 @@ func renderProjectGrid(w http.ResponseWriter, r *http.Request, trigger string) e
 	component := projectcomponent.ProjectGrid(projectViews, len(projectViews) > 0)
 
 	w.Header().Set("Content-Type", "text/html")
-	w.Header().Set("HX-Trigger-After-Settle", trigger)
+	if trigger != "" {
+		w.Header().Set("HX-Trigger-After-Settle", trigger)
+	}
 
 	return component.Render(r.Context(), w)
 }
@@ -213,6 +370,20 @@ func StreamOutput(w http.ResponseWriter, outputChan <-chan docker.StreamMessage,
 	return nil
 }
 
+// WriteJSON encodes value as a JSON response with the given status code
+func WriteJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		LogOperationError("write_json", "handlers", err)
+	}
+}
+
+// WriteJSONError writes an error message as a JSON response
+func WriteJSONError(w http.ResponseWriter, status int, message string) {
+	WriteJSON(w, status, map[string]string{"error": message})
+}
+
 // LogOperationError logs errors with consistent structure
 func LogOperationError(operation, layer string, err error, fields ...any) {
 	args := []any{"layer", layer, "operation", operation, "error", err}
@@ -283,33 +454,92 @@ func HandleHTMLContent(htmlFunc func(uuid.UUID) (string, error)) http.HandlerFun
 // HandleStream creates a generic handler for streaming endpoints
 func HandleStream(streamFunc func(uuid.UUID, chan<- docker.StreamMessage) error, streamType string) http.HandlerFunc {
 	return withProjectID(func(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
-		SetupSSE(w)
+		streamOperation(w, streamType, func(outputChan chan<- docker.StreamMessage) error {
+			return streamFunc(projectID, outputChan)
+		}, "project_id", projectID)
+	})
+}
 
-		outputChan := make(chan docker.StreamMessage, 100)
-
-		// Start streaming in a goroutine
-		go func() {
-			defer close(outputChan) // Close channel when streaming function completes
-			if err := streamFunc(projectID, outputChan); err != nil {
-				LogOperationError(fmt.Sprintf("%s_stream", streamType), "handlers", err, "project_id", projectID)
-				// Send error as StreamMessage
-				errorMsg := docker.StreamMessage{
-					Type: "error",
-					Content: fmt.Sprintf(
-						"%s failed: %s",
-						strings.ToUpper(streamType[:1])+streamType[1:],
-						err.Error(),
-					),
-				}
-				select {
-				case outputChan <- errorMsg:
-				default:
-				}
+// HandleFormStream creates a handler for streaming endpoints that take a form, such as project creation
+func HandleFormStream(
+	streamFunc func(*http.Request, chan<- docker.StreamMessage) error,
+	streamType string,
+) http.HandlerFunc {
+	return WithFormParsing(func(w http.ResponseWriter, r *http.Request) {
+		streamOperation(w, streamType, func(outputChan chan<- docker.StreamMessage) error {
+			return streamFunc(r, outputChan)
+		})
+	})
+}
+
+// streamOperation runs an operation and streams its output to the client as Server-Sent Events,
+// ending with an error message when the operation fails
+func streamOperation(
+	w http.ResponseWriter,
+	streamType string,
+	operation func(chan<- docker.StreamMessage) error,
+	fields ...any,
+) {
+	SetupSSE(w)
+
+	outputChan := make(chan docker.StreamMessage, 100)
+
+	// Start streaming in a goroutine
+	go func() {
+		defer close(outputChan) // Close channel when streaming function completes
+		if err := operation(outputChan); err != nil {
+			LogOperationError(fmt.Sprintf("%s_stream", streamType), "handlers", err, fields...)
+			// Send error as StreamMessage
+			errorMsg := docker.StreamMessage{
+				Type: "error",
+				Content: fmt.Sprintf(
+					"%s failed: %s",
+					strings.ToUpper(streamType[:1])+streamType[1:],
+					err.Error(),
+				),
 			}
-		}()
+			select {
+			case outputChan <- errorMsg:
+			default:
+			}
+		}
+	}()
+
+	if err := StreamOutput(w, outputChan, streamType); err != nil {
+		LogOperationError(fmt.Sprintf("%s_stream_output", streamType), "handlers", err, fields...)
+	}
+}
+
+// HandleProjectGrid renders the project grid, e.g. to show a project created through a stream
+func HandleProjectGrid() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := renderProjectGrid(w, r, ""); err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	}
+}
 
+// HandleSubscription creates a handler that streams the output of an operation another client
+// started. The subscription ends when the operation finishes or the client disconnects.
+func HandleSubscription(
+	subscribe func(uuid.UUID) (<-chan docker.StreamMessage, func(), error),
+	streamType string,
+) http.HandlerFunc {
+	return withProjectID(func(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+		outputChan, unsubscribe, err := subscribe(projectID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		defer unsubscribe()
+
+		// Unsubscribing closes outputChan, which ends StreamOutput while it waits for output
+		stop := context.AfterFunc(r.Context(), unsubscribe)
+		defer stop()
+
+		SetupSSE(w)
 		if err := StreamOutput(w, outputChan, streamType); err != nil {
-			LogOperationError(fmt.Sprintf("%s_stream_output", streamType), "handlers", err, "project_id", projectID)
+			LogOperationError(fmt.Sprintf("%s_subscription", streamType), "handlers", err, "project_id", projectID)
 		}
 	})
 }
@@ -332,3 +562,62 @@ func HandleProjectAction(actionFunc func(*http.Request) error, successTrigger, o
 		}
 	})
 }
+
+// HandleReorderProjects creates a handler that changes the order of the projects on the dashboard and
+// re-renders the project grid in the new order
+func HandleReorderProjects(actionFunc func(*http.Request) error) http.HandlerFunc {
+	return WithFormParsing(func(w http.ResponseWriter, r *http.Request) {
+		if err := actionFunc(r); err != nil {
+			LogOperationError("reorder_projects", "handlers", err)
+			http.Error(w, fmt.Sprintf("Failed to reorder projects: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		if err := renderProjectGrid(w, r, ""); err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
+
+// HandleWatcherCheck runs an immediate watcher check for a project and re-renders the project grid
+func HandleWatcherCheck() http.HandlerFunc {
+	return withProjectID(func(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+		watcherService := app.GetWatcherService()
+		if watcherService == nil {
+			http.Error(w, "Watcher is disabled", http.StatusServiceUnavailable)
+			return
+		}
+
+		check, err := watcherService.CheckNow(r.Context(), projectID)
+		if err != nil {
+			LogOperationError("watcher_check_now", "handlers", err, "project_id", projectID)
+			http.Error(w, fmt.Sprintf("Failed to check project: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		message, toastType := "No changes found", "info"
+		switch check.Outcome {
+		case watcher.CheckOutcomeDeployed:
+			message, toastType = "New commit deployed", "success"
+		case watcher.CheckOutcomeUpdatesAvailable:
+			message, toastType = "Updates available", "info"
+		case watcher.CheckOutcomeDeferred:
+			message, toastType = "Deployment deferred, another operation on the project is in progress", "info"
+		case watcher.CheckOutcomeError:
+			message, toastType = fmt.Sprintf("Check failed: %s", check.Error), "error"
+		}
+
+		trigger, err := json.Marshal(map[string]any{
+			"showToast": map[string]string{"message": message, "type": toastType},
+		})
+		if err != nil {
+			LogOperationError("watcher_check_now", "handlers", err, "project_id", projectID)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := renderProjectGrid(w, r, string(trigger)); err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/apitoken"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/web/handlers"
+)
+
+// withAPIToken checks the API token of the request against action. Routes with a project ID are
+// checked against that project, other routes concern no single project and only accept tokens that
+// are not scoped to one. Requests without a token pass unless the server requires one.
+func withAPIToken(action domain.APITokenAction) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var projectID *uuid.UUID
+			if id, err := handlers.ParseProjectID(r); err == nil {
+				projectID = &id
+			}
+			if !authorizeAPIRequest(w, r, action, projectID) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authorizeAPIRequest checks the API token of the request, writing the error response and returning
+// false when the request may not go on. A token that is sent is always checked, even when the server
+// does not require one.
+func authorizeAPIRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	action domain.APITokenAction,
+	projectID *uuid.UUID,
+) bool {
+	value, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		if cfg := app.GetConfig(); cfg != nil && cfg.HTTPRequireAPIToken {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			handlers.WriteJSONError(w, http.StatusUnauthorized, "API token required")
+			return false
+		}
+		return true
+	}
+
+	tokens := app.GetAPITokenService()
+	if tokens == nil {
+		handlers.WriteJSONError(w, http.StatusServiceUnavailable, "API tokens are not available")
+		return false
+	}
+	token, err := tokens.Authenticate(strings.TrimSpace(value))
+	if errors.Is(err, apitoken.ErrInvalidToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		handlers.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return false
+	}
+	if err != nil {
+		handlers.LogOperationError("authenticate_api_token", "api", err)
+		handlers.WriteJSONError(w, http.StatusInternalServerError, "failed to check API token")
+		return false
+	}
+	if !token.Allows(action, projectID) {
+		handlers.WriteJSONError(w, http.StatusForbidden, "API token does not allow "+string(action)+" here")
+		return false
+	}
+	return true
+}
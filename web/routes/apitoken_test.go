@@ -0,0 +1,119 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+
+	"github.com/oar-cd/oar/apitoken"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/db"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/repository"
+	"github.com/oar-cd/oar/web/routes"
+)
+
+func TestAPITokenScopes(t *testing.T) {
+	database, err := db.InitDatabase(db.DBConfig{Path: ":memory:", LogLevel: logger.Silent})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrateAll(database))
+
+	projectID := uuid.New()
+	require.NoError(t, database.Create(&db.ProjectModel{
+		BaseModel:    db.BaseModel{ID: projectID},
+		Name:         "scoped",
+		GitURL:       "https://example.com/scoped.git",
+		GitBranch:    "main",
+		WorkingDir:   "/tmp/scoped",
+		ComposeFiles: "compose.yaml",
+		Status:       "running",
+	}).Error)
+
+	tokens := apitoken.NewService(repository.NewAPITokenRepository(database))
+	app.SetAPITokenServiceForTesting(tokens)
+	t.Cleanup(func() { app.SetAPITokenServiceForTesting(nil) })
+
+	_, scopedToken, err := tokens.Create("read scoped", &projectID, []domain.APITokenAction{domain.APITokenActionRead})
+	require.NoError(t, err)
+	_, globalToken, err := tokens.Create("read all", nil, []domain.APITokenAction{domain.APITokenActionRead})
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	routes.RegisterAPIRoutes(r)
+
+	otherProjectID := uuid.New()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{
+			name:   "action not allowed",
+			method: http.MethodPut,
+			path:   "/api/v1/projects/" + projectID.String() + "/deployments/" + uuid.NewString() + "/annotation",
+			token:  scopedToken,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "deploy not allowed",
+			method: http.MethodPost,
+			path:   "/api/v1/watcher/check/" + projectID.String(),
+			token:  scopedToken,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "other project",
+			method: http.MethodGet,
+			path:   "/api/v1/projects/" + otherProjectID.String() + "/deployments",
+			token:  scopedToken,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "all projects with a scoped token",
+			method: http.MethodGet,
+			path:   "/api/v1/projects/status",
+			token:  scopedToken,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unknown token",
+			method: http.MethodGet,
+			path:   "/api/v1/projects/" + projectID.String() + "/deployments",
+			token:  "oar_unknown",
+			status: http.StatusUnauthorized,
+		},
+		{
+			// Passes the token check, then fails on the ID
+			name:   "allowed",
+			method: http.MethodGet,
+			path:   "/api/v1/projects/not-a-uuid/deployments",
+			token:  globalToken,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "public endpoint",
+			method: http.MethodGet,
+			path:   "/api/v1/openapi.json",
+			token:  scopedToken,
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.path, strings.NewReader("{}"))
+			request.Header.Set("Authorization", "Bearer "+tt.token)
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, request)
+			assert.Equal(t, tt.status, recorder.Code, recorder.Body.String())
+		})
+	}
+}
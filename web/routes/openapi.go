@@ -0,0 +1,357 @@
+package routes
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/watcher"
+	"github.com/oar-cd/oar/web/handlers"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the API document follows
+const openAPIVersion = "3.0.3"
+
+// errorResponse is the body of every error response of the API, see handlers.WriteJSONError
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// webhookResponse is the body of an accepted push webhook
+type webhookResponse struct {
+	Status string `json:"status"` // Always "accepted"
+}
+
+// apiOperation describes an endpoint of the JSON API for the OpenAPI document. Request and response
+// bodies are given as values of the types the handlers encode, their schemas are derived from the
+// types' JSON tags.
+type apiOperation struct {
+	method      string
+	path        string
+	id          string
+	summary     string
+	parameters  []apiParameter
+	requestBody any
+	responses   []apiResponse
+}
+
+// apiParameter is a path or query parameter of an endpoint
+type apiParameter struct {
+	name        string
+	in          string // path, query or header
+	description string
+	schema      map[string]any
+}
+
+// apiResponse is a response of an endpoint. A nil body is an error response.
+type apiResponse struct {
+	status      int
+	description string
+	body        any
+}
+
+var (
+	uuidSchema     = map[string]any{"type": "string", "format": "uuid"}
+	projectIDParam = apiParameter{name: "id", in: "path", description: "Project ID", schema: uuidSchema}
+
+	unauthorizedResponse = apiResponse{http.StatusUnauthorized, "The API token is missing or invalid", nil}
+	forbiddenResponse    = apiResponse{http.StatusForbidden, "The API token does not allow this request", nil}
+)
+
+// apiOperations lists the endpoints registered by RegisterAPIRoutes, with the status codes their
+// handlers answer with
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodGet, path: "/api/v1/openapi.json", id: "getOpenAPI",
+		summary:   "This OpenAPI document",
+		responses: []apiResponse{{http.StatusOK, "OpenAPI document", map[string]any{}}},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/version", id: "getVersion",
+		summary:   "Version and build details of the server",
+		responses: []apiResponse{{http.StatusOK, "Build details", app.BuildInfo{}}},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/watcher", id: "getWatcherStatus",
+		summary: "Watcher status, with the result of the last check of each project",
+		responses: []apiResponse{
+			{http.StatusOK, "Watcher status", watcher.WatcherStatus{}},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusServiceUnavailable, "The watcher is disabled", nil},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/watcher/check/{id}", id: "checkProject",
+		summary:    "Check a project for new commits now, deploying it if automatic deployment is enabled",
+		parameters: []apiParameter{projectIDParam},
+		responses: []apiResponse{
+			{http.StatusOK, "Result of the check", watcher.ProjectCheck{}},
+			{http.StatusBadRequest, "Invalid project ID", nil},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusInternalServerError, "The check could not run", nil},
+			{http.StatusServiceUnavailable, "The watcher is disabled", nil},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/projects/{id}/webhook", id: "projectWebhook",
+		summary: "Push webhook: checks the project in the background, after a quiet period",
+		parameters: []apiParameter{
+			projectIDParam,
+			{watcher.WebhookSignatureHeader, "header",
+				"HMAC-SHA256 of the body as sha256=<hex>, required when the project has a webhook secret",
+				map[string]any{"type": "string"}},
+		},
+		responses: []apiResponse{
+			{http.StatusAccepted, "The check is scheduled", webhookResponse{}},
+			{http.StatusBadRequest, "Invalid project ID", nil},
+			{http.StatusUnauthorized, "The signature or API token is missing or invalid", nil},
+			{http.StatusForbidden, "The API token does not allow deploying the project", nil},
+			{http.StatusNotFound, "Project not found", nil},
+			{http.StatusServiceUnavailable, "The watcher is disabled", nil},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/projects/{id}/declared", id: "getDeclared",
+		summary:    "Services, named volumes and images declared in the project's configuration",
+		parameters: []apiParameter{projectIDParam},
+		responses: []apiResponse{
+			{http.StatusOK, "Declared services, volumes and images", declaredResponse{}},
+			{http.StatusBadRequest, "Invalid project ID", nil},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusNotFound, "Project not found", nil},
+			{http.StatusInternalServerError, "The configuration could not be read", nil},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/projects/{id}/containers/{container}/logs", id: "getContainerLogs",
+		summary: "Logs of a single container of the project",
+		parameters: []apiParameter{
+			projectIDParam,
+			{"container", "path", "Name or ID of the container", map[string]any{"type": "string"}},
+			{"tail", "query", "Number of lines from the end of the logs", map[string]any{"type": "integer"}},
+			{"since", "query", "Only logs since a timestamp or a duration, e.g. 10m", map[string]any{"type": "string"}},
+			{"timestamps", "query", "Prefix lines with their timestamp when true", map[string]any{"type": "boolean"}},
+		},
+		responses: []apiResponse{
+			{http.StatusOK, "Logs of the container", containerLogsResponse{}},
+			{http.StatusBadRequest, "Invalid project ID or options", nil},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusNotFound, "Project not found, or the container does not belong to it", nil},
+			{http.StatusInternalServerError, "The logs could not be read", nil},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/projects/{id}/deployments", id: "listDeployments",
+		summary:    "Deployment history of the project, newest first",
+		parameters: []apiParameter{projectIDParam},
+		responses: []apiResponse{
+			{http.StatusOK, "Deployments", []deploymentResponse{}},
+			{http.StatusBadRequest, "Invalid project ID", nil},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusNotFound, "Project not found", nil},
+		},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/projects/{id}/deployments/{deploymentID}/annotation",
+		id:      "annotateDeployment",
+		summary: "Replace the notes and labels of a deployment",
+		parameters: []apiParameter{
+			projectIDParam,
+			{"deploymentID", "path", "Deployment ID", uuidSchema},
+		},
+		requestBody: deploymentAnnotationRequest{},
+		responses: []apiResponse{
+			{http.StatusOK, "The annotated deployment", deploymentResponse{}},
+			{http.StatusBadRequest, "Invalid ID or request body", nil},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusNotFound, "Deployment not found in the project", nil},
+			{http.StatusInternalServerError, "The annotation could not be saved", nil},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/projects/status", id: "listProjectStatuses",
+		summary: "Live status of all projects, by project ID",
+		responses: []apiResponse{
+			{http.StatusOK, "Statuses by project ID", map[string]projectStatusResponse{}},
+			unauthorizedResponse,
+			forbiddenResponse,
+			{http.StatusInternalServerError, "The projects could not be listed", nil},
+		},
+	},
+}
+
+// openAPIDocument returns the OpenAPI document of the JSON API, built once from apiOperations
+var openAPIDocument = sync.OnceValue(func() map[string]any {
+	schemas := map[string]any{}
+	errorSchema := schemaOf(reflect.TypeFor[errorResponse](), schemas)
+
+	paths := map[string]any{}
+	for _, op := range apiOperations {
+		operation := map[string]any{
+			"operationId": op.id,
+			"summary":     op.summary,
+		}
+		if len(op.parameters) > 0 {
+			parameters := make([]any, len(op.parameters))
+			for i, param := range op.parameters {
+				parameters[i] = map[string]any{
+					"name":        param.name,
+					"in":          param.in,
+					"description": param.description,
+					"required":    param.in == "path",
+					"schema":      param.schema,
+				}
+			}
+			operation["parameters"] = parameters
+		}
+		if op.requestBody != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemaOf(reflect.TypeOf(op.requestBody), schemas)),
+			}
+		}
+		responses := map[string]any{}
+		for _, response := range op.responses {
+			schema := errorSchema
+			if response.body != nil {
+				schema = schemaOf(reflect.TypeOf(response.body), schemas)
+			}
+			responses[strconv.Itoa(response.status)] = map[string]any{
+				"description": response.description,
+				"content":     jsonContent(schema),
+			}
+		}
+		operation["responses"] = responses
+
+		item, _ := paths[op.path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "Oar API",
+			"version": app.GetBuildInfo().Version,
+			"description": "JSON API of the Oar server, used by the CLI and external tools. Requests are " +
+				"authenticated with an API token sent as a bearer token, created with oar token create. " +
+				"Requests without a token are only refused when the server requires one.",
+		},
+		// The token is optional unless the server requires one
+		"security": []any{map[string]any{"apiToken": []any{}}, map[string]any{}},
+		"paths":    paths,
+		"components": map[string]any{
+			"schemas":         schemas,
+			"securitySchemes": map[string]any{"apiToken": map[string]any{"type": "http", "scheme": "bearer"}},
+		},
+	}
+})
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaOf returns the JSON schema of values of type t as encoding/json encodes them. Structs are
+// added to schemas and referenced by name.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[time.Duration]():
+		return map[string]any{"type": "integer", "format": "int64", "description": "Duration in nanoseconds"}
+	case reflect.TypeFor[uuid.UUID]():
+		return uuidSchema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaOf(t.Elem(), schemas)
+		if _, isRef := schema["$ref"]; isRef {
+			return schema
+		}
+		nullable := map[string]any{"nullable": true}
+		for key, value := range schema {
+			nullable[key] = value
+		}
+		return nullable
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := schemas[name]; !ok {
+			// Registered before its fields, so that recursive types end
+			schemas[name] = nil
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	// Interfaces may hold anything
+	return map[string]any{}
+}
+
+// structSchema returns the object schema of a struct. Fields without omitempty are required, since
+// they are always encoded.
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	required := []any{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// schemaName returns the component name of a struct type, e.g. Deployment for deploymentResponse
+func schemaName(t reflect.Type) string {
+	runes := []rune(strings.TrimSuffix(t.Name(), "Response"))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// handleOpenAPI serves the OpenAPI document of the JSON API
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	handlers.WriteJSON(w, http.StatusOK, openAPIDocument())
+}
@@ -0,0 +1,161 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/oar-cd/oar/web/routes"
+)
+
+// openAPIDocument is the part of an OpenAPI 3.0 document the API spec uses
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas         map[string]json.RawMessage `json:"schemas"`
+		SecuritySchemes map[string]struct {
+			Type   string `json:"type"`
+			Scheme string `json:"scheme"`
+		} `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string `json:"operationId"`
+	Parameters  []struct {
+		Name     string          `json:"name"`
+		In       string          `json:"in"`
+		Required bool            `json:"required"`
+		Schema   json.RawMessage `json:"schema"`
+	} `json:"parameters"`
+	Responses map[string]struct {
+		Description string `json:"description"`
+	} `json:"responses"`
+}
+
+var (
+	pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+	refPattern       = regexp.MustCompile(`"\$ref":"([^"]*)"`)
+	schemaTypes      = []string{"array", "boolean", "integer", "number", "object", "string"}
+	typePattern      = regexp.MustCompile(`"type":"([^"]*)"`)
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	r := chi.NewRouter()
+	routes.RegisterAPIRoutes(r)
+
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	raw := recorder.Body.Bytes()
+
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(raw, &doc))
+
+	t.Run("valid OpenAPI 3.0", func(t *testing.T) {
+		assert.Regexp(t, `^3\.0\.\d+$`, doc.OpenAPI)
+		assert.NotEmpty(t, doc.Info.Title)
+		assert.NotEmpty(t, doc.Info.Version)
+		assert.Equal(t, "bearer", doc.Components.SecuritySchemes["apiToken"].Scheme)
+
+		operationIDs := map[string]bool{}
+		for path, item := range doc.Paths {
+			assert.True(t, strings.HasPrefix(path, "/"), "path %s must start with a slash", path)
+			templated := pathParamPattern.FindAllStringSubmatch(path, -1)
+			for method, op := range item {
+				name := strings.ToUpper(method) + " " + path
+				assert.Contains(t, []string{"get", "put", "post", "delete", "patch", "head", "options"}, method)
+				assert.NotEmpty(t, op.OperationID, name)
+				assert.False(t, operationIDs[op.OperationID], "duplicate operationId %s", op.OperationID)
+				operationIDs[op.OperationID] = true
+
+				// Every templated path parameter is declared, and is required
+				var declared []string
+				for _, param := range op.Parameters {
+					assert.Contains(t, []string{"path", "query", "header", "cookie"}, param.In, name)
+					assert.NotEmpty(t, param.Schema, "%s parameter %s has no schema", name, param.Name)
+					if param.In == "path" {
+						assert.True(t, param.Required, "%s path parameter %s must be required", name, param.Name)
+						declared = append(declared, param.Name)
+					}
+				}
+				var expected []string
+				for _, match := range templated {
+					expected = append(expected, match[1])
+				}
+				assert.ElementsMatch(t, expected, declared, name)
+
+				require.NotEmpty(t, op.Responses, name)
+				for status, response := range op.Responses {
+					code, err := strconv.Atoi(status)
+					require.NoError(t, err, "%s has response %s", name, status)
+					assert.True(t, code >= 100 && code < 600, "%s has response %s", name, status)
+					assert.NotEmpty(t, response.Description, "%s response %s needs a description", name, status)
+				}
+			}
+		}
+
+		// References resolve to component schemas, and schemas only use the types OpenAPI knows
+		for _, match := range refPattern.FindAllSubmatch(raw, -1) {
+			ref := string(match[1])
+			require.True(t, strings.HasPrefix(ref, "#/components/schemas/"), "unexpected reference %s", ref)
+			assert.Contains(t, doc.Components.Schemas, strings.TrimPrefix(ref, "#/components/schemas/"))
+		}
+		// Security schemes have types of their own
+		var withoutSecurity map[string]any
+		require.NoError(t, json.Unmarshal(raw, &withoutSecurity))
+		delete(withoutSecurity["components"].(map[string]any), "securitySchemes")
+		schemaRaw, err := json.Marshal(withoutSecurity)
+		require.NoError(t, err)
+		for _, match := range typePattern.FindAllSubmatch(schemaRaw, -1) {
+			assert.Contains(t, schemaTypes, string(match[1]))
+		}
+	})
+
+	t.Run("matches the routes", func(t *testing.T) {
+		var registered, documented []string
+		walk := func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			registered = append(registered, method+" "+route)
+			return nil
+		}
+		require.NoError(t, chi.Walk(r, walk))
+		for path, item := range doc.Paths {
+			for method := range item {
+				documented = append(documented, strings.ToUpper(method)+" "+path)
+			}
+		}
+		slices.Sort(registered)
+		slices.Sort(documented)
+		assert.Equal(t, registered, documented)
+	})
+
+	t.Run("error responses", func(t *testing.T) {
+		assert.JSONEq(t, `{"type":"object","properties":{"error":{"type":"string"}},"required":["error"]}`,
+			string(doc.Components.Schemas["Error"]))
+
+		// An invalid ID is rejected before any service is used
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/projects/not-a-uuid/deployments", nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, doc.Paths["/api/v1/projects/{id}/deployments"]["get"].Responses,
+			strconv.Itoa(recorder.Code))
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.NotEmpty(t, body["error"])
+	})
+}
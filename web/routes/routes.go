@@ -2,16 +2,25 @@
 package routes
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/a-h/templ"
+	"github.com/docker/go-units"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/oar-cd/oar/app"
 	"github.com/oar-cd/oar/docker"
 	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/project"
+	"github.com/oar-cd/oar/watcher"
 	"github.com/oar-cd/oar/web/actions"
 	"github.com/oar-cd/oar/web/components/modals"
 	projectcomponent "github.com/oar-cd/oar/web/components/project"
@@ -19,6 +28,9 @@ import (
 	"github.com/oar-cd/oar/web/pages"
 )
 
+// maxWebhookBodySize bounds the push payloads read to verify their signature. GitHub caps them at 25 MB.
+const maxWebhookBodySize = 25 << 20
+
 // Route registration functions
 
 // RegisterHomeRoutes registers the home page route
@@ -29,7 +41,7 @@ func RegisterHomeRoutes(r chi.Router) {
 		if err != nil {
 			handlers.LogOperationError("list_projects", "main", err)
 			// Fall back to empty state on error
-			component := pages.Home(handlers.GetVersion())
+			component := pages.Home(handlers.GetBuildInfo())
 			if err := handlers.RenderComponent(w, r, component, "home_page_fallback"); err != nil {
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
@@ -40,9 +52,15 @@ func RegisterHomeRoutes(r chi.Router) {
 
 		var component templ.Component
 		if len(projectViews) > 0 {
-			component = pages.HomeWithProjects(projectViews, handlers.GetVersion())
+			summary, err := projectService.GetDashboardSummary()
+			if err != nil {
+				handlers.LogOperationError("dashboard_summary", "main", err)
+				summary = &project.DashboardSummary{}
+			}
+			summaryView := handlers.ConvertSummaryToView(summary)
+			component = pages.HomeWithProjects(projectViews, summaryView, handlers.GetBuildInfo())
 		} else {
-			component = pages.Home(handlers.GetVersion())
+			component = pages.Home(handlers.GetBuildInfo())
 		}
 
 		if err := handlers.RenderComponent(w, r, component, "home_page"); err != nil {
@@ -56,12 +74,41 @@ func RegisterProjectRoutes(r chi.Router) {
 	r.Route("/projects", func(r chi.Router) {
 		// Project creation
 		r.Get("/create", func(w http.ResponseWriter, r *http.Request) {
-			component := modals.CreateProjectModal()
+			component := modals.CreateProjectModal(app.GetConfig().ComposeDefaultFiles)
 			if err := handlers.RenderComponent(w, r, component, "create_project_modal"); err != nil {
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
 		})
-		r.Post("/create", handlers.HandleProjectAction(actions.CreateProject, "projectCreated", "create_project"))
+		r.Post("/create/stream", handlers.HandleFormStream(actions.CreateProject, "creation"))
+
+		// Project grid, refreshed after a project was created through the stream
+		r.Get("/grid", handlers.HandleProjectGrid())
+
+		// Order of the project cards, changed by dragging them
+		r.Post("/reorder", handlers.HandleReorderProjects(actions.ReorderProjects))
+
+		// Live status pills for all projects, swapped out-of-band in one request
+		r.Get("/statuses", func(w http.ResponseWriter, r *http.Request) {
+			statuses, errs, err := getAllProjectStatuses()
+			if err != nil {
+				handlers.LogOperationError("list_project_statuses", "main", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			pills := make(map[string]projectcomponent.LiveStatusView, len(statuses)+len(errs))
+			for projectID, status := range statuses {
+				pills[projectID.String()] = handlers.ConvertStatusToLiveView(status)
+			}
+			for projectID, statusErr := range errs {
+				pills[projectID.String()] = handlers.ConvertStatusErrorToLiveView(statusErr)
+			}
+
+			component := projectcomponent.StatusPillsOOB(pills)
+			if err := handlers.RenderComponent(w, r, component, "project_status_pills"); err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+		})
 
 		// Individual project routes
 		r.Route("/{id}", func(r chi.Router) {
@@ -74,16 +121,33 @@ func RegisterProjectRoutes(r chi.Router) {
 			// Project actions
 			r.Get("/config", handlers.HandleModal(getConfigProjectModalWithLoading, "config_project_modal"))
 			r.Get("/config/content", handlers.HandleHTMLContent(getConfigProjectContent))
+			r.Get("/env", handlers.HandleModal(getEnvProjectModal, "env_project_modal"))
+			r.Get("/disk-usage", handlers.HandleModal(getDiskUsageProjectModalWithLoading, "disk_usage_project_modal"))
+			r.Get("/disk-usage/content", handlers.HandleModal(getDiskUsageProjectContent, "disk_usage_project_content"))
 			r.Get("/deploy", handlers.HandleModal(getDeployProjectModal, "deploy_project_modal"))
 			r.Get("/stop", handlers.HandleModal(getStopProjectModal, "stop_project_modal"))
 			r.Get("/logs", handlers.HandleModal(getLogsProjectModalWithLoading, "logs_project_modal"))
-			r.Get("/logs/content", handlers.HandleHTMLContent(getLogsProjectContent))
+			r.Get("/logs/content", handleLogsContent)
 			r.Get("/deployments", handlers.HandleModal(getDeploymentsProjectModal, "deployments_project_modal"))
 
+			// Read-only share links
+			r.Get("/share", handleShareModal)
+			r.Post("/share", handleCreateShareLink)
+			r.Delete("/share/{shareID}", handleRevokeShareLink)
+
 			// Streaming endpoints
 			r.Post("/deploy/stream", handlers.HandleStream(actions.DeployProject, "deployment"))
+			r.Get("/deploy/watch", handlers.HandleSubscription(actions.WatchDeployment, "deployment"))
 			r.Post("/stop/stream", handlers.HandleStream(actions.StopProject, "stop"))
 
+			// Freezing and resuming the containers
+			r.Post("/pause", handlers.HandleProjectAction(actions.PauseProject, "projectPaused", "pause_project"))
+			r.Post("/unpause",
+				handlers.HandleProjectAction(actions.UnpauseProject, "projectResumed", "unpause_project"))
+
+			// Manual watcher check
+			r.Post("/check", handlers.HandleWatcherCheck())
+
 			// Status pill updates
 			r.Get("/status", handlers.HandleModal(getProjectStatusPill, "project_status_pill"))
 		})
@@ -102,11 +166,24 @@ func RegisterUtilityRoutes(r chi.Router) {
 			return
 		}
 
+		// ${VAR} references in the URL are resolved from the variables of the form, as on deployment
+		source, err := (&domain.Project{
+			GitURL:    gitURL,
+			Variables: strings.Split(strings.TrimSpace(r.FormValue("variables")), "\n"),
+		}).ResolveGitSource()
+		if err != nil {
+			handlers.LogOperationError("test_git_auth", "main", err, "git_url", gitURL)
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("HX-Trigger-After-Settle", "testAuthError")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
 		gitService := app.GetGitService()
 		gitAuthConfig := handlers.BuildGitAuthConfig(r)
 
-		if err := gitService.TestAuthentication(gitURL, gitAuthConfig); err != nil {
-			handlers.LogOperationError("test_git_auth", "main", err, "git_url", gitURL)
+		if err := gitService.TestAuthentication(source.URL, gitAuthConfig); err != nil {
+			handlers.LogOperationError("test_git_auth", "main", source.RedactError(err), "git_url", gitURL)
 			w.Header().Set("Content-Type", "text/html")
 			w.Header().Set("HX-Trigger-After-Settle", "testAuthError")
 			w.WriteHeader(http.StatusOK)
@@ -125,6 +202,388 @@ func RegisterUtilityRoutes(r chi.Router) {
 			handlers.LogOperationError("health_check", "main", err)
 		}
 	})
+
+	// Per-project deployment metrics in the Prometheus text format
+	r.Get("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		app.GetMetrics().Handler().ServeHTTP(w, r)
+	})
+}
+
+// RegisterAPIRoutes registers the JSON API used by the CLI and external tools
+func RegisterAPIRoutes(r chi.Router) {
+	r.Route("/api/v1", func(r chi.Router) {
+		// Endpoints by the API token action they need, see withAPIToken
+		read := r.With(withAPIToken(domain.APITokenActionRead))
+		deploy := r.With(withAPIToken(domain.APITokenActionDeploy))
+		annotate := r.With(withAPIToken(domain.APITokenActionAnnotate))
+
+		// OpenAPI document of the endpoints below, see apiOperations
+		r.Get("/openapi.json", handleOpenAPI)
+
+		// Version and build details of the server
+		r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
+			handlers.WriteJSON(w, http.StatusOK, app.GetBuildInfo())
+		})
+
+		// Watcher self-status
+		read.Get("/watcher", func(w http.ResponseWriter, r *http.Request) {
+			watcherService := app.GetWatcherService()
+			if watcherService == nil {
+				handlers.WriteJSONError(w, http.StatusServiceUnavailable, "watcher is disabled")
+				return
+			}
+			handlers.WriteJSON(w, http.StatusOK, watcherService.Status())
+		})
+
+		// Check a project immediately instead of waiting for the next poll
+		deploy.Post("/watcher/check/{id}", func(w http.ResponseWriter, r *http.Request) {
+			watcherService := app.GetWatcherService()
+			if watcherService == nil {
+				handlers.WriteJSONError(w, http.StatusServiceUnavailable, "watcher is disabled")
+				return
+			}
+
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			check, err := watcherService.CheckNow(r.Context(), projectID)
+			if err != nil {
+				handlers.LogOperationError("watcher_check_now", "api", err, "project_id", projectID)
+				handlers.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			handlers.WriteJSON(w, http.StatusOK, check)
+		})
+
+		// Push notification from a git host. Deliveries are debounced and the check runs in the background.
+		r.Post("/projects/{id}/webhook", func(w http.ResponseWriter, r *http.Request) {
+			watcherService := app.GetWatcherService()
+			if watcherService == nil {
+				handlers.WriteJSONError(w, http.StatusServiceUnavailable, "watcher is disabled")
+				return
+			}
+
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			targetProject, err := app.GetProjectService().Get(projectID)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusNotFound, "project not found")
+				return
+			}
+
+			// Projects with a secret only accept deliveries signed with it. Without a secret, the API token
+			// of the request is checked as on the other endpoints.
+			if targetProject.WebhookSecret == "" {
+				if !authorizeAPIRequest(w, r, domain.APITokenActionDeploy, &projectID) {
+					return
+				}
+			} else {
+				body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+				if err != nil {
+					handlers.WriteJSONError(w, http.StatusBadRequest, "failed to read request body")
+					return
+				}
+				signature := r.Header.Get(watcher.WebhookSignatureHeader)
+				if !watcher.VerifyWebhookSignature(targetProject.WebhookSecret, body, signature) {
+					handlers.WriteJSONError(w, http.StatusUnauthorized, "invalid webhook signature")
+					return
+				}
+			}
+
+			watcherService.HandleWebhook(projectID)
+			handlers.WriteJSON(w, http.StatusAccepted, webhookResponse{Status: "accepted"})
+		})
+
+		// Services, named volumes and images declared in a project's configuration
+		read.Get("/projects/{id}/declared", func(w http.ResponseWriter, r *http.Request) {
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			projectService := app.GetProjectService()
+			if _, err := projectService.Get(projectID); err != nil {
+				handlers.WriteJSONError(w, http.StatusNotFound, "project not found")
+				return
+			}
+
+			var response declaredResponse
+			lists := []struct {
+				names *[]string
+				list  func(uuid.UUID) ([]string, error)
+			}{
+				{&response.Services, projectService.ListServices},
+				{&response.Volumes, projectService.ListDeclaredVolumes},
+				{&response.Images, projectService.ListImages},
+			}
+			for _, l := range lists {
+				if *l.names, err = l.list(projectID); err != nil {
+					handlers.LogOperationError("list_declared", "api", err, "project_id", projectID)
+					handlers.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+			}
+			handlers.WriteJSON(w, http.StatusOK, response)
+		})
+
+		// Logs of a single container of a project, such as one replica of a scaled service
+		read.Get("/projects/{id}/containers/{container}/logs", func(w http.ResponseWriter, r *http.Request) {
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			containerRef := chi.URLParam(r, "container")
+
+			opts, err := parseContainerLogsOptions(r.URL.Query())
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			projectService := app.GetProjectService()
+			if _, err := projectService.Get(projectID); err != nil {
+				handlers.WriteJSONError(w, http.StatusNotFound, "project not found")
+				return
+			}
+
+			stdout, stderr, err := projectService.GetContainerLogs(projectID, containerRef, opts)
+			if errors.Is(err, docker.ErrContainerNotFound) {
+				handlers.WriteJSONError(w, http.StatusNotFound, "container not found in project")
+				return
+			}
+			if err != nil {
+				handlers.LogOperationError("container_logs", "api", err,
+					"project_id", projectID, "container", containerRef)
+				handlers.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			handlers.WriteJSON(w, http.StatusOK, containerLogsResponse{
+				Container: containerRef,
+				Stdout:    stdout,
+				Stderr:    stderr,
+			})
+		})
+
+		// Deployment history of a project
+		read.Get("/projects/{id}/deployments", func(w http.ResponseWriter, r *http.Request) {
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			deployments, err := app.GetProjectService().ListDeployments(projectID)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusNotFound, "project not found")
+				return
+			}
+
+			response := make([]deploymentResponse, len(deployments))
+			for i, deployment := range deployments {
+				response[i] = newDeploymentResponse(deployment)
+			}
+			handlers.WriteJSON(w, http.StatusOK, response)
+		})
+
+		// Replace the notes and labels of a deployment
+		annotate.Put("/projects/{id}/deployments/{deploymentID}/annotation", func(w http.ResponseWriter, r *http.Request) {
+			projectID, err := handlers.ParseProjectID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			deploymentID, err := handlers.ParseDeploymentID(r)
+			if err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			var request deploymentAnnotationRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				handlers.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+
+			projectService := app.GetProjectService()
+			deployment, err := projectService.GetDeployment(deploymentID)
+			if err != nil || deployment.ProjectID != projectID {
+				handlers.WriteJSONError(w, http.StatusNotFound, "deployment not found")
+				return
+			}
+
+			deployment, err = projectService.AnnotateDeployment(projectID, deploymentID, domain.DeploymentAnnotation{
+				Notes:  request.Notes,
+				Labels: request.Labels,
+			})
+			if err != nil {
+				handlers.LogOperationError("annotate_deployment", "api", err, "deployment_id", deploymentID)
+				handlers.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			handlers.WriteJSON(w, http.StatusOK, newDeploymentResponse(deployment))
+		})
+
+		// Live status of all projects
+		read.Get("/projects/status", func(w http.ResponseWriter, r *http.Request) {
+			statuses, errs, err := getAllProjectStatuses()
+			if err != nil {
+				handlers.LogOperationError("list_project_statuses", "api", err)
+				handlers.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			response := make(map[string]projectStatusResponse, len(statuses)+len(errs))
+			for projectID, status := range statuses {
+				response[projectID.String()] = projectStatusResponse{
+					Status:     status.Status.String(),
+					Uptime:     status.Uptime,
+					Containers: status.Containers,
+				}
+			}
+			for projectID, statusErr := range errs {
+				response[projectID.String()] = projectStatusResponse{
+					Status: docker.ComposeProjectStatusUnknown.String(),
+					Error:  statusErr.Error(),
+				}
+			}
+			handlers.WriteJSON(w, http.StatusOK, response)
+		})
+	})
+}
+
+// projectStatusResponse is the JSON representation of a project's live status
+type projectStatusResponse struct {
+	Status     string                 `json:"status"`
+	Uptime     string                 `json:"uptime,omitempty"`
+	Containers []docker.ContainerInfo `json:"containers,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// containerLogsResponse is the JSON representation of the logs of a single container
+type containerLogsResponse struct {
+	Container string `json:"container"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+}
+
+// parseContainerLogsOptions reads the tail, since and timestamps query parameters of a container
+// logs request
+func parseContainerLogsOptions(query url.Values) (docker.LogsOptions, error) {
+	opts := docker.LogsOptions{
+		Since:      query.Get("since"),
+		Timestamps: query.Get("timestamps") == "true",
+	}
+	if tail := query.Get("tail"); tail != "" {
+		n, err := strconv.Atoi(tail)
+		if err != nil {
+			return opts, fmt.Errorf("invalid tail value %q: use a number of lines", tail)
+		}
+		opts.Tail = n
+	}
+	return opts, opts.Validate()
+}
+
+// declaredResponse is the JSON representation of what a project's configuration declares
+type declaredResponse struct {
+	Services []string `json:"services"`
+	Volumes  []string `json:"volumes"`
+	Images   []string `json:"images"`
+}
+
+// deploymentResponse is the JSON representation of a deployment. The output is left out, it can
+// be large and is only needed when inspecting a single deployment.
+type deploymentResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ProjectID      uuid.UUID `json:"project_id"`
+	CommitHash     string    `json:"commit_hash"`
+	PreviousCommit string    `json:"previous_commit"` // Empty for the first deployment of a project
+	Status         string    `json:"status"`
+	CommandLine    string    `json:"command_line,omitempty"`
+	DockerVersion  string    `json:"docker_version,omitempty"`
+	ComposeVersion string    `json:"compose_version,omitempty"`
+	ImageDigests   []string  `json:"image_digests,omitempty"` // Images pinned as image:tag@digest
+	Summary        string    `json:"summary,omitempty"`       // What the deployment changed
+	Notes          string    `json:"notes"`
+	Labels         []string  `json:"labels"`
+	// FailedServices lists the services that did not come up when the deployment failed
+	FailedServices []serviceFailureResponse `json:"failed_services,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// serviceFailureResponse is the JSON representation of a service that failed to start
+type serviceFailureResponse struct {
+	Service  string   `json:"service"`
+	State    string   `json:"state"` // Empty when no container was created
+	ExitCode int      `json:"exit_code"`
+	Summary  string   `json:"summary"`
+	LastLogs []string `json:"last_logs,omitempty"`
+}
+
+func newDeploymentResponse(deployment *domain.Deployment) deploymentResponse {
+	labels := deployment.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	var failures []serviceFailureResponse
+	for _, failure := range deployment.FailedServices {
+		failures = append(failures, serviceFailureResponse{
+			Service:  failure.Service,
+			State:    failure.State,
+			ExitCode: failure.ExitCode,
+			Summary:  failure.String(),
+			LastLogs: failure.LastLogs,
+		})
+	}
+	return deploymentResponse{
+		ID:             deployment.ID,
+		ProjectID:      deployment.ProjectID,
+		CommitHash:     deployment.CommitHash,
+		PreviousCommit: deployment.PreviousCommit,
+		Status:         deployment.Status.String(),
+		CommandLine:    deployment.CommandLine,
+		DockerVersion:  deployment.DockerVersion,
+		ComposeVersion: deployment.ComposeVersion,
+		ImageDigests:   deployment.ImageDigests,
+		Summary:        deployment.Summary,
+		Notes:          deployment.Notes,
+		Labels:         labels,
+		FailedServices: failures,
+		CreatedAt:      deployment.CreatedAt,
+		UpdatedAt:      deployment.UpdatedAt,
+	}
+}
+
+// deploymentAnnotationRequest is the JSON body for annotating a deployment
+type deploymentAnnotationRequest struct {
+	Notes  string   `json:"notes"`
+	Labels []string `json:"labels"`
+}
+
+// getAllProjectStatuses fetches the live status of every project in one batch
+func getAllProjectStatuses() (map[uuid.UUID]*docker.ComposeStatus, map[uuid.UUID]error, error) {
+	projectService := app.GetProjectService()
+	projects, err := projectService.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projectIDs := make([]uuid.UUID, len(projects))
+	for i, p := range projects {
+		projectIDs[i] = p.ID
+	}
+
+	statuses, errs := projectService.GetStatusMany(projectIDs)
+	return statuses, errs, nil
 }
 
 // Modal helper functions
@@ -228,6 +687,68 @@ func getConfigProjectContent(projectID uuid.UUID) (string, error) {
 	return fmt.Sprintf(`<pre id="config-content" class="streaming-output">%s</pre>`, formattedContent), nil
 }
 
+func getEnvProjectModal(projectID uuid.UUID) (templ.Component, error) {
+	projectService := app.GetProjectService()
+	targetProject, err := projectService.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectView := handlers.ConvertProjectToView(targetProject)
+
+	vars, err := projectService.GetEffectiveEnv(projectID, false)
+	if err != nil {
+		return modals.EnvProjectErrorModal(projectView, err.Error()), nil
+	}
+	return modals.EnvProjectModal(projectView, vars), nil
+}
+
+func getDiskUsageProjectModalWithLoading(projectID uuid.UUID) (templ.Component, error) {
+	projectService := app.GetProjectService()
+	targetProject, err := projectService.Get(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectView := handlers.ConvertProjectToView(targetProject)
+	return modals.DiskUsageProjectModalWithLoading(projectView), nil
+}
+
+// getDiskUsageProjectContent measures the disk usage of a project, or reuses a recent measurement
+func getDiskUsageProjectContent(projectID uuid.UUID) (templ.Component, error) {
+	usage, err := app.GetProjectService().GetDiskUsage(projectID)
+	if err != nil {
+		return modals.DiskUsageProjectError(err.Error()), nil
+	}
+
+	view := modals.DiskUsageView{
+		Rows: []modals.DiskUsageRow{
+			{Kind: "Working directory", Size: units.HumanSize(float64(usage.WorkingDir))},
+		},
+		Total:      units.HumanSize(float64(usage.Total)),
+		ComputedAt: usage.ComputedAt,
+	}
+	for _, volume := range usage.Volumes {
+		size := "unknown"
+		if volume.Size >= 0 {
+			size = units.HumanSize(float64(volume.Size))
+		}
+		view.Rows = append(view.Rows, modals.DiskUsageRow{Kind: "Volume", Name: volume.Name, Size: size})
+	}
+	for _, image := range usage.Images {
+		view.Rows = append(view.Rows, modals.DiskUsageRow{
+			Kind:   "Image",
+			Name:   image.Image,
+			Size:   units.HumanSize(float64(image.Size)),
+			Shared: image.Shared,
+		})
+	}
+	if usage.Exceeds {
+		view.Warning = units.HumanSize(float64(usage.Warning))
+	}
+	return modals.DiskUsageProjectContent(view), nil
+}
+
 func getLogsProjectModalWithLoading(projectID uuid.UUID) (templ.Component, error) {
 	projectService := app.GetProjectService()
 	targetProject, err := projectService.Get(projectID)
@@ -239,9 +760,21 @@ func getLogsProjectModalWithLoading(projectID uuid.UUID) (templ.Component, error
 	return modals.LogsProjectModalWithLoading(projectView), nil
 }
 
-func getLogsProjectContent(projectID uuid.UUID) (string, error) {
+// handleLogsContent serves the logs panel content, with timestamps when requested. Colors written
+// by the services are always stripped, since the panel would show the escape sequences as text.
+func handleLogsContent(w http.ResponseWriter, r *http.Request) {
+	opts := docker.LogsOptions{
+		Timestamps: r.URL.Query().Get("timestamps") == "true",
+		StripColor: true,
+	}
+	handlers.HandleHTMLContent(func(projectID uuid.UUID) (string, error) {
+		return getLogsProjectContent(projectID, opts)
+	})(w, r)
+}
+
+func getLogsProjectContent(projectID uuid.UUID, opts docker.LogsOptions) (string, error) {
 	projectService := app.GetProjectService()
-	stdout, stderr, err := projectService.GetLogs(projectID)
+	stdout, stderr, err := projectService.GetLogs(projectID, opts)
 	if err != nil {
 		return fmt.Sprintf(`<pre id="static-logs-content" class="streaming-output">Error getting project logs:
 
@@ -0,0 +1,358 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/oar-cd/oar/app"
+	"github.com/oar-cd/oar/docker"
+	"github.com/oar-cd/oar/domain"
+	"github.com/oar-cd/oar/share"
+	"github.com/oar-cd/oar/web/components/modals"
+	"github.com/oar-cd/oar/web/handlers"
+	"github.com/oar-cd/oar/web/pages"
+)
+
+// sharedLogsTail bounds the log lines of each service shown through a share link
+const sharedLogsTail = 1000
+
+type shareLinkContextKey struct{}
+
+// RegisterShareRoutes registers the pages opened through share links. They only read: any other
+// method is rejected before the link is checked, and nothing of the configuration or the
+// environment is served.
+func RegisterShareRoutes(r chi.Router) {
+	r.Route("/share/{token}", func(r chi.Router) {
+		r.Use(readOnly, withShareLink)
+
+		r.Get("/", handleSharedProject)
+		r.Get("/status", handleSharedStatus)
+		r.Get("/logs", handleSharedLogs)
+		r.Get("/deploy/watch", handleSharedDeploymentWatch)
+	})
+}
+
+// readOnly rejects every request that could change something
+func readOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withShareLink verifies the token of the request and passes its link on in the context. Requests
+// with a link that cannot be used get a page that says why.
+func withShareLink(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The token is in the URL: keep it out of caches, search engines and referrers
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+		link, err := app.GetShareService().Verify(chi.URLParam(r, "token"))
+		if err != nil {
+			status, message := http.StatusNotFound, "This link is not valid."
+			switch {
+			case errors.Is(err, share.ErrLinkExpired):
+				status, message = http.StatusGone, "This link has expired."
+			case errors.Is(err, share.ErrLinkRevoked):
+				status, message = http.StatusGone, "This link has been revoked."
+			case !errors.Is(err, share.ErrInvalidLink):
+				handlers.LogOperationError("verify_share_link", "share", err)
+				status, message = http.StatusInternalServerError, "This link cannot be opened right now."
+			}
+			w.WriteHeader(status)
+			component := pages.SharedLinkUnavailable(message)
+			if err := handlers.RenderComponent(w, r, component, "shared_link_unavailable"); err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), shareLinkContextKey{}, link)))
+	})
+}
+
+func sharedLink(r *http.Request) *domain.ShareLink {
+	return r.Context().Value(shareLinkContextKey{}).(*domain.ShareLink)
+}
+
+func handleSharedProject(w http.ResponseWriter, r *http.Request) {
+	link := sharedLink(r)
+	sharedProject, err := app.GetProjectService().Get(link.ProjectID)
+	if err != nil {
+		handlers.LogOperationError("shared_project", "share", err, "project_id", link.ProjectID)
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	view := pages.SharedProjectView{
+		Token:     chi.URLParam(r, "token"),
+		Name:      sharedProject.Name,
+		GitBranch: sharedProject.GitBranch,
+		ExpiresAt: link.ExpiresAt,
+		Status:    getSharedStatus(link.ProjectID),
+	}
+	if sharedProject.LocalCommit != nil {
+		view.LocalCommit = *sharedProject.LocalCommit
+	}
+	if err := handlers.RenderComponent(w, r, pages.SharedProject(view), "shared_project"); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func handleSharedStatus(w http.ResponseWriter, r *http.Request) {
+	link := sharedLink(r)
+	component := pages.SharedStatus(chi.URLParam(r, "token"), getSharedStatus(link.ProjectID))
+	if err := handlers.RenderComponent(w, r, component, "shared_status"); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// getSharedStatus returns the live status of a project. Errors are logged but not shown, they can
+// name paths and hosts of the server.
+func getSharedStatus(projectID uuid.UUID) pages.SharedStatusView {
+	status, err := app.GetProjectService().GetStatus(projectID)
+	if err != nil {
+		handlers.LogOperationError("shared_status", "share", err, "project_id", projectID)
+		return pages.SharedStatusView{
+			Status: handlers.ConvertStatusErrorToLiveView(err).Status,
+			Error:  "The status of the project is not available right now.",
+		}
+	}
+	return pages.SharedStatusView{
+		Status:     handlers.ConvertStatusToLiveView(status).Status,
+		Uptime:     status.Uptime,
+		Containers: status.Containers,
+	}
+}
+
+func handleSharedLogs(w http.ResponseWriter, r *http.Request) {
+	link := sharedLink(r)
+	content := `<pre id="static-logs-content" class="streaming-output">Logs are not available right now.</pre>`
+
+	projectService := app.GetProjectService()
+	stdout, stderr, err := projectService.GetLogs(link.ProjectID, docker.LogsOptions{
+		StripColor: true,
+		Tail:       sharedLogsTail,
+	})
+	if err != nil {
+		handlers.LogOperationError("shared_logs", "share", err, "project_id", link.ProjectID)
+	} else if redactor, err := secretRedactor(link.ProjectID); err != nil {
+		// Without the secrets to look for, the logs cannot be shown safely
+		handlers.LogOperationError("shared_logs_redact", "share", err, "project_id", link.ProjectID)
+	} else {
+		content = formatSharedLogs(stdout, stderr, redactor)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(content)); err != nil {
+		handlers.LogOperationError("shared_logs_write", "share", err, "project_id", link.ProjectID)
+	}
+}
+
+// formatSharedLogs renders logs like the logs panel, with secrets masked and the output escaped
+func formatSharedLogs(stdout, stderr string, redactor *strings.Replacer) string {
+	var content strings.Builder
+	content.WriteString(`<pre id="static-logs-content" class="streaming-output">`)
+
+	if stderr != "" {
+		content.WriteString(`<span class="deploy-text-stderr">`)
+		for i, line := range strings.Split(stderr, "\n") {
+			if i > 0 {
+				content.WriteString("\n")
+			}
+			content.WriteString(html.EscapeString(redactor.Replace(docker.ParseComposeLogLine(line))))
+		}
+		content.WriteString("</span>\n")
+	}
+
+	if strings.TrimSpace(stdout) == "" {
+		stdout = "No logs available"
+	}
+	content.WriteString(`<span class="deploy-text-stdout">`)
+	content.WriteString(html.EscapeString(redactor.Replace(stdout)))
+	content.WriteString("</span></pre>")
+
+	return content.String()
+}
+
+// handleSharedDeploymentWatch streams the output of the deployment in progress, with secrets masked
+func handleSharedDeploymentWatch(w http.ResponseWriter, r *http.Request) {
+	link := sharedLink(r)
+	redactor, err := secretRedactor(link.ProjectID)
+	if err != nil {
+		handlers.LogOperationError("shared_deployment_watch", "share", err, "project_id", link.ProjectID)
+		http.Error(w, "Deployment output is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	outputChan, unsubscribe, err := app.GetProjectService().SubscribeDeployment(link.ProjectID)
+	if err != nil {
+		http.Error(w, "No deployment in progress", http.StatusNotFound)
+		return
+	}
+	defer unsubscribe()
+
+	// Unsubscribing closes outputChan, which ends StreamOutput while it waits for output
+	stop := context.AfterFunc(r.Context(), unsubscribe)
+	defer stop()
+
+	redacted := make(chan docker.StreamMessage, 100)
+	go func() {
+		defer close(redacted)
+		for msg := range outputChan {
+			msg.Content = redactor.Replace(msg.Content)
+			redacted <- msg
+		}
+	}()
+
+	handlers.SetupSSE(w)
+	if err := handlers.StreamOutput(w, redacted, "deployment"); err != nil {
+		handlers.LogOperationError("shared_deployment_watch", "share", err, "project_id", link.ProjectID)
+		// Drain the messages left, so that the redacting goroutine ends with the subscription
+		for range redacted {
+		}
+	}
+}
+
+// secretRedactor returns the replacer that masks the project's secret-looking variables
+func secretRedactor(projectID uuid.UUID) (*strings.Replacer, error) {
+	vars, err := app.GetProjectService().GetEffectiveEnv(projectID, true)
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewSecretRedactor(vars), nil
+}
+
+// Share link management, on the project routes
+
+func handleShareModal(w http.ResponseWriter, r *http.Request) {
+	projectID, err := handlers.ParseProjectID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	targetProject, err := app.GetProjectService().Get(projectID)
+	if err != nil {
+		handlers.LogOperationError("share_project_modal", "handlers", err, "project_id", projectID)
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	links, err := getShareLinkViews(r, projectID)
+	if err != nil {
+		handlers.LogOperationError("share_project_modal", "handlers", err, "project_id", projectID)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	component := modals.ShareProjectModal(handlers.ConvertProjectToView(targetProject), links)
+	if err := handlers.RenderComponent(w, r, component, "share_project_modal"); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func handleCreateShareLink(w http.ResponseWriter, r *http.Request) {
+	renderShareLinks(w, r, "create_share_link", func(projectID uuid.UUID) error {
+		ttl := share.DefaultTTL
+		if value := r.FormValue("expires_in"); value != "" {
+			var err error
+			if ttl, err = time.ParseDuration(value); err != nil {
+				return fmt.Errorf("invalid lifetime %q", value)
+			}
+		}
+		_, _, err := app.GetShareService().Create(projectID, ttl)
+		return err
+	})
+}
+
+func handleRevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	renderShareLinks(w, r, "revoke_share_link", func(projectID uuid.UUID) error {
+		linkID, err := uuid.Parse(chi.URLParam(r, "shareID"))
+		if err != nil {
+			return errors.New("invalid share link ID format")
+		}
+		return app.GetShareService().Revoke(projectID, linkID)
+	})
+}
+
+// renderShareLinks runs a change to the share links of a project and renders the links again,
+// with the error of the change when it failed
+func renderShareLinks(w http.ResponseWriter, r *http.Request, operation string, change func(uuid.UUID) error) {
+	handlers.WithFormParsing(func(w http.ResponseWriter, r *http.Request) {
+		projectID, err := handlers.ParseProjectID(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		targetProject, err := app.GetProjectService().Get(projectID)
+		if err != nil {
+			handlers.LogOperationError(operation, "handlers", err, "project_id", projectID)
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+
+		var message string
+		if err := change(projectID); err != nil {
+			handlers.LogOperationError(operation, "handlers", err, "project_id", projectID)
+			message = err.Error()
+		}
+
+		links, err := getShareLinkViews(r, projectID)
+		if err != nil {
+			handlers.LogOperationError(operation, "handlers", err, "project_id", projectID)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+
+		component := modals.ShareLinks(handlers.ConvertProjectToView(targetProject), links, message)
+		if err := handlers.RenderComponent(w, r, component, operation); err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		}
+	})(w, r)
+}
+
+func getShareLinkViews(r *http.Request, projectID uuid.UUID) ([]modals.ShareLinkView, error) {
+	shareService := app.GetShareService()
+	links, err := shareService.List(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]modals.ShareLinkView, len(links))
+	for i, link := range links {
+		views[i] = modals.ShareLinkView{
+			ID:        link.ID.String(),
+			URL:       shareURL(r, shareService.Token(link)),
+			ExpiresAt: link.ExpiresAt,
+			Revoked:   link.RevokedAt != nil,
+			Expired:   !now.Before(link.ExpiresAt),
+		}
+	}
+	return views, nil
+}
+
+// shareURL returns the address of a share link as seen by the client, also behind a reverse proxy
+func shareURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return fmt.Sprintf("%s://%s/share/%s", scheme, r.Host, token)
+}